@@ -0,0 +1,177 @@
+package agentgrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// AgentBatchServiceName is the name of the gRPC service which the bots can implement
+// to receive multiple events in each evaluation request.
+const AgentBatchServiceName = "network.forta.AgentBatch"
+
+// Agent gRPC batch methods
+const (
+	MethodEvaluateTxBatch Method = "/network.forta.AgentBatch/EvaluateTxBatch"
+)
+
+// batchFieldNumber is the repeated field of the batch messages which contains the requests
+// or the responses:
+//
+//	message EvaluateTxBatchRequest { repeated EvaluateTxRequest requests = 1; }
+//	message EvaluateTxBatchResponse { repeated EvaluateTxResponse responses = 1; }
+const batchFieldNumber protowire.Number = 1
+
+// wireMessage is a message which the codec encodes without the proto definitions.
+type wireMessage interface {
+	marshalWire() ([]byte, error)
+	unmarshalWire([]byte) error
+}
+
+// EvaluateTxBatchRequest carries multiple tx evaluation requests in one call.
+type EvaluateTxBatchRequest struct {
+	Requests []*protocol.EvaluateTxRequest
+}
+
+// EvaluateTxBatchResponse carries one tx evaluation response for each request of the batch,
+// in the same order with the requests.
+type EvaluateTxBatchResponse struct {
+	Responses []*protocol.EvaluateTxResponse
+}
+
+func (batch *EvaluateTxBatchRequest) marshalWire() ([]byte, error) {
+	msgs := make([]proto.Message, len(batch.Requests))
+	for i, req := range batch.Requests {
+		msgs[i] = req
+	}
+	return marshalBatch(msgs)
+}
+
+func (batch *EvaluateTxBatchRequest) unmarshalWire(b []byte) error {
+	return unmarshalBatch(b, func(data []byte) error {
+		req := new(protocol.EvaluateTxRequest)
+		if err := proto.Unmarshal(data, req); err != nil {
+			return err
+		}
+		batch.Requests = append(batch.Requests, req)
+		return nil
+	})
+}
+
+func (batch *EvaluateTxBatchResponse) marshalWire() ([]byte, error) {
+	msgs := make([]proto.Message, len(batch.Responses))
+	for i, resp := range batch.Responses {
+		msgs[i] = resp
+	}
+	return marshalBatch(msgs)
+}
+
+func (batch *EvaluateTxBatchResponse) unmarshalWire(b []byte) error {
+	return unmarshalBatch(b, func(data []byte) error {
+		resp := new(protocol.EvaluateTxResponse)
+		if err := proto.Unmarshal(data, resp); err != nil {
+			return err
+		}
+		batch.Responses = append(batch.Responses, resp)
+		return nil
+	})
+}
+
+// marshalBatch encodes the messages as the repeated batch field. The shared requests are
+// encoded only once for all bots, like in the unary calls.
+func marshalBatch(msgs []proto.Message) ([]byte, error) {
+	var b []byte
+	for _, msg := range msgs {
+		data, err := Codec.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, batchFieldNumber, protowire.BytesType)
+		b = protowire.AppendBytes(b, data)
+	}
+	return b, nil
+}
+
+func unmarshalBatch(b []byte, add func([]byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if num != batchFieldNumber || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		data, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := add(data); err != nil {
+			return fmt.Errorf("failed to unmarshal batch item: %v", err)
+		}
+	}
+	return nil
+}
+
+// AgentBatchServer is the server API for the agent batch service. The servers need to use
+// the agent codec with grpc.ForceServerCodec(Codec).
+type AgentBatchServer interface {
+	EvaluateTxBatch(context.Context, *EvaluateTxBatchRequest) (*EvaluateTxBatchResponse, error)
+}
+
+// UnimplementedAgentBatchServer can be embedded to have forward compatible implementations.
+type UnimplementedAgentBatchServer struct{}
+
+// EvaluateTxBatch implements AgentBatchServer.
+func (UnimplementedAgentBatchServer) EvaluateTxBatch(context.Context, *EvaluateTxBatchRequest) (*EvaluateTxBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateTxBatch not implemented")
+}
+
+func evaluateTxBatchHandler(
+	srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(EvaluateTxBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentBatchServer).EvaluateTxBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: string(MethodEvaluateTxBatch),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentBatchServer).EvaluateTxBatch(ctx, req.(*EvaluateTxBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentBatchServiceDesc is the grpc.ServiceDesc for the agent batch service.
+var AgentBatchServiceDesc = grpc.ServiceDesc{
+	ServiceName: AgentBatchServiceName,
+	HandlerType: (*AgentBatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EvaluateTxBatch",
+			Handler:    evaluateTxBatchHandler,
+		},
+	},
+}
+
+// RegisterAgentBatchServer registers the agent batch service to a gRPC server.
+func RegisterAgentBatchServer(s grpc.ServiceRegistrar, srv AgentBatchServer) {
+	s.RegisterService(&AgentBatchServiceDesc, srv)
+}
@@ -0,0 +1,88 @@
+package agentgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestBatchCodec(t *testing.T) {
+	r := require.New(t)
+
+	batch := &EvaluateTxBatchRequest{Requests: []*protocol.EvaluateTxRequest{testTxRequest(1), testTxRequest(2)}}
+	ShareEncoding(batch.Requests[0])
+	b, err := Codec.Marshal(batch)
+	r.NoError(err)
+
+	var decoded EvaluateTxBatchRequest
+	r.NoError(Codec.Unmarshal(b, &decoded))
+	r.Len(decoded.Requests, 2)
+	for i, req := range batch.Requests {
+		r.True(proto.Equal(req, decoded.Requests[i]))
+	}
+
+	// the batch is a valid message with a repeated field for the bots with the proto definitions
+	var resp EvaluateTxBatchResponse
+	r.NoError(Codec.Unmarshal(b, &resp))
+	r.Len(resp.Responses, 2)
+
+	r.Error(Codec.Unmarshal([]byte{0x0a, 0xff}, &decoded))
+}
+
+type testBatchServer struct {
+	UnimplementedAgentBatchServer
+}
+
+func (testBatchServer) EvaluateTxBatch(ctx context.Context, batch *EvaluateTxBatchRequest) (*EvaluateTxBatchResponse, error) {
+	resp := &EvaluateTxBatchResponse{}
+	for _, req := range batch.Requests {
+		resp.Responses = append(resp.Responses, &protocol.EvaluateTxResponse{
+			Status:   protocol.ResponseStatus_SUCCESS,
+			Findings: []*protocol.Finding{{Name: req.RequestId}},
+		})
+	}
+	return resp, nil
+}
+
+func TestClient_EvaluateTxBatch(t *testing.T) {
+	r := require.New(t)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ForceServerCodec(Codec))
+	RegisterAgentBatchServer(server, testBatchServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(DefaultCallOptions()...),
+	)
+	r.NoError(err)
+	client := NewClient()
+	client.WithConn(conn)
+	defer client.Close()
+
+	batch := &EvaluateTxBatchRequest{Requests: []*protocol.EvaluateTxRequest{testTxRequest(1), testTxRequest(2)}}
+	var resp EvaluateTxBatchResponse
+	r.NoError(client.Invoke(context.Background(), MethodEvaluateTxBatch, batch, &resp))
+	r.Len(resp.Responses, 2)
+	r.Equal("request-1", resp.Responses[0].Findings[0].Name)
+	r.Equal("request-2", resp.Responses[1].Findings[0].Name)
+
+	// the bots without the batch service respond with unimplemented
+	err = client.Invoke(context.Background(), MethodEvaluateTx, testTxRequest(1), &protocol.EvaluateTxResponse{})
+	r.Equal(codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,17 @@
+package agentgrpc
+
+// AgentBeaconServiceName is the name of the gRPC service which the bots can implement
+// to receive the consensus-layer events.
+const AgentBeaconServiceName = "network.forta.AgentBeacon"
+
+// Agent gRPC beacon methods
+//
+// The beacon events reuse the block messages, so that the bots do not need new definitions:
+//
+//	service AgentBeacon { rpc EvaluateBeacon(EvaluateBlockRequest) returns (EvaluateBlockResponse); }
+//
+// The block event of the request refers to the execution-layer block of the slot and contains
+// the beacon event as JSON in field 1003, which the bots can read with beacon.FromBlock.
+const (
+	MethodEvaluateBeacon Method = "/network.forta.AgentBeacon/EvaluateBeacon"
+)
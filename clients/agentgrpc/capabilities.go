@@ -0,0 +1,160 @@
+package agentgrpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Agent protocol versions which the node supports. The bots which do not report a version
+// are considered to use the min version.
+const (
+	ProtocolVersion    = 1
+	MinProtocolVersion = 1
+)
+
+// Event types
+const (
+	EventTypeTx       = "tx"
+	EventTypeBlock    = "block"
+	EventTypeLog      = "log"
+	EventTypeAlert    = "alert"
+	EventTypeBeacon   = "beacon"
+	EventTypeSchedule = "schedule"
+	// the pending transactions are sent only to the bots which opt in
+	EventTypePendingTx = "pending-tx"
+)
+
+// IsPendingTx tells if the transaction event is built from a pending transaction.
+func IsPendingTx(evt *protocol.TransactionEvent) bool {
+	return evt.Block == nil || len(evt.Block.BlockNumber) == 0
+}
+
+// NodeProtocol tells the bots which protocol versions the node supports.
+type NodeProtocol struct {
+	ProtocolVersion    int `json:"protocolVersion"`
+	MinProtocolVersion int `json:"minProtocolVersion"`
+}
+
+// Capabilities are reported by the bots in the initialize responses.
+type Capabilities struct {
+	ProtocolVersion int `json:"protocolVersion"`
+	// the event types which the bot wants to receive - empty means all
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// the configuration which the bot needs, as reported by the bot
+	Config map[string]string `json:"config,omitempty"`
+	// the compressors which the bot can decompress the requests with
+	Compressors []string `json:"compressors,omitempty"`
+}
+
+// DefaultCapabilities returns the capabilities of the bots which do not report any.
+func DefaultCapabilities() *Capabilities {
+	return &Capabilities{ProtocolVersion: MinProtocolVersion}
+}
+
+// Supports tells if the bot wants to receive the events of the type.
+func (caps *Capabilities) Supports(eventType string) bool {
+	if caps == nil || len(caps.EventTypes) == 0 {
+		return true
+	}
+	return caps.OptsIn(eventType)
+}
+
+// OptsIn tells if the bot reported the event type explicitly.
+func (caps *Capabilities) OptsIn(eventType string) bool {
+	if caps == nil {
+		return false
+	}
+	for _, supported := range caps.EventTypes {
+		if supported == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckProtocolVersion returns an error if the node does not support the protocol version of the bot.
+func (caps *Capabilities) CheckProtocolVersion() error {
+	if caps.ProtocolVersion < MinProtocolVersion || caps.ProtocolVersion > ProtocolVersion {
+		return fmt.Errorf(
+			"bot protocol version %d is not supported by the node (supported versions: %d-%d)",
+			caps.ProtocolVersion, MinProtocolVersion, ProtocolVersion,
+		)
+	}
+	for _, eventType := range caps.EventTypes {
+		switch eventType {
+		case EventTypeTx, EventTypeBlock, EventTypeLog, EventTypeAlert, EventTypeBeacon, EventTypeSchedule, EventTypePendingTx:
+		default:
+			return fmt.Errorf("bot reported unknown event type: %s", eventType)
+		}
+	}
+	return nil
+}
+
+// AttachNodeProtocol adds the protocol versions of the node to the initialize request.
+func AttachNodeProtocol(req *protocol.InitializeRequest) error {
+	b, err := json.Marshal(&NodeProtocol{ProtocolVersion: ProtocolVersion, MinProtocolVersion: MinProtocolVersion})
+	if err != nil {
+		return fmt.Errorf("failed to encode the node protocol: %v", err)
+	}
+	appendUnknownBytes(req.ProtoReflect(), NodeProtocolFieldNumber, b)
+	return nil
+}
+
+// AttachCapabilities adds the capabilities to the initialize response.
+func AttachCapabilities(resp *protocol.InitializeResponse, caps *Capabilities) error {
+	b, err := json.Marshal(caps)
+	if err != nil {
+		return fmt.Errorf("failed to encode the capabilities: %v", err)
+	}
+	appendUnknownBytes(resp.ProtoReflect(), CapabilitiesFieldNumber, b)
+	return nil
+}
+
+// CapabilitiesFromResponse reads the capabilities from the initialize response. It returns the
+// default capabilities if the response does not have them.
+func CapabilitiesFromResponse(resp *protocol.InitializeResponse) (*Capabilities, error) {
+	b, found, err := findUnknownBytes(resp.ProtoReflect(), CapabilitiesFieldNumber)
+	if err != nil || !found {
+		return DefaultCapabilities(), err
+	}
+	caps := DefaultCapabilities()
+	if err := json.Unmarshal(b, caps); err != nil {
+		return nil, fmt.Errorf("failed to decode the capabilities: %v", err)
+	}
+	return caps, nil
+}
+
+func appendUnknownBytes(msg protoreflect.Message, num protowire.Number, b []byte) {
+	unknown := msg.GetUnknown()
+	unknown = protowire.AppendTag(unknown, num, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.SetUnknown(unknown)
+}
+
+func findUnknownBytes(msg protoreflect.Message, num protowire.Number) ([]byte, bool, error) {
+	unknown := msg.GetUnknown()
+	for len(unknown) > 0 {
+		fieldNum, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, false, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if fieldNum == num && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, false, protowire.ParseError(n)
+			}
+			return b, true, nil
+		}
+		n = protowire.ConsumeFieldValue(fieldNum, typ, unknown)
+		if n < 0 {
+			return nil, false, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,58 @@
+package agentgrpc
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	r := require.New(t)
+
+	// the bots which do not report capabilities use the min version and receive all events
+	caps, err := CapabilitiesFromResponse(&protocol.InitializeResponse{})
+	r.NoError(err)
+	r.Equal(MinProtocolVersion, caps.ProtocolVersion)
+	r.NoError(caps.CheckProtocolVersion())
+	r.True(caps.Supports(EventTypeTx))
+	r.True(caps.Supports(EventTypeAlert))
+
+	resp := &protocol.InitializeResponse{Status: protocol.ResponseStatus_SUCCESS}
+	r.NoError(AttachCapabilities(resp, &Capabilities{
+		ProtocolVersion: ProtocolVersion,
+		EventTypes:      []string{EventTypeTx, EventTypeLog},
+		Config:          map[string]string{"chainId": "1"},
+	}))
+
+	// the capabilities should survive the encoding
+	b, err := proto.Marshal(resp)
+	r.NoError(err)
+	var decoded protocol.InitializeResponse
+	r.NoError(proto.Unmarshal(b, &decoded))
+	caps, err = CapabilitiesFromResponse(&decoded)
+	r.NoError(err)
+	r.NoError(caps.CheckProtocolVersion())
+	r.True(caps.Supports(EventTypeTx))
+	r.True(caps.Supports(EventTypeLog))
+	r.False(caps.Supports(EventTypeBlock))
+	r.True(caps.OptsIn(EventTypeTx))
+	r.False(DefaultCapabilities().OptsIn(EventTypePendingTx))
+	r.Equal("1", caps.Config["chainId"])
+
+	r.Error((&Capabilities{ProtocolVersion: ProtocolVersion + 1}).CheckProtocolVersion())
+	r.Error((&Capabilities{ProtocolVersion: MinProtocolVersion - 1}).CheckProtocolVersion())
+	r.Error((&Capabilities{ProtocolVersion: ProtocolVersion, EventTypes: []string{"unknown"}}).CheckProtocolVersion())
+}
+
+func TestAttachNodeProtocol(t *testing.T) {
+	r := require.New(t)
+
+	req := &protocol.InitializeRequest{AgentId: "0x1"}
+	r.NoError(AttachNodeProtocol(req))
+	b, found, err := findUnknownBytes(req.ProtoReflect(), NodeProtocolFieldNumber)
+	r.NoError(err)
+	r.True(found)
+	r.JSONEq(`{"protocolVersion":1,"minProtocolVersion":1}`, string(b))
+}
@@ -2,6 +2,7 @@ package agentgrpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"time"
@@ -11,6 +12,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const defaultAgentResponseMaxByteCount = 250000 // 250K
@@ -24,25 +27,44 @@ const (
 	MethodEvaluateTx    Method = "/network.forta.Agent/EvaluateTx"
 	MethodEvaluateBlock Method = "/network.forta.Agent/EvaluateBlock"
 	MethodEvaluateAlert Method = "/network.forta.Agent/EvaluateAlert"
+	MethodHealthCheck   Method = "/network.forta.Agent/HealthCheck"
 )
 
+// RequestIDKey is the gRPC metadata key which carries the request ID to the bots, so that
+// the bot logs can be matched with the node logs and the alerts.
+const RequestIDKey = "forta-request-id"
+
+// WarmUpKey is the gRPC metadata key which marks the requests that are replayed to a newly started
+// bot so that it can build its state. The findings of these requests are discarded.
+const WarmUpKey = "forta-warm-up"
+
 // Client makes the gRPC requests to evaluate block and txs and receive results.
 type Client interface {
 	DialWithRetry(config.AgentConfig) error
 	Invoke(ctx context.Context, method Method, in, out interface{}, opts ...grpc.CallOption) error
+	EvaluateTxStream(ctx context.Context, opts ...grpc.CallOption) (TxStream, error)
 	protocol.AgentClient
 	io.Closer
 }
 
 // client allows us to communicate with an agent.
 type client struct {
-	conn *grpc.ClientConn
+	conn  *grpc.ClientConn
+	creds credentials.TransportCredentials
+	opts  DialOptions
+	// the negotiated compressor of the evaluation requests
+	compressor string
 	protocol.AgentClient
 }
 
-// NewClient creates a new client.
+// NewClient creates a new client which dials the agents over plaintext connections.
 func NewClient() *client {
-	return &client{}
+	return &client{creds: insecure.NewCredentials()}
+}
+
+// NewTLSClient creates a new client which dials the agents with the TLS config.
+func NewTLSClient(tlsConfig *tls.Config) *client {
+	return &client{creds: credentials.NewTLS(tlsConfig)}
 }
 
 // DialWithRetry dials an agent using the config.
@@ -53,11 +75,12 @@ func (client *client) DialWithRetry(cfg config.AgentConfig) error {
 	)
 	for i := 0; i < 10; i++ {
 		conn, err = grpc.Dial(
-			fmt.Sprintf("%s:%s", cfg.ContainerName(), cfg.GrpcPort()),
-			grpc.WithInsecure(),
-			grpc.WithBlock(),
-			grpc.WithTimeout(10*time.Second),
-			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(defaultAgentResponseMaxByteCount)),
+			cfg.GrpcAddress(),
+			append([]grpc.DialOption{
+				grpc.WithTransportCredentials(client.creds),
+				grpc.WithBlock(),
+				grpc.WithTimeout(10 * time.Second),
+			}, client.opts.dialOptions()...)...,
 		)
 		if err == nil {
 			break
@@ -83,7 +106,7 @@ func (client *client) WithConn(conn *grpc.ClientConn) {
 
 // Invoke is a generalization of client methods.
 func (client *client) Invoke(ctx context.Context, method Method, in, out interface{}, opts ...grpc.CallOption) error {
-	return client.conn.Invoke(ctx, string(method), in, out, opts...)
+	return client.conn.Invoke(ctx, string(method), in, out, client.callOptions(opts)...)
 }
 
 // Close implements io.Closer.
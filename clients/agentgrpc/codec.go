@@ -0,0 +1,119 @@
+package agentgrpc
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// MaxSharedEncodings is the amount of latest shared requests which the codec keeps the encodings of.
+const MaxSharedEncodings = 1024
+
+// Codec is the codec of the bot connections. It is the same with the default gRPC codec, except
+// that it encodes the shared requests only once for all bots and it also encodes the batch messages.
+var Codec encoding.Codec = codec{}
+
+// DefaultCallOptions returns the call options of the bot connections.
+func DefaultCallOptions() []grpc.CallOption {
+	return []grpc.CallOption{
+		grpc.MaxCallRecvMsgSize(defaultAgentResponseMaxByteCount),
+		grpc.ForceCodec(Codec),
+	}
+}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	if wireMsg, ok := v.(wireMessage); ok {
+		return wireMsg.marshalWire()
+	}
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
+	}
+	if enc := sharedEncodings.get(msg); enc != nil {
+		return enc.encode(msg)
+	}
+	return proto.Marshal(msg)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	if wireMsg, ok := v.(wireMessage); ok {
+		return wireMsg.unmarshalWire(data)
+	}
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// Name returns the name of the default codec, so that the bots see the usual content type.
+func (codec) Name() string {
+	return "proto"
+}
+
+// ShareEncoding makes the codec encode the request only once while sending it to multiple bots.
+// The request must not change after this call. The encodings of the older requests are dropped
+// and encoded again for each bot if they are still being sent.
+func ShareEncoding(msg proto.Message) {
+	sharedEncodings.add(msg)
+}
+
+var sharedEncodings = newEncodingCache(MaxSharedEncodings)
+
+type sharedEncoding struct {
+	once sync.Once
+	b    []byte
+	err  error
+}
+
+func (enc *sharedEncoding) encode(msg proto.Message) ([]byte, error) {
+	enc.once.Do(func() {
+		enc.b, enc.err = proto.Marshal(msg)
+	})
+	// the gRPC transport only reads the encoded messages so the same bytes can be sent to all bots
+	return enc.b, enc.err
+}
+
+// encodingCache keeps the encodings of the shared requests by the pointers. The requests
+// are referenced by the cache, so the pointers cannot be reused until they are dropped.
+type encodingCache struct {
+	max       int
+	encodings map[proto.Message]*sharedEncoding
+	order     []proto.Message
+	mu        sync.Mutex
+}
+
+func newEncodingCache(max int) *encodingCache {
+	return &encodingCache{
+		max:       max,
+		encodings: make(map[proto.Message]*sharedEncoding),
+	}
+}
+
+func (cache *encodingCache) add(msg proto.Message) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, ok := cache.encodings[msg]; ok {
+		return
+	}
+	cache.encodings[msg] = &sharedEncoding{}
+	cache.order = append(cache.order, msg)
+	if len(cache.order) > cache.max {
+		delete(cache.encodings, cache.order[0])
+		cache.order[0] = nil
+		cache.order = cache.order[1:]
+	}
+}
+
+func (cache *encodingCache) get(msg proto.Message) *sharedEncoding {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.encodings[msg]
+}
@@ -0,0 +1,96 @@
+package agentgrpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func testTxRequest(i int) *protocol.EvaluateTxRequest {
+	return &protocol.EvaluateTxRequest{
+		RequestId: fmt.Sprintf("request-%d", i),
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{Hash: fmt.Sprintf("0x%064x", i), Input: "0x12345678"},
+			Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+			Logs:        []*protocol.TransactionEvent_Log{{Address: "0x1", Data: "0x1234", Topics: []string{"0x1", "0x2"}}},
+		},
+	}
+}
+
+func TestCodec(t *testing.T) {
+	r := require.New(t)
+
+	req := testTxRequest(1)
+	expected, err := proto.Marshal(req)
+	r.NoError(err)
+
+	b, err := Codec.Marshal(req)
+	r.NoError(err)
+	r.Equal(expected, b)
+
+	// the shared requests are encoded once
+	ShareEncoding(req)
+	b1, err := Codec.Marshal(req)
+	r.NoError(err)
+	b2, err := Codec.Marshal(req)
+	r.NoError(err)
+	r.Equal(expected, b1)
+	r.Same(&b1[0], &b2[0])
+
+	var decoded protocol.EvaluateTxRequest
+	r.NoError(Codec.Unmarshal(b1, &decoded))
+	r.True(proto.Equal(req, &decoded))
+	r.Equal("proto", Codec.Name())
+
+	_, err = Codec.Marshal("not a message")
+	r.Error(err)
+}
+
+func TestEncodingCache(t *testing.T) {
+	r := require.New(t)
+
+	cache := newEncodingCache(2)
+	reqs := []*protocol.EvaluateTxRequest{testTxRequest(1), testTxRequest(2), testTxRequest(3)}
+	for _, req := range reqs {
+		cache.add(req)
+	}
+	cache.add(reqs[2])
+	r.Nil(cache.get(reqs[0]))
+	r.NotNil(cache.get(reqs[1]))
+	r.NotNil(cache.get(reqs[2]))
+	r.Len(cache.order, 2)
+}
+
+// BenchmarkCodec compares encoding a request for each bot with encoding it once for all bots.
+// Use -cpuprofile and -memprofile with go tool pprof to see where the time is spent.
+func BenchmarkCodec(b *testing.B) {
+	const botCount = 50
+
+	b.Run("unshared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			req := testTxRequest(i)
+			for j := 0; j < botCount; j++ {
+				if _, err := Codec.Marshal(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			req := testTxRequest(i)
+			ShareEncoding(req)
+			for j := 0; j < botCount; j++ {
+				if _, err := Codec.Marshal(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
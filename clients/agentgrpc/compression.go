@@ -0,0 +1,123 @@
+package agentgrpc
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Compressors which the node supports.
+const (
+	CompressorGzip = gzip.Name
+	CompressorZstd = "zstd"
+)
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor is the gRPC compressor which uses pooled zstd encoders and decoders.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	defer w.pool.Put(w)
+	return w.Encoder.Close()
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (n int, err error) {
+	n, err = r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+	return n, err
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if writer, ok := c.encoders.Get().(*zstdWriter); ok {
+		writer.Encoder.Reset(w)
+		return writer, nil
+	}
+	encoder, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: encoder, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if reader, ok := c.decoders.Get().(*zstdReader); ok {
+		if err := reader.Decoder.Reset(r); err != nil {
+			c.decoders.Put(reader)
+			return nil, err
+		}
+		return reader, nil
+	}
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{Decoder: decoder, pool: &c.decoders}, nil
+}
+
+func (c *zstdCompressor) Name() string {
+	return CompressorZstd
+}
+
+// CompressionNegotiator is implemented by the clients which can compress the requests.
+type CompressionNegotiator interface {
+	// NegotiateCompressor chooses the compressor of the requests by the capabilities of the bot
+	// and returns the name of the compressor, or an empty string if the requests are not compressed.
+	NegotiateCompressor(caps *Capabilities) string
+}
+
+// NegotiateCompressor returns the preferred compressor if the bot supports it. The bots which
+// do not report the compressors are expected to support only gzip, which all gRPC servers support.
+func NegotiateCompressor(preferred string, caps *Capabilities) string {
+	if len(preferred) == 0 {
+		return ""
+	}
+	if caps == nil || len(caps.Compressors) == 0 {
+		if preferred == CompressorGzip {
+			return preferred
+		}
+		return ""
+	}
+	for _, compressor := range caps.Compressors {
+		if compressor == preferred {
+			return preferred
+		}
+	}
+	return ""
+}
+
+// NegotiateCompressor implements CompressionNegotiator. The initialize requests are never
+// compressed, since the compressor is chosen by the initialize responses.
+func (client *client) NegotiateCompressor(caps *Capabilities) string {
+	client.compressor = NegotiateCompressor(client.opts.Compression, caps)
+	return client.compressor
+}
+
+// callOptions appends the options of the negotiated compressor.
+func (client *client) callOptions(opts []grpc.CallOption) []grpc.CallOption {
+	if len(client.compressor) == 0 {
+		return opts
+	}
+	return append(opts, grpc.UseCompressor(client.compressor))
+}
@@ -0,0 +1,52 @@
+package agentgrpc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	r := require.New(t)
+
+	compressor := encoding.GetCompressor(CompressorZstd)
+	r.NotNil(compressor)
+
+	data := bytes.Repeat([]byte("forta"), 1000)
+	// the pooled encoders and decoders should be reusable
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		w, err := compressor.Compress(&buf)
+		r.NoError(err)
+		_, err = w.Write(data)
+		r.NoError(err)
+		r.NoError(w.Close())
+		r.Less(buf.Len(), len(data))
+
+		reader, err := compressor.Decompress(&buf)
+		r.NoError(err)
+		decompressed, err := io.ReadAll(reader)
+		r.NoError(err)
+		r.Equal(data, decompressed)
+	}
+}
+
+func TestNegotiateCompressor(t *testing.T) {
+	r := require.New(t)
+
+	r.Empty(NegotiateCompressor("", &Capabilities{Compressors: []string{CompressorZstd}}))
+	// the bots which do not report the compressors receive only gzip
+	r.Equal(CompressorGzip, NegotiateCompressor(CompressorGzip, DefaultCapabilities()))
+	r.Empty(NegotiateCompressor(CompressorZstd, DefaultCapabilities()))
+	// the bots which report the compressors receive only the reported ones
+	r.Equal(CompressorZstd, NegotiateCompressor(CompressorZstd, &Capabilities{Compressors: []string{CompressorGzip, CompressorZstd}}))
+	r.Empty(NegotiateCompressor(CompressorGzip, &Capabilities{Compressors: []string{CompressorZstd}}))
+
+	client := NewClient()
+	client.opts.Compression = CompressorZstd
+	r.Equal(CompressorZstd, client.NegotiateCompressor(&Capabilities{Compressors: []string{CompressorZstd}}))
+	r.Len(client.callOptions(nil), 1)
+}
@@ -1,6 +1,8 @@
 package agentgrpc
 
 import (
+	"crypto/tls"
+
 	"github.com/forta-network/forta-node/config"
 )
 
@@ -9,15 +11,24 @@ type BotDialer interface {
 	DialBot(ac config.AgentConfig) (Client, error)
 }
 
-type botDialer struct{}
+type botDialer struct {
+	tlsConfig *tls.Config
+	opts      DialOptions
+}
 
-// NewBotDialer creates a new bot dialer.
-func NewBotDialer() BotDialer {
-	return &botDialer{}
+// NewBotDialer creates a new bot dialer. The bots are dialed with mutual TLS if the TLS config
+// is provided and over plaintext connections otherwise.
+func NewBotDialer(tlsConfig *tls.Config, opts DialOptions) BotDialer {
+	return &botDialer{tlsConfig: tlsConfig, opts: opts}
 }
 
 func (bd *botDialer) DialBot(ac config.AgentConfig) (Client, error) {
 	client := NewClient()
+	// the attached bots are in development and do not have the node certificates
+	if bd.tlsConfig != nil && !ac.IsAttached() {
+		client = NewTLSClient(bd.tlsConfig)
+	}
+	client.opts = bd.opts
 	err := client.DialWithRetry(ac)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,52 @@
+package agentgrpc
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// The fields which the node adds to the messages of the agent protocol. The fields are not in the
+// protocol definitions, so the bots which do not know about them ignore them. Each field contains
+// JSON and has a number which is unique across all messages, so that a field is never mistaken for
+// another one. The new fields should take the next number.
+const (
+	// DecodedFieldNumber is the field of the transaction event message which contains the decoded
+	// transaction.
+	DecodedFieldNumber protowire.Number = 1000
+	// MempoolStatusFieldNumber is the field of the transaction event message which contains the
+	// mempool status of a pending transaction. The bots which do not know about it see the event as
+	// a pending transaction.
+	MempoolStatusFieldNumber protowire.Number = 1001
+	// GasContextFieldNumber is the field of the transaction and the block event messages which
+	// contains the gas context of the block.
+	GasContextFieldNumber protowire.Number = 1002
+	// BeaconEventFieldNumber is the field of the block event message which contains the beacon event.
+	// The beacon events are sent only to the bots which subscribe to them, with the beacon method.
+	BeaconEventFieldNumber protowire.Number = 1003
+	// StateDiffFieldNumber is the field of the transaction event message which contains the state
+	// diff of the transaction.
+	StateDiffFieldNumber protowire.Number = 1004
+	// WatchlistTagsFieldNumber is the field of the transaction event message which contains the
+	// watchlist labels of the addresses of the transaction.
+	WatchlistTagsFieldNumber protowire.Number = 1005
+	// TruncatedFieldNumber is the field of the transaction event message which contains the names of
+	// the truncated parts of the event, so that the bots can tell the missing parts from the empty ones.
+	TruncatedFieldNumber protowire.Number = 1006
+	// ElidedFieldNumber is the field of the transaction event message which tells what the content
+	// limits elided from the event, so that the bots can tell the elided data from the missing data.
+	ElidedFieldNumber protowire.Number = 1007
+	// RawTxFieldNumber is the field of the transaction event message which contains the raw transaction.
+	RawTxFieldNumber protowire.Number = 1008
+	// BlobSidecarsFieldNumber is the field of the transaction event message which contains the blob
+	// sidecars of the EIP-4844 transaction.
+	BlobSidecarsFieldNumber protowire.Number = 1009
+	// ScheduleFieldNumber is the field of the block event message which contains the schedule of a
+	// scheduled evaluation.
+	ScheduleFieldNumber protowire.Number = 1010
+	// PrivateTxFieldNumber is the field of the transaction event message which tells where a private
+	// transaction came from.
+	PrivateTxFieldNumber protowire.Number = 1011
+	// NodeProtocolFieldNumber is the field of the initialize request which contains the protocol
+	// versions of the node.
+	NodeProtocolFieldNumber protowire.Number = 1012
+	// CapabilitiesFieldNumber is the field of the initialize response which contains the capabilities
+	// of the bot. The bots which do not know about it do not send it.
+	CapabilitiesFieldNumber protowire.Number = 1013
+)
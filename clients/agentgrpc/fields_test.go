@@ -0,0 +1,39 @@
+package agentgrpc
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFieldNumbers tests that the fields which the node adds to the messages have unique numbers.
+func TestFieldNumbers(t *testing.T) {
+	r := require.New(t)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "fields.go", nil, 0)
+	r.NoError(err)
+
+	fields := make(map[string]string)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				r.True(strings.HasSuffix(name.Name, "FieldNumber"), name.Name)
+				lit, ok := valueSpec.Values[i].(*ast.BasicLit)
+				r.True(ok, "field number of %s is not a literal", name.Name)
+				other, exists := fields[lit.Value]
+				r.False(exists, "%s has the same number as %s", name.Name, other)
+				fields[lit.Value] = name.Name
+			}
+		}
+	}
+	r.Len(fields, 14)
+}
@@ -126,6 +126,26 @@ func (mr *MockClientMockRecorder) EvaluateTx(ctx, in interface{}, opts ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvaluateTx", reflect.TypeOf((*MockClient)(nil).EvaluateTx), varargs...)
 }
 
+// EvaluateTxStream mocks base method.
+func (m *MockClient) EvaluateTxStream(ctx context.Context, opts ...grpc.CallOption) (agentgrpc.TxStream, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EvaluateTxStream", varargs...)
+	ret0, _ := ret[0].(agentgrpc.TxStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvaluateTxStream indicates an expected call of EvaluateTxStream.
+func (mr *MockClientMockRecorder) EvaluateTxStream(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvaluateTxStream", reflect.TypeOf((*MockClient)(nil).EvaluateTxStream), varargs...)
+}
+
 // HealthCheck mocks base method.
 func (m *MockClient) HealthCheck(ctx context.Context, in *protocol.HealthCheckRequest, opts ...grpc.CallOption) (*protocol.HealthCheckResponse, error) {
 	m.ctrl.T.Helper()
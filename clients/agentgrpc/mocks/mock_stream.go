@@ -0,0 +1,323 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: clients/agentgrpc/stream.go
+
+// Package mock_agentgrpc is a generated GoMock package.
+package mock_agentgrpc
+
+import (
+	context "context"
+	reflect "reflect"
+
+	protocol "github.com/forta-network/forta-core-go/protocol"
+	agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc"
+	gomock "github.com/golang/mock/gomock"
+	metadata "google.golang.org/grpc/metadata"
+)
+
+// MockTxStream is a mock of TxStream interface.
+type MockTxStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxStreamMockRecorder
+}
+
+// MockTxStreamMockRecorder is the mock recorder for MockTxStream.
+type MockTxStreamMockRecorder struct {
+	mock *MockTxStream
+}
+
+// NewMockTxStream creates a new mock instance.
+func NewMockTxStream(ctrl *gomock.Controller) *MockTxStream {
+	mock := &MockTxStream{ctrl: ctrl}
+	mock.recorder = &MockTxStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxStream) EXPECT() *MockTxStreamMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockTxStream) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockTxStreamMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockTxStream)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockTxStream) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockTxStreamMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockTxStream)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockTxStream) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockTxStreamMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockTxStream)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockTxStream) Recv() (*protocol.EvaluateTxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*protocol.EvaluateTxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockTxStreamMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockTxStream)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockTxStream) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockTxStreamMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockTxStream)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockTxStream) Send(arg0 *protocol.EvaluateTxRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockTxStreamMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockTxStream)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockTxStream) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockTxStreamMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockTxStream)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockTxStream) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockTxStreamMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockTxStream)(nil).Trailer))
+}
+
+// MockAgentStreamServer is a mock of AgentStreamServer interface.
+type MockAgentStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentStreamServerMockRecorder
+}
+
+// MockAgentStreamServerMockRecorder is the mock recorder for MockAgentStreamServer.
+type MockAgentStreamServerMockRecorder struct {
+	mock *MockAgentStreamServer
+}
+
+// NewMockAgentStreamServer creates a new mock instance.
+func NewMockAgentStreamServer(ctrl *gomock.Controller) *MockAgentStreamServer {
+	mock := &MockAgentStreamServer{ctrl: ctrl}
+	mock.recorder = &MockAgentStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentStreamServer) EXPECT() *MockAgentStreamServerMockRecorder {
+	return m.recorder
+}
+
+// EvaluateTxStream mocks base method.
+func (m *MockAgentStreamServer) EvaluateTxStream(arg0 agentgrpc.AgentStream_EvaluateTxStreamServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvaluateTxStream", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvaluateTxStream indicates an expected call of EvaluateTxStream.
+func (mr *MockAgentStreamServerMockRecorder) EvaluateTxStream(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvaluateTxStream", reflect.TypeOf((*MockAgentStreamServer)(nil).EvaluateTxStream), arg0)
+}
+
+// MockAgentStream_EvaluateTxStreamServer is a mock of AgentStream_EvaluateTxStreamServer interface.
+type MockAgentStream_EvaluateTxStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentStream_EvaluateTxStreamServerMockRecorder
+}
+
+// MockAgentStream_EvaluateTxStreamServerMockRecorder is the mock recorder for MockAgentStream_EvaluateTxStreamServer.
+type MockAgentStream_EvaluateTxStreamServerMockRecorder struct {
+	mock *MockAgentStream_EvaluateTxStreamServer
+}
+
+// NewMockAgentStream_EvaluateTxStreamServer creates a new mock instance.
+func NewMockAgentStream_EvaluateTxStreamServer(ctrl *gomock.Controller) *MockAgentStream_EvaluateTxStreamServer {
+	mock := &MockAgentStream_EvaluateTxStreamServer{ctrl: ctrl}
+	mock.recorder = &MockAgentStream_EvaluateTxStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentStream_EvaluateTxStreamServer) EXPECT() *MockAgentStream_EvaluateTxStreamServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) Recv() (*protocol.EvaluateTxRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*protocol.EvaluateTxRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgentStream_EvaluateTxStreamServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) Send(arg0 *protocol.EvaluateTxResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgentStream_EvaluateTxStreamServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAgentStream_EvaluateTxStreamServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAgentStream_EvaluateTxStreamServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgentStream_EvaluateTxStreamServer)(nil).SetTrailer), arg0)
+}
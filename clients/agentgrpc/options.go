@@ -0,0 +1,104 @@
+package agentgrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+// DialOptions contains the options of the bot connections. The zero values fall back to the
+// default gRPC options. The compression is the preferred compressor, which is used only with
+// the bots that support it.
+type DialOptions struct {
+	// the connections are not pinged if zero
+	KeepaliveTime       time.Duration
+	KeepaliveTimeout    time.Duration
+	MaxRecvMsgSize      int
+	MaxSendMsgSize      int
+	Compression         string
+	MaxReconnectBackoff time.Duration
+	// intercept the requests and the streams - nil sends them as they are
+	UnaryInterceptor  grpc.UnaryClientInterceptor
+	StreamInterceptor grpc.StreamClientInterceptor
+}
+
+// DialOptionsFromConfig creates the dial options from the config.
+func DialOptionsFromConfig(cfg config.AgentGrpcConfig) DialOptions {
+	return DialOptions{
+		KeepaliveTime:       time.Duration(cfg.KeepaliveSeconds) * time.Second,
+		KeepaliveTimeout:    time.Duration(cfg.KeepaliveTimeoutSeconds) * time.Second,
+		MaxRecvMsgSize:      cfg.MaxRecvMsgSizeBytes,
+		MaxSendMsgSize:      cfg.MaxSendMsgSizeBytes,
+		Compression:         cfg.Compression,
+		MaxReconnectBackoff: time.Duration(cfg.MaxReconnectBackoffSeconds) * time.Second,
+	}
+}
+
+func (opts DialOptions) dialOptions() []grpc.DialOption {
+	dialOpts := []grpc.DialOption{grpc.WithDefaultCallOptions(opts.callOptions()...)}
+	if opts.KeepaliveTime > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    opts.KeepaliveTime,
+			Timeout: opts.KeepaliveTimeout,
+			// the bots are pinged also while they are idle, so that the next request does not fail
+			PermitWithoutStream: true,
+		}))
+	}
+	if opts.MaxReconnectBackoff > 0 {
+		backoffCfg := backoff.DefaultConfig
+		backoffCfg.MaxDelay = opts.MaxReconnectBackoff
+		dialOpts = append(dialOpts, grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoffCfg}))
+	}
+	if opts.UnaryInterceptor != nil {
+		dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(opts.UnaryInterceptor))
+	}
+	if opts.StreamInterceptor != nil {
+		dialOpts = append(dialOpts, grpc.WithStreamInterceptor(opts.StreamInterceptor))
+	}
+	return dialOpts
+}
+
+func (opts DialOptions) callOptions() []grpc.CallOption {
+	callOpts := DefaultCallOptions()
+	if opts.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(opts.MaxRecvMsgSize))
+	}
+	if opts.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(opts.MaxSendMsgSize))
+	}
+	return callOpts
+}
+
+// StateWatcher is implemented by the clients which connect to the bots over the network.
+type StateWatcher interface {
+	// WatchState calls the func with the new state whenever the connection state changes, until
+	// the context is done or the connection is closed.
+	WatchState(ctx context.Context, onChange func(connectivity.State))
+}
+
+// WatchState implements StateWatcher. The idle connections are reconnected right away, so that
+// the next request does not wait for the connection.
+func (client *client) WatchState(ctx context.Context, onChange func(connectivity.State)) {
+	if client.conn == nil {
+		return
+	}
+	state := client.conn.GetState()
+	for {
+		if state == connectivity.Idle {
+			client.conn.Connect()
+		}
+		if !client.conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		state = client.conn.GetState()
+		onChange(state)
+		if state == connectivity.Shutdown {
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package agentgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestDialOptions(t *testing.T) {
+	r := require.New(t)
+
+	opts := DialOptionsFromConfig(config.AgentGrpcConfig{
+		KeepaliveSeconds:           30,
+		KeepaliveTimeoutSeconds:    10,
+		MaxRecvMsgSizeBytes:        1000,
+		MaxSendMsgSizeBytes:        2000,
+		Compression:                "gzip",
+		MaxReconnectBackoffSeconds: 5,
+	})
+	r.Equal(30*time.Second, opts.KeepaliveTime)
+	r.Equal(5*time.Second, opts.MaxReconnectBackoff)
+	// the default options and the recv size and the send size - the compressor is negotiated with each bot
+	r.Len(opts.callOptions(), len(DefaultCallOptions())+2)
+	// the call options, the keepalive and the backoff
+	r.Len(opts.dialOptions(), 3)
+
+	r.Len(DialOptions{}.callOptions(), len(DefaultCallOptions()))
+	r.Len(DialOptions{}.dialOptions(), 1)
+}
+
+func TestClient_WatchState(t *testing.T) {
+	r := require.New(t)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	opts := DialOptions{KeepaliveTime: time.Second, KeepaliveTimeout: time.Second}
+	conn, err := grpc.Dial(
+		"bufnet",
+		append([]grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}, opts.dialOptions()...)...,
+	)
+	r.NoError(err)
+	client := NewClient()
+	client.WithConn(conn)
+
+	states := make(chan connectivity.State, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.WatchState(context.Background(), func(state connectivity.State) {
+			states <- state
+		})
+	}()
+
+	// the idle connection is connected without any requests
+	waitForState(t, states, connectivity.Ready)
+
+	r.NoError(client.Close())
+	waitForState(t, states, connectivity.Shutdown)
+	<-done
+}
+
+func waitForState(t *testing.T, states <-chan connectivity.State, expected connectivity.State) {
+	for {
+		select {
+		case state := <-states:
+			if state == expected {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("connection state is not %s", expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package agentgrpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// AgentScheduleServiceName is the name of the gRPC service which the bots can implement
+// to be evaluated periodically.
+const AgentScheduleServiceName = "network.forta.AgentSchedule"
+
+// Agent gRPC schedule methods
+//
+// The scheduled evaluations reuse the block messages, so that the bots do not need new definitions:
+//
+//	service AgentSchedule { rpc EvaluateSchedule(EvaluateBlockRequest) returns (EvaluateBlockResponse); }
+//
+// The block event of the request is the latest block of the chain and contains the schedule as
+// JSON in field 1010, which the bots can read with ScheduleFromBlock.
+const (
+	MethodEvaluateSchedule Method = "/network.forta.AgentSchedule/EvaluateSchedule"
+)
+
+// Schedule tells the bots why the block was sent to them.
+type Schedule struct {
+	// the interval which the bots are evaluated at
+	IntervalSeconds int `json:"intervalSeconds"`
+	// the time of the evaluation in RFC3339 format
+	Time string `json:"time"`
+}
+
+// AttachSchedule adds the schedule to the block event message.
+func AttachSchedule(msg *protocol.BlockEvent, schedule *Schedule) error {
+	b, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to encode the schedule: %v", err)
+	}
+	appendUnknownBytes(msg.ProtoReflect(), ScheduleFieldNumber, b)
+	return nil
+}
+
+// ScheduleFromBlock reads the schedule from the block event message. It returns nil if the block
+// is not from a scheduled evaluation.
+func ScheduleFromBlock(msg *protocol.BlockEvent) (*Schedule, error) {
+	b, found, err := findUnknownBytes(msg.ProtoReflect(), ScheduleFieldNumber)
+	if err != nil || !found {
+		return nil, err
+	}
+	var schedule Schedule
+	if err := json.Unmarshal(b, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to decode the schedule: %v", err)
+	}
+	return &schedule, nil
+}
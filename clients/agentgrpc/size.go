@@ -0,0 +1,129 @@
+package agentgrpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Oversize policies decide what happens to the tx requests which exceed the max send size.
+const (
+	OversizeTruncate = "truncate"
+	OversizeDrop     = "drop"
+)
+
+// The parts of the transaction events which are truncated, in the order of truncation.
+const (
+	TruncatedTraces = "traces"
+	// the fields which the node adds to the events out of the protocol definitions
+	TruncatedExtensions = "extensions"
+	TruncatedLogs       = "logs"
+)
+
+// SizeLimits decide how the requests are fitted into the max send size of the bot connections.
+// The zero value does not limit the requests.
+type SizeLimits struct {
+	MaxSendMsgSize int
+	OversizePolicy string
+}
+
+// SizeLimitsFromConfig creates the size limits from the config.
+func SizeLimitsFromConfig(cfg config.AgentGrpcConfig) SizeLimits {
+	return SizeLimits{
+		MaxSendMsgSize: cfg.MaxSendMsgSizeBytes,
+		OversizePolicy: cfg.OversizePolicy,
+	}
+}
+
+// LimitTxRequest returns the request as it is if it fits into the max send size. Otherwise, it
+// returns a copy of the request without the traces, then also without the extensions and then
+// also without the logs, until the copy fits. The truncated parts are flagged in the event of
+// the copy. It returns nil if the policy is to drop the oversized requests or nothing fits.
+func (limits SizeLimits) LimitTxRequest(req *protocol.EvaluateTxRequest) (*protocol.EvaluateTxRequest, []string) {
+	if limits.MaxSendMsgSize <= 0 || proto.Size(req) <= limits.MaxSendMsgSize {
+		return req, nil
+	}
+	if limits.OversizePolicy == OversizeDrop {
+		return nil, nil
+	}
+
+	limited := proto.Clone(req).(*protocol.EvaluateTxRequest)
+	if limited.Event == nil {
+		return nil, nil
+	}
+	event := limited.Event
+	steps := []struct {
+		part     string
+		truncate func()
+	}{
+		{TruncatedTraces, func() { event.Traces = nil }},
+		{TruncatedExtensions, func() { event.ProtoReflect().SetUnknown(nil) }},
+		{TruncatedLogs, func() {
+			event.Logs = nil
+			if event.Receipt != nil {
+				event.Receipt.Logs = nil
+			}
+		}},
+	}
+	var truncated []string
+	for _, step := range steps {
+		step.truncate()
+		truncated = append(truncated, step.part)
+
+		unknown := event.ProtoReflect().GetUnknown()
+		b, err := json.Marshal(truncated)
+		if err != nil {
+			return nil, truncated
+		}
+		appendUnknownBytes(event.ProtoReflect(), TruncatedFieldNumber, b)
+		if proto.Size(limited) <= limits.MaxSendMsgSize {
+			return limited, truncated
+		}
+		event.ProtoReflect().SetUnknown(unknown)
+	}
+	return nil, truncated
+}
+
+// SplitTxBatch splits the requests into the consecutive batches which fit into the max send size.
+// The requests which exceed the max size alone are in their own batches.
+func (limits SizeLimits) SplitTxBatch(requests []*protocol.EvaluateTxRequest) [][]*protocol.EvaluateTxRequest {
+	if limits.MaxSendMsgSize <= 0 {
+		return [][]*protocol.EvaluateTxRequest{requests}
+	}
+	var (
+		batches [][]*protocol.EvaluateTxRequest
+		batch   []*protocol.EvaluateTxRequest
+		size    int
+	)
+	for _, req := range requests {
+		reqSize := protowire.SizeTag(batchFieldNumber) + protowire.SizeBytes(proto.Size(req))
+		if len(batch) > 0 && size+reqSize > limits.MaxSendMsgSize {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, req)
+		size += reqSize
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// TruncatedFromEvent reads the truncated parts of the event. It returns nil if the event is
+// not truncated.
+func TruncatedFromEvent(event *protocol.TransactionEvent) ([]string, error) {
+	b, found, err := findUnknownBytes(event.ProtoReflect(), TruncatedFieldNumber)
+	if err != nil || !found {
+		return nil, err
+	}
+	var truncated []string
+	if err := json.Unmarshal(b, &truncated); err != nil {
+		return nil, fmt.Errorf("failed to decode the truncated parts: %v", err)
+	}
+	return truncated, nil
+}
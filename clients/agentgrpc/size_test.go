@@ -0,0 +1,80 @@
+package agentgrpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func testSizedTxRequest(traces, extensions, logs int) *protocol.EvaluateTxRequest {
+	event := &protocol.TransactionEvent{
+		Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0x1"},
+		Traces:      []*protocol.TransactionEvent_Trace{{Type: strings.Repeat("a", traces)}},
+		Logs:        []*protocol.TransactionEvent_Log{{Data: strings.Repeat("b", logs)}},
+	}
+	appendUnknownBytes(event.ProtoReflect(), 1004, []byte(strings.Repeat("c", extensions)))
+	return &protocol.EvaluateTxRequest{RequestId: "1", Event: event}
+}
+
+func TestLimitTxRequest(t *testing.T) {
+	r := require.New(t)
+
+	limits := SizeLimits{MaxSendMsgSize: 1000, OversizePolicy: OversizeTruncate}
+
+	// the requests which fit are not copied
+	req := testSizedTxRequest(100, 100, 100)
+	limited, truncated := limits.LimitTxRequest(req)
+	r.True(req == limited)
+	r.Nil(truncated)
+
+	// the traces are truncated first
+	req = testSizedTxRequest(2000, 100, 100)
+	limited, truncated = limits.LimitTxRequest(req)
+	r.Equal([]string{TruncatedTraces}, truncated)
+	r.Empty(limited.Event.Traces)
+	r.Len(limited.Event.Logs, 1)
+	r.LessOrEqual(proto.Size(limited), limits.MaxSendMsgSize)
+	flagged, err := TruncatedFromEvent(limited.Event)
+	r.NoError(err)
+	r.Equal(truncated, flagged)
+	// the original request does not change
+	r.Len(req.Event.Traces, 1)
+
+	// then the extensions and the logs
+	limited, truncated = limits.LimitTxRequest(testSizedTxRequest(2000, 2000, 2000))
+	r.Equal([]string{TruncatedTraces, TruncatedExtensions, TruncatedLogs}, truncated)
+	r.Empty(limited.Event.Logs)
+	flagged, err = TruncatedFromEvent(limited.Event)
+	r.NoError(err)
+	r.Equal(truncated, flagged)
+
+	// the requests are dropped if nothing fits
+	limits.MaxSendMsgSize = 10
+	limited, _ = limits.LimitTxRequest(testSizedTxRequest(100, 100, 100))
+	r.Nil(limited)
+
+	limits = SizeLimits{MaxSendMsgSize: 1000, OversizePolicy: OversizeDrop}
+	limited, _ = limits.LimitTxRequest(testSizedTxRequest(2000, 100, 100))
+	r.Nil(limited)
+}
+
+func TestSplitTxBatch(t *testing.T) {
+	r := require.New(t)
+
+	small := testSizedTxRequest(100, 0, 0)
+	large := testSizedTxRequest(2000, 0, 0)
+	limits := SizeLimits{MaxSendMsgSize: 3 * proto.Size(small)}
+
+	batches := limits.SplitTxBatch([]*protocol.EvaluateTxRequest{small, small, small, large, small})
+	r.Len(batches, 4)
+	r.Len(batches[0], 2)
+	r.Len(batches[1], 1)
+	r.Len(batches[2], 1)
+	r.True(batches[2][0] == large)
+	r.Len(batches[3], 1)
+
+	r.Len(SizeLimits{}.SplitTxBatch([]*protocol.EvaluateTxRequest{small, large}), 1)
+}
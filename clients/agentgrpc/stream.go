@@ -0,0 +1,118 @@
+package agentgrpc
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AgentStreamServiceName is the name of the gRPC service which the bots can implement
+// to receive the evaluation requests through long-lived streams.
+const AgentStreamServiceName = "network.forta.AgentStream"
+
+// Agent gRPC streaming methods
+const (
+	MethodEvaluateTxStream Method = "/network.forta.AgentStream/EvaluateTxStream"
+)
+
+var evaluateTxStreamDesc = grpc.StreamDesc{
+	StreamName:    "EvaluateTxStream",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// TxStream is a bidirectional stream which carries one tx evaluation response
+// for each request, in the same order with the requests.
+type TxStream interface {
+	Send(*protocol.EvaluateTxRequest) error
+	Recv() (*protocol.EvaluateTxResponse, error)
+	grpc.ClientStream
+}
+
+type txStream struct {
+	grpc.ClientStream
+}
+
+func (stream *txStream) Send(req *protocol.EvaluateTxRequest) error {
+	return stream.ClientStream.SendMsg(req)
+}
+
+func (stream *txStream) Recv() (*protocol.EvaluateTxResponse, error) {
+	resp := new(protocol.EvaluateTxResponse)
+	if err := stream.ClientStream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EvaluateTxStream opens a new tx evaluation stream. The bots that do not implement
+// the streaming service fail the first request on the stream with codes.Unimplemented.
+func (client *client) EvaluateTxStream(ctx context.Context, opts ...grpc.CallOption) (TxStream, error) {
+	stream, err := client.conn.NewStream(ctx, &evaluateTxStreamDesc, string(MethodEvaluateTxStream), client.callOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return &txStream{ClientStream: stream}, nil
+}
+
+// AgentStreamServer is the server API for the agent streaming service.
+type AgentStreamServer interface {
+	EvaluateTxStream(AgentStream_EvaluateTxStreamServer) error
+}
+
+// AgentStream_EvaluateTxStreamServer is the server side of the tx evaluation stream.
+type AgentStream_EvaluateTxStreamServer interface {
+	Send(*protocol.EvaluateTxResponse) error
+	Recv() (*protocol.EvaluateTxRequest, error)
+	grpc.ServerStream
+}
+
+type evaluateTxStreamServer struct {
+	grpc.ServerStream
+}
+
+func (stream *evaluateTxStreamServer) Send(resp *protocol.EvaluateTxResponse) error {
+	return stream.ServerStream.SendMsg(resp)
+}
+
+func (stream *evaluateTxStreamServer) Recv() (*protocol.EvaluateTxRequest, error) {
+	req := new(protocol.EvaluateTxRequest)
+	if err := stream.ServerStream.RecvMsg(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// UnimplementedAgentStreamServer can be embedded to have forward compatible implementations.
+type UnimplementedAgentStreamServer struct{}
+
+// EvaluateTxStream implements AgentStreamServer.
+func (UnimplementedAgentStreamServer) EvaluateTxStream(AgentStream_EvaluateTxStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method EvaluateTxStream not implemented")
+}
+
+func evaluateTxStreamHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentStreamServer).EvaluateTxStream(&evaluateTxStreamServer{ServerStream: stream})
+}
+
+// AgentStreamServiceDesc is the grpc.ServiceDesc for the agent streaming service.
+var AgentStreamServiceDesc = grpc.ServiceDesc{
+	ServiceName: AgentStreamServiceName,
+	HandlerType: (*AgentStreamServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    evaluateTxStreamDesc.StreamName,
+			Handler:       evaluateTxStreamHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+// RegisterAgentStreamServer registers the agent streaming service to a gRPC server.
+func RegisterAgentStreamServer(s grpc.ServiceRegistrar, srv AgentStreamServer) {
+	s.RegisterService(&AgentStreamServiceDesc, srv)
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
+	"os"
 
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type AgentServer struct {
@@ -32,6 +38,46 @@ func (as *AgentServer) EvaluateBlock(context.Context, *protocol.EvaluateBlockReq
 	}, nil
 }
 
+func (as *AgentServer) EvaluateTxStream(stream agentgrpc.AgentStream_EvaluateTxStreamServer) error {
+	for {
+		txRequest, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		txResponse, err := as.EvaluateTx(stream.Context(), txRequest)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(txResponse); err != nil {
+			return err
+		}
+	}
+}
+
+// loadTLSCredentials loads the bot cert and requires the node cert issued by the same CA.
+func loadTLSCredentials(certFile string) credentials.TransportCredentials {
+	cert, err := tls.LoadX509KeyPair(certFile, os.Getenv(config.EnvAgentTLSKeyFile))
+	if err != nil {
+		panic(err)
+	}
+	caPEM, err := os.ReadFile(os.Getenv(config.EnvAgentTLSCAFile))
+	if err != nil {
+		panic(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		panic("invalid ca cert")
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+}
+
 func main() {
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", config.AgentGrpcPort))
 	if err != nil {
@@ -39,8 +85,13 @@ func main() {
 	}
 	defer lis.Close()
 
-	server := grpc.NewServer()
+	var opts []grpc.ServerOption
+	if certFile := os.Getenv(config.EnvAgentTLSCertFile); len(certFile) > 0 {
+		opts = append(opts, grpc.Creds(loadTLSCredentials(certFile)))
+	}
+	server := grpc.NewServer(opts...)
 	as := &AgentServer{}
 	protocol.RegisterAgentServer(server, as)
+	agentgrpc.RegisterAgentStreamServer(server, as)
 	server.Serve(lis)
 }
@@ -0,0 +1,171 @@
+package agenthttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxResponseSize is the same with the response size limit of the gRPC bots.
+const maxResponseSize = 250000
+
+// the paths which the requests are posted to under the endpoint of the bot
+const (
+	PathInitialize    = "/initialize"
+	PathEvaluateTx    = "/evaluateTx"
+	PathEvaluateBlock = "/evaluateBlock"
+	PathEvaluateAlert = "/evaluateAlert"
+	PathHealthCheck   = "/healthCheck"
+)
+
+var methodPaths = map[agentgrpc.Method]string{
+	agentgrpc.MethodInitialize:    PathInitialize,
+	agentgrpc.MethodEvaluateTx:    PathEvaluateTx,
+	agentgrpc.MethodEvaluateBlock: PathEvaluateBlock,
+	agentgrpc.MethodEvaluateAlert: PathEvaluateAlert,
+	agentgrpc.MethodHealthCheck:   PathHealthCheck,
+}
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// client posts the requests to a bot which is an HTTP service. It implements the same interface
+// with the gRPC client so that the HTTP bots are processed like the other bots. The requests and
+// the responses are the JSON encodings of the protocol messages.
+type client struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewClient creates a new client which posts the requests to the endpoint.
+func NewClient(endpoint string, httpClient *http.Client) *client {
+	return &client{endpoint: strings.TrimSuffix(endpoint, "/"), httpClient: httpClient}
+}
+
+var _ agentgrpc.Client = &client{}
+
+// DialWithRetry implements agentgrpc.Client. The requests are sent over new or idle connections.
+func (client *client) DialWithRetry(config.AgentConfig) error {
+	return nil
+}
+
+// Invoke encodes the request as JSON, posts it to the path of the method and decodes the response.
+// The errors have the gRPC status codes, so that the bot client handles the timeouts and the retries
+// like it does for the gRPC bots.
+func (client *client) Invoke(ctx context.Context, method agentgrpc.Method, in, out interface{}, opts ...grpc.CallOption) error {
+	path, ok := methodPaths[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "method %s is not supported by http bots", method)
+	}
+	inMsg, ok1 := in.(proto.Message)
+	outMsg, ok2 := out.(proto.Message)
+	if !ok1 || !ok2 {
+		return status.Errorf(codes.Internal, "unexpected message types for %s", method)
+	}
+	body, err := protojson.Marshal(inMsg)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode the request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create the request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.httpClient.Do(req)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case err != nil:
+		return status.Errorf(codes.Unavailable, "failed to post %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	output, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Errorf(codes.Unavailable, "failed to read the response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return status.Errorf(statusCode(resp.StatusCode), "bot responded to %s with %d: %s", path, resp.StatusCode, bytes.TrimSpace(output))
+	}
+	if len(output) > maxResponseSize {
+		return status.Errorf(codes.ResourceExhausted, "response is too large: %d > %d", len(output), maxResponseSize)
+	}
+	if err := unmarshalOptions.Unmarshal(output, outMsg); err != nil {
+		return status.Errorf(codes.Internal, "failed to decode the response: %v", err)
+	}
+	return nil
+}
+
+// statusCode converts the HTTP status code to the gRPC status code.
+func statusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// EvaluateTxStream implements agentgrpc.Client. The bot client falls back to the unary calls.
+func (client *client) EvaluateTxStream(context.Context, ...grpc.CallOption) (agentgrpc.TxStream, error) {
+	return nil, status.Error(codes.Unimplemented, "http bots do not support tx streams")
+}
+
+// Initialize implements protocol.AgentClient.
+func (client *client) Initialize(ctx context.Context, in *protocol.InitializeRequest, opts ...grpc.CallOption) (*protocol.InitializeResponse, error) {
+	out := new(protocol.InitializeResponse)
+	return out, client.Invoke(ctx, agentgrpc.MethodInitialize, in, out, opts...)
+}
+
+// EvaluateTx implements protocol.AgentClient.
+func (client *client) EvaluateTx(ctx context.Context, in *protocol.EvaluateTxRequest, opts ...grpc.CallOption) (*protocol.EvaluateTxResponse, error) {
+	out := new(protocol.EvaluateTxResponse)
+	return out, client.Invoke(ctx, agentgrpc.MethodEvaluateTx, in, out, opts...)
+}
+
+// EvaluateBlock implements protocol.AgentClient.
+func (client *client) EvaluateBlock(ctx context.Context, in *protocol.EvaluateBlockRequest, opts ...grpc.CallOption) (*protocol.EvaluateBlockResponse, error) {
+	out := new(protocol.EvaluateBlockResponse)
+	return out, client.Invoke(ctx, agentgrpc.MethodEvaluateBlock, in, out, opts...)
+}
+
+// EvaluateAlert implements protocol.AgentClient.
+func (client *client) EvaluateAlert(ctx context.Context, in *protocol.EvaluateAlertRequest, opts ...grpc.CallOption) (*protocol.EvaluateAlertResponse, error) {
+	out := new(protocol.EvaluateAlertResponse)
+	return out, client.Invoke(ctx, agentgrpc.MethodEvaluateAlert, in, out, opts...)
+}
+
+// HealthCheck implements protocol.AgentClient.
+func (client *client) HealthCheck(ctx context.Context, in *protocol.HealthCheckRequest, opts ...grpc.CallOption) (*protocol.HealthCheckResponse, error) {
+	out := new(protocol.HealthCheckResponse)
+	return out, client.Invoke(ctx, agentgrpc.MethodHealthCheck, in, out, opts...)
+}
+
+// Close implements io.Closer. The connections are shared with the other HTTP bots.
+func (client *client) Close() error {
+	return nil
+}
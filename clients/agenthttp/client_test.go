@@ -0,0 +1,83 @@
+package agenthttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testBot responds to the tx requests with a finding which has the request ID.
+func testBot(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/bot" + PathEvaluateTx:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			w.Write([]byte(`{"status": "SUCCESS", "findings": [{"alertId": "` + body["requestId"].(string) + `", "severity": "HIGH"}], "unknownField": 1}`))
+		case "/bot" + PathEvaluateBlock:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/bot" + PathHealthCheck:
+			time.Sleep(time.Second)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+}
+
+func TestBotDialer(t *testing.T) {
+	r := require.New(t)
+
+	server := testBot(t)
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	next := mock_agentgrpc.NewMockBotDialer(ctrl)
+	grpcBot := config.AgentConfig{ID: "0x1"}
+	next.EXPECT().DialBot(grpcBot).Return(nil, errors.New("not running"))
+
+	dialer := NewBotDialer(next, server.Client())
+	_, err := dialer.DialBot(grpcBot)
+	r.Error(err)
+
+	_, err = dialer.DialBot(config.AgentConfig{ID: "0x2", Protocol: config.BotProtocolHTTP, Endpoint: "localhost:8080"})
+	r.Error(err)
+
+	botClient, err := dialer.DialBot(config.AgentConfig{ID: "0x2", Protocol: config.BotProtocolHTTP, Endpoint: server.URL + "/bot/"})
+	r.NoError(err)
+
+	resp, err := botClient.EvaluateTx(context.Background(), &protocol.EvaluateTxRequest{RequestId: "req-1"})
+	r.NoError(err)
+	r.Equal(protocol.ResponseStatus_SUCCESS, resp.Status)
+	r.Equal("req-1", resp.Findings[0].AlertId)
+	r.Equal(protocol.Finding_HIGH, resp.Findings[0].Severity)
+
+	// the http errors have the status codes which the bot client retries
+	_, err = botClient.EvaluateBlock(context.Background(), &protocol.EvaluateBlockRequest{})
+	r.Equal(codes.Unavailable, status.Code(err))
+
+	// the request timeouts are handled like the gRPC timeouts
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = botClient.HealthCheck(ctx, &protocol.HealthCheckRequest{})
+	r.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	// the bots which do not serve a path are handled like the gRPC bots which do not implement a method
+	_, err = botClient.EvaluateAlert(context.Background(), &protocol.EvaluateAlertRequest{})
+	r.Equal(codes.Unimplemented, status.Code(err))
+	_, err = botClient.EvaluateTxStream(context.Background())
+	r.Equal(codes.Unimplemented, status.Code(err))
+
+	r.NoError(botClient.Close())
+}
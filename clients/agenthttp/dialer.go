@@ -0,0 +1,40 @@
+package agenthttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+type botDialer struct {
+	next       agentgrpc.BotDialer
+	httpClient *http.Client
+}
+
+// NewBotDialer creates a dialer which creates the clients of the HTTP bots and dials the other
+// bots with the next dialer. The request timeouts come from the contexts of the requests.
+func NewBotDialer(next agentgrpc.BotDialer, httpClient *http.Client) agentgrpc.BotDialer {
+	return &botDialer{next: next, httpClient: httpClient}
+}
+
+func (bd *botDialer) DialBot(ac config.AgentConfig) (agentgrpc.Client, error) {
+	if !ac.IsHTTP() {
+		return bd.next.DialBot(ac)
+	}
+	endpoint, err := url.Parse(ac.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the endpoint of http bot '%s': %v", ac.ID, err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("endpoint of http bot '%s' should be an http or https url", ac.ID)
+	}
+	log.WithFields(log.Fields{
+		"bot":  ac.ID,
+		"host": endpoint.Host,
+	}).Info("using http bot")
+	return NewClient(ac.Endpoint, bd.httpClient), nil
+}
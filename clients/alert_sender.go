@@ -2,6 +2,8 @@ package clients
 
 import (
 	"context"
+	"time"
+
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
@@ -45,7 +47,8 @@ type AlertSenderConfig struct {
 
 func (a *alertSender) SignAlertAndNotify(rt *AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps) error {
 	logger := log.WithFields(log.Fields{
-		"alert": alert.Id,
+		"alert":   alert.Id,
+		"request": alert.Tags["requestId"],
 	})
 
 	// only if configured (for local mode redundancy)
@@ -58,6 +61,13 @@ func (a *alertSender) SignAlertAndNotify(rt *AgentRoundTrip, alert *protocol.Ale
 			return nil
 		}
 	}
+	// the alert is handed to the publisher now, so the latency breakdown is final
+	if alert.Metadata == nil {
+		alert.Metadata = make(map[string]string)
+	}
+	for key, value := range NewLatencyBreakdown(ts, time.Now().UTC()).Metadata() {
+		alert.Metadata[key] = value
+	}
 	alert.Scanner = &protocol.ScannerInfo{
 		Address: a.cfg.Key.Address.Hex(),
 	}
@@ -0,0 +1,216 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// DefaultBlobCacheSize is the amount of the latest blocks which the blob sidecars are kept for.
+const DefaultBlobCacheSize = 16
+
+// blobCommitmentVersionKZG is the first byte of the versioned hashes of the KZG commitments.
+const blobCommitmentVersionKZG = 0x01
+
+// BlobSidecar is a blob of a transaction with its KZG commitment and proof.
+type BlobSidecar struct {
+	Index         uint64 `json:"index,string"`
+	VersionedHash string `json:"versionedHash"`
+	KZGCommitment string `json:"kzgCommitment"`
+	KZGProof      string `json:"kzgProof"`
+	Blob          string `json:"blob"`
+}
+
+type blobSidecarsResponse struct {
+	Data []struct {
+		Index         uint64 `json:"index,string"`
+		Blob          string `json:"blob"`
+		KZGCommitment string `json:"kzg_commitment"`
+		KZGProof      string `json:"kzg_proof"`
+	} `json:"data"`
+}
+
+type genesisResponse struct {
+	Data struct {
+		GenesisTime uint64 `json:"genesis_time,string"`
+	} `json:"data"`
+}
+
+type specResponse struct {
+	Data struct {
+		SecondsPerSlot uint64 `json:"SECONDS_PER_SLOT,string"`
+	} `json:"data"`
+}
+
+// VersionedHash calculates the versioned hash of the KZG commitment, which the transactions refer to the blobs with.
+func VersionedHash(kzgCommitment string) (string, error) {
+	b, err := hexutil.Decode(kzgCommitment)
+	if err != nil {
+		return "", fmt.Errorf("invalid kzg commitment: %v", err)
+	}
+	hash := sha256.Sum256(b)
+	hash[0] = blobCommitmentVersionKZG
+	return hexutil.Encode(hash[:]), nil
+}
+
+// SlotAt returns the slot of the block timestamp. The genesis time and the slot duration are
+// requested once.
+func (c *client) SlotAt(ctx context.Context, timestamp uint64) (uint64, error) {
+	c.timingMu.Lock()
+	defer c.timingMu.Unlock()
+
+	if c.secondsPerSlot == 0 {
+		var genesis genesisResponse
+		if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+			return 0, fmt.Errorf("failed to get the genesis: %v", err)
+		}
+		var spec specResponse
+		if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+			return 0, fmt.Errorf("failed to get the spec: %v", err)
+		}
+		if spec.Data.SecondsPerSlot == 0 {
+			return 0, errors.New("spec has no slot duration")
+		}
+		c.genesisTime, c.secondsPerSlot = genesis.Data.GenesisTime, spec.Data.SecondsPerSlot
+	}
+	if timestamp < c.genesisTime {
+		return 0, fmt.Errorf("timestamp %d is before the genesis", timestamp)
+	}
+	return (timestamp - c.genesisTime) / c.secondsPerSlot, nil
+}
+
+// BlobSidecars returns the blob sidecars of the block in the slot.
+func (c *client) BlobSidecars(ctx context.Context, slot uint64) ([]*BlobSidecar, error) {
+	var resp blobSidecarsResponse
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &resp); err != nil {
+		if errors.Is(err, ErrMissedSlot) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get the blob sidecars of slot %d: %v", slot, err)
+	}
+	sidecars := make([]*BlobSidecar, 0, len(resp.Data))
+	for _, data := range resp.Data {
+		versionedHash, err := VersionedHash(data.KZGCommitment)
+		if err != nil {
+			return nil, err
+		}
+		sidecars = append(sidecars, &BlobSidecar{
+			Index:         data.Index,
+			VersionedHash: versionedHash,
+			KZGCommitment: data.KZGCommitment,
+			KZGProof:      data.KZGProof,
+			Blob:          data.Blob,
+		})
+	}
+	return sidecars, nil
+}
+
+type blobClient interface {
+	SlotAt(ctx context.Context, timestamp uint64) (uint64, error)
+	BlobSidecars(ctx context.Context, slot uint64) ([]*BlobSidecar, error)
+}
+
+type blobEntry struct {
+	once sync.Once
+	// by the lowercase versioned hashes
+	sidecars map[string]*BlobSidecar
+	err      error
+}
+
+// BlobSource finds the blob sidecars of the transactions. The sidecars of the latest blocks are
+// cached, so that the blobs of a block are requested once for all of its transactions.
+type BlobSource struct {
+	client        blobClient
+	maxBlobsPerTx int
+	entries       *lru.Cache[uint64, *blobEntry]
+	mu            sync.Mutex
+}
+
+// NewBlobSource creates a new blob source which reads the blob sidecars from the beacon API.
+func NewBlobSource(apiURL string, maxBlobsPerTx int) *BlobSource {
+	return newBlobSource(NewClient(apiURL), maxBlobsPerTx, DefaultBlobCacheSize)
+}
+
+func newBlobSource(client blobClient, maxBlobsPerTx, cacheSize int) *BlobSource {
+	return &BlobSource{
+		client:        client,
+		maxBlobsPerTx: maxBlobsPerTx,
+		entries:       lru.NewCache[uint64, *blobEntry](cacheSize),
+	}
+}
+
+// Blobs returns the blob sidecars of the versioned hashes of a transaction in the block with the
+// timestamp, in the order of the hashes and up to the max blobs per transaction. The failures are
+// cached too, so that the transactions of a block are not slowed down by the retries.
+func (bs *BlobSource) Blobs(ctx context.Context, blockTimestamp uint64, versionedHashes []string) ([]*BlobSidecar, error) {
+	entry := bs.getEntry(blockTimestamp)
+	entry.once.Do(func() {
+		entry.sidecars, entry.err = bs.fetch(ctx, blockTimestamp)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	var sidecars []*BlobSidecar
+	for _, versionedHash := range versionedHashes {
+		if len(sidecars) >= bs.maxBlobsPerTx {
+			break
+		}
+		sidecar, ok := entry.sidecars[strings.ToLower(versionedHash)]
+		if !ok {
+			return nil, fmt.Errorf("blob %s not found", versionedHash)
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+func (bs *BlobSource) getEntry(blockTimestamp uint64) *blobEntry {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if entry, ok := bs.entries.Get(blockTimestamp); ok {
+		return entry
+	}
+	entry := &blobEntry{}
+	bs.entries.Add(blockTimestamp, entry)
+	return entry
+}
+
+func (bs *BlobSource) fetch(ctx context.Context, blockTimestamp uint64) (map[string]*BlobSidecar, error) {
+	slot, err := bs.client.SlotAt(ctx, blockTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := bs.client.BlobSidecars(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	byHash := make(map[string]*BlobSidecar)
+	for _, sidecar := range sidecars {
+		byHash[strings.ToLower(sidecar.VersionedHash)] = sidecar
+	}
+	return byHash, nil
+}
+
+// AttachBlobsToTx adds the blob sidecars to the transaction event message as an unknown field.
+func AttachBlobsToTx(msg *protocol.TransactionEvent, sidecars []*BlobSidecar) error {
+	b, err := json.Marshal(sidecars)
+	if err != nil {
+		return fmt.Errorf("failed to encode the blob sidecars: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.BlobSidecarsFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
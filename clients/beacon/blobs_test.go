@@ -0,0 +1,93 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	testCommitment1 = "0x" + strings.Repeat("11", 48)
+	testCommitment2 = "0x" + strings.Repeat("22", 48)
+)
+
+func testVersionedHash(commitment string) string {
+	hash := sha256.Sum256(hexutil.MustDecode(commitment))
+	hash[0] = 0x01
+	return hexutil.Encode(hash[:])
+}
+
+func TestBlobSource(t *testing.T) {
+	r := require.New(t)
+
+	var sidecarRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			w.Write([]byte(`{"data": {"genesis_time": "1000", "genesis_fork_version": "0x00000000"}}`))
+		case "/eth/v1/config/spec":
+			w.Write([]byte(`{"data": {"SECONDS_PER_SLOT": "12", "SLOTS_PER_EPOCH": "32"}}`))
+		case "/eth/v1/beacon/blob_sidecars/10":
+			sidecarRequests++
+			w.Write([]byte(fmt.Sprintf(`{"data": [
+				{"index": "0", "blob": "0xaa", "kzg_commitment": "%s", "kzg_proof": "0x01"},
+				{"index": "1", "blob": "0xbb", "kzg_commitment": "%s", "kzg_proof": "0x02"}
+			]}`, testCommitment1, testCommitment2)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := NewBlobSource(server.URL, 1)
+
+	// the block at 1120 is in slot 10
+	sidecars, err := source.Blobs(context.Background(), 1120, []string{testVersionedHash(testCommitment2), testVersionedHash(testCommitment1)})
+	r.NoError(err)
+	// up to the max blobs
+	r.Len(sidecars, 1)
+	r.Equal(uint64(1), sidecars[0].Index)
+	r.Equal("0xbb", sidecars[0].Blob)
+	r.Equal(testVersionedHash(testCommitment2), sidecars[0].VersionedHash)
+
+	// the sidecars are cached per block
+	_, err = source.Blobs(context.Background(), 1120, []string{testVersionedHash(testCommitment1)})
+	r.NoError(err)
+	r.Equal(1, sidecarRequests)
+
+	_, err = source.Blobs(context.Background(), 1120, []string{"0x01cc"})
+	r.Error(err)
+	_, err = source.Blobs(context.Background(), 1132, []string{testVersionedHash(testCommitment1)})
+	r.ErrorIs(err, ErrMissedSlot)
+	_, err = source.Blobs(context.Background(), 900, []string{testVersionedHash(testCommitment1)})
+	r.Error(err)
+}
+
+func TestAttachBlobsToTx(t *testing.T) {
+	r := require.New(t)
+
+	sidecars := []*BlobSidecar{{Index: 1, VersionedHash: "0x01aa", KZGCommitment: "0x11", KZGProof: "0x22", Blob: "0x33"}}
+	msg := &protocol.TransactionEvent{}
+	r.NoError(AttachBlobsToTx(msg, sidecars))
+
+	unknown := msg.ProtoReflect().GetUnknown()
+	num, typ, n := protowire.ConsumeTag(unknown)
+	r.Greater(n, 0)
+	r.Equal(agentgrpc.BlobSidecarsFieldNumber, num)
+	r.Equal(protowire.BytesType, typ)
+	b, _ := protowire.ConsumeBytes(unknown[n:])
+	var decoded []*BlobSidecar
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal(sidecars, decoded)
+}
@@ -0,0 +1,145 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+)
+
+// ErrMissedSlot is returned when there is no block in the slot.
+var ErrMissedSlot = errors.New("missed slot")
+
+// Client gets the consensus-layer events from the beacon API of a consensus client.
+type Client interface {
+	// HeadSlot returns the slot of the latest block.
+	HeadSlot(ctx context.Context) (uint64, error)
+	// Event returns the event of the slot or ErrMissedSlot.
+	Event(ctx context.Context, slot uint64) (*Event, error)
+}
+
+type client struct {
+	apiURL string
+
+	// the slot timing of the chain, to find the slots of the execution blocks
+	genesisTime    uint64
+	secondsPerSlot uint64
+	timingMu       sync.Mutex
+}
+
+// NewClient creates a new beacon API client.
+func NewClient(apiURL string) *client {
+	return &client{apiURL: strings.TrimSuffix(apiURL, "/")}
+}
+
+type headerResponse struct {
+	Data struct {
+		Root   string `json:"root"`
+		Header struct {
+			Message *BeaconBlockHeader `json:"message"`
+		} `json:"header"`
+	} `json:"data"`
+}
+
+type blockResponse struct {
+	Data struct {
+		Message struct {
+			Slot          uint64 `json:"slot,string"`
+			ProposerIndex uint64 `json:"proposer_index,string"`
+			ParentRoot    string `json:"parent_root"`
+			StateRoot     string `json:"state_root"`
+			Body          struct {
+				Attestations      []*Attestation      `json:"attestations"`
+				ProposerSlashings []*ProposerSlashing `json:"proposer_slashings"`
+				AttesterSlashings []*AttesterSlashing `json:"attester_slashings"`
+				VoluntaryExits    []struct {
+					Message *VoluntaryExit `json:"message"`
+				} `json:"voluntary_exits"`
+				// missing before the merge
+				ExecutionPayload *struct {
+					BlockNumber uint64 `json:"block_number,string"`
+					BlockHash   string `json:"block_hash"`
+					Timestamp   uint64 `json:"timestamp,string"`
+				} `json:"execution_payload"`
+			} `json:"body"`
+		} `json:"message"`
+	} `json:"data"`
+}
+
+func (c *client) HeadSlot(ctx context.Context) (uint64, error) {
+	var resp headerResponse
+	if err := c.get(ctx, "/eth/v1/beacon/headers/head", &resp); err != nil {
+		return 0, fmt.Errorf("failed to get the head header: %v", err)
+	}
+	if resp.Data.Header.Message == nil {
+		return 0, errors.New("empty head header")
+	}
+	return resp.Data.Header.Message.Slot, nil
+}
+
+func (c *client) Event(ctx context.Context, slot uint64) (*Event, error) {
+	var header headerResponse
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/headers/%d", slot), &header); err != nil {
+		if errors.Is(err, ErrMissedSlot) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get the header of slot %d: %v", slot, err)
+	}
+	// get the block by the root so that the block matches the header
+	var block blockResponse
+	if err := c.get(ctx, fmt.Sprintf("/eth/v2/beacon/blocks/%s", header.Data.Root), &block); err != nil {
+		return nil, fmt.Errorf("failed to get the block of slot %d: %v", slot, err)
+	}
+
+	msg := block.Data.Message
+	evt := &Event{
+		Slot:              msg.Slot,
+		Epoch:             msg.Slot / SlotsPerEpoch,
+		BlockRoot:         header.Data.Root,
+		ParentRoot:        msg.ParentRoot,
+		StateRoot:         msg.StateRoot,
+		ProposerIndex:     msg.ProposerIndex,
+		Attestations:      msg.Body.Attestations,
+		ProposerSlashings: msg.Body.ProposerSlashings,
+		AttesterSlashings: msg.Body.AttesterSlashings,
+	}
+	for _, exit := range msg.Body.VoluntaryExits {
+		if exit.Message != nil {
+			evt.VoluntaryExits = append(evt.VoluntaryExits, exit.Message)
+		}
+	}
+	if payload := msg.Body.ExecutionPayload; payload != nil {
+		evt.ExecutionBlockNumber = payload.BlockNumber
+		evt.ExecutionBlockHash = payload.BlockHash
+		evt.Timestamp = payload.Timestamp
+	}
+	return evt, nil
+}
+
+func (c *client) get(ctx context.Context, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrMissedSlot
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with '%d': %s", resp.StatusCode, string(b))
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,103 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+const testHeaderResponse = `{
+	"data": {
+		"root": "0xroot",
+		"canonical": true,
+		"header": {
+			"message": {"slot": "65", "proposer_index": "7", "parent_root": "0xparent", "state_root": "0xstate", "body_root": "0xbody"}
+		}
+	}
+}`
+
+const testBlockResponse = `{
+	"version": "capella",
+	"data": {
+		"message": {
+			"slot": "65",
+			"proposer_index": "7",
+			"parent_root": "0xparent",
+			"state_root": "0xstate",
+			"body": {
+				"attestations": [{
+					"aggregation_bits": "0x01",
+					"data": {
+						"slot": "64",
+						"index": "1",
+						"beacon_block_root": "0xparent",
+						"source": {"epoch": "1", "root": "0xsource"},
+						"target": {"epoch": "2", "root": "0xtarget"}
+					}
+				}],
+				"proposer_slashings": [],
+				"attester_slashings": [],
+				"voluntary_exits": [{"message": {"epoch": "2", "validator_index": "100"}, "signature": "0x"}],
+				"execution_payload": {"block_number": "1000", "block_hash": "0xblock", "timestamp": "1700000000"}
+			}
+		}
+	}
+}`
+
+func TestClient(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/eth/v1/beacon/headers/head", "/eth/v1/beacon/headers/65":
+			w.Write([]byte(testHeaderResponse))
+		case "/eth/v2/beacon/blocks/0xroot":
+			w.Write([]byte(testBlockResponse))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL + "/")
+
+	slot, err := c.HeadSlot(context.Background())
+	r.NoError(err)
+	r.Equal(uint64(65), slot)
+
+	evt, err := c.Event(context.Background(), 65)
+	r.NoError(err)
+	r.Equal(uint64(65), evt.Slot)
+	r.Equal(uint64(2), evt.Epoch)
+	r.Equal("0xroot", evt.BlockRoot)
+	r.Equal(uint64(7), evt.ProposerIndex)
+	r.Len(evt.Attestations, 1)
+	r.Equal(uint64(2), evt.Attestations[0].Data.Target.Epoch)
+	r.Len(evt.VoluntaryExits, 1)
+	r.Equal(uint64(100), evt.VoluntaryExits[0].ValidatorIndex)
+	r.Equal(uint64(1000), evt.ExecutionBlockNumber)
+	r.Equal("0xblock", evt.ExecutionBlockHash)
+
+	_, err = c.Event(context.Background(), 66)
+	r.ErrorIs(err, ErrMissedSlot)
+}
+
+func TestAttachToBlock(t *testing.T) {
+	r := require.New(t)
+
+	msg := &protocol.BlockEvent{BlockHash: "0xblock"}
+	evt, err := FromBlock(msg)
+	r.NoError(err)
+	r.Nil(evt)
+
+	r.NoError(AttachToBlock(msg, &Event{Slot: 65, Epoch: 2, ProposerIndex: 7}))
+	evt, err = FromBlock(msg)
+	r.NoError(err)
+	r.Equal(uint64(65), evt.Slot)
+	r.Equal(uint64(7), evt.ProposerIndex)
+	r.Equal("0xblock", msg.BlockHash)
+}
@@ -0,0 +1,138 @@
+package beacon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SlotsPerEpoch is the amount of the slots in each epoch of the beacon chain.
+const SlotsPerEpoch = 32
+
+// Event contains the consensus-layer activity of a slot. The numbers are encoded as strings, like
+// in the beacon API.
+type Event struct {
+	Slot          uint64 `json:"slot,string"`
+	Epoch         uint64 `json:"epoch,string"`
+	BlockRoot     string `json:"blockRoot,omitempty"`
+	ParentRoot    string `json:"parentRoot,omitempty"`
+	StateRoot     string `json:"stateRoot,omitempty"`
+	ProposerIndex uint64 `json:"proposerIndex,string"`
+
+	Attestations      []*Attestation      `json:"attestations,omitempty"`
+	ProposerSlashings []*ProposerSlashing `json:"proposerSlashings,omitempty"`
+	AttesterSlashings []*AttesterSlashing `json:"attesterSlashings,omitempty"`
+	VoluntaryExits    []*VoluntaryExit    `json:"voluntaryExits,omitempty"`
+
+	// the execution-layer block of the beacon block
+	ExecutionBlockNumber uint64 `json:"executionBlockNumber,string"`
+	ExecutionBlockHash   string `json:"executionBlockHash,omitempty"`
+	Timestamp            uint64 `json:"timestamp,string"`
+}
+
+// Checkpoint is a finality checkpoint.
+type Checkpoint struct {
+	Epoch uint64 `json:"epoch,string"`
+	Root  string `json:"root"`
+}
+
+// AttestationData is the vote of an attestation.
+type AttestationData struct {
+	Slot            uint64      `json:"slot,string"`
+	Index           uint64      `json:"index,string"`
+	BeaconBlockRoot string      `json:"beacon_block_root"`
+	Source          *Checkpoint `json:"source"`
+	Target          *Checkpoint `json:"target"`
+}
+
+// Attestation is an aggregated attestation which is included in a block.
+type Attestation struct {
+	AggregationBits string           `json:"aggregation_bits"`
+	Data            *AttestationData `json:"data"`
+}
+
+// BeaconBlockHeader is a signed block header of a proposer slashing.
+type BeaconBlockHeader struct {
+	Slot          uint64 `json:"slot,string"`
+	ProposerIndex uint64 `json:"proposer_index,string"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+// SignedBeaconBlockHeader is a block header with the signature of the proposer.
+type SignedBeaconBlockHeader struct {
+	Message   *BeaconBlockHeader `json:"message"`
+	Signature string             `json:"signature"`
+}
+
+// ProposerSlashing proves that a proposer signed two different blocks for the same slot.
+type ProposerSlashing struct {
+	SignedHeader1 *SignedBeaconBlockHeader `json:"signed_header_1"`
+	SignedHeader2 *SignedBeaconBlockHeader `json:"signed_header_2"`
+}
+
+// IndexedAttestation is an attestation with the indices of the attesters.
+type IndexedAttestation struct {
+	AttestingIndices []string         `json:"attesting_indices"`
+	Data             *AttestationData `json:"data"`
+	Signature        string           `json:"signature"`
+}
+
+// AttesterSlashing proves that the attesters signed two conflicting attestations.
+type AttesterSlashing struct {
+	Attestation1 *IndexedAttestation `json:"attestation_1"`
+	Attestation2 *IndexedAttestation `json:"attestation_2"`
+}
+
+// VoluntaryExit is the request of a validator to exit.
+type VoluntaryExit struct {
+	Epoch          uint64 `json:"epoch,string"`
+	ValidatorIndex uint64 `json:"validator_index,string"`
+}
+
+// AttachToBlock adds the beacon event to the block event message as an unknown field.
+func AttachToBlock(msg *protocol.BlockEvent, evt *Event) error {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to encode the beacon event: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.BeaconEventFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// FromBlock reads the beacon event from the block event message. It returns nil if the message
+// does not have it.
+func FromBlock(msg *protocol.BlockEvent) (*Event, error) {
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == agentgrpc.BeaconEventFieldNumber && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			var evt Event
+			if err := json.Unmarshal(b, &evt); err != nil {
+				return nil, fmt.Errorf("failed to decode the beacon event: %v", err)
+			}
+			return &evt, nil
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, nil
+}
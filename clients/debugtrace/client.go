@@ -0,0 +1,191 @@
+package debugtrace
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const debugTraceBlockByNumber = "debug_traceBlockByNumber"
+
+// Trace API options
+const (
+	APITraceBlock              = "trace_block"
+	APIDebugTraceBlockByNumber = debugTraceBlockByNumber
+)
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// failoverCaller calls the trace apis in order until one of them succeeds.
+type failoverCaller []rpcCaller
+
+func (fc failoverCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) (err error) {
+	for i, caller := range fc {
+		err = caller.CallContext(ctx, result, method, args...)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"endpoint": i,
+			"method":   method,
+		}).Warn("trace api call failed - failing over")
+	}
+	return err
+}
+
+// client gets the traces by using the Geth debug API and converts them to the
+// Parity trace format so that the traces look the same to the bots.
+type client struct {
+	ethereum.Client
+	rpcClient rpcCaller
+}
+
+// NewClient wraps the given client and replaces the trace_block calls with
+// debug_traceBlockByNumber calls. The calls fail over to the next url in order.
+func NewClient(ctx context.Context, ethClient ethereum.Client, urls []string) (*client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("trace api url is required")
+	}
+	var callers failoverCaller
+	for _, url := range urls {
+		rpcClient, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial trace api: %v", err)
+		}
+		callers = append(callers, rpcClient)
+	}
+	return &client{Client: ethClient, rpcClient: callers}, nil
+}
+
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Value   *string      `json:"value"`
+	Gas     *string      `json:"gas"`
+	GasUsed *string      `json:"gasUsed"`
+	Input   *string      `json:"input"`
+	Output  *string      `json:"output"`
+	Error   *string      `json:"error"`
+	Calls   []*callFrame `json:"calls"`
+}
+
+type txTrace struct {
+	TxHash *string    `json:"txHash"`
+	Result *callFrame `json:"result"`
+	Error  *string    `json:"error"`
+}
+
+// TraceBlock gets the call traces of all transactions of the block.
+func (c *client) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	// the block is needed for the block hash and the transaction hashes
+	// as older Geth versions do not include the transaction hashes
+	block, err := c.Client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var txTraces []*txTrace
+	err = c.rpcClient.CallContext(
+		ctx, &txTraces, debugTraceBlockByNumber, hexutil.EncodeBig(number),
+		map[string]interface{}{"tracer": "callTracer"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(txTraces) != len(block.Transactions) {
+		return nil, fmt.Errorf(
+			"trace count mismatch: block has %d transactions but got %d traces",
+			len(block.Transactions), len(txTraces),
+		)
+	}
+
+	blockNumber := int(number.Int64())
+	var traces []domain.Trace
+	for i, txTrace := range txTraces {
+		if txTrace.Result == nil {
+			continue
+		}
+		txHash := block.Transactions[i].Hash
+		if txTrace.TxHash != nil && !strings.EqualFold(*txTrace.TxHash, txHash) {
+			return nil, fmt.Errorf("trace tx hash mismatch at position %d", i)
+		}
+		txPosition := i
+		traces = flattenFrame(traces, txTrace.Result, nil, domain.Trace{
+			BlockHash:           utils.StringPtr(block.Hash),
+			BlockNumber:         &blockNumber,
+			TransactionHash:     utils.StringPtr(txHash),
+			TransactionPosition: &txPosition,
+		})
+	}
+	return traces, nil
+}
+
+// flattenFrame converts the nested call frames to the traces in depth-first order.
+func flattenFrame(traces []domain.Trace, frame *callFrame, traceAddress []int, base domain.Trace) []domain.Trace {
+	trace := base
+	trace.TraceAddress = append([]int{}, traceAddress...)
+	trace.Subtraces = len(frame.Calls)
+	trace.Error = frame.Error
+
+	frameType := strings.ToUpper(frame.Type)
+	switch frameType {
+	case "CREATE", "CREATE2":
+		trace.Type = "create"
+		trace.Action = domain.TraceAction{
+			From:  utils.StringPtr(frame.From),
+			Value: frame.Value,
+			Gas:   frame.Gas,
+			Init:  frame.Input,
+		}
+		if frame.Error == nil {
+			trace.Result = &domain.TraceResult{
+				Address: utils.StringPtr(frame.To),
+				Code:    frame.Output,
+				GasUsed: frame.GasUsed,
+			}
+		}
+	case "SELFDESTRUCT":
+		trace.Type = "suicide"
+		trace.Action = domain.TraceAction{
+			Address:       utils.StringPtr(frame.From),
+			RefundAddress: utils.StringPtr(frame.To),
+			Balance:       frame.Value,
+		}
+	default:
+		trace.Type = "call"
+		trace.Action = domain.TraceAction{
+			CallType: utils.StringPtr(strings.ToLower(frameType)),
+			From:     utils.StringPtr(frame.From),
+			To:       utils.StringPtr(frame.To),
+			Value:    frame.Value,
+			Gas:      frame.Gas,
+			Input:    frame.Input,
+		}
+		if frame.Error == nil {
+			trace.Result = &domain.TraceResult{
+				Output:  frame.Output,
+				GasUsed: frame.GasUsed,
+			}
+		}
+	}
+	traces = append(traces, trace)
+
+	for i, call := range frame.Calls {
+		traces = flattenFrame(traces, call, append(traceAddress, i), base)
+	}
+	return traces
+}
@@ -0,0 +1,118 @@
+package debugtrace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceResponse = `[{
+	"txHash": "0xaa",
+	"result": {
+		"type": "CALL", "from": "0x01", "to": "0x02", "value": "0x1", "gas": "0x100", "gasUsed": "0x50", "input": "0x1234", "output": "0x",
+		"calls": [
+			{"type": "DELEGATECALL", "from": "0x02", "to": "0x03", "gas": "0x80", "gasUsed": "0x10", "input": "0x5678", "output": "0x01"},
+			{"type": "CREATE2", "from": "0x02", "to": "0x04", "value": "0x0", "gas": "0x40", "gasUsed": "0x20", "input": "0x6080", "output": "0x6080",
+				"calls": [{"type": "SELFDESTRUCT", "from": "0x04", "to": "0x01", "value": "0x5"}]}
+		]
+	}
+}]`
+
+type testRPCClient struct{}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return json.Unmarshal([]byte(testTraceResponse), result)
+}
+
+type failingRPCClient struct {
+	calls int
+}
+
+func (c *failingRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	return errors.New("unavailable")
+}
+
+func TestFailoverCaller(t *testing.T) {
+	r := require.New(t)
+
+	failing := &failingRPCClient{}
+	var traces []*txTrace
+	r.NoError(failoverCaller{failing, &testRPCClient{}}.CallContext(context.Background(), &traces, debugTraceBlockByNumber))
+	r.Len(traces, 1)
+	r.Equal(1, failing.calls)
+
+	r.Error(failoverCaller{failing, failing}.CallContext(context.Background(), &traces, debugTraceBlockByNumber))
+	r.Equal(3, failing.calls)
+
+	// should not fail over after the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.ErrorIs(failoverCaller{failing, failing}.CallContext(ctx, &traces, debugTraceBlockByNumber), context.Canceled)
+	r.Equal(4, failing.calls)
+}
+
+func TestTraceBlock(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	c := &client{Client: ethClient, rpcClient: &testRPCClient{}}
+
+	blockNumber := big.NewInt(123)
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), blockNumber).Return(&domain.Block{
+		Hash:         "0xbb",
+		Transactions: []domain.Transaction{{Hash: "0xaa"}},
+	}, nil)
+
+	traces, err := c.TraceBlock(context.Background(), blockNumber)
+	r.NoError(err)
+	r.Len(traces, 4)
+
+	for _, trace := range traces {
+		r.Equal("0xbb", *trace.BlockHash)
+		r.Equal("0xaa", *trace.TransactionHash)
+		r.Equal(0, *trace.TransactionPosition)
+		r.Equal(123, *trace.BlockNumber)
+	}
+
+	r.Equal("call", traces[0].Type)
+	r.Equal("call", *traces[0].Action.CallType)
+	r.Equal(2, traces[0].Subtraces)
+	r.Empty(traces[0].TraceAddress)
+
+	r.Equal("delegatecall", *traces[1].Action.CallType)
+	r.Equal([]int{0}, traces[1].TraceAddress)
+	r.Equal("0x01", *traces[1].Result.Output)
+
+	r.Equal("create", traces[2].Type)
+	r.Equal([]int{1}, traces[2].TraceAddress)
+	r.Equal("0x04", *traces[2].Result.Address)
+	r.Equal("0x6080", *traces[2].Action.Init)
+
+	r.Equal("suicide", traces[3].Type)
+	r.Equal([]int{1, 0}, traces[3].TraceAddress)
+	r.Equal("0x04", *traces[3].Action.Address)
+	r.Equal("0x01", *traces[3].Action.RefundAddress)
+	r.Equal("0x5", *traces[3].Action.Balance)
+}
+
+func TestTraceBlock_CountMismatch(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	c := &client{Client: ethClient, rpcClient: &testRPCClient{}}
+
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(&domain.Block{Hash: "0xbb"}, nil)
+
+	_, err := c.TraceBlock(context.Background(), big.NewInt(123))
+	r.Error(err)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +18,9 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/forta-network/forta-core-go/utils/workers"
 	"github.com/forta-network/forta-node/clients/cooldown"
@@ -31,6 +34,8 @@ const (
 	LabelFortaSupervisorStrategyVersion = "network.forta.supervisor.strategy-version"
 	LabelFortaIsBot                     = "network.forta.is-bot"
 	LabelFortaBotID                     = "network.forta.bot-id"
+	LabelFortaBotNetwork                = "network.forta.bot-network"
+	LabelFortaBotSandbox                = "network.forta.bot-sandbox"
 
 	LabelFortaSettingsAgentLogsEnable = "network.forta.settings.agent-logs.enable"
 )
@@ -75,6 +80,16 @@ type ContainerConfig struct {
 	Cmd             []string
 	DialHost        bool
 	Labels          map[string]string
+	// hardening of the bot containers
+	ReadOnlyRootfs bool
+	CapDrop        []string
+	CapAdd         []string
+	SecurityOpt    []string
+	// the tmpfs mounts by the paths, with the mount options like "size=64m"
+	Tmpfs map[string]string
+	// the files are copied into a volume at this directory if set, since the files cannot be
+	// copied into a read-only root filesystem
+	FilesDir string
 }
 
 // ContainerList contains the full container data.
@@ -380,6 +395,20 @@ func (d *dockerClient) InspectContainer(ctx context.Context, id string) (*types.
 	return &info, nil
 }
 
+// GetContainerStats returns a single sample of the resource usage stats of a container.
+func (d *dockerClient) GetContainerStats(ctx context.Context, id string) (*types.StatsJSON, error) {
+	resp, err := d.cli.ContainerStatsOneShot(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %v", err)
+	}
+	defer resp.Body.Close()
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %v", err)
+	}
+	return &stats, nil
+}
+
 // Nuke makes sure that all running Forta containers are stopped and pruned, quickly enough.
 func (d *dockerClient) Nuke(ctx context.Context) error {
 	var err error
@@ -541,6 +570,14 @@ func (d *dockerClient) StartContainer(ctx context.Context, config ContainerConfi
 			CPUQuota: config.CPUQuota,
 			Memory:   config.Memory,
 		},
+		ReadonlyRootfs: config.ReadOnlyRootfs,
+		CapDrop:        config.CapDrop,
+		CapAdd:         config.CapAdd,
+		SecurityOpt:    config.SecurityOpt,
+		Tmpfs:          config.Tmpfs,
+	}
+	if len(config.FilesDir) > 0 {
+		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{Type: mount.TypeVolume, Target: config.FilesDir})
 	}
 
 	if config.DialHost {
@@ -617,8 +654,10 @@ func (d *dockerClient) stopContainer(ctx context.Context, containerID, signal st
 
 // RemoveContainer kills and a container by ID.
 func (d *dockerClient) RemoveContainer(ctx context.Context, containerID string) error {
+	// the anonymous volumes of the files are not reused by the new containers
 	return d.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{
-		Force: true,
+		Force:         true,
+		RemoveVolumes: true,
 	})
 }
 
@@ -811,6 +850,34 @@ func (d *dockerClient) GetContainerLogs(ctx context.Context, containerID, tail s
 	return strings.Join(lines, "\n"), nil
 }
 
+// GetContainerLogsSince gets the stdout and the stderr of the container since the given time. Each line
+// starts with the timestamp of the line. All logs are returned if the time is zero and only the last
+// lines are returned if the tail is positive.
+func (d *dockerClient) GetContainerLogsSince(ctx context.Context, containerID string, since time.Time, tail int) ([]byte, error) {
+	opts := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+	}
+	if !since.IsZero() {
+		opts.Since = since.UTC().Format(time.RFC3339Nano)
+	}
+	if tail > 0 {
+		opts.Tail = strconv.Itoa(tail)
+	}
+	r, err := d.cli.ContainerLogs(ctx, containerID, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// the non-tty container logs are multiplexed with stream headers
+	var buf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&buf, &buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (d *dockerClient) labelFilter() filters.Args {
 	return makeLabelFilter(d.labels)
 }
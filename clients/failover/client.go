@@ -0,0 +1,291 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	log "github.com/sirupsen/logrus"
+)
+
+// Failover defaults
+const (
+	DefaultCallTimeout         = 15 * time.Second
+	DefaultHealthCheckInterval = 30 * time.Second
+)
+
+// Config contains the failover options.
+type Config struct {
+	// CallTimeout bounds each call to an endpoint before failing over to the next one.
+	CallTimeout time.Duration
+	// HealthCheckInterval is the interval of checking all endpoints in the background.
+	HealthCheckInterval time.Duration
+	// LoadBalance distributes the calls to the healthy endpoints in round-robin order
+	// instead of always preferring the first healthy endpoint.
+	LoadBalance bool
+}
+
+type endpoint struct {
+	name    string
+	client  ethereum.Client
+	healthy bool
+	lastErr health.ErrorTracker
+}
+
+// client wraps multiple clients of the same chain and fails over to the next
+// endpoint when an endpoint returns an error or times out.
+type client struct {
+	apiName   string
+	cfg       Config
+	endpoints []*endpoint
+	next      uint64
+	mu        sync.RWMutex
+
+	lastBlockEndpoint health.MessageTracker
+}
+
+// NewClient creates a client for each of the urls and wraps them.
+func NewClient(ctx context.Context, apiName string, urls []string, cfg Config) (*client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no endpoints")
+	}
+	var endpoints []*endpoint
+	for i, u := range urls {
+		ethClient, err := ethereum.NewStreamEthClient(ctx, fmt.Sprintf("%s-%d", apiName, i), u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for endpoint %d: %v", i, err)
+		}
+		endpoints = append(endpoints, &endpoint{name: endpointName(i, u), client: ethClient, healthy: true})
+	}
+	c := newClient(apiName, endpoints, cfg)
+	go c.checkHealth(ctx)
+	return c, nil
+}
+
+func newClient(apiName string, endpoints []*endpoint, cfg Config) *client {
+	if cfg.CallTimeout <= 0 {
+		cfg.CallTimeout = DefaultCallTimeout
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	return &client{apiName: apiName, cfg: cfg, endpoints: endpoints}
+}
+
+// endpointName identifies the endpoint without leaking the credentials in the url path or the query.
+func endpointName(i int, u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || len(parsed.Host) == 0 {
+		return fmt.Sprintf("%d", i)
+	}
+	return fmt.Sprintf("%d-%s", i, parsed.Host)
+}
+
+// candidates returns the healthy endpoints first, in the preferred order, and then the unhealthy
+// ones as the last resort.
+func (c *client) candidates() []*endpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var healthy, unhealthy []*endpoint
+	for _, ep := range c.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	if c.cfg.LoadBalance && len(healthy) > 1 {
+		start := int(atomic.AddUint64(&c.next, 1) % uint64(len(healthy)))
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (c *client) setHealth(ep *endpoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ep.lastErr.Set(err)
+	if ep.healthy != (err == nil) {
+		ep.healthy = err == nil
+		logger := log.WithField("endpoint", ep.name)
+		if ep.healthy {
+			logger.Info("endpoint is healthy again")
+		} else {
+			logger.WithError(err).Warn("endpoint is unhealthy")
+		}
+	}
+}
+
+// call invokes the endpoints in order until one succeeds and returns the endpoint which served the call.
+func call[T any](
+	ctx context.Context, c *client, method string, fn func(ctx context.Context, ethClient ethereum.Client) (T, error),
+) (result T, served *endpoint, err error) {
+	for _, ep := range c.candidates() {
+		callCtx, cancel := context.WithTimeout(ctx, c.cfg.CallTimeout)
+		result, err = fn(callCtx, ep.client)
+		cancel()
+		if err == nil {
+			c.setHealth(ep, nil)
+			return result, ep, nil
+		}
+		if ctx.Err() != nil {
+			return result, nil, ctx.Err()
+		}
+		c.setHealth(ep, err)
+		log.WithError(err).WithFields(log.Fields{
+			"endpoint": ep.name,
+			"method":   method,
+		}).Warn("endpoint call failed - failing over")
+	}
+	return result, nil, err
+}
+
+// checkHealth checks all endpoints periodically so that the unhealthy ones can be used again.
+func (c *client) checkHealth(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, ep := range c.endpoints {
+			checkCtx, cancel := context.WithTimeout(ctx, c.cfg.CallTimeout)
+			_, err := ep.client.BlockNumber(checkCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			c.setHealth(ep, err)
+		}
+	}
+}
+
+// Close closes all clients.
+func (c *client) Close() {
+	for _, ep := range c.endpoints {
+		ep.client.Close()
+	}
+}
+
+// SetRetryInterval sets the retry interval of all clients.
+func (c *client) SetRetryInterval(d time.Duration) {
+	for _, ep := range c.endpoints {
+		ep.client.SetRetryInterval(d)
+	}
+}
+
+// IsWebsocket tells if the first endpoint is a websocket endpoint.
+func (c *client) IsWebsocket() bool {
+	return c.endpoints[0].client.IsWebsocket()
+}
+
+// BlockByHash returns the block by hash.
+func (c *client) BlockByHash(ctx context.Context, hash string) (*domain.Block, error) {
+	block, _, err := call(ctx, c, "BlockByHash", func(ctx context.Context, ethClient ethereum.Client) (*domain.Block, error) {
+		return ethClient.BlockByHash(ctx, hash)
+	})
+	return block, err
+}
+
+// BlockByNumber returns the block by number and records the endpoint which served the block.
+func (c *client) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	block, served, err := call(ctx, c, "BlockByNumber", func(ctx context.Context, ethClient ethereum.Client) (*domain.Block, error) {
+		return ethClient.BlockByNumber(ctx, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"blockNumber": block.Number,
+		"endpoint":    served.name,
+	}).Debug("block served by endpoint")
+	c.lastBlockEndpoint.Set(fmt.Sprintf("%s: %s", block.Number, served.name))
+	return block, nil
+}
+
+// BlockNumber returns the latest block number.
+func (c *client) BlockNumber(ctx context.Context) (*big.Int, error) {
+	blockNumber, _, err := call(ctx, c, "BlockNumber", func(ctx context.Context, ethClient ethereum.Client) (*big.Int, error) {
+		return ethClient.BlockNumber(ctx)
+	})
+	return blockNumber, err
+}
+
+// TransactionReceipt returns the receipt of the transaction.
+func (c *client) TransactionReceipt(ctx context.Context, txHash string) (*domain.TransactionReceipt, error) {
+	receipt, _, err := call(ctx, c, "TransactionReceipt", func(ctx context.Context, ethClient ethereum.Client) (*domain.TransactionReceipt, error) {
+		return ethClient.TransactionReceipt(ctx, txHash)
+	})
+	return receipt, err
+}
+
+// ChainID returns the chain ID.
+func (c *client) ChainID(ctx context.Context) (*big.Int, error) {
+	chainID, _, err := call(ctx, c, "ChainID", func(ctx context.Context, ethClient ethereum.Client) (*big.Int, error) {
+		return ethClient.ChainID(ctx)
+	})
+	return chainID, err
+}
+
+// TraceBlock returns the traces of the block.
+func (c *client) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	traces, _, err := call(ctx, c, "TraceBlock", func(ctx context.Context, ethClient ethereum.Client) ([]domain.Trace, error) {
+		return ethClient.TraceBlock(ctx, number)
+	})
+	return traces, err
+}
+
+// GetLogs returns the logs which match the query.
+func (c *client) GetLogs(ctx context.Context, q eth.FilterQuery) ([]types.Log, error) {
+	logs, _, err := call(ctx, c, "GetLogs", func(ctx context.Context, ethClient ethereum.Client) ([]types.Log, error) {
+		return ethClient.GetLogs(ctx, q)
+	})
+	return logs, err
+}
+
+// SubscribeToHead subscribes to the first endpoint which accepts the subscription. The subscription
+// is not bounded by the call timeout.
+func (c *client) SubscribeToHead(ctx context.Context) (domain.HeaderCh, error) {
+	var err error
+	for _, ep := range c.candidates() {
+		var headerCh domain.HeaderCh
+		headerCh, err = ep.client.SubscribeToHead(ctx)
+		if err == nil {
+			return headerCh, nil
+		}
+		c.setHealth(ep, err)
+	}
+	return nil, err
+}
+
+// Name returns the name of the client.
+func (c *client) Name() string {
+	return fmt.Sprintf("%s-json-rpc-failover-client", c.apiName)
+}
+
+// Health implements the health.Reporter interface.
+func (c *client) Health() health.Reports {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	reports := health.Reports{
+		c.lastBlockEndpoint.GetReport("endpoint.last-block"),
+	}
+	for _, ep := range c.endpoints {
+		reports = append(reports, ep.lastErr.GetReport(fmt.Sprintf("endpoint.%s", ep.name)))
+	}
+	return reports
+}
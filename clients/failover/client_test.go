@@ -0,0 +1,77 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	client1 := mock_ethereum.NewMockClient(ctrl)
+	client2 := mock_ethereum.NewMockClient(ctrl)
+	c := newClient("chain", []*endpoint{
+		{name: "0", client: client1, healthy: true},
+		{name: "1", client: client2, healthy: true},
+	}, Config{})
+
+	blockNumber := big.NewInt(1)
+	client1.EXPECT().BlockByNumber(gomock.Any(), blockNumber).Return(nil, errors.New("failed"))
+	client2.EXPECT().BlockByNumber(gomock.Any(), blockNumber).Return(&domain.Block{Number: "0x1"}, nil)
+
+	block, err := c.BlockByNumber(context.Background(), blockNumber)
+	r.NoError(err)
+	r.Equal("0x1", block.Number)
+	r.False(c.endpoints[0].healthy)
+	r.Equal("0x1: 1", c.lastBlockEndpoint.GetReport("").Details)
+
+	// prefers the healthy endpoint
+	client2.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(2), nil)
+	latest, err := c.BlockNumber(context.Background())
+	r.NoError(err)
+	r.Equal(int64(2), latest.Int64())
+
+	// falls back to the unhealthy endpoint as the last resort
+	client2.EXPECT().ChainID(gomock.Any()).Return(nil, errors.New("failed"))
+	client1.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(137), nil)
+	chainID, err := c.ChainID(context.Background())
+	r.NoError(err)
+	r.Equal(int64(137), chainID.Int64())
+	r.True(c.endpoints[0].healthy)
+	r.False(c.endpoints[1].healthy)
+}
+
+func TestLoadBalance(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	client1 := mock_ethereum.NewMockClient(ctrl)
+	client2 := mock_ethereum.NewMockClient(ctrl)
+	c := newClient("chain", []*endpoint{
+		{name: "0", client: client1, healthy: true},
+		{name: "1", client: client2, healthy: true},
+	}, Config{LoadBalance: true})
+
+	client1.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(1), nil)
+	client2.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(1), nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.BlockNumber(context.Background())
+		r.NoError(err)
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("0-mainnet.infura.io", endpointName(0, "https://mainnet.infura.io/v3/secret"))
+	r.Equal("1", endpointName(1, "not a url"))
+}
@@ -0,0 +1,181 @@
+package feehistory
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const feeHistory = "eth_feeHistory"
+
+// DefaultCacheSize is the amount of the latest blocks which the gas context is kept for.
+const DefaultCacheSize = 256
+
+// DefaultRewardPercentiles are the priority fee percentiles if none are configured.
+var DefaultRewardPercentiles = []float64{10, 50, 90}
+
+// PriorityFee is a percentile of the priority fees which were paid in the block.
+type PriorityFee struct {
+	Percentile float64 `json:"percentile"`
+	Fee        string  `json:"fee"`
+}
+
+// GasContext is the gas market of a block.
+type GasContext struct {
+	BlockNumber            string         `json:"blockNumber"`
+	BaseFeePerGas          string         `json:"baseFeePerGas"`
+	NextBaseFeePerGas      string         `json:"nextBaseFeePerGas,omitempty"`
+	GasUsedRatio           float64        `json:"gasUsedRatio"`
+	PriorityFeePercentiles []*PriorityFee `json:"priorityFeePercentiles,omitempty"`
+}
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type feeHistoryResult struct {
+	OldestBlock   string     `json:"oldestBlock"`
+	BaseFeePerGas []string   `json:"baseFeePerGas"`
+	GasUsedRatio  []float64  `json:"gasUsedRatio"`
+	Reward        [][]string `json:"reward"`
+}
+
+type cacheEntry struct {
+	blockNumber uint64
+	once        sync.Once
+	gasContext  *GasContext
+	err         error
+}
+
+// Client gets the gas context of the blocks with eth_feeHistory and caches it per block, so that
+// the gas context is requested once for the block and all of its transactions.
+type Client struct {
+	rpcClient         rpcCaller
+	rewardPercentiles []float64
+	cacheSize         int
+
+	// ordered from the oldest to the newest
+	order   *list.List
+	entries map[uint64]*list.Element
+	mu      sync.Mutex
+}
+
+// NewClient creates a new fee history client.
+func NewClient(ctx context.Context, url string, rewardPercentiles []float64) (*Client, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial fee history api: %v", err)
+	}
+	return newClient(rpcClient, rewardPercentiles, DefaultCacheSize), nil
+}
+
+func newClient(rpcClient rpcCaller, rewardPercentiles []float64, cacheSize int) *Client {
+	if len(rewardPercentiles) == 0 {
+		rewardPercentiles = DefaultRewardPercentiles
+	}
+	return &Client{
+		rpcClient:         rpcClient,
+		rewardPercentiles: rewardPercentiles,
+		cacheSize:         cacheSize,
+		order:             list.New(),
+		entries:           make(map[uint64]*list.Element),
+	}
+}
+
+// GasContext returns the gas context of the block. The failures are cached too, so that
+// the transactions of a block are not slowed down by the retries.
+func (c *Client) GasContext(ctx context.Context, blockNumber uint64) (*GasContext, error) {
+	entry := c.getEntry(blockNumber)
+	entry.once.Do(func() {
+		entry.gasContext, entry.err = c.fetch(ctx, blockNumber)
+	})
+	return entry.gasContext, entry.err
+}
+
+func (c *Client) getEntry(blockNumber uint64) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[blockNumber]; ok {
+		return elem.Value.(*cacheEntry)
+	}
+	entry := &cacheEntry{blockNumber: blockNumber}
+	c.entries[blockNumber] = c.order.PushBack(entry)
+	for c.order.Len() > c.cacheSize {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).blockNumber)
+	}
+	return entry
+}
+
+func (c *Client) fetch(ctx context.Context, blockNumber uint64) (*GasContext, error) {
+	var result feeHistoryResult
+	err := c.rpcClient.CallContext(ctx, &result, feeHistory, hexutil.EncodeUint64(1), hexutil.EncodeUint64(blockNumber), c.rewardPercentiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %v", err)
+	}
+	if len(result.BaseFeePerGas) == 0 || len(result.GasUsedRatio) == 0 {
+		return nil, fmt.Errorf("fee history has no data for block %d", blockNumber)
+	}
+
+	gasContext := &GasContext{
+		BlockNumber:   hexutil.EncodeUint64(blockNumber),
+		BaseFeePerGas: result.BaseFeePerGas[0],
+		GasUsedRatio:  result.GasUsedRatio[0],
+	}
+	// the next base fee is included after the requested blocks
+	if len(result.BaseFeePerGas) > 1 {
+		gasContext.NextBaseFeePerGas = result.BaseFeePerGas[1]
+	}
+	if len(result.Reward) > 0 {
+		for i, fee := range result.Reward[0] {
+			if i >= len(c.rewardPercentiles) {
+				break
+			}
+			gasContext.PriorityFeePercentiles = append(gasContext.PriorityFeePercentiles, &PriorityFee{
+				Percentile: c.rewardPercentiles[i],
+				Fee:        fee,
+			})
+		}
+	}
+	return gasContext, nil
+}
+
+// AttachToTx adds the gas context to the transaction event message as an unknown field.
+func AttachToTx(msg *protocol.TransactionEvent, gasContext *GasContext) error {
+	unknown, err := appendGasContext(msg.ProtoReflect().GetUnknown(), gasContext)
+	if err != nil {
+		return err
+	}
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// AttachToBlock adds the gas context to the block event message as an unknown field.
+func AttachToBlock(msg *protocol.BlockEvent, gasContext *GasContext) error {
+	unknown, err := appendGasContext(msg.ProtoReflect().GetUnknown(), gasContext)
+	if err != nil {
+		return err
+	}
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+func appendGasContext(unknown []byte, gasContext *GasContext) ([]byte, error) {
+	b, err := json.Marshal(gasContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the gas context: %v", err)
+	}
+	unknown = protowire.AppendTag(unknown, agentgrpc.GasContextFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	return unknown, nil
+}
@@ -0,0 +1,94 @@
+package feehistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testFeeHistoryResponse = `{
+	"oldestBlock": "0x10",
+	"baseFeePerGas": ["0x100", "0x110"],
+	"gasUsedRatio": [0.75],
+	"reward": [["0x1", "0x2", "0x3"]]
+}`
+
+type testRPCClient struct {
+	calls int
+	err   error
+}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	return json.Unmarshal([]byte(testFeeHistoryResponse), result)
+}
+
+func TestGasContext(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{}
+	c := newClient(rpcClient, nil, 2)
+
+	gasContext, err := c.GasContext(context.Background(), 16)
+	r.NoError(err)
+	r.Equal("0x10", gasContext.BlockNumber)
+	r.Equal("0x100", gasContext.BaseFeePerGas)
+	r.Equal("0x110", gasContext.NextBaseFeePerGas)
+	r.Equal(0.75, gasContext.GasUsedRatio)
+	r.Equal([]*PriorityFee{{10, "0x1"}, {50, "0x2"}, {90, "0x3"}}, gasContext.PriorityFeePercentiles)
+
+	// the gas context should be cached per block
+	_, err = c.GasContext(context.Background(), 16)
+	r.NoError(err)
+	r.Equal(1, rpcClient.calls)
+
+	// the oldest blocks should be dropped from the cache
+	_, _ = c.GasContext(context.Background(), 17)
+	_, _ = c.GasContext(context.Background(), 18)
+	_, _ = c.GasContext(context.Background(), 16)
+	r.Equal(4, rpcClient.calls)
+}
+
+func TestGasContext_Error(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{err: errors.New("method not found")}
+	c := newClient(rpcClient, nil, DefaultCacheSize)
+
+	_, err := c.GasContext(context.Background(), 16)
+	r.Error(err)
+	// the failure should be cached too
+	_, err = c.GasContext(context.Background(), 16)
+	r.Error(err)
+	r.Equal(1, rpcClient.calls)
+}
+
+func TestAttach(t *testing.T) {
+	r := require.New(t)
+
+	gasContext := &GasContext{BlockNumber: "0x10", BaseFeePerGas: "0x100", GasUsedRatio: 0.5}
+	txMsg := &protocol.TransactionEvent{}
+	r.NoError(AttachToTx(txMsg, gasContext))
+	blockMsg := &protocol.BlockEvent{}
+	r.NoError(AttachToBlock(blockMsg, gasContext))
+
+	for _, unknown := range [][]byte{txMsg.ProtoReflect().GetUnknown(), blockMsg.ProtoReflect().GetUnknown()} {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		r.Greater(n, 0)
+		r.Equal(agentgrpc.GasContextFieldNumber, num)
+		r.Equal(protowire.BytesType, typ)
+		b, _ := protowire.ConsumeBytes(unknown[n:])
+		var decoded GasContext
+		r.NoError(json.Unmarshal(b, &decoded))
+		r.Equal(*gasContext, decoded)
+	}
+}
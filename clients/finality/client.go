@@ -0,0 +1,176 @@
+package finality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	blockByNumber = "eth_getBlockByNumber"
+	finalizedTag  = "finalized"
+)
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// client makes the latest block look like the last block which is deep enough, so that the block feed
+// waits until the blocks are confirmed or finalized before sending them to the bots.
+type client struct {
+	ethereum.Client
+	ctx          context.Context
+	rpcClient    rpcCaller
+	cfg          config.FinalityConfig
+	pollInterval time.Duration
+
+	safeHead *big.Int
+	mu       sync.RWMutex
+}
+
+// NewClient wraps the given client of the chain feed by the finality mode. The finalized blocks are
+// found by using the "finalized" block tag which the execution clients learn from the beacon chain.
+func NewClient(ctx context.Context, ethClient ethereum.Client, url string, cfg config.FinalityConfig) (ethereum.Client, error) {
+	c := &client{
+		Client:       ethClient,
+		ctx:          ctx,
+		cfg:          cfg,
+		pollInterval: time.Duration(cfg.PollIntervalSeconds) * time.Second,
+	}
+	switch cfg.Mode {
+	case "", config.FinalityModeLatest:
+		return ethClient, nil
+
+	case config.FinalityModeConfirmations:
+		return c, nil
+
+	case config.FinalityModeFinalized:
+		rpcClient, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial finality api: %v", err)
+		}
+		c.rpcClient = rpcClient
+		return c, nil
+
+	default:
+		return nil, fmt.Errorf("unknown finality mode: %s", cfg.Mode)
+	}
+}
+
+// BlockNumber returns the number of the last block which is deep enough.
+func (c *client) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return c.refreshSafeHead(ctx)
+}
+
+// BlockByNumber waits until the block is deep enough and then gets it. The latest block
+// is the last block which is deep enough.
+func (c *client) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	if number == nil {
+		safeHead, err := c.refreshSafeHead(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.Client.BlockByNumber(ctx, safeHead)
+	}
+	if err := c.waitForBlock(ctx, number); err != nil {
+		return nil, err
+	}
+	return c.Client.BlockByNumber(ctx, number)
+}
+
+func (c *client) waitForBlock(ctx context.Context, number *big.Int) error {
+	if c.isSafe(number) {
+		return nil
+	}
+	logger := log.WithFields(log.Fields{
+		"mode":  c.cfg.Mode,
+		"block": number.String(),
+	})
+	logger.Debug("waiting for the block to be deep enough")
+	for {
+		safeHead, err := c.refreshSafeHead(ctx)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get the last deep enough block")
+		}
+		if safeHead != nil && safeHead.Cmp(number) >= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+func (c *client) isSafe(number *big.Int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.safeHead != nil && c.safeHead.Cmp(number) >= 0
+}
+
+// refreshSafeHead gets the number of the last block which is deep enough.
+func (c *client) refreshSafeHead(ctx context.Context) (*big.Int, error) {
+	var (
+		safeHead *big.Int
+		err      error
+	)
+	switch c.cfg.Mode {
+	case config.FinalityModeConfirmations:
+		safeHead, err = c.confirmedHead(ctx)
+	default:
+		safeHead, err = c.finalizedHead(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the safe head never goes back
+	if c.safeHead == nil || safeHead.Cmp(c.safeHead) > 0 {
+		c.safeHead = safeHead
+	}
+	return new(big.Int).Set(c.safeHead), nil
+}
+
+func (c *client) confirmedHead(ctx context.Context) (*big.Int, error) {
+	head, err := c.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	confirmed := new(big.Int).Sub(head, new(big.Int).SetUint64(c.cfg.Confirmations))
+	if confirmed.Sign() < 0 {
+		confirmed.SetUint64(0)
+	}
+	return confirmed, nil
+}
+
+type blockHeader struct {
+	Number *hexutil.Big `json:"number"`
+}
+
+func (c *client) finalizedHead(ctx context.Context) (*big.Int, error) {
+	var header *blockHeader
+	if err := c.rpcClient.CallContext(ctx, &header, blockByNumber, finalizedTag, false); err != nil {
+		return nil, fmt.Errorf("failed to get the finalized block: %v", err)
+	}
+	if header == nil || header.Number == nil {
+		return nil, errors.New("chain does not tag the finalized blocks")
+	}
+	return header.Number.ToInt(), nil
+}
@@ -0,0 +1,103 @@
+package finality
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testRPCClient struct {
+	finalized uint64
+}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method != blockByNumber || args[0] != finalizedTag {
+		return fmt.Errorf("unexpected call: %s %v", method, args)
+	}
+	return json.Unmarshal([]byte(fmt.Sprintf(`{"number":"0x%x"}`, atomic.LoadUint64(&c.finalized))), result)
+}
+
+func TestNewClient_Latest(t *testing.T) {
+	r := require.New(t)
+
+	ethClient := mock_ethereum.NewMockClient(gomock.NewController(t))
+	c, err := NewClient(context.Background(), ethClient, "", config.FinalityConfig{Mode: config.FinalityModeLatest})
+	r.NoError(err)
+	r.Equal(ethClient, c)
+
+	_, err = NewClient(context.Background(), ethClient, "", config.FinalityConfig{Mode: "safe"})
+	r.Error(err)
+}
+
+func TestClient_Confirmations(t *testing.T) {
+	r := require.New(t)
+
+	ethClient := mock_ethereum.NewMockClient(gomock.NewController(t))
+	c, err := NewClient(context.Background(), ethClient, "", config.FinalityConfig{
+		Mode:                config.FinalityModeConfirmations,
+		Confirmations:       10,
+		PollIntervalSeconds: 1,
+	})
+	r.NoError(err)
+
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(100), nil)
+	head, err := c.BlockNumber(context.Background())
+	r.NoError(err)
+	r.Equal(int64(90), head.Int64())
+
+	// the latest block is the last confirmed block
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(100), nil)
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), big.NewInt(90)).Return(&domain.Block{Number: "0x5a"}, nil)
+	block, err := c.BlockByNumber(context.Background(), nil)
+	r.NoError(err)
+	r.Equal("0x5a", block.Number)
+
+	// the confirmed blocks are returned without checking the head again
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), big.NewInt(85)).Return(&domain.Block{Number: "0x55"}, nil)
+	_, err = c.BlockByNumber(context.Background(), big.NewInt(85))
+	r.NoError(err)
+}
+
+func TestClient_Finalized(t *testing.T) {
+	r := require.New(t)
+
+	ethClient := mock_ethereum.NewMockClient(gomock.NewController(t))
+	rpcClient := &testRPCClient{finalized: 100}
+	c := &client{
+		Client:       ethClient,
+		ctx:          context.Background(),
+		rpcClient:    rpcClient,
+		cfg:          config.FinalityConfig{Mode: config.FinalityModeFinalized},
+		pollInterval: 10 * time.Millisecond,
+	}
+
+	head, err := c.BlockNumber(context.Background())
+	r.NoError(err)
+	r.Equal(int64(100), head.Int64())
+
+	// should wait until the block is finalized
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreUint64(&rpcClient.finalized, 101)
+	}()
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), big.NewInt(101)).Return(&domain.Block{Number: "0x65"}, nil)
+	block, err := c.BlockByNumber(context.Background(), big.NewInt(101))
+	r.NoError(err)
+	r.Equal("0x65", block.Number)
+
+	// should stop waiting when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.BlockByNumber(ctx, big.NewInt(200))
+	r.ErrorIs(err, context.DeadlineExceeded)
+}
@@ -28,6 +28,7 @@ type DockerClient interface {
 	GetContainerByName(ctx context.Context, name string) (*types.Container, error)
 	GetContainerByID(ctx context.Context, id string) (*types.Container, error)
 	InspectContainer(ctx context.Context, id string) (*types.ContainerJSON, error)
+	GetContainerStats(ctx context.Context, id string) (*types.StatsJSON, error)
 	StartContainerWithID(ctx context.Context, containerID string) error
 	StartContainer(ctx context.Context, config docker.ContainerConfig) (*docker.Container, error)
 	StopContainer(ctx context.Context, id string) error
@@ -43,6 +44,7 @@ type DockerClient interface {
 	EnsureLocalImage(ctx context.Context, name, ref string) error
 	EnsureLocalImages(ctx context.Context, timeoutPerPull time.Duration, imagePulls []docker.ImagePull) []error
 	GetContainerLogs(ctx context.Context, containerID, tail string, truncate int) (string, error)
+	GetContainerLogsSince(ctx context.Context, containerID string, since time.Time, tail int) ([]byte, error)
 	GetContainerFromRemoteAddr(ctx context.Context, hostPort string) (*types.Container, error)
 	SetImagePullCooldown(threshold int, cooldownDuration time.Duration)
 }
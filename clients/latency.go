@@ -0,0 +1,91 @@
+package clients
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+)
+
+// The alert metadata keys of the latency breakdown in milliseconds
+const (
+	MetadataLatencyFeed    = "latency.feedMs"
+	MetadataLatencyQueue   = "latency.queueMs"
+	MetadataLatencyBot     = "latency.botMs"
+	MetadataLatencyPublish = "latency.publishMs"
+	MetadataLatencyTotal   = "latency.totalMs"
+)
+
+// LatencyBreakdown is the time an alert spent at each stage before it was published.
+type LatencyBreakdown struct {
+	// from the block (or the source alert) to the block being observed or the tx being dispatched
+	Feed time.Duration
+	// from the feed to the bot request
+	Queue time.Duration
+	// from the bot request to the bot response
+	Bot time.Duration
+	// from the bot response to the alert being handed to the publisher
+	Publish time.Duration
+	// from the block (or the source alert) to the alert being handed to the publisher
+	Total time.Duration
+}
+
+// NewLatencyBreakdown calculates the latency breakdown from the timestamps of the stages. The stages
+// with unknown timestamps and the negative durations caused by clock differences are left as zero.
+func NewLatencyBreakdown(ts *domain.TrackingTimestamps, published time.Time) *LatencyBreakdown {
+	if ts == nil {
+		return &LatencyBreakdown{}
+	}
+	start := ts.Block
+	if start.IsZero() {
+		start = ts.SourceAlert
+	}
+	return &LatencyBreakdown{
+		Feed:    between(start, ts.Feed),
+		Queue:   between(ts.Feed, ts.BotRequest),
+		Bot:     between(ts.BotRequest, ts.BotResponse),
+		Publish: between(ts.BotResponse, published),
+		Total:   between(start, published),
+	}
+}
+
+func between(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// Metadata returns the latency breakdown as alert metadata.
+func (lb *LatencyBreakdown) Metadata() map[string]string {
+	return map[string]string{
+		MetadataLatencyFeed:    formatMs(lb.Feed),
+		MetadataLatencyQueue:   formatMs(lb.Queue),
+		MetadataLatencyBot:     formatMs(lb.Bot),
+		MetadataLatencyPublish: formatMs(lb.Publish),
+		MetadataLatencyTotal:   formatMs(lb.Total),
+	}
+}
+
+func formatMs(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// LatencyBreakdownFromMetadata reads the latency breakdown from the alert metadata.
+func LatencyBreakdownFromMetadata(metadata map[string]string) (*LatencyBreakdown, bool) {
+	if _, ok := metadata[MetadataLatencyTotal]; !ok {
+		return nil, false
+	}
+	return &LatencyBreakdown{
+		Feed:    parseMs(metadata[MetadataLatencyFeed]),
+		Queue:   parseMs(metadata[MetadataLatencyQueue]),
+		Bot:     parseMs(metadata[MetadataLatencyBot]),
+		Publish: parseMs(metadata[MetadataLatencyPublish]),
+		Total:   parseMs(metadata[MetadataLatencyTotal]),
+	}, true
+}
+
+func parseMs(s string) time.Duration {
+	ms, _ := strconv.ParseInt(s, 10, 64)
+	return time.Duration(ms) * time.Millisecond
+}
@@ -51,10 +51,15 @@ func NewClient(name, natsURL string) *Client {
 
 // AgentsHandler handles agents.* subjects.
 type AgentsHandler func(AgentPayload) error
+type AgentLimitHandler func(AgentLimitPayload) error
+type AgentThrottleHandler func(AgentThrottlePayload) error
+type AgentPerformanceHandler func(AgentPerformancePayload) error
 type SubscriptionHandler func(SubscriptionPayload) error
 type AgentMetricHandler func(*protocol.AgentMetricList) error
 type InspectionResultsHandler func(results *protocol.InspectionResults) error
 type ScannerHandler func(ScannerPayload) error
+type LogLevelHandler func(LogLevelPayload) error
+type ConfigReloadHandler func(ConfigReloadPayload) error
 
 // Subscribe subscribes the consumer to this client.
 func (client *Client) Subscribe(subject string, handler interface{}) {
@@ -73,6 +78,30 @@ func (client *Client) Subscribe(subject string, handler interface{}) {
 			}
 			err = h(payload)
 
+		case AgentLimitHandler:
+			var payload AgentLimitPayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
+		case AgentThrottleHandler:
+			var payload AgentThrottlePayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
+		case AgentPerformanceHandler:
+			var payload AgentPerformancePayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
 		case AgentMetricHandler:
 			var payload protocol.AgentMetricList
 			err = proto.Unmarshal(m.Data, &payload)
@@ -104,6 +133,22 @@ func (client *Client) Subscribe(subject string, handler interface{}) {
 			}
 			err = h(payload)
 
+		case LogLevelHandler:
+			var payload LogLevelPayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
+		case ConfigReloadHandler:
+			var payload ConfigReloadPayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
 		default:
 			logger.Panicf("no handler found")
 		}
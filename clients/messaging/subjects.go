@@ -4,6 +4,7 @@ import (
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // Message types
@@ -15,15 +16,50 @@ const (
 	SubjectAgentsStatusStopping   = "agents.status.stopping"
 	SubjectAgentsStatusStopped    = "agents.status.stopped"
 	SubjectAgentsStatusRestarted  = "agents.status.restarted"
+	SubjectAgentsStatusLimited    = "agents.status.limited"
+	SubjectAgentsStatusThrottled  = "agents.status.throttled"
+	SubjectAgentsStatusSwapped    = "agents.status.swapped"
+	SubjectAgentsStatusDisabled   = "agents.status.disabled"
 	SubjectMetricAgent            = "metric.agent"
 	SubjectScannerBlock           = "scanner.block"
 	SubjectScannerAlert           = "scanner.alert"
 	SubjectInspectionDone         = "inspection.done"
+	SubjectAdminBotsAdd           = "admin.bots.add"
+	SubjectAdminBotsRemove        = "admin.bots.remove"
+	SubjectAdminLogLevel          = "admin.log.level"
+	SubjectAdminConfigReload      = "admin.config.reload"
 )
 
 // AgentPayload is the message payload.
 type AgentPayload []config.AgentConfig
 
+// AgentLimitPayload is the message payload for a bot which exceeded a resource limit.
+type AgentLimitPayload struct {
+	Agent     config.AgentConfig `json:"agent"`
+	Resource  string             `json:"resource"`
+	Usage     float64            `json:"usage"`
+	Limit     float64            `json:"limit"`
+	Restarted bool               `json:"restarted"`
+}
+
+// AgentThrottlePayload is the message payload for a bot which can not keep up with the requests
+// and has its requests dropped by the backpressure policy.
+type AgentThrottlePayload struct {
+	Agent   config.AgentConfig `json:"agent"`
+	Policy  string             `json:"policy"`
+	Dropped uint64             `json:"dropped"`
+}
+
+// AgentPerformancePayload is the message payload for a bot which was disabled because of its
+// low performance score.
+type AgentPerformancePayload struct {
+	Agent        config.AgentConfig `json:"agent"`
+	Score        float64            `json:"score"`
+	TimeoutRate  float64            `json:"timeoutRate"`
+	ErrorRate    float64            `json:"errorRate"`
+	AvgLatencyMs float64            `json:"avgLatencyMs"`
+}
+
 // AgentMetricPayload is the message payload for metrics.
 type AgentMetricPayload *protocol.AgentMetricList
 
@@ -33,4 +69,25 @@ type SubscriptionPayload []*domain.CombinerBotSubscription
 // ScannerPayload is the message payload for general scanner info.
 type ScannerPayload struct {
 	LatestBlockInput uint64 `json:"latestBlockInput"`
+	ChainID          uint64 `json:"chainId,omitempty"`
+}
+
+// LogLevelPayload is the message payload for the log level changes from the admin API.
+type LogLevelPayload struct {
+	Level string `json:"level"`
+}
+
+// ConfigReloadPayload is the message payload which tells the containers to reload the config
+// after the scanner reloads it.
+type ConfigReloadPayload struct{}
+
+// SetLogLevel changes the log level of the process to the level in the payload.
+func SetLogLevel(payload LogLevelPayload) error {
+	level, err := log.ParseLevel(payload.Level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+	log.WithField("level", level).Info("changed the log level")
+	return nil
 }
@@ -186,6 +186,36 @@ func (mr *MockDockerClientMockRecorder) GetContainerLogs(ctx, containerID, tail,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerLogs", reflect.TypeOf((*MockDockerClient)(nil).GetContainerLogs), ctx, containerID, tail, truncate)
 }
 
+// GetContainerLogsSince mocks base method.
+func (m *MockDockerClient) GetContainerLogsSince(ctx context.Context, containerID string, since time.Time, tail int) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerLogsSince", ctx, containerID, since, tail)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContainerLogsSince indicates an expected call of GetContainerLogsSince.
+func (mr *MockDockerClientMockRecorder) GetContainerLogsSince(ctx, containerID, since, tail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerLogsSince", reflect.TypeOf((*MockDockerClient)(nil).GetContainerLogsSince), ctx, containerID, since, tail)
+}
+
+// GetContainerStats mocks base method.
+func (m *MockDockerClient) GetContainerStats(ctx context.Context, id string) (*types.StatsJSON, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerStats", ctx, id)
+	ret0, _ := ret[0].(*types.StatsJSON)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContainerStats indicates an expected call of GetContainerStats.
+func (mr *MockDockerClientMockRecorder) GetContainerStats(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerStats", reflect.TypeOf((*MockDockerClient)(nil).GetContainerStats), ctx, id)
+}
+
 // GetContainers mocks base method.
 func (m *MockDockerClient) GetContainers(ctx context.Context) (docker.ContainerList, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,185 @@
+package privateflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Bundle is a bundle of signed transactions in the eth_sendBundle format of the Flashbots-style relays.
+type Bundle struct {
+	// the ID which the provider assigns - the hash of the transactions if empty
+	ID string `json:"id"`
+	// the signed transactions in the EIP-2718 encoding
+	Txs []string `json:"txs"`
+	// the block which the bundle targets
+	BlockNumber       string   `json:"blockNumber"`
+	MinTimestamp      uint64   `json:"minTimestamp,omitempty"`
+	MaxTimestamp      uint64   `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []string `json:"revertingTxHashes,omitempty"`
+}
+
+// Hash returns the ID of the bundle or the hash of its transactions.
+func (bundle *Bundle) Hash() string {
+	if len(bundle.ID) > 0 {
+		return bundle.ID
+	}
+	return crypto.Keccak256Hash([]byte(strings.Join(bundle.Txs, ","))).Hex()
+}
+
+type bundlesResponse struct {
+	Bundles []*Bundle `json:"bundles"`
+}
+
+// PrivateTx tells the bots where a transaction of the private order flow came from.
+type PrivateTx struct {
+	// the name of the provider
+	Source   string `json:"source"`
+	BundleID string `json:"bundleId"`
+	// the position of the transaction in the bundle
+	Index       int    `json:"index"`
+	TargetBlock string `json:"targetBlock,omitempty"`
+	// the bundle is still included if the transaction reverts
+	CanRevert bool `json:"canRevert,omitempty"`
+}
+
+// Client gets the bundles of the private order flow from a provider.
+type Client interface {
+	// Bundles returns the bundles which the provider currently has. The same bundles can be
+	// returned again.
+	Bundles(ctx context.Context) ([]*Bundle, error)
+}
+
+type client struct {
+	url        string
+	authHeader string
+	authToken  string
+}
+
+// NewClient creates a new provider client which sends the token with the auth header, if set.
+func NewClient(url, authHeader, authToken string) *client {
+	return &client{url: url, authHeader: authHeader, authToken: authToken}
+}
+
+func (c *client) Bundles(ctx context.Context) ([]*Bundle, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(c.authHeader) > 0 {
+		req.Header.Set(c.authHeader, c.authToken)
+	}
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with '%d': %s", resp.StatusCode, string(b))
+	}
+	var bundles bundlesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bundles); err != nil {
+		return nil, fmt.Errorf("failed to decode the bundles: %v", err)
+	}
+	return bundles.Bundles, nil
+}
+
+// DecodeTx decodes a signed transaction of a bundle. The sender is recovered from the signature.
+func DecodeTx(chainID *big.Int, rawTx string) (*domain.Transaction, error) {
+	b, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction encoding: %v", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("failed to decode the transaction: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), &tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover the sender: %v", err)
+	}
+	v, r, s := tx.RawSignatureValues()
+	input := hexutil.Encode(tx.Data())
+	value := hexutil.EncodeBig(tx.Value())
+	domainTx := &domain.Transaction{
+		From:     strings.ToLower(from.Hex()),
+		Gas:      hexutil.EncodeUint64(tx.Gas()),
+		GasPrice: hexutil.EncodeBig(tx.GasPrice()),
+		Hash:     tx.Hash().Hex(),
+		Input:    &input,
+		Nonce:    hexutil.EncodeUint64(tx.Nonce()),
+		Value:    &value,
+		V:        hexutil.EncodeBig(v),
+		R:        hexutil.EncodeBig(r),
+		S:        hexutil.EncodeBig(s),
+	}
+	if to := tx.To(); to != nil {
+		toStr := strings.ToLower(to.Hex())
+		domainTx.To = &toStr
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		maxFee := hexutil.EncodeBig(tx.GasFeeCap())
+		maxPriorityFee := hexutil.EncodeBig(tx.GasTipCap())
+		domainTx.MaxFeePerGas, domainTx.MaxPriorityFeePerGas = &maxFee, &maxPriorityFee
+	}
+	return domainTx, nil
+}
+
+// AttachToTx adds the private transaction info to the transaction event message as an unknown field.
+func AttachToTx(msg *protocol.TransactionEvent, privateTx *PrivateTx) error {
+	b, err := json.Marshal(privateTx)
+	if err != nil {
+		return fmt.Errorf("failed to encode the private tx: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.PrivateTxFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// FromTx reads the private transaction info from the transaction event message. It returns nil
+// if the transaction is not from the private order flow.
+func FromTx(msg *protocol.TransactionEvent) (*PrivateTx, error) {
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == agentgrpc.PrivateTxFieldNumber && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			var privateTx PrivateTx
+			if err := json.Unmarshal(b, &privateTx); err != nil {
+				return nil, fmt.Errorf("failed to decode the private tx: %v", err)
+			}
+			return &privateTx, nil
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, nil
+}
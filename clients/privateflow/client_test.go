@@ -0,0 +1,92 @@
+package privateflow
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+var testChainID = big.NewInt(1)
+
+func signedRawTx(t *testing.T) (string, *types.Transaction, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(testChainID), &types.DynamicFeeTx{
+		ChainID: testChainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to,
+		Value: big.NewInt(3), Data: []byte{0xab},
+	})
+	require.NoError(t, err)
+	b, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return hexutil.Encode(b), tx, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestBundles(t *testing.T) {
+	r := require.New(t)
+
+	rawTx, _, _ := signedRawTx(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"bundles": []*Bundle{{Txs: []string{rawTx}, BlockNumber: "0x64"}},
+		})
+	}))
+	defer srv.Close()
+
+	bundles, err := NewClient(srv.URL, "X-Api-Key", "secret").Bundles(context.Background())
+	r.NoError(err)
+	r.Len(bundles, 1)
+	r.Equal([]string{rawTx}, bundles[0].Txs)
+	r.Equal("0x64", bundles[0].BlockNumber)
+	// the bundles without an id are identified by their transactions
+	r.NotEmpty(bundles[0].Hash())
+
+	_, err = NewClient(srv.URL, "", "").Bundles(context.Background())
+	r.Error(err)
+}
+
+func TestDecodeTx(t *testing.T) {
+	r := require.New(t)
+
+	rawTx, tx, from := signedRawTx(t)
+	decoded, err := DecodeTx(testChainID, rawTx)
+	r.NoError(err)
+	r.Equal(tx.Hash().Hex(), decoded.Hash)
+	r.Equal(strings.ToLower(from.Hex()), decoded.From)
+	r.Equal("0x0000000000000000000000000000000000000002", *decoded.To)
+	r.Equal("0xab", *decoded.Input)
+	r.Equal("0x3", *decoded.Value)
+	r.Equal("0x2", *decoded.MaxFeePerGas)
+
+	_, err = DecodeTx(testChainID, "0x1234")
+	r.Error(err)
+}
+
+func TestAttachToTx(t *testing.T) {
+	r := require.New(t)
+
+	msg := &protocol.TransactionEvent{}
+	privateTx, err := FromTx(msg)
+	r.NoError(err)
+	r.Nil(privateTx)
+
+	r.NoError(AttachToTx(msg, &PrivateTx{Source: "relay", BundleID: "0x1", Index: 2, CanRevert: true}))
+	privateTx, err = FromTx(msg)
+	r.NoError(err)
+	r.Equal(&PrivateTx{Source: "relay", BundleID: "0x1", Index: 2, CanRevert: true}, privateTx)
+}
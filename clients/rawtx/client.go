@@ -0,0 +1,324 @@
+package rawtx
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// DefaultCacheSize is the amount of the latest blocks which the raw transactions are kept for.
+const DefaultCacheSize = 16
+
+// The transaction type names
+const (
+	TypeLegacy     = "legacy"
+	TypeAccessList = "accessList" // EIP-2930
+	TypeDynamicFee = "dynamicFee" // EIP-1559
+	TypeBlob       = "blob"       // EIP-4844
+	TypeUnknown    = "unknown"
+)
+
+// BlobTxType is the EIP-2718 type of the EIP-4844 transactions.
+const BlobTxType = 0x03
+
+// RawTx contains the fields of a transaction which are not in the transaction event message.
+type RawTx struct {
+	Hash string `json:"hash"`
+	// the EIP-2718 type in hex
+	Type string `json:"type"`
+	// one of the type names
+	TypeName   string           `json:"typeName"`
+	ChainID    string           `json:"chainId,omitempty"`
+	AccessList types.AccessList `json:"accessList,omitempty"`
+	// the blob fee cap and the blobs of the EIP-4844 transactions
+	MaxFeePerBlobGas    string   `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes []string `json:"blobVersionedHashes,omitempty"`
+	// the signed transaction in the EIP-2718 encoding, which the transaction hash is calculated from -
+	// empty if the transaction could not be encoded to the same hash, e.g. the chain specific types
+	RLP string `json:"rlp,omitempty"`
+
+	// the signature and the type are also set to the transaction message if the feed did not set them
+	v, r, s string
+}
+
+// rpcTx is a transaction in the eth_getBlockByNumber response.
+type rpcTx struct {
+	Type                 hexutil.Uint64    `json:"type"`
+	Hash                 common.Hash       `json:"hash"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxFeePerBlobGas     *hexutil.Big      `json:"maxFeePerBlobGas"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	To                   *common.Address   `json:"to"`
+	Value                *hexutil.Big      `json:"value"`
+	Input                hexutil.Bytes     `json:"input"`
+	AccessList           *types.AccessList `json:"accessList"`
+	BlobVersionedHashes  []common.Hash     `json:"blobVersionedHashes"`
+	V                    *hexutil.Big      `json:"v"`
+	R                    *hexutil.Big      `json:"r"`
+	S                    *hexutil.Big      `json:"s"`
+}
+
+// blobTxPayload is the RLP payload of the signed EIP-4844 transactions, which the Geth version of
+// this node does not support yet.
+type blobTxPayload struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
+	BlobFeeCap *big.Int
+	BlobHashes []common.Hash
+	V, R, S    *big.Int
+}
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type cacheEntry struct {
+	blockNumber uint64
+	once        sync.Once
+	// by the lowercase transaction hashes
+	txs map[string]*RawTx
+	err error
+}
+
+// Client gets the full transactions of a block with a single call and caches the raw transactions
+// per block, so that the transactions do not need a call each.
+type Client struct {
+	rpcClient rpcCaller
+	cacheSize int
+
+	// ordered from the oldest to the newest
+	order   *list.List
+	entries map[uint64]*list.Element
+	mu      sync.Mutex
+}
+
+// NewClient creates a new raw transaction client.
+func NewClient(ctx context.Context, url string) (*Client, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial raw tx api: %v", err)
+	}
+	return newClient(rpcClient, DefaultCacheSize), nil
+}
+
+func newClient(rpcClient rpcCaller, cacheSize int) *Client {
+	return &Client{
+		rpcClient: rpcClient,
+		cacheSize: cacheSize,
+		order:     list.New(),
+		entries:   make(map[uint64]*list.Element),
+	}
+}
+
+// RawTx returns the raw transaction in the block. The failures are cached too, so that the
+// transactions of a block are not slowed down by the retries.
+func (c *Client) RawTx(ctx context.Context, blockNumber uint64, txHash string) (*RawTx, error) {
+	entry := c.getEntry(blockNumber)
+	entry.once.Do(func() {
+		entry.txs, entry.err = c.fetch(ctx, blockNumber)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	rawTx, ok := entry.txs[strings.ToLower(txHash)]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s not found in block %d", txHash, blockNumber)
+	}
+	return rawTx, nil
+}
+
+func (c *Client) getEntry(blockNumber uint64) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[blockNumber]; ok {
+		return elem.Value.(*cacheEntry)
+	}
+	entry := &cacheEntry{blockNumber: blockNumber}
+	c.entries[blockNumber] = c.order.PushBack(entry)
+	for c.order.Len() > c.cacheSize {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).blockNumber)
+	}
+	return entry
+}
+
+func (c *Client) fetch(ctx context.Context, blockNumber uint64) (map[string]*RawTx, error) {
+	var block *struct {
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := c.rpcClient.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(blockNumber), true); err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %v", blockNumber, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", blockNumber)
+	}
+	txs := make(map[string]*RawTx)
+	for _, txJSON := range block.Transactions {
+		rawTx, err := Decode(txJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode a transaction of block %d: %v", blockNumber, err)
+		}
+		txs[strings.ToLower(rawTx.Hash)] = rawTx
+	}
+	return txs, nil
+}
+
+// Decode creates the raw transaction from the transaction JSON of the JSON-RPC API.
+func Decode(txJSON []byte) (*RawTx, error) {
+	var tx rpcTx
+	if err := json.Unmarshal(txJSON, &tx); err != nil {
+		return nil, err
+	}
+	rawTx := &RawTx{
+		Hash:     tx.Hash.Hex(),
+		Type:     hexutil.EncodeUint64(uint64(tx.Type)),
+		TypeName: typeName(uint64(tx.Type)),
+		v:        bigString(tx.V),
+		r:        bigString(tx.R),
+		s:        bigString(tx.S),
+	}
+	if tx.ChainID != nil {
+		rawTx.ChainID = tx.ChainID.String()
+	}
+	if tx.AccessList != nil {
+		rawTx.AccessList = *tx.AccessList
+	}
+	if tx.MaxFeePerBlobGas != nil {
+		rawTx.MaxFeePerBlobGas = tx.MaxFeePerBlobGas.String()
+	}
+	for _, blobHash := range tx.BlobVersionedHashes {
+		rawTx.BlobVersionedHashes = append(rawTx.BlobVersionedHashes, blobHash.Hex())
+	}
+
+	b, err := encode(&tx, txJSON)
+	switch {
+	case err != nil:
+		log.WithError(err).WithField("tx", rawTx.Hash).Debug("failed to encode the raw transaction")
+	case !bytes.Equal(crypto.Keccak256(b), tx.Hash.Bytes()):
+		log.WithField("tx", rawTx.Hash).Debug("encoded raw transaction does not have the same hash")
+	default:
+		rawTx.RLP = hexutil.Encode(b)
+	}
+	return rawTx, nil
+}
+
+func encode(tx *rpcTx, txJSON []byte) ([]byte, error) {
+	switch uint64(tx.Type) {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+		var gethTx types.Transaction
+		if err := gethTx.UnmarshalJSON(txJSON); err != nil {
+			return nil, err
+		}
+		return gethTx.MarshalBinary()
+
+	case BlobTxType:
+		if tx.To == nil {
+			return nil, fmt.Errorf("blob transaction without recipient")
+		}
+		payload := &blobTxPayload{
+			ChainID:    toBig(tx.ChainID),
+			Nonce:      uint64(tx.Nonce),
+			GasTipCap:  toBig(tx.MaxPriorityFeePerGas),
+			GasFeeCap:  toBig(tx.MaxFeePerGas),
+			Gas:        uint64(tx.Gas),
+			To:         *tx.To,
+			Value:      toBig(tx.Value),
+			Data:       tx.Input,
+			BlobFeeCap: toBig(tx.MaxFeePerBlobGas),
+			BlobHashes: tx.BlobVersionedHashes,
+			V:          toBig(tx.V),
+			R:          toBig(tx.R),
+			S:          toBig(tx.S),
+		}
+		if tx.AccessList != nil {
+			payload.AccessList = *tx.AccessList
+		}
+		b, err := rlp.EncodeToBytes(payload)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{BlobTxType}, b...), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type)
+	}
+}
+
+func typeName(txType uint64) string {
+	switch txType {
+	case types.LegacyTxType:
+		return TypeLegacy
+	case types.AccessListTxType:
+		return TypeAccessList
+	case types.DynamicFeeTxType:
+		return TypeDynamicFee
+	case BlobTxType:
+		return TypeBlob
+	default:
+		return TypeUnknown
+	}
+}
+
+func toBig(n *hexutil.Big) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n.ToInt()
+}
+
+func bigString(n *hexutil.Big) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
+
+// AttachToTx adds the raw transaction to the transaction event message as an unknown field. The type
+// and the signature of the transaction message are set too if they are empty.
+func AttachToTx(msg *protocol.TransactionEvent, rawTx *RawTx) error {
+	if tx := msg.Transaction; tx != nil {
+		if len(tx.Type) == 0 {
+			tx.Type = rawTx.Type
+		}
+		if len(tx.V) == 0 && len(tx.R) == 0 && len(tx.S) == 0 {
+			tx.V, tx.R, tx.S = rawTx.v, rawTx.r, rawTx.s
+		}
+	}
+	b, err := json.Marshal(rawTx)
+	if err != nil {
+		return fmt.Errorf("failed to encode the raw tx: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.RawTxFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
@@ -0,0 +1,174 @@
+package rawtx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	testChainID   = big.NewInt(1)
+	testRecipient = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	testAccess    = types.AccessList{{
+		Address:     common.HexToAddress("0x0000000000000000000000000000000000000003"),
+		StorageKeys: []common.Hash{common.HexToHash("0x01")},
+	}}
+	testBlobHash = common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000001")
+)
+
+type testRPCClient struct {
+	txs   []json.RawMessage
+	calls int
+	err   error
+}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	b, _ := json.Marshal(map[string]interface{}{"transactions": c.txs})
+	return json.Unmarshal(b, result)
+}
+
+func signedTxJSON(t *testing.T, txData types.TxData) (json.RawMessage, *types.Transaction) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(testChainID), txData)
+	require.NoError(t, err)
+	b, err := tx.MarshalJSON()
+	require.NoError(t, err)
+	return b, tx
+}
+
+// blobTxJSON creates an EIP-4844 transaction and calculates its hash from the encoding in the EIP.
+func blobTxJSON() (json.RawMessage, []byte) {
+	fields := []interface{}{
+		testChainID, uint64(1), big.NewInt(2), big.NewInt(3), uint64(21000), testRecipient, big.NewInt(4),
+		[]byte{0xab}, testAccess, big.NewInt(5), []common.Hash{testBlobHash}, big.NewInt(1), big.NewInt(6), big.NewInt(7),
+	}
+	payload, _ := rlp.EncodeToBytes(fields)
+	encoded := append([]byte{BlobTxType}, payload...)
+	accessList, _ := json.Marshal(testAccess)
+	return json.RawMessage(fmt.Sprintf(`{
+		"type": "0x3", "hash": "%s", "chainId": "0x1", "nonce": "0x1", "maxPriorityFeePerGas": "0x2",
+		"maxFeePerGas": "0x3", "gas": "0x5208", "to": "%s", "value": "0x4", "input": "0xab",
+		"accessList": %s, "maxFeePerBlobGas": "0x5", "blobVersionedHashes": ["%s"],
+		"v": "0x1", "r": "0x6", "s": "0x7"
+	}`, crypto.Keccak256Hash(encoded).Hex(), testRecipient.Hex(), accessList, testBlobHash.Hex())), encoded
+}
+
+func TestRawTx(t *testing.T) {
+	r := require.New(t)
+
+	legacyJSON, legacyTx := signedTxJSON(t, &types.LegacyTx{
+		Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &testRecipient, Value: big.NewInt(1),
+	})
+	accessListJSON, accessListTx := signedTxJSON(t, &types.AccessListTx{
+		ChainID: testChainID, Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &testRecipient, AccessList: testAccess,
+	})
+	dynamicFeeJSON, dynamicFeeTx := signedTxJSON(t, &types.DynamicFeeTx{
+		ChainID: testChainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &testRecipient,
+	})
+	blobJSON, blobEncoded := blobTxJSON()
+
+	rpcClient := &testRPCClient{txs: []json.RawMessage{legacyJSON, accessListJSON, dynamicFeeJSON, blobJSON}}
+	c := newClient(rpcClient, DefaultCacheSize)
+
+	for _, tx := range []*types.Transaction{legacyTx, accessListTx, dynamicFeeTx} {
+		rawTx, err := c.RawTx(context.Background(), 16, tx.Hash().Hex())
+		r.NoError(err)
+		expected, err := tx.MarshalBinary()
+		r.NoError(err)
+		r.Equal(hexutil.Encode(expected), rawTx.RLP)
+		r.Equal(hexutil.EncodeUint64(uint64(tx.Type())), rawTx.Type)
+	}
+
+	rawTx, err := c.RawTx(context.Background(), 16, accessListTx.Hash().Hex())
+	r.NoError(err)
+	r.Equal(TypeAccessList, rawTx.TypeName)
+	r.Equal("0x1", rawTx.ChainID)
+	r.Equal(testAccess, rawTx.AccessList)
+
+	var blobTx struct {
+		Hash string `json:"hash"`
+	}
+	r.NoError(json.Unmarshal(blobJSON, &blobTx))
+	rawTx, err = c.RawTx(context.Background(), 16, blobTx.Hash)
+	r.NoError(err)
+	r.Equal(TypeBlob, rawTx.TypeName)
+	r.Equal(hexutil.Encode(blobEncoded), rawTx.RLP)
+	r.Equal("0x5", rawTx.MaxFeePerBlobGas)
+	r.Equal([]string{testBlobHash.Hex()}, rawTx.BlobVersionedHashes)
+
+	_, err = c.RawTx(context.Background(), 16, "0x1234")
+	r.Error(err)
+	// the block should be requested once
+	r.Equal(1, rpcClient.calls)
+}
+
+func TestRawTx_UnknownType(t *testing.T) {
+	r := require.New(t)
+
+	rawTx, err := Decode([]byte(`{"type": "0x7e", "hash": "0x0000000000000000000000000000000000000000000000000000000000000001"}`))
+	r.NoError(err)
+	r.Equal(TypeUnknown, rawTx.TypeName)
+	r.Equal("0x7e", rawTx.Type)
+	// cannot be encoded
+	r.Empty(rawTx.RLP)
+}
+
+func TestRawTx_Error(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{err: errors.New("block not available")}
+	c := newClient(rpcClient, DefaultCacheSize)
+
+	_, err := c.RawTx(context.Background(), 16, "0x1234")
+	r.Error(err)
+	// the failure should be cached too
+	_, err = c.RawTx(context.Background(), 16, "0x1234")
+	r.Error(err)
+	r.Equal(1, rpcClient.calls)
+}
+
+func TestAttachToTx(t *testing.T) {
+	r := require.New(t)
+
+	dynamicFeeJSON, _ := signedTxJSON(t, &types.DynamicFeeTx{
+		ChainID: testChainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &testRecipient,
+	})
+	rawTx, err := Decode(dynamicFeeJSON)
+	r.NoError(err)
+
+	msg := &protocol.TransactionEvent{Transaction: &protocol.TransactionEvent_EthTransaction{}}
+	r.NoError(AttachToTx(msg, rawTx))
+	r.Equal("0x2", msg.Transaction.Type)
+	r.NotEmpty(msg.Transaction.V)
+	r.NotEmpty(msg.Transaction.R)
+	r.NotEmpty(msg.Transaction.S)
+
+	unknown := msg.ProtoReflect().GetUnknown()
+	num, typ, n := protowire.ConsumeTag(unknown)
+	r.Greater(n, 0)
+	r.Equal(agentgrpc.RawTxFieldNumber, num)
+	r.Equal(protowire.BytesType, typ)
+	b, _ := protowire.ConsumeBytes(unknown[n:])
+	var decoded RawTx
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal(rawTx.RLP, decoded.RLP)
+	r.Equal(TypeDynamicFee, decoded.TypeName)
+}
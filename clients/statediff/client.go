@@ -0,0 +1,352 @@
+package statediff
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// State diff APIs
+const (
+	// the prestate tracer of Geth in the diff mode
+	APIDebugTraceBlockByNumber = "debug_traceBlockByNumber"
+	// the state diff traces of Parity and Erigon
+	APITraceReplayBlockTransactions = "trace_replayBlockTransactions"
+)
+
+// DefaultCacheSize is the amount of the latest blocks which the state diffs are kept for.
+const DefaultCacheSize = 16
+
+const zeroValue = "0x0"
+
+// ValueChange is the value of a field before and after the transaction.
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AccountDiff contains the changed fields of an account. The unchanged fields are nil.
+type AccountDiff struct {
+	Balance     *ValueChange            `json:"balance,omitempty"`
+	Nonce       *ValueChange            `json:"nonce,omitempty"`
+	CodeChanged bool                    `json:"codeChanged,omitempty"`
+	Storage     map[string]*ValueChange `json:"storage,omitempty"`
+}
+
+// StateDiff contains the accounts which the transaction changed, by the lowercase addresses.
+type StateDiff struct {
+	TxHash   string                  `json:"txHash"`
+	Accounts map[string]*AccountDiff `json:"accounts"`
+}
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type cacheEntry struct {
+	blockNumber uint64
+	once        sync.Once
+	// by the lowercase transaction hashes
+	diffs map[string]*StateDiff
+	err   error
+}
+
+// Client gets the state diffs of all transactions of a block with a single call and caches them
+// per block, so that the transactions do not need a call each.
+type Client struct {
+	rpcClient rpcCaller
+	api       string
+	cacheSize int
+
+	// ordered from the oldest to the newest
+	order   *list.List
+	entries map[uint64]*list.Element
+	mu      sync.Mutex
+}
+
+// NewClient creates a new state diff client which uses the given API.
+func NewClient(ctx context.Context, url, api string) (*Client, error) {
+	if api != APIDebugTraceBlockByNumber && api != APITraceReplayBlockTransactions {
+		return nil, fmt.Errorf("unsupported state diff api: %s", api)
+	}
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial state diff api: %v", err)
+	}
+	return newClient(rpcClient, api, DefaultCacheSize), nil
+}
+
+func newClient(rpcClient rpcCaller, api string, cacheSize int) *Client {
+	return &Client{
+		rpcClient: rpcClient,
+		api:       api,
+		cacheSize: cacheSize,
+		order:     list.New(),
+		entries:   make(map[uint64]*list.Element),
+	}
+}
+
+// StateDiff returns the state diff of the transaction in the block. It returns nil if the
+// transaction did not change the state. The failures are cached too, so that the transactions
+// of a block are not slowed down by the retries.
+func (c *Client) StateDiff(ctx context.Context, blockNumber uint64, txHash string) (*StateDiff, error) {
+	entry := c.getEntry(blockNumber)
+	entry.once.Do(func() {
+		entry.diffs, entry.err = c.fetch(ctx, blockNumber)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry.diffs[strings.ToLower(txHash)], nil
+}
+
+func (c *Client) getEntry(blockNumber uint64) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[blockNumber]; ok {
+		return elem.Value.(*cacheEntry)
+	}
+	entry := &cacheEntry{blockNumber: blockNumber}
+	c.entries[blockNumber] = c.order.PushBack(entry)
+	for c.order.Len() > c.cacheSize {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).blockNumber)
+	}
+	return entry
+}
+
+func (c *Client) fetch(ctx context.Context, blockNumber uint64) (map[string]*StateDiff, error) {
+	var (
+		diffs []*StateDiff
+		err   error
+	)
+	switch c.api {
+	case APITraceReplayBlockTransactions:
+		diffs, err = c.fetchReplay(ctx, blockNumber)
+	default:
+		diffs, err = c.fetchPrestate(ctx, blockNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	byTx := make(map[string]*StateDiff)
+	for _, diff := range diffs {
+		if len(diff.Accounts) > 0 {
+			byTx[strings.ToLower(diff.TxHash)] = diff
+		}
+	}
+	return byTx, nil
+}
+
+type prestateAccount struct {
+	Balance *string           `json:"balance"`
+	Nonce   *uint64           `json:"nonce"`
+	Code    *string           `json:"code"`
+	Storage map[string]string `json:"storage"`
+}
+
+type prestateTrace struct {
+	TxHash string `json:"txHash"`
+	Result *struct {
+		Pre  map[string]*prestateAccount `json:"pre"`
+		Post map[string]*prestateAccount `json:"post"`
+	} `json:"result"`
+}
+
+// fetchPrestate uses the prestate tracer in the diff mode, where the pre state contains the changed
+// accounts with the old values and the post state contains only the new values of the changed fields.
+// The cleared storage slots and the deleted accounts are missing in the post state. The traces without
+// the transaction hashes, from the Geth versions before v1.11, are skipped.
+func (c *Client) fetchPrestate(ctx context.Context, blockNumber uint64) ([]*StateDiff, error) {
+	var traces []*prestateTrace
+	err := c.rpcClient.CallContext(
+		ctx, &traces, APIDebugTraceBlockByNumber, hexutil.EncodeUint64(blockNumber),
+		map[string]interface{}{
+			"tracer":       "prestateTracer",
+			"tracerConfig": map[string]interface{}{"diffMode": true},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the prestate traces: %v", err)
+	}
+	var diffs []*StateDiff
+	for _, trace := range traces {
+		if trace.Result == nil || len(trace.TxHash) == 0 {
+			continue
+		}
+		diff := &StateDiff{TxHash: trace.TxHash, Accounts: make(map[string]*AccountDiff)}
+		for address, pre := range trace.Result.Pre {
+			post, ok := trace.Result.Post[address]
+			if !ok {
+				post = &prestateAccount{}
+			}
+			diff.Accounts[strings.ToLower(address)] = diffAccounts(pre, post, !ok)
+		}
+		for address, post := range trace.Result.Post {
+			if _, ok := trace.Result.Pre[address]; !ok {
+				diff.Accounts[strings.ToLower(address)] = diffAccounts(&prestateAccount{}, post, false)
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+func diffAccounts(pre, post *prestateAccount, deleted bool) *AccountDiff {
+	account := &AccountDiff{
+		Balance:     diffValues(pre.Balance, post.Balance, deleted),
+		Nonce:       diffValues(encodeNonce(pre.Nonce), encodeNonce(post.Nonce), deleted),
+		CodeChanged: post.Code != nil || (deleted && pre.Code != nil),
+	}
+	for slot, from := range pre.Storage {
+		to, ok := post.Storage[slot]
+		if !ok {
+			to = zeroValue
+		}
+		account.setStorage(slot, &ValueChange{From: from, To: to})
+	}
+	for slot, to := range post.Storage {
+		if _, ok := pre.Storage[slot]; !ok {
+			account.setStorage(slot, &ValueChange{From: zeroValue, To: to})
+		}
+	}
+	return account
+}
+
+// diffValues returns nil if the field did not change. The post state contains only the changed
+// fields, unless the account is deleted and all of its fields are cleared.
+func diffValues(from, to *string, deleted bool) *ValueChange {
+	if to == nil && (!deleted || from == nil || *from == zeroValue) {
+		return nil
+	}
+	change := &ValueChange{From: zeroValue, To: zeroValue}
+	if from != nil {
+		change.From = *from
+	}
+	if to != nil {
+		change.To = *to
+	}
+	return change
+}
+
+func encodeNonce(nonce *uint64) *string {
+	if nonce == nil {
+		return nil
+	}
+	encoded := hexutil.EncodeUint64(*nonce)
+	return &encoded
+}
+
+func (account *AccountDiff) setStorage(slot string, change *ValueChange) {
+	if account.Storage == nil {
+		account.Storage = make(map[string]*ValueChange)
+	}
+	account.Storage[strings.ToLower(slot)] = change
+}
+
+type replayTrace struct {
+	TransactionHash string                                `json:"transactionHash"`
+	StateDiff       map[string]map[string]json.RawMessage `json:"stateDiff"`
+}
+
+// fetchReplay uses the state diff traces, where each field is "=" if it did not change or is
+// one of {"+": new}, {"-": old} and {"*": {"from": old, "to": new}}. The storage field contains
+// these values by the slots.
+func (c *Client) fetchReplay(ctx context.Context, blockNumber uint64) ([]*StateDiff, error) {
+	var traces []*replayTrace
+	err := c.rpcClient.CallContext(
+		ctx, &traces, APITraceReplayBlockTransactions, hexutil.EncodeUint64(blockNumber), []string{"stateDiff"},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the state diff traces: %v", err)
+	}
+	var diffs []*StateDiff
+	for _, trace := range traces {
+		diff := &StateDiff{TxHash: trace.TransactionHash, Accounts: make(map[string]*AccountDiff)}
+		for address, fields := range trace.StateDiff {
+			account := &AccountDiff{}
+			var err error
+			if account.Balance, err = parseReplayValue(fields["balance"]); err != nil {
+				return nil, err
+			}
+			if account.Nonce, err = parseReplayValue(fields["nonce"]); err != nil {
+				return nil, err
+			}
+			code, err := parseReplayValue(fields["code"])
+			if err != nil {
+				return nil, err
+			}
+			account.CodeChanged = code != nil
+			var storage map[string]json.RawMessage
+			if len(fields["storage"]) > 0 {
+				if err := json.Unmarshal(fields["storage"], &storage); err != nil {
+					return nil, fmt.Errorf("failed to decode the storage diff: %v", err)
+				}
+			}
+			for slot, value := range storage {
+				change, err := parseReplayValue(value)
+				if err != nil {
+					return nil, err
+				}
+				if change != nil {
+					account.setStorage(slot, change)
+				}
+			}
+			diff.Accounts[strings.ToLower(address)] = account
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// parseReplayValue returns nil if the value did not change.
+func parseReplayValue(value json.RawMessage) (*ValueChange, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	var unchanged string
+	if err := json.Unmarshal(value, &unchanged); err == nil {
+		return nil, nil
+	}
+	var change struct {
+		Added   *string      `json:"+"`
+		Removed *string      `json:"-,"`
+		Changed *ValueChange `json:"*"`
+	}
+	if err := json.Unmarshal(value, &change); err != nil {
+		return nil, fmt.Errorf("failed to decode the state diff value: %v", err)
+	}
+	switch {
+	case change.Changed != nil:
+		return change.Changed, nil
+	case change.Added != nil:
+		return &ValueChange{From: zeroValue, To: *change.Added}, nil
+	case change.Removed != nil:
+		return &ValueChange{From: *change.Removed, To: zeroValue}, nil
+	}
+	return nil, nil
+}
+
+// AttachToTx adds the state diff to the transaction event message as an unknown field.
+func AttachToTx(msg *protocol.TransactionEvent, diff *StateDiff) error {
+	b, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode the state diff: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.StateDiffFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
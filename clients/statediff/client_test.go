@@ -0,0 +1,172 @@
+package statediff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testPrestateResponse = `[
+	{
+		"txHash": "0xAA",
+		"result": {
+			"pre": {
+				"0xOwned": {"balance": "0x10", "nonce": 1, "code": "0x6080", "storage": {"0x00": "0x01", "0x01": "0x05"}},
+				"0xSender": {"balance": "0x100", "nonce": 7},
+				"0xDestroyed": {"balance": "0x0", "code": "0x6080"}
+			},
+			"post": {
+				"0xOwned": {"storage": {"0x00": "0x02"}},
+				"0xSender": {"balance": "0x90", "nonce": 8},
+				"0xCreated": {"balance": "0x1", "code": "0x6080", "nonce": 1}
+			}
+		}
+	},
+	{"txHash": "0xBB", "result": {"pre": {}, "post": {}}}
+]`
+
+const testReplayResponse = `[
+	{
+		"transactionHash": "0xAA",
+		"stateDiff": {
+			"0xOwned": {
+				"balance": "=",
+				"nonce": "=",
+				"code": "=",
+				"storage": {
+					"0x00": {"*": {"from": "0x01", "to": "0x02"}},
+					"0x01": {"-": "0x05"}
+				}
+			},
+			"0xSender": {
+				"balance": {"*": {"from": "0x100", "to": "0x90"}},
+				"nonce": {"*": {"from": "0x7", "to": "0x8"}},
+				"code": "=",
+				"storage": {}
+			},
+			"0xCreated": {"balance": {"+": "0x1"}, "nonce": {"+": "0x1"}, "code": {"+": "0x6080"}, "storage": {}}
+		}
+	}
+]`
+
+type testRPCClient struct {
+	response string
+	method   string
+	calls    int
+	err      error
+}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	c.method = method
+	if c.err != nil {
+		return c.err
+	}
+	return json.Unmarshal([]byte(c.response), result)
+}
+
+func TestStateDiff_Prestate(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{response: testPrestateResponse}
+	c := newClient(rpcClient, APIDebugTraceBlockByNumber, 2)
+
+	diff, err := c.StateDiff(context.Background(), 16, "0xaa")
+	r.NoError(err)
+	r.Equal(APIDebugTraceBlockByNumber, rpcClient.method)
+
+	owned := diff.Accounts["0xowned"]
+	r.Nil(owned.Balance)
+	r.Nil(owned.Nonce)
+	r.False(owned.CodeChanged)
+	r.Equal(map[string]*ValueChange{
+		"0x00": {From: "0x01", To: "0x02"},
+		"0x01": {From: "0x05", To: "0x0"},
+	}, owned.Storage)
+
+	sender := diff.Accounts["0xsender"]
+	r.Equal(&ValueChange{From: "0x100", To: "0x90"}, sender.Balance)
+	r.Equal(&ValueChange{From: "0x7", To: "0x8"}, sender.Nonce)
+
+	created := diff.Accounts["0xcreated"]
+	r.Equal(&ValueChange{From: "0x0", To: "0x1"}, created.Balance)
+	r.True(created.CodeChanged)
+
+	destroyed := diff.Accounts["0xdestroyed"]
+	r.Nil(destroyed.Balance)
+	r.True(destroyed.CodeChanged)
+
+	// the transactions which do not change the state do not have a diff
+	diff, err = c.StateDiff(context.Background(), 16, "0xbb")
+	r.NoError(err)
+	r.Nil(diff)
+	r.Equal(1, rpcClient.calls)
+}
+
+func TestStateDiff_Replay(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{response: testReplayResponse}
+	c := newClient(rpcClient, APITraceReplayBlockTransactions, 2)
+
+	diff, err := c.StateDiff(context.Background(), 16, "0xAA")
+	r.NoError(err)
+	r.Equal(APITraceReplayBlockTransactions, rpcClient.method)
+
+	owned := diff.Accounts["0xowned"]
+	r.Nil(owned.Balance)
+	r.False(owned.CodeChanged)
+	r.Equal(map[string]*ValueChange{
+		"0x00": {From: "0x01", To: "0x02"},
+		"0x01": {From: "0x05", To: "0x0"},
+	}, owned.Storage)
+	r.Equal(&ValueChange{From: "0x100", To: "0x90"}, diff.Accounts["0xsender"].Balance)
+	r.Equal(&ValueChange{From: "0x0", To: "0x1"}, diff.Accounts["0xcreated"].Nonce)
+	r.True(diff.Accounts["0xcreated"].CodeChanged)
+	r.Empty(diff.Accounts["0xsender"].Storage)
+}
+
+func TestStateDiff_Error(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{err: errors.New("not supported")}
+	c := newClient(rpcClient, APIDebugTraceBlockByNumber, 2)
+
+	_, err := c.StateDiff(context.Background(), 16, "0xaa")
+	r.Error(err)
+	// the failure is cached for the other transactions of the block
+	_, err = c.StateDiff(context.Background(), 16, "0xbb")
+	r.Error(err)
+	r.Equal(1, rpcClient.calls)
+
+	// the oldest blocks are evicted
+	_, _ = c.StateDiff(context.Background(), 17, "0xaa")
+	_, _ = c.StateDiff(context.Background(), 18, "0xaa")
+	_, _ = c.StateDiff(context.Background(), 16, "0xaa")
+	r.Equal(4, rpcClient.calls)
+}
+
+func TestAttachToTx(t *testing.T) {
+	r := require.New(t)
+
+	msg := &protocol.TransactionEvent{}
+	diff := &StateDiff{TxHash: "0xaa", Accounts: map[string]*AccountDiff{
+		"0x1": {Balance: &ValueChange{From: "0x1", To: "0x0"}},
+	}}
+	r.NoError(AttachToTx(msg, diff))
+
+	unknown := msg.ProtoReflect().GetUnknown()
+	num, typ, n := protowire.ConsumeTag(unknown)
+	r.Equal(agentgrpc.StateDiffFieldNumber, num)
+	r.Equal(protowire.BytesType, typ)
+	b, _ := protowire.ConsumeBytes(unknown[n:])
+	var decoded StateDiff
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal(diff, &decoded)
+}
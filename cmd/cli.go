@@ -9,7 +9,7 @@ import (
 	"path"
 	"reflect"
 	"regexp"
-	"strings"
+	"time"
 
 	"github.com/creasty/defaults"
 	"github.com/sirupsen/logrus"
@@ -17,7 +17,6 @@ import (
 	"github.com/forta-network/forta-node/config"
 	"gopkg.in/yaml.v3"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -33,8 +32,10 @@ var (
 	cfg config.Config
 
 	parsedArgs struct {
-		Version uint64
-		NoCheck bool
+		Version    uint64
+		NoCheck    bool
+		ReplayFrom uint64
+		ReplayTo   uint64
 	}
 
 	cmdForta = &cobra.Command{
@@ -93,6 +94,172 @@ publishes alerts about them`,
 		RunE:  handleFortaVersion,
 	}
 
+	cmdFortaDoctor = &cobra.Command{
+		Use:   "doctor",
+		Short: "diagnose the config, the apis, docker, ipfs and the scanner key",
+		RunE:  withInitialized(handleFortaDoctor),
+	}
+
+	cmdFortaAgents = &cobra.Command{
+		Use:   "agents",
+		Short: "manage the local bots",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAgentsList = &cobra.Command{
+		Use:   "list",
+		Short: "list the local bot images",
+		RunE:  withInitialized(handleFortaAgentsList),
+	}
+
+	cmdFortaAgentsAdd = &cobra.Command{
+		Use:   "add <image>",
+		Short: "add a bot image to the local bots",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withInitialized(handleFortaAgentsAdd),
+	}
+
+	cmdFortaAgentsRemove = &cobra.Command{
+		Use:   "remove <image>",
+		Short: "remove a bot image from the local bots",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withInitialized(handleFortaAgentsRemove),
+	}
+
+	cmdFortaDeadLetters = &cobra.Command{
+		Use:   "dead-letters",
+		Short: "inspect and replay the requests which could not be delivered to the bots",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaDeadLettersList = &cobra.Command{
+		Use:   "list",
+		Short: "list the dead letters",
+		RunE:  withInitialized(handleFortaDeadLettersList),
+	}
+
+	cmdFortaDeadLettersShow = &cobra.Command{
+		Use:   "show <id>",
+		Short: "show a dead letter with the request",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withInitialized(handleFortaDeadLettersShow),
+	}
+
+	cmdFortaDeadLettersReplay = &cobra.Command{
+		Use:   "replay [<id>...]",
+		Short: "replay the dead letters once the bots are ready",
+		RunE:  withInitialized(handleFortaDeadLettersReplay),
+	}
+
+	cmdFortaAgentStore = &cobra.Command{
+		Use:   "agent-store",
+		Short: "inspect and wipe the key-value stores of the bots",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAgentStoreList = &cobra.Command{
+		Use:   "list",
+		Short: "list the bots which have a store with the usage",
+		RunE:  withInitialized(handleFortaAgentStoreList),
+	}
+
+	cmdFortaAgentStoreShow = &cobra.Command{
+		Use:   "show <bot id> [<key>]",
+		Short: "show the keys of a bot or the value of a key",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  withInitialized(handleFortaAgentStoreShow),
+	}
+
+	cmdFortaAgentStoreWipe = &cobra.Command{
+		Use:   "wipe [<bot id>...]",
+		Short: "delete the stores of the bots",
+		RunE:  withInitialized(handleFortaAgentStoreWipe),
+	}
+
+	cmdFortaAdmin = &cobra.Command{
+		Use:   "admin",
+		Short: "manage the running node through the admin api",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAdminAddBot = &cobra.Command{
+		Use:   "add-bot <id> <image>",
+		Short: "run a bot until the node restarts",
+		Args:  cobra.ExactArgs(2),
+		RunE:  withInitialized(handleFortaAdminAddBot),
+	}
+
+	cmdFortaAdminRemoveBot = &cobra.Command{
+		Use:   "remove-bot <id>",
+		Short: "stop a bot until the node restarts",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withInitialized(handleFortaAdminRemoveBot),
+	}
+
+	cmdFortaAdminLogLevel = &cobra.Command{
+		Use:   "log-level <level>",
+		Short: "change the log level of the node containers",
+		Args:  cobra.ExactArgs(1),
+		RunE:  withInitialized(handleFortaAdminLogLevel),
+	}
+
+	cmdFortaAdminPause = &cobra.Command{
+		Use:   "pause",
+		Short: "stop sending the new events to the bots",
+		RunE:  withInitialized(handleFortaAdminPause),
+	}
+
+	cmdFortaAdminResume = &cobra.Command{
+		Use:   "resume",
+		Short: "continue sending the new events to the bots",
+		RunE:  withInitialized(handleFortaAdminResume),
+	}
+
+	cmdFortaAdminCheckpoint = &cobra.Command{
+		Use:   "checkpoint",
+		Short: "save the snapshot of the unfinished bot requests",
+		RunE:  withInitialized(handleFortaAdminCheckpoint),
+	}
+
+	cmdFortaAdminDrain = &cobra.Command{
+		Use:   "drain",
+		Short: "pause the feeds and wait for the bots to finish the requests before stopping the node",
+		RunE:  withInitialized(handleFortaAdminDrain),
+	}
+
+	cmdFortaAdminReload = &cobra.Command{
+		Use:   "reload",
+		Short: "reload the config and apply the changed settings which do not need a restart",
+		RunE:  withInitialized(handleFortaAdminReload),
+	}
+
+	cmdFortaLogs = &cobra.Command{
+		Use:   "logs [<agent>]",
+		Short: "show the captured logs of a bot or list the bots with logs",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  withInitialized(handleFortaLogs),
+	}
+
+	cmdFortaTest = &cobra.Command{
+		Use:   "test",
+		Short: "run the blocks and the transactions from a fixture file against a locally running bot",
+		RunE:  handleFortaTest,
+	}
+
+	cmdFortaLoadTest = &cobra.Command{
+		Use:   "loadtest",
+		Short: "send synthetic transactions at a target rate to locally running bots and report how they keep up",
+		RunE:  handleFortaLoadTest,
+	}
+
 	cmdFortaBatch = &cobra.Command{
 		Use:   "batch",
 		Short: "batch utils",
@@ -146,6 +313,12 @@ publishes alerts about them`,
 		Short: "generate a pool registration signature",
 		RunE:  withInitialized(withValidConfig(handleFortaAuthorizePool)),
 	}
+
+	cmdFortaRegister = &cobra.Command{
+		Use:   "register",
+		Short: "check the scanner registration and stake or generate a registration signature",
+		RunE:  withInitialized(withValidConfig(handleFortaRegister)),
+	}
 )
 
 // Execute executes the root command.
@@ -167,6 +340,39 @@ func init() {
 
 	cmdForta.AddCommand(cmdFortaVersion)
 
+	cmdForta.AddCommand(cmdFortaDoctor)
+
+	cmdForta.AddCommand(cmdFortaAgents)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsList)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsAdd)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsRemove)
+
+	cmdForta.AddCommand(cmdFortaDeadLetters)
+	cmdFortaDeadLetters.AddCommand(cmdFortaDeadLettersList)
+	cmdFortaDeadLetters.AddCommand(cmdFortaDeadLettersShow)
+	cmdFortaDeadLetters.AddCommand(cmdFortaDeadLettersReplay)
+
+	cmdForta.AddCommand(cmdFortaAgentStore)
+	cmdFortaAgentStore.AddCommand(cmdFortaAgentStoreList)
+	cmdFortaAgentStore.AddCommand(cmdFortaAgentStoreShow)
+	cmdFortaAgentStore.AddCommand(cmdFortaAgentStoreWipe)
+
+	cmdForta.AddCommand(cmdFortaAdmin)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminAddBot)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminRemoveBot)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminLogLevel)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminPause)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminResume)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminCheckpoint)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminDrain)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminReload)
+
+	cmdForta.AddCommand(cmdFortaLogs)
+
+	cmdForta.AddCommand(cmdFortaTest)
+
+	cmdForta.AddCommand(cmdFortaLoadTest)
+
 	cmdForta.AddCommand(cmdFortaBatch)
 
 	cmdForta.AddCommand(cmdFortaStatus)
@@ -175,6 +381,8 @@ func init() {
 	cmdForta.AddCommand(cmdFortaAuthorize)
 	cmdFortaAuthorize.AddCommand(cmdFortaAuthorizePool)
 
+	cmdForta.AddCommand(cmdFortaRegister)
+
 	// Global (persistent) flags
 
 	cmdForta.PersistentFlags().String("dir", "", "Forta dir (default is $HOME/.forta) (overrides $FORTA_DIR)")
@@ -195,6 +403,44 @@ func init() {
 
 	// forta run
 	cmdFortaRun.Flags().BoolVar(&parsedArgs.NoCheck, "no-check", false, "disable scanner registry check and just run")
+	cmdFortaRun.Flags().Uint64Var(&parsedArgs.ReplayFrom, "replay-from", 0, "replay the historical blocks starting from this block (local mode only)")
+	cmdFortaRun.Flags().Uint64Var(&parsedArgs.ReplayTo, "replay-to", 0, "replay the historical blocks until this block (local mode only)")
+
+	// forta dead-letters
+	cmdFortaDeadLettersList.Flags().String("bot", "", "list only the dead letters of this bot")
+	cmdFortaDeadLettersReplay.Flags().String("bot", "", "replay all dead letters of this bot")
+	cmdFortaDeadLettersReplay.Flags().Bool("all", false, "replay all dead letters")
+
+	// forta agent-store
+	cmdFortaAgentStoreWipe.Flags().Bool("all", false, "wipe all agent stores")
+
+	// forta logs
+	cmdFortaLogs.Flags().Int("tail", 100, "amount of the last lines to show (0 shows all)")
+
+	// forta test
+	cmdFortaTest.Flags().String("fixture", "", "path to a JSON fixture or a protobuf fixture (.pb)")
+	cmdFortaTest.MarkFlagRequired("fixture")
+	cmdFortaTest.Flags().String("agent", "localhost:"+config.AgentGrpcPort, "gRPC address of the bot")
+	cmdFortaTest.Flags().String("bot-id", "0x0000000000000000000000000000000000000000000000000000000000000000", "bot ID to initialize the bot with")
+	cmdFortaTest.Flags().Duration("timeout", 30*time.Second, "how long to wait for each bot response")
+	cmdFortaTest.Flags().String("format", testFormatPretty, "output formatting/encoding: pretty (default), json")
+
+	// forta loadtest
+	cmdFortaLoadTest.Flags().StringSlice("agent", []string{"localhost:" + config.AgentGrpcPort}, "gRPC addresses of the bots (repeatable)")
+	cmdFortaLoadTest.Flags().String("bot-id", "0x0000000000000000000000000000000000000000000000000000000000000000", "bot ID to initialize the bots with")
+	cmdFortaLoadTest.Flags().Int("tps", 100, "target amount of transactions per second")
+	cmdFortaLoadTest.Flags().Duration("duration", 30*time.Second, "how long to generate the transactions")
+	cmdFortaLoadTest.Flags().Int("concurrency", 10, "amount of concurrent requests to each bot")
+	cmdFortaLoadTest.Flags().Int("buffer", 1000, "amount of transactions to buffer for each bot before dropping")
+	cmdFortaLoadTest.Flags().Duration("timeout", 30*time.Second, "how long to wait for each bot response")
+	cmdFortaLoadTest.Flags().Int64("seed", 1, "seed of the synthetic transactions")
+	cmdFortaLoadTest.Flags().Uint64("chain-id", 1, "chain ID of the synthetic transactions")
+	cmdFortaLoadTest.Flags().String("profile", "", "path to a tx profile or a JSON fixture to model the transactions on")
+	cmdFortaLoadTest.Flags().String("format", testFormatPretty, "output formatting/encoding: pretty (default), json")
+
+	// forta doctor
+	cmdFortaDoctor.Flags().Duration("timeout", 10*time.Second, "how long to wait for each check request")
+	cmdFortaDoctor.Flags().Int("samples", 5, "amount of requests to measure the scan api latency with")
 
 	// forta status
 	cmdFortaStatus.Flags().String("format", StatusFormatPretty, "output formatting/encoding: pretty (default), oneline, json, csv")
@@ -210,6 +456,10 @@ func init() {
 	cmdFortaAuthorizePool.Flags().Bool("polygonscan", false, "see the registerScannerNode() inputs to use in Polygonscan")
 	cmdFortaAuthorizePool.Flags().BoolP("force", "f", false, "ignore warning(s)")
 	cmdFortaAuthorizePool.Flags().Bool("clean", false, "output only the encoded registration info")
+
+	// forta register
+	cmdFortaRegister.Flags().Int64("pool-id", 0, "scanner pool ID to register the scanner to if it is not registered")
+	cmdFortaRegister.Flags().Bool("polygonscan", false, "see the registerScannerNode() inputs to use in Polygonscan")
 }
 
 func initConfig() {
@@ -240,6 +490,9 @@ func initConfig() {
 	if err := defaults.Set(&cfg); err != nil {
 		panic(err)
 	}
+	if err := config.ApplyEnvOverrides(&cfg, os.Environ()); err != nil {
+		logrus.WithError(err).Fatal("failed to apply config overrides")
+	}
 
 	cfg.FortaDir = fortaDir
 	cfg.KeyDirPath = path.Join(cfg.FortaDir, config.DefaultKeysDirName)
@@ -265,27 +518,19 @@ func resolveEnvVarsHookFunc(from reflect.Kind, to reflect.Kind, data interface{}
 }
 
 func validateConfig() error {
-	validate := validator.New()
-
-	// Use the YAML names while validating the struct.
-	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("yaml"), ",", 2)[0]
-		if name == "-" {
-			return ""
-		}
-		return name
-	})
-
-	if err := validate.Struct(&cfg); err != nil {
-		validationErrs := err.(validator.ValidationErrors)
-		fmt.Fprintln(os.Stderr, "The config file has invalid or missing fields:")
-		for _, validationErr := range validationErrs {
-			fmt.Fprintf(os.Stderr, "  - %s\n", validationErr.Namespace()[7:])
-		}
-		return errors.New("invalid config file")
+	err := cfg.Validate()
+	if err == nil {
+		return nil
 	}
-
-	return nil
+	validationErrs, ok := err.(config.ValidationErrors)
+	if !ok {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "The config file has invalid or missing fields:")
+	for _, validationErr := range validationErrs {
+		fmt.Fprintf(os.Stderr, "  - %s\n", validationErr)
+	}
+	return errors.New("invalid config file")
 }
 
 func withValidConfig(handler func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
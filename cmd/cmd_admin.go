@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/admin"
+	"github.com/forta-network/forta-node/services/components/reload"
+	"github.com/spf13/cobra"
+)
+
+// adminTimeout bounds the admin requests. The drain can take longer, as long as the shutdown timeout.
+const adminTimeout = time.Minute
+
+// withAdminClient connects to the admin api socket in the forta dir.
+func withAdminClient(timeout time.Duration, do func(context.Context, *admin.Client) error) error {
+	if !cfg.AdminAPI.Enable {
+		return errors.New("admin api is not enabled - please enable it in the config and restart the node")
+	}
+	conn, err := admin.DialSocket(path.Join(cfg.FortaDir, cfg.AdminAPI.SocketName))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return do(ctx, admin.NewClient(conn))
+}
+
+func handleFortaAdminAddBot(cmd *cobra.Command, args []string) error {
+	botConfig := config.AgentConfig{ID: args[0], Image: args[1], IsLocal: cfg.LocalModeConfig.Enable}
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.AddBot(ctx, botConfig)
+	}); err != nil {
+		return err
+	}
+	greenBold("Added bot %s until the node restarts.\n", args[0])
+	return nil
+}
+
+func handleFortaAdminRemoveBot(cmd *cobra.Command, args []string) error {
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.RemoveBot(ctx, args[0])
+	}); err != nil {
+		return err
+	}
+	greenBold("Removed bot %s until the node restarts.\n", args[0])
+	return nil
+}
+
+func handleFortaAdminLogLevel(cmd *cobra.Command, args []string) error {
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.SetLogLevel(ctx, args[0])
+	}); err != nil {
+		return err
+	}
+	greenBold("Changed the log level to %s.\n", args[0])
+	return nil
+}
+
+func handleFortaAdminPause(cmd *cobra.Command, args []string) error {
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.PauseFeeds(ctx)
+	}); err != nil {
+		return err
+	}
+	greenBold("Paused the feeds.\n")
+	return nil
+}
+
+func handleFortaAdminResume(cmd *cobra.Command, args []string) error {
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.ResumeFeeds(ctx)
+	}); err != nil {
+		return err
+	}
+	greenBold("Resumed the feeds.\n")
+	return nil
+}
+
+func handleFortaAdminCheckpoint(cmd *cobra.Command, args []string) error {
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) error {
+		return client.TriggerCheckpoint(ctx)
+	}); err != nil {
+		return err
+	}
+	greenBold("Saved the snapshot of the unfinished bot requests.\n")
+	return nil
+}
+
+func handleFortaAdminDrain(cmd *cobra.Command, args []string) error {
+	timeout := adminTimeout + time.Duration(cfg.Scan.ShutdownTimeoutSeconds)*time.Second
+	if err := withAdminClient(timeout, func(ctx context.Context, client *admin.Client) error {
+		return client.Drain(ctx)
+	}); err != nil {
+		return err
+	}
+	greenBold("Drained the node - it is ready to stop.\n")
+	return nil
+}
+
+func handleFortaAdminReload(cmd *cobra.Command, args []string) error {
+	var report *reload.Report
+	if err := withAdminClient(adminTimeout, func(ctx context.Context, client *admin.Client) (err error) {
+		report, err = client.ReloadConfig(ctx)
+		return
+	}); err != nil {
+		return err
+	}
+	if len(report.Applied) == 0 {
+		greenBold("Reloaded the config - no settings were changed.\n")
+	} else {
+		greenBold("Reloaded the config and applied the changed settings: %s\n", strings.Join(report.Applied, ", "))
+	}
+	if len(report.RequiresRestart) > 0 {
+		yellowBold("Please restart the node to apply the other changed settings: %s\n", strings.Join(report.RequiresRestart, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/agentstore"
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+)
+
+func agentStore() store.AgentStore {
+	return store.NewAgentStore(path.Join(cfg.FortaDir, config.DefaultAgentStoreDirName), agentstore.Limits(cfg.AgentStore))
+}
+
+func handleFortaAgentStoreList(cmd *cobra.Command, args []string) error {
+	as := agentStore()
+	botIDs, err := as.Bots()
+	if err != nil {
+		return err
+	}
+	if len(botIDs) == 0 {
+		cmd.Println("No agent stores.")
+		return nil
+	}
+	for _, botID := range botIDs {
+		usage, err := as.Usage(botID)
+		if err != nil {
+			return err
+		}
+		cmd.Println(formatAgentStoreUsage(usage, cfg.AgentStore))
+	}
+	return nil
+}
+
+func handleFortaAgentStoreShow(cmd *cobra.Command, args []string) error {
+	as := agentStore()
+	botID := args[0]
+	if len(args) > 1 {
+		value, err := as.Get(botID, args[1])
+		if err != nil {
+			return err
+		}
+		cmd.Println(formatAgentStoreValue(value))
+		return nil
+	}
+	keys, err := as.Keys(botID)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		cmd.Println("No keys.")
+		return nil
+	}
+	for _, key := range keys {
+		cmd.Println(key)
+	}
+	return nil
+}
+
+func handleFortaAgentStoreWipe(cmd *cobra.Command, args []string) error {
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && !all {
+		return errors.New("please specify the bot IDs or all agent stores with --all")
+	}
+
+	as := agentStore()
+	botIDs := args
+	if len(botIDs) == 0 {
+		botIDs, err = as.Bots()
+		if err != nil {
+			return err
+		}
+	}
+	for _, botID := range botIDs {
+		if err := as.Wipe(botID); err != nil {
+			return fmt.Errorf("failed to wipe the agent store of %s: %v", botID, err)
+		}
+	}
+	greenBold("Wiped %d agent store(s).\n", len(botIDs))
+	return nil
+}
+
+func formatAgentStoreUsage(usage *store.AgentStoreUsage, storeCfg config.AgentStoreConfig) string {
+	return fmt.Sprintf(
+		"%s  %d/%d keys  %d/%d bytes", usage.BotID, usage.Keys, storeCfg.MaxKeys, usage.Bytes, storeCfg.MaxBytes,
+	)
+}
+
+// formatAgentStoreValue shows the text values as they are and the binary values as hex.
+func formatAgentStoreValue(value []byte) string {
+	if utf8.Valid(value) {
+		return string(value)
+	}
+	return hexutil.Encode(value)
+}
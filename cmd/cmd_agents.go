@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// local agent set in the config file
+const (
+	configKeyLocalMode = "localMode"
+	configKeyBotImages = "botImages"
+)
+
+func handleFortaAgentsList(cmd *cobra.Command, args []string) error {
+	if !cfg.LocalModeConfig.Enable {
+		yellowBold("Local mode is disabled - the node runs the bots assigned from the registry.\n")
+	}
+	if len(cfg.LocalModeConfig.BotImages) == 0 {
+		cmd.Println("No local bots.")
+		return nil
+	}
+	for _, image := range cfg.LocalModeConfig.BotImages {
+		cmd.Println(image)
+	}
+	return nil
+}
+
+func handleFortaAgentsAdd(cmd *cobra.Command, args []string) error {
+	if err := editConfigFile(func(configBytes []byte) ([]byte, error) {
+		return addBotImage(configBytes, args[0])
+	}); err != nil {
+		return err
+	}
+	greenBold("Added %s to the local bots - please restart the node to apply.\n", args[0])
+	return nil
+}
+
+func handleFortaAgentsRemove(cmd *cobra.Command, args []string) error {
+	if err := editConfigFile(func(configBytes []byte) ([]byte, error) {
+		return removeBotImage(configBytes, args[0])
+	}); err != nil {
+		return err
+	}
+	greenBold("Removed %s from the local bots - please restart the node to apply.\n", args[0])
+	return nil
+}
+
+func editConfigFile(edit func(configBytes []byte) ([]byte, error)) error {
+	configBytes, err := os.ReadFile(cfg.ConfigFilePath())
+	if err != nil {
+		return fmt.Errorf("failed to read the config file: %v", err)
+	}
+	configBytes, err = edit(configBytes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.ConfigFilePath(), configBytes, 0644)
+}
+
+// addBotImage adds the image to the local bot images in the config file and keeps the comments and the order.
+func addBotImage(configBytes []byte, image string) ([]byte, error) {
+	return editBotImages(configBytes, func(images *yaml.Node) error {
+		for _, item := range images.Content {
+			if item.Value == image {
+				return fmt.Errorf("bot image %s is already in the local bots", image)
+			}
+		}
+		images.Content = append(images.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: image})
+		return nil
+	})
+}
+
+// removeBotImage removes the image from the local bot images in the config file.
+func removeBotImage(configBytes []byte, image string) ([]byte, error) {
+	return editBotImages(configBytes, func(images *yaml.Node) error {
+		for i, item := range images.Content {
+			if item.Value == image {
+				images.Content = append(images.Content[:i], images.Content[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("bot image %s is not in the local bots", image)
+	})
+}
+
+func editBotImages(configBytes []byte, edit func(images *yaml.Node) error) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(configBytes, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the config file: %v", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, errors.New("unexpected config file format")
+	}
+
+	localMode, err := mappingValue(doc.Content[0], configKeyLocalMode, yaml.MappingNode)
+	if err != nil {
+		return nil, err
+	}
+	images, err := mappingValue(localMode, configKeyBotImages, yaml.SequenceNode)
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(images); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode the config file: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue finds the value of the key in the mapping node or adds the key with an empty value.
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("unexpected config file format around '%s'", key)
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		value := mapping.Content[i+1]
+		// handle the empty values like "botImages:"
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			*value = yaml.Node{Kind: kind}
+		}
+		if value.Kind != kind {
+			return nil, fmt.Errorf("unexpected config file format at '%s'", key)
+		}
+		return value, nil
+	}
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value, nil
+}
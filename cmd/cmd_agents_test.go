@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAgentsConfig = `chainId: 137
+# local mode
+localMode:
+  enable: true
+  botImages:
+    - bot-image-1
+`
+
+const testAgentsConfigAdded = `chainId: 137
+# local mode
+localMode:
+  enable: true
+  botImages:
+    - bot-image-1
+    - bot-image-2
+`
+
+func TestAddRemoveBotImage(t *testing.T) {
+	r := require.New(t)
+
+	configBytes, err := addBotImage([]byte(testAgentsConfig), "bot-image-2")
+	r.NoError(err)
+	r.Equal(testAgentsConfigAdded, string(configBytes))
+
+	_, err = addBotImage(configBytes, "bot-image-2")
+	r.Error(err)
+
+	configBytes, err = removeBotImage(configBytes, "bot-image-2")
+	r.NoError(err)
+	r.Equal(testAgentsConfig, string(configBytes))
+
+	_, err = removeBotImage(configBytes, "bot-image-2")
+	r.Error(err)
+}
+
+func TestAddBotImage_NoLocalMode(t *testing.T) {
+	r := require.New(t)
+
+	configBytes, err := addBotImage([]byte("chainId: 1\n"), "bot-image-1")
+	r.NoError(err)
+	r.Equal("chainId: 1\nlocalMode:\n  botImages:\n    - bot-image-1\n", string(configBytes))
+}
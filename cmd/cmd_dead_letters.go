@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+)
+
+func deadLetterStore() store.DeadLetterStore {
+	return store.NewDeadLetterStore(path.Join(cfg.FortaDir, config.DefaultDeadLettersDirName), cfg.Scan.MaxDeadLetters)
+}
+
+func handleFortaDeadLettersList(cmd *cobra.Command, args []string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	letters, err := deadLetterStore().List()
+	if err != nil {
+		return err
+	}
+	letters = filterDeadLetters(letters, botID)
+	if len(letters) == 0 {
+		cmd.Println("No dead letters.")
+		return nil
+	}
+	for _, letter := range letters {
+		cmd.Println(formatDeadLetter(letter))
+	}
+	return nil
+}
+
+func handleFortaDeadLettersShow(cmd *cobra.Command, args []string) error {
+	letter, err := deadLetterStore().Get(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(letter, "", "  ")
+	if err != nil {
+		return err
+	}
+	cmd.Println(string(b))
+	return nil
+}
+
+func handleFortaDeadLettersReplay(cmd *cobra.Command, args []string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && len(botID) == 0 && !all {
+		return errors.New("please specify the dead letter IDs, a bot with --bot or all dead letters with --all")
+	}
+
+	dls := deadLetterStore()
+	ids := args
+	if len(ids) == 0 {
+		letters, err := dls.List()
+		if err != nil {
+			return err
+		}
+		for _, letter := range filterDeadLetters(letters, botID) {
+			ids = append(ids, letter.ID)
+		}
+	}
+	for _, id := range ids {
+		if err := dls.MarkReplay(id); err != nil {
+			return fmt.Errorf("failed to mark dead letter %s for replay: %v", id, err)
+		}
+	}
+	greenBold("Marked %d dead letter(s) for replay - the node sends them to the bots when the bots are ready.\n", len(ids))
+	return nil
+}
+
+// filterDeadLetters returns the dead letters of the bot. All letters are returned if the bot ID is empty.
+func filterDeadLetters(letters []*store.DeadLetter, botID string) (filtered []*store.DeadLetter) {
+	for _, letter := range letters {
+		if len(botID) == 0 || strings.EqualFold(letter.BotID, botID) {
+			filtered = append(filtered, letter)
+		}
+	}
+	return
+}
+
+func formatDeadLetter(letter *store.DeadLetter) string {
+	method := letter.Method[strings.LastIndex(letter.Method, "/")+1:]
+	line := fmt.Sprintf(
+		"%s  %s  %s  %s  %s", letter.ID, letter.CreatedAt.Local().Format(time.RFC3339), letter.BotID, method, letter.Reason,
+	)
+	if letter.Replay {
+		line += "  (replay pending)"
+	}
+	return line
+}
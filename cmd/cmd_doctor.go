@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/fatih/color"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/debugtrace"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/spf13/cobra"
+)
+
+// the average latencies of the scan api above this are reported as a warning
+const doctorSlowLatency = 500 * time.Millisecond
+
+// doctor check statuses
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorResult is the outcome of a diagnostics check.
+type doctorResult struct {
+	Name    string
+	Status  string
+	Details string
+	// tells how to fix the problem if the check did not pass
+	Hint string
+}
+
+func doctorPass(name, details string, args ...interface{}) *doctorResult {
+	return &doctorResult{Name: name, Status: doctorOK, Details: fmt.Sprintf(details, args...)}
+}
+
+func doctorProblem(status, name, hint string, err error) *doctorResult {
+	return &doctorResult{Name: name, Status: status, Details: err.Error(), Hint: hint}
+}
+
+// doctor runs the diagnostics checks.
+type doctor struct {
+	cfg          config.Config
+	timeout      time.Duration
+	samples      int
+	httpClient   *http.Client
+	dockerClient clients.DockerClient
+}
+
+func handleFortaDoctor(cmd *cobra.Command, args []string) error {
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	samples, err := cmd.Flags().GetInt("samples")
+	if err != nil {
+		return err
+	}
+	if samples < 1 {
+		return fmt.Errorf("the amount of the latency samples should be at least 1")
+	}
+
+	d := &doctor{
+		cfg:        cfg,
+		timeout:    timeout,
+		samples:    samples,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	// a missing docker client is reported by the docker check
+	if dockerClient, err := docker.NewDockerClient(""); err == nil {
+		d.dockerClient = dockerClient
+	}
+
+	results := d.run(context.Background())
+	var problems int
+	for _, result := range results {
+		printDoctorResult(result)
+		if result.Status == doctorFail {
+			problems++
+		}
+	}
+	fmt.Println()
+	if problems > 0 {
+		redBold("Found %d problem(s) - see the hints above.\n", problems)
+		return fmt.Errorf("diagnostics failed")
+	}
+	greenBold("All checks passed.\n")
+	return nil
+}
+
+func printDoctorResult(result *doctorResult) {
+	switch result.Status {
+	case doctorOK:
+		color.New(color.FgGreen).Printf("[ OK ] ")
+	case doctorWarn:
+		color.New(color.FgYellow).Printf("[WARN] ")
+	default:
+		color.New(color.FgRed).Printf("[FAIL] ")
+	}
+	fmt.Printf("%s: %s\n", result.Name, result.Details)
+	if len(result.Hint) > 0 {
+		fmt.Printf("       hint: %s\n", result.Hint)
+	}
+}
+
+// run runs all checks in order. The scan api checks stop if the api is not reachable or is on
+// another chain.
+func (d *doctor) run(ctx context.Context) []*doctorResult {
+	results := []*doctorResult{d.checkConfig()}
+	results = append(results, d.checkKeys())
+	results = append(results, d.checkScanAPI(ctx)...)
+	if result := d.checkTraceAPI(ctx); result != nil {
+		results = append(results, result)
+	}
+	if result := d.checkWebsocket(ctx); result != nil {
+		results = append(results, result)
+	}
+	results = append(results, d.checkRegistryAPI(ctx))
+	results = append(results, d.checkDocker(ctx))
+	results = append(results, d.checkIPFS(ctx))
+	return results
+}
+
+func (d *doctor) checkConfig() *doctorResult {
+	const name = "config"
+	err := d.cfg.Validate()
+	if err == nil {
+		return doctorPass(name, "valid")
+	}
+	validationErrs, ok := err.(config.ValidationErrors)
+	if !ok {
+		return doctorProblem(doctorFail, name, "fix the config file", err)
+	}
+	return &doctorResult{
+		Name:    name,
+		Status:  doctorFail,
+		Details: fmt.Sprintf("invalid or missing fields: %s", strings.Join(validationErrs, "; ")),
+		Hint:    fmt.Sprintf("fix the fields in %s", d.cfg.ConfigFilePath()),
+	}
+}
+
+func (d *doctor) checkKeys() *doctorResult {
+	const name = "scanner key"
+	key, err := security.LoadKeyWithPassphrase(d.cfg.KeyDirPath, d.cfg.Passphrase)
+	if err != nil {
+		return doctorProblem(
+			doctorFail, name,
+			"make sure that you ran 'forta init' and that the passphrase is correct ($FORTA_PASSPHRASE or --passphrase)",
+			fmt.Errorf("failed to load the scanner key: %v", err),
+		)
+	}
+	return doctorPass(name, "loaded %s", key.Address.Hex())
+}
+
+func (d *doctor) dial(ctx context.Context, jsonRpc config.JsonRpcConfig) (*rpc.Client, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	rpcClient, err := rpc.DialContext(dialCtx, jsonRpc.Url)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range jsonRpc.Headers {
+		rpcClient.SetHeader(k, v)
+	}
+	return rpcClient, nil
+}
+
+func (d *doctor) call(ctx context.Context, rpcClient *rpc.Client, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	return rpcClient.CallContext(ctx, result, method, args...)
+}
+
+// checkScanAPI checks the chain ID and the archive state of the scan api and measures the
+// baseline latency.
+func (d *doctor) checkScanAPI(ctx context.Context) []*doctorResult {
+	const name = "scan api"
+	hint := "check scan.jsonRpc.url in the config file and make sure that the node is reachable"
+	if len(d.cfg.Scan.JsonRpc.Url) == 0 {
+		return []*doctorResult{doctorProblem(doctorFail, name, "set scan.jsonRpc.url in the config file", fmt.Errorf("no url"))}
+	}
+	rpcClient, err := d.dial(ctx, d.cfg.Scan.JsonRpc)
+	if err != nil {
+		return []*doctorResult{doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to connect: %v", err))}
+	}
+	defer rpcClient.Close()
+
+	var chainID hexutil.Big
+	if err := d.call(ctx, rpcClient, &chainID, "eth_chainId"); err != nil {
+		return []*doctorResult{doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to get the chain ID: %v", err))}
+	}
+	if chainID.ToInt().Cmp(big.NewInt(int64(d.cfg.ChainID))) != 0 {
+		return []*doctorResult{doctorProblem(
+			doctorFail, name,
+			"set chainId in the config file to the chain of the scan api or use an api of the configured chain",
+			fmt.Errorf("the api is on chain %s but the config has chain %d", chainID.ToInt(), d.cfg.ChainID),
+		)}
+	}
+	results := []*doctorResult{doctorPass(name, "connected to chain %d", d.cfg.ChainID)}
+	results = append(results, d.checkLatency(ctx, rpcClient))
+	results = append(results, d.checkArchive(ctx, rpcClient))
+	return results
+}
+
+func (d *doctor) checkLatency(ctx context.Context, rpcClient *rpc.Client) *doctorResult {
+	const name = "scan api latency"
+	var total, max time.Duration
+	for i := 0; i < d.samples; i++ {
+		var blockNumber hexutil.Uint64
+		start := time.Now()
+		if err := d.call(ctx, rpcClient, &blockNumber, "eth_blockNumber"); err != nil {
+			return doctorProblem(
+				doctorFail, name, "the scan api is unstable - consider using another provider",
+				fmt.Errorf("failed to get the latest block number: %v", err),
+			)
+		}
+		latency := time.Since(start)
+		total += latency
+		if latency > max {
+			max = latency
+		}
+	}
+	avg := total / time.Duration(d.samples)
+	details := fmt.Sprintf("avg %s, max %s over %d requests", avg.Round(time.Millisecond), max.Round(time.Millisecond), d.samples)
+	if avg > doctorSlowLatency {
+		return &doctorResult{
+			Name:    name,
+			Status:  doctorWarn,
+			Details: details,
+			Hint:    "the scan api is slow - run the node closer to the api or use a faster provider to avoid falling behind the chain",
+		}
+	}
+	return doctorPass(name, details)
+}
+
+// checkArchive checks if the state of an old block is available.
+func (d *doctor) checkArchive(ctx context.Context, rpcClient *rpc.Client) *doctorResult {
+	const name = "scan api archive state"
+	var balance hexutil.Big
+	err := d.call(ctx, rpcClient, &balance, "eth_getBalance", "0x0000000000000000000000000000000000000000", "0x1")
+	if err != nil {
+		return &doctorResult{
+			Name:    name,
+			Status:  doctorWarn,
+			Details: fmt.Sprintf("the state of the old blocks is not available: %v", err),
+			Hint:    "the bots which query the historical state need an archive node for the scan api",
+		}
+	}
+	return doctorPass(name, "available")
+}
+
+// checkTraceAPI checks if the trace api supports the configured trace method.
+func (d *doctor) checkTraceAPI(ctx context.Context) *doctorResult {
+	const name = "trace api"
+	if !d.cfg.Trace.Enabled {
+		return nil
+	}
+	hint := "check trace.jsonRpc.url in the config file"
+	if len(d.cfg.Trace.JsonRpc.Url) == 0 {
+		return doctorProblem(doctorFail, name, "set trace.jsonRpc.url in the config file or disable the tracing", fmt.Errorf("no url"))
+	}
+	rpcClient, err := d.dial(ctx, d.cfg.Trace.JsonRpc)
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to connect: %v", err))
+	}
+	defer rpcClient.Close()
+
+	var blockNumber hexutil.Uint64
+	if err := d.call(ctx, rpcClient, &blockNumber, "eth_blockNumber"); err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to get the latest block number: %v", err))
+	}
+	// the latest block can be too new for the traces to be ready
+	if blockNumber > 0 {
+		blockNumber--
+	}
+
+	api := d.cfg.Trace.API
+	if len(api) == 0 {
+		api = debugtrace.APITraceBlock
+	}
+	var traces interface{}
+	switch api {
+	case debugtrace.APIDebugTraceBlockByNumber:
+		err = d.call(ctx, rpcClient, &traces, api, blockNumber.String(), map[string]interface{}{"tracer": "callTracer"})
+	default:
+		err = d.call(ctx, rpcClient, &traces, api, blockNumber.String())
+	}
+	if err != nil {
+		return doctorProblem(
+			doctorFail, name,
+			fmt.Sprintf("make sure that the node supports %s or set trace.api to the trace method of the node", api),
+			fmt.Errorf("failed to trace block %d: %v", blockNumber, err),
+		)
+	}
+	return doctorPass(name, "%s is supported", api)
+}
+
+// checkWebsocket checks if the pending transactions can be subscribed to.
+func (d *doctor) checkWebsocket(ctx context.Context) *doctorResult {
+	const name = "websocket api"
+	wsURL := d.cfg.Scan.PendingTxs.WebsocketURL
+	if len(wsURL) == 0 {
+		return nil
+	}
+	hint := "check scan.pendingTxs.websocketUrl in the config file and make sure that the node has the websocket api enabled"
+	rpcClient, err := d.dial(ctx, config.JsonRpcConfig{Url: wsURL})
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to connect: %v", err))
+	}
+	defer rpcClient.Close()
+
+	subCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	sub, err := rpcClient.EthSubscribe(subCtx, make(chan string), "newPendingTransactions")
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to subscribe to the pending transactions: %v", err))
+	}
+	sub.Unsubscribe()
+	return doctorPass(name, "subscribed to the pending transactions")
+}
+
+func (d *doctor) checkRegistryAPI(ctx context.Context) *doctorResult {
+	const name = "registry api"
+	hint := "check registry.jsonRpc.url in the config file - the node needs a polygon api to find the bots"
+	rpcClient, err := d.dial(ctx, d.cfg.Registry.JsonRpc)
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to connect: %v", err))
+	}
+	defer rpcClient.Close()
+
+	var chainID hexutil.Big
+	if err := d.call(ctx, rpcClient, &chainID, "eth_chainId"); err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to get the chain ID: %v", err))
+	}
+	if chainID.ToInt().Uint64() != d.cfg.Registry.ChainID {
+		return doctorProblem(
+			doctorFail, name, hint,
+			fmt.Errorf("the api is on chain %s but the registry is on chain %d", chainID.ToInt(), d.cfg.Registry.ChainID),
+		)
+	}
+	return doctorPass(name, "connected to chain %d", d.cfg.Registry.ChainID)
+}
+
+func (d *doctor) checkDocker(ctx context.Context) *doctorResult {
+	const name = "docker"
+	hint := "make sure that docker is running and that this user can access the docker socket (e.g. is in the docker group)"
+	if d.dockerClient == nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to create the docker client"))
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	containers, err := d.dockerClient.GetContainers(ctx)
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to list the containers: %v", err))
+	}
+	return doctorPass(name, "connected (%d containers)", len(containers))
+}
+
+func (d *doctor) checkIPFS(ctx context.Context) *doctorResult {
+	const name = "ipfs"
+	hint := "check registry.ipfs.apiUrl in the config file - the node needs ipfs to get the bot manifests"
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(d.cfg.Registry.IPFS.APIURL, "/")+"/api/v0/version", nil)
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, err)
+	}
+	if len(d.cfg.Registry.IPFS.Username) > 0 {
+		req.SetBasicAuth(d.cfg.Registry.IPFS.Username, d.cfg.Registry.IPFS.Password)
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("failed to connect: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return doctorProblem(doctorFail, name, hint, fmt.Errorf("unexpected status: %s", resp.Status))
+	}
+	return doctorPass(name, "connected")
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// testJsonRpcServer responds with the results of the methods and fails the other methods.
+func testJsonRpcServer(results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var rpcReq struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(req.Body).Decode(&rpcReq)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": rpcReq.ID}
+		result, ok := results[rpcReq.Method]
+		if ok {
+			resp["result"] = json.RawMessage(result)
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "the method does not exist"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func testDoctor(cfg config.Config) *doctor {
+	return &doctor{cfg: cfg, timeout: 5 * time.Second, samples: 3, httpClient: http.DefaultClient}
+}
+
+func TestDoctorScanAPI(t *testing.T) {
+	r := require.New(t)
+
+	server := testJsonRpcServer(map[string]string{
+		"eth_chainId":     `"0x1"`,
+		"eth_blockNumber": `"0x10"`,
+	})
+	defer server.Close()
+
+	var doctorCfg config.Config
+	doctorCfg.ChainID = 1
+	doctorCfg.Scan.JsonRpc.Url = server.URL
+
+	results := testDoctor(doctorCfg).checkScanAPI(context.Background())
+	r.Len(results, 3)
+	r.Equal(doctorOK, results[0].Status)
+	r.Equal(doctorOK, results[1].Status)
+	r.Contains(results[1].Details, "over 3 requests")
+	// not an archive node
+	r.Equal(doctorWarn, results[2].Status)
+	r.NotEmpty(results[2].Hint)
+}
+
+func TestDoctorScanAPI_WrongChain(t *testing.T) {
+	r := require.New(t)
+
+	server := testJsonRpcServer(map[string]string{"eth_chainId": `"0x89"`})
+	defer server.Close()
+
+	var doctorCfg config.Config
+	doctorCfg.ChainID = 1
+	doctorCfg.Scan.JsonRpc.Url = server.URL
+
+	results := testDoctor(doctorCfg).checkScanAPI(context.Background())
+	r.Len(results, 1)
+	r.Equal(doctorFail, results[0].Status)
+	r.Contains(results[0].Details, "chain 137")
+}
+
+func TestDoctorTraceAPI(t *testing.T) {
+	r := require.New(t)
+
+	server := testJsonRpcServer(map[string]string{
+		"eth_blockNumber": `"0x10"`,
+		"trace_block":     `[]`,
+	})
+	defer server.Close()
+
+	var doctorCfg config.Config
+	doctorCfg.Trace.Enabled = true
+	doctorCfg.Trace.JsonRpc.Url = server.URL
+
+	result := testDoctor(doctorCfg).checkTraceAPI(context.Background())
+	r.Equal(doctorOK, result.Status)
+
+	// the node does not support the configured method
+	doctorCfg.Trace.API = "debug_traceBlockByNumber"
+	result = testDoctor(doctorCfg).checkTraceAPI(context.Background())
+	r.Equal(doctorFail, result.Status)
+	r.Contains(result.Hint, "debug_traceBlockByNumber")
+
+	// not checked without tracing
+	doctorCfg.Trace.Enabled = false
+	r.Nil(testDoctor(doctorCfg).checkTraceAPI(context.Background()))
+}
+
+func TestDoctorDocker(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+
+	d := testDoctor(config.Config{})
+	r.Equal(doctorFail, d.checkDocker(context.Background()).Status)
+
+	d.dockerClient = dockerClient
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(docker.ContainerList{}, nil)
+	r.Equal(doctorOK, d.checkDocker(context.Background()).Status)
+
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, errors.New("permission denied"))
+	result := d.checkDocker(context.Background())
+	r.Equal(doctorFail, result.Status)
+	r.Contains(result.Details, "permission denied")
+}
+
+func TestDoctorIPFS(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v0/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Version": "0.18.0"}`))
+	}))
+	defer server.Close()
+
+	var doctorCfg config.Config
+	doctorCfg.Registry.IPFS.APIURL = server.URL + "/"
+	r.Equal(doctorOK, testDoctor(doctorCfg).checkIPFS(context.Background()).Status)
+
+	doctorCfg.Registry.IPFS.APIURL = server.URL + "/wrong"
+	r.Equal(doctorFail, testDoctor(doctorCfg).checkIPFS(context.Background()).Status)
+}
+
+func TestDoctorKeys(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	_, err := keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP).NewAccount("Forta123")
+	r.NoError(err)
+
+	var doctorCfg config.Config
+	doctorCfg.KeyDirPath = dir
+	doctorCfg.Passphrase = "Forta123"
+	r.Equal(doctorOK, testDoctor(doctorCfg).checkKeys().Status)
+
+	doctorCfg.Passphrase = "wrong"
+	result := testDoctor(doctorCfg).checkKeys()
+	r.Equal(doctorFail, result.Status)
+	r.NotEmpty(result.Hint)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/scanner/fixture"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	testFormatPretty = "pretty"
+	testFormatJSON   = "json"
+)
+
+func handleFortaTest(cmd *cobra.Command, args []string) error {
+	fixturePath, err := cmd.Flags().GetString("fixture")
+	if err != nil {
+		return err
+	}
+	agentAddr, err := cmd.Flags().GetString("agent")
+	if err != nil {
+		return err
+	}
+	botID, err := cmd.Flags().GetString("bot-id")
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != testFormatPretty && format != testFormatJSON {
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+
+	events, err := fixture.Load(fixturePath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dialCtx, dialCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer dialCancel()
+	conn, err := grpc.DialContext(
+		dialCtx, agentAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the bot at %s: %v", agentAddr, err)
+	}
+	defer conn.Close()
+
+	runner := fixture.NewRunner(protocol.NewAgentClient(conn), timeout)
+	if err := runner.Initialize(ctx, botID); err != nil {
+		return err
+	}
+
+	var results []*fixtureResultOutput
+	report, err := runner.Run(ctx, events, func(result *fixture.Result) {
+		if format == testFormatJSON {
+			results = append(results, toFixtureResultOutput(result))
+			return
+		}
+		printFixtureResult(cmd, result)
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == testFormatJSON {
+		b, err := json.MarshalIndent(&fixtureRunOutput{Results: results, Report: report}, "", "  ")
+		if err != nil {
+			return err
+		}
+		cmd.Println(string(b))
+		return nil
+	}
+	printFixtureReport(cmd, report)
+	return nil
+}
+
+type fixtureResultOutput struct {
+	Event     string              `json:"event"`
+	LatencyMs int64               `json:"latencyMs"`
+	Error     string              `json:"error,omitempty"`
+	Findings  []*protocol.Finding `json:"findings,omitempty"`
+}
+
+type fixtureRunOutput struct {
+	Results []*fixtureResultOutput `json:"results"`
+	Report  *fixture.Report        `json:"report"`
+}
+
+func toFixtureResultOutput(result *fixture.Result) *fixtureResultOutput {
+	output := &fixtureResultOutput{
+		Event:     result.Event.String(),
+		LatencyMs: result.Latency.Milliseconds(),
+		Findings:  result.Findings,
+	}
+	if result.Err != nil {
+		output.Error = result.Err.Error()
+	}
+	return output
+}
+
+func printFixtureResult(cmd *cobra.Command, result *fixture.Result) {
+	if result.Err != nil {
+		redBold("%s: %v\n", result.Event, result.Err)
+		return
+	}
+	if len(result.Findings) == 0 {
+		cmd.Printf("%s: no findings (%s)\n", result.Event, result.Latency.Round(time.Millisecond))
+		return
+	}
+	greenBold("%s: %d finding(s) (%s)\n", result.Event, len(result.Findings), result.Latency.Round(time.Millisecond))
+	for _, finding := range result.Findings {
+		cmd.Printf("  [%s] %s (%s): %s\n", finding.Severity, finding.Name, finding.AlertId, finding.Description)
+	}
+}
+
+func printFixtureReport(cmd *cobra.Command, report *fixture.Report) {
+	whiteBold("\n%d event(s), %d finding(s), %d error(s)\n", report.Events, report.Findings, report.Errors)
+	for _, kind := range []string{"block", "tx"} {
+		stats, ok := report.Latency[kind]
+		if !ok {
+			continue
+		}
+		cmd.Printf(
+			"%s latency: count=%d min=%s avg=%s p50=%s p95=%s max=%s\n", kind, stats.Count,
+			stats.Min.Round(time.Microsecond), stats.Avg.Round(time.Microsecond), stats.P50.Round(time.Microsecond),
+			stats.P95.Round(time.Microsecond), stats.Max.Round(time.Microsecond),
+		)
+	}
+}
@@ -98,10 +98,11 @@ scan:
     url: <required>
 
 # Used for retrieving traces of all transactions in a block
-# Must support trace_block (e.g. Alchemy)
+# Must support trace_block (e.g. Alchemy) or debug_traceBlockByNumber (e.g. Geth)
 trace:
   jsonRpc:
     url: <required>
+  # api: debug_traceBlockByNumber
 
 # Used for loading assigned bots and detecting newer node versions
 # Always set this as a reliable Polygon JSON-RPC API
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/scanner/fakechain"
+	"github.com/forta-network/forta-node/services/scanner/fixture"
+	"github.com/forta-network/forta-node/services/scanner/loadtest"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func handleFortaLoadTest(cmd *cobra.Command, args []string) error {
+	agentAddrs, err := cmd.Flags().GetStringSlice("agent")
+	if err != nil {
+		return err
+	}
+	botID, err := cmd.Flags().GetString("bot-id")
+	if err != nil {
+		return err
+	}
+	profilePath, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != testFormatPretty && format != testFormatJSON {
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	var ltCfg loadtest.Config
+	if ltCfg.TPS, err = cmd.Flags().GetInt("tps"); err != nil {
+		return err
+	}
+	if ltCfg.Duration, err = cmd.Flags().GetDuration("duration"); err != nil {
+		return err
+	}
+	if ltCfg.Concurrency, err = cmd.Flags().GetInt("concurrency"); err != nil {
+		return err
+	}
+	if ltCfg.BufferSize, err = cmd.Flags().GetInt("buffer"); err != nil {
+		return err
+	}
+	if ltCfg.Timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return err
+	}
+	if ltCfg.Seed, err = cmd.Flags().GetInt64("seed"); err != nil {
+		return err
+	}
+	if ltCfg.ChainID, err = cmd.Flags().GetUint64("chain-id"); err != nil {
+		return err
+	}
+	if len(profilePath) > 0 {
+		ltCfg.Profile, err = loadTxProfile(profilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var bots []*loadtest.Bot
+	for _, agentAddr := range agentAddrs {
+		dialCtx, dialCancel := context.WithTimeout(ctx, 10*time.Second)
+		conn, err := grpc.DialContext(
+			dialCtx, agentAddr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithBlock(),
+		)
+		dialCancel()
+		if err != nil {
+			return fmt.Errorf("failed to connect to the bot at %s: %v", agentAddr, err)
+		}
+		defer conn.Close()
+
+		client := protocol.NewAgentClient(conn)
+		if err := fixture.NewRunner(client, ltCfg.Timeout).Initialize(ctx, botID); err != nil {
+			return fmt.Errorf("failed to initialize the bot at %s: %v", agentAddr, err)
+		}
+		bots = append(bots, &loadtest.Bot{Name: agentAddr, Client: client})
+	}
+
+	if format == testFormatPretty {
+		whiteBold("Sending %d tx/s to %d bot(s) for %s...\n", ltCfg.TPS, len(bots), ltCfg.Duration)
+	}
+	report, err := loadtest.Run(ctx, ltCfg, bots)
+	if err != nil && err != context.Canceled {
+		return err
+	}
+
+	if format == testFormatJSON {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		cmd.Println(string(b))
+		return nil
+	}
+	printLoadTestReport(cmd, report)
+	return nil
+}
+
+// loadTxProfile reads a tx profile or records it from the blocks of a JSON fixture.
+func loadTxProfile(profilePath string) (*fakechain.TxProfile, error) {
+	b, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the profile: %v", err)
+	}
+	var profileFile struct {
+		fakechain.TxProfile
+		Blocks []*domain.Block `json:"blocks"`
+	}
+	if err := json.Unmarshal(b, &profileFile); err != nil {
+		return nil, fmt.Errorf("failed to decode the profile: %v", err)
+	}
+	if len(profileFile.Blocks) > 0 {
+		return fakechain.ProfileFromBlocks(profileFile.Blocks), nil
+	}
+	return &profileFile.TxProfile, nil
+}
+
+func printLoadTestReport(cmd *cobra.Command, report *loadtest.Report) {
+	whiteBold(
+		"\ngenerated %d tx(s) in %s (%.1f tx/s, target %d tx/s)\n", report.Generated,
+		report.Duration.Round(time.Millisecond), report.GeneratedTPS, report.TargetTPS,
+	)
+	for _, bot := range report.Bots {
+		botColor := color.New(color.Bold, color.FgGreen)
+		if bot.Dropped > 0 || bot.Errors > 0 {
+			botColor = color.New(color.Bold, color.FgYellow)
+		}
+		botColor.Printf("%s: %.1f tx/s\n", bot.Name, bot.Throughput)
+		cmd.Printf(
+			"  sent=%d completed=%d errors=%d dropped=%d findings=%d\n",
+			bot.Sent, bot.Completed, bot.Errors, bot.Dropped, bot.Findings,
+		)
+		cmd.Printf(
+			"  latency: min=%s avg=%s p50=%s p90=%s p99=%s max=%s\n",
+			bot.Latency.Min.Round(time.Microsecond), bot.Latency.Avg.Round(time.Microsecond),
+			bot.Latency.P50.Round(time.Microsecond), bot.Latency.P90.Round(time.Microsecond),
+			bot.Latency.P99.Round(time.Microsecond), bot.Latency.Max.Round(time.Microsecond),
+		)
+	}
+	cmd.Printf(
+		"resources: cpu=%s max-heap=%.1fMB goroutines=%d\n", report.Resources.CPUTime.Round(time.Millisecond),
+		float64(report.Resources.MaxHeapBytes)/(1<<20), report.Resources.Goroutines,
+	)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTxProfile(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	profilePath := path.Join(dir, "profile.json")
+	r.NoError(os.WriteFile(profilePath, []byte(`{"contractCallRatio": 0.5, "inputSizes": [4, 68]}`), 0644))
+	profile, err := loadTxProfile(profilePath)
+	r.NoError(err)
+	r.Equal(0.5, profile.ContractCallRatio)
+	r.Equal([]int{4, 68}, profile.InputSizes)
+
+	fixturePath := path.Join(dir, "fixture.json")
+	r.NoError(os.WriteFile(fixturePath, []byte(`{"blocks": [{"number": "0x1", "transactions": [{"input": "0x12345678", "value": "0x0"}]}]}`), 0644))
+	profile, err = loadTxProfile(fixturePath)
+	r.NoError(err)
+	r.Equal(1.0, profile.ContractCallRatio)
+	r.Equal(0.0, profile.ValueTransferRatio)
+	r.Equal([]int{4}, profile.InputSizes)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"path"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+)
+
+func agentLogStore() store.AgentLogStore {
+	captureCfg := cfg.AgentLogsConfig.Capture
+	return store.NewAgentLogStore(
+		path.Join(cfg.FortaDir, config.DefaultAgentLogsDirName),
+		int64(captureCfg.MaxFileSizeMB)*1024*1024, captureCfg.MaxFiles,
+	)
+}
+
+func handleFortaLogs(cmd *cobra.Command, args []string) error {
+	tail, err := cmd.Flags().GetInt("tail")
+	if err != nil {
+		return err
+	}
+	return showAgentLogs(cmd, agentLogStore(), args, tail)
+}
+
+// showAgentLogs prints the last lines of the bot logs or lists the bots with logs if no bot is specified.
+func showAgentLogs(cmd *cobra.Command, als store.AgentLogStore, args []string, tail int) error {
+	if len(args) == 0 {
+		botIDs, err := als.List()
+		if err != nil {
+			return err
+		}
+		if len(botIDs) == 0 {
+			cmd.Println("No agent logs.")
+			return nil
+		}
+		for _, botID := range botIDs {
+			cmd.Println(botID)
+		}
+		return nil
+	}
+
+	botID, err := store.FindAgentLogs(als, args[0])
+	if err != nil {
+		return err
+	}
+	lines, err := als.Tail(botID, tail)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		cmd.Println(line)
+	}
+	return nil
+}
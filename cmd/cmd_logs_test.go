@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowAgentLogs(t *testing.T) {
+	r := require.New(t)
+
+	als := store.NewAgentLogStore(t.TempDir(), 0, 0)
+	r.NoError(als.Append("0xabc", []byte("line 1\nline 2\n")))
+	r.NoError(als.Append("0xdef", []byte("other\n")))
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	r.NoError(showAgentLogs(cmd, als, nil, 10))
+	r.Equal("0xabc\n0xdef\n", out.String())
+
+	out.Reset()
+	r.NoError(showAgentLogs(cmd, als, []string{"0xa"}, 1))
+	r.Equal("line 2\n", out.String())
+
+	r.ErrorIs(showAgentLogs(cmd, als, []string{"0x1"}, 1), store.ErrAgentLogsNotFound)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/fatih/color"
+	"github.com/forta-network/forta-core-go/registry"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+)
+
+// ErrRegisterNeedsPool is returned when the scanner is not registered and the pool is unknown.
+var ErrRegisterNeedsPool = errors.New("scanner is not registered: please provide the pool with --pool-id")
+
+func handleFortaRegister(cmd *cobra.Command, args []string) error {
+	poolID, err := cmd.Flags().GetInt64("pool-id")
+	if err != nil {
+		return err
+	}
+	polygonscan, _ := cmd.Flags().GetBool("polygonscan")
+
+	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to load scanner key: %v", err)
+	}
+
+	regClient, err := store.GetRegistryClient(context.Background(), cfg, registry.ClientConfig{
+		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
+		ENSAddress: cfg.ENSConfig.ContractAddress,
+		Name:       "registry-client",
+		PrivateKey: scannerKey.PrivateKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %v", err)
+	}
+
+	return registerWithRegistry(regClient, scannerKey, poolID, polygonscan)
+}
+
+// registerWithRegistry shows the state of the registered scanners and generates the registration
+// signature for the others.
+func registerWithRegistry(regClient registry.Client, scannerKey *keystore.Key, poolID int64, polygonscan bool) error {
+	regClient.SetRegistryChainID(cfg.Registry.ChainID)
+
+	minStake, err := store.ParseMinStake(cfg.Registry.ScannerCheck.MinStakeFORT)
+	if err != nil {
+		return err
+	}
+	state, err := store.CheckScanner(regClient, scannerKey.Address.Hex(), minStake)
+	if err != nil {
+		return fmt.Errorf("failed to check scanner state: %v", err)
+	}
+
+	if state.Registered {
+		whiteBold("This scanner is already registered to pool %s.\n", state.PoolID)
+		if state.Verified() {
+			color.New(color.FgGreen).Println("The scanner is enabled and staked enough.")
+			return nil
+		}
+		if !state.Enabled {
+			yellowBold("The scanner is either disabled or does not meet with the minimum stake requirement.\n")
+		}
+		if !state.Staked() {
+			yellowBold("The stake allocated to the scanner is below the configured minimum (%s FORT).\n", cfg.Registry.ScannerCheck.MinStakeFORT)
+		}
+		return nil
+	}
+
+	if poolID <= 0 {
+		return ErrRegisterNeedsPool
+	}
+	return authorizePoolWithRegistry(regClient, scannerKey, poolID, polygonscan, false, false)
+}
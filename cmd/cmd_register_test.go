@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-core-go/registry"
+	mock_registry "github.com/forta-network/forta-core-go/registry/mocks"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister_Registered(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regClient := mock_registry.NewMockClient(ctrl)
+
+	dir := t.TempDir()
+	scannerKeyStore := keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+
+	_, err := scannerKeyStore.NewAccount("Forta123")
+	r.NoError(err)
+
+	scannerKey, err := security.LoadKeyWithPassphrase(dir, "Forta123")
+	r.NoError(err)
+
+	regClient.EXPECT().SetRegistryChainID(cfg.Registry.ChainID)
+	regClient.EXPECT().GetScanner(scannerKey.Address.Hex()).Return(&registry.Scanner{Enabled: true, PoolID: "1"}, nil)
+
+	r.NoError(registerWithRegistry(regClient, scannerKey, 0, false))
+}
+
+func TestRegister_NeedsPool(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regClient := mock_registry.NewMockClient(ctrl)
+
+	dir := t.TempDir()
+	scannerKeyStore := keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+
+	_, err := scannerKeyStore.NewAccount("Forta123")
+	r.NoError(err)
+
+	scannerKey, err := security.LoadKeyWithPassphrase(dir, "Forta123")
+	r.NoError(err)
+
+	regClient.EXPECT().SetRegistryChainID(cfg.Registry.ChainID)
+	regClient.EXPECT().GetScanner(scannerKey.Address.Hex()).Return(nil, nil)
+
+	r.ErrorIs(registerWithRegistry(regClient, scannerKey, 0, false), ErrRegisterNeedsPool)
+}
@@ -8,19 +8,26 @@ import (
 	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-node/cmd/runner"
+	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/store"
 	"github.com/spf13/cobra"
 )
 
 // errors
 var (
-	ErrCannotRunScanner = errors.New("cannot run scanner")
+	ErrCannotRunScanner     = errors.New("cannot run scanner")
+	ErrReplayNeedsLocalMode = errors.New("replay is available only in local mode")
+	ErrReplayNeedsRange     = errors.New("replay needs both --replay-from and --replay-to")
+	ErrReplayInvalidRange   = errors.New("--replay-to should not be less than --replay-from")
 )
 
 func handleFortaRun(cmd *cobra.Command, args []string) error {
 	if err := checkScannerState(); err != nil {
 		return err
 	}
+	if err := applyReplayRange(cmd); err != nil {
+		return err
+	}
 	if cfg.LocalModeConfig.Enable {
 		whiteBold("Running in local mode...\n")
 		if len(cfg.LocalModeConfig.WebhookURL) > 0 {
@@ -33,6 +40,29 @@ func handleFortaRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applyReplayRange sets the local mode runtime limits from the replay flags so that
+// the scanner scans the historical block range instead of following the latest blocks.
+func applyReplayRange(cmd *cobra.Command) error {
+	replayFrom := cmd.Flags().Changed("replay-from")
+	replayTo := cmd.Flags().Changed("replay-to")
+	if !replayFrom && !replayTo {
+		return nil
+	}
+	if !cfg.LocalModeConfig.Enable {
+		return ErrReplayNeedsLocalMode
+	}
+	if !replayFrom || !replayTo {
+		return ErrReplayNeedsRange
+	}
+	if parsedArgs.ReplayTo < parsedArgs.ReplayFrom {
+		return ErrReplayInvalidRange
+	}
+	cfg.LocalModeConfig.RuntimeLimits.StartBlock = &parsedArgs.ReplayFrom
+	cfg.LocalModeConfig.RuntimeLimits.StopBlock = &parsedArgs.ReplayTo
+	whiteBold("Replaying blocks %d to %d...\n", parsedArgs.ReplayFrom, parsedArgs.ReplayTo)
+	return nil
+}
+
 func checkScannerState() error {
 	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
 	if err != nil {
@@ -58,19 +88,33 @@ func checkScannerState() error {
 	if err != nil {
 		return fmt.Errorf("failed to create registry client: %v", err)
 	}
-	scanner, err := registry.GetScanner(scannerAddressStr)
+	minStake, err := store.ParseMinStake(cfg.Registry.ScannerCheck.MinStakeFORT)
+	if err != nil {
+		return err
+	}
+	state, err := store.CheckScanner(registry, scannerAddressStr, minStake)
 	if err != nil {
 		return fmt.Errorf("failed to check scanner state: %v", err)
 	}
 
-	// treat reverts the same as non-registered
-	if scanner == nil {
-		yellowBold("Scanner not registered - please make sure you register first.\n")
+	if !state.Registered {
+		yellowBold("Scanner not registered - please make sure you register first with 'forta register'.\n")
 		toStderr("You can disable this behaviour with --no-check flag.\n")
 		return ErrCannotRunScanner
 	}
-	if !scanner.Enabled {
+	if !state.Enabled {
 		yellowBold("Warning! Your scan node is either disabled or does not meet with the minimum stake requirement. It will not receive any detection bots yet.\n")
 	}
+	if !state.Staked() {
+		yellowBold("Warning! The stake allocated to your scan node is below the configured minimum (%s FORT).\n", cfg.Registry.ScannerCheck.MinStakeFORT)
+	}
+	if !state.Verified() {
+		switch cfg.Registry.ScannerCheck.OnUnverified {
+		case config.UnverifiedScannerRefuse:
+			yellowBold("The alerts will not be published until the scan node is verified.\n")
+		case config.UnverifiedScannerMark:
+			yellowBold("The alerts will be marked as unverified until the scan node is verified.\n")
+		}
+	}
 	return nil
 }
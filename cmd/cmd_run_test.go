@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func newReplayCmd(t *testing.T, from, to string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Uint64Var(&parsedArgs.ReplayFrom, "replay-from", 0, "")
+	cmd.Flags().Uint64Var(&parsedArgs.ReplayTo, "replay-to", 0, "")
+	if len(from) > 0 {
+		require.NoError(t, cmd.Flags().Set("replay-from", from))
+	}
+	if len(to) > 0 {
+		require.NoError(t, cmd.Flags().Set("replay-to", to))
+	}
+	return cmd
+}
+
+func TestApplyReplayRange(t *testing.T) {
+	r := require.New(t)
+
+	prevCfg := cfg
+	defer func() {
+		cfg = prevCfg
+	}()
+	cfg = config.Config{}
+
+	r.NoError(applyReplayRange(newReplayCmd(t, "", "")))
+	r.ErrorIs(applyReplayRange(newReplayCmd(t, "10", "20")), ErrReplayNeedsLocalMode)
+
+	cfg.LocalModeConfig.Enable = true
+	r.ErrorIs(applyReplayRange(newReplayCmd(t, "10", "")), ErrReplayNeedsRange)
+	r.ErrorIs(applyReplayRange(newReplayCmd(t, "20", "10")), ErrReplayInvalidRange)
+
+	r.NoError(applyReplayRange(newReplayCmd(t, "10", "20")))
+	r.Equal(uint64(10), *cfg.LocalModeConfig.RuntimeLimits.StartBlock)
+	r.Equal(uint64(20), *cfg.LocalModeConfig.RuntimeLimits.StopBlock)
+
+	// the range is inclusive so a single block can be replayed
+	r.NoError(applyReplayRange(newReplayCmd(t, "15", "15")))
+	r.Equal(uint64(15), *cfg.LocalModeConfig.RuntimeLimits.StartBlock)
+	r.Equal(uint64(15), *cfg.LocalModeConfig.RuntimeLimits.StopBlock)
+}
@@ -2,13 +2,23 @@ package json_rpc
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/agentstore"
+	"github.com/forta-network/forta-node/services/components/reload"
+	"github.com/forta-network/forta-node/services/history"
 	jrp "github.com/forta-network/forta-node/services/json-rpc"
+	"github.com/forta-network/forta-node/store"
 )
 
 func initJsonRpcProxy(ctx context.Context, cfg config.Config) (*jrp.JsonRpcProxy, error) {
@@ -19,19 +29,112 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	// can't dial localhost - need to dial host gateway from container
 	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
 	cfg.JsonRpcProxy.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.JsonRpcProxy.JsonRpc.Url)
+	for i := range cfg.Chains {
+		cfg.Chains[i].JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Chains[i].JsonRpc.Url)
+	}
 
 	proxy, err := initJsonRpcProxy(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return []services.Service{
+	historyAPI, err := initHistoryAPI(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	agentStoreAPI, err := initAgentStoreAPI(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := reload.New(config.GetConfigForContainer, nil)
+	if err != nil {
+		return nil, err
+	}
+	reloader.Handle(proxy.PrepareRateLimits, reload.RateLimitSettings...)
+	reloader.Delegate(reload.ScannerSettings...)
+	reloader.Delegate(reload.LocalBotSettings...)
+	services.OnReload(func() {
+		_, _ = reloader.Reload()
+	})
+
+	reporters := []health.Reporter{proxy}
+	svcs := []services.Service{proxy}
+	if historyAPI != nil {
+		reporters = append(reporters, historyAPI)
+		svcs = append(svcs, historyAPI)
+	}
+	if agentStoreAPI != nil {
+		reporters = append(reporters, agentStoreAPI)
+		svcs = append(svcs, agentStoreAPI)
+	}
+
+	return append([]services.Service{
 		health.NewService(
 			ctx, "", healthutils.DefaultHealthServerErrHandler,
-			health.CheckerFrom(summarizeReports, proxy),
+			health.CheckerFrom(summarizeReports, reporters...),
 		),
-		proxy,
-	}, nil
+	}, svcs...), nil
+}
+
+// initHistoryAPI creates the history API which uses the same JSON-RPC API as the proxy. It returns
+// nil if the history API is disabled.
+func initHistoryAPI(ctx context.Context, cfg config.Config) (*history.API, error) {
+	if !cfg.JsonRpcProxy.History.Enable {
+		return nil, nil
+	}
+	jCfg := cfg.Scan.JsonRpc
+	if len(cfg.JsonRpcProxy.JsonRpc.Url) > 0 {
+		jCfg = cfg.JsonRpcProxy.JsonRpc
+	}
+	headers := make(http.Header)
+	for k, v := range jCfg.Headers {
+		headers.Set(k, v)
+	}
+	rpcClient, err := rpc.DialOptions(ctx, jCfg.Url, rpc.WithHeaders(headers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the json-rpc api for the history api: %v", err)
+	}
+
+	botAuthenticator, err := clients.NewBotAuthenticator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts history.AlertSource
+	if cfg.AlertQueryAPI.Enable {
+		alerts = history.NewAlertQuerySource(
+			fmt.Sprintf("http://%s:%s", config.DockerScannerContainerName, cfg.AlertQueryAPI.Port),
+			cfg.AlertQueryAPI.Token, http.DefaultClient,
+		)
+	}
+
+	return history.NewAPI(ctx, history.APIConfig{
+		Port:             config.DefaultHistoryAPIPort,
+		Config:           cfg.JsonRpcProxy.History,
+		Blocks:           history.NewRPCBlockSource(rpcClient),
+		BotAuthenticator: botAuthenticator,
+		Alerts:           alerts,
+	})
+}
+
+// initAgentStoreAPI creates the agent store API which keeps the stores of the bots in the forta dir.
+// It returns nil if the agent store is disabled.
+func initAgentStoreAPI(ctx context.Context, cfg config.Config) (*agentstore.API, error) {
+	if !cfg.AgentStore.Enable {
+		return nil, nil
+	}
+	botAuthenticator, err := clients.NewBotAuthenticator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return agentstore.NewAPI(agentstore.APIConfig{
+		Port:             config.DefaultAgentStoreAPIPort,
+		Config:           cfg.AgentStore,
+		Store:            store.NewAgentStore(path.Join(cfg.FortaDir, config.DefaultAgentStoreDirName), agentstore.Limits(cfg.AgentStore)),
+		BotAuthenticator: botAuthenticator,
+	})
 }
 
 func summarizeReports(reports health.Reports) *health.Report {
@@ -4,15 +4,20 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"time"
 
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/admin"
 	"github.com/forta-network/forta-node/services/runner"
 	"github.com/forta-network/forta-node/store"
 	log "github.com/sirupsen/logrus"
 )
 
+const reloadTimeout = time.Minute
+
 func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
 	shouldDisableAutoUpdate := cfg.AutoUpdate.Disable
 	imgStore, err := store.NewFortaImageStore(ctx, config.DefaultContainerPort, !shouldDisableAutoUpdate)
@@ -46,6 +51,10 @@ func Run(cfg config.Config) {
 	logger.Info("starting")
 	defer logger.Info("exiting")
 
+	services.OnReload(func() {
+		reloadNode(cfg)
+	})
+
 	serviceList, err := initServices(ctx, cfg)
 	if err != nil {
 		logger.WithError(err).Error("could not initialize services")
@@ -61,3 +70,29 @@ func Run(cfg config.Config) {
 		logger.WithError(err).Error("error running services")
 	}
 }
+
+// reloadNode forwards the reload signal to the scanner through the admin api, since the runner
+// does not have the reloadable settings.
+func reloadNode(cfg config.Config) {
+	if !cfg.AdminAPI.Enable {
+		log.Warn("admin api is not enabled - please enable it to reload the config without restarting")
+		return
+	}
+	conn, err := admin.DialSocket(path.Join(cfg.FortaDir, cfg.AdminAPI.SocketName))
+	if err != nil {
+		log.WithError(err).Error("failed to connect to the admin api")
+		return
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+	report, err := admin.NewClient(conn).ReloadConfig(ctx)
+	if err != nil {
+		log.WithError(err).Error("failed to reload the config")
+		return
+	}
+	log.WithFields(log.Fields{
+		"applied":         report.Applied,
+		"requiresRestart": report.RequiresRestart,
+	}).Info("reloaded the config")
+}
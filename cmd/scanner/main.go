@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -12,8 +13,21 @@ import (
 
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol/settings"
+	"github.com/forta-network/forta-node/services/admin"
+	"github.com/forta-network/forta-node/services/alertquery"
 	"github.com/forta-network/forta-node/services/components"
+	"github.com/forta-network/forta-node/services/components/abidecoder"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/forta-network/forta-node/services/components/chaos"
+	"github.com/forta-network/forta-node/services/components/coverage"
+	"github.com/forta-network/forta-node/services/components/reload"
+	"github.com/forta-network/forta-node/services/components/tokens"
+	"github.com/forta-network/forta-node/services/components/tracing"
+	"github.com/forta-network/forta-node/services/components/watchlist"
+	"github.com/forta-network/forta-node/services/exporter"
+	"github.com/forta-network/forta-node/services/ingest"
 	"github.com/forta-network/forta-node/services/publisher"
+	"github.com/forta-network/forta-node/services/statusapi"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
@@ -24,14 +38,27 @@ import (
 	"github.com/forta-network/forta-core-go/feeds"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/clients/debugtrace"
+	"github.com/forta-network/forta-node/clients/failover"
+	"github.com/forta-network/forta-node/clients/feehistory"
+	"github.com/forta-network/forta-node/clients/finality"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/privateflow"
+	"github.com/forta-network/forta-node/clients/rawtx"
+	"github.com/forta-network/forta-node/clients/statediff"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/forta-network/forta-node/services/scanner/fakechain"
 )
 
-func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, cfg config.Config) (*scanner.TxStreamService, feeds.BlockFeed, error) {
+func initTxStream(
+	ctx context.Context, ethClient, traceClient ethereum.Client, checkpoints store.CheckpointStore, checkpoint string,
+	snapshot *store.PipelineSnapshot, gasContext scanner.GasContextSource, duplicateBlocks *scanner.DuplicateBlocks,
+	coverageTracker *coverage.Tracker, cfg config.Config,
+) (*scanner.TxStreamService, feeds.BlockFeed, error) {
 	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
 	cfg.JsonRpcProxy.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
@@ -50,13 +77,18 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 
 	var rateLimit *time.Ticker
 	if cfg.Scan.BlockRateLimit > 0 {
-		rateLimit = time.NewTicker(time.Duration(cfg.Scan.BlockRateLimit) * time.Millisecond)
+		// the feed should get the blocks faster than the chain produces them
+		rateLimit = time.NewTicker(
+			config.GetChainProfile(cfg.ChainID).BlockRateLimit(time.Duration(cfg.Scan.BlockRateLimit) * time.Millisecond),
+		)
 	}
 
 	var maxAgePtr *time.Duration
 	// support scanning old block ranges in local mode
 	hasLocalModeBlockRange := cfg.LocalModeConfig.Enable && cfg.LocalModeConfig.RuntimeLimits.StopBlock != nil
-	if !hasLocalModeBlockRange && cfg.Scan.BlockMaxAgeSeconds > 0 {
+	// the confirmed and the finalized blocks are expected to be old
+	delaysBlocks := cfg.Scan.Finality.Mode == config.FinalityModeConfirmations || cfg.Scan.Finality.Mode == config.FinalityModeFinalized
+	if !hasLocalModeBlockRange && !delaysBlocks && !cfg.Scan.FakeChain.Enable && cfg.Scan.BlockMaxAgeSeconds > 0 {
 		maxAge := time.Duration(cfg.Scan.BlockMaxAgeSeconds) * time.Second
 		maxAgePtr = &maxAge
 	}
@@ -75,23 +107,59 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 		}
 	}
 
+	// resume from the block after the last processed one unless a start block is specified
+	if startBlock == nil && !cfg.Scan.DisableCheckpoints {
+		checkpointBlock, ok, err := checkpoints.GetCheckpoint(checkpoint)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get block checkpoint: %v", err)
+		}
+		if ok {
+			startBlock = big.NewInt(0).SetUint64(checkpointBlock + 1)
+			log.WithFields(log.Fields{
+				"chainId":    cfg.ChainID,
+				"startBlock": startBlock,
+			}).Info("resuming from the block checkpoint")
+		}
+		// the blocks which the bots did not finish before the shutdown are dispatched again
+		if cursor, ok := scanner.SnapshotCursor(snapshot, checkpoint); ok && (startBlock == nil || cursor < startBlock.Uint64()) {
+			startBlock = big.NewInt(0).SetUint64(cursor)
+			log.WithFields(log.Fields{
+				"chainId":    cfg.ChainID,
+				"startBlock": startBlock,
+			}).Info("resuming from the pipeline snapshot")
+		}
+	}
+
 	if startBlock != nil && stopBlock != nil && !(stopBlock.Cmp(startBlock) > 0) {
 		log.Fatal("stop block is not greater than the start block - please check the runtime limits")
 	}
 
+	// the blocks from the start block are dispatched again on purpose
+	if duplicateBlocks != nil && startBlock != nil {
+		if err := duplicateBlocks.Forget(startBlock.Uint64()); err != nil {
+			return nil, nil, fmt.Errorf("failed to forget the dispatched blocks: %v", err)
+		}
+	}
+
 	ethClient.SetRetryInterval(time.Second * time.Duration(cfg.Scan.RetryIntervalSeconds))
 
-	blockFeed, err := feeds.NewBlockFeed(ctx, ethClient, traceClient, feeds.BlockFeedConfig{
-		ChainID:             chainID,
-		Tracing:             cfg.Trace.Enabled,
-		RateLimit:           rateLimit,
-		SkipBlocksOlderThan: maxAgePtr,
-		Offset:              getBlockOffset(cfg),
-		Start:               startBlock,
-		End:                 stopBlock,
-	})
-	if err != nil {
-		return nil, nil, err
+	var blockFeed feeds.BlockFeed
+	if cfg.Scan.FakeChain.Enable {
+		blockFeed = initFakeChain(ctx, cfg, startBlock, stopBlock)
+	} else {
+		var err error
+		blockFeed, err = feeds.NewBlockFeed(ctx, ethClient, traceClient, feeds.BlockFeedConfig{
+			ChainID:             chainID,
+			Tracing:             cfg.Trace.Enabled,
+			RateLimit:           rateLimit,
+			SkipBlocksOlderThan: maxAgePtr,
+			Offset:              getBlockOffset(cfg),
+			Start:               startBlock,
+			End:                 stopBlock,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// subscribe to block feed so we can detect block end and trigger exit
@@ -120,10 +188,21 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 		services.TriggerExit(delay)
 	}()
 
+	// the canonical blocks which are dispatched again after a reorg are traced like the feed traces the blocks
+	var streamTraceClient ethereum.Client
+	if cfg.Trace.Enabled {
+		streamTraceClient = traceClient
+	}
 	txStream, err := scanner.NewTxStreamService(ctx, ethClient, blockFeed, scanner.TxStreamServiceConfig{
 		JsonRpcConfig:       cfg.Scan.JsonRpc,
 		TraceJsonRpcConfig:  cfg.Trace.JsonRpc,
 		SkipBlocksOlderThan: maxAgePtr,
+		FetchReceipts:       cfg.Scan.FetchReceipts,
+		GasContext:          gasContext,
+		DuplicateBlocks:     duplicateBlocks,
+		Coverage:            coverageTracker,
+		Reorgs:              scanner.NewReorgDetector(scanner.DefaultReorgDetectionWindow),
+		TraceClient:         streamTraceClient,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create the tx stream service: %v", err)
@@ -132,6 +211,34 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 	return txStream, blockFeed, nil
 }
 
+// initFakeChain creates the fake chain which replaces the block feed. The checkpoints and
+// the runtime limits override the configured block range.
+func initFakeChain(ctx context.Context, cfg config.Config, startBlock, stopBlock *big.Int) *fakechain.Chain {
+	fakeChainCfg := cfg.Scan.FakeChain
+	chainCfg := fakechain.Config{
+		ChainID:       config.ParseBigInt(cfg.ChainID),
+		StartBlock:    fakeChainCfg.StartBlock,
+		EndBlock:      fakeChainCfg.EndBlock,
+		BlockInterval: time.Duration(fakeChainCfg.BlockIntervalMs) * time.Millisecond,
+		TxsPerBlock:   fakeChainCfg.TxsPerBlock,
+		Accounts:      fakeChainCfg.Accounts,
+		Seed:          fakeChainCfg.Seed,
+	}
+	if startBlock != nil {
+		chainCfg.StartBlock = startBlock.Uint64()
+	}
+	if stopBlock != nil {
+		chainCfg.EndBlock = stopBlock.Uint64()
+	}
+	log.WithFields(log.Fields{
+		"chainId":     cfg.ChainID,
+		"startBlock":  chainCfg.StartBlock,
+		"txsPerBlock": chainCfg.TxsPerBlock,
+		"interval":    chainCfg.BlockInterval,
+	}).Warn("scanning a fake chain")
+	return fakechain.New(ctx, chainCfg)
+}
+
 // getBlockOffset either returns the default offset configured for the chain or
 // the safe offset if required.
 func getBlockOffset(cfg config.Config) int {
@@ -150,7 +257,9 @@ func getBlockOffset(cfg config.Config) int {
 	return chainSettings.DefaultOffset
 }
 
-func initCombinationStream(ctx context.Context, msgClient clients.MessageClient, cfg config.Config) (*scanner.CombinerAlertStreamService, feeds.AlertFeed, error) {
+func initCombinationStream(
+	ctx context.Context, msgClient clients.MessageClient, cfg config.Config, localAlertFeed *scanner.LocalAlertFeed,
+) (*scanner.CombinerAlertStreamService, feeds.AlertFeed, error) {
 	combinerFeed, err := feeds.NewCombinerFeed(
 		ctx, feeds.CombinerFeedConfig{
 			APIUrl:            cfg.CombinerConfig.AlertAPIURL,
@@ -164,12 +273,15 @@ func initCombinationStream(ctx context.Context, msgClient clients.MessageClient,
 		return nil, nil, fmt.Errorf("failed to create combiner feed: %v", err)
 	}
 
-	combinerStream, err := scanner.NewCombinerAlertStreamService(
-		ctx, combinerFeed, msgClient, scanner.CombinerAlertStreamServiceConfig{
-			Start: cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
-			End:   cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
-		},
-	)
+	streamCfg := scanner.CombinerAlertStreamServiceConfig{
+		Start: cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
+		End:   cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
+	}
+	// avoid assigning a nil pointer to the interface
+	if localAlertFeed != nil {
+		streamCfg.LocalFeed = localAlertFeed
+	}
+	combinerStream, err := scanner.NewCombinerAlertStreamService(ctx, combinerFeed, msgClient, streamCfg)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create the tx stream service: %v", err)
 	}
@@ -207,49 +319,500 @@ func initCombinationStream(ctx context.Context, msgClient clients.MessageClient,
 	return combinerStream, combinerFeed, nil
 }
 
+func initPendingTxStream(ctx context.Context, cfg config.Config) (*scanner.PendingTxStreamService, error) {
+	var tracker *scanner.MempoolTracker
+	if pendingCfg := cfg.Scan.PendingTxs; pendingCfg.TrackStatus {
+		tracker = scanner.NewMempoolTracker(
+			pendingCfg.MaxTrackedTxs, time.Duration(pendingCfg.StatusTimeoutSeconds)*time.Second,
+		)
+	}
+	return scanner.NewPendingTxStreamService(ctx, scanner.PendingTxStreamServiceConfig{
+		WebsocketURL: utils.ConvertToDockerHostURL(cfg.Scan.PendingTxs.WebsocketURL),
+		ChainID:      config.ParseBigInt(cfg.ChainID),
+		Tracker:      tracker,
+	})
+}
+
 func initTxAnalyzer(
 	ctx context.Context, cfg config.Config,
-	as clients.AlertSender, stream *scanner.TxStreamService,
+	as clients.AlertSender, stream scanner.EventStream, pendingStream *scanner.PendingTxStreamService,
+	reorgDetector *scanner.ReorgDetector, botWarnings *scanner.BotWarnings,
+	responseLogger *scanner.ResponseLogger,
 	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
+	decoder *abidecoder.Registry, gasContext scanner.GasContextSource, stateDiff scanner.StateDiffSource, rawTx scanner.RawTxSource,
+	blobs scanner.BlobSource,
+	watchlists *watchlist.Watchlists, tokenEnricher *tokens.Enricher,
 ) (*scanner.TxAnalyzerService, error) {
+	var (
+		pendingTxChannel     <-chan *domain.TransactionEvent
+		mempoolStatusChannel <-chan *scanner.MempoolStatusEvent
+	)
+	if pendingStream != nil {
+		pendingTxChannel = pendingStream.ReadOnlyPendingTxStream()
+		mempoolStatusChannel = pendingStream.ReadOnlyMempoolStatusStream()
+	}
 	return scanner.NewTxAnalyzerService(ctx, scanner.TxAnalyzerServiceConfig{
-		TxChannel:     stream.ReadOnlyTxStream(),
-		AlertSender:   as,
-		MsgClient:     msgClient,
-		BotProcessing: botProcessingComponents,
+		TxChannel:            stream.ReadOnlyTxStream(),
+		PendingTxChannel:     pendingTxChannel,
+		MempoolStatusChannel: mempoolStatusChannel,
+		ReorgDetector:        reorgDetector,
+		AlertSender:          as,
+		MsgClient:            msgClient,
+		BotWarnings:          botWarnings,
+		ResponseLogger:       responseLogger,
+		Shard:                scanner.NewShard(cfg.Scan.Sharding),
+		Decoder:              decoder,
+		GasContext:           gasContext,
+		StateDiff:            stateDiff,
+		RawTx:                rawTx,
+		Blobs:                blobs,
+		Watchlists:           watchlists,
+		Tokens:               tokenEnricher,
+		ContentLimits:        scanner.NewContentLimits(cfg.Scan.ContentLimits, msgClient),
+		BotProcessing:        botProcessingComponents,
 	})
 }
 
 func initBlockAnalyzer(
 	ctx context.Context, cfg config.Config,
-	as clients.AlertSender, stream *scanner.TxStreamService,
+	as clients.AlertSender, stream scanner.EventStream, reorgDetector *scanner.ReorgDetector,
+	botWarnings *scanner.BotWarnings, responseLogger *scanner.ResponseLogger,
 	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
+	checkpoints store.CheckpointStore, checkpoint string, pendingBlocks scanner.PendingBlockSource,
+	gasContext scanner.GasContextSource,
 ) (*scanner.BlockAnalyzerService, error) {
+	if cfg.Scan.DisableCheckpoints {
+		checkpoints = nil
+	}
 	return scanner.NewBlockAnalyzerService(ctx, scanner.BlockAnalyzerServiceConfig{
-		BlockChannel:  stream.ReadOnlyBlockStream(),
-		AlertSender:   as,
-		MsgClient:     msgClient,
-		BotProcessing: botProcessingComponents,
+		BlockChannel:   stream.ReadOnlyBlockStream(),
+		ReorgDetector:  reorgDetector,
+		AlertSender:    as,
+		MsgClient:      msgClient,
+		BotWarnings:    botWarnings,
+		ResponseLogger: responseLogger,
+		Checkpoints:    checkpoints,
+		Checkpoint:     checkpoint,
+		Shard:          scanner.NewShard(cfg.Scan.Sharding),
+		PendingBlocks:  pendingBlocks,
+		GasContext:     gasContext,
+		BotProcessing:  botProcessingComponents,
 	})
 }
 
+// chainPipeline contains the feed and the analyzers which scan a chain.
+type chainPipeline struct {
+	chainID   int
+	blockFeed scanner.Feed
+	txStream  *scanner.TxStreamService
+	// nil if the blocks are not prioritized by recency
+	recencyQueue  *scanner.RecencyQueue
+	txAnalyzer    *scanner.TxAnalyzerService
+	blockAnalyzer *scanner.BlockAnalyzerService
+	// nil if the block lag alarm is disabled
+	blockLag *scanner.BlockLagMonitor
+	// nil if the log feed is disabled
+	logFeed *scanner.LogFeed
+	// nil if the beacon feed is disabled
+	beaconFeed *scanner.BeaconFeed
+	// nil if the schedule feed is disabled
+	scheduleFeed *scanner.ScheduleFeed
+	// nil if the private order flow feed is disabled
+	privateFlowFeed *scanner.PrivateFlowFeed
+	reporters       []health.Reporter
+	// the reporters which the self findings are created from
+	rpcReporters []health.Reporter
+	lagReporters []health.Reporter
+}
+
+// services returns the services of the pipeline in the start order.
+func (pipeline *chainPipeline) services() []services.Service {
+	svcs := []services.Service{pipeline.txStream}
+	if pipeline.recencyQueue != nil {
+		svcs = append(svcs, pipeline.recencyQueue)
+	}
+	svcs = append(svcs, pipeline.txAnalyzer, pipeline.blockAnalyzer)
+	if pipeline.blockLag != nil {
+		svcs = append(svcs, pipeline.blockLag)
+	}
+	if pipeline.logFeed != nil {
+		svcs = append(svcs, pipeline.logFeed)
+	}
+	if pipeline.beaconFeed != nil {
+		svcs = append(svcs, pipeline.beaconFeed)
+	}
+	if pipeline.scheduleFeed != nil {
+		svcs = append(svcs, pipeline.scheduleFeed)
+	}
+	if pipeline.privateFlowFeed != nil {
+		svcs = append(svcs, pipeline.privateFlowFeed)
+	}
+	return svcs
+}
+
+// initChainPipeline creates the feed and the analyzers of a chain. The analyzers of all chains
+// receive the bot results from the same channels, since each result carries its own request.
+func initChainPipeline(
+	ctx context.Context, cfg config.Config, checkpoint string,
+	as clients.AlertSender, pendingTxStream *scanner.PendingTxStreamService,
+	botWarnings *scanner.BotWarnings, responseLogger *scanner.ResponseLogger,
+	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
+	checkpoints store.CheckpointStore, dispatches store.DispatchStore, snapshot *store.PipelineSnapshot,
+) (*chainPipeline, error) {
+	if shard := scanner.NewShard(cfg.Scan.Sharding); shard != nil {
+		log.WithFields(log.Fields{
+			"chainId": cfg.ChainID,
+			"shard":   shard.String(),
+			"by":      cfg.Scan.Sharding.By,
+		}).Info("processing a shard of the chain events")
+	}
+
+	ethClient, err := initEthClient(ctx, "chain", cfg.Scan.JsonRpc, cfg.Scan.RpcFailover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream eth client: %v", err)
+	}
+	if cfg.Chaos.Enable {
+		ethClient = chaos.NewInjector(cfg.Chaos).EthClient(ethClient)
+	}
+
+	traceClient, err := initEthClient(ctx, "trace", cfg.Trace.JsonRpc, cfg.Scan.RpcFailover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace stream eth client: %v", err)
+	}
+	if cfg.Trace.Enabled && cfg.Trace.API == debugtrace.APIDebugTraceBlockByNumber {
+		traceClient, err = debugtrace.NewClient(ctx, traceClient, cfg.Trace.JsonRpc.Urls())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create debug trace client: %v", err)
+		}
+	}
+
+	// the bots receive only the blocks which are deep enough in the chain
+	feedClient, err := finality.NewClient(ctx, ethClient, cfg.Scan.JsonRpc.Url, cfg.Scan.Finality)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create finality client: %v", err)
+	}
+	// the lag is calculated from the last block which is deep enough
+	lagClient := feedClient
+	if cfg.Tracing.Enable {
+		feedClient = tracing.NewEthClient(feedClient, cfg.ChainID)
+	}
+	var gasContext scanner.GasContextSource
+	if cfg.Scan.GasContext.Enable {
+		gasContext, err = feehistory.NewClient(ctx, cfg.Scan.JsonRpc.Url, cfg.Scan.GasContext.RewardPercentiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fee history client: %v", err)
+		}
+	}
+	var duplicateBlocks *scanner.DuplicateBlocks
+	if !cfg.Scan.DuplicateBlocks.Disable {
+		duplicateBlocks = scanner.NewDuplicateBlocks(
+			checkpoint, time.Duration(cfg.Scan.DuplicateBlocks.WindowSeconds)*time.Second, dispatches, msgClient,
+		)
+	}
+	txStream, blockFeed, err := initTxStream(
+		ctx, feedClient, traceClient, checkpoints, checkpoint, snapshot, gasContext, duplicateBlocks,
+		botProcessingComponents.Coverage, cfg,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tx stream: %v", err)
+	}
+
+	var decoder *abidecoder.Registry
+	if cfg.Scan.AbiDecoder.Enable {
+		decoder, err = abidecoder.NewRegistry(ctx, cfg.Scan.AbiDecoder, cfg.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize abi decoder: %v", err)
+		}
+	}
+
+	var stateDiff scanner.StateDiffSource
+	if cfg.Scan.StateDiff.Enable {
+		stateDiffURL := cfg.Trace.JsonRpc.Url
+		if len(stateDiffURL) == 0 {
+			stateDiffURL = cfg.Scan.JsonRpc.Url
+		}
+		stateDiff, err = statediff.NewClient(ctx, stateDiffURL, cfg.Scan.StateDiff.API)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state diff client: %v", err)
+		}
+	}
+
+	// the blobs are found by the versioned hashes of the raw transactions
+	var rawTx scanner.RawTxSource
+	if cfg.Scan.RawTransactions.Enable || cfg.Scan.Blobs.Enable {
+		rawTx, err = rawtx.NewClient(ctx, cfg.Scan.JsonRpc.Url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create raw tx client: %v", err)
+		}
+	}
+	var blobs scanner.BlobSource
+	if cfg.Scan.Blobs.Enable {
+		blobs = beacon.NewBlobSource(cfg.Scan.Blobs.URL, cfg.Scan.Blobs.MaxBlobsPerTx)
+	}
+
+	watchlists := watchlist.NewWatchlists(ctx, cfg.Scan.Watchlists)
+
+	var tokenEnricher *tokens.Enricher
+	if cfg.Scan.TokenEnrichment.Enable {
+		tokenEnricher, err = tokens.NewEnricher(ctx, cfg.Scan.JsonRpc.Url, cfg.ChainID, cfg.Scan.TokenEnrichment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token enricher: %v", err)
+		}
+	}
+
+	var (
+		stream        scanner.EventStream = txStream
+		recencyQueue  *scanner.RecencyQueue
+		pendingBlocks scanner.PendingBlockSource
+	)
+	if cfg.Scan.RecencyPriority.Enable {
+		recencyQueue = scanner.NewRecencyQueue(ctx, scanner.RecencyQueueConfig{
+			BlockChannel: txStream.ReadOnlyBlockStream(),
+			TxChannel:    txStream.ReadOnlyTxStream(),
+			MaxBlocks:    cfg.Scan.RecencyPriority.MaxBufferedBlocks,
+			MaxTxs:       cfg.Scan.RecencyPriority.MaxBufferedTxs,
+		})
+		stream = recencyQueue
+		pendingBlocks = recencyQueue
+	}
+
+	reorgDetector := scanner.NewReorgDetector(scanner.DefaultReorgDetectionWindow)
+	txAnalyzer, err := initTxAnalyzer(
+		ctx, cfg, as, stream, pendingTxStream, reorgDetector, botWarnings, responseLogger, botProcessingComponents, msgClient,
+		decoder, gasContext, stateDiff, rawTx, blobs, watchlists, tokenEnricher,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tx analyzer: %v", err)
+	}
+	blockAnalyzer, err := initBlockAnalyzer(
+		ctx, cfg, as, stream, reorgDetector, botWarnings, responseLogger, botProcessingComponents, msgClient,
+		checkpoints, checkpoint, pendingBlocks, gasContext,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize block analyzer: %v", err)
+	}
+
+	pipeline := &chainPipeline{
+		chainID:       cfg.ChainID,
+		blockFeed:     blockFeed,
+		txStream:      txStream,
+		recencyQueue:  recencyQueue,
+		txAnalyzer:    txAnalyzer,
+		blockAnalyzer: blockAnalyzer,
+		reporters: []health.Reporter{
+			ethClient, traceClient, blockFeed, txStream, txAnalyzer, blockAnalyzer,
+		},
+		rpcReporters: []health.Reporter{ethClient, traceClient},
+	}
+	if cfg.Scan.BlockLagAlarmThreshold > 0 {
+		pipeline.blockLag, err = scanner.NewBlockLagMonitor(ctx, scanner.BlockLagMonitorConfig{
+			ChainID:     cfg.ChainID,
+			EthClient:   lagClient,
+			Blocks:      blockAnalyzer,
+			BotPool:     botProcessingComponents.BotPool,
+			BotWarnings: botWarnings,
+			Threshold:   cfg.Scan.BlockLagAlarmThreshold,
+			Interval:    time.Duration(cfg.Scan.BlockLagCheckIntervalSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize block lag monitor: %v", err)
+		}
+		pipeline.reporters = append(pipeline.reporters, pipeline.blockLag)
+		pipeline.lagReporters = append(pipeline.lagReporters, pipeline.blockLag)
+	}
+	if decoder != nil {
+		pipeline.reporters = append(pipeline.reporters, decoder)
+	}
+	if watchlists != nil {
+		pipeline.reporters = append(pipeline.reporters, watchlists)
+	}
+	if cfg.Scan.LogFeed.Enable {
+		pipeline.logFeed, err = scanner.NewLogFeed(ctx, scanner.LogFeedConfig{
+			ChainID:       cfg.ChainID,
+			EthClient:     feedClient,
+			BotPool:       botProcessingComponents.BotPool,
+			RequestSender: botProcessingComponents.RequestSender,
+			Offset:        getBlockOffset(cfg),
+			MaxBlockRange: cfg.Scan.LogFeed.MaxBlockRange,
+			Interval:      time.Duration(cfg.Scan.LogFeed.PollIntervalSeconds) * time.Second,
+			Watchlists:    watchlists,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize log feed: %v", err)
+		}
+		pipeline.reporters = append(pipeline.reporters, pipeline.logFeed)
+	}
+	if cfg.Scan.Beacon.Enable {
+		pipeline.beaconFeed, err = scanner.NewBeaconFeed(ctx, scanner.BeaconFeedConfig{
+			ChainID:       cfg.ChainID,
+			Client:        beacon.NewClient(cfg.Scan.Beacon.URL),
+			BotPool:       botProcessingComponents.BotPool,
+			RequestSender: botProcessingComponents.RequestSender,
+			MaxSlotRange:  cfg.Scan.Beacon.MaxSlotRange,
+			Interval:      time.Duration(cfg.Scan.Beacon.PollIntervalSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize beacon feed: %v", err)
+		}
+		pipeline.reporters = append(pipeline.reporters, pipeline.beaconFeed)
+	}
+	if cfg.Scan.Schedule.Enable {
+		pipeline.scheduleFeed, err = scanner.NewScheduleFeed(ctx, scanner.ScheduleFeedConfig{
+			ChainID:         cfg.ChainID,
+			Blocks:          feedClient,
+			BotPool:         botProcessingComponents.BotPool,
+			RequestSender:   botProcessingComponents.RequestSender,
+			DefaultInterval: time.Duration(cfg.Scan.Schedule.DefaultIntervalSeconds) * time.Second,
+			MinInterval:     time.Duration(cfg.Scan.Schedule.MinIntervalSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize schedule feed: %v", err)
+		}
+		pipeline.reporters = append(pipeline.reporters, pipeline.scheduleFeed)
+	}
+	if privateFlowCfg := cfg.Scan.PrivateFlow; privateFlowCfg.Enable {
+		pipeline.privateFlowFeed, err = scanner.NewPrivateFlowFeed(ctx, scanner.PrivateFlowFeedConfig{
+			ChainID:       cfg.ChainID,
+			Source:        privateFlowCfg.Name,
+			Client:        privateflow.NewClient(privateFlowCfg.URL, privateFlowCfg.AuthHeader, privateFlowCfg.AuthToken),
+			RequestSender: botProcessingComponents.RequestSender,
+			Bots:          privateFlowCfg.Bots,
+			Interval:      time.Duration(privateFlowCfg.PollIntervalSeconds) * time.Second,
+			ContentLimits: scanner.NewContentLimits(cfg.Scan.ContentLimits, msgClient),
+			Watchlists:    watchlists,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize private flow feed: %v", err)
+		}
+		pipeline.reporters = append(pipeline.reporters, pipeline.privateFlowFeed)
+	}
+	return pipeline, nil
+}
+
 func initCombinerAlertAnalyzer(
 	ctx context.Context, cfg config.Config,
 	as clients.AlertSender, stream *scanner.CombinerAlertStreamService,
+	botWarnings *scanner.BotWarnings, responseLogger *scanner.ResponseLogger,
 	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
 ) (*scanner.CombinerAlertAnalyzerService, error) {
 	return scanner.NewCombinerAlertAnalyzerService(
 		ctx, scanner.CombinerAlertAnalyzerServiceConfig{
-			AlertChannel:  stream.ReadOnlyAlertStream(),
-			AlertSender:   as,
-			MsgClient:     msgClient,
-			ChainID:       fmt.Sprintf("%d", cfg.ChainID),
-			BotProcessing: botProcessingComponents,
+			AlertChannel:   stream.ReadOnlyAlertStream(),
+			AlertSender:    as,
+			MsgClient:      msgClient,
+			BotWarnings:    botWarnings,
+			ResponseLogger: responseLogger,
+			ChainID:        fmt.Sprintf("%d", cfg.ChainID),
+			Shard:          scanner.NewShard(cfg.Scan.Sharding),
+			BotProcessing:  botProcessingComponents,
 		},
 	)
 }
 
-func initAlertSender(ctx context.Context, key *keystore.Key, pubClient clients.PublishClient, cfg config.Config) (clients.AlertSender, error) {
+// initExporter creates the metrics exporter. The counts of the additional chains are exported
+// with the chain id suffix.
+func initExporter(
+	ctx context.Context, cfg config.Config, msgClient clients.MessageClient, pipelines []*chainPipeline,
+) (*exporter.Exporter, error) {
+	processed := make(map[string]func() uint64)
+	queueDepths := make(map[string]func() int)
+	blockLags := make(map[string]func() uint64)
+	for _, pipeline := range pipelines {
+		var suffix string
+		if pipeline.chainID != cfg.ChainID {
+			suffix = fmt.Sprintf("-%d", pipeline.chainID)
+		}
+		stream := pipeline.txStream
+		processed["tx"+suffix] = pipeline.txAnalyzer.ProcessedCount
+		processed["block"+suffix] = pipeline.blockAnalyzer.ProcessedCount
+		queueDepths["tx"+suffix] = func() int {
+			return len(stream.ReadOnlyTxStream())
+		}
+		queueDepths["block"+suffix] = func() int {
+			return len(stream.ReadOnlyBlockStream())
+		}
+		if pipeline.recencyQueue != nil {
+			queueDepths["tx"+suffix] = pipeline.recencyQueue.TxQueueLen
+			queueDepths["block"+suffix] = pipeline.recencyQueue.BlockQueueLen
+		}
+		if pipeline.blockLag != nil {
+			blockLags[strconv.Itoa(pipeline.chainID)] = pipeline.blockLag.Lag
+		}
+	}
+	return exporter.NewExporter(ctx, exporter.ExporterConfig{
+		Port:        cfg.PrometheusConfig.Port,
+		MsgClient:   msgClient,
+		Processed:   processed,
+		QueueDepths: queueDepths,
+		BlockLags:   blockLags,
+	})
+}
+
+// initStatusAPI creates the status API which serves the health and the status of the node.
+func initStatusAPI(
+	ctx context.Context, cfg config.Config, msgClient clients.MessageClient, pipelines []*chainPipeline,
+	publisherSvc *publisher.Publisher, botPool botio.BotPool, checker health.HealthChecker,
+) (*statusapi.StatusAPI, error) {
+	chains := make(map[int]statusapi.BlockSource)
+	for _, pipeline := range pipelines {
+		chains[pipeline.chainID] = pipeline.blockAnalyzer
+	}
+	var agentLogs store.AgentLogStore
+	if captureCfg := cfg.AgentLogsConfig.Capture; !captureCfg.Disable {
+		// the supervisor captures the logs to the shared forta dir
+		agentLogs = store.NewAgentLogStore(
+			path.Join(cfg.FortaDir, config.DefaultAgentLogsDirName),
+			int64(captureCfg.MaxFileSizeMB)*1024*1024, captureCfg.MaxFiles,
+		)
+	}
+	return statusapi.NewStatusAPI(ctx, statusapi.StatusAPIConfig{
+		Port:      cfg.StatusAPI.Port,
+		MsgClient: msgClient,
+		Health:    checker,
+		BotPool:   botPool,
+		Chains:    chains,
+		Publish:   publisherSvc,
+		AgentLogs: agentLogs,
+		Token:     cfg.StatusAPI.Token,
+	})
+}
+
+// initAdminAPI creates the admin API which listens on the socket in the forta dir and, if the port
+// is set, on the port with mutual TLS.
+func initAdminAPI(
+	ctx context.Context, cfg config.Config, msgClient clients.MessageClient, sender botio.Sender,
+	botDrainer *scanner.BotDrainService, reloader *reload.Reloader,
+) (*admin.API, error) {
+	fortaDirPath := func(filePath string) string {
+		if len(filePath) == 0 || path.IsAbs(filePath) {
+			return filePath
+		}
+		return path.Join(cfg.FortaDir, filePath)
+	}
+	apiCfg := admin.APIConfig{
+		SocketPath:    fortaDirPath(cfg.AdminAPI.SocketName),
+		Port:          cfg.AdminAPI.Port,
+		RequestSender: sender,
+		Drainer:       botDrainer,
+		MsgClient:     msgClient,
+		Reloader:      reloader,
+	}
+	if len(apiCfg.Port) > 0 {
+		tlsConfig, err := admin.ServerTLSConfig(
+			fortaDirPath(cfg.AdminAPI.CertFile), fortaDirPath(cfg.AdminAPI.KeyFile),
+			fortaDirPath(cfg.AdminAPI.ClientCAFile),
+		)
+		if err != nil {
+			return nil, err
+		}
+		apiCfg.TLSConfig = tlsConfig
+	}
+	return admin.NewAPI(ctx, apiCfg)
+}
+
+func initAlertSender(
+	ctx context.Context, key *keystore.Key, pubClient clients.PublishClient, cfg config.Config,
+) (clients.AlertSender, error) {
 	ds, err := store.NewDeduplicationStore(cfg)
 	if err != nil {
 		return nil, err
@@ -260,10 +823,88 @@ func initAlertSender(ctx context.Context, key *keystore.Key, pubClient clients.P
 	})
 }
 
+// withAlertFilters wraps the alert sender with the alert filter and the finding rules.
+func withAlertFilters(
+	alertSender clients.AlertSender, msgClient clients.MessageClient, alertHistory store.AlertHistoryStore,
+	filterCfg config.AlertFilterConfig,
+) clients.AlertSender {
+	if filterCfg.DedupeWindowSeconds > 0 || filterCfg.MaxAlertsPerMinute > 0 {
+		alertSender = scanner.NewFilteringAlertSender(alertSender, msgClient, scanner.NewAlertFilter(
+			time.Duration(filterCfg.DedupeWindowSeconds)*time.Second, filterCfg.MaxAlertsPerMinute, alertHistory,
+		))
+	}
+	// the rules are applied first so that the suppressed findings are not counted by the filter
+	if len(filterCfg.Rules) > 0 {
+		alertSender = scanner.NewRuleAlertSender(alertSender, msgClient, filterCfg.Rules)
+	}
+	return alertSender
+}
+
+// initReloader creates the reloader which applies the reloaded settings of the scanner and lets the
+// other containers reload theirs.
+func initReloader(
+	msgClient clients.MessageClient, publisherSvc *publisher.Publisher, baseSender clients.AlertSender,
+	filteredSender *scanner.SwappableAlertSender, alertHistory store.AlertHistoryStore,
+) (*reload.Reloader, error) {
+	reloader, err := reload.New(config.GetConfigForContainer, msgClient)
+	if err != nil {
+		return nil, err
+	}
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		payload := messaging.LogLevelPayload{Level: cfg.Log.Level}
+		if _, err := log.ParseLevel(payload.Level); err != nil {
+			return nil, err
+		}
+		return func() {
+			_ = messaging.SetLogLevel(payload)
+			msgClient.Publish(messaging.SubjectAdminLogLevel, payload)
+		}, nil
+	}, reload.SettingLogLevel)
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		alertSender := withAlertFilters(baseSender, msgClient, alertHistory, cfg.AlertFilter)
+		return func() {
+			filteredSender.Swap(alertSender)
+		}, nil
+	}, reload.SettingAlertFilter)
+	reloader.Handle(publisherSvc.PrepareSinks, reload.SettingSinks, reload.SettingRoutes)
+	reloader.Delegate(reload.LocalBotSettings...)
+	reloader.Delegate(reload.RateLimitSettings...)
+	return reloader, nil
+}
+
+// initEthClient creates a failover client if there are fallback urls.
+func initEthClient(
+	ctx context.Context, apiName string, jsonRpcCfg config.JsonRpcConfig, failoverCfg config.RpcFailoverConfig,
+) (ethereum.Client, error) {
+	if len(jsonRpcCfg.FallbackUrls) == 0 {
+		return ethereum.NewStreamEthClient(ctx, apiName, jsonRpcCfg.Url)
+	}
+	return failover.NewClient(ctx, apiName, jsonRpcCfg.Urls(), failover.Config{
+		CallTimeout:         time.Duration(failoverCfg.CallTimeoutSeconds) * time.Second,
+		HealthCheckInterval: time.Duration(failoverCfg.HealthCheckIntervalSeconds) * time.Second,
+		LoadBalance:         failoverCfg.LoadBalance,
+	})
+}
+
+// convertToDockerHostURLs converts the main and the fallback urls.
+func convertToDockerHostURLs(jsonRpcCfg *config.JsonRpcConfig) {
+	jsonRpcCfg.Url = utils.ConvertToDockerHostURL(jsonRpcCfg.Url)
+	for i, u := range jsonRpcCfg.FallbackUrls {
+		jsonRpcCfg.FallbackUrls[i] = utils.ConvertToDockerHostURL(u)
+	}
+}
+
 func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
 	// can't dial localhost - need to dial host gateway from container
-	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
-	cfg.Trace.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Trace.JsonRpc.Url)
+	convertToDockerHostURLs(&cfg.Scan.JsonRpc)
+	convertToDockerHostURLs(&cfg.Trace.JsonRpc)
+	for i := range cfg.Chains {
+		convertToDockerHostURLs(&cfg.Chains[i].JsonRpc)
+		convertToDockerHostURLs(&cfg.Chains[i].Trace.JsonRpc)
+	}
+	cfg.Scan.Beacon.URL = utils.ConvertToDockerHostURL(cfg.Scan.Beacon.URL)
+	cfg.Scan.Blobs.URL = utils.ConvertToDockerHostURL(cfg.Scan.Blobs.URL)
+	cfg.Scan.PrivateFlow.URL = utils.ConvertToDockerHostURL(cfg.Scan.PrivateFlow.URL)
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
 	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
@@ -280,29 +921,35 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		return nil, err
 	}
 
-	publisherSvc, err := publisher.NewPublisher(ctx, cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create publisher: %v", err)
+	var tracingProvider *tracing.Provider
+	if cfg.Tracing.Enable {
+		tracingProvider, err = tracing.NewProvider(ctx, cfg.Tracing, "forta-scanner")
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize tracing: %v", err)
+		}
 	}
 
-	alertSender, err := initAlertSender(ctx, key, publisherSvc, cfg)
+	publisherSvc, err := publisher.NewPublisher(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize alert sender: %v", err)
+		return nil, fmt.Errorf("failed to create publisher: %v", err)
 	}
 
-	ethClient, err := ethereum.NewStreamEthClient(ctx, "chain", cfg.Scan.JsonRpc.Url)
+	localStore, err := store.NewLocalStore(path.Join(cfg.FortaDir, ".scanner-db"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stream eth client: %v", err)
+		return nil, err
 	}
 
-	traceClient, err := ethereum.NewStreamEthClient(ctx, "trace", cfg.Trace.JsonRpc.Url)
+	baseSender, err := initAlertSender(ctx, key, publisherSvc, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create trace stream eth client: %v", err)
+		return nil, fmt.Errorf("failed to initialize alert sender: %v", err)
 	}
-
-	txStream, blockFeed, err := initTxStream(ctx, ethClient, traceClient, cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create tx stream: %v", err)
+	// the filters are replaced when the config is reloaded
+	filteredSender := scanner.NewSwappableAlertSender(withAlertFilters(baseSender, msgClient, localStore, cfg.AlertFilter))
+	alertSender := clients.AlertSender(filteredSender)
+	var localAlertFeed *scanner.LocalAlertFeed
+	if cfg.CombinerConfig.LocalAlerts {
+		localAlertFeed = scanner.NewLocalAlertFeed(ctx)
+		alertSender = scanner.NewLocalFeedAlertSender(alertSender, localAlertFeed)
 	}
 
 	var waitBots int
@@ -324,44 +971,217 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot processing components: %v", err)
 	}
-	txAnalyzer, err := initTxAnalyzer(ctx, cfg, alertSender, txStream, botProcessingComponents, msgClient)
+	var pendingTxStream *scanner.PendingTxStreamService
+	if cfg.Scan.PendingTxs.Enable {
+		pendingTxStream, err = initPendingTxStream(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize pending tx stream: %v", err)
+		}
+	}
+	// the warnings about the bots are published as the findings of the node
+	botWarnings := scanner.NewBotWarnings(scanner.BotWarningsConfig{
+		ChainID:     cfg.ChainID,
+		AlertSender: alertSender,
+		MsgClient:   msgClient,
+		Cooldown:    time.Duration(cfg.Scan.SelfFindings.CooldownSeconds) * time.Second,
+	})
+	responseLogger := scanner.NewResponseLogger(ctx, cfg.Scan.BotResponseLogSampleRate)
+	// the snapshot is used once - the next shutdown saves a new one
+	snapshot, _, err := localStore.GetSnapshot()
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize tx analyzer: %v", err)
+		log.WithError(err).Warn("failed to get the pipeline snapshot - resuming from the checkpoints")
+	}
+	if err := localStore.DeleteSnapshot(); err != nil {
+		return nil, fmt.Errorf("failed to delete the pipeline snapshot: %v", err)
 	}
-	blockAnalyzer, err := initBlockAnalyzer(ctx, cfg, alertSender, txStream, botProcessingComponents, msgClient)
+	checkpoints := map[uint64]string{uint64(cfg.ChainID): scanner.BlockCheckpoint}
+	mainPipeline, err := initChainPipeline(
+		ctx, cfg, scanner.BlockCheckpoint, alertSender, pendingTxStream, botWarnings, responseLogger,
+		botProcessingComponents, msgClient, localStore, localStore, snapshot,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize block analyzer: %v", err)
+		return nil, err
+	}
+	pipelines := []*chainPipeline{mainPipeline}
+	for _, chain := range cfg.Chains {
+		checkpoints[uint64(chain.ChainID)] = scanner.ChainBlockCheckpoint(chain.ChainID)
+		pipeline, err := initChainPipeline(
+			ctx, cfg.ForChain(chain), scanner.ChainBlockCheckpoint(chain.ChainID),
+			alertSender, nil, botWarnings, responseLogger, botProcessingComponents, msgClient, localStore, localStore, snapshot,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the pipeline of chain %d: %v", chain.ChainID, err)
+		}
+		for _, reporters := range [][]health.Reporter{pipeline.reporters, pipeline.rpcReporters, pipeline.lagReporters} {
+			for i, reporter := range reporters {
+				reporters[i] = scanner.NewChainReporter(chain.ChainID, reporter)
+			}
+		}
+		pipelines = append(pipelines, pipeline)
 	}
 
-	// Start the main block feed so all transaction feeds can start consuming.
+	// Start the block feeds so all transaction feeds can start consuming.
 	if !cfg.Scan.DisableAutostart {
-		blockFeed.Start()
+		for _, pipeline := range pipelines {
+			pipeline.blockFeed.Start()
+		}
 	}
 
-	combinationStream, combinationFeed, err := initCombinationStream(ctx, msgClient, cfg)
+	combinationStream, combinationFeed, err := initCombinationStream(ctx, msgClient, cfg, localAlertFeed)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize combiner stream: %v", err)
 	}
 
-	combinationAnalyzer, err := initCombinerAlertAnalyzer(ctx, cfg, alertSender, combinationStream, botProcessingComponents, msgClient)
+	combinationAnalyzer, err := initCombinerAlertAnalyzer(
+		ctx, cfg, alertSender, combinationStream, botWarnings, responseLogger, botProcessingComponents, msgClient,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize combiner analyzer: %v", err)
 	}
 
+	var metricsExporter *exporter.Exporter
+	if cfg.PrometheusConfig.Enable {
+		metricsExporter, err = initExporter(ctx, cfg, msgClient, pipelines)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize metrics exporter: %v", err)
+		}
+	}
+
+	var ingestAPI *ingest.API
+	if cfg.IngestAPI.Enable {
+		ingestAPI, err = ingest.NewAPI(ctx, ingest.APIConfig{
+			Port:          cfg.IngestAPI.Port,
+			GrpcPort:      cfg.IngestAPI.GrpcPort,
+			Token:         cfg.IngestAPI.Token,
+			RequestSender: botProcessingComponents.RequestSender,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize ingest api: %v", err)
+		}
+	}
+
+	botDrainer := scanner.NewBotDrainService(
+		botProcessingComponents, time.Duration(cfg.Scan.ShutdownTimeoutSeconds)*time.Second,
+		localStore, checkpoints,
+	)
+	reloader, err := initReloader(msgClient, publisherSvc, baseSender, filteredSender, localStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config reloader: %v", err)
+	}
+	services.OnReload(func() {
+		_, _ = reloader.Reload()
+	})
+	var adminAPI *admin.API
+	if cfg.AdminAPI.Enable {
+		adminAPI, err = initAdminAPI(ctx, cfg, msgClient, botProcessingComponents.RequestSender, botDrainer, reloader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize admin api: %v", err)
+		}
+	}
+
+	var reporters []health.Reporter
+	for _, pipeline := range pipelines {
+		reporters = append(reporters, pipeline.reporters...)
+	}
+	reporters = append(reporters,
+		combinationFeed, combinationAnalyzer,
+		botProcessingComponents.RequestSender,
+		publisherSvc,
+		scanner.NewIdentityReporter(key.Address),
+	)
+	if pendingTxStream != nil {
+		reporters = append(reporters, pendingTxStream)
+	}
+	if metricsExporter != nil {
+		reporters = append(reporters, metricsExporter)
+	}
+	if ingestAPI != nil {
+		reporters = append(reporters, ingestAPI)
+	}
+	if adminAPI != nil {
+		reporters = append(reporters, adminAPI)
+	}
+
+	var selfFindings *scanner.SelfFindings
+	if cfg.Scan.SelfFindings.Enable {
+		selfFindingsCfg := scanner.SelfFindingsConfig{
+			ChainID:          cfg.ChainID,
+			AlertSender:      alertSender,
+			MsgClient:        msgClient,
+			PublishReporters: []health.Reporter{publisherSvc},
+			Interval:         time.Duration(cfg.Scan.SelfFindings.CheckIntervalSeconds) * time.Second,
+			Cooldown:         time.Duration(cfg.Scan.SelfFindings.CooldownSeconds) * time.Second,
+		}
+		for _, pipeline := range pipelines {
+			selfFindingsCfg.RPCReporters = append(selfFindingsCfg.RPCReporters, pipeline.rpcReporters...)
+			selfFindingsCfg.LagReporters = append(selfFindingsCfg.LagReporters, pipeline.lagReporters...)
+		}
+		selfFindings, err = scanner.NewSelfFindings(ctx, selfFindingsCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize self findings: %v", err)
+		}
+		reporters = append(reporters, selfFindings)
+	}
+
+	checker := health.CheckerFrom(summarizeReports, reporters...)
 	svcs := []services.Service{
-		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, health.CheckerFrom(
-			summarizeReports,
-			ethClient, traceClient, combinationFeed, blockFeed, txStream,
-			txAnalyzer, blockAnalyzer, combinationAnalyzer,
-			botProcessingComponents.RequestSender,
-			publisherSvc,
-		)),
-		txStream,
-		txAnalyzer,
-		blockAnalyzer,
+		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, checker),
+	}
+	for _, pipeline := range pipelines {
+		svcs = append(svcs, pipeline.services()...)
+	}
+	svcs = append(svcs,
 		combinationStream,
 		combinationAnalyzer,
+		botDrainer,
 		publisherSvc,
+	)
+	if pendingTxStream != nil {
+		svcs = append(svcs, pendingTxStream)
+	}
+	if botProcessingComponents.DeadLetterReplayer != nil {
+		svcs = append(svcs, botProcessingComponents.DeadLetterReplayer)
+	}
+	if botProcessingComponents.Coverage != nil {
+		svcs = append(svcs, botProcessingComponents.Coverage)
+	}
+	if metricsExporter != nil {
+		svcs = append(svcs, metricsExporter)
+	}
+	if ingestAPI != nil {
+		svcs = append(svcs, ingestAPI)
+	}
+	if adminAPI != nil {
+		svcs = append(svcs, adminAPI)
+	}
+	if selfFindings != nil {
+		svcs = append(svcs, selfFindings)
+	}
+	if cfg.StatusAPI.Enable {
+		statusAPI, err := initStatusAPI(
+			ctx, cfg, msgClient, pipelines, publisherSvc, botProcessingComponents.BotPool, checker,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize status api: %v", err)
+		}
+		svcs = append(svcs, statusAPI)
+	}
+	if cfg.AlertQueryAPI.Enable {
+		alertQueryAPI, err := alertquery.NewAlertQueryAPI(ctx, alertquery.AlertQueryAPIConfig{
+			Port:       cfg.AlertQueryAPI.Port,
+			Token:      cfg.AlertQueryAPI.Token,
+			Store:      publisherSvc.AlertStore(),
+			MaxResults: cfg.AlertQueryAPI.MaxResults,
+			Proofs:     publisherSvc.BatchProofStore(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize alert query api: %v", err)
+		}
+		svcs = append(svcs, alertQueryAPI)
+	}
+	// stopped last to export the spans of the other services
+	if tracingProvider != nil {
+		svcs = append(svcs, tracingProvider)
 	}
 
 	return svcs, nil
@@ -434,6 +1254,12 @@ func summarizeReports(reports health.Reports) *health.Report {
 	}
 	summary.Punc(".")
 
+	blockLag, ok := reports.NameContains("block-lag-monitor.blocks")
+	lagging := ok && blockLag.Status == health.StatusLagging
+	if lagging {
+		summary.Addf("lagging behind the chain head by %s blocks.", blockLag.Details)
+	}
+
 	getTxReceiptErr, ok := reports.NameContains("chain-json-rpc-client.request.get-transaction-receipt.error")
 	if ok && len(getTxReceiptErr.Details) > 0 {
 		summary.Addf("failing to get transaction receipt with error '%s', this can slow down block processing.", getTxReceiptErr.Details)
@@ -452,7 +1278,12 @@ func summarizeReports(reports health.Reports) *health.Report {
 		summary.Status(health.StatusFailing)
 	}
 
-	return summary.Finish()
+	report := summary.Finish()
+	// the failures are more important than the lag
+	if lagging && report.Status == health.StatusOK {
+		report.Status = health.StatusLagging
+	}
+	return report
 }
 
 func isNotFoundErr(errMsg string) bool {
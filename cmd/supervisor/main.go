@@ -13,6 +13,7 @@ import (
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/registry"
+	"github.com/forta-network/forta-node/services/components/reload"
 	"github.com/forta-network/forta-node/services/supervisor"
 )
 
@@ -39,6 +40,17 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		ScannerAddress: key.Address,
 		BotRegistry:    botRegistry,
 	}
+	reloader, err := reload.New(config.GetConfigForContainer, nil)
+	if err != nil {
+		return nil, err
+	}
+	reloader.Handle(botRegistry.PrepareLocalBots, reload.LocalBotSettings...)
+	reloader.Delegate(reload.ScannerSettings...)
+	reloader.Delegate(reload.RateLimitSettings...)
+	services.OnReload(func() {
+		_, _ = reloader.Reload()
+	})
+
 	svc, err := supervisor.NewSupervisorService(ctx, supervisor.SupervisorServiceConfig{
 		Config:             cfg,
 		Passphrase:         passphrase,
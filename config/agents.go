@@ -25,6 +25,57 @@ type AgentConfig struct {
 
 	ChainID     int
 	ShardConfig *ShardConfig
+	// the events which the bot subscribes to - nil subscribes to all events
+	Filters *BotFilters `yaml:"filters" json:"filters,omitempty"`
+	// the host:port of the bot if it runs outside of the node, like on the host of a developer
+	Address string `yaml:"address" json:"address,omitempty"`
+	// the protocol of the bot - the bots are gRPC services by default
+	Protocol string `yaml:"protocol" json:"protocol,omitempty" validate:"omitempty,oneof=grpc http"`
+	// the url which the requests of the http bots are posted under
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+}
+
+// Bot protocols
+const (
+	BotProtocolGRPC = "grpc"
+	BotProtocolHTTP = "http"
+)
+
+// Bot event types
+const (
+	BotEventBlock = "block"
+	BotEventTx    = "tx"
+	// the logs from eth_getLogs, without the rest of the transaction
+	BotEventLog = "log"
+	// the consensus-layer events from the beacon feed
+	BotEventBeacon = "beacon"
+	// the latest block at the schedule interval, from the schedule feed
+	BotEventSchedule = "schedule"
+)
+
+// BotFilters declares the events which a bot subscribes to, so that the node does not send every
+// event on the chain to a bot which cares about a few contracts. An empty field matches all events,
+// so a bot which declares only the addresses still receives all block events. A transaction should
+// match all non-empty transaction fields: it should involve one of the addresses and emit a log
+// with one of the topics. The bots which subscribe to the log events but not to the transactions
+// receive only the matching logs from the log feed, where the topics are matched as the event
+// signatures, which is the first topic.
+type BotFilters struct {
+	ChainIDs   []uint64 `yaml:"chainIds" json:"chainIds,omitempty"`
+	EventTypes []string `yaml:"eventTypes" json:"eventTypes,omitempty" validate:"dive,oneof=block tx log beacon schedule"`
+	// the transactions which are from, to or involve these addresses
+	Addresses []string `yaml:"addresses" json:"addresses,omitempty" validate:"dive,eth_addr"`
+	// the transactions which emit a log with one of these topics at any position
+	Topics []string `yaml:"topics" json:"topics,omitempty"`
+	// the transactions which call one of these functions, like "transfer(address,uint256)"
+	Functions []string `yaml:"functions" json:"functions,omitempty"`
+	// the transactions which emit one of these events, like "Transfer(address,address,uint256)"
+	Events []string `yaml:"events" json:"events,omitempty"`
+	// the transactions which involve an address with one of these watchlist labels, like "exploiter"
+	Labels []string `yaml:"labels" json:"labels,omitempty"`
+	// the interval of the scheduled evaluations if the bot subscribes to the schedule events - the
+	// default interval of the schedule feed if zero
+	ScheduleIntervalSeconds int `yaml:"scheduleIntervalSeconds" json:"scheduleIntervalSeconds,omitempty" validate:"min=0"`
 }
 
 type ShardConfig struct {
@@ -78,6 +129,16 @@ func (ac AgentConfig) Equal(b AgentConfig) bool {
 	return sameShardID && sameShardCount
 }
 
+// IsHTTP tells if the bot is an HTTP service which receives the requests at its endpoint.
+func (ac *AgentConfig) IsHTTP() bool {
+	return ac.Protocol == BotProtocolHTTP
+}
+
+// IsAttached tells if the bot runs outside of the node and is dialed at its address.
+func (ac *AgentConfig) IsAttached() bool {
+	return len(ac.Address) > 0
+}
+
 // IsSharded tells if this is a sharded bot.
 func (ac *AgentConfig) IsSharded() bool {
 	return ac.ShardConfig != nil && ac.ShardConfig.Shards > 1
@@ -115,6 +176,27 @@ func (ac AgentConfig) ContainerName() string {
 	return strings.Join(parts, "-")
 }
 
+// agentGrpcPort is the port which the bots listen to - the default port unless configured
+var agentGrpcPort = AgentGrpcPort
+
+// SetAgentGrpcPort sets the port which the bots are started with and dialed at. The default port
+// is used if the port is empty.
+func SetAgentGrpcPort(port string) {
+	if len(port) == 0 {
+		port = AgentGrpcPort
+	}
+	agentGrpcPort = port
+}
+
+// GrpcPort returns the port which the bot listens to.
 func (ac AgentConfig) GrpcPort() string {
-	return AgentGrpcPort
+	return agentGrpcPort
+}
+
+// GrpcAddress returns the address which the bot is dialed at.
+func (ac AgentConfig) GrpcAddress() string {
+	if ac.IsAttached() {
+		return ac.Address
+	}
+	return fmt.Sprintf("%s:%s", ac.ContainerName(), ac.GrpcPort())
 }
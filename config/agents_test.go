@@ -154,3 +154,13 @@ func TestAgentConfig_Equal(t *testing.T) {
 		)
 	}
 }
+
+func TestAgentConfig_GrpcAddress(t *testing.T) {
+	attached := AgentConfig{ID: "0x1", Address: "host.docker.internal:50052"}
+	assert.True(t, attached.IsAttached())
+	assert.Equal(t, "host.docker.internal:50052", attached.GrpcAddress())
+
+	local := AgentConfig{ID: "0x1", IsLocal: true}
+	assert.False(t, local.IsAttached())
+	assert.Equal(t, local.ContainerName()+":"+AgentGrpcPort, local.GrpcAddress())
+}
@@ -0,0 +1,80 @@
+package config
+
+import "time"
+
+// ChainProfile describes how the feeds should treat the chains which behave differently from the
+// Ethereum mainnet: the block times, the depths which the reorgs can reach and the block sizes.
+type ChainProfile struct {
+	ChainID int
+	// the average block time
+	BlockTime time.Duration
+	// the confirmations after which the blocks are not expected to be reorged
+	Confirmations uint64
+	// the transaction requests which each bot can buffer - the chains with large blocks need more
+	TxBufferSize int
+}
+
+// defaultChainProfile is used for the chains which do not have a profile.
+var defaultChainProfile = ChainProfile{
+	BlockTime:     12 * time.Second,
+	Confirmations: 12,
+	TxBufferSize:  2000,
+}
+
+var chainProfiles = map[int]ChainProfile{
+	1: defaultChainProfile,
+	// the deposit transactions from L1 have no signature and no gas price
+	10: {
+		BlockTime:     2 * time.Second,
+		Confirmations: 10,
+		TxBufferSize:  2000,
+	},
+	56: {
+		BlockTime:     3 * time.Second,
+		Confirmations: 15,
+		TxBufferSize:  10000,
+	},
+	137: {
+		BlockTime: 2 * time.Second,
+		// the chain had reorgs deeper than 100 blocks
+		Confirmations: 128,
+		TxBufferSize:  10000,
+	},
+	250: {
+		BlockTime:     time.Second,
+		Confirmations: 5,
+		TxBufferSize:  2000,
+	},
+	// the blocks are numbered by the chain itself and not by the L1 blocks, and the retryable and
+	// the internal transactions of the chain have no signature
+	42161: {
+		BlockTime:     250 * time.Millisecond,
+		Confirmations: 20,
+		TxBufferSize:  4000,
+	},
+	43114: {
+		BlockTime:     2 * time.Second,
+		Confirmations: 1,
+		TxBufferSize:  2000,
+	},
+}
+
+// GetChainProfile returns the profile of the chain, or the Ethereum mainnet profile if the chain
+// does not have one.
+func GetChainProfile(chainID int) ChainProfile {
+	profile, ok := chainProfiles[chainID]
+	if !ok {
+		profile = defaultChainProfile
+	}
+	profile.ChainID = chainID
+	return profile
+}
+
+// BlockRateLimit returns the min interval between the blocks which the feed gets. It is bounded by
+// the half of the block time, so that the feed can catch up with the chains which have fast blocks.
+func (profile ChainProfile) BlockRateLimit(configured time.Duration) time.Duration {
+	if max := profile.BlockTime / 2; configured > max {
+		return max
+	}
+	return configured
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/creasty/defaults"
 	"github.com/forta-network/forta-core-go/protocol/settings"
@@ -18,6 +21,66 @@ type PublicAPIProxyConfig struct {
 type JsonRpcConfig struct {
 	Url     string            `yaml:"url" json:"url" validate:"omitempty,url"`
 	Headers map[string]string `yaml:"headers" json:"headers"`
+	// used by the scanner and the tracer for failing over when the main url fails
+	FallbackUrls []string `yaml:"fallbackUrls" json:"fallbackUrls" validate:"dive,url"`
+}
+
+// Urls returns the main url followed by the fallback urls.
+func (cfg JsonRpcConfig) Urls() []string {
+	return append([]string{cfg.Url}, cfg.FallbackUrls...)
+}
+
+// RpcFailoverConfig contains the failover options for the json-rpc configs with fallback urls.
+type RpcFailoverConfig struct {
+	CallTimeoutSeconds         int  `yaml:"callTimeoutSeconds" json:"callTimeoutSeconds" default:"15" validate:"min=1"`
+	HealthCheckIntervalSeconds int  `yaml:"healthCheckIntervalSeconds" json:"healthCheckIntervalSeconds" default:"30" validate:"min=1"`
+	LoadBalance                bool `yaml:"loadBalance" json:"loadBalance"`
+}
+
+// DuplicateBlocksConfig configures the suppression of the blocks which are dispatched again. The hashes of
+// the dispatched blocks are kept in the local store for the window, so that the bots do not receive the same
+// block and its transactions twice, even after a restart.
+type DuplicateBlocksConfig struct {
+	Disable       bool `yaml:"disable" json:"disable"`
+	WindowSeconds int  `yaml:"windowSeconds" json:"windowSeconds" default:"3600" validate:"min=1"`
+}
+
+// BotPerformanceConfig configures the performance scores of the bots. The score is calculated from the timeouts,
+// the errors and the average latency in the window of the last requests of the bot. The bots with lower scores
+// than the min score are disabled until they pass the probation checks, which are the health checks of the bot.
+type BotPerformanceConfig struct {
+	Enable          bool    `yaml:"enable" json:"enable"`
+	WindowSize      int     `yaml:"windowSize" json:"windowSize" default:"100" validate:"min=10"`
+	MinScore        float64 `yaml:"minScore" json:"minScore" default:"0.5" validate:"min=0,max=1"`
+	LatencyTargetMs int     `yaml:"latencyTargetMs" json:"latencyTargetMs" default:"5000" validate:"min=1"`
+	ProbationChecks int     `yaml:"probationChecks" json:"probationChecks" default:"5" validate:"min=1"`
+}
+
+// Content limit policies
+const (
+	ContentLimitTruncate = "truncate"
+	ContentLimitDrop     = "drop"
+)
+
+// ContentLimitsConfig caps the content of the transaction events so that the very large blocks do
+// not blow up the memory. The calldata is cut to the max size, the traces deeper than the max depth
+// and the logs after the max amount are removed, and the event is marked with what was elided. The
+// "drop" policy skips the events which exceed the limits instead. The zero values do not limit.
+type ContentLimitsConfig struct {
+	MaxCalldataBytes int    `yaml:"maxCalldataBytes" json:"maxCalldataBytes" validate:"min=0"`
+	MaxTraceDepth    int    `yaml:"maxTraceDepth" json:"maxTraceDepth" validate:"min=0"`
+	MaxLogs          int    `yaml:"maxLogs" json:"maxLogs" validate:"min=0"`
+	Policy           string `yaml:"policy" json:"policy" default:"truncate" validate:"omitempty,oneof=truncate drop"`
+}
+
+// CoverageConfig configures the coverage reports which tell which blocks the node saw, processed and
+// dispatched on each chain and which of these blocks each bot acknowledged. The reports are saved in the
+// coverage dir of the Forta dir and posted to the publish URL as JSON if it is set.
+type CoverageConfig struct {
+	Enable          bool   `yaml:"enable" json:"enable"`
+	IntervalSeconds int    `yaml:"intervalSeconds" json:"intervalSeconds" default:"3600" validate:"min=60"`
+	MaxReports      int    `yaml:"maxReports" json:"maxReports" default:"168" validate:"min=1"`
+	PublishURL      string `yaml:"publishUrl" json:"publishUrl" validate:"omitempty,url"`
 }
 
 type ScannerConfig struct {
@@ -27,11 +90,412 @@ type ScannerConfig struct {
 	BlockMaxAgeSeconds   int64         `yaml:"blockMaxAgeSeconds" json:"blockMaxAgeSeconds" default:"600"`
 	RetryIntervalSeconds int64         `yaml:"retryIntervalSeconds" json:"retryIntervalSeconds" default:"8"`
 	AlertAPIURL          string        `yaml:"apiUrl" json:"apiUrl" default:"https://api.forta.network/graphql" validate:"url"`
+
+	BotRequestTimeoutSeconds int            `yaml:"botRequestTimeoutSeconds" json:"botRequestTimeoutSeconds" default:"30" validate:"min=1"`
+	BotRequestTimeouts       map[string]int `yaml:"botRequestTimeouts" json:"botRequestTimeouts" validate:"dive,min=1"`
+	BotRequestMaxAttempts    int            `yaml:"botRequestMaxAttempts" json:"botRequestMaxAttempts" default:"3" validate:"min=1"`
+	BotRequestRetryBackoffMs int            `yaml:"botRequestRetryBackoffMs" json:"botRequestRetryBackoffMs" default:"100" validate:"min=1"`
+
+	BotCircuitBreakerThreshold       uint `yaml:"botCircuitBreakerThreshold" json:"botCircuitBreakerThreshold" default:"10" validate:"min=1"`
+	BotCircuitBreakerCooldownSeconds int  `yaml:"botCircuitBreakerCooldownSeconds" json:"botCircuitBreakerCooldownSeconds" default:"60" validate:"min=1"`
+
+	// checks the bot health periodically and stops sending requests to the bots which fail too many consecutive checks
+	BotHealthCheckIntervalSeconds  int  `yaml:"botHealthCheckIntervalSeconds" json:"botHealthCheckIntervalSeconds" default:"30" validate:"min=0"`
+	BotHealthCheckFailureThreshold uint `yaml:"botHealthCheckFailureThreshold" json:"botHealthCheckFailureThreshold" default:"3" validate:"min=1"`
+
+	// replays the requests of the last blocks to the newly started bots, marked as warm-up, before the live requests - the
+	// findings of the warm-up requests are discarded and the bots start processing the live requests after the timeout
+	BotWarmUpBlocks         int `yaml:"botWarmUpBlocks" json:"botWarmUpBlocks" validate:"min=0,max=1000"`
+	BotWarmUpTimeoutSeconds int `yaml:"botWarmUpTimeoutSeconds" json:"botWarmUpTimeoutSeconds" default:"60" validate:"min=1"`
+
+	// persists the requests which could not be delivered to the bots after the retries so that they can be replayed
+	EnableDeadLetters               bool `yaml:"enableDeadLetters" json:"enableDeadLetters"`
+	MaxDeadLetters                  int  `yaml:"maxDeadLetters" json:"maxDeadLetters" default:"10000" validate:"min=1"`
+	DeadLetterReplayIntervalSeconds int  `yaml:"deadLetterReplayIntervalSeconds" json:"deadLetterReplayIntervalSeconds" default:"10" validate:"min=1"`
+
+	// disables sending the tx evaluation requests through long-lived streams to the bots which support streaming
+	DisableBotTxStreams bool `yaml:"disableBotTxStreams" json:"disableBotTxStreams"`
+
+	// sends the tx evaluation requests in batches to the bots which support batching - a batch is sent
+	// when it reaches the max size or when its oldest request waits for the max wait time
+	EnableBotBatches  bool `yaml:"enableBotBatches" json:"enableBotBatches"`
+	BotBatchMaxSize   int  `yaml:"botBatchMaxSize" json:"botBatchMaxSize" default:"50" validate:"min=1"`
+	BotBatchMaxWaitMs int  `yaml:"botBatchMaxWaitMs" json:"botBatchMaxWaitMs" default:"50" validate:"min=0"`
+
+	// bounds the concurrent evaluation requests per request type of each bot and of all bots in total
+	BotConcurrency           int            `yaml:"botConcurrency" json:"botConcurrency" default:"1" validate:"min=1"`
+	BotConcurrencyOverrides  map[string]int `yaml:"botConcurrencyOverrides" json:"botConcurrencyOverrides" validate:"dive,min=1"`
+	MaxConcurrentBotRequests int            `yaml:"maxConcurrentBotRequests" json:"maxConcurrentBotRequests" validate:"min=0"`
+
+	// decides what happens to the new requests when the request buffer of a bot is full
+	BotBackpressurePolicy   string            `yaml:"botBackpressurePolicy" json:"botBackpressurePolicy" default:"drop-newest" validate:"omitempty,oneof=block drop-oldest drop-newest"`
+	BotBackpressurePolicies map[string]string `yaml:"botBackpressurePolicies" json:"botBackpressurePolicies" validate:"dive,oneof=block drop-oldest drop-newest"`
+
+	PendingTxs PendingTxsConfig `yaml:"pendingTxs" json:"pendingTxs"`
+
+	// fetches the receipts to send the actual status and gas usage of the transactions to the bots
+	FetchReceipts bool `yaml:"fetchReceipts" json:"fetchReceipts"`
+
+	RpcFailover RpcFailoverConfig `yaml:"rpcFailover" json:"rpcFailover"`
+
+	// skips the blocks which are delivered again, e.g. after a failover between the json-rpc providers
+	DuplicateBlocks DuplicateBlocksConfig `yaml:"duplicateBlocks" json:"duplicateBlocks"`
+
+	// disables the bots which time out, fail or respond too slowly
+	BotPerformance BotPerformanceConfig `yaml:"botPerformance" json:"botPerformance"`
+
+	// reports the blocks which each bot evaluated periodically
+	Coverage CoverageConfig `yaml:"coverage" json:"coverage"`
+
+	// caps the calldata, the traces and the logs of the transaction events sent to the bots
+	ContentLimits ContentLimitsConfig `yaml:"contentLimits" json:"contentLimits"`
+
+	// raises an alarm when the last processed block falls behind the chain head by more than the threshold - zero disables
+	BlockLagAlarmThreshold       uint64 `yaml:"blockLagAlarmThreshold" json:"blockLagAlarmThreshold" default:"50"`
+	BlockLagCheckIntervalSeconds int    `yaml:"blockLagCheckIntervalSeconds" json:"blockLagCheckIntervalSeconds" default:"30" validate:"min=1"`
+
+	// delays dispatching the blocks to the bots until they are confirmed or finalized
+	Finality FinalityConfig `yaml:"finality" json:"finality"`
+
+	// disables resuming from the last processed block after restarts
+	DisableCheckpoints bool `yaml:"disableCheckpoints" json:"disableCheckpoints"`
+
+	// splits the chain events between the nodes which scan the same chain for the same bots
+	Sharding ShardingConfig `yaml:"sharding" json:"sharding"`
+
+	// the transaction requests which each bot can buffer - uses the default of the chain if not set
+	BotTxBufferSize int `yaml:"botTxBufferSize" json:"botTxBufferSize" validate:"min=0"`
+
+	// spills the transaction requests to the disk when the buffer of a bot is full, instead of applying the
+	// backpressure policy, until the requests of the bot take the max disk space
+	DisableBotTxOverflow bool `yaml:"disableBotTxOverflow" json:"disableBotTxOverflow"`
+	BotTxOverflowMaxMB   int  `yaml:"botTxOverflowMaxMb" json:"botTxOverflowMaxMb" default:"256" validate:"min=1"`
+
+	// logs one of every N bot responses at the debug level
+	BotResponseLogSampleRate int `yaml:"botResponseLogSampleRate" json:"botResponseLogSampleRate" default:"100" validate:"min=1"`
+
+	// bounds each of the shutdown steps: draining the bots and flushing the alerts
+	ShutdownTimeoutSeconds int `yaml:"shutdownTimeoutSeconds" json:"shutdownTimeoutSeconds" default:"30" validate:"min=1"`
+
+	// the bots which receive the events of the main chain - all bots receive them if empty
+	Bots []string `yaml:"bots" json:"bots"`
+
+	// sends the logs to the bots which subscribe only to the log events
+	LogFeed LogFeedConfig `yaml:"logFeed" json:"logFeed"`
+
+	// sends the attestations, the proposals, the slashings and the exits from a consensus client to the
+	// bots which subscribe to the beacon events
+	Beacon BeaconFeedConfig `yaml:"beacon" json:"beacon"`
+
+	// sends the latest block to the bots which subscribe to the schedule events at their intervals
+	Schedule ScheduleFeedConfig `yaml:"schedule" json:"schedule"`
+
+	// sends the transactions of the private order flow from a provider only to the permitted bots
+	PrivateFlow PrivateFlowConfig `yaml:"privateFlow" json:"privateFlow"`
+
+	// decodes the function calls and the logs of the transactions before sending them to the bots
+	AbiDecoder AbiDecoderConfig `yaml:"abiDecoder" json:"abiDecoder"`
+
+	// attaches the base fee, the gas used ratio and the priority fee percentiles of the blocks to the events
+	GasContext GasContextConfig `yaml:"gasContext" json:"gasContext"`
+
+	// attaches the balance, nonce, code and storage changes of the transactions to the events
+	StateDiff StateDiffConfig `yaml:"stateDiff" json:"stateDiff"`
+
+	// attaches the raw RLP, the type, the chain ID and the access list of the transactions to the events
+	RawTransactions RawTransactionsConfig `yaml:"rawTransactions" json:"rawTransactions"`
+
+	// attaches the blob sidecars of the EIP-4844 transactions from the beacon api to the events
+	Blobs BlobsConfig `yaml:"blobs" json:"blobs"`
+
+	// tags the transactions which involve the addresses of the watchlists with the labels of the addresses
+	Watchlists WatchlistsConfig `yaml:"watchlists" json:"watchlists"`
+
+	// annotates the findings about the token transfers with the token metadata and the USD values
+	TokenEnrichment TokenEnrichmentConfig `yaml:"tokenEnrichment" json:"tokenEnrichment"`
+
+	// publishes the findings about the node itself with the alerts
+	SelfFindings SelfFindingsConfig `yaml:"selfFindings" json:"selfFindings"`
+
+	// replaces the block feed with a fake chain which generates synthetic blocks and transactions
+	FakeChain FakeChainConfig `yaml:"fakeChain" json:"fakeChain"`
+
+	// dispatches the newest blocks first when the node falls behind
+	RecencyPriority RecencyPriorityConfig `yaml:"recencyPriority" json:"recencyPriority"`
+}
+
+// RecencyPriorityConfig configures the queue which sends the newest of the buffered blocks and
+// transactions to the bots first, while the older ones are backfilled at a lower priority.
+type RecencyPriorityConfig struct {
+	Enable            bool `yaml:"enable" json:"enable"`
+	MaxBufferedBlocks int  `yaml:"maxBufferedBlocks" json:"maxBufferedBlocks" default:"100" validate:"min=1"`
+	MaxBufferedTxs    int  `yaml:"maxBufferedTxs" json:"maxBufferedTxs" default:"20000" validate:"min=1"`
+}
+
+// SelfFindingsConfig configures the findings which the node creates about its own failures, like
+// the RPC failures, the bot crashes, the block lag and the publish failures. A finding is not
+// repeated for the same failure until the cooldown passes.
+type SelfFindingsConfig struct {
+	Enable               bool `yaml:"enable" json:"enable"`
+	CheckIntervalSeconds int  `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"60" validate:"min=1"`
+	CooldownSeconds      int  `yaml:"cooldownSeconds" json:"cooldownSeconds" default:"900" validate:"min=0"`
+}
+
+// GasContextConfig configures the gas context which is fetched with eth_feeHistory.
+type GasContextConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// the priority fee percentiles - uses 10, 50 and 90 if not set
+	RewardPercentiles []float64 `yaml:"rewardPercentiles" json:"rewardPercentiles" validate:"dive,min=0,max=100"`
+}
+
+// StateDiffConfig configures the state diffs of the transactions. The diffs are requested from the trace
+// json-rpc api if it is configured, otherwise from the scan json-rpc api.
+type StateDiffConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// API is the state diff API to use: debug_traceBlockByNumber (Geth) or trace_replayBlockTransactions (Parity/Erigon)
+	API string `yaml:"api" json:"api" default:"debug_traceBlockByNumber" validate:"oneof=debug_traceBlockByNumber trace_replayBlockTransactions"`
+}
+
+// RawTransactionsConfig configures the raw transactions, which are read from the full blocks of
+// the scan json-rpc api.
+type RawTransactionsConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+}
+
+// BlobsConfig configures the blob sidecars of the EIP-4844 transactions. The blobs are found by the
+// versioned hashes of the raw transactions, so the raw transactions are attached too. The beacon nodes
+// keep the blobs for a limited time, so the old blocks are sent without them.
+type BlobsConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// the beacon api url
+	URL string `yaml:"url" json:"url" validate:"required_if=Enable true,omitempty,url"`
+	// a blob is 128 KiB, so the rest of the blobs are left out to keep the events small enough
+	MaxBlobsPerTx int `yaml:"maxBlobsPerTx" json:"maxBlobsPerTx" default:"6" validate:"min=0"`
+}
+
+// TokenEnrichmentConfig configures the token transfer annotations of the findings. The symbols and
+// the decimals are read from the token contracts with the scan json-rpc api. The price URL can contain
+// the {chainId} and the {address} placeholders, and it should respond with a JSON object which contains
+// the USD price as "usd", optionally under the token address like the CoinGecko token price API.
+// The USD values are not annotated if the price URL is not set.
+type TokenEnrichmentConfig struct {
+	Enable            bool   `yaml:"enable" json:"enable"`
+	PriceURL          string `yaml:"priceUrl" json:"priceUrl"`
+	PriceCacheSeconds int    `yaml:"priceCacheSeconds" json:"priceCacheSeconds" default:"300" validate:"min=1"`
+	// the max amount of the transfers to annotate each finding with
+	MaxTransfers int `yaml:"maxTransfers" json:"maxTransfers" default:"10" validate:"min=1"`
+}
+
+// WatchlistsConfig configures the address watchlists. The lists are refreshed periodically, so that the
+// lists which are maintained elsewhere, like the sanctioned addresses, stay up to date.
+type WatchlistsConfig struct {
+	Lists                  []WatchlistConfig `yaml:"lists" json:"lists" validate:"dive"`
+	RefreshIntervalSeconds int               `yaml:"refreshIntervalSeconds" json:"refreshIntervalSeconds" default:"300" validate:"min=1"`
+}
+
+// WatchlistConfig is a file or an http(s) url which contains an address and its labels on each line, like
+// "0x...,exploiter", or a JSON object of the addresses and the label arrays. The label of the list is
+// added to all of its addresses.
+type WatchlistConfig struct {
+	Source string `yaml:"source" json:"source" validate:"required"`
+	Label  string `yaml:"label" json:"label"`
+}
+
+// FakeChainConfig configures the fake chain which is used for testing the pipeline and the bots
+// without a chain. The same seed generates the same blocks and transactions.
+type FakeChainConfig struct {
+	Enable          bool   `yaml:"enable" json:"enable"`
+	StartBlock      uint64 `yaml:"startBlock" json:"startBlock" default:"1"`
+	EndBlock        uint64 `yaml:"endBlock" json:"endBlock"`
+	BlockIntervalMs int    `yaml:"blockIntervalMs" json:"blockIntervalMs" default:"1000" validate:"min=0"`
+	TxsPerBlock     int    `yaml:"txsPerBlock" json:"txsPerBlock" default:"10" validate:"min=0"`
+	Accounts        int    `yaml:"accounts" json:"accounts" default:"100" validate:"min=1"`
+	Seed            int64  `yaml:"seed" json:"seed" default:"1"`
+}
+
+// AbiDecoderConfig configures the ABIs which the transactions are decoded with. The contract ABIs
+// are preferred to the signatures, which are used for all contracts.
+type AbiDecoderConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// the JSON ABI files of the contracts
+	ABIs []ContractABIConfig `yaml:"abis" json:"abis" validate:"dive"`
+	// the files which contain a function or an event signature on each line, in addition to the
+	// common signatures - the event signatures start with "event "
+	SignatureFiles []string `yaml:"signatureFiles" json:"signatureFiles"`
+	// fetches the ABIs of the verified contracts in the background
+	Etherscan EtherscanConfig `yaml:"etherscan" json:"etherscan"`
+	Sourcify  SourcifyConfig  `yaml:"sourcify" json:"sourcify"`
+}
+
+// ContractABIConfig is the ABI file of a contract. The ABIs without an address are used for all
+// contracts, like the signatures.
+type ContractABIConfig struct {
+	Address string `yaml:"address" json:"address" validate:"omitempty,eth_addr"`
+	Path    string `yaml:"path" json:"path" validate:"required"`
+}
+
+type EtherscanConfig struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	APIURL string `yaml:"apiUrl" json:"apiUrl" default:"https://api.etherscan.io/api" validate:"url"`
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+}
+
+type SourcifyConfig struct {
+	Enable  bool   `yaml:"enable" json:"enable"`
+	RepoURL string `yaml:"repoUrl" json:"repoUrl" default:"https://repo.sourcify.dev" validate:"url"`
+}
+
+// LogFeedConfig configures the feed which gets the logs from eth_getLogs with the address and topic
+// filters of the bots.
+type LogFeedConfig struct {
+	Enable              bool   `yaml:"enable" json:"enable"`
+	PollIntervalSeconds int    `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"5" validate:"min=1"`
+	MaxBlockRange       uint64 `yaml:"maxBlockRange" json:"maxBlockRange" default:"100" validate:"min=1"`
+}
+
+// BeaconFeedConfig configures the feed which gets the blocks of the beacon chain from the beacon API
+// of a consensus client.
+type BeaconFeedConfig struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	URL    string `yaml:"url" json:"url" validate:"required_if=Enable true,omitempty,url"`
+	// a slot is 12 seconds on the mainnet
+	PollIntervalSeconds int `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"12" validate:"min=1"`
+	// the max slots which are processed in each poll, so that the feed catches up gradually
+	MaxSlotRange uint64 `yaml:"maxSlotRange" json:"maxSlotRange" default:"32" validate:"min=1"`
+}
+
+// ScheduleFeedConfig configures the feed which evaluates the bots periodically with the latest block,
+// so that the bots which check a state from time to time do not need to receive every block.
+type ScheduleFeedConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// the interval of the bots which do not set one
+	DefaultIntervalSeconds int `yaml:"defaultIntervalSeconds" json:"defaultIntervalSeconds" default:"600" validate:"min=1"`
+	// the shorter intervals of the bots are raised to this
+	MinIntervalSeconds int `yaml:"minIntervalSeconds" json:"minIntervalSeconds" default:"60" validate:"min=1"`
+}
+
+// PrivateFlowConfig configures the feed which gets the bundles of the private order flow from a
+// Flashbots-style relay or an endpoint of the operator, so that the permitted bots can detect the
+// transactions before they are included. The endpoint should return the bundles in the eth_sendBundle
+// format as {"bundles": [...]}.
+type PrivateFlowConfig struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	URL    string `yaml:"url" json:"url" validate:"required_if=Enable true,omitempty,url"`
+	// the name of the provider which the bots receive with the transactions
+	Name string `yaml:"name" json:"name" default:"private"`
+	// sends the token with this header, like "Authorization", if set
+	AuthHeader string `yaml:"authHeader" json:"authHeader"`
+	AuthToken  string `yaml:"authToken" json:"authToken"`
+	// the only bots which receive the private transactions
+	Bots                []string `yaml:"bots" json:"bots" validate:"required_if=Enable true"`
+	PollIntervalSeconds int      `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"2" validate:"min=1"`
+}
+
+// Block finality modes
+const (
+	FinalityModeLatest        = "latest"
+	FinalityModeConfirmations = "confirmations"
+	FinalityModeFinalized     = "finalized"
+)
+
+// FinalityConfig decides how deep the blocks should be before they are sent to the bots. The blocks are
+// sent as soon as they are mined in the "latest" mode, after the given amount of confirmations in the
+// "confirmations" mode and after the chain tags them as finalized in the "finalized" mode. The default
+// confirmations of the chain are used if the confirmations are not set.
+type FinalityConfig struct {
+	Mode                string `yaml:"mode" json:"mode" default:"latest" validate:"omitempty,oneof=latest confirmations finalized"`
+	Confirmations       uint64 `yaml:"confirmations" json:"confirmations"`
+	PollIntervalSeconds int    `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"5" validate:"min=1"`
+}
+
+// Sharding modes
+const (
+	ShardByBlock = "block"
+	ShardByTx    = "tx"
+)
+
+// ShardingConfig makes the node process only a deterministic part of the chain events, so that a
+// large set of bots can be scanned by multiple nodes without duplicate alerts. Each node should
+// have the same amount of shards and a different shard ID. In the "block" mode, the blocks and
+// their transactions are split by the block number. In the "tx" mode, the blocks are split by the
+// block number and the transactions are split by the transaction hash.
+type ShardingConfig struct {
+	Shards  uint   `yaml:"shards" json:"shards" default:"1" validate:"min=1"`
+	ShardID uint   `yaml:"shardId" json:"shardId" validate:"ltfield=Shards"`
+	By      string `yaml:"by" json:"by" default:"block" validate:"omitempty,oneof=block tx"`
+}
+
+// ChainConfig is an additional chain which the node scans in its own feed and analyzer pipeline.
+// The other scan settings are the same with the main chain.
+type ChainConfig struct {
+	ChainID int           `yaml:"chainId" json:"chainId" validate:"required,min=1"`
+	JsonRpc JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc"`
+	Trace   TraceConfig   `yaml:"trace" json:"trace"`
+	// the bots which receive the events of this chain - all bots receive them if empty
+	Bots []string `yaml:"bots" json:"bots"`
+	// overrides the finality of the main chain, so that the chains can use different modes
+	Finality *FinalityConfig `yaml:"finality" json:"finality"`
+	// overrides the sharding of the main chain
+	Sharding *ShardingConfig `yaml:"sharding" json:"sharding"`
+}
+
+// PendingTxsConfig enables streaming pending transactions from the mempool to the bots.
+type PendingTxsConfig struct {
+	Enable       bool   `yaml:"enable" json:"enable"`
+	WebsocketURL string `yaml:"websocketUrl" json:"websocketUrl" validate:"required_if=Enable true,omitempty,url"`
+
+	// tracks the pending transactions and sends the dropped, replaced and stuck ones to the bots again
+	// with their mempool status. The transactions which are not mined within the timeout are looked up.
+	TrackStatus          bool `yaml:"trackStatus" json:"trackStatus"`
+	MaxTrackedTxs        int  `yaml:"maxTrackedTxs" json:"maxTrackedTxs" default:"10000" validate:"min=1"`
+	StatusTimeoutSeconds int  `yaml:"statusTimeoutSeconds" json:"statusTimeoutSeconds" default:"300" validate:"min=1"`
+}
+
+// BotConcurrencyFor returns the max concurrent evaluation requests for the bot with given ID.
+// Per-bot values from the overrides map override the global default.
+func (sc ScannerConfig) BotConcurrencyFor(botID string) int {
+	for id, concurrency := range sc.BotConcurrencyOverrides {
+		if strings.EqualFold(id, botID) {
+			return concurrency
+		}
+	}
+	return sc.BotConcurrency
+}
+
+// Backpressure policies
+const (
+	BackpressureBlock      = "block"
+	BackpressureDropOldest = "drop-oldest"
+	BackpressureDropNewest = "drop-newest"
+)
+
+// BotBackpressurePolicyFor returns the backpressure policy for the bot with given ID.
+// Per-bot values from the policies map override the global default.
+func (sc ScannerConfig) BotBackpressurePolicyFor(botID string) string {
+	for id, policy := range sc.BotBackpressurePolicies {
+		if strings.EqualFold(id, botID) {
+			return policy
+		}
+	}
+	return sc.BotBackpressurePolicy
+}
+
+// BotRequestTimeout returns the evaluation request timeout for the bot with given ID.
+// Per-bot values from the timeouts map override the global default.
+func (sc ScannerConfig) BotRequestTimeout(botID string) time.Duration {
+	for id, timeoutSeconds := range sc.BotRequestTimeouts {
+		if strings.EqualFold(id, botID) {
+			return time.Duration(timeoutSeconds) * time.Second
+		}
+	}
+	return time.Duration(sc.BotRequestTimeoutSeconds) * time.Second
 }
 
 type TraceConfig struct {
 	JsonRpc JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc"`
 	Enabled bool          `yaml:"enabled" json:"enabled"`
+	// API is the trace API to use: trace_block (Parity/Erigon) or debug_traceBlockByNumber (Geth)
+	API string `yaml:"api" json:"api" default:"trace_block" validate:"omitempty,oneof=trace_block debug_traceBlockByNumber"`
 }
 
 type RateLimitConfig struct {
@@ -40,8 +504,39 @@ type RateLimitConfig struct {
 }
 
 type JsonRpcProxyConfig struct {
-	JsonRpc         JsonRpcConfig    `yaml:"jsonRpc" json:"jsonRpc"`
-	RateLimitConfig *RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	JsonRpc         JsonRpcConfig               `yaml:"jsonRpc" json:"jsonRpc"`
+	RateLimitConfig *RateLimitConfig            `yaml:"rateLimit" json:"rateLimit"`
+	BotRateLimits   map[string]*RateLimitConfig `yaml:"botRateLimits" json:"botRateLimits" validate:"dive"`
+	AllowedMethods  []string                    `yaml:"allowedMethods" json:"allowedMethods"`
+	Cache           JsonRpcCacheConfig          `yaml:"cache" json:"cache"`
+	History         HistoryAPIConfig            `yaml:"history" json:"history"`
+}
+
+// JsonRpcCacheConfig makes the JSON-RPC proxy respond to the same requests of the bots from a short-lived
+// cache, so that the bots which query the same data while processing a block do not multiply the
+// upstream load. The cache key contains the method and the params, including the block tag or number.
+type JsonRpcCacheConfig struct {
+	Disable    bool `yaml:"disable" json:"disable"`
+	TTLSeconds int  `yaml:"ttlSeconds" json:"ttlSeconds" default:"3" validate:"min=1"`
+	MaxEntries int  `yaml:"maxEntries" json:"maxEntries" default:"10000" validate:"min=1"`
+	// the methods which are cached - supports the '*' suffix like the allowed methods
+	Methods []string `yaml:"methods" json:"methods"`
+}
+
+// HistoryAPIConfig enables the gRPC service next to the JSON-RPC proxy which serves the common
+// historical lookups of the bots: the recent transactions to an address, the previous alerts of the
+// bot and the block timestamps. The lookups are cached for all bots and rate limited per bot.
+type HistoryAPIConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// the requests per second of each bot
+	Rate  float64 `yaml:"rate" json:"rate" default:"5" validate:"gt=0"`
+	Burst int     `yaml:"burst" json:"burst" default:"10" validate:"min=1"`
+	// the amount of the latest blocks which an address lookup scans
+	MaxBlocks       int `yaml:"maxBlocks" json:"maxBlocks" default:"100" validate:"min=1"`
+	MaxTransactions int `yaml:"maxTransactions" json:"maxTransactions" default:"100" validate:"min=1"`
+	MaxAlerts       int `yaml:"maxAlerts" json:"maxAlerts" default:"100" validate:"min=1"`
+	CacheTTLSeconds int `yaml:"cacheTtlSeconds" json:"cacheTtlSeconds" default:"60" validate:"min=1"`
+	CacheMaxBlocks  int `yaml:"cacheMaxBlocks" json:"cacheMaxBlocks" default:"1000" validate:"min=1"`
 }
 
 type LogConfig struct {
@@ -51,15 +546,56 @@ type LogConfig struct {
 }
 
 type RegistryConfig struct {
-	ChainID                uint64        `yaml:"chainId" json:"chainId" default:"137"`
-	JsonRpc                JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc" default:"{\"url\": \"https://polygon-rpc.com\"}"`
-	IPFS                   IPFSConfig    `yaml:"ipfs" json:"ipfs"`
-	ContainerRegistry      string        `yaml:"containerRegistry" json:"containerRegistry" validate:"hostname|hostname_port" default:"disco.forta.network" `
-	Username               string        `yaml:"username" json:"username"`
-	Password               string        `yaml:"password" json:"password"`
-	Disable                bool          `yaml:"disable" json:"disable"` // for testing situations
-	CheckIntervalSeconds   int           `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"15"`
-	ReleaseDistributionUrl string        `yaml:"releaseDistributionUrl" json:"releaseDistributionUrl" default:"https://dist.forta.network/manifests/releases"`
+	ChainID              uint64        `yaml:"chainId" json:"chainId" default:"137"`
+	JsonRpc              JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc" default:"{\"url\": \"https://polygon-rpc.com\"}"`
+	IPFS                 IPFSConfig    `yaml:"ipfs" json:"ipfs"`
+	ContainerRegistry    string        `yaml:"containerRegistry" json:"containerRegistry" validate:"hostname|hostname_port" default:"disco.forta.network" `
+	Username             string        `yaml:"username" json:"username"`
+	Password             string        `yaml:"password" json:"password"`
+	Disable              bool          `yaml:"disable" json:"disable"` // for testing situations
+	CheckIntervalSeconds int           `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"15"`
+	// the supervisor syncs the bot containers with the assigned bots at this interval
+	BotRefreshIntervalSeconds int `yaml:"botRefreshIntervalSeconds" json:"botRefreshIntervalSeconds" default:"60" validate:"min=1"`
+	// verifies the developer signatures of the bot manifests
+	VerifyManifestSignatures bool   `yaml:"verifyManifestSignatures" json:"verifyManifestSignatures"`
+	ReleaseDistributionUrl   string `yaml:"releaseDistributionUrl" json:"releaseDistributionUrl" default:"https://dist.forta.network/manifests/releases"`
+	// verifies that this scanner is registered and staked enough
+	ScannerCheck ScannerCheckConfig `yaml:"scannerCheck" json:"scannerCheck"`
+	// launches only the bots which are signed by the allowed developers, unless in the development mode
+	Developers DeveloperAllowlistConfig `yaml:"developers" json:"developers"`
+}
+
+// DeveloperAllowlistConfig decides whose bots the node launches. If any developers are allowed, the bot
+// manifests should be signed by one of them and the unsigned bots and the bots of the other developers
+// are refused. The bots which are configured by their images in the local mode do not have manifests
+// and are not checked.
+type DeveloperAllowlistConfig struct {
+	// the developer addresses which are allowed to sign the bot manifests
+	Allowed []string `yaml:"allowed" json:"allowed" validate:"dive,eth_addr"`
+	// allows the manifests which are signed by the owners of the bots in the registry
+	AllowBotOwners bool `yaml:"allowBotOwners" json:"allowBotOwners"`
+}
+
+// Enabled tells if the developers of the bots should be checked.
+func (cfg DeveloperAllowlistConfig) Enabled() bool {
+	return len(cfg.Allowed) > 0 || cfg.AllowBotOwners
+}
+
+// Unverified scanner policies
+const (
+	UnverifiedScannerWarn   = "warn"
+	UnverifiedScannerRefuse = "refuse"
+	UnverifiedScannerMark   = "mark"
+)
+
+// ScannerCheckConfig verifies this scanner in the scanner registry. The scanner is verified if it is
+// registered, enabled and the stake allocated to each scanner of its pool is not below the minimum.
+type ScannerCheckConfig struct {
+	// the minimum stake per scanner in FORT, in addition to the threshold of the registry
+	MinStakeFORT string `yaml:"minStakeFort" json:"minStakeFort" validate:"omitempty,numeric"`
+	// what to do if the scanner is not verified: warn (default), refuse to publish or mark the alerts as unverified
+	OnUnverified         string `yaml:"onUnverified" json:"onUnverified" default:"warn" validate:"oneof=warn refuse mark"`
+	CheckIntervalSeconds int    `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"600" validate:"min=1"`
 }
 
 type IPFSConfig struct {
@@ -76,18 +612,127 @@ type BatchConfig struct {
 	MaxAlerts                    *int `yaml:"maxAlerts" json:"maxAlerts" default:"1000" `
 }
 
+// RetryConfig configures the backoff between the publish attempts of a batch which failed to
+// publish. The backoff doubles after each failed attempt until the max.
+type RetryConfig struct {
+	InitialBackoffSeconds int `yaml:"initialBackoffSeconds" json:"initialBackoffSeconds" default:"5" validate:"min=1"`
+	MaxBackoffSeconds     int `yaml:"maxBackoffSeconds" json:"maxBackoffSeconds" default:"300" validate:"min=1"`
+}
+
 type PublisherConfig struct {
 	SkipPublish   bool        `yaml:"skipPublish" json:"skipPublish" default:"false"`
 	AlwaysPublish bool        `yaml:"alwaysPublish" json:"alwaysPublish" default:"false"`
 	APIURL        string      `yaml:"apiUrl" json:"apiUrl" default:"https://alerts.forta.network" validate:"url"`
 	IPFS          IPFSConfig  `yaml:"ipfs" json:"ipfs" validate:"required_unless=SkipPublish true"`
 	Batch         BatchConfig `yaml:"batch" json:"batch"`
+	Retry         RetryConfig `yaml:"retry" json:"retry"`
+
+	// routes the alerts to the streaming platforms of the operator in addition to publishing them
+	Sinks []AlertSinkConfig `yaml:"sinks" json:"sinks" validate:"dive"`
+	// sends the alerts with the severities only to some of the destinations - the alerts with the
+	// other severities are sent to all destinations
+	Routes []AlertRouteConfig `yaml:"routes" json:"routes" validate:"dive"`
+	// keeps the Merkle proofs of the published alerts for the alert query api
+	Proofs BatchProofsConfig `yaml:"proofs" json:"proofs"`
+}
+
+// BatchProofsConfig makes the publisher sign the Merkle root of the alerts in each published batch
+// and keep the alert IDs of the batches for the retention period, so that the inclusion proofs of
+// the alerts can be served from the alert query api.
+type BatchProofsConfig struct {
+	Enable         bool `yaml:"enable" json:"enable"`
+	RetentionHours int  `yaml:"retentionHours" json:"retentionHours" default:"168" validate:"min=1"`
+}
+
+// Alert route destinations in addition to the alert sink names
+const (
+	AlertRouteBatch = "batch"
+	AlertRouteLocal = "local"
+)
+
+// AlertRouteConfig sends the alerts with the severities to the destinations. A destination is either
+// the name of an alert sink, "batch" for publishing to the network or "local" for the local alert store.
+type AlertRouteConfig struct {
+	Severities []string `yaml:"severities" json:"severities" validate:"required,dive,oneof=UNKNOWN INFO LOW MEDIUM HIGH CRITICAL"`
+	To         []string `yaml:"to" json:"to" validate:"required"`
+}
+
+// Alert sink types
+const (
+	AlertSinkKafka   = "kafka"
+	AlertSinkNATS    = "nats"
+	AlertSinkWebhook = "webhook"
+)
+
+// Alert sink partitioning
+const (
+	AlertSinkPartitionByChain    = "chain"
+	AlertSinkPartitionByBot      = "bot"
+	AlertSinkPartitionByChainBot = "chain-bot"
+)
+
+// Webhook body formats
+const (
+	WebhookFormatGeneric   = "generic"
+	WebhookFormatSlack     = "slack"
+	WebhookFormatPagerDuty = "pagerduty"
+	WebhookFormatCustom    = "custom"
+)
+
+// AlertSinkConfig is a streaming platform or a webhook which receives the alerts. The Kafka sink produces
+// to the topic through the Kafka REST proxy at the URL, the NATS sink publishes to the JetStream stream
+// which captures the subjects under the topic and the webhook sink posts each alert to the URL.
+// The alerts are buffered on the disk until they are delivered.
+type AlertSinkConfig struct {
+	Name        string            `yaml:"name" json:"name" validate:"required,alphanum"`
+	Type        string            `yaml:"type" json:"type" validate:"required,oneof=kafka nats webhook"`
+	URL         string            `yaml:"url" json:"url" validate:"required,url"`
+	Topic       string            `yaml:"topic" json:"topic" validate:"required_unless=Type webhook"`
+	Headers     map[string]string `yaml:"headers" json:"headers"`
+	PartitionBy string            `yaml:"partitionBy" json:"partitionBy" default:"chain-bot" validate:"oneof=chain bot chain-bot"`
+
+	// only the alerts with the severities and from the bots are sent - all alerts are sent if empty
+	Severities []string `yaml:"severities" json:"severities" validate:"dive,oneof=UNKNOWN INFO LOW MEDIUM HIGH CRITICAL"`
+	Bots       []string `yaml:"bots" json:"bots"`
+
+	Webhook WebhookSinkConfig `yaml:"webhook" json:"webhook"`
+
+	// the oldest alerts are dropped when the sink is unavailable for too long
+	MaxBufferedAlerts    int `yaml:"maxBufferedAlerts" json:"maxBufferedAlerts" default:"100000" validate:"min=1"`
+	FlushIntervalSeconds int `yaml:"flushIntervalSeconds" json:"flushIntervalSeconds" default:"1" validate:"min=1"`
+}
+
+// WebhookSinkConfig decides the body of the webhook requests. The custom format is a Go template
+// which receives the alert, the finding and the template params. The body is signed with HMAC-SHA256
+// if a secret is specified.
+type WebhookSinkConfig struct {
+	Format         string            `yaml:"format" json:"format" default:"generic" validate:"oneof=generic slack pagerduty custom"`
+	Template       string            `yaml:"template" json:"template" validate:"required_if=Format custom"`
+	TemplateParams map[string]string `yaml:"templateParams" json:"templateParams"`
+	Secret         string            `yaml:"secret" json:"secret"`
+	MaxAttempts    int               `yaml:"maxAttempts" json:"maxAttempts" default:"3" validate:"min=1"`
 }
 
 type ResourcesConfig struct {
 	DisableAgentLimits bool    `yaml:"disableAgentLimits" json:"disableAgentLimits" default:"false" `
 	AgentMaxMemoryMiB  int     `yaml:"agentMaxMemoryMib" json:"agentMaxMemoryMib" validate:"omitempty,min=100"`
 	AgentMaxCPUs       float64 `yaml:"agentMaxCpus" json:"agentMaxCpus" validate:"omitempty,gt=0"`
+
+	// overrides the limits above for specific bots
+	AgentLimits []AgentResourcesConfig `yaml:"agentLimits" json:"agentLimits" validate:"dive"`
+
+	// the bots which stay at their limits for this many consecutive checks are reported and the
+	// bots at their memory limits are restarted if the enforcement is enabled - docker throttles the cpu
+	// so the bots at their cpu limits are only reported
+	EnforceMemoryLimits  bool `yaml:"enforceMemoryLimits" json:"enforceMemoryLimits" default:"false"`
+	LimitViolationChecks int  `yaml:"limitViolationChecks" json:"limitViolationChecks" default:"5" validate:"omitempty,min=1"`
+}
+
+// AgentResourcesConfig contains the resource limits of a bot.
+type AgentResourcesConfig struct {
+	BotID        string  `yaml:"botId" json:"botId" validate:"required"`
+	MaxMemoryMiB int     `yaml:"maxMemoryMib" json:"maxMemoryMib" validate:"omitempty,min=100"`
+	MaxCPUs      float64 `yaml:"maxCpus" json:"maxCpus" validate:"omitempty,gt=0"`
 }
 
 type ENSConfig struct {
@@ -113,6 +758,17 @@ type AgentLogsConfig struct {
 	URL                 string `yaml:"url" json:"url" default:"https://alerts.forta.network/logs/agents" validate:"url"`
 	Disable             bool   `yaml:"disable" json:"disable"`
 	SendIntervalSeconds int    `yaml:"sendIntervalSeconds" json:"sendIntervalSeconds" default:"60"`
+	// Capture writes the bot container logs to the files in the Forta dir.
+	Capture AgentLogCaptureConfig `yaml:"capture" json:"capture"`
+}
+
+type AgentLogCaptureConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// the log file of a bot is rotated after this size
+	MaxFileSizeMB int `yaml:"maxFileSizeMb" json:"maxFileSizeMb" default:"10" validate:"min=1"`
+	// the amount of the log files kept for each bot, including the current file
+	MaxFiles            int `yaml:"maxFiles" json:"maxFiles" default:"3" validate:"min=1"`
+	PollIntervalSeconds int `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"10"`
 }
 
 type ContainerRegistryConfig struct {
@@ -166,6 +822,12 @@ type LocalModeConfig struct {
 	ShardedBots           []*LocalShardedBot       `yaml:"shardedBots" json:"shardedBots"`
 	PrivateKeyHex         string                   `yaml:"privateKeyHex" json:"privateKeyHex"`
 	Standalone            StandaloneModeConfig     `yaml:"standalone" json:"standalone"`
+	// the subscription filters of the local bots by the bot ID or the image reference
+	BotFilters map[string]*BotFilters `yaml:"botFilters" json:"botFilters" validate:"dive"`
+	// the bots which run on the host and receive the events next to the bots in the containers
+	AttachedBots []*LocalAttachedBot `yaml:"attachedBots" json:"attachedBots" validate:"dive"`
+	// the bots which are HTTP services and receive the events as JSON requests
+	HTTPBots []*LocalHTTPBot `yaml:"httpBots" json:"httpBots" validate:"dive"`
 }
 
 // IsStandalone checks if the node is in standalone mode. It should only be available
@@ -174,6 +836,23 @@ func (lmc LocalModeConfig) IsStandalone() bool {
 	return lmc.Enable && lmc.Standalone.Enable
 }
 
+// LocalAttachedBot is a bot which is being developed and runs outside of the node. The node dials
+// the bot at the address over plaintext and logs its findings with the details. The localhost
+// addresses are dialed at the Docker host.
+type LocalAttachedBot struct {
+	ID      string      `yaml:"id" json:"id" validate:"required"`
+	Address string      `yaml:"address" json:"address" validate:"required,hostname_port"`
+	Filters *BotFilters `yaml:"filters" json:"filters"`
+}
+
+// LocalHTTPBot is a bot which runs behind an HTTP service. The node posts the requests under the
+// endpoint as JSON and parses the findings from the JSON responses.
+type LocalHTTPBot struct {
+	ID       string      `yaml:"id" json:"id" validate:"required"`
+	Endpoint string      `yaml:"endpoint" json:"endpoint" validate:"required,url"`
+	Filters  *BotFilters `yaml:"filters" json:"filters"`
+}
+
 type LocalShardedBot struct {
 	BotImage *string `yaml:"botImage" json:"botImage"`
 	// number of shards for bot
@@ -188,6 +867,242 @@ type InspectionConfig struct {
 	InspectAtStartup  *bool `yaml:"inspectAtStartup" json:"inspectAtStartup" default:"true"`
 }
 
+type PrometheusConfig struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	Port   string `yaml:"port" json:"port" default:"9107" validate:"omitempty,numeric"`
+	// the host interface which the port is published on - the metrics are not reachable from the network by default
+	HostIP string `yaml:"hostIp" json:"hostIp" default:"127.0.0.1" validate:"omitempty,ip"`
+}
+
+// StatusAPIConfig configures the HTTP API which serves the health and the status of the node. The token
+// is required as a bearer token from the clients which read the bot logs.
+type StatusAPIConfig struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	Port   string `yaml:"port" json:"port" default:"9108" validate:"omitempty,numeric"`
+	// the host interface which the port is published on - the status is not reachable from the network by default
+	HostIP string `yaml:"hostIp" json:"hostIp" default:"127.0.0.1" validate:"omitempty,ip"`
+	Token  string `yaml:"token" json:"token" validate:"required_if=Enable true"`
+}
+
+// AlertQueryAPIConfig enables the API which serves the alerts of the node from the local store. The
+// alerts are kept for the retention period. The token is required as a bearer token from the clients.
+type AlertQueryAPIConfig struct {
+	Enable         bool   `yaml:"enable" json:"enable"`
+	Port           string `yaml:"port" json:"port" default:"9111" validate:"omitempty,numeric"`
+	Token          string `yaml:"token" json:"token" validate:"required_if=Enable true"`
+	RetentionHours int    `yaml:"retentionHours" json:"retentionHours" default:"168" validate:"min=1"`
+	MaxResults     int    `yaml:"maxResults" json:"maxResults" default:"1000" validate:"min=1"`
+}
+
+// IngestAPIConfig enables the API which the external systems can push the events into the node with.
+// The token is required as a bearer token from the clients.
+type IngestAPIConfig struct {
+	Enable   bool   `yaml:"enable" json:"enable"`
+	Port     string `yaml:"port" json:"port" default:"9109" validate:"omitempty,numeric"`
+	GrpcPort string `yaml:"grpcPort" json:"grpcPort" default:"9110" validate:"omitempty,numeric"`
+	Token    string `yaml:"token" json:"token" validate:"required_if=Enable true"`
+}
+
+// AdminAPIConfig enables the API which the operators can manage the running node with. The API
+// listens on a unix socket in the forta dir, which only the owner of the dir can connect to, and
+// also on the port if it is set, which requires the client certificates that are signed by the
+// client CA. The file paths are relative to the forta dir.
+type AdminAPIConfig struct {
+	Enable       bool   `yaml:"enable" json:"enable"`
+	SocketName   string `yaml:"socketName" json:"socketName" default:"admin.sock"`
+	Port         string `yaml:"port" json:"port" validate:"omitempty,numeric"`
+	CertFile     string `yaml:"certFile" json:"certFile" validate:"required_with=Port"`
+	KeyFile      string `yaml:"keyFile" json:"keyFile" validate:"required_with=Port"`
+	ClientCAFile string `yaml:"clientCaFile" json:"clientCaFile" validate:"required_with=Port"`
+}
+
+// Tracing exporters
+const (
+	TracingExporterOTLPGRPC = "otlp-grpc"
+	TracingExporterOTLPHTTP = "otlp-http"
+)
+
+// TracingConfig enables exporting the OpenTelemetry spans of the scan pipeline to an OTLP collector.
+type TracingConfig struct {
+	Enable   bool              `yaml:"enable" json:"enable"`
+	Exporter string            `yaml:"exporter" json:"exporter" default:"otlp-grpc" validate:"omitempty,oneof=otlp-grpc otlp-http"`
+	Endpoint string            `yaml:"endpoint" json:"endpoint" validate:"required_if=Enable true"`
+	Insecure bool              `yaml:"insecure" json:"insecure"`
+	Headers  map[string]string `yaml:"headers" json:"headers"`
+	// the ratio of the blocks to trace
+	SampleRatio float64 `yaml:"sampleRatio" json:"sampleRatio" default:"1" validate:"min=0,max=1"`
+}
+
+// ChaosConfig enables injecting random faults to validate the retries, the circuit breakers and the
+// checkpoints in CI and staging. Each fault is injected with its probability and the probabilities are
+// zero by default. It should never be enabled in production.
+type ChaosConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// makes the faults repeatable - zero seeds with the current time
+	Seed int64 `yaml:"seed" json:"seed"`
+
+	// delays the bot requests, drops them until they time out or fails them
+	BotDelayProbability float64 `yaml:"botDelayProbability" json:"botDelayProbability" validate:"min=0,max=1"`
+	BotDelayMs          int     `yaml:"botDelayMs" json:"botDelayMs" default:"1000" validate:"min=0"`
+	BotDropProbability  float64 `yaml:"botDropProbability" json:"botDropProbability" validate:"min=0,max=1"`
+	BotErrorProbability float64 `yaml:"botErrorProbability" json:"botErrorProbability" validate:"min=0,max=1"`
+
+	// kills a random bot container periodically - zero disables
+	BotKillIntervalSeconds int `yaml:"botKillIntervalSeconds" json:"botKillIntervalSeconds" validate:"min=0"`
+
+	// stalls the json-rpc requests of the block feed
+	RPCStallProbability float64 `yaml:"rpcStallProbability" json:"rpcStallProbability" validate:"min=0,max=1"`
+	RPCStallMs          int     `yaml:"rpcStallMs" json:"rpcStallMs" default:"5000" validate:"min=0"`
+}
+
+// AlertFilterConfig bounds the alerts from noisy bots. Zero values disable the filters.
+type AlertFilterConfig struct {
+	DedupeWindowSeconds int `yaml:"dedupeWindowSeconds" json:"dedupeWindowSeconds" validate:"min=0"`
+	MaxAlertsPerMinute  int `yaml:"maxAlertsPerMinute" json:"maxAlertsPerMinute" validate:"min=0"`
+
+	// suppresses or tags the matching findings before they are published
+	Rules []FindingRule `yaml:"rules" json:"rules" validate:"dive"`
+}
+
+// Finding rule actions
+const (
+	FindingRuleActionDrop = "drop"
+	FindingRuleActionTag  = "tag"
+)
+
+// FindingRule matches the findings by all of the non-empty fields. A finding matches a field
+// if it matches any of the values in the field.
+type FindingRule struct {
+	Bots       []string          `yaml:"bots" json:"bots"`
+	AlertIDs   []string          `yaml:"alertIds" json:"alertIds"`
+	Severities []string          `yaml:"severities" json:"severities" validate:"dive,oneof=UNKNOWN INFO LOW MEDIUM HIGH CRITICAL"`
+	Addresses  []string          `yaml:"addresses" json:"addresses" validate:"dive,len=42,hexadecimal"`
+	Action     string            `yaml:"action" json:"action" validate:"omitempty,oneof=drop tag"`
+	Tags       map[string]string `yaml:"tags" json:"tags" validate:"required_if=Action tag"`
+}
+
+// AgentGrpcConfig contains the dial options of the bot gRPC connections. The connections are
+// pinged with the keepalive interval, so that the broken connections are detected and redialed
+// without waiting for the next request. The compressor is negotiated with each bot and the tx
+// requests which exceed the max send size are truncated or dropped by the oversize policy.
+type AgentGrpcConfig struct {
+	// the port which the bots listen to
+	Port                       string `yaml:"port" json:"port" default:"50051" validate:"numeric"`
+	KeepaliveSeconds           int    `yaml:"keepaliveSeconds" json:"keepaliveSeconds" default:"30" validate:"min=0"`
+	KeepaliveTimeoutSeconds    int    `yaml:"keepaliveTimeoutSeconds" json:"keepaliveTimeoutSeconds" default:"10" validate:"min=1"`
+	MaxRecvMsgSizeBytes        int    `yaml:"maxRecvMsgSizeBytes" json:"maxRecvMsgSizeBytes" default:"250000" validate:"min=1"`
+	MaxSendMsgSizeBytes        int    `yaml:"maxSendMsgSizeBytes" json:"maxSendMsgSizeBytes" default:"16777216" validate:"min=1"`
+	Compression                string `yaml:"compression" json:"compression" validate:"omitempty,oneof=gzip zstd"`
+	OversizePolicy             string `yaml:"oversizePolicy" json:"oversizePolicy" default:"truncate" validate:"omitempty,oneof=truncate drop"`
+	MaxReconnectBackoffSeconds int    `yaml:"maxReconnectBackoffSeconds" json:"maxReconnectBackoffSeconds" default:"30" validate:"min=1"`
+}
+
+// AgentNetworkConfig isolates the bot containers in internal networks which reach only the node
+// services, so that the bots cannot reach the internet or the other bots. The egress bots are
+// left out for the bots which need to reach the external APIs.
+type AgentNetworkConfig struct {
+	Isolate    bool     `yaml:"isolate" json:"isolate" default:"false"`
+	EgressBots []string `yaml:"egressBots" json:"egressBots"`
+}
+
+// IsIsolated tells if the bot container should not reach the internet.
+func (anc AgentNetworkConfig) IsIsolated(botID string) bool {
+	if !anc.Isolate {
+		return false
+	}
+	for _, egressBot := range anc.EgressBots {
+		if strings.EqualFold(egressBot, botID) {
+			return false
+		}
+	}
+	return true
+}
+
+// AgentSandboxConfig hardens the bot containers: the root filesystem is read-only, all capabilities
+// except the allowed ones are dropped, the privileges cannot be escalated and a seccomp profile limits
+// the system calls. The bots can write only to the tmpfs scratch directories, which are limited in size.
+// The overrides relax the sandbox for the bots which have legitimate needs.
+type AgentSandboxConfig struct {
+	Enable bool `yaml:"enable" json:"enable" default:"false"`
+	// the capabilities which the bots keep, like "NET_BIND_SERVICE"
+	Capabilities []string `yaml:"capabilities" json:"capabilities"`
+	// the seccomp profile JSON, relative to the forta dir - the default profile of docker if empty
+	SeccompProfile string `yaml:"seccompProfile" json:"seccompProfile"`
+	// the writable scratch directories - only /tmp if empty
+	TmpfsDirs    []string `yaml:"tmpfsDirs" json:"tmpfsDirs"`
+	TmpfsSizeMiB int      `yaml:"tmpfsSizeMib" json:"tmpfsSizeMib" default:"64" validate:"min=1"`
+
+	Overrides []AgentSandboxOverrideConfig `yaml:"overrides" json:"overrides" validate:"dive"`
+}
+
+// AgentSandboxOverrideConfig relaxes the sandbox of a bot.
+type AgentSandboxOverrideConfig struct {
+	BotID          string `yaml:"botId" json:"botId" validate:"required"`
+	WritableRootfs bool   `yaml:"writableRootfs" json:"writableRootfs"`
+	// added to the capabilities which all bots keep
+	Capabilities []string `yaml:"capabilities" json:"capabilities"`
+	// runs the bot without a seccomp profile
+	Unconfined bool `yaml:"unconfined" json:"unconfined"`
+	// added to the scratch directories of all bots
+	TmpfsDirs    []string `yaml:"tmpfsDirs" json:"tmpfsDirs"`
+	TmpfsSizeMiB int      `yaml:"tmpfsSizeMib" json:"tmpfsSizeMib" validate:"omitempty,min=1"`
+}
+
+// AgentStoreConfig enables the gRPC service next to the JSON-RPC proxy which offers each bot a small
+// key-value store in the forta dir, so that the stateful bots keep their state when their containers
+// are restarted. The bots cannot reach the keys of the other bots and the size of each store is limited.
+type AgentStoreConfig struct {
+	Enable bool `yaml:"enable" json:"enable" default:"false"`
+	// the requests per second of each bot
+	Rate    float64 `yaml:"rate" json:"rate" default:"50" validate:"gt=0"`
+	Burst   int     `yaml:"burst" json:"burst" default:"100" validate:"min=1"`
+	MaxKeys int     `yaml:"maxKeys" json:"maxKeys" default:"1000" validate:"min=1"`
+	// the keys are kept in the file names, so they cannot be longer than 120 bytes
+	MaxKeyBytes   int `yaml:"maxKeyBytes" json:"maxKeyBytes" default:"100" validate:"min=1,max=120"`
+	MaxValueBytes int `yaml:"maxValueBytes" json:"maxValueBytes" default:"65536" validate:"min=1"`
+	// the total size of the keys and the values of each bot
+	MaxBytes int `yaml:"maxBytes" json:"maxBytes" default:"1048576" validate:"min=1"`
+}
+
+// AgentEnvConfig contains the environment variables and the secrets which are injected into the
+// bot containers at the start, so that the bots can receive the API keys without having them in
+// the images.
+type AgentEnvConfig struct {
+	Vault VaultConfig    `yaml:"vault" json:"vault"`
+	Bots  []BotEnvConfig `yaml:"bots" json:"bots" validate:"dive"`
+}
+
+// BotEnvConfig contains the environment variables and the secrets of a bot.
+type BotEnvConfig struct {
+	BotID   string            `yaml:"botId" json:"botId" validate:"required"`
+	Env     map[string]string `yaml:"env" json:"env"`
+	Secrets []BotSecretConfig `yaml:"secrets" json:"secrets" validate:"dive"`
+}
+
+// BotSecretConfig is an environment variable of a bot which gets its value from a file in the forta
+// dir, an environment variable of the node or a Vault secret.
+type BotSecretConfig struct {
+	Name      string `yaml:"name" json:"name" validate:"required"`
+	File      string `yaml:"file" json:"file" validate:"required_without_all=FromEnv VaultPath"`
+	FromEnv   string `yaml:"fromEnv" json:"fromEnv"`
+	VaultPath string `yaml:"vaultPath" json:"vaultPath"`
+	VaultKey  string `yaml:"vaultKey" json:"vaultKey" validate:"required_with=VaultPath"`
+}
+
+// VaultConfig is the Vault server which the bot secrets are read from. The token file is relative
+// to the forta dir.
+type VaultConfig struct {
+	Address   string `yaml:"address" json:"address" validate:"omitempty,url"`
+	Token     string `yaml:"token" json:"token"`
+	TokenFile string `yaml:"tokenFile" json:"tokenFile"`
+}
+
+// AgentTLSConfig contains the mutual TLS settings of the bot gRPC connections.
+type AgentTLSConfig struct {
+	// allows plaintext connections to the bots, only in development mode
+	AllowInsecure bool `yaml:"allowInsecure" json:"allowInsecure"`
+}
+
 type StorageConfig struct {
 	Provide string `yaml:"provide" json:"provide" default:"https://ipfs-router.forta.network/provide"`
 	Reframe string `yaml:"reframe" json:"reframe" default:"https://ipfs-router.forta.network/reframe"`
@@ -197,6 +1112,9 @@ type CombinerConfig struct {
 	AlertAPIURL       string `yaml:"alertApiUrl" json:"alertApiUrl" default:"http://forta-public-api:8535" validate:"url"`
 	CombinerCachePath string `yaml:"alertCachePath" json:"alertCachePath"`
 	QueryInterval     uint64 `yaml:"queryInterval" json:"queryInterval"`
+
+	// feeds the alerts of the bots on this node to the subscribed combiner bots without waiting for the alert api
+	LocalAlerts bool `yaml:"localAlerts" json:"localAlerts"`
 }
 
 type AdvancedConfig struct {
@@ -220,6 +1138,9 @@ type Config struct {
 	Scan  ScannerConfig `yaml:"scan" json:"scan"`
 	Trace TraceConfig   `yaml:"trace" json:"trace"`
 
+	// additional chains to scan in the same node process
+	Chains []ChainConfig `yaml:"chains" json:"chains" validate:"dive"`
+
 	Registry         RegistryConfig       `yaml:"registry" json:"registry"`
 	Publish          PublisherConfig      `yaml:"publish" json:"publish"`
 	JsonRpcProxy     JsonRpcProxyConfig   `yaml:"jsonRpcProxy" json:"jsonRpcProxy"`
@@ -234,6 +1155,20 @@ type Config struct {
 	InspectionConfig InspectionConfig     `yaml:"inspection" json:"inspection"`
 	StorageConfig    StorageConfig        `yaml:"storage" json:"storage"`
 	CombinerConfig   CombinerConfig       `yaml:"combiner" json:"combiner"`
+	PrometheusConfig PrometheusConfig     `yaml:"prometheus" json:"prometheus"`
+	StatusAPI        StatusAPIConfig      `yaml:"statusApi" json:"statusApi"`
+	IngestAPI        IngestAPIConfig      `yaml:"ingestApi" json:"ingestApi"`
+	AdminAPI         AdminAPIConfig       `yaml:"adminApi" json:"adminApi"`
+	AlertQueryAPI    AlertQueryAPIConfig  `yaml:"alertQueryApi" json:"alertQueryApi"`
+	AlertFilter      AlertFilterConfig    `yaml:"alertFilter" json:"alertFilter"`
+	AgentTLS         AgentTLSConfig       `yaml:"agentTls" json:"agentTls"`
+	AgentGrpc        AgentGrpcConfig      `yaml:"agentGrpc" json:"agentGrpc"`
+	AgentEnv         AgentEnvConfig       `yaml:"agentEnv" json:"agentEnv"`
+	AgentNetwork     AgentNetworkConfig   `yaml:"agentNetwork" json:"agentNetwork"`
+	AgentSandbox     AgentSandboxConfig   `yaml:"agentSandbox" json:"agentSandbox"`
+	AgentStore       AgentStoreConfig     `yaml:"agentStore" json:"agentStore"`
+	Tracing          TracingConfig        `yaml:"tracing" json:"tracing"`
+	Chaos            ChaosConfig          `yaml:"chaos" json:"chaos"`
 	AdvancedConfig   AdvancedConfig       `yaml:"advanced" json:"advanced"`
 }
 
@@ -247,7 +1182,15 @@ func GetConfigForContainer() (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
+	if err := ApplyEnvOverrides(&cfg, os.Environ()); err != nil {
+		return Config{}, err
+	}
+	cfg.Development, _ = strconv.ParseBool(os.Getenv(EnvDevelopment))
 	applyContextDefaults(&cfg)
+	SetAgentGrpcPort(cfg.AgentGrpc.Port)
+	if err := applyReplayRange(&cfg); err != nil {
+		return Config{}, err
+	}
 
 	// initialize combiner cache dump path if cache is persistent
 	if cfg.CombinerConfig.CombinerCachePath != "" {
@@ -266,6 +1209,82 @@ func GetConfigForContainer() (Config, error) {
 	return cfg, nil
 }
 
+// AgentTLSEnabled tells if the bot gRPC connections should use mutual TLS. Plaintext
+// connections are allowed only if explicitly enabled in development mode.
+func (cfg *Config) AgentTLSEnabled() bool {
+	return !(cfg.Development && cfg.AgentTLS.AllowInsecure)
+}
+
+// ForChain returns the config to scan the additional chain with.
+func (cfg Config) ForChain(chain ChainConfig) Config {
+	cfg.ChainID = chain.ChainID
+	cfg.Scan.JsonRpc = chain.JsonRpc
+	cfg.Scan.Bots = chain.Bots
+	cfg.Scan.PendingTxs = PendingTxsConfig{}
+	cfg.Trace = chain.Trace
+	// the proxy sends the requests of the bots to the json-rpc api of their chain
+	cfg.JsonRpcProxy.JsonRpc = chain.JsonRpc
+	if chain.Finality != nil {
+		cfg.Scan.Finality = *chain.Finality
+	}
+	if chain.Sharding != nil {
+		cfg.Scan.Sharding = *chain.Sharding
+	}
+	cfg.Chains = nil
+	// the replay range is in the blocks of the main chain
+	cfg.LocalModeConfig.RuntimeLimits.StartBlock = nil
+	cfg.LocalModeConfig.RuntimeLimits.StopBlock = nil
+	return cfg
+}
+
+// BotScansChain tells if the bot should receive the events of the chain.
+func (cfg Config) BotScansChain(botID string, chainID uint64) bool {
+	bots := cfg.Scan.Bots
+	if chainID != uint64(cfg.ChainID) {
+		chain, ok := cfg.chain(chainID)
+		if !ok {
+			return false
+		}
+		bots = chain.Bots
+	}
+	if len(bots) == 0 {
+		return true
+	}
+	for _, bot := range bots {
+		if strings.EqualFold(bot, botID) {
+			return true
+		}
+	}
+	return false
+}
+
+// BotChainID returns the chain which the json-rpc requests of the bot go to by default. It is the
+// additional chain if the bot scans only that chain and the main chain otherwise.
+func (cfg Config) BotChainID(botID string) int {
+	if cfg.BotScansChain(botID, uint64(cfg.ChainID)) {
+		return cfg.ChainID
+	}
+	var chainIDs []int
+	for _, chain := range cfg.Chains {
+		if cfg.BotScansChain(botID, uint64(chain.ChainID)) {
+			chainIDs = append(chainIDs, chain.ChainID)
+		}
+	}
+	if len(chainIDs) == 1 {
+		return chainIDs[0]
+	}
+	return cfg.ChainID
+}
+
+func (cfg Config) chain(chainID uint64) (ChainConfig, bool) {
+	for _, chain := range cfg.Chains {
+		if uint64(chain.ChainID) == chainID {
+			return chain, true
+		}
+	}
+	return ChainConfig{}, false
+}
+
 // BotsToWait returns the count of the bots to wait.
 func (cfg *Config) BotsToWait() (waitBots int) {
 	if !cfg.LocalModeConfig.Enable {
@@ -273,6 +1292,8 @@ func (cfg *Config) BotsToWait() (waitBots int) {
 	}
 	waitBots += len(cfg.LocalModeConfig.BotImages)
 	waitBots += len(cfg.LocalModeConfig.Standalone.BotContainers)
+	waitBots += len(cfg.LocalModeConfig.AttachedBots)
+	waitBots += len(cfg.LocalModeConfig.HTTPBots)
 	// sharded bots spawn on multiple containers, so total "wait bot" count is shards * target
 	for _, bot := range cfg.LocalModeConfig.ShardedBots {
 		if bot != nil {
@@ -288,6 +1309,13 @@ func applyContextDefaults(cfg *Config) {
 	if chainSettings.EnableTrace && !cfg.LocalModeConfig.Enable {
 		cfg.Trace.Enabled = true
 	}
+	chainProfile := GetChainProfile(cfg.ChainID)
+	if cfg.Scan.Finality.Mode == FinalityModeConfirmations && cfg.Scan.Finality.Confirmations == 0 {
+		cfg.Scan.Finality.Confirmations = chainProfile.Confirmations
+	}
+	if cfg.Scan.BotTxBufferSize == 0 {
+		cfg.Scan.BotTxBufferSize = chainProfile.TxBufferSize
+	}
 	if cfg.ENSConfig.DefaultContract {
 		cfg.ENSConfig.ContractAddress = ""
 	}
@@ -296,6 +1324,55 @@ func applyContextDefaults(cfg *Config) {
 	cfg.CombinerConfig.CombinerCachePath = path.Join(cfg.FortaDir, DefaultCombinerCacheFileName)
 }
 
+// ReplayRangeEnv returns the env vars which pass the replay range to the node containers.
+func (cfg *Config) ReplayRangeEnv() map[string]string {
+	env := make(map[string]string)
+	runtimeLimits := cfg.LocalModeConfig.RuntimeLimits
+	if runtimeLimits.StartBlock != nil {
+		env[EnvReplayFrom] = strconv.FormatUint(*runtimeLimits.StartBlock, 10)
+	}
+	if runtimeLimits.StopBlock != nil {
+		env[EnvReplayTo] = strconv.FormatUint(*runtimeLimits.StopBlock, 10)
+	}
+	return env
+}
+
+// SecretEnv returns the env vars which the bot secrets are read from, so that the runner can pass
+// them to the supervisor which starts the bots.
+func (cfg *Config) SecretEnv() map[string]string {
+	env := make(map[string]string)
+	for _, botEnv := range cfg.AgentEnv.Bots {
+		for _, secret := range botEnv.Secrets {
+			if len(secret.FromEnv) == 0 {
+				continue
+			}
+			if value, ok := os.LookupEnv(secret.FromEnv); ok {
+				env[secret.FromEnv] = value
+			}
+		}
+	}
+	return env
+}
+
+// applyReplayRange overrides the local mode runtime limits with the replay range from the env vars.
+func applyReplayRange(cfg *Config) error {
+	for envVar, block := range map[string]**uint64{
+		EnvReplayFrom: &cfg.LocalModeConfig.RuntimeLimits.StartBlock,
+		EnvReplayTo:   &cfg.LocalModeConfig.RuntimeLimits.StopBlock,
+	} {
+		valueStr := os.Getenv(envVar)
+		if len(valueStr) == 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(valueStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid $%s value: %v", envVar, err)
+		}
+		*block = &value
+	}
+	return nil
+}
+
 func getConfigFromFile() (cfg Config, err error) {
 	var (
 		successfullyLoadedTimes int
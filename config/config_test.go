@@ -3,6 +3,7 @@ package config
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -27,3 +28,167 @@ func TestApplyContextDefaults(t *testing.T) {
 	r.Equal(path.Join(cfg.FortaDir, DefaultKeysDirName), cfg.KeyDirPath)
 	r.Equal(path.Join(cfg.FortaDir, DefaultCombinerCacheFileName), cfg.CombinerConfig.CombinerCachePath)
 }
+
+func TestBotRequestTimeout(t *testing.T) {
+	r := require.New(t)
+
+	scannerCfg := ScannerConfig{
+		BotRequestTimeoutSeconds: 30,
+		BotRequestTimeouts: map[string]int{
+			"0xAbCd": 60,
+		},
+	}
+
+	r.Equal(time.Minute, scannerCfg.BotRequestTimeout("0xabcd"))
+	r.Equal(30*time.Second, scannerCfg.BotRequestTimeout("0x1234"))
+}
+
+func TestBotConcurrencyFor(t *testing.T) {
+	r := require.New(t)
+
+	scannerCfg := ScannerConfig{
+		BotConcurrency: 1,
+		BotConcurrencyOverrides: map[string]int{
+			"0xAbCd": 4,
+		},
+	}
+
+	r.Equal(4, scannerCfg.BotConcurrencyFor("0xabcd"))
+	r.Equal(1, scannerCfg.BotConcurrencyFor("0x1234"))
+}
+
+func TestBotScansChain(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{
+		ChainID: 1,
+		Scan:    ScannerConfig{Bots: []string{"0xAbCd"}},
+		Chains: []ChainConfig{
+			{ChainID: 137, Bots: []string{"0x1234"}},
+			{ChainID: 56},
+		},
+	}
+
+	r.True(cfg.BotScansChain("0xabcd", 1))
+	r.False(cfg.BotScansChain("0x1234", 1))
+	r.True(cfg.BotScansChain("0x1234", 137))
+	r.False(cfg.BotScansChain("0xabcd", 137))
+	r.True(cfg.BotScansChain("0xabcd", 56))
+	r.False(cfg.BotScansChain("0xabcd", 10))
+
+	// the requests go to the main chain unless the bot scans only one additional chain
+	r.Equal(1, cfg.BotChainID("0xabcd"))
+	r.Equal(1, cfg.BotChainID("0x1234"))
+	cfg.Chains[1].Bots = []string{"0xabcd"}
+	r.Equal(137, cfg.BotChainID("0x1234"))
+}
+
+func TestForChain(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{
+		ChainID: 1,
+		Scan: ScannerConfig{
+			JsonRpc:        JsonRpcConfig{Url: "http://ethereum:8545"},
+			BlockRateLimit: 100,
+			PendingTxs:     PendingTxsConfig{Enable: true},
+		},
+		Chains: []ChainConfig{
+			{ChainID: 137, JsonRpc: JsonRpcConfig{Url: "http://polygon:8545"}, Bots: []string{"0x1234"}},
+			{ChainID: 10, Finality: &FinalityConfig{Mode: FinalityModeFinalized}, Sharding: &ShardingConfig{Shards: 2, ShardID: 1}},
+		},
+	}
+
+	chainCfg := cfg.ForChain(cfg.Chains[0])
+	r.Equal(137, chainCfg.ChainID)
+	r.Equal("http://polygon:8545", chainCfg.Scan.JsonRpc.Url)
+	r.Equal("http://polygon:8545", chainCfg.JsonRpcProxy.JsonRpc.Url)
+	r.Equal(100, chainCfg.Scan.BlockRateLimit)
+	r.Equal([]string{"0x1234"}, chainCfg.Scan.Bots)
+	r.False(chainCfg.Scan.PendingTxs.Enable)
+	r.Empty(chainCfg.Chains)
+	r.Equal("http://ethereum:8545", cfg.Scan.JsonRpc.Url)
+
+	// the chains can override the finality mode
+	r.Empty(chainCfg.Scan.Finality.Mode)
+	r.Equal(FinalityModeFinalized, cfg.ForChain(cfg.Chains[1]).Scan.Finality.Mode)
+
+	// and the sharding
+	r.Zero(chainCfg.Scan.Sharding.Shards)
+	r.Equal(uint(2), cfg.ForChain(cfg.Chains[1]).Scan.Sharding.Shards)
+}
+
+func TestApplyReplayRange(t *testing.T) {
+	r := require.New(t)
+
+	startBlock := uint64(1400000)
+	stopBlock := uint64(1410000)
+	hostCfg := &Config{}
+	hostCfg.LocalModeConfig.RuntimeLimits.StartBlock = &startBlock
+	hostCfg.LocalModeConfig.RuntimeLimits.StopBlock = &stopBlock
+
+	for envVar, value := range hostCfg.ReplayRangeEnv() {
+		t.Setenv(envVar, value)
+	}
+
+	cfg := &Config{}
+	r.NoError(applyReplayRange(cfg))
+	r.Equal(startBlock, *cfg.LocalModeConfig.RuntimeLimits.StartBlock)
+	r.Equal(stopBlock, *cfg.LocalModeConfig.RuntimeLimits.StopBlock)
+
+	t.Setenv(EnvReplayTo, "bad")
+	r.Error(applyReplayRange(cfg))
+}
+
+func TestApplyContextDefaults_ChainProfile(t *testing.T) {
+	r := require.New(t)
+
+	cfg := &Config{ChainID: 137}
+	cfg.Scan.Finality.Mode = FinalityModeConfirmations
+	applyContextDefaults(cfg)
+	r.Equal(uint64(128), cfg.Scan.Finality.Confirmations)
+	r.Equal(10000, cfg.Scan.BotTxBufferSize)
+
+	// the configured values are kept
+	cfg = &Config{ChainID: 137}
+	cfg.Scan.Finality.Mode = FinalityModeConfirmations
+	cfg.Scan.Finality.Confirmations = 5
+	cfg.Scan.BotTxBufferSize = 100
+	applyContextDefaults(cfg)
+	r.Equal(uint64(5), cfg.Scan.Finality.Confirmations)
+	r.Equal(100, cfg.Scan.BotTxBufferSize)
+
+	// unknown chains use the mainnet profile
+	r.Equal(12*time.Second, GetChainProfile(12345).BlockTime)
+	r.Equal(12345, GetChainProfile(12345).ChainID)
+	r.Equal(125*time.Millisecond, GetChainProfile(42161).BlockRateLimit(200*time.Millisecond))
+	r.Equal(200*time.Millisecond, GetChainProfile(1).BlockRateLimit(200*time.Millisecond))
+}
+
+func TestAgentNetworkConfig_IsIsolated(t *testing.T) {
+	r := require.New(t)
+
+	r.False(AgentNetworkConfig{EgressBots: []string{"0xbot1"}}.IsIsolated("0xbot2"))
+
+	networkCfg := AgentNetworkConfig{Isolate: true, EgressBots: []string{"0xBOT1"}}
+	r.False(networkCfg.IsIsolated("0xbot1"))
+	r.True(networkCfg.IsIsolated("0xbot2"))
+}
+
+func TestConfig_SecretEnv(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv("TEST_BOT_API_KEY", "secret")
+	var cfg Config
+	cfg.AgentEnv.Bots = []BotEnvConfig{
+		{
+			BotID: "0xbot1",
+			Secrets: []BotSecretConfig{
+				{Name: "API_KEY", FromEnv: "TEST_BOT_API_KEY"},
+				{Name: "OTHER_KEY", FromEnv: "TEST_BOT_MISSING_KEY"},
+				{Name: "FILE_KEY", File: "secrets/key"},
+			},
+		},
+	}
+	r.Equal(map[string]string{"TEST_BOT_API_KEY": "secret"}, cfg.SecretEnv())
+}
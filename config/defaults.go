@@ -3,6 +3,12 @@ package config
 const (
 	DefaultKeysDirName           = ".keys"
 	DefaultCombinerCacheFileName = ".combiner_cache.json"
+	DefaultDeadLettersDirName    = ".dead-letters"
+	DefaultAlertSinksDirName     = ".alert-sinks"
+	DefaultTxOverflowDirName     = ".tx-overflow"
+	DefaultAgentLogsDirName      = ".agent-logs"
+	DefaultCoverageDirName       = ".coverage"
+	DefaultAgentStoreDirName     = ".agent-store"
 	DefaultConfigFileName        = "config.yml"
 	DefaultWrappedConfigFileName = "wrapped-config.yml"
 	DefaultConfigWrapperKey      = "x-forta-config"
@@ -13,5 +19,7 @@ const (
 	DefaultStoragePort           = "8525"
 	DefaultPublicAPIProxyPort    = "8535"
 	DefaultJSONRPCProxyPort      = "8545"
+	DefaultHistoryAPIPort        = "8555"
+	DefaultAgentStoreAPIPort     = "8565"
 	DefaultFortaNodeBinaryPath   = "/forta-node" // the path for the common binary in the container image
 )
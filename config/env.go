@@ -4,6 +4,8 @@ const (
 	EnvHostFortaDir = "HOST_FORTA_DIR" // for retrieving forta dir path on the host os
 	EnvDevelopment  = "FORTA_DEVELOPMENT"
 	EnvReleaseInfo  = "FORTA_RELEASE_INFO"
+	EnvReplayFrom   = "FORTA_REPLAY_FROM" // for replaying a historical block range
+	EnvReplayTo     = "FORTA_REPLAY_TO"
 
 	// Agent env vars
 	EnvJsonRpcHost        = "JSON_RPC_HOST"
@@ -12,10 +14,17 @@ const (
 	EnvJWTProviderPort    = "FORTA_JWT_PROVIDER_PORT"
 	EnvPublicAPIProxyHost = "FORTA_PUBLIC_API_PROXY_HOST"
 	EnvPublicAPIProxyPort = "FORTA_PUBLIC_API_PROXY_PORT"
+	EnvHistoryAPIHost     = "FORTA_HISTORY_API_HOST"
+	EnvHistoryAPIPort     = "FORTA_HISTORY_API_PORT"
+	EnvAgentStoreAPIHost  = "FORTA_AGENT_STORE_API_HOST"
+	EnvAgentStoreAPIPort  = "FORTA_AGENT_STORE_API_PORT"
 	EnvAgentGrpcPort      = "AGENT_GRPC_PORT"
 	EnvFortaBotID         = "FORTA_BOT_ID"
 	EnvFortaBotOwner      = "FORTA_BOT_OWNER"
 	EnvFortaChainID       = "FORTA_CHAIN_ID"
+	EnvAgentTLSCertFile   = "FORTA_AGENT_TLS_CERT_FILE"
+	EnvAgentTLSKeyFile    = "FORTA_AGENT_TLS_KEY_FILE"
+	EnvAgentTLSCAFile     = "FORTA_AGENT_TLS_CA_FILE"
 )
 
 // EnvDefaults contain default values for one env.
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvConfigOverridePrefix is the prefix of the env vars which override the config file values. The rest
+// of the name is the upper-case YAML path joined with underscores, e.g. FORTA_CONFIG_SCAN_JSONRPC_URL
+// overrides scan.jsonRpc.url. The lists are comma-separated.
+const EnvConfigOverridePrefix = "FORTA_CONFIG_"
+
+// OverrideEnv returns the config override env vars from the given environment.
+func OverrideEnv(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], EnvConfigOverridePrefix) {
+			env[parts[0]] = parts[1]
+		}
+	}
+	return env
+}
+
+// ApplyEnvOverrides overrides the config values with the override env vars from the given environment.
+func ApplyEnvOverrides(cfg *Config, environ []string) error {
+	env := OverrideEnv(environ)
+	if len(env) == 0 {
+		return nil
+	}
+	if err := overrideStruct(reflect.ValueOf(cfg).Elem(), strings.TrimSuffix(EnvConfigOverridePrefix, "_"), env); err != nil {
+		return err
+	}
+	// the applied ones are deleted so the rest are unknown
+	if len(env) > 0 {
+		var unknown []string
+		for envVar := range env {
+			unknown = append(unknown, envVar)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown config override env vars: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func overrideStruct(v reflect.Value, prefix string, env map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if !field.IsExported() || len(name) == 0 || name == "-" {
+			continue
+		}
+		envVar := prefix + "_" + strings.ToUpper(name)
+		if err := overrideValue(v.Field(i), envVar, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func overrideValue(v reflect.Value, envVar string, env map[string]string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return overrideStruct(v, envVar, env)
+
+	case reflect.Ptr:
+		if !hasOverride(envVar, v.Type().Elem().Kind() == reflect.Struct, env) {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return overrideValue(v.Elem(), envVar, env)
+	}
+
+	valueStr, ok := env[envVar]
+	if !ok {
+		return nil
+	}
+	delete(env, envVar)
+	if err := setValue(v, valueStr); err != nil {
+		return fmt.Errorf("invalid $%s value: %v", envVar, err)
+	}
+	return nil
+}
+
+// hasOverride tells if there is an override for the env var or, for structs, any of the fields under it.
+func hasOverride(envVar string, isStruct bool, env map[string]string) bool {
+	if !isStruct {
+		_, ok := env[envVar]
+		return ok
+	}
+	for name := range env {
+		if strings.HasPrefix(name, envVar+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+func setValue(v reflect.Value, valueStr string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(valueStr)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(valueStr)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(valueStr, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(valueStr, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(valueStr, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(valueStr, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
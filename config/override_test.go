@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(ApplyEnvOverrides(&cfg, []string{
+		"OTHER_VAR=1",
+		"FORTA_CONFIG_CHAINID=137",
+		"FORTA_CONFIG_SCAN_JSONRPC_URL=http://localhost:8545",
+		"FORTA_CONFIG_SCAN_JSONRPC_FALLBACKURLS=http://localhost:8546, http://localhost:8547",
+		"FORTA_CONFIG_SCAN_FETCHRECEIPTS=true",
+		"FORTA_CONFIG_LOG_LEVEL=debug",
+		"FORTA_CONFIG_LOCALMODE_RUNTIMELIMITS_STARTBLOCK=100",
+	}))
+	r.Equal(137, cfg.ChainID)
+	r.Equal("http://localhost:8545", cfg.Scan.JsonRpc.Url)
+	r.Equal([]string{"http://localhost:8546", "http://localhost:8547"}, cfg.Scan.JsonRpc.FallbackUrls)
+	r.True(cfg.Scan.FetchReceipts)
+	r.Equal("debug", cfg.Log.Level)
+	r.NotNil(cfg.LocalModeConfig.RuntimeLimits.StartBlock)
+	r.Equal(uint64(100), *cfg.LocalModeConfig.RuntimeLimits.StartBlock)
+	r.Nil(cfg.LocalModeConfig.RuntimeLimits.StopBlock)
+
+	r.ErrorContains(ApplyEnvOverrides(&cfg, []string{"FORTA_CONFIG_CHAINID=foo"}), "FORTA_CONFIG_CHAINID")
+	r.ErrorContains(ApplyEnvOverrides(&cfg, []string{"FORTA_CONFIG_TYPO=1"}), "FORTA_CONFIG_TYPO")
+}
+
+func TestOverrideEnv(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(map[string]string{
+		"FORTA_CONFIG_LOG_LEVEL": "debug",
+	}, OverrideEnv([]string{"FORTA_CONFIG_LOG_LEVEL=debug", "FORTA_DIR=/tmp"}))
+}
@@ -1,14 +1,21 @@
 package config
 
+import "strings"
+
 // BotResourceLimits contain the agent resource limits data.
 type BotResourceLimits struct {
 	CPUQuota int64 // in microseconds
 	Memory   int64 // in bytes
 }
 
-// GetAgentResourceLimits calculates and returns the resource limits by
+// CPUs returns the CPU limit as the amount of CPUs.
+func (limits *BotResourceLimits) CPUs() float64 {
+	return float64(limits.CPUQuota) / float64(100000)
+}
+
+// GetAgentResourceLimits calculates and returns the resource limits of a bot by
 // taking the configuration into account. Zero values mean no limits.
-func GetAgentResourceLimits(resourcesCfg ResourcesConfig) *BotResourceLimits {
+func GetAgentResourceLimits(resourcesCfg ResourcesConfig, botID string) *BotResourceLimits {
 	var limits BotResourceLimits
 
 	if resourcesCfg.DisableAgentLimits {
@@ -25,6 +32,19 @@ func GetAgentResourceLimits(resourcesCfg ResourcesConfig) *BotResourceLimits {
 		limits.Memory = MiBToBytes(resourcesCfg.AgentMaxMemoryMiB)
 	}
 
+	for _, agentLimits := range resourcesCfg.AgentLimits {
+		if !strings.EqualFold(agentLimits.BotID, botID) {
+			continue
+		}
+		if agentLimits.MaxCPUs > 0 {
+			limits.CPUQuota = CPUsToMicroseconds(agentLimits.MaxCPUs)
+		}
+		if agentLimits.MaxMemoryMiB > 0 {
+			limits.Memory = MiBToBytes(agentLimits.MaxMemoryMiB)
+		}
+		break
+	}
+
 	return &limits
 }
 
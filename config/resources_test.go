@@ -9,7 +9,7 @@ import (
 func TestGetAgentResourceLimits(t *testing.T) {
 	r := require.New(t)
 
-	limits := GetAgentResourceLimits(ResourcesConfig{})
+	limits := GetAgentResourceLimits(ResourcesConfig{}, "0x1")
 	r.Equal(CPUsToMicroseconds(0.2), limits.CPUQuota)
 	r.Equal(MiBToBytes(10000), limits.Memory)
 }
@@ -20,7 +20,54 @@ func TestGetAgentResourceLimits_CustomValues(t *testing.T) {
 	limits := GetAgentResourceLimits(ResourcesConfig{
 		AgentMaxMemoryMiB: 12,
 		AgentMaxCPUs:      0.1,
-	})
+	}, "0x1")
 	r.Equal(CPUsToMicroseconds(0.1), limits.CPUQuota)
 	r.Equal(MiBToBytes(12), limits.Memory)
 }
+
+func TestGetAgentResourceLimits_AgentLimits(t *testing.T) {
+	r := require.New(t)
+
+	resourcesCfg := ResourcesConfig{
+		AgentMaxMemoryMiB: 200,
+		AgentLimits: []AgentResourcesConfig{
+			{
+				BotID:        "0xAbC",
+				MaxMemoryMiB: 1000,
+			},
+			{
+				BotID:   "0xdef",
+				MaxCPUs: 0.5,
+			},
+		},
+	}
+
+	limits := GetAgentResourceLimits(resourcesCfg, "0xabc")
+	r.Equal(CPUsToMicroseconds(0.2), limits.CPUQuota)
+	r.Equal(MiBToBytes(1000), limits.Memory)
+
+	limits = GetAgentResourceLimits(resourcesCfg, "0xdef")
+	r.Equal(CPUsToMicroseconds(0.5), limits.CPUQuota)
+	r.Equal(0.5, limits.CPUs())
+	r.Equal(MiBToBytes(200), limits.Memory)
+
+	limits = GetAgentResourceLimits(resourcesCfg, "0x123")
+	r.Equal(CPUsToMicroseconds(0.2), limits.CPUQuota)
+	r.Equal(MiBToBytes(200), limits.Memory)
+}
+
+func TestGetAgentResourceLimits_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	limits := GetAgentResourceLimits(ResourcesConfig{
+		DisableAgentLimits: true,
+		AgentLimits: []AgentResourcesConfig{
+			{
+				BotID:   "0x1",
+				MaxCPUs: 0.5,
+			},
+		},
+	}, "0x1")
+	r.Zero(limits.CPUQuota)
+	r.Zero(limits.Memory)
+}
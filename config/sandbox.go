@@ -0,0 +1,57 @@
+package config
+
+import "strings"
+
+// DefaultSandboxTmpfsDir is the scratch directory of the sandboxed bots if none is configured.
+const DefaultSandboxTmpfsDir = "/tmp"
+
+// BotSandbox is the sandbox of a bot container after the overrides.
+type BotSandbox struct {
+	ReadOnlyRootfs bool     `json:"readOnlyRootfs"`
+	Capabilities   []string `json:"capabilities,omitempty"`
+	// the path of the seccomp profile - the default profile of docker if empty
+	SeccompProfile string   `json:"seccompProfile,omitempty"`
+	Unconfined     bool     `json:"unconfined,omitempty"`
+	TmpfsDirs      []string `json:"tmpfsDirs"`
+	TmpfsSizeMiB   int      `json:"tmpfsSizeMib"`
+}
+
+// GetBotSandbox calculates and returns the sandbox of a bot by taking the overrides into account.
+// It returns nil if the sandbox is disabled.
+func GetBotSandbox(sandboxCfg AgentSandboxConfig, botID string) *BotSandbox {
+	if !sandboxCfg.Enable {
+		return nil
+	}
+
+	sandbox := &BotSandbox{
+		ReadOnlyRootfs: true,
+		Capabilities:   append([]string{}, sandboxCfg.Capabilities...),
+		SeccompProfile: sandboxCfg.SeccompProfile,
+		TmpfsDirs:      append([]string{}, sandboxCfg.TmpfsDirs...),
+		TmpfsSizeMiB:   sandboxCfg.TmpfsSizeMiB,
+	}
+	if len(sandbox.TmpfsDirs) == 0 {
+		sandbox.TmpfsDirs = []string{DefaultSandboxTmpfsDir}
+	}
+
+	for _, override := range sandboxCfg.Overrides {
+		if !strings.EqualFold(override.BotID, botID) {
+			continue
+		}
+		if override.WritableRootfs {
+			sandbox.ReadOnlyRootfs = false
+		}
+		if override.Unconfined {
+			sandbox.Unconfined = true
+			sandbox.SeccompProfile = ""
+		}
+		sandbox.Capabilities = append(sandbox.Capabilities, override.Capabilities...)
+		sandbox.TmpfsDirs = append(sandbox.TmpfsDirs, override.TmpfsDirs...)
+		if override.TmpfsSizeMiB > 0 {
+			sandbox.TmpfsSizeMiB = override.TmpfsSizeMiB
+		}
+		break
+	}
+
+	return sandbox
+}
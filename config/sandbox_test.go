@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBotSandbox(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(GetBotSandbox(AgentSandboxConfig{}, "0x1"))
+
+	sandbox := GetBotSandbox(AgentSandboxConfig{Enable: true, TmpfsSizeMiB: 64}, "0x1")
+	r.Equal(&BotSandbox{
+		ReadOnlyRootfs: true,
+		Capabilities:   []string{},
+		TmpfsDirs:      []string{DefaultSandboxTmpfsDir},
+		TmpfsSizeMiB:   64,
+	}, sandbox)
+}
+
+func TestGetBotSandbox_Overrides(t *testing.T) {
+	r := require.New(t)
+
+	sandboxCfg := AgentSandboxConfig{
+		Enable:         true,
+		Capabilities:   []string{"NET_BIND_SERVICE"},
+		SeccompProfile: "seccomp.json",
+		TmpfsDirs:      []string{"/tmp"},
+		TmpfsSizeMiB:   64,
+		Overrides: []AgentSandboxOverrideConfig{
+			{
+				BotID:          "0xAbC",
+				WritableRootfs: true,
+				Capabilities:   []string{"SYS_PTRACE"},
+				Unconfined:     true,
+				TmpfsDirs:      []string{"/cache"},
+				TmpfsSizeMiB:   256,
+			},
+		},
+	}
+
+	sandbox := GetBotSandbox(sandboxCfg, "0xabc")
+	r.False(sandbox.ReadOnlyRootfs)
+	r.True(sandbox.Unconfined)
+	r.Empty(sandbox.SeccompProfile)
+	r.Equal([]string{"NET_BIND_SERVICE", "SYS_PTRACE"}, sandbox.Capabilities)
+	r.Equal([]string{"/tmp", "/cache"}, sandbox.TmpfsDirs)
+	r.Equal(256, sandbox.TmpfsSizeMiB)
+
+	// the overrides of a bot do not change the others
+	sandbox = GetBotSandbox(sandboxCfg, "0xdef")
+	r.True(sandbox.ReadOnlyRootfs)
+	r.Equal("seccomp.json", sandbox.SeccompProfile)
+	r.Equal([]string{"NET_BIND_SERVICE"}, sandbox.Capabilities)
+	r.Equal([]string{"/tmp"}, sandbox.TmpfsDirs)
+	r.Equal(64, sandbox.TmpfsSizeMiB)
+}
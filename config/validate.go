@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationErrors contains a readable message per invalid config field.
+type ValidationErrors []string
+
+func (errs ValidationErrors) Error() string {
+	return fmt.Sprintf("invalid config: %s", strings.Join(errs, "; "))
+}
+
+// Validate validates the config and returns the invalid fields by their YAML paths.
+func (cfg *Config) Validate() error {
+	validate := validator.New()
+
+	// use the YAML names while validating the struct
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	var fieldErrs validator.ValidationErrors
+	if err := validate.Struct(cfg); err != nil && !errors.As(err, &fieldErrs) {
+		return err
+	}
+	var errs ValidationErrors
+	for _, fieldErr := range fieldErrs {
+		// trim the root struct name
+		field := strings.TrimPrefix(fieldErr.Namespace(), "Config.")
+		errs = append(errs, fmt.Sprintf("%s: %s", field, validationMessage(fieldErr)))
+	}
+	errs = append(errs, cfg.validateChains()...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateChains checks that each chain is scanned by a single pipeline.
+func (cfg *Config) validateChains() (errs ValidationErrors) {
+	seen := map[int]bool{cfg.ChainID: true}
+	for i, chain := range cfg.Chains {
+		if chain.ChainID == 0 {
+			continue
+		}
+		if seen[chain.ChainID] {
+			errs = append(errs, fmt.Sprintf("chains[%d].chainId: chain %d is already scanned", i, chain.ChainID))
+		}
+		seen[chain.ChainID] = true
+		if len(chain.JsonRpc.Url) == 0 {
+			errs = append(errs, fmt.Sprintf("chains[%d].jsonRpc.url: is required", i))
+		}
+	}
+	return
+}
+
+// validationMessage explains the failed validation without printing the value which can be a secret.
+func validationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "url":
+		return "must be a valid url"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", param)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", param)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "lt", "ltfield":
+		return fmt.Sprintf("must be less than %s", param)
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(param), ", "))
+	case "eth_addr":
+		return "must be a valid ethereum address"
+	default:
+		if len(param) > 0 {
+			return fmt.Sprintf("failed the '%s=%s' validation", fieldErr.Tag(), param)
+		}
+		return fmt.Sprintf("failed the '%s' validation", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	r.NoError(cfg.Validate())
+
+	cfg.Scan.JsonRpc.Url = "not a url"
+	cfg.Scan.BotConcurrency = 0
+	cfg.Scan.BotBackpressurePolicy = "unknown"
+
+	err := cfg.Validate()
+	r.Error(err)
+	validationErrs, ok := err.(ValidationErrors)
+	r.True(ok)
+	r.ElementsMatch(ValidationErrors{
+		"scan.jsonRpc.url: must be a valid url",
+		"scan.botConcurrency: must be at least 1",
+		"scan.botBackpressurePolicy: must be one of: block, drop-oldest, drop-newest",
+	}, validationErrs)
+}
+
+func TestValidate_FindingRules(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.AlertFilter.Rules = []FindingRule{
+		{Severities: []string{"LOW"}, Addresses: []string{"0x5b38Da6a701c568545dCfcB03FcB875f56beddC4"}},
+		{Severities: []string{"SEVERE"}, Action: FindingRuleActionTag},
+	}
+
+	err := cfg.Validate()
+	r.Error(err)
+	r.ElementsMatch(ValidationErrors{
+		"alertFilter.rules[1].severities[0]: must be one of: UNKNOWN, INFO, LOW, MEDIUM, HIGH, CRITICAL",
+		"alertFilter.rules[1].tags: is required",
+	}, err)
+}
+
+func TestValidate_Chains(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.Chains = []ChainConfig{
+		{ChainID: 137, JsonRpc: JsonRpcConfig{Url: "http://polygon:8545"}},
+		{ChainID: 1, JsonRpc: JsonRpcConfig{Url: "http://ethereum:8545"}},
+		{ChainID: 137},
+		{},
+	}
+
+	err := cfg.Validate()
+	r.Error(err)
+	r.ElementsMatch(ValidationErrors{
+		"chains[3].chainId: is required",
+		"chains[1].chainId: chain 1 is already scanned",
+		"chains[2].chainId: chain 137 is already scanned",
+		"chains[2].jsonRpc.url: is required",
+	}, err)
+}
+
+func TestValidate_Sinks(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	cfg.Publish.Sinks = []AlertSinkConfig{
+		{Name: "slack", Type: AlertSinkWebhook, URL: "https://hooks.slack.com/services/x", Severities: []string{"HIGH"}},
+		{Name: "custom", Type: AlertSinkWebhook, URL: "https://example.com", Webhook: WebhookSinkConfig{Format: WebhookFormatCustom}},
+		{Name: "kafka", Type: AlertSinkKafka, URL: "http://kafka:8082", Severities: []string{"SEVERE"}},
+	}
+	r.NoError(defaults.Set(&cfg))
+
+	err := cfg.Validate()
+	r.Error(err)
+	r.ElementsMatch(ValidationErrors{
+		"publish.sinks[1].webhook.template: is required",
+		"publish.sinks[2].topic: is required",
+		"publish.sinks[2].severities[0]: must be one of: UNKNOWN, INFO, LOW, MEDIUM, HIGH, CRITICAL",
+	}, err)
+}
+
+func TestValidate_Sharding(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.Scan.Sharding = ShardingConfig{Shards: 4, ShardID: 4, By: "address"}
+
+	err := cfg.Validate()
+	r.Error(err)
+	r.Contains(err, "scan.sharding.shardId: must be less than Shards")
+	r.Contains(err, "scan.sharding.by: must be one of: block, tx")
+
+	cfg.Scan.Sharding = ShardingConfig{Shards: 4, ShardID: 3, By: ShardByTx}
+	r.NoError(cfg.Validate())
+}
+
+func TestValidate_BotFilters(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.LocalModeConfig.BotFilters = map[string]*BotFilters{
+		"0xbot": {EventTypes: []string{"tx", "trace"}, Addresses: []string{"0xbad"}},
+		"0xok":  {EventTypes: []string{BotEventBlock}, Addresses: []string{"0xdAC17F958D2ee523a2206206994597C13D831ec7"}},
+	}
+
+	err := cfg.Validate()
+	r.Error(err)
+	r.ElementsMatch(ValidationErrors{
+		"localMode.botFilters[0xbot].eventTypes[1]: must be one of: block, tx, log, beacon, schedule",
+		"localMode.botFilters[0xbot].addresses[0]: must be a valid ethereum address",
+	}, err)
+}
@@ -0,0 +1,76 @@
+package nodeutils
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker opens after the max amount of consecutive failures and rejects
+// calls until the cool-down period is over. After the cool-down, only the next call
+// is allowed as a trial and the circuit closes again with the first success. The
+// other calls are rejected until the trial call succeeds or fails.
+type CircuitBreaker struct {
+	max       uint
+	cooldown  time.Duration
+	failures  uint
+	openUntil time.Time
+	open      bool
+	probing   bool
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a new circuit breaker.
+func NewCircuitBreaker(max uint, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		max:      max,
+		cooldown: cooldown,
+	}
+}
+
+// Allow tells if a call is allowed.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if !cb.open {
+		return true
+	}
+	if cb.probing || time.Now().Before(cb.openUntil) {
+		return false
+	}
+	cb.probing = true
+	return true
+}
+
+// Success resets the failures and tells if the circuit was closed with this call.
+func (cb *CircuitBreaker) Success() (closed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	closed = cb.open
+	cb.open = false
+	cb.probing = false
+	cb.failures = 0
+	return
+}
+
+// Failure saves a failure and tells if the circuit was opened with this call.
+func (cb *CircuitBreaker) Failure() (opened bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	if cb.max == 0 || cb.failures < cb.max {
+		return false
+	}
+	// restart the cool-down if the trial call failed
+	opened = !cb.open
+	cb.open = true
+	cb.probing = false
+	cb.openUntil = time.Now().Add(cb.cooldown)
+	return
+}
+
+// IsOpen tells if the circuit is open.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.open
+}
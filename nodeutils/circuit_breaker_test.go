@@ -0,0 +1,66 @@
+package nodeutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	r := require.New(t)
+
+	// open after two consecutive failures
+	cb := NewCircuitBreaker(2, time.Hour)
+
+	r.True(cb.Allow())
+	r.False(cb.Failure())
+	r.False(cb.Success()) // no failure - back to zero
+	r.False(cb.Failure())
+	r.True(cb.Failure()) // hit the limit - open the circuit
+	r.True(cb.IsOpen())
+	r.False(cb.Allow())
+
+	// should close with the first success after the cool-down
+	cb.openUntil = time.Now().Add(-time.Second)
+	r.True(cb.Allow())
+	r.True(cb.Success())
+	r.False(cb.IsOpen())
+	r.True(cb.Allow())
+}
+
+func TestCircuitBreaker_TrialFailure(t *testing.T) {
+	r := require.New(t)
+
+	cb := NewCircuitBreaker(1, time.Hour)
+	r.True(cb.Failure())
+
+	// failing the trial call should restart the cool-down without reopening
+	cb.openUntil = time.Now().Add(-time.Second)
+	r.True(cb.Allow())
+	r.False(cb.Failure())
+	r.False(cb.Allow())
+}
+
+func TestCircuitBreaker_SingleTrial(t *testing.T) {
+	r := require.New(t)
+
+	cb := NewCircuitBreaker(1, time.Hour)
+	r.True(cb.Failure())
+
+	// only one trial call is allowed after the cool-down
+	cb.openUntil = time.Now().Add(-time.Second)
+	r.True(cb.Allow())
+	r.False(cb.Allow())
+	r.False(cb.Allow())
+
+	// the next cool-down allows the next trial
+	r.False(cb.Failure())
+	r.False(cb.Allow())
+	cb.openUntil = time.Now().Add(-time.Second)
+	r.True(cb.Allow())
+	r.False(cb.Allow())
+	r.True(cb.Success())
+	r.True(cb.Allow())
+	r.True(cb.Allow())
+}
@@ -0,0 +1,248 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/forta-network/forta-node/services/components/reload"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Drainer drains the bot requests and saves the snapshots of the unfinished requests.
+type Drainer interface {
+	Drain() error
+	SaveSnapshot() error
+}
+
+// ConfigReloader reloads the config of the node.
+type ConfigReloader interface {
+	Reload() (*reload.Report, error)
+}
+
+// API lets the operators manage the running node. The clients are authenticated by the
+// permissions of the unix socket or by the client certificates on the TCP port.
+type API struct {
+	ctx context.Context
+	cfg APIConfig
+
+	socketServer *grpc.Server
+	tlsServer    *grpc.Server
+
+	lastRequest health.TimeTracker
+}
+
+// APIConfig contains the admin API configuration.
+type APIConfig struct {
+	SocketPath string
+	Port       string
+	// verifies the client certificates on the port
+	TLSConfig     *tls.Config
+	RequestSender botio.Sender
+	Drainer       Drainer
+	MsgClient     clients.MessageClient
+	// reloads the config - the config reload is not supported if nil
+	Reloader ConfigReloader
+}
+
+// NewAPI creates a new admin API.
+func NewAPI(ctx context.Context, cfg APIConfig) (*API, error) {
+	if len(cfg.SocketPath) == 0 && len(cfg.Port) == 0 {
+		return nil, errors.New("admin api socket path or port is required")
+	}
+	if len(cfg.Port) > 0 && cfg.TLSConfig == nil {
+		return nil, errors.New("admin api tls config is required with the port")
+	}
+	return &API{ctx: ctx, cfg: cfg}, nil
+}
+
+// ServerTLSConfig loads the server certificate and requires the client certificates which are
+// signed by the client CA.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the admin api key pair: %v", err)
+	}
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the admin api client CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("failed to parse the admin api client CA")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// Start starts the gRPC servers on the socket and the port.
+func (api *API) Start() error {
+	if len(api.cfg.SocketPath) > 0 {
+		// clean up the socket of the previous run
+		if err := os.Remove(api.cfg.SocketPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the admin api socket: %v", err)
+		}
+		lis, err := net.Listen("unix", api.cfg.SocketPath)
+		if err != nil {
+			return fmt.Errorf("failed to listen on the admin api socket: %v", err)
+		}
+		// only the owner can connect to the socket
+		if err := os.Chmod(api.cfg.SocketPath, 0600); err != nil {
+			lis.Close()
+			return fmt.Errorf("failed to set the admin api socket permissions: %v", err)
+		}
+		api.socketServer = api.serve(lis)
+	}
+	if len(api.cfg.Port) > 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", api.cfg.Port))
+		if err != nil {
+			return fmt.Errorf("failed to listen on the admin api port: %v", err)
+		}
+		api.tlsServer = api.serve(lis, grpc.Creds(credentials.NewTLS(api.cfg.TLSConfig)))
+	}
+	return nil
+}
+
+func (api *API) serve(lis net.Listener, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append(opts, grpc.UnaryInterceptor(api.logRequest))...)
+	RegisterAdminServer(server, api)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			log.WithError(err).Error("admin grpc server stopped")
+		}
+	}()
+	return server
+}
+
+// logRequest keeps a record of the operations in the logs.
+func (api *API) logRequest(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	lg := log.WithField("method", info.FullMethod)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		lg = lg.WithField("peer", p.Addr.String())
+	}
+	api.lastRequest.Set()
+	resp, err := handler(ctx, req)
+	if err != nil {
+		lg.WithError(err).Warn("admin request failed")
+		return nil, err
+	}
+	lg.Info("admin request")
+	return resp, nil
+}
+
+// Stop stops the servers.
+func (api *API) Stop() error {
+	if api.socketServer != nil {
+		api.socketServer.Stop()
+	}
+	if api.tlsServer != nil {
+		api.tlsServer.Stop()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *API) Name() string {
+	return "admin-api"
+}
+
+// Health implements the health.Reporter interface.
+func (api *API) Health() health.Reports {
+	return health.Reports{
+		api.lastRequest.GetReport("request.time"),
+	}
+}
+
+// AddBot lets the supervisor run the bot until the restart.
+func (api *API) AddBot(botConfig config.AgentConfig) error {
+	switch {
+	case len(botConfig.ID) == 0:
+		return status.Error(codes.InvalidArgument, "bot id is required")
+	case botConfig.IsHTTP() && len(botConfig.Endpoint) == 0:
+		return status.Error(codes.InvalidArgument, "endpoint is required for http bots")
+	case len(botConfig.Image) == 0 && len(botConfig.Address) == 0 && !botConfig.IsHTTP():
+		return status.Error(codes.InvalidArgument, "bot image, address or endpoint is required")
+	}
+	api.cfg.MsgClient.Publish(messaging.SubjectAdminBotsAdd, messaging.AgentPayload{botConfig})
+	return nil
+}
+
+// RemoveBot lets the supervisor stop the bot until the restart.
+func (api *API) RemoveBot(botID string) error {
+	if len(botID) == 0 {
+		return status.Error(codes.InvalidArgument, "bot id is required")
+	}
+	api.cfg.MsgClient.Publish(messaging.SubjectAdminBotsRemove, messaging.AgentPayload{{ID: botID}})
+	return nil
+}
+
+// SetLogLevel changes the log level of the scanner and lets the other containers know.
+func (api *API) SetLogLevel(level string) error {
+	payload := messaging.LogLevelPayload{Level: level}
+	if err := messaging.SetLogLevel(payload); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	api.cfg.MsgClient.Publish(messaging.SubjectAdminLogLevel, payload)
+	return nil
+}
+
+// PauseFeeds stops sending the new events to the bots.
+func (api *API) PauseFeeds() error {
+	api.cfg.RequestSender.PauseFeeds()
+	return nil
+}
+
+// ResumeFeeds continues sending the new events to the bots.
+func (api *API) ResumeFeeds() error {
+	api.cfg.RequestSender.ResumeFeeds()
+	return nil
+}
+
+// TriggerCheckpoint saves the snapshot of the unfinished bot requests, so that the blocks of the
+// requests are dispatched again if the node stops before saving the next snapshot.
+func (api *API) TriggerCheckpoint() error {
+	return api.cfg.Drainer.SaveSnapshot()
+}
+
+// Drain pauses the feeds and drains the bot requests before the shutdown. The bots do not
+// process the requests anymore until the node is restarted.
+func (api *API) Drain() error {
+	api.cfg.RequestSender.PauseFeeds()
+	if err := api.cfg.Drainer.Drain(); err != nil {
+		return err
+	}
+	log.Info("drained the node - ready to stop")
+	return nil
+}
+
+// ReloadConfig reloads the config and applies the changed settings which do not need a restart.
+func (api *API) ReloadConfig() (*reload.Report, error) {
+	if api.cfg.Reloader == nil {
+		return nil, status.Error(codes.Unimplemented, "config reload is not supported")
+	}
+	report, err := api.cfg.Reloader.Reload()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return report, nil
+}
@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/forta-network/forta-node/services/components/reload"
+	"github.com/forta-network/forta-node/services/components/security"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type testDrainer struct {
+	drained   bool
+	snapshots int
+	err       error
+}
+
+func (td *testDrainer) Drain() error {
+	td.drained = true
+	return td.err
+}
+
+func (td *testDrainer) SaveSnapshot() error {
+	td.snapshots++
+	return nil
+}
+
+type testReloader struct {
+	err error
+}
+
+func (tr *testReloader) Reload() (*reload.Report, error) {
+	if tr.err != nil {
+		return nil, tr.err
+	}
+	return &reload.Report{Applied: []string{"log.level"}, RequiresRestart: []string{"scan.jsonRpc.url"}}, nil
+}
+
+func TestAPI_Socket(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	sender := mock_botio.NewMockSender(ctrl)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	drainer := &testDrainer{}
+	reloader := &testReloader{}
+	socketPath := path.Join(t.TempDir(), "admin.sock")
+	api, err := NewAPI(context.Background(), APIConfig{
+		SocketPath:    socketPath,
+		RequestSender: sender,
+		Drainer:       drainer,
+		MsgClient:     msgClient,
+		Reloader:      reloader,
+	})
+	r.NoError(err)
+	r.NoError(api.Start())
+	defer api.Stop()
+
+	info, err := os.Stat(socketPath)
+	r.NoError(err)
+	r.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	conn, err := DialSocket(socketPath)
+	r.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	botConfig := config.AgentConfig{ID: "0x1", Image: "bot-image"}
+	msgClient.EXPECT().Publish(messaging.SubjectAdminBotsAdd, messaging.AgentPayload{botConfig})
+	r.NoError(client.AddBot(ctx, botConfig))
+	err = client.AddBot(ctx, config.AgentConfig{ID: "0x1"})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+
+	msgClient.EXPECT().Publish(messaging.SubjectAdminBotsRemove, messaging.AgentPayload{{ID: "0x1"}})
+	r.NoError(client.RemoveBot(ctx, "0x1"))
+
+	level := log.GetLevel()
+	defer log.SetLevel(level)
+	msgClient.EXPECT().Publish(messaging.SubjectAdminLogLevel, messaging.LogLevelPayload{Level: "debug"})
+	r.NoError(client.SetLogLevel(ctx, "debug"))
+	r.Equal(log.DebugLevel, log.GetLevel())
+	err = client.SetLogLevel(ctx, "loud")
+	r.Equal(codes.InvalidArgument, status.Code(err))
+
+	sender.EXPECT().PauseFeeds()
+	r.NoError(client.PauseFeeds(ctx))
+	sender.EXPECT().ResumeFeeds()
+	r.NoError(client.ResumeFeeds(ctx))
+
+	r.NoError(client.TriggerCheckpoint(ctx))
+	r.Equal(1, drainer.snapshots)
+
+	drainer.err = errors.New("timed out")
+	sender.EXPECT().PauseFeeds()
+	err = client.Drain(ctx)
+	r.Equal(codes.Internal, status.Code(err))
+	r.True(drainer.drained)
+
+	report, err := client.ReloadConfig(ctx)
+	r.NoError(err)
+	r.Equal([]string{"log.level"}, report.Applied)
+	r.Equal([]string{"scan.jsonRpc.url"}, report.RequiresRestart)
+	reloader.err = errors.New("invalid config")
+	_, err = client.ReloadConfig(ctx)
+	r.Equal(codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAPI_MutualTLS(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	ca, err := security.LoadOrCreateCA(dir)
+	r.NoError(err)
+	certPEM, keyPEM, err := ca.IssueCert("forta-node-admin", "localhost")
+	r.NoError(err)
+	certFile, keyFile := path.Join(dir, "admin.pem"), path.Join(dir, "admin-key.pem")
+	r.NoError(os.WriteFile(certFile, certPEM, 0600))
+	r.NoError(os.WriteFile(keyFile, keyPEM, 0600))
+	tlsConfig, err := ServerTLSConfig(certFile, keyFile, path.Join(dir, security.TLSCACertFileName))
+	r.NoError(err)
+
+	ctrl := gomock.NewController(t)
+	sender := mock_botio.NewMockSender(ctrl)
+	api, err := NewAPI(context.Background(), APIConfig{
+		Port:          "9113",
+		TLSConfig:     tlsConfig,
+		RequestSender: sender,
+		Drainer:       &testDrainer{},
+		MsgClient:     mock_clients.NewMockMessageClient(ctrl),
+	})
+	r.NoError(err)
+	r.NoError(api.Start())
+	defer api.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	// the client with the certificate can manage the node
+	clientTLSConfig, err := ca.ClientTLSConfig()
+	r.NoError(err)
+	conn, err := grpc.Dial("localhost:9113", grpc.WithTransportCredentials(credentials.NewTLS(clientTLSConfig)))
+	r.NoError(err)
+	defer conn.Close()
+	sender.EXPECT().PauseFeeds()
+	r.NoError(NewClient(conn).PauseFeeds(ctx))
+
+	// the client without the certificate can not
+	noCertConfig := &tls.Config{RootCAs: clientTLSConfig.RootCAs, MinVersion: tls.VersionTLS12}
+	noCertConn, err := grpc.Dial("localhost:9113", grpc.WithTransportCredentials(credentials.NewTLS(noCertConfig)))
+	r.NoError(err)
+	defer noCertConn.Close()
+	r.Error(NewClient(noCertConn).PauseFeeds(ctx))
+}
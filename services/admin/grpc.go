@@ -0,0 +1,215 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/reload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// AdminServiceName is the name of the gRPC service which the operators manage the running node with.
+const AdminServiceName = "network.forta.Admin"
+
+// Admin gRPC methods
+const (
+	MethodAddBot            = "/network.forta.Admin/AddBot"
+	MethodRemoveBot         = "/network.forta.Admin/RemoveBot"
+	MethodSetLogLevel       = "/network.forta.Admin/SetLogLevel"
+	MethodPauseFeeds        = "/network.forta.Admin/PauseFeeds"
+	MethodResumeFeeds       = "/network.forta.Admin/ResumeFeeds"
+	MethodTriggerCheckpoint = "/network.forta.Admin/TriggerCheckpoint"
+	MethodDrain             = "/network.forta.Admin/Drain"
+	MethodReloadConfig      = "/network.forta.Admin/ReloadConfig"
+)
+
+// AdminServer is the server side of the admin service.
+type AdminServer interface {
+	AddBot(botConfig config.AgentConfig) error
+	RemoveBot(botID string) error
+	SetLogLevel(level string) error
+	PauseFeeds() error
+	ResumeFeeds() error
+	TriggerCheckpoint() error
+	Drain() error
+	ReloadConfig() (*reload.Report, error)
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: AdminServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("AddBot", MethodAddBot, newString, func(srv AdminServer, in proto.Message) error {
+			var botConfig config.AgentConfig
+			if err := json.Unmarshal([]byte(in.(*wrapperspb.StringValue).GetValue()), &botConfig); err != nil {
+				return status.Error(codes.InvalidArgument, fmt.Sprintf("failed to decode the bot config: %v", err))
+			}
+			return srv.AddBot(botConfig)
+		}),
+		unaryMethod("RemoveBot", MethodRemoveBot, newString, func(srv AdminServer, in proto.Message) error {
+			return srv.RemoveBot(in.(*wrapperspb.StringValue).GetValue())
+		}),
+		unaryMethod("SetLogLevel", MethodSetLogLevel, newString, func(srv AdminServer, in proto.Message) error {
+			return srv.SetLogLevel(in.(*wrapperspb.StringValue).GetValue())
+		}),
+		unaryMethod("PauseFeeds", MethodPauseFeeds, newEmpty, func(srv AdminServer, in proto.Message) error {
+			return srv.PauseFeeds()
+		}),
+		unaryMethod("ResumeFeeds", MethodResumeFeeds, newEmpty, func(srv AdminServer, in proto.Message) error {
+			return srv.ResumeFeeds()
+		}),
+		unaryMethod("TriggerCheckpoint", MethodTriggerCheckpoint, newEmpty, func(srv AdminServer, in proto.Message) error {
+			return srv.TriggerCheckpoint()
+		}),
+		unaryMethod("Drain", MethodDrain, newEmpty, func(srv AdminServer, in proto.Message) error {
+			return srv.Drain()
+		}),
+		respondingMethod("ReloadConfig", MethodReloadConfig, newEmpty, func(srv AdminServer, in proto.Message) (proto.Message, error) {
+			report, err := srv.ReloadConfig()
+			if err != nil {
+				return nil, err
+			}
+			b, err := json.Marshal(report)
+			if err != nil {
+				return nil, err
+			}
+			return wrapperspb.String(string(b)), nil
+		}),
+	},
+}
+
+func newString() proto.Message {
+	return new(wrapperspb.StringValue)
+}
+
+func newEmpty() proto.Message {
+	return new(emptypb.Empty)
+}
+
+// unaryMethod creates the handler of a method which responds with an empty message.
+func unaryMethod(
+	name, method string, newIn func() proto.Message, call func(AdminServer, proto.Message) error,
+) grpc.MethodDesc {
+	return respondingMethod(name, method, newIn, func(srv AdminServer, in proto.Message) (proto.Message, error) {
+		if err := call(srv, in); err != nil {
+			return nil, err
+		}
+		return new(emptypb.Empty), nil
+	})
+}
+
+// respondingMethod creates the handler of a method. The errors which are not gRPC status errors
+// are internal errors.
+func respondingMethod(
+	name, method string, newIn func() proto.Message, call func(AdminServer, proto.Message) (proto.Message, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := newIn()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				out, err := call(srv.(AdminServer), req.(proto.Message))
+				if err != nil {
+					if _, ok := status.FromError(err); ok {
+						return nil, err
+					}
+					return nil, status.Error(codes.Internal, err.Error())
+				}
+				return out, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+		},
+	}
+}
+
+// RegisterAdminServer registers the admin service to the gRPC server.
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+// Client manages a node through the admin API.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient creates a new client with the connection.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
+
+// DialSocket connects to the admin API of a node on the unix socket.
+func DialSocket(socketPath string) (*grpc.ClientConn, error) {
+	return grpc.Dial("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// AddBot runs the bot on the node until the restart.
+func (client *Client) AddBot(ctx context.Context, botConfig config.AgentConfig) error {
+	b, err := json.Marshal(botConfig)
+	if err != nil {
+		return fmt.Errorf("failed to encode the bot config: %v", err)
+	}
+	return client.invoke(ctx, MethodAddBot, wrapperspb.String(string(b)))
+}
+
+// RemoveBot stops the bot on the node until the restart.
+func (client *Client) RemoveBot(ctx context.Context, botID string) error {
+	return client.invoke(ctx, MethodRemoveBot, wrapperspb.String(botID))
+}
+
+// SetLogLevel changes the log level of the node containers.
+func (client *Client) SetLogLevel(ctx context.Context, level string) error {
+	return client.invoke(ctx, MethodSetLogLevel, wrapperspb.String(level))
+}
+
+// PauseFeeds stops sending the new events to the bots.
+func (client *Client) PauseFeeds(ctx context.Context) error {
+	return client.invoke(ctx, MethodPauseFeeds, new(emptypb.Empty))
+}
+
+// ResumeFeeds continues sending the new events to the bots.
+func (client *Client) ResumeFeeds(ctx context.Context) error {
+	return client.invoke(ctx, MethodResumeFeeds, new(emptypb.Empty))
+}
+
+// TriggerCheckpoint saves the snapshot of the unfinished bot requests.
+func (client *Client) TriggerCheckpoint(ctx context.Context) error {
+	return client.invoke(ctx, MethodTriggerCheckpoint, new(emptypb.Empty))
+}
+
+// Drain pauses the feeds and waits for the bots to finish the requests, so that the node can be
+// stopped without losing the requests.
+func (client *Client) Drain(ctx context.Context) error {
+	return client.invoke(ctx, MethodDrain, new(emptypb.Empty))
+}
+
+// ReloadConfig reloads the config of the node and returns the applied settings and the changed
+// settings which need a restart.
+func (client *Client) ReloadConfig(ctx context.Context) (*reload.Report, error) {
+	var out wrapperspb.StringValue
+	if err := client.conn.Invoke(ctx, MethodReloadConfig, new(emptypb.Empty), &out); err != nil {
+		return nil, err
+	}
+	var report reload.Report
+	if err := json.Unmarshal([]byte(out.GetValue()), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode the reload report: %v", err)
+	}
+	return &report, nil
+}
+
+func (client *Client) invoke(ctx context.Context, method string, in proto.Message) error {
+	return client.conn.Invoke(ctx, method, in, new(emptypb.Empty))
+}
@@ -0,0 +1,259 @@
+package agentstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GetRequest asks for the value of a key.
+type GetRequest struct {
+	Key string `json:"key"`
+}
+
+// GetResponse contains the value, which is base64 in the JSON.
+type GetResponse struct {
+	Value []byte `json:"value"`
+}
+
+// PutRequest sets the value of a key. The value is base64 in the JSON.
+type PutRequest struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// PutResponse contains the usage of the store after the value is set.
+type PutResponse struct {
+	Usage *store.AgentStoreUsage `json:"usage"`
+}
+
+// DeleteRequest deletes a key.
+type DeleteRequest struct {
+	Key string `json:"key"`
+}
+
+// DeleteResponse is empty.
+type DeleteResponse struct{}
+
+// ListRequest asks for the keys of the store.
+type ListRequest struct {
+	// all keys if it is empty
+	Prefix string `json:"prefix"`
+}
+
+// ListResponse contains the sorted keys and the usage of the store.
+type ListResponse struct {
+	Keys  []string               `json:"keys"`
+	Usage *store.AgentStoreUsage `json:"usage"`
+}
+
+type botIDKey struct{}
+
+// API serves the key-value stores to the bots over gRPC. Each bot can reach only its own store,
+// which is found from the remote address of the bot.
+type API struct {
+	cfg        APIConfig
+	grpcServer *grpc.Server
+
+	rateLimiter ratelimiter.RateLimiter
+
+	requests  uint64
+	throttled uint64
+}
+
+// APIConfig contains the agent store API configuration.
+type APIConfig struct {
+	Port             string
+	Config           config.AgentStoreConfig
+	Store            store.AgentStore
+	BotAuthenticator clients.IPAuthenticator
+}
+
+// NewAPI creates a new agent store API.
+func NewAPI(cfg APIConfig) (*API, error) {
+	if len(cfg.Port) == 0 {
+		return nil, fmt.Errorf("agent store api port is required")
+	}
+	if cfg.Store == nil || cfg.BotAuthenticator == nil {
+		return nil, fmt.Errorf("agent store api store and bot authenticator are required")
+	}
+	return &API{
+		cfg:         cfg,
+		rateLimiter: ratelimiter.NewRateLimiter(cfg.Config.Rate, cfg.Config.Burst),
+	}, nil
+}
+
+// Limits returns the store limits from the config.
+func Limits(cfg config.AgentStoreConfig) store.AgentStoreLimits {
+	return store.AgentStoreLimits{
+		MaxKeys:       cfg.MaxKeys,
+		MaxKeyBytes:   cfg.MaxKeyBytes,
+		MaxValueBytes: cfg.MaxValueBytes,
+		MaxBytes:      cfg.MaxBytes,
+	}
+}
+
+// Start starts the gRPC server.
+func (api *API) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", api.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on the agent store api port: %v", err)
+	}
+	api.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(api.authorize))
+	RegisterAgentStoreServer(api.grpcServer, api)
+	go func() {
+		if err := api.grpcServer.Serve(lis); err != nil {
+			log.WithError(err).Error("agent store api server stopped")
+		}
+	}()
+	return nil
+}
+
+// Stop stops the server.
+func (api *API) Stop() error {
+	if api.grpcServer != nil {
+		api.grpcServer.Stop()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *API) Name() string {
+	return "agent-store-api"
+}
+
+// Health implements the health.Reporter interface.
+func (api *API) Health() health.Reports {
+	return health.Reports{
+		&health.Report{
+			Name:    "requests",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&api.requests), 10),
+		},
+		&health.Report{
+			Name:    "requests.throttled",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&api.throttled), 10),
+		},
+	}
+}
+
+// authorize finds the calling bot from the remote address and rate limits it.
+func (api *API) authorize(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unknown caller")
+	}
+	botConfig, err := api.cfg.BotAuthenticator.FindAgentFromRemoteAddr(p.Addr.String())
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "the caller is not a bot")
+	}
+	atomic.AddUint64(&api.requests, 1)
+	if api.rateLimiter.ExceedsLimit(botConfig.ID) {
+		atomic.AddUint64(&api.throttled, 1)
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return handler(context.WithValue(ctx, botIDKey{}, botConfig.ID), req)
+}
+
+// Get gets the value of the key from the store of the calling bot.
+func (api *API) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	botID, err := callerBotID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value, err := api.cfg.Store.Get(botID, req.Key)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetResponse{Value: value}, nil
+}
+
+// Put sets the value of the key in the store of the calling bot.
+func (api *API) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
+	botID, err := callerBotID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.cfg.Store.Put(botID, req.Key, req.Value); err != nil {
+		return nil, toStatus(err)
+	}
+	usage, err := api.cfg.Store.Usage(botID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &PutResponse{Usage: usage}, nil
+}
+
+// Delete deletes the key from the store of the calling bot.
+func (api *API) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	botID, err := callerBotID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.cfg.Store.Delete(botID, req.Key); err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+// List lists the keys in the store of the calling bot.
+func (api *API) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	botID, err := callerBotID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := api.cfg.Store.Keys(botID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	usage, err := api.cfg.Store.Usage(botID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &ListResponse{Keys: []string{}, Usage: usage}
+	for _, key := range keys {
+		if strings.HasPrefix(key, req.Prefix) {
+			resp.Keys = append(resp.Keys, key)
+		}
+	}
+	return resp, nil
+}
+
+func callerBotID(ctx context.Context) (string, error) {
+	botID, _ := ctx.Value(botIDKey{}).(string)
+	if len(botID) == 0 {
+		return "", status.Error(codes.PermissionDenied, "the caller is not a bot")
+	}
+	return botID, nil
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, store.ErrAgentKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, store.ErrInvalidAgentKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrAgentStoreLimitExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,125 @@
+package agentstore
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testBotID      = "0xbot1"
+	testOtherBotID = "0xbot2"
+)
+
+func testConfig() config.AgentStoreConfig {
+	return config.AgentStoreConfig{
+		Rate:          100,
+		Burst:         100,
+		MaxKeys:       2,
+		MaxKeyBytes:   10,
+		MaxValueBytes: 10,
+		MaxBytes:      100,
+	}
+}
+
+func TestAgentStoreGrpc(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+
+	cfg := testConfig()
+	agentStore := store.NewAgentStore(t.TempDir(), Limits(cfg))
+	api, err := NewAPI(APIConfig{
+		Port:             "0",
+		Config:           cfg,
+		Store:            agentStore,
+		BotAuthenticator: authenticator,
+	})
+	r.NoError(err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(api.authorize))
+	RegisterAgentStoreServer(grpcServer, api)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	r.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(&config.AgentConfig{ID: testBotID}, nil).Times(8)
+
+	_, err = client.Get(context.Background(), &GetRequest{Key: "state"})
+	r.Equal(codes.NotFound, status.Code(err))
+
+	putResp, err := client.Put(context.Background(), &PutRequest{Key: "state", Value: []byte{0, 1, 2}})
+	r.NoError(err)
+	r.Equal(&store.AgentStoreUsage{BotID: testBotID, Keys: 1, Bytes: 8}, putResp.Usage)
+	_, err = client.Put(context.Background(), &PutRequest{Key: "other", Value: []byte("1")})
+	r.NoError(err)
+
+	getResp, err := client.Get(context.Background(), &GetRequest{Key: "state"})
+	r.NoError(err)
+	r.Equal([]byte{0, 1, 2}, getResp.Value)
+
+	listResp, err := client.List(context.Background(), &ListRequest{Prefix: "st"})
+	r.NoError(err)
+	r.Equal([]string{"state"}, listResp.Keys)
+	r.Equal(2, listResp.Usage.Keys)
+
+	// the limits
+	_, err = client.Put(context.Background(), &PutRequest{Key: "third", Value: []byte("1")})
+	r.Equal(codes.ResourceExhausted, status.Code(err))
+	_, err = client.Put(context.Background(), &PutRequest{Value: []byte("1")})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Delete(context.Background(), &DeleteRequest{Key: "state"})
+	r.NoError(err)
+	keys, err := agentStore.Keys(testBotID)
+	r.NoError(err)
+	r.Equal([]string{"other"}, keys)
+
+	// the other bot does not see the keys
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(&config.AgentConfig{ID: testOtherBotID}, nil)
+	_, err = client.Get(context.Background(), &GetRequest{Key: "other"})
+	r.Equal(codes.NotFound, status.Code(err))
+
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(nil, errors.New("bot container not found"))
+	_, err = client.Get(context.Background(), &GetRequest{Key: "other"})
+	r.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func TestAgentStore_Caller(t *testing.T) {
+	r := require.New(t)
+
+	cfg := testConfig()
+	api, err := NewAPI(APIConfig{
+		Port:             "0",
+		Config:           cfg,
+		Store:            store.NewAgentStore(t.TempDir(), Limits(cfg)),
+		BotAuthenticator: mock_clients.NewMockIPAuthenticator(gomock.NewController(t)),
+	})
+	r.NoError(err)
+
+	_, err = api.List(context.Background(), &ListRequest{})
+	r.Equal(codes.PermissionDenied, status.Code(err))
+
+	listResp, err := api.List(context.WithValue(context.Background(), botIDKey{}, testBotID), &ListRequest{})
+	r.NoError(err)
+	r.Empty(listResp.Keys)
+	r.Equal(0, listResp.Usage.Keys)
+}
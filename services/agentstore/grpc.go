@@ -0,0 +1,144 @@
+package agentstore
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// AgentStoreServiceName is the name of the gRPC service which serves the key-value stores of the
+// bots. The requests and the responses are JSON in string values, so that the bots do not need the
+// generated code of the service.
+const AgentStoreServiceName = "network.forta.AgentStore"
+
+// Agent store gRPC methods
+const (
+	MethodGet    = "/network.forta.AgentStore/Get"
+	MethodPut    = "/network.forta.AgentStore/Put"
+	MethodDelete = "/network.forta.AgentStore/Delete"
+	MethodList   = "/network.forta.AgentStore/List"
+)
+
+// AgentStoreServer is the server side of the agent store service.
+type AgentStoreServer interface {
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+}
+
+var agentStoreServiceDesc = grpc.ServiceDesc{
+	ServiceName: AgentStoreServiceName,
+	HandlerType: (*AgentStoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("Get", MethodGet, AgentStoreServer.Get),
+		unaryMethod("Put", MethodPut, AgentStoreServer.Put),
+		unaryMethod("Delete", MethodDelete, AgentStoreServer.Delete),
+		unaryMethod("List", MethodList, AgentStoreServer.List),
+	},
+}
+
+// unaryMethod creates the handler of a method which decodes the request from and encodes the
+// response to JSON. The errors which are not gRPC status errors are internal errors.
+func unaryMethod[Req, Resp any](
+	name, method string, call func(AgentStoreServer, context.Context, *Req) (*Resp, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				var request Req
+				if err := json.Unmarshal([]byte(req.(*wrapperspb.StringValue).GetValue()), &request); err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "failed to decode the request: %v", err)
+				}
+				resp, err := call(srv.(AgentStoreServer), ctx, &request)
+				if err != nil {
+					if _, ok := status.FromError(err); ok {
+						return nil, err
+					}
+					return nil, status.Error(codes.Internal, err.Error())
+				}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to encode the response: %v", err)
+				}
+				return wrapperspb.String(string(b)), nil
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+		},
+	}
+}
+
+// RegisterAgentStoreServer registers the agent store service to the gRPC server.
+func RegisterAgentStoreServer(s *grpc.Server, srv AgentStoreServer) {
+	s.RegisterService(&agentStoreServiceDesc, srv)
+}
+
+// Client reads and writes the store of the calling bot through the agent store API.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient creates a new client with the connection.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
+
+// Get returns the value of a key.
+func (client *Client) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	resp := new(GetResponse)
+	if err := client.invoke(ctx, MethodGet, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Put sets the value of a key.
+func (client *Client) Put(ctx context.Context, req *PutRequest) (*PutResponse, error) {
+	resp := new(PutResponse)
+	if err := client.invoke(ctx, MethodPut, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Delete deletes a key.
+func (client *Client) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	resp := new(DeleteResponse)
+	if err := client.invoke(ctx, MethodDelete, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// List returns the keys and the usage of the store.
+func (client *Client) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	resp := new(ListResponse)
+	if err := client.invoke(ctx, MethodList, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (client *Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	out := new(wrapperspb.StringValue)
+	if err := client.conn.Invoke(ctx, method, wrapperspb.String(string(b)), out); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(out.GetValue()), resp)
+}
@@ -0,0 +1,268 @@
+package alertquery
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/services/publisher/merkle"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// AlertQueryAPI serves the alerts from the local store over HTTP, so that the dashboards and the
+// investigations can query the alerts of the node without an indexer.
+type AlertQueryAPI struct {
+	ctx    context.Context
+	cfg    AlertQueryAPIConfig
+	server *http.Server
+}
+
+// AlertQueryAPIConfig contains the alert query API configuration.
+type AlertQueryAPIConfig struct {
+	Port string
+	// the bearer token which the clients should send
+	Token string
+	Store store.AlertStore
+	// limits the amount of the alerts in a response
+	MaxResults int
+	// serves the inclusion proofs of the alerts in the published batches if it is set
+	Proofs store.BatchProofStore
+}
+
+// AlertsResponse is the response of the alerts endpoint.
+type AlertsResponse struct {
+	Alerts []*store.StoredAlert `json:"alerts"`
+	Count  int                  `json:"count"`
+}
+
+// ProofResponse is the response of the proof endpoint. The receipt is signed by the scanner and
+// includes the root of the proof.
+type ProofResponse struct {
+	Proof     *merkle.Proof       `json:"proof"`
+	Batch     string              `json:"batch"`
+	Receipt   string              `json:"receipt"`
+	Signature *protocol.Signature `json:"signature"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewAlertQueryAPI creates a new alert query API.
+func NewAlertQueryAPI(ctx context.Context, cfg AlertQueryAPIConfig) (*AlertQueryAPI, error) {
+	if len(cfg.Port) == 0 {
+		return nil, fmt.Errorf("alert query api port is required")
+	}
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("alert query api store is required")
+	}
+	if len(cfg.Token) == 0 {
+		return nil, fmt.Errorf("alert query api token is required")
+	}
+	return &AlertQueryAPI{
+		ctx: ctx,
+		cfg: cfg,
+	}, nil
+}
+
+// Start starts the alert query server.
+func (api *AlertQueryAPI) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/alerts", api.handleAlerts)
+	if api.cfg.Proofs != nil {
+		mux.HandleFunc("/proof", api.handleProof)
+	}
+	api.server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", api.cfg.Port),
+		Handler: mux,
+	}
+	utils.GoListenAndServe(api.server)
+	return nil
+}
+
+// Stop stops the alert query server.
+func (api *AlertQueryAPI) Stop() error {
+	if api.server != nil {
+		return api.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *AlertQueryAPI) Name() string {
+	return "alert-query-api"
+}
+
+func (api *AlertQueryAPI) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if !api.checkRequest(w, r) {
+		return
+	}
+	query, err := ParseQuery(r.URL.Query(), api.cfg.MaxResults)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &errorResponse{Error: err.Error()})
+		return
+	}
+	alerts, err := api.cfg.Store.QueryAlerts(*query)
+	if err != nil {
+		log.WithError(err).Error("failed to query alerts")
+		writeJSON(w, http.StatusInternalServerError, &errorResponse{Error: "failed to query alerts"})
+		return
+	}
+	if alerts == nil {
+		alerts = []*store.StoredAlert{}
+	}
+	writeJSON(w, http.StatusOK, &AlertsResponse{Alerts: alerts, Count: len(alerts)})
+}
+
+func (api *AlertQueryAPI) handleProof(w http.ResponseWriter, r *http.Request) {
+	if !api.checkRequest(w, r) {
+		return
+	}
+	alertID := r.URL.Query().Get("alertId")
+	if len(alertID) == 0 {
+		writeJSON(w, http.StatusBadRequest, &errorResponse{Error: "alertId is required"})
+		return
+	}
+	batchProof, ok, err := api.cfg.Proofs.GetBatchProof(alertID)
+	if err != nil {
+		log.WithError(err).Error("failed to get batch proof")
+		writeJSON(w, http.StatusInternalServerError, &errorResponse{Error: "failed to get batch proof"})
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusNotFound, &errorResponse{Error: "no published batch includes the alert"})
+		return
+	}
+	proof, err := merkle.NewTree(batchProof.AlertIDs).Proof(alertID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, &errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, &ProofResponse{
+		Proof:     proof,
+		Batch:     batchProof.Batch,
+		Receipt:   batchProof.Receipt,
+		Signature: batchProof.Signature,
+	})
+}
+
+// checkRequest checks the method and the token of the request and writes the error response.
+func (api *AlertQueryAPI) checkRequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, &errorResponse{Error: "only GET is allowed"})
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(api.cfg.Token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(api.cfg.Token)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, &errorResponse{Error: "unauthorized"})
+		return false
+	}
+	return true
+}
+
+// ParseQuery makes the alert query from the URL parameters. The bots and the severities can be repeated
+// or comma separated. The times are RFC3339 or unix seconds and the block numbers are decimal or hex.
+// The limit is capped at the max results.
+func ParseQuery(values url.Values, maxResults int) (*store.AlertQuery, error) {
+	query := &store.AlertQuery{
+		BotIDs:      splitValues(values["bot"]),
+		Severities:  splitValues(values["severity"]),
+		MinSeverity: strings.ToUpper(values.Get("minSeverity")),
+		Address:     values.Get("address"),
+		Limit:       maxResults,
+	}
+	for _, severity := range append(query.Severities, query.MinSeverity) {
+		if _, ok := protocol.Finding_Severity_value[strings.ToUpper(severity)]; len(severity) > 0 && !ok {
+			return nil, fmt.Errorf("invalid severity: %s", severity)
+		}
+	}
+	var err error
+	if query.ChainID, err = parseUint(values, "chainId"); err != nil {
+		return nil, err
+	}
+	if query.FromBlock, err = parseUint(values, "fromBlock"); err != nil {
+		return nil, err
+	}
+	if query.ToBlock, err = parseUint(values, "toBlock"); err != nil {
+		return nil, err
+	}
+	if query.From, err = parseTime(values, "from"); err != nil {
+		return nil, err
+	}
+	if query.To, err = parseTime(values, "to"); err != nil {
+		return nil, err
+	}
+	if limitStr := values.Get("limit"); len(limitStr) > 0 {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit: %s", limitStr)
+		}
+		if maxResults == 0 || limit < maxResults {
+			query.Limit = limit
+		}
+	}
+	return query, nil
+}
+
+func splitValues(values []string) (split []string) {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); len(part) > 0 {
+				split = append(split, part)
+			}
+		}
+	}
+	return
+}
+
+func parseUint(values url.Values, name string) (uint64, error) {
+	value := values.Get(name)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	if strings.HasPrefix(value, "0x") {
+		n, err := hexutil.DecodeUint64(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %s", name, value)
+		}
+		return n, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
+}
+
+func parseTime(values url.Values, name string) (time.Time, error) {
+	value := values.Get(name)
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return t, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Warn("failed to write alert query api response")
+	}
+}
@@ -0,0 +1,131 @@
+package alertquery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/publisher/merkle"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "test-token"
+
+func testRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	return req
+}
+
+func TestParseQuery(t *testing.T) {
+	r := require.New(t)
+
+	values, err := url.ParseQuery("bot=0x1,0x2&bot=0x3&severity=high&minSeverity=low&address=0xabc&chainId=137" +
+		"&fromBlock=0x10&toBlock=20&from=1700000000&to=2023-11-15T00:00:00Z&limit=50")
+	r.NoError(err)
+	query, err := ParseQuery(values, 100)
+	r.NoError(err)
+	r.Equal([]string{"0x1", "0x2", "0x3"}, query.BotIDs)
+	r.Equal([]string{"high"}, query.Severities)
+	r.Equal("LOW", query.MinSeverity)
+	r.Equal("0xabc", query.Address)
+	r.Equal(uint64(137), query.ChainID)
+	r.Equal(uint64(16), query.FromBlock)
+	r.Equal(uint64(20), query.ToBlock)
+	r.Equal(int64(1700000000), query.From.Unix())
+	r.Equal(time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC), query.To)
+	r.Equal(50, query.Limit)
+
+	query, err = ParseQuery(url.Values{"limit": {"500"}}, 100)
+	r.NoError(err)
+	r.Equal(100, query.Limit)
+
+	for _, invalid := range []string{"severity=bad", "chainId=x", "from=yesterday", "limit=0", "toBlock=0xzz"} {
+		values, _ := url.ParseQuery(invalid)
+		_, err := ParseQuery(values, 100)
+		r.Error(err, invalid)
+	}
+}
+
+func TestAlertQueryAPI_Alerts(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	now := time.Now().UTC()
+	r.NoError(localStore.PutAlert(&store.StoredAlert{ID: "alert1", BotID: "0x1", Severity: "LOW", Timestamp: now, Alert: json.RawMessage(`{}`)}))
+	r.NoError(localStore.PutAlert(&store.StoredAlert{ID: "alert2", BotID: "0x2", Severity: "HIGH", Timestamp: now, Alert: json.RawMessage(`{}`)}))
+
+	_, err = NewAlertQueryAPI(context.Background(), AlertQueryAPIConfig{Port: "9111", Store: localStore})
+	r.Error(err)
+	api, err := NewAlertQueryAPI(context.Background(), AlertQueryAPIConfig{Port: "9111", Token: testToken, Store: localStore, MaxResults: 10})
+	r.NoError(err)
+
+	rec := httptest.NewRecorder()
+	api.handleAlerts(rec, testRequest(http.MethodGet, "/alerts?bot=0x2", nil))
+	r.Equal(http.StatusOK, rec.Code)
+	var resp AlertsResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.Equal(1, resp.Count)
+	r.Equal("alert2", resp.Alerts[0].ID)
+
+	rec = httptest.NewRecorder()
+	api.handleAlerts(rec, testRequest(http.MethodGet, "/alerts?limit=-1", nil))
+	r.Equal(http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	api.handleAlerts(rec, testRequest(http.MethodPost, "/alerts", nil))
+	r.Equal(http.StatusMethodNotAllowed, rec.Code)
+
+	// no token
+	rec = httptest.NewRecorder()
+	api.handleAlerts(rec, httptest.NewRequest(http.MethodGet, "/alerts?bot=0x2", nil))
+	r.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func TestAlertQueryAPI_Proof(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	alertIDs := []string{"alert1", "alert2", "alert3"}
+	r.NoError(localStore.PutBatchProof(&store.BatchProof{
+		Batch:     "batch1",
+		Receipt:   `{"root": "0x1"}`,
+		Signature: &protocol.Signature{Signature: "0xsig", Signer: "0xscanner"},
+		AlertIDs:  alertIDs,
+		Timestamp: time.Now(),
+	}))
+
+	api, err := NewAlertQueryAPI(context.Background(), AlertQueryAPIConfig{Port: "9111", Token: testToken, Store: localStore, Proofs: localStore})
+	r.NoError(err)
+
+	rec := httptest.NewRecorder()
+	api.handleProof(rec, testRequest(http.MethodGet, "/proof?alertId=alert2", nil))
+	r.Equal(http.StatusOK, rec.Code)
+	var resp ProofResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.Equal("batch1", resp.Batch)
+	r.Equal("0xscanner", resp.Signature.Signer)
+	r.Equal(merkle.NewTree(alertIDs).Root().Hex(), resp.Proof.Root)
+	r.NoError(merkle.Verify(resp.Proof))
+
+	rec = httptest.NewRecorder()
+	api.handleProof(rec, testRequest(http.MethodGet, "/proof?alertId=alert4", nil))
+	r.Equal(http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	api.handleProof(rec, testRequest(http.MethodGet, "/proof", nil))
+	r.Equal(http.StatusBadRequest, rec.Code)
+}
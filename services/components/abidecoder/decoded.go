@@ -0,0 +1,146 @@
+package abidecoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Decoded is the decoded function call and logs of a transaction.
+type Decoded struct {
+	Call *DecodedCall  `json:"call,omitempty"`
+	Logs []*DecodedLog `json:"logs,omitempty"`
+}
+
+// DecodedCall is a decoded function call.
+type DecodedCall struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Args      []*Arg `json:"args"`
+}
+
+// DecodedLog is a decoded log.
+type DecodedLog struct {
+	Address   string `json:"address"`
+	LogIndex  string `json:"logIndex"`
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Args      []*Arg `json:"args"`
+}
+
+// Arg is a decoded argument. The integers are decimal strings, and the addresses, the hashes and
+// the bytes are hex strings.
+type Arg struct {
+	Name    string      `json:"name,omitempty"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// Attach adds the decoded transaction to the message as an unknown field, so that the message is
+// still valid for the bots which do not know about it.
+func Attach(msg *protocol.TransactionEvent, decoded *Decoded) error {
+	b, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode the decoded transaction: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.DecodedFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// FromMessage reads the decoded transaction from the message. It returns nil if the message
+// does not have it.
+func FromMessage(msg *protocol.TransactionEvent) (*Decoded, error) {
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == agentgrpc.DecodedFieldNumber && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			var decoded Decoded
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				return nil, fmt.Errorf("failed to decode the decoded transaction: %v", err)
+			}
+			return &decoded, nil
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, nil
+}
+
+func formatArgs(inputs abi.Arguments, values []interface{}) []*Arg {
+	args := make([]*Arg, len(inputs))
+	for i, input := range inputs {
+		args[i] = &Arg{Name: input.Name, Type: input.Type.String(), Value: formatValue(values[i])}
+	}
+	return args
+}
+
+// formatValue converts the unpacked value to a value which is readable as JSON.
+func formatValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return strings.ToLower(v.Hex())
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case string, bool:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(value)
+	case reflect.Array:
+		// the fixed size bytes
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			values[i] = formatValue(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Struct:
+		// the tuples
+		fields := make(map[string]interface{})
+		for i := 0; i < rv.NumField(); i++ {
+			fields[rv.Type().Field(i).Name] = formatValue(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return fmt.Sprint(value)
+}
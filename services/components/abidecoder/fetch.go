@@ -0,0 +1,149 @@
+package abidecoder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const fetchTimeout = 30 * time.Second
+
+// ErrNotVerified is returned when the source does not have the ABI of the contract.
+var ErrNotVerified = errors.New("contract is not verified")
+
+// Fetcher fetches the ABIs of the verified contracts.
+type Fetcher interface {
+	Name() string
+	FetchABI(ctx context.Context, address common.Address) (*abi.ABI, error)
+}
+
+type etherscanFetcher struct {
+	apiURL string
+	apiKey string
+	client *http.Client
+}
+
+// NewEtherscanFetcher creates a new fetcher which uses an Etherscan compatible API.
+func NewEtherscanFetcher(apiURL, apiKey string) Fetcher {
+	return &etherscanFetcher{
+		apiURL: apiURL,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+func (ef *etherscanFetcher) Name() string {
+	return "etherscan"
+}
+
+type etherscanResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+func (ef *etherscanFetcher) FetchABI(ctx context.Context, address common.Address) (*abi.ABI, error) {
+	query := url.Values{}
+	query.Set("module", "contract")
+	query.Set("action", "getabi")
+	query.Set("address", address.Hex())
+	if len(ef.apiKey) > 0 {
+		query.Set("apikey", ef.apiKey)
+	}
+	b, err := get(ctx, ef.client, ef.apiURL+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var resp etherscanResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode etherscan response: %v", err)
+	}
+	if resp.Status != "1" {
+		if strings.Contains(strings.ToLower(resp.Result), "not verified") {
+			return nil, ErrNotVerified
+		}
+		return nil, fmt.Errorf("etherscan responded with an error: %s: %s", resp.Message, resp.Result)
+	}
+	contractABI, err := abi.JSON(strings.NewReader(resp.Result))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse etherscan abi: %v", err)
+	}
+	return &contractABI, nil
+}
+
+type sourcifyFetcher struct {
+	repoURL string
+	chainID int
+	client  *http.Client
+}
+
+// NewSourcifyFetcher creates a new fetcher which uses the Sourcify repository.
+func NewSourcifyFetcher(repoURL string, chainID int) Fetcher {
+	return &sourcifyFetcher{
+		repoURL: strings.TrimSuffix(repoURL, "/"),
+		chainID: chainID,
+		client:  &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+func (sf *sourcifyFetcher) Name() string {
+	return "sourcify"
+}
+
+type sourcifyMetadata struct {
+	Output struct {
+		ABI json.RawMessage `json:"abi"`
+	} `json:"output"`
+}
+
+func (sf *sourcifyFetcher) FetchABI(ctx context.Context, address common.Address) (*abi.ABI, error) {
+	// the partial matches have the same ABI but a different metadata hash
+	for _, match := range []string{"full_match", "partial_match"} {
+		b, err := get(ctx, sf.client, fmt.Sprintf("%s/contracts/%s/%d/%s/metadata.json", sf.repoURL, match, sf.chainID, address.Hex()))
+		if errors.Is(err, ErrNotVerified) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var metadata sourcifyMetadata
+		if err := json.Unmarshal(b, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode sourcify metadata: %v", err)
+		}
+		contractABI, err := abi.JSON(bytes.NewReader(metadata.Output.ABI))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sourcify abi: %v", err)
+		}
+		return &contractABI, nil
+	}
+	return nil, ErrNotVerified
+}
+
+func get(ctx context.Context, client *http.Client, reqURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotVerified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+}
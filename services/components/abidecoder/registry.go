@@ -0,0 +1,425 @@
+package abidecoder
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	fetchQueueSize = 1000
+	// keeps the fetch rate under the rate limits of the public APIs
+	fetchInterval = 250 * time.Millisecond
+)
+
+// eventEntry is an event which is known from a signature or an ABI. The indexed arguments of the
+// events from the signatures are unknown, so they are assumed to be the first arguments.
+type eventEntry struct {
+	event        abi.Event
+	guessIndexed bool
+}
+
+// Registry knows the ABIs of the contracts and the common function and event signatures, and
+// decodes the function calls and the logs of the transactions with them. The ABIs of the unknown
+// contracts are fetched in the background if any fetchers are configured, so the first
+// transactions of a contract are decoded only with the signatures.
+type Registry struct {
+	ctx      context.Context
+	fetchers []Fetcher
+
+	methods   map[[4]byte][]abi.Method
+	events    map[common.Hash][]eventEntry
+	contracts map[common.Address]*abi.ABI
+	// the contracts which are queued or tried to be fetched
+	requested  map[common.Address]bool
+	fetchQueue chan common.Address
+	mu         sync.RWMutex
+
+	lastFetchErr health.ErrorTracker
+}
+
+// NewRegistry creates a new registry with the common signatures and the configured signatures
+// and ABIs, and starts fetching the unknown contract ABIs in the background if the fetchers are
+// enabled.
+func NewRegistry(ctx context.Context, cfg config.AbiDecoderConfig, chainID int) (*Registry, error) {
+	registry := &Registry{
+		ctx:        ctx,
+		methods:    make(map[[4]byte][]abi.Method),
+		events:     make(map[common.Hash][]eventEntry),
+		contracts:  make(map[common.Address]*abi.ABI),
+		requested:  make(map[common.Address]bool),
+		fetchQueue: make(chan common.Address, fetchQueueSize),
+	}
+	if err := registry.AddSignatures(CommonSignatures...); err != nil {
+		return nil, err
+	}
+	for _, path := range cfg.SignatureFiles {
+		signatures, err := readSignatures(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.AddSignatures(signatures...); err != nil {
+			return nil, fmt.Errorf("invalid signature in %s: %v", path, err)
+		}
+	}
+	for _, abiCfg := range cfg.ABIs {
+		contractABI, err := readABI(abiCfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(abiCfg.Address) == 0 {
+			registry.AddABI(nil, contractABI)
+			continue
+		}
+		address := common.HexToAddress(abiCfg.Address)
+		registry.AddABI(&address, contractABI)
+	}
+
+	if cfg.Etherscan.Enable {
+		registry.fetchers = append(registry.fetchers, NewEtherscanFetcher(cfg.Etherscan.APIURL, cfg.Etherscan.APIKey))
+	}
+	if cfg.Sourcify.Enable {
+		registry.fetchers = append(registry.fetchers, NewSourcifyFetcher(cfg.Sourcify.RepoURL, chainID))
+	}
+	if len(registry.fetchers) > 0 {
+		go registry.fetchLoop()
+	}
+	return registry, nil
+}
+
+func readABI(path string) (*abi.ABI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open abi file: %v", err)
+	}
+	defer f.Close()
+	contractABI, err := abi.JSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse abi file %s: %v", path, err)
+	}
+	return &contractABI, nil
+}
+
+// AddSignatures adds the function and the event signatures. The event signatures start with "event ".
+func (registry *Registry) AddSignatures(signatures ...string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, signature := range signatures {
+		isEvent := strings.HasPrefix(signature, eventPrefix)
+		signature = strings.TrimSpace(strings.TrimPrefix(signature, eventPrefix))
+		name, args, err := parseSignature(signature)
+		if err != nil {
+			return err
+		}
+		if isEvent {
+			event := abi.NewEvent(name, name, false, args)
+			registry.events[event.ID] = append(registry.events[event.ID], eventEntry{event: event, guessIndexed: true})
+			continue
+		}
+		method := abi.NewMethod(name, name, abi.Function, "", false, false, args, nil)
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		registry.methods[selector] = append(registry.methods[selector], method)
+	}
+	return nil
+}
+
+// AddABI adds the ABI of a contract. The ABI is used for all contracts if the address is nil.
+func (registry *Registry) AddABI(address *common.Address, contractABI *abi.ABI) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if address != nil {
+		registry.contracts[*address] = contractABI
+		return
+	}
+	for _, method := range contractABI.Methods {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		// the ABI methods are preferred to the signatures since the arguments are named
+		registry.methods[selector] = append([]abi.Method{method}, registry.methods[selector]...)
+	}
+	for _, event := range contractABI.Events {
+		registry.events[event.ID] = append([]eventEntry{{event: event}}, registry.events[event.ID]...)
+	}
+}
+
+// Annotate decodes the function call and the logs of the transaction and attaches them to the
+// message. It does nothing if nothing can be decoded.
+func (registry *Registry) Annotate(msg *protocol.TransactionEvent) {
+	decoded := registry.DecodeTx(msg)
+	if decoded == nil {
+		return
+	}
+	if err := Attach(msg, decoded); err != nil {
+		log.WithError(err).WithField("tx", msg.GetTransaction().GetHash()).Warn("failed to attach the decoded transaction")
+	}
+}
+
+// DecodeTx decodes the function call and the logs of the transaction. It returns nil if nothing
+// can be decoded.
+func (registry *Registry) DecodeTx(msg *protocol.TransactionEvent) *Decoded {
+	decoded := &Decoded{}
+	tx := msg.GetTransaction()
+	if len(tx.GetTo()) > 0 {
+		decoded.Call = registry.DecodeCall(common.HexToAddress(tx.GetTo()), tx.GetInput())
+	}
+	for _, l := range msg.GetLogs() {
+		decodedLog := registry.DecodeLog(l)
+		if decodedLog != nil {
+			decoded.Logs = append(decoded.Logs, decodedLog)
+		}
+	}
+	if decoded.Call == nil && len(decoded.Logs) == 0 {
+		return nil
+	}
+	return decoded
+}
+
+// DecodeCall decodes the hex input of a call to the contract. It returns nil if the function is
+// unknown or the input does not match the arguments.
+func (registry *Registry) DecodeCall(to common.Address, inputHex string) *DecodedCall {
+	input, err := hex.DecodeString(strings.TrimPrefix(inputHex, "0x"))
+	if err != nil || len(input) < 4 {
+		return nil
+	}
+	contractABI := registry.contractABI(to)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	candidates := registry.methods[[4]byte{input[0], input[1], input[2], input[3]}]
+	if contractABI != nil {
+		if method, err := contractABI.MethodById(input[:4]); err == nil {
+			candidates = append([]abi.Method{*method}, candidates...)
+		}
+	}
+	for _, method := range candidates {
+		values, err := method.Inputs.Unpack(input[4:])
+		if err != nil {
+			continue
+		}
+		return &DecodedCall{
+			Name:      method.RawName,
+			Signature: method.Sig,
+			Args:      formatArgs(method.Inputs, values),
+		}
+	}
+	return nil
+}
+
+// DecodeLog decodes the log with the event of the contract or the common events. It returns nil
+// if the event is unknown or the log does not match the arguments.
+func (registry *Registry) DecodeLog(l *protocol.TransactionEvent_Log) *DecodedLog {
+	if len(l.GetTopics()) == 0 {
+		return nil
+	}
+	topics := make([]common.Hash, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = common.HexToHash(topic)
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(l.GetData(), "0x"))
+	if err != nil {
+		return nil
+	}
+	contractABI := registry.contractABI(common.HexToAddress(l.GetAddress()))
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	candidates := registry.events[topics[0]]
+	if contractABI != nil {
+		if event, err := contractABI.EventByID(topics[0]); err == nil {
+			candidates = append([]eventEntry{{event: *event}}, candidates...)
+		}
+	}
+	for _, candidate := range candidates {
+		event := candidate.event
+		if candidate.guessIndexed {
+			event = withIndexed(event, len(topics)-1)
+		}
+		args, err := unpackLog(event, topics[1:], data)
+		if err != nil {
+			continue
+		}
+		return &DecodedLog{
+			Address:   strings.ToLower(l.GetAddress()),
+			LogIndex:  l.GetLogIndex(),
+			Name:      event.RawName,
+			Signature: event.Sig,
+			Args:      args,
+		}
+	}
+	return nil
+}
+
+// withIndexed returns a copy of the event where the first arguments are indexed.
+func withIndexed(event abi.Event, indexed int) abi.Event {
+	if indexed > len(event.Inputs) {
+		indexed = len(event.Inputs)
+	}
+	inputs := make(abi.Arguments, len(event.Inputs))
+	copy(inputs, event.Inputs)
+	for i := range inputs {
+		inputs[i].Indexed = i < indexed
+	}
+	event.Inputs = inputs
+	return event
+}
+
+func unpackLog(event abi.Event, topics []common.Hash, data []byte) ([]*Arg, error) {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) != len(topics) {
+		return nil, errors.New("topic count does not match the indexed arguments")
+	}
+	nonIndexed := event.Inputs.NonIndexed()
+	values, err := nonIndexed.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	indexedValues := make(map[string]interface{})
+	if err := abi.ParseTopicsIntoMap(indexedValues, indexedArgs(indexed), topics); err != nil {
+		return nil, err
+	}
+
+	args := make([]*Arg, 0, len(event.Inputs))
+	var indexedPos, nonIndexedPos int
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			args = append(args, &Arg{
+				Name:    input.Name,
+				Type:    input.Type.String(),
+				Indexed: true,
+				Value:   formatValue(indexedValues[indexedArgName(input, indexedPos)]),
+			})
+			indexedPos++
+			continue
+		}
+		args = append(args, &Arg{Name: input.Name, Type: input.Type.String(), Value: formatValue(values[nonIndexedPos])})
+		nonIndexedPos++
+	}
+	return args, nil
+}
+
+// indexedArgs names the unnamed indexed arguments so that they can be parsed into a map.
+func indexedArgs(args abi.Arguments) abi.Arguments {
+	named := make(abi.Arguments, len(args))
+	for i, arg := range args {
+		arg.Name = indexedArgName(arg, i)
+		named[i] = arg
+	}
+	return named
+}
+
+func indexedArgName(arg abi.Argument, i int) string {
+	if len(arg.Name) > 0 {
+		return arg.Name
+	}
+	return "arg" + strconv.Itoa(i)
+}
+
+// contractABI returns the ABI of the contract and requests it from the fetchers if it is unknown.
+func (registry *Registry) contractABI(address common.Address) *abi.ABI {
+	registry.mu.RLock()
+	contractABI, ok := registry.contracts[address]
+	requested := registry.requested[address]
+	registry.mu.RUnlock()
+	if ok || requested || len(registry.fetchers) == 0 {
+		return contractABI
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.requested[address] {
+		return nil
+	}
+	select {
+	case registry.fetchQueue <- address:
+		registry.requested[address] = true
+	default:
+		// tries again with the next transaction of the contract
+	}
+	return nil
+}
+
+func (registry *Registry) fetchLoop() {
+	ticker := time.NewTicker(fetchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-registry.ctx.Done():
+			return
+		case address := <-registry.fetchQueue:
+			registry.fetch(address)
+		}
+		select {
+		case <-registry.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (registry *Registry) fetch(address common.Address) {
+	logger := log.WithField("address", strings.ToLower(address.Hex()))
+	var lastErr error
+	for _, fetcher := range registry.fetchers {
+		contractABI, err := fetcher.FetchABI(registry.ctx, address)
+		if err == nil {
+			registry.AddABI(&address, contractABI)
+			logger.WithField("source", fetcher.Name()).Debug("fetched the contract abi")
+			registry.lastFetchErr.Set(nil)
+			return
+		}
+		if !errors.Is(err, ErrNotVerified) {
+			lastErr = err
+		}
+	}
+	registry.lastFetchErr.Set(lastErr)
+	if lastErr != nil {
+		logger.WithError(lastErr).Warn("failed to fetch the contract abi")
+		// requests again with the next transaction of the contract
+		registry.mu.Lock()
+		delete(registry.requested, address)
+		registry.mu.Unlock()
+	}
+}
+
+// Name implements the health.Reporter interface.
+func (registry *Registry) Name() string {
+	return "abi-decoder"
+}
+
+// Health implements the health.Reporter interface.
+func (registry *Registry) Health() health.Reports {
+	registry.mu.RLock()
+	contracts := len(registry.contracts)
+	registry.mu.RUnlock()
+	return health.Reports{
+		&health.Report{
+			Name:    "abi.contracts",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(contracts),
+		},
+		registry.lastFetchErr.GetReport("abi.fetch.error"),
+	}
+}
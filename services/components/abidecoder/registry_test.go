@@ -0,0 +1,189 @@
+package abidecoder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testToken     = "0x00000000000000000000000000000000000000aa"
+	testRecipient = "0x00000000000000000000000000000000000000bb"
+	// transfer(0xbb, 1000)
+	testTransferInput = "0xa9059cbb00000000000000000000000000000000000000000000000000000000000000bb00000000000000000000000000000000000000000000000000000000000003e8"
+	testTransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	testTransferData  = "0x00000000000000000000000000000000000000000000000000000000000003e8"
+	testAddressTopic  = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+
+	testABI = `[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}]`
+)
+
+func testTransferTx() *protocol.TransactionEvent {
+	return &protocol.TransactionEvent{
+		Transaction: &protocol.TransactionEvent_EthTransaction{To: testToken, Input: testTransferInput},
+		Logs: []*protocol.TransactionEvent_Log{
+			{
+				Address:  testToken,
+				Topics:   []string{testTransferTopic, testAddressTopic, testAddressTopic},
+				Data:     testTransferData,
+				LogIndex: "0x0",
+			},
+			// unknown event
+			{Address: testToken, Topics: []string{"0x01"}},
+		},
+	}
+}
+
+func TestRegistry_Signatures(t *testing.T) {
+	r := require.New(t)
+
+	registry, err := NewRegistry(context.Background(), config.AbiDecoderConfig{}, 1)
+	r.NoError(err)
+
+	decoded := registry.DecodeTx(testTransferTx())
+	r.NotNil(decoded)
+	r.Equal(&DecodedCall{
+		Name:      "transfer",
+		Signature: "transfer(address,uint256)",
+		Args: []*Arg{
+			{Type: "address", Value: testRecipient},
+			{Type: "uint256", Value: "1000"},
+		},
+	}, decoded.Call)
+	r.Len(decoded.Logs, 1)
+	r.Equal(&DecodedLog{
+		Address:   testToken,
+		LogIndex:  "0x0",
+		Name:      "Transfer",
+		Signature: "Transfer(address,address,uint256)",
+		Args: []*Arg{
+			{Name: "arg0", Type: "address", Indexed: true, Value: testRecipient},
+			{Name: "arg1", Type: "address", Indexed: true, Value: testRecipient},
+			{Name: "arg2", Type: "uint256", Value: "1000"},
+		},
+	}, decoded.Logs[0])
+
+	// unknown function
+	r.Nil(registry.DecodeCall(common.HexToAddress(testToken), "0x12345678"))
+	// invalid input
+	r.Nil(registry.DecodeCall(common.HexToAddress(testToken), "0xa9059cbb00"))
+}
+
+func TestRegistry_ContractABI(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	abiPath := filepath.Join(dir, "token.json")
+	r.NoError(os.WriteFile(abiPath, []byte(testABI), 0644))
+	sigPath := filepath.Join(dir, "signatures.txt")
+	r.NoError(os.WriteFile(sigPath, []byte("# custom\nfoo(uint256)\nevent Bar(address)\n"), 0644))
+
+	registry, err := NewRegistry(context.Background(), config.AbiDecoderConfig{
+		ABIs:           []config.ContractABIConfig{{Address: testToken, Path: abiPath}},
+		SignatureFiles: []string{sigPath},
+	}, 1)
+	r.NoError(err)
+
+	decoded := registry.DecodeTx(testTransferTx())
+	r.Equal("to", decoded.Call.Args[0].Name)
+	r.Equal("amount", decoded.Call.Args[1].Name)
+	r.Equal("from", decoded.Logs[0].Args[0].Name)
+	r.Equal("value", decoded.Logs[0].Args[2].Name)
+
+	// the other contracts are decoded with the signatures
+	call := registry.DecodeCall(common.HexToAddress(testRecipient), testTransferInput)
+	r.Empty(call.Args[0].Name)
+	r.NotNil(registry.DecodeCall(common.HexToAddress(testRecipient), "0x2fbebd38"+testTransferData[2:]))
+
+	_, err = NewRegistry(context.Background(), config.AbiDecoderConfig{SignatureFiles: []string{filepath.Join(dir, "none")}}, 1)
+	r.Error(err)
+	r.Error(registry.AddSignatures("foo((uint256,address))"))
+}
+
+func TestAttach(t *testing.T) {
+	r := require.New(t)
+
+	registry, err := NewRegistry(context.Background(), config.AbiDecoderConfig{}, 1)
+	r.NoError(err)
+
+	msg := testTransferTx()
+	decoded, err := FromMessage(msg)
+	r.NoError(err)
+	r.Nil(decoded)
+
+	registry.Annotate(msg)
+	b, err := proto.Marshal(msg)
+	r.NoError(err)
+
+	var received protocol.TransactionEvent
+	r.NoError(proto.Unmarshal(b, &received))
+	r.Equal(testTransferInput, received.Transaction.Input)
+	decoded, err = FromMessage(&received)
+	r.NoError(err)
+	r.Equal("transfer", decoded.Call.Name)
+	r.Equal("Transfer", decoded.Logs[0].Name)
+}
+
+func TestFetchers(t *testing.T) {
+	r := require.New(t)
+
+	verified := common.HexToAddress(testToken)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api":
+			if req.URL.Query().Get("address") != verified.Hex() {
+				fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"Contract source code not verified"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status":"1","message":"OK","result":%q}`, testABI)
+		case fmt.Sprintf("/contracts/partial_match/1/%s/metadata.json", verified.Hex()):
+			fmt.Fprintf(w, `{"output":{"abi":%s}}`, testABI)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for _, fetcher := range []Fetcher{
+		NewEtherscanFetcher(server.URL+"/api", "key"),
+		NewSourcifyFetcher(server.URL, 1),
+	} {
+		contractABI, err := fetcher.FetchABI(context.Background(), verified)
+		r.NoError(err, fetcher.Name())
+		r.Contains(contractABI.Methods, "transfer")
+
+		_, err = fetcher.FetchABI(context.Background(), common.HexToAddress(testRecipient))
+		r.ErrorIs(err, ErrNotVerified, fetcher.Name())
+	}
+}
+
+func TestRegistry_Fetch(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, `{"status":"1","message":"OK","result":%q}`, testABI)
+	}))
+	defer server.Close()
+
+	registry, err := NewRegistry(context.Background(), config.AbiDecoderConfig{}, 1)
+	r.NoError(err)
+	registry.fetchers = []Fetcher{NewEtherscanFetcher(server.URL, "")}
+
+	// the first call is decoded with the signatures and the abi is fetched in the background
+	call := registry.DecodeCall(common.HexToAddress(testToken), testTransferInput)
+	r.Empty(call.Args[0].Name)
+	registry.fetch(<-registry.fetchQueue)
+	call = registry.DecodeCall(common.HexToAddress(testToken), testTransferInput)
+	r.Equal("to", call.Args[0].Name)
+}
@@ -0,0 +1,116 @@
+package abidecoder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// eventPrefix marks the event signatures in the signature lists.
+const eventPrefix = "event "
+
+// CommonSignatures are the function and the event signatures of the token standards and the
+// other widely used contracts.
+var CommonSignatures = []string{
+	"transfer(address,uint256)",
+	"transferFrom(address,address,uint256)",
+	"approve(address,uint256)",
+	"increaseAllowance(address,uint256)",
+	"decreaseAllowance(address,uint256)",
+	"mint(address,uint256)",
+	"burn(uint256)",
+	"burnFrom(address,uint256)",
+	"deposit()",
+	"withdraw(uint256)",
+	"safeTransferFrom(address,address,uint256)",
+	"safeTransferFrom(address,address,uint256,bytes)",
+	"safeTransferFrom(address,address,uint256,uint256,bytes)",
+	"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+	"setApprovalForAll(address,bool)",
+	"transferOwnership(address)",
+	"renounceOwnership()",
+	"upgradeTo(address)",
+	"upgradeToAndCall(address,bytes)",
+	"multicall(bytes[])",
+	"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+	"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)",
+	"swapExactETHForTokens(uint256,address[],address,uint256)",
+	"swapExactTokensForETH(uint256,uint256,address[],address,uint256)",
+	"event Transfer(address,address,uint256)",
+	"event Approval(address,address,uint256)",
+	"event ApprovalForAll(address,address,bool)",
+	"event TransferSingle(address,address,address,uint256,uint256)",
+	"event TransferBatch(address,address,address,uint256[],uint256[])",
+	"event OwnershipTransferred(address,address)",
+	"event Upgraded(address)",
+	"event Deposit(address,uint256)",
+	"event Withdrawal(address,uint256)",
+	"event Swap(address,uint256,uint256,uint256,uint256,address)",
+	"event Sync(uint112,uint112)",
+	"event Paused(address)",
+	"event Unpaused(address)",
+}
+
+// SignatureHash returns the hash of the function or the event signature, ignoring the spaces. The
+// first four bytes of the hash are the function selector.
+func SignatureHash(signature string) common.Hash {
+	return crypto.Keccak256Hash([]byte(strings.ReplaceAll(signature, " ", "")))
+}
+
+// parseSignature parses the name and the argument types of a signature like "transfer(address,uint256)".
+// The tuple arguments are not supported since their components are not named in the signatures.
+func parseSignature(signature string) (name string, args abi.Arguments, err error) {
+	signature = strings.ReplaceAll(signature, " ", "")
+	open := strings.Index(signature, "(")
+	if open < 1 || !strings.HasSuffix(signature, ")") {
+		return "", nil, fmt.Errorf("invalid signature: %s", signature)
+	}
+	name = signature[:open]
+	params := signature[open+1 : len(signature)-1]
+	if len(params) == 0 {
+		return name, nil, nil
+	}
+	if strings.Contains(params, "(") {
+		return "", nil, fmt.Errorf("tuple arguments are not supported: %s", signature)
+	}
+	for _, param := range strings.Split(params, ",") {
+		typ, err := abi.NewType(param, "", nil)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid argument type in signature %s: %v", signature, err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return name, args, nil
+}
+
+// readSignatures reads the signatures from a file which contains a signature on each line. The
+// empty lines and the lines starting with "#" are skipped.
+func readSignatures(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signature file: %v", err)
+	}
+	defer f.Close()
+	return scanSignatures(f)
+}
+
+func scanSignatures(r io.Reader) (signatures []string, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		signatures = append(signatures, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read signatures: %v", err)
+	}
+	return signatures, nil
+}
@@ -0,0 +1,174 @@
+package botio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// processTxBatches processes the tx requests in batches with as many workers as the concurrency
+// option. The workers continue with one request at a time after the bot is found not to support
+// the batches.
+func (bot *botClient) processTxBatches(lg *log.Entry) {
+	var wg sync.WaitGroup
+	for i := 0; i < bot.requestOpts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bot.processTxBatchesWorker(lg)
+		}()
+	}
+	wg.Wait()
+}
+
+func (bot *botClient) processTxBatchesWorker(lg *log.Entry) {
+	for !bot.txBatchUnsupported.Load() {
+		batch, ok := bot.collectTxBatch()
+		if !ok {
+			lg.WithError(bot.ctx.Err()).Info("bot context is done")
+			return
+		}
+		if exit := bot.processTxBatch(lg, batch); exit {
+			return
+		}
+	}
+	processRequestsWorker(bot.ctx, bot.txRequests, &bot.inFlight, bot.requestOpts, lg, bot.processTransaction)
+}
+
+// collectTxBatch waits for the first request and collects the next requests until the batch
+// is full or the first request waits for the batch wait time.
+func (bot *botClient) collectTxBatch() ([]*botreq.TxRequest, bool) {
+	var batch []*botreq.TxRequest
+	add := func(request *botreq.TxRequest) {
+		atomic.AddInt64(&bot.inFlight, 1)
+		bot.pending.add(pendingTxRequest(request.Original))
+		batch = append(batch, request)
+	}
+
+	select {
+	case <-bot.ctx.Done():
+		return nil, false
+	case request := <-bot.txRequests:
+		add(request)
+	}
+
+	timer := time.NewTimer(bot.requestOpts.BatchWait)
+	defer timer.Stop()
+	for len(batch) < bot.requestOpts.BatchSize {
+		select {
+		case <-bot.ctx.Done():
+			bot.releaseTxBatch(batch)
+			return nil, false
+		case <-timer.C:
+			return batch, true
+		case request := <-bot.txRequests:
+			add(request)
+		}
+	}
+	return batch, true
+}
+
+func (bot *botClient) releaseTxBatch(batch []*botreq.TxRequest) {
+	for _, request := range batch {
+		bot.finishRequest(request.Original.RequestId)
+	}
+	atomic.AddInt64(&bot.inFlight, -int64(len(batch)))
+}
+
+// processTxBatch sends the batch in one call and sends the results of each request like
+// the unary calls. The batch is split into multiple calls if it exceeds the max send size.
+func (bot *botClient) processTxBatch(lg *log.Entry, batch []*botreq.TxRequest) (exit bool) {
+	defer bot.releaseTxBatch(batch)
+
+	requests := make([]*protocol.EvaluateTxRequest, len(batch))
+	for i, request := range batch {
+		requests[i] = request.Original
+	}
+	for _, split := range bot.requestOpts.SizeLimits.SplitTxBatch(requests) {
+		if exit = bot.sendTxBatch(lg, batch[:len(split)]); exit {
+			return
+		}
+		batch = batch[len(split):]
+	}
+	return
+}
+
+// sendTxBatch sends the batch in one call. The batch is sent again with unary calls if the bot
+// does not support batches.
+func (bot *botClient) sendTxBatch(lg *log.Entry, batch []*botreq.TxRequest) (exit bool) {
+	lg = lg.WithField("batchSize", len(batch))
+	botConfig := bot.Config()
+	botClient := bot.grpcClient()
+
+	if bot.IsClosed() {
+		return true
+	}
+
+	if !bot.requestOpts.Semaphore.acquire(bot.ctx) {
+		return false
+	}
+	defer bot.requestOpts.Semaphore.release()
+
+	ctx, cancel := context.WithTimeout(bot.ctx, bot.requestOpts.Timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	in := &agentgrpc.EvaluateTxBatchRequest{Requests: make([]*protocol.EvaluateTxRequest, len(batch))}
+	for i, request := range batch {
+		in.Requests[i] = request.Original
+	}
+	out := new(agentgrpc.EvaluateTxBatchResponse)
+
+	lg.Debug("sending batch request")
+	requestTime := time.Now().UTC()
+	err := bot.invoke(ctx, lg, botClient, agentgrpc.MethodEvaluateTxBatch, in, out, metrics.MetricTxDrop)
+	responseTime := time.Now().UTC()
+
+	if err == nil && len(out.Responses) != len(batch) {
+		err = fmt.Errorf("batch has %d responses for %d requests", len(out.Responses), len(batch))
+	}
+
+	if status.Code(err) == codes.Unimplemented {
+		if bot.txBatchUnsupported.CompareAndSwap(false, true) {
+			lg.Info("bot does not support batches - falling back to unary calls")
+		}
+		return bot.processTxBatchUnary(lg, batch)
+	}
+
+	if err != nil && err != errCircuitOpen {
+		// the requests are dead lettered one by one so that they are replayed with unary calls
+		for _, request := range batch {
+			bot.deadLetter(lg, botConfig, agentgrpc.MethodEvaluateTx, request.Original, err)
+		}
+	}
+	if err != nil {
+		return bot.handleTxErr(lg, botConfig, err, startTime)
+	}
+
+	for i, request := range batch {
+		bot.sendTxResult(lg.WithField("request", request.Original.RequestId), botConfig, request, out.Responses[i], startTime, requestTime, responseTime)
+	}
+	return false
+}
+
+func (bot *botClient) processTxBatchUnary(lg *log.Entry, batch []*botreq.TxRequest) (exit bool) {
+	for _, request := range batch {
+		ctx, cancel := context.WithTimeout(bot.ctx, bot.requestOpts.Timeout)
+		exit = bot.processTransaction(ctx, lg, request)
+		cancel()
+		if exit {
+			return
+		}
+	}
+	return
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -22,6 +23,7 @@ import (
 	"github.com/forta-network/forta-node/services/components/metrics"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/status"
 )
 
@@ -32,21 +34,36 @@ type BotClient interface {
 
 	Initialized() <-chan struct{}
 	IsInitialized() bool
+	IsReady() bool
 	Closed() <-chan struct{}
 	IsClosed() bool
 
 	TxBufferIsFull() bool
+	IsIdle() bool
+	IsDegraded() bool
+	IsDisabled() bool
+	PerformanceScore() (PerformanceScore, bool)
+	PendingRequests() []PendingRequest
 
 	Initialize()
 	StartProcessing()
 
 	ShouldProcessBlock(blockNumberHex string) bool
 	ShouldProcessAlert(event *protocol.AlertEvent) bool
+	ShouldProcessTxEvent(event *protocol.TransactionEvent) bool
+	ShouldProcessLogEvent(event *protocol.TransactionEvent) bool
+	ShouldProcessBlockEvent(event *protocol.BlockEvent) bool
+	ShouldProcessBeaconEvent(event *protocol.BlockEvent) bool
+	ShouldProcessScheduledEvent(event *protocol.BlockEvent) bool
 
 	TxRequestCh() chan<- *botreq.TxRequest
 	BlockRequestCh() chan<- *botreq.BlockRequest
 	CombinationRequestCh() chan<- *botreq.CombinationRequest
 
+	EnqueueTxRequest(req *botreq.TxRequest) (dropped bool)
+	EnqueueBlockRequest(req *botreq.BlockRequest) (dropped bool)
+	EnqueueCombinationRequest(req *botreq.CombinationRequest) (dropped bool)
+
 	LogStatus()
 
 	CombinerBotSubscriptions() []domain.CombinerBotSubscription
@@ -62,29 +79,68 @@ const (
 	DefaultInitializeTimeout = 5 * time.Minute
 )
 
+// dropWarningInterval is the min interval between the dropped request warnings of a bot.
+var dropWarningInterval = time.Minute
+
+// Redial backoff limits
+var (
+	MinRedialBackoff = 10 * time.Second
+	MaxRedialBackoff = 5 * time.Minute
+)
+
 // botClient receives blocks and transactions, and produces results.
 type botClient struct {
-	ctx               context.Context
-	ctxCancel         func()
-	configUnsafe      config.AgentConfig
-	alertConfigUnsafe protocol.AlertConfig
+	ctx                context.Context
+	ctxCancel          func()
+	configUnsafe       config.AgentConfig
+	filterUnsafe       *eventFilter
+	alertConfigUnsafe  protocol.AlertConfig
+	capabilitiesUnsafe *agentgrpc.Capabilities
 
 	txRequests          chan *botreq.TxRequest          // never closed - deallocated when bot is discarded
 	blockRequests       chan *botreq.BlockRequest       // never closed - deallocated when bot is discarded
 	combinationRequests chan *botreq.CombinationRequest // never closed - deallocated when bot is discarded
 
-	resultChannels botreq.SendOnlyChannels
+	resultChannels  botreq.SendOnlyChannels
+	inFlight        int64
+	pending         *pendingRequests
+	dropped         uint64
+	lastDropWarning time.Time
+
+	requestOpts RequestOptions
 
+	circuitBreaker   *nodeutils.CircuitBreaker
 	errCounter       *nodeutils.ErrorCounter
 	msgClient        clients.MessageClient
 	lifecycleMetrics metrics.Lifecycle
 
-	dialer       agentgrpc.BotDialer
-	clientUnsafe agentgrpc.Client
+	dialer        agentgrpc.BotDialer
+	clientUnsafe  agentgrpc.Client
+	degraded      atomic.Bool
+	redialBackoff time.Duration
+
+	unhealthy           atomic.Bool
+	healthCheckFailures uint32
+
+	performance        *performanceWindow
+	disabled           atomic.Bool
+	probationSuccesses uint32
+
+	txStreams           chan *txStream
+	txStreamUnsupported atomic.Bool
+
+	// the amount of the tx requests on the disk or moving from the disk to the buffer
+	overflowed         int
+	overflowSignal     chan struct{}
+	overflowMu         sync.Mutex
+	txBatchUnsupported atomic.Bool
 
 	initialized     chan struct{}
 	initializedOnce sync.Once
 
+	warmedUp   chan struct{}
+	warmUpOnce sync.Once
+
 	closeOnce sync.Once
 
 	mu sync.RWMutex
@@ -124,23 +180,40 @@ func MakeCombinerBotSubscriptions(
 func NewBotClient(
 	ctx context.Context, botCfg config.AgentConfig,
 	msgClient clients.MessageClient, lifecycleMetrics metrics.Lifecycle, botDialer agentgrpc.BotDialer,
-	resultChannels botreq.SendOnlyChannels,
+	resultChannels botreq.SendOnlyChannels, requestOpts RequestOptions,
 ) *botClient {
+	requestOpts.setDefaults()
 	botCtx, botCtxCancel := context.WithCancel(ctx)
-	return &botClient{
+	bot := &botClient{
 		ctx:                 botCtx,
 		ctxCancel:           botCtxCancel,
 		configUnsafe:        botCfg,
-		txRequests:          make(chan *botreq.TxRequest, DefaultBufferSize),
+		filterUnsafe:        newEventFilter(botCfg.Filters),
+		txRequests:          make(chan *botreq.TxRequest, requestOpts.TxBufferSize),
 		blockRequests:       make(chan *botreq.BlockRequest, DefaultBufferSize),
 		combinationRequests: make(chan *botreq.CombinationRequest, DefaultBufferSize),
 		resultChannels:      resultChannels,
-		errCounter:          nodeutils.NewErrorCounter(3, isCriticalErr),
-		msgClient:           msgClient,
-		lifecycleMetrics:    lifecycleMetrics,
-		dialer:              botDialer,
-		initialized:         make(chan struct{}),
+		pending:             newPendingRequests(),
+		requestOpts:         requestOpts,
+		circuitBreaker: nodeutils.NewCircuitBreaker(
+			requestOpts.CircuitBreakerThreshold, requestOpts.CircuitBreakerCooldown,
+		),
+		errCounter:       nodeutils.NewErrorCounter(3, isCriticalErr),
+		msgClient:        msgClient,
+		lifecycleMetrics: lifecycleMetrics,
+		dialer:           botDialer,
+		initialized:      make(chan struct{}),
+		warmedUp:         make(chan struct{}),
+		txStreams:        make(chan *txStream, requestOpts.Concurrency),
+		overflowSignal:   make(chan struct{}, 1),
+	}
+	if requestOpts.Performance != nil {
+		bot.performance = newPerformanceWindow(*requestOpts.Performance)
+	}
+	if requestOpts.TxOverflow != nil {
+		go bot.refillTxRequests()
 	}
+	return bot
 }
 
 func isCriticalErr(err error) bool {
@@ -156,6 +229,7 @@ func (bot *botClient) LogStatus() {
 		"bot":         bot.Config().ID,
 		"blockBuffer": len(bot.blockRequests),
 		"txBuffer":    len(bot.txRequests),
+		"txOverflow":  bot.overflowLen(),
 		"initialized": bot.IsInitialized(),
 		"closed":      bot.IsClosed(),
 	}).Debug("bot status")
@@ -163,7 +237,52 @@ func (bot *botClient) LogStatus() {
 
 // TxBufferIsFull tells if an bot input buffer is full.
 func (bot *botClient) TxBufferIsFull() bool {
-	return len(bot.txRequests) == DefaultBufferSize
+	return len(bot.txRequests) == cap(bot.txRequests)
+}
+
+// IsIdle tells if the bot has no buffered or in-flight requests. The bots which are not
+// processing requests yet or anymore are considered idle.
+func (bot *botClient) IsIdle() bool {
+	if !bot.IsInitialized() || bot.IsClosed() {
+		return true
+	}
+	return len(bot.txRequests) == 0 && len(bot.blockRequests) == 0 &&
+		len(bot.combinationRequests) == 0 && atomic.LoadInt64(&bot.inFlight) == 0 && bot.overflowLen() == 0
+}
+
+// PendingRequests returns the tx and the block requests which the bot did not finish. The requests
+// left in the buffers are included after the bot is closed, since the buffers are not consumed
+// while the bot is processing requests.
+func (bot *botClient) PendingRequests() []PendingRequest {
+	if bot.IsClosed() {
+		bot.collectBufferedRequests()
+	}
+	return bot.pending.list()
+}
+
+func (bot *botClient) collectBufferedRequests() {
+	for {
+		select {
+		case req := <-bot.txRequests:
+			bot.pending.add(pendingTxRequest(req.Original))
+		case req := <-bot.blockRequests:
+			bot.pending.add(pendingBlockRequest(req.Original))
+		default:
+			return
+		}
+	}
+}
+
+// finishRequest stops tracking the request unless the bot was closed while processing it.
+func (bot *botClient) finishRequest(requestID string) {
+	if !bot.IsClosed() {
+		bot.pending.remove(requestID)
+	}
+}
+
+// IsDegraded tells if the bot could not be dialed and is being redialed in the background.
+func (bot *botClient) IsDegraded() bool {
+	return bot.degraded.Load()
 }
 
 // SetConfig sets the bot config.
@@ -172,6 +291,14 @@ func (bot *botClient) SetConfig(botConfig config.AgentConfig) {
 	defer bot.mu.Unlock()
 
 	bot.configUnsafe = botConfig
+	bot.filterUnsafe = newEventFilter(botConfig.Filters)
+}
+
+func (bot *botClient) filter() *eventFilter {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+
+	return bot.filterUnsafe
 }
 
 // Config returns the bot config.
@@ -198,6 +325,22 @@ func (bot *botClient) SetAlertConfig(alertConfig *protocol.AlertConfig) {
 	bot.alertConfigUnsafe = *alertConfig
 }
 
+// capabilities returns the capabilities which the bot reported while initializing. It is nil
+// until the bot is initialized, which supports all event types.
+func (bot *botClient) capabilities() *agentgrpc.Capabilities {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
+
+	return bot.capabilitiesUnsafe
+}
+
+func (bot *botClient) setCapabilities(capabilities *agentgrpc.Capabilities) {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+
+	bot.capabilitiesUnsafe = capabilities
+}
+
 // grpcClient returns the bot gRPC client.
 func (bot *botClient) grpcClient() agentgrpc.Client {
 	bot.mu.RLock()
@@ -233,6 +376,56 @@ func (bot *botClient) CombinationRequestCh() chan<- *botreq.CombinationRequest {
 	return bot.combinationRequests
 }
 
+// EnqueueTxRequest sends the tx request by spilling to the disk if the buffer is full or
+// by applying the backpressure policy.
+func (bot *botClient) EnqueueTxRequest(req *botreq.TxRequest) bool {
+	if bot.requestOpts.TxOverflow != nil {
+		return bot.droppedIf(bot.enqueueTxRequestWithOverflow(req))
+	}
+	return bot.droppedIf(enqueueRequest(bot.Closed(), bot.txRequests, req, bot.requestOpts.BackpressurePolicy))
+}
+
+// EnqueueBlockRequest sends the block request by applying the backpressure policy.
+func (bot *botClient) EnqueueBlockRequest(req *botreq.BlockRequest) bool {
+	return bot.droppedIf(enqueueRequest(bot.Closed(), bot.blockRequests, req, bot.requestOpts.BackpressurePolicy))
+}
+
+// EnqueueCombinationRequest sends the combination request by applying the backpressure policy.
+func (bot *botClient) EnqueueCombinationRequest(req *botreq.CombinationRequest) bool {
+	return bot.droppedIf(enqueueRequest(bot.Closed(), bot.combinationRequests, req, bot.requestOpts.BackpressurePolicy))
+}
+
+// droppedIf counts the dropped requests and warns periodically so that it is visible
+// that the bot can not keep up. The warning is also published so that the node can
+// publish a warning finding about the bot.
+func (bot *botClient) droppedIf(dropped bool) bool {
+	if !dropped {
+		return false
+	}
+	count := atomic.AddUint64(&bot.dropped, 1)
+
+	bot.mu.Lock()
+	shouldWarn := time.Since(bot.lastDropWarning) >= dropWarningInterval
+	if shouldWarn {
+		bot.lastDropWarning = time.Now()
+	}
+	bot.mu.Unlock()
+
+	if shouldWarn {
+		log.WithFields(log.Fields{
+			"bot":     bot.Config().ID,
+			"policy":  bot.requestOpts.BackpressurePolicy,
+			"dropped": count,
+		}).Warn("bot can not keep up with the requests - dropping requests")
+		bot.msgClient.Publish(messaging.SubjectAgentsStatusThrottled, messaging.AgentThrottlePayload{
+			Agent:   bot.Config(),
+			Policy:  bot.requestOpts.BackpressurePolicy,
+			Dropped: count,
+		})
+	}
+	return true
+}
+
 // Close implements io.Closer.
 func (bot *botClient) Close() error {
 	bot.closeOnce.Do(func() {
@@ -244,6 +437,9 @@ func (bot *botClient) Close() error {
 		botConfig := bot.Config()
 		log.WithField("bot", botConfig.ID).WithField("image", botConfig.Image).Info("detached")
 		bot.lifecycleMetrics.ClientClose(botConfig)
+		if bot.requestOpts.TxOverflow != nil {
+			bot.closeOverflow()
+		}
 		if bot.isCombinerBot() {
 			bot.msgClient.Publish(messaging.SubjectAgentsAlertUnsubscribe, bot.CombinerBotSubscriptions())
 			bot.lifecycleMetrics.ActionUnsubscribe(bot.CombinerBotSubscriptions())
@@ -307,25 +503,39 @@ func (bot *botClient) initialize() {
 
 	botClient, err := bot.dialer.DialBot(botConfig)
 	if err != nil {
-		logger.WithError(err).Info("failed to dial bot")
+		logger.WithError(err).Warn("failed to dial bot - redialing in the background")
+		bot.lifecycleMetrics.FailureDial(err, botConfig)
+		bot.degraded.Store(true)
+		bot.redialLater()
 		return
 	}
+	bot.degraded.Store(false)
+	bot.redialBackoff = 0
 	bot.setGrpcClient(botClient)
 	bot.lifecycleMetrics.StatusAttached(botConfig)
 	logger.Info("attached to bot")
+	if watcher, ok := botClient.(agentgrpc.StateWatcher); ok {
+		go watcher.WatchState(bot.ctx, bot.onConnectionStateChange)
+	}
 
 	ctx, cancel := context.WithTimeout(bot.ctx, DefaultInitializeTimeout)
 	defer cancel()
 
 	// invoke initialize method of the bot
-	initializeResponse, err := botClient.Initialize(ctx, &protocol.InitializeRequest{
+	initializeRequest := &protocol.InitializeRequest{
 		AgentId:   botConfig.ID,
 		ProxyHost: config.DockerJSONRPCProxyContainerName,
-	})
+	}
+	if err := agentgrpc.AttachNodeProtocol(initializeRequest); err != nil {
+		logger.WithError(err).Warn("failed to attach the node protocol to the initialize request")
+	}
+	initializeResponse, err := botClient.Initialize(ctx, initializeRequest)
 
 	// it is not mandatory to implement a initialize method, safe to skip
 	if status.Code(err) == codes.Unimplemented {
 		logger.WithError(err).Info("initialize() method not implemented in bot - safe to ignore")
+		bot.setCapabilities(agentgrpc.DefaultCapabilities())
+		negotiateCompressor(botClient, agentgrpc.DefaultCapabilities())
 		bot.initSuccess(botConfig)
 		return
 	}
@@ -350,6 +560,27 @@ func (bot *botClient) initialize() {
 		return
 	}
 
+	capabilities, err := agentgrpc.CapabilitiesFromResponse(initializeResponse)
+	if err == nil {
+		err = capabilities.CheckProtocolVersion()
+	}
+	if err != nil {
+		logger.WithError(err).WithFields(log.Fields{
+			"nodeProtocolVersion":    agentgrpc.ProtocolVersion,
+			"nodeMinProtocolVersion": agentgrpc.MinProtocolVersion,
+		}).Error("bot is incompatible with the node - not running the bot")
+		bot.lifecycleMetrics.FailureInitializeValidate(err, botConfig)
+		_ = bot.Close()
+		return
+	}
+	bot.setCapabilities(capabilities)
+	logger.WithFields(log.Fields{
+		"protocolVersion": capabilities.ProtocolVersion,
+		"eventTypes":      capabilities.EventTypes,
+		"config":          capabilities.Config,
+		"compressor":      negotiateCompressor(botClient, capabilities),
+	}).Info("bot capabilities")
+
 	// Let services know about the latest subscriptions
 	if initializeResponse != nil && initializeResponse.AlertConfig != nil {
 		bot.SetAlertConfig(initializeResponse.AlertConfig)
@@ -361,9 +592,61 @@ func (bot *botClient) initialize() {
 	logger.Info("bot initialization succeeded")
 }
 
+// onConnectionStateChange marks the bot as degraded while the connection is broken. The gRPC
+// connection reconnects in the background, so the bot recovers without being restarted.
+func (bot *botClient) onConnectionStateChange(state connectivity.State) {
+	logger := log.WithFields(log.Fields{
+		"bot":   bot.Config().ID,
+		"state": state.String(),
+	})
+	switch state {
+	case connectivity.TransientFailure:
+		if !bot.degraded.Swap(true) {
+			logger.Warn("lost the connection to the bot - reconnecting")
+		}
+	case connectivity.Ready:
+		if bot.degraded.Swap(false) {
+			logger.Info("reconnected to the bot")
+		}
+	default:
+		logger.Debug("bot connection state changed")
+	}
+}
+
+// redialLater retries initializing the bot after an exponential backoff, unless the bot is closed.
+func (bot *botClient) redialLater() {
+	bot.redialBackoff *= 2
+	if bot.redialBackoff == 0 {
+		bot.redialBackoff = MinRedialBackoff
+	}
+	if bot.redialBackoff > MaxRedialBackoff {
+		bot.redialBackoff = MaxRedialBackoff
+	}
+	backoff := bot.redialBackoff
+	go func() {
+		select {
+		case <-bot.ctx.Done():
+		case <-time.After(backoff):
+			bot.initialize()
+		}
+	}()
+}
+
 func (bot *botClient) initSuccess(botConfig config.AgentConfig) {
 	bot.setInitialized()
 	bot.lifecycleMetrics.StatusInitialized(botConfig)
+	go bot.warmUp()
+	if bot.requestOpts.HealthCheckInterval > 0 || bot.performance != nil {
+		go bot.checkHealthPeriodically()
+	}
+}
+
+// negotiateCompressor chooses the compressor of the requests if the client can compress them.
+func negotiateCompressor(botClient agentgrpc.Client, capabilities *agentgrpc.Capabilities) string {
+	if negotiator, ok := botClient.(agentgrpc.CompressionNegotiator); ok {
+		return negotiator.NegotiateCompressor(capabilities)
+	}
+	return ""
 }
 
 func validateInitializeResponse(response *protocol.InitializeResponse) error {
@@ -391,9 +674,25 @@ func (bot *botClient) StartProcessing() {
 	go bot.processCombinationAlerts()
 }
 
+// processRequests processes the requests with as many workers as the concurrency option.
 func processRequests[R any](
-	ctx context.Context, reqCh <-chan *R, closedCh <-chan struct{}, logger *log.Entry,
-	processFunc func(context.Context, *log.Entry, *R) bool,
+	ctx context.Context, reqCh <-chan *R, inFlight *int64, opts RequestOptions,
+	logger *log.Entry, processFunc func(context.Context, *log.Entry, *R) bool,
+) {
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			processRequestsWorker(ctx, reqCh, inFlight, opts, logger, processFunc)
+		}()
+	}
+	wg.Wait()
+}
+
+func processRequestsWorker[R any](
+	ctx context.Context, reqCh <-chan *R, inFlight *int64, opts RequestOptions,
+	logger *log.Entry, processFunc func(context.Context, *log.Entry, *R) bool,
 ) {
 	for {
 		select {
@@ -402,9 +701,16 @@ func processRequests[R any](
 			return
 
 		case request := <-reqCh:
-			ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+			atomic.AddInt64(inFlight, 1)
+			if !opts.Semaphore.acquire(ctx) {
+				atomic.AddInt64(inFlight, -1)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
 			exit := processFunc(ctx, logger, request)
 			cancel()
+			opts.Semaphore.release()
+			atomic.AddInt64(inFlight, -1)
 			if exit {
 				return
 			}
@@ -421,9 +727,13 @@ func (bot *botClient) processTransactions() {
 		},
 	)
 
-	<-bot.Initialized()
+	<-bot.WarmedUp()
 
-	processRequests(bot.ctx, bot.txRequests, bot.Closed(), lg, bot.processTransaction)
+	if bot.requestOpts.BatchSize > 1 {
+		bot.processTxBatches(lg)
+		return
+	}
+	processRequests(bot.ctx, bot.txRequests, &bot.inFlight, bot.requestOpts, lg, bot.processTransaction)
 }
 func (bot *botClient) processBlocks() {
 	lg := log.WithFields(
@@ -434,9 +744,9 @@ func (bot *botClient) processBlocks() {
 		},
 	)
 
-	<-bot.Initialized()
+	<-bot.WarmedUp()
 
-	processRequests(bot.ctx, bot.blockRequests, bot.Closed(), lg, bot.processBlock)
+	processRequests(bot.ctx, bot.blockRequests, &bot.inFlight, bot.requestOpts, lg, bot.processBlock)
 }
 
 func (bot *botClient) processCombinationAlerts() {
@@ -448,15 +758,23 @@ func (bot *botClient) processCombinationAlerts() {
 		},
 	)
 
-	<-bot.Initialized()
+	<-bot.WarmedUp()
 
-	processRequests(bot.ctx, bot.combinationRequests, bot.Closed(), lg, bot.processCombinationAlert)
+	processRequests(bot.ctx, bot.combinationRequests, &bot.inFlight, bot.requestOpts, lg, bot.processCombinationAlert)
 }
 
 func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, request *botreq.TxRequest) (exit bool) {
+	lg = lg.WithFields(log.Fields{
+		"request": request.Original.RequestId,
+		"block":   request.Original.Event.GetBlock().GetBlockNumber(),
+		"tx":      request.Original.Event.GetTransaction().GetHash(),
+	})
 	botConfig := bot.Config()
 	botClient := bot.grpcClient()
 
+	bot.pending.add(pendingTxRequest(request.Original))
+	defer bot.finishRequest(request.Original.RequestId)
+
 	if bot.IsClosed() {
 		return true
 	}
@@ -467,45 +785,57 @@ func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, req
 	resp := new(protocol.EvaluateTxResponse)
 
 	requestTime := time.Now().UTC()
-	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateTx, request.Original, resp)
+	err := bot.invoke(ctx, lg, botClient, agentgrpc.MethodEvaluateTx, request.Original, resp, metrics.MetricTxDrop)
 	responseTime := time.Now().UTC()
 
 	if err == nil {
-		// truncate findings
-		if len(resp.Findings) > MaxFindings {
-			dropped := len(resp.Findings) - MaxFindings
-			droppedMetric := metrics.CreateAgentMetric(botConfig, metrics.MetricFindingsDropped, float64(dropped))
-			bot.msgClient.PublishProto(
-				messaging.SubjectMetricAgent,
-				&protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}},
-			)
-			resp.Findings = resp.Findings[:MaxFindings]
-		}
-		var duration time.Duration
-		resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
-		lg.WithField("duration", duration).Debugf("request successful")
+		bot.sendTxResult(lg, botConfig, request, resp, startTime, requestTime, responseTime)
+		return false
+	}
 
-		if resp.Metadata == nil {
-			resp.Metadata = make(map[string]string)
-		}
-		resp.Metadata["imageHash"] = botConfig.ImageHash()
+	return bot.handleTxErr(lg, botConfig, err, startTime)
+}
 
-		ts := domain.TrackingTimestampsFromMessage(request.Original.Event.Timestamps)
-		ts.BotRequest = requestTime
-		ts.BotResponse = responseTime
+// sendTxResult sends the successful tx response to the results.
+func (bot *botClient) sendTxResult(
+	lg *log.Entry, botConfig config.AgentConfig, request *botreq.TxRequest, resp *protocol.EvaluateTxResponse,
+	startTime, requestTime, responseTime time.Time,
+) {
+	// truncate findings
+	if len(resp.Findings) > MaxFindings {
+		dropped := len(resp.Findings) - MaxFindings
+		droppedMetric := metrics.CreateAgentMetric(botConfig, metrics.MetricFindingsDropped, float64(dropped))
+		bot.msgClient.PublishProto(
+			messaging.SubjectMetricAgent,
+			&protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}},
+		)
+		resp.Findings = resp.Findings[:MaxFindings]
+	}
+	var duration time.Duration
+	resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
+	lg.WithField("duration", duration).Debugf("request successful")
 
-		bot.resultChannels.Tx <- &botreq.TxResult{
-			AgentConfig: botConfig,
-			Request:     request.Original,
-			Response:    resp,
-			Timestamps:  ts,
-		}
-		lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
+	if resp.Metadata == nil {
+		resp.Metadata = make(map[string]string)
+	}
+	resp.Metadata["imageHash"] = botConfig.ImageHash()
 
-		return false
+	ts := domain.TrackingTimestampsFromMessage(request.Original.Event.Timestamps)
+	ts.BotRequest = requestTime
+	ts.BotResponse = responseTime
+
+	bot.resultChannels.Tx <- &botreq.TxResult{
+		AgentConfig: botConfig,
+		Request:     request.Original,
+		Response:    resp,
+		Timestamps:  ts,
 	}
+	lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
+}
 
-	if status.Code(err) == codes.Unimplemented {
+// handleTxErr counts the tx evaluation error and closes the bot if there are too many errors.
+func (bot *botClient) handleTxErr(lg *log.Entry, botConfig config.AgentConfig, err error, startTime time.Time) (exit bool) {
+	if status.Code(err) == codes.Unimplemented || err == errCircuitOpen {
 		return false
 	}
 
@@ -521,9 +851,16 @@ func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, req
 }
 
 func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *botreq.BlockRequest) (exit bool) {
+	lg = lg.WithFields(log.Fields{
+		"request": request.Original.RequestId,
+		"block":   request.Original.Event.GetBlockNumber(),
+	})
 	botConfig := bot.Config()
 	botClient := bot.grpcClient()
 
+	bot.pending.add(pendingBlockRequest(request.Original))
+	defer bot.finishRequest(request.Original.RequestId)
+
 	if bot.IsClosed() {
 		return true
 	}
@@ -532,8 +869,15 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 
 	lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 	resp := new(protocol.EvaluateBlockResponse)
+	method := agentgrpc.MethodEvaluateBlock
+	switch {
+	case request.Beacon:
+		method = agentgrpc.MethodEvaluateBeacon
+	case request.Scheduled:
+		method = agentgrpc.MethodEvaluateSchedule
+	}
 	requestTime := time.Now().UTC()
-	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateBlock, request.Original, resp)
+	err := bot.invoke(ctx, lg, botClient, method, request.Original, resp, metrics.MetricBlockDrop)
 	responseTime := time.Now().UTC()
 
 	if err == nil {
@@ -573,7 +917,7 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 		return false
 	}
 
-	if status.Code(err) == codes.Unimplemented {
+	if status.Code(err) == codes.Unimplemented || err == errCircuitOpen {
 		return false
 	}
 
@@ -589,6 +933,10 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 }
 
 func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry, request *botreq.CombinationRequest) bool {
+	lg = lg.WithFields(log.Fields{
+		"request":     request.Original.RequestId,
+		"sourceAlert": request.Original.Event.GetAlert().GetHash(),
+	})
 	botConfig := bot.Config()
 	botClient := bot.grpcClient()
 
@@ -601,9 +949,13 @@ func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry
 	lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 	resp := new(protocol.EvaluateAlertResponse)
 	requestTime := time.Now().UTC()
-	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateAlert, request.Original, resp)
+	err := bot.invoke(ctx, lg, botClient, agentgrpc.MethodEvaluateAlert, request.Original, resp, metrics.MetricCombinerDrop)
 	responseTime := time.Now().UTC()
 
+	if err == errCircuitOpen {
+		return false
+	}
+
 	if err != nil {
 		if status.Code(err) != codes.Unimplemented {
 			lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking bot")
@@ -618,9 +970,7 @@ func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry
 
 	// validate response
 	if vErr := validateEvaluateAlertResponse(resp); vErr != nil {
-		lg.WithField(
-			"request", request.Original.RequestId,
-		).WithError(vErr).Error("evaluate combination response validation failed")
+		lg.WithError(vErr).Error("evaluate combination response validation failed")
 		bot.lifecycleMetrics.BotError("validate.evaluate.alert.response", vErr, botConfig)
 
 		return false
@@ -735,8 +1085,37 @@ func (bot *botClient) ShouldProcessBlock(blockNumberHex string) bool {
 	return isAtLeastStartBlock && isAtMostStopBlock && isOnThisShard
 }
 
+// ShouldProcessTxEvent tells if the transaction matches the subscription filters of the bot. The
+// pending transactions are sent only to the bots which opt in.
+func (bot *botClient) ShouldProcessTxEvent(event *protocol.TransactionEvent) bool {
+	if agentgrpc.IsPendingTx(event) {
+		return bot.capabilities().OptsIn(agentgrpc.EventTypePendingTx) && bot.filter().MatchesTx(event)
+	}
+	return bot.capabilities().Supports(agentgrpc.EventTypeTx) && bot.filter().MatchesTx(event)
+}
+
+// ShouldProcessLogEvent tells if the logs from the log feed match the subscription filters of the bot.
+func (bot *botClient) ShouldProcessLogEvent(event *protocol.TransactionEvent) bool {
+	return bot.capabilities().Supports(agentgrpc.EventTypeLog) && bot.filter().MatchesLog(event)
+}
+
+// ShouldProcessBeaconEvent tells if the beacon event matches the subscription filters of the bot.
+func (bot *botClient) ShouldProcessBeaconEvent(event *protocol.BlockEvent) bool {
+	return bot.capabilities().Supports(agentgrpc.EventTypeBeacon) && bot.filter().MatchesBeacon(event)
+}
+
+// ShouldProcessScheduledEvent tells if the scheduled evaluation matches the subscription filters of the bot.
+func (bot *botClient) ShouldProcessScheduledEvent(event *protocol.BlockEvent) bool {
+	return bot.capabilities().Supports(agentgrpc.EventTypeSchedule) && bot.filter().MatchesSchedule(event)
+}
+
+// ShouldProcessBlockEvent tells if the block matches the subscription filters of the bot.
+func (bot *botClient) ShouldProcessBlockEvent(event *protocol.BlockEvent) bool {
+	return bot.capabilities().Supports(agentgrpc.EventTypeBlock) && bot.filter().MatchesBlock(event)
+}
+
 func (bot *botClient) ShouldProcessAlert(event *protocol.AlertEvent) bool {
-	if !bot.isCombinerBot() {
+	if !bot.isCombinerBot() || !bot.capabilities().Supports(agentgrpc.EventTypeAlert) {
 		return false
 	}
 
@@ -2,12 +2,17 @@ package botio
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"sync/atomic"
+	"time"
 
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
 )
 
 // BotClientFactory creates new bot clients.
@@ -20,21 +25,100 @@ type botClientFactory struct {
 	msgClient        clients.MessageClient
 	lifecycleMetrics metrics.Lifecycle
 	dialer           agentgrpc.BotDialer
+	scannerCfg       config.ScannerConfig
+	semaphore        Semaphore
+	deadLetters      store.DeadLetterStore
+	overflowDir      string
+	overflowCount    uint64
+	warmUp           *WarmUp
+	sizeLimits       agentgrpc.SizeLimits
 }
 
 // NewBotClientFactory creates a new bot client factory by reusing provided dependencies.
+// The dead letter store and the warm-up are optional. The tx overflow is disabled if the overflow dir is empty.
 func NewBotClientFactory(
 	resultChannels botreq.SendOnlyChannels, msgClient clients.MessageClient,
-	lifecycleMetrics metrics.Lifecycle, dialer agentgrpc.BotDialer,
+	lifecycleMetrics metrics.Lifecycle, dialer agentgrpc.BotDialer, scannerCfg config.ScannerConfig,
+	deadLetters store.DeadLetterStore, overflowDir string, warmUp *WarmUp, sizeLimits agentgrpc.SizeLimits,
 ) BotClientFactory {
 	return &botClientFactory{
 		resultChannels:   resultChannels,
 		msgClient:        msgClient,
 		lifecycleMetrics: lifecycleMetrics,
 		dialer:           dialer,
+		scannerCfg:       scannerCfg,
+		semaphore:        NewSemaphore(scannerCfg.MaxConcurrentBotRequests),
+		deadLetters:      deadLetters,
+		overflowDir:      overflowDir,
+		warmUp:           warmUp,
+		sizeLimits:       sizeLimits,
 	}
 }
 
 func (bcf *botClientFactory) NewBotClient(ctx context.Context, botConfig config.AgentConfig) BotClient {
-	return NewBotClient(ctx, botConfig, bcf.msgClient, bcf.lifecycleMetrics, bcf.dialer, bcf.resultChannels)
+	return NewBotClient(
+		ctx, botConfig, bcf.msgClient, bcf.lifecycleMetrics, bcf.dialer, bcf.resultChannels,
+		RequestOptions{
+			Timeout:      bcf.scannerCfg.BotRequestTimeout(botConfig.ID),
+			MaxAttempts:  bcf.scannerCfg.BotRequestMaxAttempts,
+			RetryBackoff: time.Duration(bcf.scannerCfg.BotRequestRetryBackoffMs) * time.Millisecond,
+			Concurrency:  bcf.scannerCfg.BotConcurrencyFor(botConfig.ID),
+			Semaphore:    bcf.semaphore,
+
+			BackpressurePolicy: bcf.scannerCfg.BotBackpressurePolicyFor(botConfig.ID),
+
+			CircuitBreakerThreshold: bcf.scannerCfg.BotCircuitBreakerThreshold,
+			CircuitBreakerCooldown:  time.Duration(bcf.scannerCfg.BotCircuitBreakerCooldownSeconds) * time.Second,
+
+			HealthCheckInterval:         time.Duration(bcf.scannerCfg.BotHealthCheckIntervalSeconds) * time.Second,
+			HealthCheckFailureThreshold: bcf.scannerCfg.BotHealthCheckFailureThreshold,
+
+			TxStreams: !bcf.scannerCfg.DisableBotTxStreams,
+
+			BatchSize: bcf.botBatchSize(),
+			BatchWait: time.Duration(bcf.scannerCfg.BotBatchMaxWaitMs) * time.Millisecond,
+
+			DeadLetters: bcf.deadLetters,
+
+			TxBufferSize: bcf.scannerCfg.BotTxBufferSize,
+			TxOverflow:   bcf.txOverflow(botConfig),
+
+			WarmUp:        bcf.warmUp,
+			WarmUpTimeout: time.Duration(bcf.scannerCfg.BotWarmUpTimeoutSeconds) * time.Second,
+
+			SizeLimits: bcf.sizeLimits,
+
+			Performance: bcf.botPerformance(),
+		},
+	)
+}
+
+func (bcf *botClientFactory) botBatchSize() int {
+	if !bcf.scannerCfg.EnableBotBatches {
+		return 0
+	}
+	return bcf.scannerCfg.BotBatchMaxSize
+}
+
+func (bcf *botClientFactory) botPerformance() *PerformanceOptions {
+	cfg := bcf.scannerCfg.BotPerformance
+	if !cfg.Enable {
+		return nil
+	}
+	return &PerformanceOptions{
+		WindowSize:      cfg.WindowSize,
+		MinScore:        cfg.MinScore,
+		LatencyTarget:   time.Duration(cfg.LatencyTargetMs) * time.Millisecond,
+		ProbationChecks: cfg.ProbationChecks,
+	}
+}
+
+// txOverflow creates a new overflow queue for each bot client, since the clients of the same bot
+// may overlap while the bot is being replaced.
+func (bcf *botClientFactory) txOverflow(botConfig config.AgentConfig) store.OverflowQueue {
+	if len(bcf.overflowDir) == 0 {
+		return nil
+	}
+	dir := path.Join(bcf.overflowDir, fmt.Sprintf("%s-%d", botConfig.ID, atomic.AddUint64(&bcf.overflowCount, 1)))
+	return store.NewOverflowQueue(dir, int64(bcf.scannerCfg.BotTxOverflowMaxMB)*1024*1024)
 }
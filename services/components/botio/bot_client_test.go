@@ -3,6 +3,10 @@ package botio
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,11 +18,19 @@ import (
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/services/components/metrics"
 	mock_metrics "github.com/forta-network/forta-node/services/components/metrics/mocks"
+	"github.com/forta-network/forta-node/store"
+	"github.com/forta-network/forta-node/testutils/agentserver"
 
 	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -73,7 +85,7 @@ func (s *BotClientSuite) SetupTest() {
 
 	s.botClient = NewBotClient(context.Background(), config.AgentConfig{
 		ID: testBotID,
-	}, s.msgClient, s.lifecycleMetrics, s.botDialer, s.resultChannels.SendOnly())
+	}, s.msgClient, s.lifecycleMetrics, s.botDialer, s.resultChannels.SendOnly(), RequestOptions{})
 }
 
 // TestStartProcessStop tests the starting, processing and stopping flow for a bot.
@@ -236,3 +248,575 @@ func (s *BotClientSuite) TestInitialize_ValidationError() {
 
 	s.botClient.Initialize()
 }
+
+func (s *BotClientSuite) TestInitialize_IncompatibleProtocol() {
+	s.lifecycleMetrics.EXPECT().ClientDial(s.botClient.configUnsafe)
+	s.lifecycleMetrics.EXPECT().StatusAttached(s.botClient.configUnsafe)
+	resp := &protocol.InitializeResponse{Status: protocol.ResponseStatus_SUCCESS}
+	s.r.NoError(agentgrpc.AttachCapabilities(resp, &agentgrpc.Capabilities{ProtocolVersion: agentgrpc.ProtocolVersion + 1}))
+	s.botGrpc.EXPECT().Initialize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *protocol.InitializeRequest, opts ...interface{}) (*protocol.InitializeResponse, error) {
+			// the node should tell its protocol versions
+			s.r.NotEmpty(req.ProtoReflect().GetUnknown())
+			return resp, nil
+		})
+	s.lifecycleMetrics.EXPECT().FailureInitializeValidate(gomock.Any(), s.botClient.configUnsafe)
+	s.lifecycleMetrics.EXPECT().ClientClose(s.botClient.configUnsafe)
+	s.botGrpc.EXPECT().Close()
+
+	s.botClient.Initialize()
+	s.r.True(s.botClient.IsClosed())
+	s.r.False(s.botClient.IsInitialized())
+}
+
+func (s *BotClientSuite) TestInitialize_Capabilities() {
+	s.lifecycleMetrics.EXPECT().ClientDial(s.botClient.configUnsafe)
+	s.lifecycleMetrics.EXPECT().StatusAttached(s.botClient.configUnsafe)
+	resp := &protocol.InitializeResponse{Status: protocol.ResponseStatus_SUCCESS}
+	s.r.NoError(agentgrpc.AttachCapabilities(resp, &agentgrpc.Capabilities{
+		ProtocolVersion: agentgrpc.ProtocolVersion,
+		EventTypes:      []string{agentgrpc.EventTypeBlock},
+	}))
+	s.botGrpc.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(resp, nil)
+	s.lifecycleMetrics.EXPECT().StatusInitialized(s.botClient.configUnsafe)
+
+	s.botClient.Initialize()
+	s.r.True(s.botClient.IsInitialized())
+
+	// the bot should receive only the blocks
+	s.r.False(s.botClient.ShouldProcessTxEvent(&protocol.TransactionEvent{}))
+	s.r.False(s.botClient.ShouldProcessLogEvent(&protocol.TransactionEvent{}))
+	s.r.True(s.botClient.ShouldProcessBlockEvent(&protocol.BlockEvent{}))
+}
+
+func (s *BotClientSuite) TestInitialize_Redial() {
+	MinRedialBackoff = time.Millisecond
+	dialer := mock_agentgrpc.NewMockBotDialer(gomock.NewController(s.T()))
+	s.botClient.dialer = dialer
+	botConfig := s.botClient.configUnsafe
+
+	dialErr := errors.New("failed to dial")
+	s.lifecycleMetrics.EXPECT().ClientDial(botConfig).Times(2)
+	dialer.EXPECT().DialBot(botConfig).Return(nil, dialErr)
+	s.lifecycleMetrics.EXPECT().FailureDial(dialErr, botConfig)
+	dialer.EXPECT().DialBot(botConfig).Return(s.botGrpc, nil)
+	s.lifecycleMetrics.EXPECT().StatusAttached(botConfig)
+	s.botGrpc.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(&protocol.InitializeResponse{}, nil)
+	s.lifecycleMetrics.EXPECT().StatusInitialized(botConfig)
+
+	s.botClient.Initialize()
+	s.r.True(s.botClient.IsDegraded())
+
+	select {
+	case <-s.botClient.Initialized():
+	case <-time.After(time.Second):
+		s.r.FailNow("bot was not redialed")
+	}
+	s.r.False(s.botClient.IsDegraded())
+}
+
+func (s *BotClientSuite) TestInvokeWithRetry() {
+	s.botClient.requestOpts.RetryBackoff = time.Millisecond
+	lg := log.WithField("test", "retry")
+	req := &protocol.EvaluateTxRequest{}
+	resp := &protocol.EvaluateTxResponse{}
+
+	// transient errors should be retried
+	gomock.InOrder(
+		s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, resp).
+			Return(status.Error(codes.Unavailable, "unavailable")),
+		s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, resp).Return(nil),
+	)
+	s.r.NoError(s.botClient.invokeWithRetry(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+
+	// attempts should be limited
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, resp).
+		Return(status.Error(codes.Unavailable, "unavailable")).Times(DefaultMaxAttempts)
+	s.r.Error(s.botClient.invokeWithRetry(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+
+	// other errors should not be retried
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, resp).
+		Return(status.Error(codes.InvalidArgument, "bad request")).Times(1)
+	s.r.Error(s.botClient.invokeWithRetry(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+}
+
+// TestTxStreams tests sending the tx requests through the streams and falling back to unary calls.
+func (s *BotClientSuite) TestTxStreams() {
+	s.botClient.requestOpts.TxStreams = true
+	lg := log.WithField("test", "stream")
+	req := &protocol.EvaluateTxRequest{}
+	resp := &protocol.EvaluateTxResponse{}
+
+	// the idle stream should be reused by the next request
+	stream := mock_agentgrpc.NewMockTxStream(gomock.NewController(s.T()))
+	s.botGrpc.EXPECT().EvaluateTxStream(gomock.Any()).Return(stream, nil).Times(1)
+	stream.EXPECT().Send(req).Return(nil).Times(2)
+	stream.EXPECT().RecvMsg(resp).Return(nil).Times(2)
+	s.r.NoError(s.botClient.call(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+	s.r.NoError(s.botClient.call(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+
+	// a failed stream should be replaced
+	stream.EXPECT().Send(req).Return(status.Error(codes.Unavailable, "unavailable"))
+	s.r.Error(s.botClient.call(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+
+	// unary calls should be made if the bot does not support streaming
+	stream2 := mock_agentgrpc.NewMockTxStream(gomock.NewController(s.T()))
+	s.botGrpc.EXPECT().EvaluateTxStream(gomock.Any()).Return(stream2, nil).Times(1)
+	stream2.EXPECT().Send(req).Return(nil)
+	stream2.EXPECT().RecvMsg(resp).Return(status.Error(codes.Unimplemented, "unimplemented"))
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, resp).Return(nil).Times(2)
+	s.r.NoError(s.botClient.call(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+	s.r.NoError(s.botClient.call(context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req, resp))
+}
+
+// TestTxBatches tests sending the tx requests in batches and falling back to unary calls.
+func (s *BotClientSuite) TestTxBatches() {
+	s.botClient.requestOpts.BatchSize = 2
+	s.botClient.requestOpts.BatchWait = time.Minute
+	s.botClient.setGrpcClient(s.botGrpc)
+	s.botClient.setInitialized()
+	s.msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+	lg := log.WithField("test", "batch")
+
+	txRequest := func(hash string) *botreq.TxRequest {
+		return &botreq.TxRequest{
+			Original: &protocol.EvaluateTxRequest{
+				RequestId: hash,
+				Event: &protocol.TransactionEvent{
+					Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+					Transaction: &protocol.TransactionEvent_EthTransaction{Hash: hash},
+				},
+			},
+		}
+	}
+
+	// the full batch should be sent in one call
+	s.botClient.TxRequestCh() <- txRequest("0x1")
+	s.botClient.TxRequestCh() <- txRequest("0x2")
+	batch, ok := s.botClient.collectTxBatch()
+	s.r.True(ok)
+	s.r.Len(batch, 2)
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTxBatch, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, method agentgrpc.Method, in, out interface{}, opts ...interface{}) error {
+			s.r.Len(in.(*agentgrpc.EvaluateTxBatchRequest).Requests, 2)
+			out.(*agentgrpc.EvaluateTxBatchResponse).Responses = []*protocol.EvaluateTxResponse{
+				{Findings: []*protocol.Finding{{Name: "first"}}},
+				{Findings: []*protocol.Finding{{Name: "second"}}},
+			}
+			return nil
+		})
+	exit := make(chan bool, 1)
+	go func() { exit <- s.botClient.processTxBatch(lg, batch) }()
+	for _, name := range []string{"first", "second"} {
+		result := <-s.resultChannels.Tx
+		s.r.Equal(name, result.Response.Findings[0].Name)
+	}
+	s.r.False(<-exit)
+	s.r.True(s.botClient.IsIdle())
+
+	// the batch should be sent with unary calls if the bot does not support batches
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTxBatch, gomock.Any(), gomock.Any()).
+		Return(status.Error(codes.Unimplemented, "unimplemented"))
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	go func() {
+		exit <- s.botClient.processTxBatch(lg, []*botreq.TxRequest{txRequest("0x3"), txRequest("0x4")})
+	}()
+	<-s.resultChannels.Tx
+	<-s.resultChannels.Tx
+	s.r.False(<-exit)
+	s.r.True(s.botClient.txBatchUnsupported.Load())
+}
+
+// TestHealthCheck tests pausing and resuming the requests to a bot based on the health checks.
+func (s *BotClientSuite) TestHealthCheck() {
+	s.botClient.requestOpts.HealthCheckFailureThreshold = 2
+	s.botClient.setGrpcClient(s.botGrpc)
+	s.botClient.setInitialized()
+	s.r.True(s.botClient.IsReady())
+
+	// a single failure should not make the bot unready
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodHealthCheck, gomock.Any(), gomock.Any()).
+		Return(status.Error(codes.Unavailable, "unavailable"))
+	s.r.True(s.botClient.checkHealth())
+	s.r.True(s.botClient.IsReady())
+
+	// error status in the response should count as a failure
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodHealthCheck, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, method agentgrpc.Method, in, out interface{}, opts ...grpc.CallOption) error {
+			out.(*protocol.HealthCheckResponse).Status = protocol.HealthCheckResponse_ERROR
+			return nil
+		})
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	s.r.True(s.botClient.checkHealth())
+	s.r.False(s.botClient.IsReady())
+
+	// the first success should reinstate the bot
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodHealthCheck, gomock.Any(), gomock.Any()).Return(nil)
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	s.r.True(s.botClient.checkHealth())
+	s.r.True(s.botClient.IsReady())
+
+	// unimplemented health check should not affect the bot
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodHealthCheck, gomock.Any(), gomock.Any()).
+		Return(status.Error(codes.Unimplemented, "unimplemented"))
+	s.r.False(s.botClient.checkHealth())
+	s.r.True(s.botClient.IsReady())
+}
+
+// TestPerformanceScore tests disabling a bot with a low performance score and enabling it after the probation.
+func (s *BotClientSuite) TestPerformanceScore() {
+	opts := PerformanceOptions{WindowSize: 4, MinScore: 0.5, LatencyTarget: time.Second, ProbationChecks: 2}
+	s.botClient.requestOpts.Performance = &opts
+	s.botClient.performance = newPerformanceWindow(opts)
+	s.botClient.setGrpcClient(s.botGrpc)
+	s.botClient.setInitialized()
+
+	// the slow responses lower the score
+	s.botClient.recordPerformance(nil, 2*time.Second)
+	score, ok := s.botClient.PerformanceScore()
+	s.r.True(ok)
+	s.r.Equal(0.5, score.Score)
+	s.r.Equal(float64(2000), score.AvgLatencyMs)
+
+	// the score is not checked until the window is full
+	s.botClient.recordPerformance(status.Error(codes.DeadlineExceeded, "timeout"), time.Second)
+	s.botClient.recordPerformance(status.Error(codes.Unimplemented, "unimplemented"), time.Second)
+	s.botClient.recordPerformance(errors.New("failed"), time.Second)
+	s.r.True(s.botClient.IsReady())
+
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusDisabled, gomock.Any()).
+		Do(func(subject string, payload interface{}) {
+			s.r.Equal(0.25, payload.(messaging.AgentPerformancePayload).TimeoutRate)
+		})
+	s.botClient.recordPerformance(nil, time.Second)
+	s.r.True(s.botClient.IsDisabled())
+	s.r.False(s.botClient.IsReady())
+
+	// a failed health check should restart the probation
+	s.botClient.setHealthCheckResult(nil)
+	s.botClient.setHealthCheckResult(errors.New("failed"))
+	s.botClient.setHealthCheckResult(nil)
+	s.r.True(s.botClient.IsDisabled())
+
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	s.botClient.setHealthCheckResult(nil)
+	s.r.False(s.botClient.IsDisabled())
+	s.r.True(s.botClient.IsReady())
+	score, _ = s.botClient.PerformanceScore()
+	s.r.Equal(0, score.Requests)
+}
+
+func TestProcessRequests_Concurrency(t *testing.T) {
+	r := require.New(t)
+
+	testCases := []struct {
+		name        string
+		concurrency int
+		semaphore   Semaphore
+		expected    int64
+	}{
+		{name: "workers", concurrency: 3, expected: 3},
+		{name: "semaphore", concurrency: 3, semaphore: NewSemaphore(2), expected: 2},
+	}
+
+	for _, testCase := range testCases {
+		ctx, cancel := context.WithCancel(context.Background())
+		reqCh := make(chan *int, 10)
+		for i := 0; i < 6; i++ {
+			reqCh <- new(int)
+		}
+
+		var (
+			inFlight, current, max int64
+			done                   sync.WaitGroup
+		)
+		done.Add(6)
+		go processRequests(ctx, reqCh, &inFlight, RequestOptions{
+			Timeout:     time.Minute,
+			Concurrency: testCase.concurrency,
+			Semaphore:   testCase.semaphore,
+		}, log.WithField("test", testCase.name), func(ctx context.Context, lg *log.Entry, req *int) bool {
+			n := atomic.AddInt64(&current, 1)
+			for {
+				prev := atomic.LoadInt64(&max)
+				if n <= prev || atomic.CompareAndSwapInt64(&max, prev, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt64(&current, -1)
+			done.Done()
+			return false
+		})
+		done.Wait()
+		cancel()
+		r.Equal(testCase.expected, atomic.LoadInt64(&max), testCase.name)
+	}
+}
+
+func TestEnqueueRequest(t *testing.T) {
+	r := require.New(t)
+
+	done := make(chan struct{})
+	first, second := 1, 2
+
+	// drop the new request
+	reqCh := make(chan *int, 1)
+	r.False(enqueueRequest(done, reqCh, &first, config.BackpressureDropNewest))
+	r.True(enqueueRequest(done, reqCh, &second, config.BackpressureDropNewest))
+	r.Equal(&first, <-reqCh)
+
+	// drop the oldest request
+	r.False(enqueueRequest(done, reqCh, &first, config.BackpressureDropOldest))
+	r.True(enqueueRequest(done, reqCh, &second, config.BackpressureDropOldest))
+	r.Equal(&second, <-reqCh)
+
+	// block until there is room
+	r.False(enqueueRequest(done, reqCh, &first, config.BackpressureBlock))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-reqCh
+	}()
+	r.False(enqueueRequest(done, reqCh, &second, config.BackpressureBlock))
+	r.Equal(&second, <-reqCh)
+
+	// stop blocking when done
+	reqCh <- &first
+	close(done)
+	r.False(enqueueRequest(done, reqCh, &second, config.BackpressureBlock))
+}
+
+// TestTxOverflow tests spilling the tx requests to the disk when the buffer is full.
+func TestTxOverflow(t *testing.T) {
+	r := require.New(t)
+
+	lifecycleMetrics := mock_metrics.NewMockLifecycle(gomock.NewController(t))
+	lifecycleMetrics.EXPECT().ClientClose(gomock.Any())
+	overflow := store.NewOverflowQueue(t.TempDir(), 0)
+	botClient := NewBotClient(
+		context.Background(), config.AgentConfig{ID: testBotID}, nil, lifecycleMetrics, nil,
+		botreq.MakeResultChannels().SendOnly(), RequestOptions{TxBufferSize: 1, TxOverflow: overflow},
+	)
+
+	for i := 0; i < 5; i++ {
+		r.False(botClient.EnqueueTxRequest(&botreq.TxRequest{
+			Original: &protocol.EvaluateTxRequest{RequestId: fmt.Sprintf("request-%d", i)},
+		}))
+	}
+	r.Equal(4, botClient.overflowLen())
+
+	// the requests should be received in order after the buffer frees up
+	for i := 0; i < 5; i++ {
+		select {
+		case req := <-botClient.txRequests:
+			r.Equal(fmt.Sprintf("request-%d", i), req.Original.RequestId)
+		case <-time.After(5 * time.Second):
+			r.FailNow("request is not received")
+		}
+	}
+	r.Eventually(func() bool { return botClient.overflowLen() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	// the spilled requests should be pending after the bot is closed
+	r.False(botClient.EnqueueTxRequest(&botreq.TxRequest{Original: &protocol.EvaluateTxRequest{RequestId: "in-buffer"}}))
+	r.False(botClient.EnqueueTxRequest(&botreq.TxRequest{Original: &protocol.EvaluateTxRequest{RequestId: "on-disk"}}))
+	r.NoError(botClient.Close())
+	r.Eventually(func() bool { return len(botClient.PendingRequests()) == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestDroppedRequestWarning tests that the dropped requests are warned about periodically.
+func TestDroppedRequestWarning(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	lifecycleMetrics := mock_metrics.NewMockLifecycle(ctrl)
+	botConfig := config.AgentConfig{ID: testBotID}
+	botClient := NewBotClient(
+		context.Background(), botConfig, msgClient, lifecycleMetrics, nil,
+		botreq.MakeResultChannels().SendOnly(), RequestOptions{BackpressurePolicy: config.BackpressureDropNewest},
+	)
+
+	// should warn only once within the interval
+	msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusThrottled, messaging.AgentThrottlePayload{
+		Agent:   botConfig,
+		Policy:  config.BackpressureDropNewest,
+		Dropped: 1,
+	})
+	for i := 0; i < DefaultBufferSize+2; i++ {
+		botClient.EnqueueBlockRequest(&botreq.BlockRequest{})
+	}
+	r.Equal(uint64(2), atomic.LoadUint64(&botClient.dropped))
+}
+
+// TestBotClient_AgentServer tests the bot client end-to-end with an in-process bot.
+func TestBotClient_AgentServer(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	msgClient.EXPECT().Publish(gomock.Any(), gomock.Any()).AnyTimes()
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+
+	server := agentserver.New(t)
+	server.SetBehavior(agentgrpc.MethodEvaluateTx, agentserver.Behavior{
+		Findings: []*protocol.Finding{{Name: "finding", Severity: protocol.Finding_HIGH}},
+	})
+
+	resultChannels := botreq.MakeResultChannels()
+	botClient := NewBotClient(
+		context.Background(), config.AgentConfig{ID: testBotID}, msgClient,
+		metrics.NewLifecycleClient(msgClient), server.Dialer(), resultChannels.SendOnly(), RequestOptions{},
+	)
+	defer botClient.Close()
+	botClient.StartProcessing()
+	botClient.Initialize()
+	select {
+	case <-botClient.Initialized():
+	case <-time.After(5 * time.Second):
+		r.FailNow("bot is not initialized")
+	}
+	r.Len(server.InitializeRequests(), 1)
+
+	for _, hash := range []string{"0x1", "0x2"} {
+		botClient.TxRequestCh() <- &botreq.TxRequest{
+			Original: &protocol.EvaluateTxRequest{
+				RequestId: hash,
+				Event: &protocol.TransactionEvent{
+					Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+					Transaction: &protocol.TransactionEvent_EthTransaction{Hash: hash},
+				},
+			},
+		}
+		result := <-resultChannels.Tx
+		r.Len(result.Response.Findings, 1)
+	}
+	server.RequireTxHashes(t, "0x1", "0x2")
+}
+
+func TestWithRequestID(t *testing.T) {
+	r := require.New(t)
+
+	ctx := withRequestID(context.Background(), &protocol.EvaluateTxRequest{RequestId: testRequestID})
+	md, ok := metadata.FromOutgoingContext(ctx)
+	r.True(ok)
+	r.Equal([]string{testRequestID}, md.Get(agentgrpc.RequestIDKey))
+
+	ctx = withRequestID(context.Background(), &protocol.EvaluateBlockRequest{})
+	_, ok = metadata.FromOutgoingContext(ctx)
+	r.False(ok)
+}
+
+// TestPendingRequests tests that the buffered requests are pending after the bot is closed.
+func (s *BotClientSuite) TestPendingRequests() {
+	s.botClient.TxRequestCh() <- &botreq.TxRequest{
+		Original: &protocol.EvaluateTxRequest{
+			RequestId: "tx",
+			Event: &protocol.TransactionEvent{
+				Network: &protocol.TransactionEvent_Network{ChainId: "0x89"},
+				Block:   &protocol.TransactionEvent_EthBlock{BlockNumber: "0x10"},
+			},
+		},
+	}
+	s.botClient.BlockRequestCh() <- &botreq.BlockRequest{
+		Original: &protocol.EvaluateBlockRequest{
+			RequestId: "block",
+			Event: &protocol.BlockEvent{
+				Network:     &protocol.BlockEvent_Network{ChainId: "0x89"},
+				BlockNumber: "0xf",
+			},
+		},
+	}
+	// the buffers are not consumed while the bot is open
+	s.r.Empty(s.botClient.PendingRequests())
+
+	s.lifecycleMetrics.EXPECT().ClientClose(s.botClient.configUnsafe)
+	s.r.NoError(s.botClient.Close())
+
+	pending := s.botClient.PendingRequests()
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].BlockNumber < pending[j].BlockNumber
+	})
+	s.r.Equal([]PendingRequest{
+		{RequestID: "block", ChainID: 137, BlockNumber: 15},
+		{RequestID: "tx", ChainID: 137, BlockNumber: 16},
+	}, pending)
+	s.r.Len(s.botClient.PendingRequests(), 2)
+}
+
+// TestWarmUp tests replaying the requests of the last blocks to the bot and discarding the findings.
+func (s *BotClientSuite) TestWarmUp() {
+	warmUp := NewWarmUp(2, nil)
+	for _, blockNumber := range []string{"0x1", "0x2", "0x3"} {
+		warmUp.recordTx(&botreq.TxRequest{
+			Original: &protocol.EvaluateTxRequest{
+				RequestId: "tx" + blockNumber,
+				Event: &protocol.TransactionEvent{
+					Network:     &protocol.TransactionEvent_Network{ChainId: "0x1"},
+					Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: blockNumber},
+					Transaction: &protocol.TransactionEvent_EthTransaction{Hash: blockNumber},
+				},
+			},
+		})
+		warmUp.recordBlock(&botreq.BlockRequest{
+			Original: &protocol.EvaluateBlockRequest{
+				RequestId: "block" + blockNumber,
+				Event: &protocol.BlockEvent{
+					Network:     &protocol.BlockEvent_Network{ChainId: "0x1"},
+					BlockNumber: blockNumber,
+				},
+			},
+		})
+	}
+	s.botClient.requestOpts.WarmUp = warmUp
+	s.botClient.setGrpcClient(s.botGrpc)
+
+	var requestIDs []string
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, method agentgrpc.Method, in, out interface{}, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			s.r.Equal([]string{"true"}, md.Get(agentgrpc.WarmUpKey))
+			requestIDs = append(requestIDs, md.Get(agentgrpc.RequestIDKey)[0])
+			switch resp := out.(type) {
+			case *protocol.EvaluateTxResponse:
+				resp.Findings = []*protocol.Finding{{Name: "warm-up"}}
+			case *protocol.EvaluateBlockResponse:
+				resp.Findings = []*protocol.Finding{{Name: "warm-up"}}
+			}
+			return nil
+		}).Times(4)
+
+	s.botClient.warmUp()
+
+	// only the last blocks are replayed and the findings are discarded
+	s.r.Equal([]string{"tx0x2", "block0x2", "tx0x3", "block0x3"}, requestIDs)
+	s.r.True(isChanClosed(s.botClient.WarmedUp()))
+	s.r.Len(s.resultChannels.Tx, 0)
+	s.r.Len(s.resultChannels.Block, 0)
+
+	// the warm-up is done only once
+	s.botClient.warmUp()
+}
+
+// TestWarmUp_Timeout tests that the bot stops the warm-up after the timeout.
+func (s *BotClientSuite) TestWarmUp_Timeout() {
+	warmUp := NewWarmUp(10, nil)
+	for _, blockNumber := range []string{"0x1", "0x2"} {
+		warmUp.recordBlock(&botreq.BlockRequest{
+			Original: &protocol.EvaluateBlockRequest{
+				Event: &protocol.BlockEvent{BlockNumber: blockNumber},
+			},
+		})
+	}
+	s.botClient.requestOpts.WarmUp = warmUp
+	s.botClient.requestOpts.WarmUpTimeout = time.Millisecond
+	s.botClient.setGrpcClient(s.botGrpc)
+
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateBlock, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, method agentgrpc.Method, in, out interface{}, opts ...grpc.CallOption) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).Times(1)
+
+	s.botClient.warmUp()
+	s.r.True(isChanClosed(s.botClient.WarmedUp()))
+}
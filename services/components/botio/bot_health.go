@@ -0,0 +1,103 @@
+package botio
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IsReady tells if the bot is initialized, not closed, not unhealthy and not disabled, so that new
+// requests can be sent.
+func (bot *botClient) IsReady() bool {
+	return bot.IsInitialized() && !bot.IsClosed() && !bot.unhealthy.Load() && !bot.disabled.Load()
+}
+
+// checkHealthPeriodically checks the health of the bot until the bot is closed. It stops checking
+// if the bot does not implement the health check method. The disabled bots which can not be checked
+// pass a probation check each time a check is due.
+func (bot *botClient) checkHealthPeriodically() {
+	interval := bot.requestOpts.HealthCheckInterval
+	checks := interval > 0
+	if !checks {
+		interval = defaultProbationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bot.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if checks && !bot.checkHealth() {
+			log.WithField("bot", bot.Config().ID).Debug("health check not implemented in bot - safe to ignore")
+			checks = false
+			if bot.performance == nil {
+				return
+			}
+		}
+		if !checks {
+			bot.probationCheck(nil)
+		}
+	}
+}
+
+// checkHealth invokes the health check method of the bot and tells if it is supported.
+func (bot *botClient) checkHealth() (supported bool) {
+	ctx, cancel := context.WithTimeout(bot.ctx, bot.requestOpts.Timeout)
+	defer cancel()
+
+	resp := new(protocol.HealthCheckResponse)
+	err := bot.grpcClient().Invoke(ctx, agentgrpc.MethodHealthCheck, &protocol.HealthCheckRequest{}, resp)
+	if status.Code(err) == codes.Unimplemented {
+		return false
+	}
+	if bot.IsClosed() {
+		return true
+	}
+	if err == nil && resp.Status == protocol.HealthCheckResponse_ERROR {
+		err = agentgrpc.Error(resp.Errors)
+	}
+	bot.setHealthCheckResult(err)
+	return true
+}
+
+// setHealthCheckResult pulls the bot out of the dispatch set after too many consecutive failures
+// and reinstates it with the first successful check.
+func (bot *botClient) setHealthCheckResult(err error) {
+	bot.probationCheck(err)
+
+	botConfig := bot.Config()
+	logger := log.WithField("bot", botConfig.ID)
+
+	if err == nil {
+		atomic.StoreUint32(&bot.healthCheckFailures, 0)
+		if bot.unhealthy.CompareAndSwap(true, false) {
+			logger.Info("bot is healthy again - resuming requests")
+			metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+				metrics.CreateAgentMetric(botConfig, metrics.MetricHealthRecovered, 1),
+			})
+		}
+		return
+	}
+
+	failures := atomic.AddUint32(&bot.healthCheckFailures, 1)
+	logger = logger.WithError(err).WithField("failures", failures)
+	if uint(failures) < bot.requestOpts.HealthCheckFailureThreshold {
+		logger.Debug("bot health check failed")
+		return
+	}
+	if bot.unhealthy.CompareAndSwap(false, true) {
+		logger.Warn("too many failed health checks - pausing requests")
+		metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botConfig, metrics.MetricHealthFailing, 1),
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package botio
+
+import (
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// enqueueTxRequestWithOverflow sends the tx request to the buffer or spills it to the disk if the
+// buffer is full. The requests stay on the disk until the bot catches up, so the request order is
+// kept by spilling the new requests as long as there are requests on the disk. The backpressure
+// policy is applied only if the overflow queue does not have space either.
+func (bot *botClient) enqueueTxRequestWithOverflow(req *botreq.TxRequest) (dropped bool) {
+	bot.overflowMu.Lock()
+	defer bot.overflowMu.Unlock()
+
+	if bot.overflowed == 0 {
+		select {
+		case bot.txRequests <- req:
+			return false
+		default:
+		}
+	}
+
+	b, err := proto.Marshal(req.Original)
+	if err == nil {
+		err = bot.requestOpts.TxOverflow.Push(b)
+	}
+	if err == nil {
+		bot.overflowed++
+		select {
+		case bot.overflowSignal <- struct{}{}:
+		default:
+		}
+		return false
+	}
+
+	log.WithField("bot", bot.Config().ID).WithError(err).Debug("failed to spill the tx request to the disk")
+	return enqueueRequest(bot.Closed(), bot.txRequests, req, bot.requestOpts.BackpressurePolicy)
+}
+
+// refillTxRequests moves the tx requests from the disk to the buffer as the buffer frees up.
+func (bot *botClient) refillTxRequests() {
+	lg := log.WithFields(log.Fields{
+		"bot":       bot.Config().ID,
+		"component": "bot-client",
+	})
+	for {
+		select {
+		case <-bot.ctx.Done():
+			return
+		case <-bot.overflowSignal:
+		}
+
+		for {
+			b, ok, err := bot.requestOpts.TxOverflow.Pop()
+			if err != nil {
+				lg.WithError(err).Warn("failed to read the tx request from the disk")
+			}
+			if !ok {
+				break
+			}
+			req := new(protocol.EvaluateTxRequest)
+			if err := proto.Unmarshal(b, req); err != nil {
+				lg.WithError(err).Warn("failed to decode the tx request from the disk")
+				bot.overflowDone()
+				continue
+			}
+			select {
+			case <-bot.ctx.Done():
+				bot.pending.add(pendingTxRequest(req))
+				return
+			case bot.txRequests <- &botreq.TxRequest{Original: req}:
+				bot.overflowDone()
+			}
+		}
+	}
+}
+
+func (bot *botClient) overflowDone() {
+	bot.overflowMu.Lock()
+	defer bot.overflowMu.Unlock()
+
+	bot.overflowed--
+}
+
+// overflowLen returns the amount of the tx requests which were spilled to the disk and not yet
+// moved to the buffer.
+func (bot *botClient) overflowLen() int {
+	bot.overflowMu.Lock()
+	defer bot.overflowMu.Unlock()
+
+	return bot.overflowed
+}
+
+// closeOverflow keeps the spilled requests as pending and deletes them from the disk.
+func (bot *botClient) closeOverflow() {
+	for {
+		b, ok, err := bot.requestOpts.TxOverflow.Pop()
+		if err != nil || !ok {
+			break
+		}
+		req := new(protocol.EvaluateTxRequest)
+		if err := proto.Unmarshal(b, req); err == nil {
+			bot.pending.add(pendingTxRequest(req))
+		}
+	}
+	if err := bot.requestOpts.TxOverflow.Close(); err != nil {
+		log.WithField("bot", bot.Config().ID).WithError(err).Warn("failed to delete the tx overflow")
+	}
+}
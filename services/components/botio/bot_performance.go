@@ -0,0 +1,196 @@
+package botio
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the probation checks of the bots are due with this interval if the health checks are disabled
+const defaultProbationInterval = 30 * time.Second
+
+// PerformanceOptions contains the thresholds of the bot performance scores.
+type PerformanceOptions struct {
+	// the amount of the last requests which the score is calculated from
+	WindowSize int
+	// the bots with lower scores are disabled
+	MinScore float64
+	// the average latencies above the target lower the score
+	LatencyTarget time.Duration
+	// the consecutive successful health checks which enable the disabled bots again
+	ProbationChecks int
+}
+
+type performanceSample struct {
+	timeout bool
+	failed  bool
+	latency time.Duration
+}
+
+// PerformanceScore is the rolling performance of a bot in the window of its last requests.
+type PerformanceScore struct {
+	Score        float64 `json:"score"`
+	TimeoutRate  float64 `json:"timeoutRate"`
+	ErrorRate    float64 `json:"errorRate"`
+	AvgLatencyMs float64 `json:"avgLatencyMs"`
+	Requests     int     `json:"requests"`
+}
+
+// performanceWindow keeps the outcomes of the last requests of a bot.
+type performanceWindow struct {
+	opts    PerformanceOptions
+	samples []performanceSample
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+func newPerformanceWindow(opts PerformanceOptions) *performanceWindow {
+	return &performanceWindow{opts: opts, samples: make([]performanceSample, opts.WindowSize)}
+}
+
+// add adds the sample and tells if the window is full.
+func (pw *performanceWindow) add(sample performanceSample) bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.samples[pw.next] = sample
+	pw.next = (pw.next + 1) % len(pw.samples)
+	if pw.next == 0 {
+		pw.full = true
+	}
+	return pw.full
+}
+
+func (pw *performanceWindow) reset() {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.next = 0
+	pw.full = false
+}
+
+// score calculates the score from the success rate, which is lowered by the timeouts and the errors,
+// and from the average latency, which lowers the score as it gets above the latency target.
+func (pw *performanceWindow) score() PerformanceScore {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	samples := pw.samples[:pw.next]
+	if pw.full {
+		samples = pw.samples
+	}
+	if len(samples) == 0 {
+		return PerformanceScore{Score: 1}
+	}
+	var (
+		timeouts, failures int
+		totalLatency       time.Duration
+	)
+	for _, sample := range samples {
+		switch {
+		case sample.timeout:
+			timeouts++
+		case sample.failed:
+			failures++
+		}
+		totalLatency += sample.latency
+	}
+	n := float64(len(samples))
+	avgLatency := totalLatency / time.Duration(len(samples))
+	score := PerformanceScore{
+		TimeoutRate:  float64(timeouts) / n,
+		ErrorRate:    float64(failures) / n,
+		AvgLatencyMs: float64(avgLatency.Milliseconds()),
+		Requests:     len(samples),
+	}
+	score.Score = 1 - score.TimeoutRate - score.ErrorRate
+	if avgLatency > pw.opts.LatencyTarget {
+		score.Score *= float64(pw.opts.LatencyTarget) / float64(avgLatency)
+	}
+	return score
+}
+
+// PerformanceScore returns the performance score of the bot. It returns false if the performance
+// scoring is disabled.
+func (bot *botClient) PerformanceScore() (PerformanceScore, bool) {
+	if bot.performance == nil {
+		return PerformanceScore{}, false
+	}
+	return bot.performance.score(), true
+}
+
+// IsDisabled tells if the bot is disabled because of its low performance score.
+func (bot *botClient) IsDisabled() bool {
+	return bot.disabled.Load()
+}
+
+// recordPerformance adds the outcome of the request to the performance window and disables the
+// bot if the score of the full window falls below the min score.
+func (bot *botClient) recordPerformance(err error, latency time.Duration) {
+	if bot.performance == nil || status.Code(err) == codes.Unimplemented || err == errCircuitOpen {
+		return
+	}
+	full := bot.performance.add(performanceSample{
+		timeout: status.Code(err) == codes.DeadlineExceeded,
+		failed:  err != nil,
+		latency: latency,
+	})
+	if !full || bot.disabled.Load() {
+		return
+	}
+	score := bot.performance.score()
+	if score.Score >= bot.requestOpts.Performance.MinScore || !bot.disabled.CompareAndSwap(false, true) {
+		return
+	}
+	atomic.StoreUint32(&bot.probationSuccesses, 0)
+
+	botConfig := bot.Config()
+	log.WithFields(log.Fields{
+		"bot":          botConfig.ID,
+		"score":        score.Score,
+		"timeoutRate":  score.TimeoutRate,
+		"errorRate":    score.ErrorRate,
+		"avgLatencyMs": score.AvgLatencyMs,
+	}).Warn("bot performance score is too low - disabling bot until the probation checks pass")
+	metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(botConfig, metrics.MetricPerformanceDisabled, score.Score),
+	})
+	bot.msgClient.Publish(messaging.SubjectAgentsStatusDisabled, messaging.AgentPerformancePayload{
+		Agent:        botConfig,
+		Score:        score.Score,
+		TimeoutRate:  score.TimeoutRate,
+		ErrorRate:    score.ErrorRate,
+		AvgLatencyMs: score.AvgLatencyMs,
+	})
+}
+
+// probationCheck enables the disabled bot again after enough consecutive successful checks.
+func (bot *botClient) probationCheck(err error) {
+	if bot.performance == nil || !bot.disabled.Load() {
+		return
+	}
+	if err != nil {
+		atomic.StoreUint32(&bot.probationSuccesses, 0)
+		return
+	}
+	successes := atomic.AddUint32(&bot.probationSuccesses, 1)
+	if int(successes) < bot.requestOpts.Performance.ProbationChecks {
+		return
+	}
+	// the score starts over so that the old requests do not disable the bot again
+	bot.performance.reset()
+	if !bot.disabled.CompareAndSwap(true, false) {
+		return
+	}
+	botConfig := bot.Config()
+	log.WithField("bot", botConfig.ID).Info("bot passed the probation checks - enabling bot")
+	metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(botConfig, metrics.MetricPerformanceEnabled, 1),
+	})
+}
@@ -0,0 +1,323 @@
+package botio
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/components/tracing"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Request retry and circuit breaker defaults
+const (
+	DefaultMaxAttempts             = 3
+	DefaultRetryBackoff            = 100 * time.Millisecond
+	DefaultCircuitBreakerThreshold = 10
+	DefaultCircuitBreakerCooldown  = time.Minute
+
+	DefaultHealthCheckFailureThreshold = 3
+)
+
+var errCircuitOpen = errors.New("bot circuit is open")
+
+// RequestOptions contains the options for the evaluation requests sent to a bot.
+type RequestOptions struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	RetryBackoff time.Duration
+
+	// Concurrency is the amount of concurrent requests per request type. The requests
+	// are not guaranteed to reach the bot in order if this is greater than one, so
+	// the bots should rely on the block number and the timestamps of the events.
+	Concurrency int
+	// Semaphore is shared by all bots to bound the total amount of concurrent requests.
+	Semaphore Semaphore
+	// BackpressurePolicy decides what happens when the request buffer is full.
+	BackpressurePolicy string
+
+	CircuitBreakerThreshold uint
+	CircuitBreakerCooldown  time.Duration
+
+	// HealthCheckInterval is the interval of the bot health checks. Zero disables the health checks.
+	HealthCheckInterval         time.Duration
+	HealthCheckFailureThreshold uint
+
+	// TxStreams enables sending the tx requests through long-lived streams. The bots which do not
+	// support streaming continue to receive unary calls.
+	TxStreams bool
+
+	// BatchSize is the max amount of the tx requests which are sent in one batch call. The batch is sent
+	// earlier if its oldest request waits for the batch wait time. The bots which do not support
+	// batching continue to receive the requests one by one. Zero or one disables batching.
+	BatchSize int
+	BatchWait time.Duration
+
+	// DeadLetters keeps the requests which could not be delivered. Nil disables the dead letters.
+	DeadLetters store.DeadLetterStore
+
+	// TxBufferSize is the amount of the tx requests which can wait to be sent to the bot.
+	TxBufferSize int
+	// TxOverflow keeps the tx requests which do not fit in the buffer on the disk. Nil disables
+	// the overflow, so the backpressure policy is applied when the buffer is full.
+	TxOverflow store.OverflowQueue
+
+	// WarmUp keeps the requests of the last blocks which are replayed to the bot after it is initialized.
+	// The live requests wait until the replay is done or the warm-up timeout. Nil disables the warm-up.
+	WarmUp        *WarmUp
+	WarmUpTimeout time.Duration
+
+	// SizeLimits splits the tx batches which exceed the max send size.
+	SizeLimits agentgrpc.SizeLimits
+
+	// Performance disables the bot when its performance score falls below the min score and enables it
+	// again after the probation checks. Nil disables the performance scoring.
+	Performance *PerformanceOptions
+}
+
+func (opts *RequestOptions) setDefaults() {
+	if opts.Timeout <= 0 {
+		opts.Timeout = RequestTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	if opts.TxBufferSize <= 0 {
+		opts.TxBufferSize = DefaultBufferSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if len(opts.BackpressurePolicy) == 0 {
+		opts.BackpressurePolicy = config.BackpressureDropNewest
+	}
+	if opts.CircuitBreakerThreshold == 0 {
+		opts.CircuitBreakerThreshold = DefaultCircuitBreakerThreshold
+	}
+	if opts.CircuitBreakerCooldown <= 0 {
+		opts.CircuitBreakerCooldown = DefaultCircuitBreakerCooldown
+	}
+	if opts.HealthCheckFailureThreshold == 0 {
+		opts.HealthCheckFailureThreshold = DefaultHealthCheckFailureThreshold
+	}
+	if opts.WarmUpTimeout <= 0 {
+		opts.WarmUpTimeout = DefaultWarmUpTimeout
+	}
+}
+
+// Semaphore bounds the amount of concurrent requests. A nil semaphore does not bound.
+type Semaphore chan struct{}
+
+// NewSemaphore creates a new semaphore. It returns nil if the size is not positive.
+func NewSemaphore(size int) Semaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(Semaphore, size)
+}
+
+// acquire blocks until there is room or the context is done, and tells if it was acquired.
+func (sem Semaphore) acquire(ctx context.Context) bool {
+	if sem == nil {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case sem <- struct{}{}:
+		return true
+	}
+}
+
+func (sem Semaphore) release() {
+	if sem != nil {
+		<-sem
+	}
+}
+
+// enqueueRequest sends the request to the request channel. If the channel is full, it either
+// blocks, drops the oldest request in the channel or drops the new request, depending on the policy.
+// It tells if any request was dropped.
+func enqueueRequest[R any](done <-chan struct{}, reqCh chan *R, req *R, policy string) (dropped bool) {
+	select {
+	case <-done:
+		return false
+	case reqCh <- req:
+		return false
+	default:
+	}
+
+	switch policy {
+	case config.BackpressureBlock:
+		select {
+		case <-done:
+		case reqCh <- req:
+		}
+		return false
+
+	case config.BackpressureDropOldest:
+		for {
+			select {
+			case <-reqCh:
+				dropped = true
+			default:
+			}
+			select {
+			case <-done:
+				return
+			case reqCh <- req:
+				return
+			default:
+			}
+		}
+
+	default:
+		return true
+	}
+}
+
+// isTransientErr tells if the request can be retried after the error.
+func isTransientErr(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// invoke invokes the bot unless the circuit of the bot is open. The circuit opens after too many
+// consecutive failures so that a failing bot does not waste the time of the node.
+func (bot *botClient) invoke(
+	ctx context.Context, lg *log.Entry, botClient agentgrpc.Client,
+	method agentgrpc.Method, in, out interface{}, dropMetric string,
+) error {
+	botConfig := bot.Config()
+	ctx, span := startEvaluateSpan(ctx, botConfig, method, in)
+	defer span.End()
+
+	if !bot.circuitBreaker.Allow() {
+		lg.Debug("bot circuit is open - dropping request")
+		metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botConfig, dropMetric, 1),
+		})
+		// not persisted since all requests are dropped while the circuit is open
+		return errCircuitOpen
+	}
+
+	startTime := time.Now()
+	err := bot.invokeWithRetry(withRequestID(tracing.InjectGRPC(ctx), in), lg, botClient, method, in, out)
+	bot.recordPerformance(err, time.Since(startTime))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	if err == nil || status.Code(err) == codes.Unimplemented {
+		if bot.circuitBreaker.Success() {
+			lg.Info("bot circuit is closed - resuming requests")
+			metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+				metrics.CreateAgentMetric(botConfig, metrics.MetricCircuitClosed, 1),
+			})
+		}
+		return err
+	}
+
+	bot.deadLetter(lg, botConfig, method, in, err)
+	if bot.circuitBreaker.Failure() {
+		lg.WithError(err).WithField("cooldown", bot.requestOpts.CircuitBreakerCooldown).
+			Warn("too many consecutive failures - bot circuit is open")
+		metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botConfig, metrics.MetricCircuitOpen, 1),
+		})
+	}
+	return err
+}
+
+// withRequestID adds the request ID to the outgoing gRPC metadata. Like the trace context,
+// it reaches the bots only with the unary calls.
+func withRequestID(ctx context.Context, in interface{}) context.Context {
+	var requestID string
+	switch req := in.(type) {
+	case *protocol.EvaluateTxRequest:
+		requestID = req.RequestId
+	case *protocol.EvaluateBlockRequest:
+		requestID = req.RequestId
+	case *protocol.EvaluateAlertRequest:
+		requestID = req.RequestId
+	}
+	if len(requestID) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, agentgrpc.RequestIDKey, requestID)
+}
+
+// startEvaluateSpan starts the evaluation span in the trace of the block. The trace context
+// reaches the bots in the gRPC metadata of the unary calls. The tx streams carry only
+// the trace context of the span which opened the stream.
+func startEvaluateSpan(
+	ctx context.Context, botConfig config.AgentConfig, method agentgrpc.Method, in interface{},
+) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("bot.id", botConfig.ID),
+		attribute.String("bot.image", botConfig.Image),
+		attribute.String("rpc.method", string(method)),
+	}
+	switch req := in.(type) {
+	case *protocol.EvaluateTxRequest:
+		ctx = tracing.BlockContext(ctx, req.GetEvent().GetBlock().GetBlockHash())
+		attrs = append(attrs,
+			attribute.String("request.id", req.RequestId),
+			attribute.String("tx.hash", req.GetEvent().GetTransaction().GetHash()),
+		)
+	case *protocol.EvaluateBlockRequest:
+		ctx = tracing.BlockContext(ctx, req.GetEvent().GetBlockHash())
+		attrs = append(attrs, attribute.String("request.id", req.RequestId))
+	case *protocol.EvaluateAlertRequest:
+		attrs = append(attrs,
+			attribute.String("request.id", req.RequestId),
+			attribute.String("alert.hash", req.GetEvent().GetAlert().GetHash()),
+		)
+	}
+	return tracing.Tracer().Start(ctx, tracing.SpanBotEvaluate, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// invokeWithRetry invokes the bot and retries with exponential backoff if the errors are transient.
+// The retries are bounded by the max attempts and the request context.
+func (bot *botClient) invokeWithRetry(
+	ctx context.Context, lg *log.Entry, botClient agentgrpc.Client,
+	method agentgrpc.Method, in, out interface{},
+) (err error) {
+	backoff := bot.requestOpts.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err = bot.call(ctx, lg, botClient, method, in, out)
+		if err == nil || !isTransientErr(err) || attempt >= bot.requestOpts.MaxAttempts {
+			return
+		}
+
+		lg.WithError(err).WithFields(log.Fields{
+			"attempt": attempt,
+			"backoff": backoff,
+		}).Warn("transient error while invoking bot - retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
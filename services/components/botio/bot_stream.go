@@ -0,0 +1,108 @@
+package botio
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// txStream is an idle tx evaluation stream which can be reused by the next request.
+type txStream struct {
+	client agentgrpc.Client
+	stream agentgrpc.TxStream
+	cancel context.CancelFunc
+}
+
+// call sends the tx requests through the tx streams if the bot supports streaming and makes
+// a unary call otherwise.
+func (bot *botClient) call(
+	ctx context.Context, lg *log.Entry, botClient agentgrpc.Client,
+	method agentgrpc.Method, in, out interface{},
+) error {
+	if method != agentgrpc.MethodEvaluateTx || !bot.requestOpts.TxStreams || bot.txStreamUnsupported.Load() {
+		return botClient.Invoke(ctx, method, in, out)
+	}
+
+	err := bot.evaluateTxStream(ctx, botClient, in.(*protocol.EvaluateTxRequest), out.(*protocol.EvaluateTxResponse))
+	if status.Code(err) != codes.Unimplemented {
+		return err
+	}
+	if bot.txStreamUnsupported.CompareAndSwap(false, true) {
+		lg.Info("bot does not support tx streams - falling back to unary calls")
+	}
+	return botClient.Invoke(ctx, method, in, out)
+}
+
+// evaluateTxStream sends the request through an idle stream or a new one if there are no idle
+// streams. The stream is reused only if the request succeeds so that a broken stream or a stream
+// with a late response is never reused.
+func (bot *botClient) evaluateTxStream(
+	ctx context.Context, botClient agentgrpc.Client,
+	req *protocol.EvaluateTxRequest, resp *protocol.EvaluateTxResponse,
+) error {
+	stream, err := bot.getTxStream(botClient)
+	if err != nil {
+		return err
+	}
+
+	// the stream outlives the request so cancel the stream if the request context is done first
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.cancel()
+		case <-done:
+		}
+	}()
+
+	err = stream.stream.Send(req)
+	if err == nil {
+		err = stream.stream.RecvMsg(resp)
+	}
+	close(done)
+
+	if err != nil || ctx.Err() != nil {
+		stream.cancel()
+		return err
+	}
+	bot.putTxStream(stream)
+	return nil
+}
+
+func (bot *botClient) getTxStream(botClient agentgrpc.Client) (*txStream, error) {
+	for {
+		select {
+		case stream := <-bot.txStreams:
+			// the streams of the previous connections are useless after redialing
+			if stream.client == botClient {
+				return stream, nil
+			}
+			stream.cancel()
+
+		default:
+			return bot.openTxStream(botClient)
+		}
+	}
+}
+
+func (bot *botClient) openTxStream(botClient agentgrpc.Client) (*txStream, error) {
+	ctx, cancel := context.WithCancel(bot.ctx)
+	stream, err := botClient.EvaluateTxStream(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &txStream{client: botClient, stream: stream, cancel: cancel}, nil
+}
+
+func (bot *botClient) putTxStream(stream *txStream) {
+	select {
+	case bot.txStreams <- stream:
+	default:
+		stream.cancel()
+	}
+}
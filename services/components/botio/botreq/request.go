@@ -12,6 +12,10 @@ type TxRequest struct {
 // BlockRequest contains the request data.
 type BlockRequest struct {
 	Original *protocol.EvaluateBlockRequest
+	// the request is from the beacon feed and is sent with the beacon method
+	Beacon bool
+	// the request is from the schedule feed and is sent with the schedule method
+	Scheduled bool
 }
 
 // CombinationRequest contains the request data.
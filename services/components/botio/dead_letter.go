@@ -0,0 +1,196 @@
+package botio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// deadLetter persists the request which could not be delivered to the bot. The requests of
+// the detached bots are not persisted because they were not expected to be delivered.
+func (bot *botClient) deadLetter(
+	lg *log.Entry, botConfig config.AgentConfig, method agentgrpc.Method, in interface{}, reason error,
+) {
+	if bot.requestOpts.DeadLetters == nil || bot.ctx.Err() != nil {
+		return
+	}
+	msg, ok := in.(proto.Message)
+	if !ok {
+		return
+	}
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		lg.WithError(err).Warn("failed to encode the dead letter request")
+		return
+	}
+	err = bot.requestOpts.DeadLetters.Put(&store.DeadLetter{
+		BotID:    botConfig.ID,
+		BotImage: botConfig.Image,
+		Method:   string(method),
+		Reason:   reason.Error(),
+		Request:  b,
+	})
+	if err != nil {
+		lg.WithError(err).Warn("failed to persist the dead letter")
+		return
+	}
+	metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(botConfig, metrics.MetricRequestDeadLetter, 1),
+	})
+}
+
+// DeadLetterReplayer sends the dead letters which are marked for replay to the bots again,
+// once the bots are ready.
+type DeadLetterReplayer struct {
+	ctx         context.Context
+	msgClient   clients.MessageClient
+	deadLetters store.DeadLetterStore
+	botPool     BotPool
+	interval    time.Duration
+}
+
+// NewDeadLetterReplayer creates a new dead letter replayer.
+func NewDeadLetterReplayer(
+	ctx context.Context, msgClient clients.MessageClient, deadLetters store.DeadLetterStore,
+	botPool BotPool, interval time.Duration,
+) *DeadLetterReplayer {
+	return &DeadLetterReplayer{
+		ctx:         ctx,
+		msgClient:   msgClient,
+		deadLetters: deadLetters,
+		botPool:     botPool,
+		interval:    interval,
+	}
+}
+
+// Start implements services.Service.
+func (dlr *DeadLetterReplayer) Start() error {
+	go func() {
+		ticker := time.NewTicker(dlr.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-dlr.ctx.Done():
+				return
+			case <-ticker.C:
+				dlr.replay()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (dlr *DeadLetterReplayer) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (dlr *DeadLetterReplayer) Name() string {
+	return "dead-letter-replayer"
+}
+
+// replay enqueues the marked dead letters and deletes them. The requests which can not
+// be delivered again become new dead letters.
+func (dlr *DeadLetterReplayer) replay() {
+	letters, err := dlr.deadLetters.List()
+	if err != nil {
+		log.WithError(err).Warn("failed to list the dead letters")
+		return
+	}
+	bots := dlr.botPool.GetCurrentBotClients()
+	for _, letter := range letters {
+		if !letter.Replay {
+			continue
+		}
+		logger := log.WithFields(log.Fields{
+			"bot":        letter.BotID,
+			"deadLetter": letter.ID,
+		})
+		bot, enqueue, err := prepareReplay(bots, letter)
+		if err != nil {
+			logger.WithError(err).Warn("deleting invalid dead letter")
+			_ = dlr.deadLetters.Delete(letter.ID)
+			continue
+		}
+		// wait until the bot is ready
+		if bot == nil || enqueue() {
+			continue
+		}
+		if err := dlr.deadLetters.Delete(letter.ID); err != nil && err != store.ErrDeadLetterNotFound {
+			logger.WithError(err).Warn("failed to delete the replayed dead letter")
+		}
+		logger.Info("replayed dead letter")
+		metrics.SendAgentMetrics(dlr.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(bot.Config(), metrics.MetricRequestReplay, 1),
+		})
+	}
+}
+
+// prepareReplay decodes the request of the dead letter and finds a ready bot client which should
+// receive the request. The returned function enqueues the request and tells if it was dropped.
+func prepareReplay(bots []BotClient, letter *store.DeadLetter) (BotClient, func() (dropped bool), error) {
+	switch agentgrpc.Method(letter.Method) {
+	case agentgrpc.MethodEvaluateTx:
+		req := new(protocol.EvaluateTxRequest)
+		if err := protojson.Unmarshal(letter.Request, req); err != nil {
+			return nil, nil, err
+		}
+		bot := findReplayBot(bots, letter.BotID, func(bot BotClient) bool {
+			return bot.ShouldProcessBlock(req.GetEvent().GetBlock().GetBlockNumber())
+		})
+		return bot, func() bool {
+			return bot.EnqueueTxRequest(&botreq.TxRequest{Original: req})
+		}, nil
+
+	case agentgrpc.MethodEvaluateBlock:
+		req := new(protocol.EvaluateBlockRequest)
+		if err := protojson.Unmarshal(letter.Request, req); err != nil {
+			return nil, nil, err
+		}
+		bot := findReplayBot(bots, letter.BotID, func(bot BotClient) bool {
+			return bot.ShouldProcessBlock(req.GetEvent().GetBlockNumber())
+		})
+		return bot, func() bool {
+			return bot.EnqueueBlockRequest(&botreq.BlockRequest{Original: req})
+		}, nil
+
+	case agentgrpc.MethodEvaluateAlert:
+		req := new(protocol.EvaluateAlertRequest)
+		if err := protojson.Unmarshal(letter.Request, req); err != nil {
+			return nil, nil, err
+		}
+		bot := findReplayBot(bots, letter.BotID, func(bot BotClient) bool {
+			return bot.ShouldProcessAlert(req.GetEvent())
+		})
+		return bot, func() bool {
+			return bot.EnqueueCombinationRequest(&botreq.CombinationRequest{Original: req})
+		}, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown dead letter method: %s", letter.Method)
+	}
+}
+
+// findReplayBot finds the ready client of the bot which should receive the request. The sharded
+// bots have a client for each shard.
+func findReplayBot(bots []BotClient, botID string, shouldProcess func(BotClient) bool) BotClient {
+	for _, bot := range bots {
+		if strings.EqualFold(bot.Config().ID, botID) && bot.IsReady() && !bot.IsDegraded() && shouldProcess(bot) {
+			return bot
+		}
+	}
+	return nil
+}
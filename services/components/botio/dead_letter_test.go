@@ -0,0 +1,112 @@
+package botio
+
+import (
+	"context"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/nodeutils"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type testBotPool []BotClient
+
+func (pool testBotPool) WaitForAll() {}
+
+func (pool testBotPool) GetCurrentBotClients() []BotClient {
+	return pool
+}
+
+// TestDeadLetters tests persisting the undeliverable requests and replaying them.
+func (s *BotClientSuite) TestDeadLetters() {
+	deadLetters := store.NewDeadLetterStore(s.T().TempDir(), 0)
+	s.botClient.requestOpts.DeadLetters = deadLetters
+	s.botClient.requestOpts.MaxAttempts = 1
+	lg := log.WithField("test", "dead-letters")
+	req := &protocol.EvaluateTxRequest{
+		RequestId: testRequestID,
+		Event: &protocol.TransactionEvent{
+			Block: &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+		},
+	}
+
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).AnyTimes()
+	s.botGrpc.EXPECT().Invoke(gomock.Any(), agentgrpc.MethodEvaluateTx, req, gomock.Any()).
+		Return(status.Error(codes.InvalidArgument, "bad request"))
+	s.r.Error(s.botClient.invoke(
+		context.Background(), lg, s.botGrpc, agentgrpc.MethodEvaluateTx, req,
+		&protocol.EvaluateTxResponse{}, metrics.MetricTxDrop,
+	))
+
+	letters, err := deadLetters.List()
+	s.r.NoError(err)
+	s.r.Len(letters, 1)
+	s.r.Equal(testBotID, letters[0].BotID)
+	s.r.Equal(string(agentgrpc.MethodEvaluateTx), letters[0].Method)
+	s.r.Contains(letters[0].Reason, "bad request")
+
+	replayer := NewDeadLetterReplayer(context.Background(), s.msgClient, deadLetters, testBotPool{s.botClient}, 0)
+
+	// the letters are not replayed unless they are marked
+	s.botClient.setInitialized()
+	replayer.replay()
+	s.r.Len(s.botClient.txRequests, 0)
+
+	// the marked letters are not replayed until the bot is ready
+	s.r.NoError(deadLetters.MarkReplay(letters[0].ID))
+	s.botClient.unhealthy.Store(true)
+	replayer.replay()
+	s.r.Len(s.botClient.txRequests, 0)
+
+	s.botClient.unhealthy.Store(false)
+	replayer.replay()
+	s.r.Len(s.botClient.txRequests, 1)
+	replayed := <-s.botClient.txRequests
+	s.r.True(proto.Equal(req, replayed.Original))
+
+	letters, err = deadLetters.List()
+	s.r.NoError(err)
+	s.r.Empty(letters)
+}
+
+// TestDeadLetters_Detached tests that the requests to the detached bots are not persisted.
+func (s *BotClientSuite) TestDeadLetters_Detached() {
+	deadLetters := store.NewDeadLetterStore(s.T().TempDir(), 0)
+	s.botClient.requestOpts.DeadLetters = deadLetters
+	s.botClient.ctxCancel()
+
+	s.botClient.deadLetter(
+		log.WithField("test", "dead-letters"), s.botClient.Config(), agentgrpc.MethodEvaluateBlock,
+		&protocol.EvaluateBlockRequest{}, errCircuitOpen,
+	)
+
+	letters, err := deadLetters.List()
+	s.r.NoError(err)
+	s.r.Empty(letters)
+}
+
+// TestDeadLetters_CircuitOpen tests that the requests dropped by the open circuit are not persisted.
+func (s *BotClientSuite) TestDeadLetters_CircuitOpen() {
+	deadLetters := store.NewDeadLetterStore(s.T().TempDir(), 0)
+	s.botClient.requestOpts.DeadLetters = deadLetters
+	s.botClient.circuitBreaker = nodeutils.NewCircuitBreaker(1, time.Hour)
+	s.botClient.circuitBreaker.Failure()
+
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).AnyTimes()
+	s.r.Equal(errCircuitOpen, s.botClient.invoke(
+		context.Background(), log.WithField("test", "dead-letters"), s.botGrpc, agentgrpc.MethodEvaluateBlock,
+		&protocol.EvaluateBlockRequest{}, &protocol.EvaluateBlockResponse{}, metrics.MetricBlockDrop,
+	))
+
+	letters, err := deadLetters.List()
+	s.r.NoError(err)
+	s.r.Empty(letters)
+}
@@ -0,0 +1,205 @@
+package botio
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/abidecoder"
+	"github.com/forta-network/forta-node/services/components/watchlist"
+)
+
+// eventFilter matches the events with the subscription filters of a bot. A nil filter matches
+// all events.
+type eventFilter struct {
+	chainIDs   map[uint64]bool
+	eventTypes map[string]bool
+	addresses  map[string]bool
+	topics     map[string]bool
+	// the function selectors and the event topics of the signatures
+	selectors  map[string]bool
+	signatures map[string]bool
+	labels     []string
+}
+
+// newEventFilter prepares the filters for matching. It returns nil if there are no filters.
+func newEventFilter(filters *config.BotFilters) *eventFilter {
+	if filters == nil {
+		return nil
+	}
+	if len(filters.ChainIDs) == 0 && len(filters.EventTypes) == 0 && len(filters.Addresses) == 0 && len(filters.Topics) == 0 &&
+		len(filters.Functions) == 0 && len(filters.Events) == 0 && len(filters.Labels) == 0 {
+		return nil
+	}
+	filter := &eventFilter{
+		chainIDs:   make(map[uint64]bool),
+		eventTypes: make(map[string]bool),
+		addresses:  make(map[string]bool),
+		topics:     make(map[string]bool),
+		selectors:  make(map[string]bool),
+		signatures: make(map[string]bool),
+		labels:     filters.Labels,
+	}
+	for _, chainID := range filters.ChainIDs {
+		filter.chainIDs[chainID] = true
+	}
+	for _, eventType := range filters.EventTypes {
+		filter.eventTypes[eventType] = true
+	}
+	for _, address := range filters.Addresses {
+		filter.addresses[strings.ToLower(address)] = true
+	}
+	for _, topic := range filters.Topics {
+		filter.topics[strings.ToLower(topic)] = true
+	}
+	for _, function := range filters.Functions {
+		filter.selectors[abidecoder.SignatureHash(function).Hex()[:10]] = true
+	}
+	for _, event := range filters.Events {
+		filter.signatures[abidecoder.SignatureHash(event).Hex()] = true
+	}
+	return filter
+}
+
+func (filter *eventFilter) matchesChain(chainIDHex string) bool {
+	if len(filter.chainIDs) == 0 {
+		return true
+	}
+	chainID, err := hexutil.DecodeUint64(chainIDHex)
+	return err == nil && filter.chainIDs[chainID]
+}
+
+func (filter *eventFilter) matchesType(eventType string) bool {
+	return len(filter.eventTypes) == 0 || filter.eventTypes[eventType]
+}
+
+// MatchesBlock tells if the block event matches the filters. The address and topic filters
+// apply only to the transactions.
+func (filter *eventFilter) MatchesBlock(evt *protocol.BlockEvent) bool {
+	if filter == nil {
+		return true
+	}
+	return filter.matchesType(config.BotEventBlock) && filter.matchesChain(evt.GetNetwork().GetChainId())
+}
+
+// MatchesTx tells if the transaction event matches the filters.
+func (filter *eventFilter) MatchesTx(evt *protocol.TransactionEvent) bool {
+	if filter == nil {
+		return true
+	}
+	if !filter.matchesType(config.BotEventTx) || !filter.matchesChain(evt.GetNetwork().GetChainId()) {
+		return false
+	}
+	return filter.matchesAddresses(evt) && filter.matchesTopics(evt) && filter.matchesFunctions(evt) && filter.matchesEvents(evt) &&
+		filter.matchesLabels(evt)
+}
+
+// MatchesLog tells if the log event matches the filters. Only the bots which subscribe to the log
+// events receive them, so a nil filter does not match.
+func (filter *eventFilter) MatchesLog(evt *protocol.TransactionEvent) bool {
+	if filter == nil || !filter.eventTypes[config.BotEventLog] || !filter.matchesChain(evt.GetNetwork().GetChainId()) {
+		return false
+	}
+	return filter.matchesAddresses(evt) && filter.matchesEventSignatures(evt) && filter.matchesEvents(evt) && filter.matchesLabels(evt)
+}
+
+// MatchesBeacon tells if the beacon event matches the filters. Like the log events, only the bots
+// which subscribe to the beacon events receive them.
+func (filter *eventFilter) MatchesBeacon(evt *protocol.BlockEvent) bool {
+	return filter != nil && filter.eventTypes[config.BotEventBeacon] && filter.matchesChain(evt.GetNetwork().GetChainId())
+}
+
+// MatchesSchedule tells if the scheduled evaluation matches the filters. Only the bots which
+// subscribe to the schedule events receive them.
+func (filter *eventFilter) MatchesSchedule(evt *protocol.BlockEvent) bool {
+	return filter != nil && filter.eventTypes[config.BotEventSchedule] && filter.matchesChain(evt.GetNetwork().GetChainId())
+}
+
+// matchesLabels checks the watchlist tags of the transaction, so the transactions are never matched
+// if the watchlists are not configured.
+func (filter *eventFilter) matchesLabels(evt *protocol.TransactionEvent) bool {
+	if len(filter.labels) == 0 {
+		return true
+	}
+	tags, err := watchlist.FromMessage(evt)
+	if err != nil || tags == nil {
+		return false
+	}
+	for _, label := range filter.labels {
+		if tags.HasLabel(label) {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *eventFilter) matchesAddresses(evt *protocol.TransactionEvent) bool {
+	if len(filter.addresses) == 0 {
+		return true
+	}
+	tx := evt.GetTransaction()
+	if filter.addresses[strings.ToLower(tx.GetFrom())] || filter.addresses[strings.ToLower(tx.GetTo())] {
+		return true
+	}
+	if filter.addresses[strings.ToLower(evt.GetContractAddress())] {
+		return true
+	}
+	for address := range evt.GetAddresses() {
+		if filter.addresses[strings.ToLower(address)] {
+			return true
+		}
+	}
+	for _, l := range evt.GetLogs() {
+		if filter.addresses[strings.ToLower(l.GetAddress())] {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *eventFilter) matchesTopics(evt *protocol.TransactionEvent) bool {
+	if len(filter.topics) == 0 {
+		return true
+	}
+	for _, l := range evt.GetLogs() {
+		for _, topic := range l.GetTopics() {
+			if filter.topics[strings.ToLower(topic)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (filter *eventFilter) matchesEventSignatures(evt *protocol.TransactionEvent) bool {
+	if len(filter.topics) == 0 {
+		return true
+	}
+	for _, l := range evt.GetLogs() {
+		if len(l.GetTopics()) > 0 && filter.topics[strings.ToLower(l.GetTopics()[0])] {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *eventFilter) matchesFunctions(evt *protocol.TransactionEvent) bool {
+	if len(filter.selectors) == 0 {
+		return true
+	}
+	input := strings.ToLower(evt.GetTransaction().GetInput())
+	return len(input) >= 10 && filter.selectors[input[:10]]
+}
+
+func (filter *eventFilter) matchesEvents(evt *protocol.TransactionEvent) bool {
+	if len(filter.signatures) == 0 {
+		return true
+	}
+	for _, l := range evt.GetLogs() {
+		if len(l.GetTopics()) > 0 && filter.signatures[strings.ToLower(l.GetTopics()[0])] {
+			return true
+		}
+	}
+	return false
+}
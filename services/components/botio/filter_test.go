@@ -0,0 +1,138 @@
+package botio
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/watchlist"
+	"github.com/stretchr/testify/require"
+)
+
+func testFilterTx(from, to string, logs ...*protocol.TransactionEvent_Log) *protocol.TransactionEvent {
+	return &protocol.TransactionEvent{
+		Transaction: &protocol.TransactionEvent_EthTransaction{From: from, To: to},
+		Network:     &protocol.TransactionEvent_Network{ChainId: "0x1"},
+		Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+		Logs:        logs,
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	r := require.New(t)
+
+	// no filters
+	var filter *eventFilter
+	r.Nil(newEventFilter(nil))
+	r.Nil(newEventFilter(&config.BotFilters{}))
+	r.True(filter.MatchesTx(testFilterTx("0x1", "0x2")))
+	r.True(filter.MatchesBlock(&protocol.BlockEvent{}))
+
+	filter = newEventFilter(&config.BotFilters{
+		Addresses: []string{"0xABCD"},
+		Topics:    []string{"0xTOPIC"},
+	})
+	// all blocks are matched since the filters apply to the transactions
+	r.True(filter.MatchesBlock(&protocol.BlockEvent{}))
+
+	transferLog := &protocol.TransactionEvent_Log{Address: "0xabcd", Topics: []string{"0xtopic"}}
+	otherLog := &protocol.TransactionEvent_Log{Address: "0x1234", Topics: []string{"0xother"}}
+	r.True(filter.MatchesTx(testFilterTx("0x1", "0x2", transferLog)))
+	r.True(filter.MatchesTx(testFilterTx("0x1", "0xabcd", &protocol.TransactionEvent_Log{Topics: []string{"0x0", "0xtopic"}})))
+	// the address matches but no topic does
+	r.False(filter.MatchesTx(testFilterTx("0xabcd", "0x2", otherLog)))
+	// the topic matches but no address does
+	r.False(filter.MatchesTx(testFilterTx("0x1", "0x2", &protocol.TransactionEvent_Log{Address: "0x1234", Topics: []string{"0xtopic"}})))
+}
+
+func TestEventFilter_TypesAndChains(t *testing.T) {
+	r := require.New(t)
+
+	filter := newEventFilter(&config.BotFilters{
+		ChainIDs:   []uint64{137},
+		EventTypes: []string{config.BotEventBlock},
+	})
+	r.True(filter.MatchesBlock(&protocol.BlockEvent{Network: &protocol.BlockEvent_Network{ChainId: "0x89"}}))
+	r.False(filter.MatchesBlock(&protocol.BlockEvent{Network: &protocol.BlockEvent_Network{ChainId: "0x1"}}))
+
+	tx := testFilterTx("0x1", "0x2")
+	tx.Network.ChainId = "0x89"
+	r.False(filter.MatchesTx(tx))
+}
+
+func TestEventFilter_Logs(t *testing.T) {
+	r := require.New(t)
+
+	// the bots without filters receive the transactions instead
+	var filter *eventFilter
+	r.False(filter.MatchesLog(testFilterTx("", "")))
+
+	filter = newEventFilter(&config.BotFilters{
+		EventTypes: []string{config.BotEventLog},
+		Addresses:  []string{"0xABCD"},
+		Topics:     []string{"0xTOPIC"},
+	})
+	r.False(filter.MatchesTx(testFilterTx("0x1", "0xabcd", &protocol.TransactionEvent_Log{Address: "0xabcd", Topics: []string{"0xtopic"}})))
+	r.True(filter.MatchesLog(testFilterTx("", "", &protocol.TransactionEvent_Log{Address: "0xabcd", Topics: []string{"0xtopic"}})))
+	// the topic should be the event signature
+	r.False(filter.MatchesLog(testFilterTx("", "", &protocol.TransactionEvent_Log{Address: "0xabcd", Topics: []string{"0x0", "0xtopic"}})))
+	r.False(filter.MatchesLog(testFilterTx("", "", &protocol.TransactionEvent_Log{Address: "0x1234", Topics: []string{"0xtopic"}})))
+}
+
+func TestBotClient_Filters(t *testing.T) {
+	r := require.New(t)
+
+	bot := &botClient{}
+	bot.SetConfig(config.AgentConfig{Filters: &config.BotFilters{EventTypes: []string{config.BotEventTx}}})
+	r.True(bot.ShouldProcessTxEvent(testFilterTx("0x1", "0x2")))
+	r.False(bot.ShouldProcessBlockEvent(&protocol.BlockEvent{}))
+
+	bot.SetConfig(config.AgentConfig{})
+	r.True(bot.ShouldProcessBlockEvent(&protocol.BlockEvent{}))
+
+	// the pending transactions are sent only to the bots which opt in
+	pendingTx := testFilterTx("0x1", "0x2")
+	pendingTx.Block = nil
+	r.False(bot.ShouldProcessTxEvent(pendingTx))
+	bot.setCapabilities(&agentgrpc.Capabilities{EventTypes: []string{agentgrpc.EventTypeBlock, agentgrpc.EventTypePendingTx}})
+	r.True(bot.ShouldProcessTxEvent(pendingTx))
+	r.False(bot.ShouldProcessTxEvent(testFilterTx("0x1", "0x2")))
+}
+
+func TestEventFilter_Signatures(t *testing.T) {
+	r := require.New(t)
+
+	filter := newEventFilter(&config.BotFilters{
+		Functions: []string{"transfer(address, uint256)"},
+		Events:    []string{"Transfer(address,address,uint256)"},
+	})
+	transferLog := &protocol.TransactionEvent_Log{Topics: []string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"}}
+	tx := testFilterTx("0x1", "0x2", transferLog)
+	tx.Transaction.Input = "0xA9059CBB0000"
+	r.True(filter.MatchesTx(tx))
+	// approve(address,uint256)
+	tx.Transaction.Input = "0x095ea7b30000"
+	r.False(filter.MatchesTx(tx))
+	tx.Transaction.Input = "0xa9059cbb"
+	tx.Logs = nil
+	r.False(filter.MatchesTx(tx))
+}
+
+func TestEventFilter_Labels(t *testing.T) {
+	r := require.New(t)
+
+	filter := newEventFilter(&config.BotFilters{Labels: []string{"exploiter"}})
+	r.NotNil(filter)
+
+	tagged := testFilterTx("0x1", "0x2")
+	r.NoError(watchlist.Attach(tagged, watchlist.Tags{"0x1": {"exploiter", "sanctioned"}}))
+	other := testFilterTx("0x1", "0x2")
+	r.NoError(watchlist.Attach(other, watchlist.Tags{"0x1": {"team multisig"}}))
+
+	r.True(filter.MatchesTx(tagged))
+	r.False(filter.MatchesTx(other))
+	// the transactions are not tagged without the watchlists
+	r.False(filter.MatchesTx(testFilterTx("0x1", "0x2")))
+	r.True(filter.MatchesBlock(&protocol.BlockEvent{}))
+}
@@ -0,0 +1,51 @@
+package botio
+
+import (
+	"context"
+	"sync"
+)
+
+// feedGate blocks the requests from the feeds while the feeds are paused. The blocked feeds
+// stop reading the new events until the feeds are resumed, so no events are lost.
+type feedGate struct {
+	// closed when the feeds are resumed - nil while the feeds are not paused
+	resumed chan struct{}
+	mu      sync.Mutex
+}
+
+func (g *feedGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		g.resumed = make(chan struct{})
+	}
+}
+
+func (g *feedGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		close(g.resumed)
+		g.resumed = nil
+	}
+}
+
+func (g *feedGate) paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed != nil
+}
+
+// wait blocks until the feeds are resumed or the context is done.
+func (g *feedGate) wait(ctx context.Context) {
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
@@ -10,6 +10,7 @@ import (
 	domain "github.com/forta-network/forta-core-go/domain"
 	protocol "github.com/forta-network/forta-core-go/protocol"
 	config "github.com/forta-network/forta-node/config"
+	botio "github.com/forta-network/forta-node/services/components/botio"
 	botreq "github.com/forta-network/forta-node/services/components/botio/botreq"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -121,6 +122,48 @@ func (mr *MockBotClientMockRecorder) Config() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Config", reflect.TypeOf((*MockBotClient)(nil).Config))
 }
 
+// EnqueueBlockRequest mocks base method.
+func (m *MockBotClient) EnqueueBlockRequest(req *botreq.BlockRequest) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueBlockRequest", req)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EnqueueBlockRequest indicates an expected call of EnqueueBlockRequest.
+func (mr *MockBotClientMockRecorder) EnqueueBlockRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueBlockRequest", reflect.TypeOf((*MockBotClient)(nil).EnqueueBlockRequest), req)
+}
+
+// EnqueueCombinationRequest mocks base method.
+func (m *MockBotClient) EnqueueCombinationRequest(req *botreq.CombinationRequest) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueCombinationRequest", req)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EnqueueCombinationRequest indicates an expected call of EnqueueCombinationRequest.
+func (mr *MockBotClientMockRecorder) EnqueueCombinationRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueCombinationRequest", reflect.TypeOf((*MockBotClient)(nil).EnqueueCombinationRequest), req)
+}
+
+// EnqueueTxRequest mocks base method.
+func (m *MockBotClient) EnqueueTxRequest(req *botreq.TxRequest) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueTxRequest", req)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EnqueueTxRequest indicates an expected call of EnqueueTxRequest.
+func (mr *MockBotClientMockRecorder) EnqueueTxRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTxRequest", reflect.TypeOf((*MockBotClient)(nil).EnqueueTxRequest), req)
+}
+
 // Initialize mocks base method.
 func (m *MockBotClient) Initialize() {
 	m.ctrl.T.Helper()
@@ -161,6 +204,48 @@ func (mr *MockBotClientMockRecorder) IsClosed() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClosed", reflect.TypeOf((*MockBotClient)(nil).IsClosed))
 }
 
+// IsDegraded mocks base method.
+func (m *MockBotClient) IsDegraded() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDegraded")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsDegraded indicates an expected call of IsDegraded.
+func (mr *MockBotClientMockRecorder) IsDegraded() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDegraded", reflect.TypeOf((*MockBotClient)(nil).IsDegraded))
+}
+
+// IsDisabled mocks base method.
+func (m *MockBotClient) IsDisabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDisabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsDisabled indicates an expected call of IsDisabled.
+func (mr *MockBotClientMockRecorder) IsDisabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDisabled", reflect.TypeOf((*MockBotClient)(nil).IsDisabled))
+}
+
+// IsIdle mocks base method.
+func (m *MockBotClient) IsIdle() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsIdle")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsIdle indicates an expected call of IsIdle.
+func (mr *MockBotClientMockRecorder) IsIdle() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIdle", reflect.TypeOf((*MockBotClient)(nil).IsIdle))
+}
+
 // IsInitialized mocks base method.
 func (m *MockBotClient) IsInitialized() bool {
 	m.ctrl.T.Helper()
@@ -175,6 +260,20 @@ func (mr *MockBotClientMockRecorder) IsInitialized() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsInitialized", reflect.TypeOf((*MockBotClient)(nil).IsInitialized))
 }
 
+// IsReady mocks base method.
+func (m *MockBotClient) IsReady() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReady")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsReady indicates an expected call of IsReady.
+func (mr *MockBotClientMockRecorder) IsReady() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReady", reflect.TypeOf((*MockBotClient)(nil).IsReady))
+}
+
 // LogStatus mocks base method.
 func (m *MockBotClient) LogStatus() {
 	m.ctrl.T.Helper()
@@ -187,6 +286,35 @@ func (mr *MockBotClientMockRecorder) LogStatus() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogStatus", reflect.TypeOf((*MockBotClient)(nil).LogStatus))
 }
 
+// PendingRequests mocks base method.
+func (m *MockBotClient) PendingRequests() []botio.PendingRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingRequests")
+	ret0, _ := ret[0].([]botio.PendingRequest)
+	return ret0
+}
+
+// PendingRequests indicates an expected call of PendingRequests.
+func (mr *MockBotClientMockRecorder) PendingRequests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingRequests", reflect.TypeOf((*MockBotClient)(nil).PendingRequests))
+}
+
+// PerformanceScore mocks base method.
+func (m *MockBotClient) PerformanceScore() (botio.PerformanceScore, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PerformanceScore")
+	ret0, _ := ret[0].(botio.PerformanceScore)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// PerformanceScore indicates an expected call of PerformanceScore.
+func (mr *MockBotClientMockRecorder) PerformanceScore() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PerformanceScore", reflect.TypeOf((*MockBotClient)(nil).PerformanceScore))
+}
+
 // SetConfig mocks base method.
 func (m *MockBotClient) SetConfig(arg0 config.AgentConfig) {
 	m.ctrl.T.Helper()
@@ -213,6 +341,20 @@ func (mr *MockBotClientMockRecorder) ShouldProcessAlert(event interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessAlert", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessAlert), event)
 }
 
+// ShouldProcessBeaconEvent mocks base method.
+func (m *MockBotClient) ShouldProcessBeaconEvent(event *protocol.BlockEvent) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldProcessBeaconEvent", event)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldProcessBeaconEvent indicates an expected call of ShouldProcessBeaconEvent.
+func (mr *MockBotClientMockRecorder) ShouldProcessBeaconEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessBeaconEvent", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessBeaconEvent), event)
+}
+
 // ShouldProcessBlock mocks base method.
 func (m *MockBotClient) ShouldProcessBlock(blockNumberHex string) bool {
 	m.ctrl.T.Helper()
@@ -227,6 +369,62 @@ func (mr *MockBotClientMockRecorder) ShouldProcessBlock(blockNumberHex interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessBlock", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessBlock), blockNumberHex)
 }
 
+// ShouldProcessBlockEvent mocks base method.
+func (m *MockBotClient) ShouldProcessBlockEvent(event *protocol.BlockEvent) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldProcessBlockEvent", event)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldProcessBlockEvent indicates an expected call of ShouldProcessBlockEvent.
+func (mr *MockBotClientMockRecorder) ShouldProcessBlockEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessBlockEvent", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessBlockEvent), event)
+}
+
+// ShouldProcessLogEvent mocks base method.
+func (m *MockBotClient) ShouldProcessLogEvent(event *protocol.TransactionEvent) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldProcessLogEvent", event)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldProcessLogEvent indicates an expected call of ShouldProcessLogEvent.
+func (mr *MockBotClientMockRecorder) ShouldProcessLogEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessLogEvent", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessLogEvent), event)
+}
+
+// ShouldProcessScheduledEvent mocks base method.
+func (m *MockBotClient) ShouldProcessScheduledEvent(event *protocol.BlockEvent) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldProcessScheduledEvent", event)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldProcessScheduledEvent indicates an expected call of ShouldProcessScheduledEvent.
+func (mr *MockBotClientMockRecorder) ShouldProcessScheduledEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessScheduledEvent", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessScheduledEvent), event)
+}
+
+// ShouldProcessTxEvent mocks base method.
+func (m *MockBotClient) ShouldProcessTxEvent(event *protocol.TransactionEvent) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldProcessTxEvent", event)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShouldProcessTxEvent indicates an expected call of ShouldProcessTxEvent.
+func (mr *MockBotClientMockRecorder) ShouldProcessTxEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessTxEvent", reflect.TypeOf((*MockBotClient)(nil).ShouldProcessTxEvent), event)
+}
+
 // StartProcessing mocks base method.
 func (m *MockBotClient) StartProcessing() {
 	m.ctrl.T.Helper()
@@ -64,6 +64,30 @@ func (mr *MockSenderMockRecorder) Name() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockSender)(nil).Name))
 }
 
+// PauseFeeds mocks base method.
+func (m *MockSender) PauseFeeds() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PauseFeeds")
+}
+
+// PauseFeeds indicates an expected call of PauseFeeds.
+func (mr *MockSenderMockRecorder) PauseFeeds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseFeeds", reflect.TypeOf((*MockSender)(nil).PauseFeeds))
+}
+
+// ResumeFeeds mocks base method.
+func (m *MockSender) ResumeFeeds() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResumeFeeds")
+}
+
+// ResumeFeeds indicates an expected call of ResumeFeeds.
+func (mr *MockSenderMockRecorder) ResumeFeeds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeFeeds", reflect.TypeOf((*MockSender)(nil).ResumeFeeds))
+}
+
 // SendEvaluateAlertRequest mocks base method.
 func (m *MockSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest) {
 	m.ctrl.T.Helper()
@@ -76,6 +100,18 @@ func (mr *MockSenderMockRecorder) SendEvaluateAlertRequest(req interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateAlertRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateAlertRequest), req)
 }
 
+// SendEvaluateBeaconRequest mocks base method.
+func (m *MockSender) SendEvaluateBeaconRequest(req *protocol.EvaluateBlockRequest) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendEvaluateBeaconRequest", req)
+}
+
+// SendEvaluateBeaconRequest indicates an expected call of SendEvaluateBeaconRequest.
+func (mr *MockSenderMockRecorder) SendEvaluateBeaconRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateBeaconRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateBeaconRequest), req)
+}
+
 // SendEvaluateBlockRequest mocks base method.
 func (m *MockSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest) {
 	m.ctrl.T.Helper()
@@ -88,6 +124,42 @@ func (mr *MockSenderMockRecorder) SendEvaluateBlockRequest(req interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateBlockRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateBlockRequest), req)
 }
 
+// SendEvaluateLogRequest mocks base method.
+func (m *MockSender) SendEvaluateLogRequest(req *protocol.EvaluateTxRequest) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendEvaluateLogRequest", req)
+}
+
+// SendEvaluateLogRequest indicates an expected call of SendEvaluateLogRequest.
+func (mr *MockSenderMockRecorder) SendEvaluateLogRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateLogRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateLogRequest), req)
+}
+
+// SendEvaluatePrivateTxRequest mocks base method.
+func (m *MockSender) SendEvaluatePrivateTxRequest(req *protocol.EvaluateTxRequest, botIDs []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendEvaluatePrivateTxRequest", req, botIDs)
+}
+
+// SendEvaluatePrivateTxRequest indicates an expected call of SendEvaluatePrivateTxRequest.
+func (mr *MockSenderMockRecorder) SendEvaluatePrivateTxRequest(req, botIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluatePrivateTxRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluatePrivateTxRequest), req, botIDs)
+}
+
+// SendEvaluateScheduledRequest mocks base method.
+func (m *MockSender) SendEvaluateScheduledRequest(req *protocol.EvaluateBlockRequest, botIDs []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendEvaluateScheduledRequest", req, botIDs)
+}
+
+// SendEvaluateScheduledRequest indicates an expected call of SendEvaluateScheduledRequest.
+func (mr *MockSenderMockRecorder) SendEvaluateScheduledRequest(req, botIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateScheduledRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateScheduledRequest), req, botIDs)
+}
+
 // SendEvaluateTxRequest mocks base method.
 func (m *MockSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
 	m.ctrl.T.Helper()
@@ -100,6 +172,43 @@ func (mr *MockSenderMockRecorder) SendEvaluateTxRequest(req interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateTxRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateTxRequest), req)
 }
 
+// MockChainAssignment is a mock of ChainAssignment interface.
+type MockChainAssignment struct {
+	ctrl     *gomock.Controller
+	recorder *MockChainAssignmentMockRecorder
+}
+
+// MockChainAssignmentMockRecorder is the mock recorder for MockChainAssignment.
+type MockChainAssignmentMockRecorder struct {
+	mock *MockChainAssignment
+}
+
+// NewMockChainAssignment creates a new mock instance.
+func NewMockChainAssignment(ctrl *gomock.Controller) *MockChainAssignment {
+	mock := &MockChainAssignment{ctrl: ctrl}
+	mock.recorder = &MockChainAssignmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChainAssignment) EXPECT() *MockChainAssignmentMockRecorder {
+	return m.recorder
+}
+
+// BotScansChain mocks base method.
+func (m *MockChainAssignment) BotScansChain(botID string, chainID uint64) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BotScansChain", botID, chainID)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// BotScansChain indicates an expected call of BotScansChain.
+func (mr *MockChainAssignmentMockRecorder) BotScansChain(botID, chainID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BotScansChain", reflect.TypeOf((*MockChainAssignment)(nil).BotScansChain), botID, chainID)
+}
+
 // MockBotPool is a mock of BotPool interface.
 type MockBotPool struct {
 	ctrl     *gomock.Controller
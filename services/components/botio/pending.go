@@ -0,0 +1,58 @@
+package botio
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// PendingRequest is a tx or a block request which a bot did not finish processing.
+type PendingRequest struct {
+	RequestID   string
+	ChainID     uint64
+	BlockNumber uint64
+}
+
+func pendingTxRequest(req *protocol.EvaluateTxRequest) PendingRequest {
+	chainID, _ := hexutil.DecodeUint64(req.Event.GetNetwork().GetChainId())
+	blockNumber, _ := hexutil.DecodeUint64(req.Event.GetBlock().GetBlockNumber())
+	return PendingRequest{RequestID: req.RequestId, ChainID: chainID, BlockNumber: blockNumber}
+}
+
+func pendingBlockRequest(req *protocol.EvaluateBlockRequest) PendingRequest {
+	chainID, _ := hexutil.DecodeUint64(req.Event.GetNetwork().GetChainId())
+	blockNumber, _ := hexutil.DecodeUint64(req.Event.GetBlockNumber())
+	return PendingRequest{RequestID: req.RequestId, ChainID: chainID, BlockNumber: blockNumber}
+}
+
+// pendingRequests tracks the requests which were taken from the buffers but not finished.
+type pendingRequests struct {
+	requests map[string]PendingRequest
+	mu       sync.Mutex
+}
+
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{requests: make(map[string]PendingRequest)}
+}
+
+func (pr *pendingRequests) add(req PendingRequest) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.requests[req.RequestID] = req
+}
+
+func (pr *pendingRequests) remove(requestID string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	delete(pr.requests, requestID)
+}
+
+func (pr *pendingRequests) list() (reqs []PendingRequest) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	for _, req := range pr.requests {
+		reqs = append(reqs, req)
+	}
+	return
+}
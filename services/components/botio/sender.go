@@ -9,8 +9,10 @@ import (
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/services/components/coverage"
 	"github.com/forta-network/forta-node/services/components/metrics"
 	log "github.com/sirupsen/logrus"
 )
@@ -18,11 +20,23 @@ import (
 // Sender sends requests to all bots and outputs bot responses.
 type Sender interface {
 	SendEvaluateTxRequest(req *protocol.EvaluateTxRequest)
+	SendEvaluateLogRequest(req *protocol.EvaluateTxRequest)
+	SendEvaluatePrivateTxRequest(req *protocol.EvaluateTxRequest, botIDs []string)
 	SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest)
+	SendEvaluateBeaconRequest(req *protocol.EvaluateBlockRequest)
+	SendEvaluateScheduledRequest(req *protocol.EvaluateBlockRequest, botIDs []string)
 	SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest)
+	// PauseFeeds blocks the requests until the feeds are resumed.
+	PauseFeeds()
+	ResumeFeeds()
 	health.Reporter
 }
 
+// ChainAssignment knows which bots should receive the events of each chain.
+type ChainAssignment interface {
+	BotScansChain(botID string, chainID uint64) bool
+}
+
 // BotPool knows the latest bot clients.
 type BotPool interface {
 	WaitForAll()
@@ -34,14 +48,28 @@ type requestSender struct {
 
 	botPool   BotPool
 	msgClient clients.MessageClient
+	chains    ChainAssignment
+	warmUp    *WarmUp
+	limits    agentgrpc.SizeLimits
+	coverage  *coverage.Tracker
+	gate      feedGate
 }
 
-// NewSender creates a new requestSender.
-func NewSender(ctx context.Context, msgClient clients.MessageClient, botPool BotPool) Sender {
+// NewSender creates a new requestSender. All bots receive the events of all chains
+// if the chain assignment is nil. The warm-up is optional. The tx requests which exceed
+// the size limits are truncated or dropped. The coverage tracker is optional.
+func NewSender(
+	ctx context.Context, msgClient clients.MessageClient, botPool BotPool, chains ChainAssignment, warmUp *WarmUp,
+	limits agentgrpc.SizeLimits, tracker *coverage.Tracker,
+) Sender {
 	return &requestSender{
 		ctx:       ctx,
 		botPool:   botPool,
 		msgClient: msgClient,
+		chains:    chains,
+		warmUp:    warmUp,
+		limits:    limits,
+		coverage:  tracker,
 	}
 }
 
@@ -50,11 +78,17 @@ func (rs *requestSender) Health() health.Reports {
 	bots := rs.botPool.GetCurrentBotClients()
 
 	botCount := len(bots)
-	var fullCount int
+	var fullCount, degradedCount, notReadyCount int
 	for _, bot := range bots {
 		if bot.TxBufferIsFull() {
 			fullCount++
 		}
+		if bot.IsDegraded() {
+			degradedCount++
+		}
+		if !bot.IsReady() {
+			notReadyCount++
+		}
 	}
 	status := health.StatusOK
 	if botCount == 0 {
@@ -71,6 +105,21 @@ func (rs *requestSender) Health() health.Reports {
 			Status:  health.StatusInfo,
 			Details: strconv.Itoa(fullCount),
 		},
+		&health.Report{
+			Name:    "agents.degraded",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(degradedCount),
+		},
+		&health.Report{
+			Name:    "agents.not-ready",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(notReadyCount),
+		},
+		&health.Report{
+			Name:    "feeds.paused",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatBool(rs.gate.paused()),
+		},
 	}
 }
 
@@ -79,110 +128,219 @@ func (rs *requestSender) Name() string {
 	return "sender"
 }
 
+// PauseFeeds blocks the requests from the feeds until the feeds are resumed. The requests
+// which the bots have received before are still processed.
+func (rs *requestSender) PauseFeeds() {
+	rs.gate.pause()
+	log.WithField("component", "pool").Info("paused the feeds")
+}
+
+// ResumeFeeds lets the blocked requests through.
+func (rs *requestSender) ResumeFeeds() {
+	rs.gate.resume()
+	log.WithField("component", "pool").Info("resumed the feeds")
+}
+
+// botScansChain tells if the bot should receive the events of the chain.
+func (rs *requestSender) botScansChain(bot BotClient, chainID uint64) bool {
+	return rs.chains == nil || rs.chains.BotScansChain(bot.Config().ID, chainID)
+}
+
 // SendEvaluateTxRequest sends the request to all of the active bots which
 // should be processing the block.
 func (rs *requestSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
+	rs.sendTxRequest("SendEvaluateTxRequest", req, BotClient.ShouldProcessTxEvent, true)
+}
+
+// SendEvaluateLogRequest sends the request from the log feed to all of the active bots which
+// subscribe to the logs of the transaction.
+func (rs *requestSender) SendEvaluateLogRequest(req *protocol.EvaluateTxRequest) {
+	rs.sendTxRequest("SendEvaluateLogRequest", req, BotClient.ShouldProcessLogEvent, false)
+}
+
+// SendEvaluatePrivateTxRequest sends the request from the private order flow feed only to the
+// permitted bots which subscribe to the transaction.
+func (rs *requestSender) SendEvaluatePrivateTxRequest(req *protocol.EvaluateTxRequest, botIDs []string) {
+	permitted := make(map[string]bool)
+	for _, botID := range botIDs {
+		permitted[botID] = true
+	}
+	rs.sendTxRequest(
+		"SendEvaluatePrivateTxRequest", req, func(bot BotClient, event *protocol.TransactionEvent) bool {
+			return permitted[bot.Config().ID] && bot.ShouldProcessTxEvent(event)
+		}, false,
+	)
+}
+
+func (rs *requestSender) sendTxRequest(
+	name string, req *protocol.EvaluateTxRequest, shouldProcess func(BotClient, *protocol.TransactionEvent) bool,
+	warmUp bool,
+) {
 	startTime := time.Now()
 	lg := log.WithFields(log.Fields{
 		"tx":        req.Event.Transaction.Hash,
 		"component": "pool",
 	})
-	lg.Debug("SendEvaluateTxRequest")
+	lg.Debug(name)
 
+	limited, truncated := rs.limits.LimitTxRequest(req)
+	if limited == nil {
+		lg.WithField("truncated", truncated).Warn("tx request exceeds the max message size - dropped request")
+		return
+	}
+	if len(truncated) > 0 {
+		lg.WithField("truncated", truncated).Info("tx request exceeds the max message size - truncated request")
+	}
+	req = limited
+
+	rs.gate.wait(rs.ctx)
 	rs.botPool.WaitForAll()
 
 	bots := rs.botPool.GetCurrentBotClients()
+	chainID, _ := hexutil.DecodeUint64(req.Event.GetNetwork().GetChainId())
+
+	// all bots share the same request and its encoding
+	request := &botreq.TxRequest{Original: req}
+	agentgrpc.ShareEncoding(req)
+	debug := log.IsLevelEnabled(log.DebugLevel)
+	if warmUp {
+		rs.warmUp.recordTx(request)
+	}
 
 	var metricsList []*protocol.AgentMetric
 	for _, bot := range bots {
-		if !bot.ShouldProcessBlock(req.Event.Block.BlockNumber) {
+		if !bot.IsReady() || !bot.ShouldProcessBlock(req.Event.Block.BlockNumber) || !rs.botScansChain(bot, chainID) ||
+			!shouldProcess(bot, req.Event) {
 			continue
 		}
 		botConfig := bot.Config()
 
-		lg.WithFields(log.Fields{
-			"bot":      botConfig.ID,
-			"duration": time.Since(startTime),
-		}).Debug("sending tx request to evalTxCh")
-
-		// unblock req send and discard agent if agent is closed
-
-		select {
-		case <-bot.Closed():
-			lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
-		case bot.TxRequestCh() <- &botreq.TxRequest{
-			Original: req,
-		}:
-		default: // do not try to send if the buffer is full
-			lg.WithField("bot", botConfig.ID).Debug("agent tx request buffer is full - skipping")
+		if debug {
+			lg.WithFields(log.Fields{
+				"bot":      botConfig.ID,
+				"duration": time.Since(startTime),
+			}).Debug("sending tx request to evalTxCh")
+		}
+
+		if bot.EnqueueTxRequest(request) {
+			lg.WithField("bot", botConfig.ID).Debug("agent tx request buffer is full - dropped request")
 			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig, metrics.MetricTxDrop, 1))
 		}
-		lg.WithFields(log.Fields{
-			"bot":      botConfig.ID,
-			"duration": time.Since(startTime),
-		}).Debug("sent tx request to evalTxCh")
+		if debug {
+			lg.WithFields(log.Fields{
+				"bot":      botConfig.ID,
+				"duration": time.Since(startTime),
+			}).Debug("sent tx request to evalTxCh")
+		}
 	}
 	metrics.SendAgentMetrics(rs.msgClient, metricsList)
 
 	lg.WithFields(log.Fields{
 		"duration": time.Since(startTime),
-	}).Debug("Finished SendEvaluateTxRequest")
+	}).Debug("Finished " + name)
 }
 
 // SendEvaluateBlockRequest sends the request to all of the active bots which
 // should be processing the block.
 func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest) {
+	rs.sendBlockRequest("SendEvaluateBlockRequest", &botreq.BlockRequest{Original: req}, BotClient.ShouldProcessBlockEvent)
+}
+
+// SendEvaluateBeaconRequest sends the request from the beacon feed to all of the active bots which
+// subscribe to the beacon events.
+func (rs *requestSender) SendEvaluateBeaconRequest(req *protocol.EvaluateBlockRequest) {
+	rs.sendBlockRequest(
+		"SendEvaluateBeaconRequest", &botreq.BlockRequest{Original: req, Beacon: true}, BotClient.ShouldProcessBeaconEvent,
+	)
+}
+
+// SendEvaluateScheduledRequest sends the request from the schedule feed to the bots which are due
+// and subscribe to the schedule events.
+func (rs *requestSender) SendEvaluateScheduledRequest(req *protocol.EvaluateBlockRequest, botIDs []string) {
+	due := make(map[string]bool)
+	for _, botID := range botIDs {
+		due[botID] = true
+	}
+	rs.sendBlockRequest(
+		"SendEvaluateScheduledRequest", &botreq.BlockRequest{Original: req, Scheduled: true},
+		func(bot BotClient, event *protocol.BlockEvent) bool {
+			return due[bot.Config().ID] && bot.ShouldProcessScheduledEvent(event)
+		},
+	)
+}
+
+func (rs *requestSender) sendBlockRequest(
+	name string, request *botreq.BlockRequest, shouldProcess func(BotClient, *protocol.BlockEvent) bool,
+) {
+	req := request.Original
 	startTime := time.Now()
 	lg := log.WithFields(log.Fields{
 		"block":     req.Event.BlockNumber,
 		"component": "pool",
 	})
-	lg.Debug("SendEvaluateBlockRequest")
+	lg.Debug(name)
 
+	rs.gate.wait(rs.ctx)
 	rs.botPool.WaitForAll()
 
 	bots := rs.botPool.GetCurrentBotClients()
+	chainID, _ := hexutil.DecodeUint64(req.Event.GetNetwork().GetChainId())
+
+	// all bots share the same request and its encoding
+	agentgrpc.ShareEncoding(req)
+	debug := log.IsLevelEnabled(log.DebugLevel)
+	// the beacon events and the scheduled evaluations are not the blocks of the feed
+	feedBlock := !request.Beacon && !request.Scheduled
+	// the warm-up replays only the blocks and their transactions
+	if feedBlock {
+		rs.warmUp.recordBlock(request)
+	}
+
+	blockNumber, _ := hexutil.DecodeUint64(req.Event.BlockNumber)
 
 	var metricsList []*protocol.AgentMetric
 	for _, bot := range bots {
-		if !bot.ShouldProcessBlock(req.Event.BlockNumber) {
+		if !bot.IsReady() || !bot.ShouldProcessBlock(req.Event.BlockNumber) || !rs.botScansChain(bot, chainID) ||
+			!shouldProcess(bot, req.Event) {
 			continue
 		}
 		botConfig := bot.Config()
 
-		lg.WithFields(log.Fields{
-			"bot":      botConfig.ID,
-			"duration": time.Since(startTime),
-		}).Debug("sending block request to evalBlockCh")
-
-		// unblock req send if agent is closed
-		select {
-		case <-bot.Closed():
-			lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
-		case bot.BlockRequestCh() <- &botreq.BlockRequest{
-			Original: req,
-		}:
-		default: // do not try to send if the buffer is full
-			lg.WithField("bot", botConfig.ID).Warn("agent block request buffer is full - skipping")
-			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig, metrics.MetricBlockDrop, 1))
-		}
-		lg.WithFields(
-			log.Fields{
+		if debug {
+			lg.WithFields(log.Fields{
 				"bot":      botConfig.ID,
 				"duration": time.Since(startTime),
-			},
-		).Debug("sent tx request to evalBlockCh")
+			}).Debug("sending block request to evalBlockCh")
+		}
+
+		if bot.EnqueueBlockRequest(request) {
+			lg.WithField("bot", botConfig.ID).Debug("agent block request buffer is full - dropped request")
+			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig, metrics.MetricBlockDrop, 1))
+		} else if feedBlock {
+			rs.coverage.Dispatched(chainID, botConfig.ID, blockNumber)
+		}
+		if debug {
+			lg.WithFields(
+				log.Fields{
+					"bot":      botConfig.ID,
+					"duration": time.Since(startTime),
+				},
+			).Debug("sent tx request to evalBlockCh")
+		}
 	}
 
-	blockNumber, _ := hexutil.DecodeUint64(req.Event.BlockNumber)
-	rs.msgClient.Publish(messaging.SubjectScannerBlock, &messaging.ScannerPayload{
-		LatestBlockInput: blockNumber,
-	})
+	// only the blocks of the feed move the latest block of the scanner
+	if feedBlock {
+		rs.msgClient.Publish(messaging.SubjectScannerBlock, &messaging.ScannerPayload{
+			LatestBlockInput: blockNumber,
+			ChainID:          chainID,
+		})
+	}
 
 	metrics.SendAgentMetrics(rs.msgClient, metricsList)
 	lg.WithFields(log.Fields{
 		"duration": time.Since(startTime),
-	}).Debug("Finished SendEvaluateBlockRequest")
+	}).Debug("Finished " + name)
 }
 
 // SendEvaluateAlertRequest sends the request to all the active bots which
@@ -203,6 +361,7 @@ func (rs *requestSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertReq
 		return
 	}
 
+	rs.gate.wait(rs.ctx)
 	rs.botPool.WaitForAll()
 
 	bots := rs.botPool.GetCurrentBotClients()
@@ -226,6 +385,10 @@ func (rs *requestSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertReq
 		lg.Warn("failed to find subscriber")
 		return
 	}
+	if !target.IsReady() {
+		lg.Debug("subscriber is not ready")
+		return
+	}
 
 	// filter out bad events
 	if !target.ShouldProcessAlert(req.Event) {
@@ -240,15 +403,8 @@ func (rs *requestSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertReq
 		},
 	).Debug("sending alert request to evalAlertCh")
 
-	// unblock req send if agent is closed
-	select {
-	case <-target.Closed():
-		lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
-	case target.CombinationRequestCh() <- &botreq.CombinationRequest{
-		Original: req,
-	}:
-	default: // do not try to send if the buffer is full
-		lg.WithField("bot", botConfig.ID).Warn("agent alert request buffer is full - skipping")
+	if target.EnqueueCombinationRequest(&botreq.CombinationRequest{Original: req}) {
+		lg.WithField("bot", botConfig.ID).Debug("agent alert request buffer is full - dropped request")
 		metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig, metrics.MetricCombinerDrop, 1))
 	}
 
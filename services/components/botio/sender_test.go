@@ -5,11 +5,11 @@ import (
 	"testing"
 
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/clients/messaging"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio"
-	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -42,22 +42,29 @@ func (s *SenderTestSuite) SetupTest() {
 
 	s.botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{s.botClient}).AnyTimes()
 
-	s.sender = botio.NewSender(context.Background(), s.msgClient, s.botPool)
+	s.sender = botio.NewSender(context.Background(), s.msgClient, s.botPool, nil, nil, agentgrpc.SizeLimits{}, nil)
 }
 
 func (s *SenderTestSuite) TestHealth() {
 	s.botClient.EXPECT().TxBufferIsFull().Return(false)
+	s.botClient.EXPECT().IsDegraded().Return(true)
+	s.botClient.EXPECT().IsReady().Return(false)
 	reports := s.sender.Health()
 	s.r.Equal("agents.total", reports[0].Name)
 	s.r.Equal("agents.lagging", reports[1].Name)
+	s.r.Equal("agents.degraded", reports[2].Name)
+	s.r.Equal("1", reports[2].Details)
+	s.r.Equal("agents.not-ready", reports[3].Name)
+	s.r.Equal("1", reports[3].Details)
 }
 
 func (s *SenderTestSuite) TestSendEvaluateTxRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
 	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
+	s.botClient.EXPECT().ShouldProcessTxEvent(gomock.Any()).Return(true)
 	s.botClient.EXPECT().Config().Return(config.AgentConfig{})
-	s.botClient.EXPECT().Closed().Return(make(chan struct{}))
-	s.botClient.EXPECT().TxRequestCh().Return(make(chan *botreq.TxRequest, 1))
+	s.botClient.EXPECT().EnqueueTxRequest(gomock.Any()).Return(false)
 
 	s.sender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{
 		Event: &protocol.TransactionEvent{
@@ -71,12 +78,76 @@ func (s *SenderTestSuite) TestSendEvaluateTxRequest() {
 	})
 }
 
+func (s *SenderTestSuite) TestSendEvaluateTxRequest_ChainAssignment() {
+	sender := botio.NewSender(context.Background(), s.msgClient, s.botPool, config.Config{
+		ChainID: 1,
+		Chains:  []config.ChainConfig{{ChainID: 137, Bots: []string{"0x1234"}}},
+	}, nil, agentgrpc.SizeLimits{}, nil)
+
+	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
+	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
+	s.botClient.EXPECT().Config().Return(config.AgentConfig{ID: "0xabcd"})
+	// not enqueued because the bot is not assigned to the chain
+
+	sender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{
+				Hash: "0x1",
+			},
+			Block: &protocol.TransactionEvent_EthBlock{
+				BlockNumber: "0x1",
+			},
+			Network: &protocol.TransactionEvent_Network{
+				ChainId: "0x89",
+			},
+		},
+	})
+}
+
+func (s *SenderTestSuite) TestSendEvaluateTxRequest_Filtered() {
+	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
+	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
+	s.botClient.EXPECT().ShouldProcessTxEvent(gomock.Any()).Return(false)
+	// not enqueued because the bot does not subscribe to the tx
+
+	s.sender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{
+				Hash: "0x1",
+			},
+			Block: &protocol.TransactionEvent_EthBlock{
+				BlockNumber: "0x1",
+			},
+		},
+	})
+}
+
+func (s *SenderTestSuite) TestSendEvaluatePrivateTxRequest() {
+	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
+	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
+	s.botClient.EXPECT().Config().Return(config.AgentConfig{ID: "0x2"}).AnyTimes()
+	// not enqueued because the bot is not permitted
+
+	s.sender.SendEvaluatePrivateTxRequest(&protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{
+				Hash: "0x1",
+			},
+			Block: &protocol.TransactionEvent_EthBlock{},
+		},
+	}, []string{"0x1"})
+}
+
 func (s *SenderTestSuite) TestSendEvaluateBlockRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
 	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
+	s.botClient.EXPECT().ShouldProcessBlockEvent(gomock.Any()).Return(true)
 	s.botClient.EXPECT().Config().Return(config.AgentConfig{})
-	s.botClient.EXPECT().Closed().Return(make(chan struct{}))
-	s.botClient.EXPECT().BlockRequestCh().Return(make(chan *botreq.BlockRequest, 1))
+	s.botClient.EXPECT().EnqueueBlockRequest(gomock.Any()).Return(false)
 	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any())
 
 	s.sender.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{
@@ -90,10 +161,10 @@ func (s *SenderTestSuite) TestSendEvaluateBlockRequest() {
 
 func (s *SenderTestSuite) TestSendEvaluateAlertRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
+	s.botClient.EXPECT().IsReady().Return(true)
 	s.botClient.EXPECT().ShouldProcessAlert(gomock.Any()).Return(true)
 	s.botClient.EXPECT().Config().Return(config.AgentConfig{}).Times(2)
-	s.botClient.EXPECT().Closed().Return(make(chan struct{}))
-	s.botClient.EXPECT().CombinationRequestCh().Return(make(chan *botreq.CombinationRequest, 1))
+	s.botClient.EXPECT().EnqueueCombinationRequest(gomock.Any()).Return(false)
 	s.msgClient.EXPECT().Publish(messaging.SubjectScannerAlert, gomock.Any())
 
 	s.sender.SendEvaluateAlertRequest(&protocol.EvaluateAlertRequest{
@@ -106,3 +177,39 @@ func (s *SenderTestSuite) TestSendEvaluateAlertRequest() {
 		},
 	})
 }
+
+// BenchmarkSendEvaluateTxRequest measures dispatching a tx request to the bots. Run with -cpuprofile
+// or -memprofile to analyze the dispatch loop with pprof.
+func BenchmarkSendEvaluateTxRequest(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().WaitForAll().AnyTimes()
+
+	var bots []botio.BotClient
+	for i := 0; i < 50; i++ {
+		botClient := mock_botio.NewMockBotClient(ctrl)
+		botClient.EXPECT().IsReady().Return(true).AnyTimes()
+		botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true).AnyTimes()
+		botClient.EXPECT().ShouldProcessTxEvent(gomock.Any()).Return(true).AnyTimes()
+		botClient.EXPECT().Config().Return(config.AgentConfig{ID: "0xbot"}).AnyTimes()
+		botClient.EXPECT().EnqueueTxRequest(gomock.Any()).Return(false).AnyTimes()
+		bots = append(bots, botClient)
+	}
+	botPool.EXPECT().GetCurrentBotClients().Return(bots).AnyTimes()
+	sender := botio.NewSender(context.Background(), msgClient, botPool, nil, nil, agentgrpc.SizeLimits{}, nil)
+
+	req := &protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0x1"},
+			Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+			Network:     &protocol.TransactionEvent_Network{ChainId: "0x1"},
+		},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sender.SendEvaluateTxRequest(req)
+	}
+}
@@ -0,0 +1,202 @@
+package botio
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWarmUpTimeout is the max duration of the warm-up of a bot.
+const DefaultWarmUpTimeout = time.Minute
+
+type warmUpBlock struct {
+	chainID     uint64
+	blockNumber uint64
+	txs         []*botreq.TxRequest
+	block       *botreq.BlockRequest
+}
+
+// warmUpRequest is either a tx or a block request.
+type warmUpRequest struct {
+	tx    *botreq.TxRequest
+	block *botreq.BlockRequest
+}
+
+// WarmUp keeps the tx and block requests of the last blocks of each chain, so that they can be
+// replayed to the newly started bots before the bots receive the live requests.
+type WarmUp struct {
+	blocks int
+	chains ChainAssignment
+
+	// the last blocks of each chain, ordered from the oldest to the newest
+	chainBlocks map[uint64][]*warmUpBlock
+	mu          sync.Mutex
+}
+
+// NewWarmUp creates a new warm-up which keeps the requests of the given amount of the last blocks.
+// It returns nil if the amount is not positive, which disables the warm-up.
+func NewWarmUp(blocks int, chains ChainAssignment) *WarmUp {
+	if blocks <= 0 {
+		return nil
+	}
+	return &WarmUp{
+		blocks:      blocks,
+		chains:      chains,
+		chainBlocks: make(map[uint64][]*warmUpBlock),
+	}
+}
+
+// getBlock returns the block which the request belongs to and adds it if it is a new block.
+func (wu *WarmUp) getBlock(chainIDHex, blockNumberHex string) *warmUpBlock {
+	chainID, _ := hexutil.DecodeUint64(chainIDHex)
+	blockNumber, err := hexutil.DecodeUint64(blockNumberHex)
+	if err != nil {
+		return nil
+	}
+	blocks := wu.chainBlocks[chainID]
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].blockNumber == blockNumber {
+			return blocks[i]
+		}
+	}
+	// the blocks of the reorgs and the late blocks are not kept
+	if len(blocks) > 0 && blockNumber < blocks[len(blocks)-1].blockNumber {
+		return nil
+	}
+	block := &warmUpBlock{chainID: chainID, blockNumber: blockNumber}
+	blocks = append(blocks, block)
+	if len(blocks) > wu.blocks {
+		blocks = blocks[len(blocks)-wu.blocks:]
+	}
+	wu.chainBlocks[chainID] = blocks
+	return block
+}
+
+func (wu *WarmUp) recordTx(req *botreq.TxRequest) {
+	if wu == nil {
+		return
+	}
+	wu.mu.Lock()
+	defer wu.mu.Unlock()
+	event := req.Original.Event
+	if block := wu.getBlock(event.GetNetwork().GetChainId(), event.GetBlock().GetBlockNumber()); block != nil {
+		block.txs = append(block.txs, req)
+	}
+}
+
+func (wu *WarmUp) recordBlock(req *botreq.BlockRequest) {
+	if wu == nil {
+		return
+	}
+	wu.mu.Lock()
+	defer wu.mu.Unlock()
+	event := req.Original.Event
+	if block := wu.getBlock(event.GetNetwork().GetChainId(), event.GetBlockNumber()); block != nil {
+		block.block = req
+	}
+}
+
+// requestsFor returns the requests of the last blocks which the bot should process, in the order
+// of the blocks and with the transactions of each block before the block.
+func (wu *WarmUp) requestsFor(bot BotClient) (requests []warmUpRequest) {
+	wu.mu.Lock()
+	defer wu.mu.Unlock()
+	botID := bot.Config().ID
+	for chainID, chainBlocks := range wu.chainBlocks {
+		if wu.chains != nil && !wu.chains.BotScansChain(botID, chainID) {
+			continue
+		}
+		for _, block := range chainBlocks {
+			for _, req := range block.txs {
+				if bot.ShouldProcessBlock(req.Original.Event.GetBlock().GetBlockNumber()) &&
+					bot.ShouldProcessTxEvent(req.Original.Event) {
+					requests = append(requests, warmUpRequest{tx: req})
+				}
+			}
+			if block.block != nil && bot.ShouldProcessBlock(block.block.Original.Event.GetBlockNumber()) &&
+				bot.ShouldProcessBlockEvent(block.block.Original.Event) {
+				requests = append(requests, warmUpRequest{block: block.block})
+			}
+		}
+	}
+	return
+}
+
+// WarmedUp returns the channel which is closed when the bot is done with the warm-up and starts
+// processing the live requests.
+func (bot *botClient) WarmedUp() <-chan struct{} {
+	return bot.warmedUp
+}
+
+// warmUp replays the requests of the last blocks to the bot once, marked as warm-up, and discards
+// the findings. The warm-up stops early if it takes longer than the warm-up timeout. The live
+// requests wait in the buffers during the warm-up.
+func (bot *botClient) warmUp() {
+	bot.warmUpOnce.Do(func() {
+		defer close(bot.warmedUp) // never close this anywhere else
+
+		if bot.requestOpts.WarmUp == nil {
+			return
+		}
+		requests := bot.requestOpts.WarmUp.requestsFor(bot)
+		if len(requests) == 0 {
+			return
+		}
+
+		lg := log.WithFields(log.Fields{
+			"bot":       bot.Config().ID,
+			"component": "bot-client",
+			"evaluate":  "warm-up",
+		})
+		startTime := time.Now()
+		ctx, cancel := context.WithTimeout(bot.ctx, bot.requestOpts.WarmUpTimeout)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, agentgrpc.WarmUpKey, "true")
+
+		var replayed int
+		for _, request := range requests {
+			if ctx.Err() != nil {
+				break
+			}
+			var (
+				method      agentgrpc.Method
+				in, out     interface{}
+				blockNumber string
+			)
+			if request.tx != nil {
+				method, in, out = agentgrpc.MethodEvaluateTx, request.tx.Original, new(protocol.EvaluateTxResponse)
+				blockNumber = request.tx.Original.Event.GetBlock().GetBlockNumber()
+			} else {
+				method, in, out = agentgrpc.MethodEvaluateBlock, request.block.Original, new(protocol.EvaluateBlockResponse)
+				blockNumber = request.block.Original.Event.GetBlockNumber()
+			}
+			reqCtx, reqCancel := context.WithTimeout(ctx, bot.requestOpts.Timeout)
+			err := bot.grpcClient().Invoke(withRequestID(reqCtx, in), method, in, out)
+			reqCancel()
+			if err != nil && status.Code(err) != codes.Unimplemented && ctx.Err() == nil {
+				lg.WithError(err).WithField("block", blockNumber).Debug("failed to send the warm-up request")
+			}
+			replayed++
+		}
+
+		lg = lg.WithFields(log.Fields{
+			"replayed": replayed,
+			"total":    len(requests),
+			"duration": time.Since(startTime),
+		})
+		if replayed < len(requests) {
+			lg.Warn("bot warm-up timed out - starting to process the live requests")
+			return
+		}
+		lg.Info("bot warm-up is done")
+	})
+}
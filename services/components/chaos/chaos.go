@@ -0,0 +1,205 @@
+package chaos
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the dropped bot requests without a deadline fail after this
+const maxDropDuration = time.Minute
+
+type botFault int
+
+const (
+	botFaultNone botFault = iota
+	botFaultDrop
+	botFaultError
+	botFaultDelay
+)
+
+// Injector injects random faults into the bot requests and the json-rpc requests, and picks the
+// bot containers to kill.
+type Injector struct {
+	cfg  config.ChaosConfig
+	rand *rand.Rand
+	mu   sync.Mutex
+}
+
+// NewInjector creates a new fault injector.
+func NewInjector(cfg config.ChaosConfig) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.WithField("seed", seed).Warn("fault injection is enabled - this should never run in production")
+	return &Injector{cfg: cfg, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (inj *Injector) float() float64 {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	return inj.rand.Float64()
+}
+
+// Pick picks a random index from n items.
+func (inj *Injector) Pick(n int) int {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	return inj.rand.Intn(n)
+}
+
+// nextBotFault picks at most one fault for a bot request.
+func (inj *Injector) nextBotFault() botFault {
+	roll := inj.float()
+	for _, fault := range []struct {
+		fault       botFault
+		probability float64
+	}{
+		{botFaultDrop, inj.cfg.BotDropProbability},
+		{botFaultError, inj.cfg.BotErrorProbability},
+		{botFaultDelay, inj.cfg.BotDelayProbability},
+	} {
+		if roll < fault.probability {
+			return fault.fault
+		}
+		roll -= fault.probability
+	}
+	return botFaultNone
+}
+
+// injectBotFault delays the request, fails it or drops it by waiting until the request times out.
+func (inj *Injector) injectBotFault(ctx context.Context, method string) error {
+	fault := inj.nextBotFault()
+	if fault == botFaultNone {
+		return nil
+	}
+	logger := log.WithField("method", method)
+	switch fault {
+	case botFaultDrop:
+		logger.Debug("chaos: dropping bot request")
+		ctx, cancel := context.WithTimeout(ctx, maxDropDuration)
+		defer cancel()
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	case botFaultError:
+		logger.Debug("chaos: failing bot request")
+		return status.Error(codes.Unavailable, "chaos: injected bot request failure")
+	default:
+		logger.Debug("chaos: delaying bot request")
+		if err := sleep(ctx, time.Duration(inj.cfg.BotDelayMs)*time.Millisecond); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor injects the faults into the bot requests.
+func (inj *Injector) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		if err := inj.injectBotFault(ctx, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor injects the faults into the request streams of the bots.
+func (inj *Injector) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		if err := inj.injectBotFault(ctx, method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// stall stalls the json-rpc request with the stall probability.
+func (inj *Injector) stall(ctx context.Context, method string) error {
+	if inj.float() >= inj.cfg.RPCStallProbability {
+		return nil
+	}
+	log.WithField("method", method).Debug("chaos: stalling json-rpc request")
+	return sleep(ctx, time.Duration(inj.cfg.RPCStallMs)*time.Millisecond)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// EthClient wraps the client to stall the json-rpc requests.
+func (inj *Injector) EthClient(client ethereum.Client) ethereum.Client {
+	return &ethClient{Client: client, inj: inj}
+}
+
+type ethClient struct {
+	ethereum.Client
+	inj *Injector
+}
+
+func (client *ethClient) BlockByHash(ctx context.Context, hash string) (*domain.Block, error) {
+	if err := client.inj.stall(ctx, "eth_getBlockByHash"); err != nil {
+		return nil, err
+	}
+	return client.Client.BlockByHash(ctx, hash)
+}
+
+func (client *ethClient) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	if err := client.inj.stall(ctx, "eth_getBlockByNumber"); err != nil {
+		return nil, err
+	}
+	return client.Client.BlockByNumber(ctx, number)
+}
+
+func (client *ethClient) BlockNumber(ctx context.Context) (*big.Int, error) {
+	if err := client.inj.stall(ctx, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+	return client.Client.BlockNumber(ctx)
+}
+
+func (client *ethClient) TransactionReceipt(ctx context.Context, txHash string) (*domain.TransactionReceipt, error) {
+	if err := client.inj.stall(ctx, "eth_getTransactionReceipt"); err != nil {
+		return nil, err
+	}
+	return client.Client.TransactionReceipt(ctx, txHash)
+}
+
+func (client *ethClient) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	if err := client.inj.stall(ctx, "trace_block"); err != nil {
+		return nil, err
+	}
+	return client.Client.TraceBlock(ctx, number)
+}
+
+func (client *ethClient) GetLogs(ctx context.Context, q eth.FilterQuery) ([]types.Log, error) {
+	if err := client.inj.stall(ctx, "eth_getLogs"); err != nil {
+		return nil, err
+	}
+	return client.Client.GetLogs(ctx, q)
+}
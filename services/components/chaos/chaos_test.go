@@ -0,0 +1,80 @@
+package chaos
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func invokeWithFaults(cfg config.ChaosConfig, timeout time.Duration) (invoked bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	interceptor := NewInjector(cfg).UnaryClientInterceptor()
+	err = interceptor(ctx, "/network.forta.Agent/EvaluateTx", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			invoked = true
+			return nil
+		},
+	)
+	return
+}
+
+func TestBotFaults(t *testing.T) {
+	r := require.New(t)
+
+	invoked, err := invokeWithFaults(config.ChaosConfig{Enable: true}, time.Second)
+	r.NoError(err)
+	r.True(invoked)
+
+	invoked, err = invokeWithFaults(config.ChaosConfig{Enable: true, BotErrorProbability: 1}, time.Second)
+	r.Equal(codes.Unavailable, status.Code(err))
+	r.False(invoked)
+
+	invoked, err = invokeWithFaults(config.ChaosConfig{Enable: true, BotDropProbability: 1}, time.Millisecond*50)
+	r.Equal(codes.DeadlineExceeded, status.Code(err))
+	r.False(invoked)
+
+	start := time.Now()
+	invoked, err = invokeWithFaults(config.ChaosConfig{Enable: true, BotDelayProbability: 1, BotDelayMs: 50}, time.Second)
+	r.NoError(err)
+	r.True(invoked)
+	r.GreaterOrEqual(time.Since(start), time.Millisecond*50)
+}
+
+func TestBotFaults_Seed(t *testing.T) {
+	r := require.New(t)
+
+	cfg := config.ChaosConfig{Enable: true, Seed: 1, BotErrorProbability: 0.3, BotDropProbability: 0.3}
+	inj1, inj2 := NewInjector(cfg), NewInjector(cfg)
+	for i := 0; i < 100; i++ {
+		r.Equal(inj1.nextBotFault(), inj2.nextBotFault())
+	}
+}
+
+func TestEthClientStall(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	client := mock_ethereum.NewMockClient(ctrl)
+	ethClient := NewInjector(config.ChaosConfig{Enable: true, RPCStallProbability: 1, RPCStallMs: 1000}).EthClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	_, err := ethClient.BlockByNumber(ctx, big.NewInt(1))
+	r.ErrorIs(err, context.DeadlineExceeded)
+
+	ethClient = NewInjector(config.ChaosConfig{Enable: true}).EthClient(client)
+	client.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(1), nil)
+	blockNumber, err := ethClient.BlockNumber(context.Background())
+	r.NoError(err)
+	r.Equal(int64(1), blockNumber.Int64())
+}
@@ -3,20 +3,29 @@ package components
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/agenthttp"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/services/components/chaos"
 	"github.com/forta-network/forta-node/services/components/containers"
+	"github.com/forta-network/forta-node/services/components/coverage"
 	"github.com/forta-network/forta-node/services/components/lifecycle"
 	"github.com/forta-network/forta-node/services/components/lifecycle/mediator"
 	"github.com/forta-network/forta-node/services/components/metrics"
 	"github.com/forta-network/forta-node/services/components/registry"
+	"github.com/forta-network/forta-node/services/components/security"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
 )
 
 // BotProcessingConfig contains bot processing component configuration and dependencies.
@@ -29,20 +38,48 @@ type BotProcessingConfig struct {
 type BotProcessing struct {
 	RequestSender botio.Sender
 	Results       botreq.ReceiveOnlyChannels
+	BotPool       lifecycle.BotPool
+	// DeadLetterReplayer is nil if the dead letters are disabled.
+	DeadLetterReplayer *botio.DeadLetterReplayer
+	// Coverage is nil if the coverage reports are disabled.
+	Coverage *coverage.Tracker
 }
 
 // GetBotProcessingComponents returns the bot processing components after doing dependency injection.
 func GetBotProcessingComponents(ctx context.Context, botProcCfg BotProcessingConfig) (BotProcessing, error) {
 	resultChannels := botreq.MakeResultChannels()
 	lifecycleMetrics := metrics.NewLifecycleClient(botProcCfg.MessageClient)
+	botDialer, err := newBotDialer(botProcCfg.Config)
+	if err != nil {
+		return BotProcessing{}, err
+	}
+	botDialer = agenthttp.NewBotDialer(botDialer, &http.Client{})
+	var deadLetters store.DeadLetterStore
+	if botProcCfg.Config.Scan.EnableDeadLetters {
+		deadLetters = store.NewDeadLetterStore(
+			path.Join(botProcCfg.Config.FortaDir, config.DefaultDeadLettersDirName), botProcCfg.Config.Scan.MaxDeadLetters,
+		)
+	}
+	var overflowDir string
+	if !botProcCfg.Config.Scan.DisableBotTxOverflow {
+		overflowDir = path.Join(botProcCfg.Config.FortaDir, config.DefaultTxOverflowDirName)
+		// the requests of the previous runs are not sent to the bots again
+		if err := store.ClearOverflowQueues(overflowDir); err != nil {
+			return BotProcessing{}, err
+		}
+	}
+	warmUp := botio.NewWarmUp(botProcCfg.Config.Scan.BotWarmUpBlocks, botProcCfg.Config)
+	sizeLimits := agentgrpc.SizeLimitsFromConfig(botProcCfg.Config.AgentGrpc)
 	botClientFactory := botio.NewBotClientFactory(
 		resultChannels.SendOnly(), botProcCfg.MessageClient,
-		lifecycleMetrics, agentgrpc.NewBotDialer(),
+		lifecycleMetrics, botDialer, botProcCfg.Config.Scan, deadLetters, overflowDir, warmUp, sizeLimits,
 	)
+	lifecycleMediator := mediator.New(botProcCfg.MessageClient, lifecycleMetrics)
+	// the bots are not bound to the main context so that they can be drained during the shutdown
 	botPool := lifecycle.NewBotPool(
-		ctx, lifecycleMetrics, botClientFactory, botProcCfg.Config.BotsToWait(),
+		context.Background(), lifecycleMetrics, botClientFactory, botProcCfg.Config.BotsToWait(), lifecycleMediator,
 	)
-	mediator.New(botProcCfg.MessageClient, lifecycleMetrics).ConnectBotPool(botPool)
+	lifecycleMediator.ConnectBotPool(botPool)
 
 	// update the bot pool directly if we are in standalone mode
 	if botProcCfg.Config.LocalModeConfig.IsStandalone() {
@@ -59,13 +96,55 @@ func GetBotProcessingComponents(ctx context.Context, botProcCfg BotProcessingCon
 		}
 	}
 
-	sender := botio.NewSender(ctx, botProcCfg.MessageClient, botPool)
+	var coverageTracker *coverage.Tracker
+	if botProcCfg.Config.Scan.Coverage.Enable {
+		coverageTracker = coverage.NewTracker(ctx, botProcCfg.Config.Scan.Coverage, store.NewCoverageStore(
+			path.Join(botProcCfg.Config.FortaDir, config.DefaultCoverageDirName), botProcCfg.Config.Scan.Coverage.MaxReports,
+		))
+	}
+	sender := botio.NewSender(
+		ctx, botProcCfg.MessageClient, botPool, botProcCfg.Config, warmUp, sizeLimits, coverageTracker,
+	)
+	var deadLetterReplayer *botio.DeadLetterReplayer
+	if deadLetters != nil {
+		deadLetterReplayer = botio.NewDeadLetterReplayer(
+			ctx, botProcCfg.MessageClient, deadLetters, botPool,
+			time.Duration(botProcCfg.Config.Scan.DeadLetterReplayIntervalSeconds)*time.Second,
+		)
+	}
 	return BotProcessing{
-		RequestSender: sender,
-		Results:       resultChannels.ReceiveOnly(),
+		RequestSender:      sender,
+		Results:            resultChannels.ReceiveOnly(),
+		BotPool:            botPool,
+		DeadLetterReplayer: deadLetterReplayer,
+		Coverage:           coverageTracker,
 	}, nil
 }
 
+// newBotDialer creates a dialer which dials the bots with mutual TLS by using the node
+// certificate authority, unless plaintext connections are allowed.
+func newBotDialer(cfg config.Config) (agentgrpc.BotDialer, error) {
+	dialOpts := agentgrpc.DialOptionsFromConfig(cfg.AgentGrpc)
+	if cfg.Chaos.Enable {
+		injector := chaos.NewInjector(cfg.Chaos)
+		dialOpts.UnaryInterceptor = injector.UnaryClientInterceptor()
+		dialOpts.StreamInterceptor = injector.StreamClientInterceptor()
+	}
+	if !cfg.AgentTLSEnabled() {
+		log.Warn("mutual TLS is disabled - dialing the bots over plaintext connections")
+		return agentgrpc.NewBotDialer(nil, dialOpts), nil
+	}
+	ca, err := security.LoadCA(security.TLSDir(cfg.FortaDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the bot certificate authority: %v", err)
+	}
+	tlsConfig, err := ca.ClientTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the bot tls config: %v", err)
+	}
+	return agentgrpc.NewBotDialer(tlsConfig, dialOpts), nil
+}
+
 // BotLifecycleConfig contains bot lifecycle component configuration and dependencies.
 type BotLifecycleConfig struct {
 	Config         config.Config
@@ -106,9 +185,20 @@ func GetBotLifecycleComponents(ctx context.Context, botLifeConfig BotLifecycleCo
 		return BotLifecycle{}, fmt.Errorf("failed to create the bot docker client: %v", err)
 	}
 
+	// the certificate authority is created here so that it exists before the scanner dials the bots
+	var ca *security.CA
+	if cfg.AgentTLSEnabled() {
+		ca, err = security.LoadOrCreateCA(security.TLSDir(cfg.FortaDir))
+		if err != nil {
+			return BotLifecycle{}, fmt.Errorf("failed to load the bot certificate authority: %v", err)
+		}
+	}
+
 	botClient := containers.NewBotClient(
-		botLifeConfig.Config.Log, botLifeConfig.Config.ResourcesConfig,
-		dockerClient, botImageClient,
+		botLifeConfig.Config.Log, botLifeConfig.Config.ResourcesConfig, cfg.AgentNetwork,
+		dockerClient, botImageClient, ca,
+		containers.NewEnvResolver(cfg.AgentEnv, cfg.FortaDir),
+		containers.NewSandbox(cfg.AgentSandbox, cfg.FortaDir),
 	)
 	lifecycleMetrics := metrics.NewLifecycleClient(botLifeConfig.MessageClient)
 	lifecycleMediator := mediator.New(botLifeConfig.MessageClient, lifecycleMetrics)
@@ -116,8 +206,9 @@ func GetBotLifecycleComponents(ctx context.Context, botLifeConfig BotLifecycleCo
 	lifecycleMediator.ConnectBotMonitor(botMonitor)
 	botManager := lifecycle.NewManager(
 		botLifeConfig.BotRegistry, botClient, lifecycleMediator,
-		lifecycleMetrics, botMonitor,
+		lifecycleMetrics, botMonitor, cfg.ResourcesConfig, lifecycleMediator,
 	)
+	lifecycleMediator.ConnectBotManager(botManager)
 
 	return BotLifecycle{
 		BotManager: botManager,
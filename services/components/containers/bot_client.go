@@ -10,6 +10,7 @@ import (
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/security"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -30,54 +31,91 @@ type BotClient interface {
 	StopBot(ctx context.Context, botConfig config.AgentConfig) error
 	LoadBotContainers(ctx context.Context) ([]types.Container, error)
 	StartWaitBotContainer(ctx context.Context, containerID string) error
+	GetBotContainerStats(ctx context.Context, containerID string) (*types.StatsJSON, error)
+	InspectBotContainer(ctx context.Context, containerID string) (*types.ContainerJSON, error)
 }
 
 type botClient struct {
 	logConfig       config.LogConfig
 	resourcesConfig config.ResourcesConfig
+	networkConfig   config.AgentNetworkConfig
 	client          clients.DockerClient
 	botImageClient  clients.DockerClient
+	ca              *security.CA
+	envResolver     *EnvResolver
+	sandbox         *Sandbox
 }
 
-// NewBotClient creates a new bot client to manage bot containers.
+// NewBotClient creates a new bot client to manage bot containers. If the certificate authority
+// is provided, each new bot container receives a certificate to serve with mutual TLS. If the env
+// resolver is provided, the configured environment variables and secrets are injected into the bot
+// containers. The isolated bots are started in the internal networks which reach only the service
+// containers. If the sandbox is provided, the bot containers are hardened with the sandbox settings.
 func NewBotClient(
-	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig,
-	client clients.DockerClient, botImageClient clients.DockerClient,
+	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig, networkConfig config.AgentNetworkConfig,
+	client clients.DockerClient, botImageClient clients.DockerClient, ca *security.CA,
+	envResolver *EnvResolver, sandbox *Sandbox,
 ) *botClient {
 	botImageClient.SetImagePullCooldown(ImagePullCooldownThreshold, ImagePullCooldownDuration)
 	return &botClient{
 		logConfig:       logConfig,
 		resourcesConfig: resourcesConfig,
+		networkConfig:   networkConfig,
 		client:          client,
 		botImageClient:  botImageClient,
+		ca:              ca,
+		envResolver:     envResolver,
+		sandbox:         sandbox,
 	}
 }
 
 var _ BotClient = &botClient{}
 
-// EnsureBotImages ensures that all of the bot images are locally available.
+// EnsureBotImages ensures that all of the bot images are locally available. The attached and HTTP bots
+// have no images so their errors are always nil.
 func (bc *botClient) EnsureBotImages(ctx context.Context, botConfigs []config.AgentConfig) []error {
-	var imagePulls []docker.ImagePull
-	for _, botConfig := range botConfigs {
+	var (
+		imagePulls []docker.ImagePull
+		pullIndex  []int
+	)
+	for i, botConfig := range botConfigs {
+		if botConfig.IsAttached() || botConfig.IsHTTP() {
+			continue
+		}
 		imagePulls = append(imagePulls, docker.ImagePull{
 			Name: botConfig.ID,
 			Ref:  botConfig.Image,
 		})
+		pullIndex = append(pullIndex, i)
+	}
+	errs := make([]error, len(botConfigs))
+	if len(imagePulls) == 0 {
+		return errs
+	}
+	for i, err := range bc.botImageClient.EnsureLocalImages(ctx, BotPullTimeout, imagePulls) {
+		if i < len(pullIndex) {
+			errs[pullIndex[i]] = err
+		}
 	}
-	return bc.botImageClient.EnsureLocalImages(ctx, BotPullTimeout, imagePulls)
+	return errs
 }
 
 // LaunchBot launches a bot by downloading docker image and starting the container.
 // This method can be called when the bot containers are alive and should be able to
 // handle that situation.
 func (bc *botClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig) error {
+	// the attached and the http bots are already running
+	if botConfig.IsAttached() || botConfig.IsHTTP() {
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, BotStartTimeout)
 	defer cancel()
 
 	// first make sure that the bot's bridge network exists
-	botNetworkID, err := bc.client.EnsurePublicNetwork(ctx, botConfig.ContainerName())
+	botNetworkID, err := bc.ensureBotNetwork(ctx, botConfig)
 	if err != nil {
-		return fmt.Errorf("error creating public network: %v", err)
+		return err
 	}
 
 	_, err = bc.client.GetContainerByName(ctx, botConfig.ContainerName())
@@ -87,7 +125,23 @@ func (bc *botClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig
 
 	case errors.Is(err, docker.ErrContainerNotFound):
 		// if the bot container doesn't exist, create and start the container
-		botContainerCfg := NewBotContainerConfig(botNetworkID, botConfig, bc.logConfig, bc.resourcesConfig)
+		botContainerCfg := NewBotContainerConfig(
+			botNetworkID, botConfig, bc.logConfig, bc.resourcesConfig, bc.networkConfig,
+		)
+		// the sandbox decides where the files are added
+		if err := bc.sandbox.Apply(&botContainerCfg, botConfig.ID); err != nil {
+			return err
+		}
+		if bc.ca != nil {
+			if err := AddBotTLSFiles(&botContainerCfg, bc.ca, botConfig); err != nil {
+				return err
+			}
+		}
+		if bc.envResolver != nil {
+			if err := AddBotEnv(ctx, &botContainerCfg, bc.envResolver, botConfig); err != nil {
+				return err
+			}
+		}
 		_, err = bc.client.StartContainer(ctx, botContainerCfg)
 		if err != nil {
 			return fmt.Errorf("failed to start bot container: %v", err)
@@ -103,6 +157,23 @@ func (bc *botClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig
 	return bc.attachServiceContainers(ctx, botNetworkID)
 }
 
+// ensureBotNetwork creates the network of the bot. The isolated bots get an internal network
+// without the egress, so that they can reach only the service containers attached to it.
+func (bc *botClient) ensureBotNetwork(ctx context.Context, botConfig config.AgentConfig) (string, error) {
+	if bc.networkConfig.IsIsolated(botConfig.ID) {
+		botNetworkID, err := bc.client.EnsureInternalNetwork(ctx, botConfig.ContainerName())
+		if err != nil {
+			return "", fmt.Errorf("error creating internal network: %v", err)
+		}
+		return botNetworkID, nil
+	}
+	botNetworkID, err := bc.client.EnsurePublicNetwork(ctx, botConfig.ContainerName())
+	if err != nil {
+		return "", fmt.Errorf("error creating public network: %v", err)
+	}
+	return botNetworkID, nil
+}
+
 func (bc *botClient) attachServiceContainers(ctx context.Context, botNetworkID string) error {
 	serviceContainerIDs, err := bc.getServiceContainerIDs(ctx)
 	if err != nil {
@@ -182,6 +253,9 @@ func (bc *botClient) TearDownBot(ctx context.Context, containerName string, remo
 
 // StopBot shuts down a bot container.
 func (bc *botClient) StopBot(ctx context.Context, botConfig config.AgentConfig) error {
+	if botConfig.IsAttached() || botConfig.IsHTTP() {
+		return nil
+	}
 	container, err := bc.client.GetContainerByName(ctx, botConfig.ContainerName())
 	if err != nil {
 		return fmt.Errorf("failed to get the bot container to stop: %v", err)
@@ -204,3 +278,13 @@ func (bc *botClient) StartWaitBotContainer(ctx context.Context, containerID stri
 	}
 	return bc.client.WaitContainerStart(ctx, containerID)
 }
+
+// GetBotContainerStats returns the current resource usage of the bot container.
+func (bc *botClient) GetBotContainerStats(ctx context.Context, containerID string) (*types.StatsJSON, error) {
+	return bc.client.GetContainerStats(ctx, containerID)
+}
+
+// InspectBotContainer returns the details of the bot container.
+func (bc *botClient) InspectBotContainer(ctx context.Context, containerID string) (*types.ContainerJSON, error) {
+	return bc.client.InspectContainer(ctx, containerID)
+}
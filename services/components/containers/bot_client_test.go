@@ -9,6 +9,7 @@ import (
 	"github.com/forta-network/forta-node/clients/docker"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/security"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -49,7 +50,7 @@ func (s *BotClientTestSuite) SetupTest() {
 
 	s.botImageClient.EXPECT().SetImagePullCooldown(ImagePullCooldownThreshold, ImagePullCooldownDuration)
 
-	s.botClient = NewBotClient(config.LogConfig{}, config.ResourcesConfig{}, s.client, s.botImageClient)
+	s.botClient = NewBotClient(config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{}, s.client, s.botImageClient, nil, nil, nil)
 }
 
 func (s *BotClientTestSuite) TestEnsureBotImages() {
@@ -82,6 +83,40 @@ func (s *BotClientTestSuite) TestEnsureBotImages() {
 	s.r.Equal(retErrs, s.botClient.EnsureBotImages(context.Background(), botConfigs))
 }
 
+func (s *BotClientTestSuite) TestEnsureBotImages_Attached() {
+	botConfigs := []config.AgentConfig{
+		{
+			ID:      testBotID1,
+			Address: "host.docker.internal:50051",
+		},
+		{
+			ID:    testBotID2,
+			Image: testImageRef,
+		},
+	}
+	expectedImagePulls := []docker.ImagePull{
+		{
+			Name: testBotID2,
+			Ref:  testImageRef,
+		},
+	}
+	retErr := errors.New("err2")
+	s.botImageClient.EXPECT().EnsureLocalImages(gomock.Any(), BotPullTimeout, expectedImagePulls).Return([]error{retErr})
+
+	s.r.Equal([]error{nil, retErr}, s.botClient.EnsureBotImages(context.Background(), botConfigs))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_Attached() {
+	botConfig := config.AgentConfig{
+		ID:      testBotID1,
+		Address: "host.docker.internal:50052",
+	}
+
+	// no container calls are expected
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+	s.r.NoError(s.botClient.StopBot(context.Background(), botConfig))
+}
+
 func (s *BotClientTestSuite) TestLaunchBot_Exists() {
 	botConfig := config.AgentConfig{
 		ID:    testBotID1,
@@ -121,7 +156,7 @@ func (s *BotClientTestSuite) TestLaunchBot_DoesNotExist() {
 
 	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
 	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
-	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.LogConfig{}, config.ResourcesConfig{})
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
 	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
 	for _, serviceContainerName := range getServiceContainerNames() {
 		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
@@ -133,6 +168,72 @@ func (s *BotClientTestSuite) TestLaunchBot_DoesNotExist() {
 	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
 }
 
+func (s *BotClientTestSuite) TestLaunchBot_Isolated() {
+	s.botClient.networkConfig = config.AgentNetworkConfig{Isolate: true, EgressBots: []string{testBotID2}}
+
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+
+	s.client.EXPECT().EnsureInternalNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
+	s.client.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, containerCfg docker.ContainerConfig) (*docker.Container, error) {
+			s.r.Equal(LabelValueBotNetworkIsolated, containerCfg.Labels[docker.LabelFortaBotNetwork])
+			return nil, nil
+		})
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+
+	// the egress bots get the public networks
+	botConfig.ID = testBotID2
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, nil)
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_TLS() {
+	ca, err := security.LoadOrCreateCA(s.T().TempDir())
+	s.r.NoError(err)
+	s.botClient.ca = ca
+
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
+	s.client.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, containerCfg docker.ContainerConfig) (*docker.Container, error) {
+			s.r.Equal(ca.CertPEM(), containerCfg.Files[BotTLSCAPath])
+			s.r.NotEmpty(containerCfg.Files[BotTLSCertPath])
+			s.r.NotEmpty(containerCfg.Files[BotTLSKeyPath])
+			s.r.Equal(BotTLSCertPath, containerCfg.Env[config.EnvAgentTLSCertFile])
+			return nil, nil
+		})
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
 func (s *BotClientTestSuite) TestTearDownBot() {
 	botConfig := config.AgentConfig{
 		ID:    testBotID1,
@@ -2,9 +2,11 @@ package containers
 
 import (
 	"fmt"
+	"path"
 
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/security"
 )
 
 // Label values
@@ -16,6 +18,16 @@ const (
 	// LabelValueStrategyVersion is for versioning the critical changes in container management strategy.
 	// It's effective in deciding if a bot container should be re-created or not.
 	LabelValueStrategyVersion = "2023-06-16T15:00:00Z"
+	// the bot containers are recreated when the network isolation of the bot changes
+	LabelValueBotNetworkPublic   = "public"
+	LabelValueBotNetworkIsolated = "isolated"
+)
+
+// Bot TLS file paths in the bot containers
+const (
+	BotTLSCertPath = "/forta-agent-tls-cert.pem"
+	BotTLSKeyPath  = "/forta-agent-tls-key.pem"
+	BotTLSCAPath   = "/forta-agent-tls-ca.pem"
 )
 
 // Limits define container limits.
@@ -27,9 +39,9 @@ type Limits struct {
 // NewBotContainerConfig creates a new bot container config.
 func NewBotContainerConfig(
 	networkID string, botConfig config.AgentConfig,
-	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig,
+	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig, networkConfig config.AgentNetworkConfig,
 ) docker.ContainerConfig {
-	limits := config.GetAgentResourceLimits(resourcesConfig)
+	limits := config.GetAgentResourceLimits(resourcesConfig, botConfig.ID)
 
 	return docker.ContainerConfig{
 		Name:           botConfig.ContainerName(),
@@ -43,6 +55,10 @@ func NewBotContainerConfig(
 			config.EnvJWTProviderPort:    config.DefaultJWTProviderPort,
 			config.EnvPublicAPIProxyHost: config.DockerPublicAPIProxyContainerName,
 			config.EnvPublicAPIProxyPort: config.DefaultPublicAPIProxyPort,
+			config.EnvHistoryAPIHost:     config.DockerJSONRPCProxyContainerName,
+			config.EnvHistoryAPIPort:     config.DefaultHistoryAPIPort,
+			config.EnvAgentStoreAPIHost:  config.DockerJSONRPCProxyContainerName,
+			config.EnvAgentStoreAPIPort:  config.DefaultAgentStoreAPIPort,
 			config.EnvAgentGrpcPort:      botConfig.GrpcPort(),
 			config.EnvFortaBotID:         botConfig.ID,
 			config.EnvFortaBotOwner:      botConfig.Owner,
@@ -56,6 +72,30 @@ func NewBotContainerConfig(
 			docker.LabelFortaIsBot:                     LabelValueFortaIsBot,
 			docker.LabelFortaSupervisorStrategyVersion: LabelValueStrategyVersion,
 			docker.LabelFortaBotID:                     botConfig.ID,
+			docker.LabelFortaBotNetwork:                BotNetworkLabelValue(networkConfig.IsIsolated(botConfig.ID)),
 		},
 	}
 }
+
+// AddBotTLSFiles issues a certificate for the bot and adds it to the container config with the CA
+// certificate so that the bot can serve with mutual TLS.
+func AddBotTLSFiles(containerCfg *docker.ContainerConfig, ca *security.CA, botConfig config.AgentConfig) error {
+	certPEM, keyPEM, err := ca.IssueCert(botConfig.ID, botConfig.ContainerName())
+	if err != nil {
+		return fmt.Errorf("failed to issue bot cert: %v", err)
+	}
+	if containerCfg.Files == nil {
+		containerCfg.Files = make(map[string][]byte)
+	}
+	// the sandboxed containers receive the files under the files dir
+	certPath := path.Join(containerCfg.FilesDir, BotTLSCertPath)
+	keyPath := path.Join(containerCfg.FilesDir, BotTLSKeyPath)
+	caPath := path.Join(containerCfg.FilesDir, BotTLSCAPath)
+	containerCfg.Files[certPath] = certPEM
+	containerCfg.Files[keyPath] = keyPEM
+	containerCfg.Files[caPath] = ca.CertPEM()
+	containerCfg.Env[config.EnvAgentTLSCertFile] = certPath
+	containerCfg.Env[config.EnvAgentTLSKeyFile] = keyPath
+	containerCfg.Env[config.EnvAgentTLSCAFile] = caPath
+	return nil
+}
@@ -0,0 +1,152 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const vaultRequestTimeout = time.Second * 10
+
+// EnvResolver resolves the environment variables and the secrets of the bots from the config.
+type EnvResolver struct {
+	cfg        config.AgentEnvConfig
+	fortaDir   string
+	httpClient *http.Client
+}
+
+// NewEnvResolver creates a new env resolver. The secret files are relative to the forta dir.
+func NewEnvResolver(cfg config.AgentEnvConfig, fortaDir string) *EnvResolver {
+	return &EnvResolver{
+		cfg:        cfg,
+		fortaDir:   fortaDir,
+		httpClient: &http.Client{Timeout: vaultRequestTimeout},
+	}
+}
+
+// Resolve returns the environment variables of the bot, including the secret values.
+func (er *EnvResolver) Resolve(ctx context.Context, botID string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, botEnv := range er.cfg.Bots {
+		if !strings.EqualFold(botEnv.BotID, botID) {
+			continue
+		}
+		for name, value := range botEnv.Env {
+			env[name] = value
+		}
+		for _, secret := range botEnv.Secrets {
+			value, err := er.resolveSecret(ctx, secret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve secret %s of bot %s: %v", secret.Name, botID, err)
+			}
+			env[secret.Name] = value
+		}
+	}
+	return env, nil
+}
+
+func (er *EnvResolver) resolveSecret(ctx context.Context, secret config.BotSecretConfig) (string, error) {
+	switch {
+	case len(secret.File) > 0:
+		return er.readFile(secret.File)
+	case len(secret.FromEnv) > 0:
+		value, ok := os.LookupEnv(secret.FromEnv)
+		if !ok {
+			return "", fmt.Errorf("env var %s is not set", secret.FromEnv)
+		}
+		return value, nil
+	case len(secret.VaultPath) > 0:
+		return er.readVault(ctx, secret.VaultPath, secret.VaultKey)
+	default:
+		return "", fmt.Errorf("no secret source")
+	}
+}
+
+func (er *EnvResolver) readFile(filePath string) (string, error) {
+	if !path.IsAbs(filePath) {
+		filePath = path.Join(er.fortaDir, filePath)
+	}
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %v", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// readVault reads the key of the secret from Vault. Both the KV version 1 and the version 2
+// responses are supported.
+func (er *EnvResolver) readVault(ctx context.Context, secretPath, key string) (string, error) {
+	if len(er.cfg.Vault.Address) == 0 {
+		return "", fmt.Errorf("vault address is not configured")
+	}
+	token := er.cfg.Vault.Token
+	if len(er.cfg.Vault.TokenFile) > 0 {
+		var err error
+		if token, err = er.readFile(er.cfg.Vault.TokenFile); err != nil {
+			return "", err
+		}
+	}
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(er.cfg.Vault.Address, "/"), strings.TrimPrefix(secretPath, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %v", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := er.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send vault request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %v", err)
+	}
+	data := body.Data
+	// the kv version 2 secrets are nested
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret has no key %s", key)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// AddBotEnv adds the resolved environment variables of the bot to the container config. The
+// variables which the node sets for the bots are not overridden.
+func AddBotEnv(ctx context.Context, containerCfg *docker.ContainerConfig, resolver *EnvResolver, botConfig config.AgentConfig) error {
+	env, err := resolver.Resolve(ctx, botConfig.ID)
+	if err != nil {
+		return err
+	}
+	for name, value := range env {
+		if _, ok := containerCfg.Env[name]; ok {
+			log.WithFields(log.Fields{
+				"botId": botConfig.ID,
+				"env":   name,
+			}).Warn("skipping the bot env var which is reserved by the node")
+			continue
+		}
+		containerCfg.Env[name] = value
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package containers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvResolver(t *testing.T) {
+	r := require.New(t)
+
+	fortaDir := t.TempDir()
+	r.NoError(os.WriteFile(path.Join(fortaDir, "api-key"), []byte("file-secret\n"), 0600))
+	r.NoError(os.WriteFile(path.Join(fortaDir, "vault-token"), []byte("token1"), 0600))
+	t.Setenv("TEST_BOT_SECRET", "env-secret")
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "token1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/secret/data/bot":
+			w.Write([]byte(`{"data":{"data":{"key":"vault-v2-secret"}}}`))
+		case "/v1/kv/bot":
+			w.Write([]byte(`{"data":{"key":"vault-v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	resolver := NewEnvResolver(config.AgentEnvConfig{
+		Vault: config.VaultConfig{Address: vault.URL, TokenFile: "vault-token"},
+		Bots: []config.BotEnvConfig{
+			{
+				BotID: "0xBOT",
+				Env:   map[string]string{"MODE": "full", config.EnvFortaBotID: "0xother"},
+				Secrets: []config.BotSecretConfig{
+					{Name: "FILE_SECRET", File: "api-key"},
+					{Name: "ENV_SECRET", FromEnv: "TEST_BOT_SECRET"},
+					{Name: "VAULT_V2_SECRET", VaultPath: "secret/data/bot", VaultKey: "key"},
+					{Name: "VAULT_V1_SECRET", VaultPath: "/kv/bot", VaultKey: "key"},
+				},
+			},
+			{
+				BotID:   "0xbroken",
+				Secrets: []config.BotSecretConfig{{Name: "MISSING", VaultPath: "missing", VaultKey: "key"}},
+			},
+		},
+	}, fortaDir)
+
+	botConfig := config.AgentConfig{ID: "0xbot"}
+	containerCfg := NewBotContainerConfig("network", botConfig, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
+	r.NoError(AddBotEnv(context.Background(), &containerCfg, resolver, botConfig))
+	r.Equal("full", containerCfg.Env["MODE"])
+	r.Equal("file-secret", containerCfg.Env["FILE_SECRET"])
+	r.Equal("env-secret", containerCfg.Env["ENV_SECRET"])
+	r.Equal("vault-v2-secret", containerCfg.Env["VAULT_V2_SECRET"])
+	r.Equal("vault-v1-secret", containerCfg.Env["VAULT_V1_SECRET"])
+	// the reserved env vars are not overridden
+	r.Equal("0xbot", containerCfg.Env[config.EnvFortaBotID])
+
+	containerCfg = docker.ContainerConfig{Env: map[string]string{}}
+	r.Error(AddBotEnv(context.Background(), &containerCfg, resolver, config.AgentConfig{ID: "0xbroken"}))
+
+	env, err := resolver.Resolve(context.Background(), "0xunknown")
+	r.NoError(err)
+	r.Empty(env)
+}
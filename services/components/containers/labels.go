@@ -10,6 +10,24 @@ func HasSameLabelValue(container *types.Container, key, value string) bool {
 	return container.Labels[key] == value
 }
 
+// BotNetworkLabelValue returns the label value of the bot network.
+func BotNetworkLabelValue(isolated bool) string {
+	if isolated {
+		return LabelValueBotNetworkIsolated
+	}
+	return LabelValueBotNetworkPublic
+}
+
+// HasBotNetwork checks if the bot container was started in the isolated or the public network. The
+// containers without the label were started before the isolation and are in the public networks.
+func HasBotNetwork(container *types.Container, isolated bool) bool {
+	value, ok := container.Labels[docker.LabelFortaBotNetwork]
+	if !ok {
+		value = LabelValueBotNetworkPublic
+	}
+	return value == BotNetworkLabelValue(isolated)
+}
+
 // IsBotContainer checks if given container is a bot container by looking at the label value.
 func IsBotContainer(container *types.Container) bool {
 	return HasSameLabelValue(container, docker.LabelFortaIsBot, LabelValueFortaIsBot)
@@ -50,6 +50,36 @@ func (mr *MockBotClientMockRecorder) EnsureBotImages(ctx, botConfigs interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureBotImages", reflect.TypeOf((*MockBotClient)(nil).EnsureBotImages), ctx, botConfigs)
 }
 
+// GetBotContainerStats mocks base method.
+func (m *MockBotClient) GetBotContainerStats(ctx context.Context, containerID string) (*types.StatsJSON, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotContainerStats", ctx, containerID)
+	ret0, _ := ret[0].(*types.StatsJSON)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotContainerStats indicates an expected call of GetBotContainerStats.
+func (mr *MockBotClientMockRecorder) GetBotContainerStats(ctx, containerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotContainerStats", reflect.TypeOf((*MockBotClient)(nil).GetBotContainerStats), ctx, containerID)
+}
+
+// InspectBotContainer mocks base method.
+func (m *MockBotClient) InspectBotContainer(ctx context.Context, containerID string) (*types.ContainerJSON, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectBotContainer", ctx, containerID)
+	ret0, _ := ret[0].(*types.ContainerJSON)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectBotContainer indicates an expected call of InspectBotContainer.
+func (mr *MockBotClientMockRecorder) InspectBotContainer(ctx, containerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectBotContainer", reflect.TypeOf((*MockBotClient)(nil).InspectBotContainer), ctx, containerID)
+}
+
 // LaunchBot mocks base method.
 func (m *MockBotClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,109 @@
+package containers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+)
+
+// BotFilesDir is the directory which the files are copied into in the sandboxed bot containers.
+const BotFilesDir = "/forta-agent-files"
+
+// LabelValueBotSandboxNone is the sandbox label value of the bot containers which are not sandboxed.
+const LabelValueBotSandboxNone = "none"
+
+// Sandbox hardens the bot containers with the sandbox settings of the bots.
+type Sandbox struct {
+	cfg      config.AgentSandboxConfig
+	fortaDir string
+}
+
+// NewSandbox creates a new sandbox. The seccomp profile is relative to the forta dir.
+func NewSandbox(cfg config.AgentSandboxConfig, fortaDir string) *Sandbox {
+	return &Sandbox{cfg: cfg, fortaDir: fortaDir}
+}
+
+// Apply adds the sandbox settings of the bot to the container config. The container keeps no
+// capabilities other than the allowed ones and cannot gain new privileges. The files of the
+// container should be added after this, under the files dir of the container.
+func (sb *Sandbox) Apply(containerCfg *docker.ContainerConfig, botID string) error {
+	if sb == nil {
+		return nil
+	}
+	sandbox := config.GetBotSandbox(sb.cfg, botID)
+	if containerCfg.Labels == nil {
+		containerCfg.Labels = make(map[string]string)
+	}
+	containerCfg.Labels[docker.LabelFortaBotSandbox] = BotSandboxLabelValue(sandbox)
+	if sandbox == nil {
+		return nil
+	}
+
+	containerCfg.ReadOnlyRootfs = sandbox.ReadOnlyRootfs
+	if sandbox.ReadOnlyRootfs {
+		containerCfg.FilesDir = BotFilesDir
+	}
+	containerCfg.CapDrop = []string{"ALL"}
+	containerCfg.CapAdd = sandbox.Capabilities
+	containerCfg.SecurityOpt = []string{"no-new-privileges:true"}
+	switch {
+	case sandbox.Unconfined:
+		containerCfg.SecurityOpt = append(containerCfg.SecurityOpt, "seccomp=unconfined")
+	case len(sandbox.SeccompProfile) > 0:
+		profile, err := sb.readProfile(sandbox.SeccompProfile)
+		if err != nil {
+			return err
+		}
+		containerCfg.SecurityOpt = append(containerCfg.SecurityOpt, "seccomp="+profile)
+	}
+	containerCfg.Tmpfs = make(map[string]string)
+	for _, dir := range sandbox.TmpfsDirs {
+		containerCfg.Tmpfs[dir] = fmt.Sprintf("rw,nosuid,nodev,size=%dm", sandbox.TmpfsSizeMiB)
+	}
+	return nil
+}
+
+// readProfile reads the seccomp profile as compact JSON, as the docker API expects it.
+func (sb *Sandbox) readProfile(profilePath string) (string, error) {
+	if !path.IsAbs(profilePath) {
+		profilePath = path.Join(sb.fortaDir, profilePath)
+	}
+	b, err := os.ReadFile(profilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read seccomp profile: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return "", fmt.Errorf("invalid seccomp profile: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// BotSandboxLabelValue returns the label value of the bot sandbox, so that the bot containers are
+// recreated when the sandbox of the bot changes.
+func BotSandboxLabelValue(sandbox *config.BotSandbox) string {
+	if sandbox == nil {
+		return LabelValueBotSandboxNone
+	}
+	b, _ := json.Marshal(sandbox)
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:8])
+}
+
+// HasBotSandbox checks if the bot container was started with the sandbox. The containers without
+// the label were started before the sandbox and are not sandboxed.
+func HasBotSandbox(container *types.Container, sandbox *config.BotSandbox) bool {
+	value, ok := container.Labels[docker.LabelFortaBotSandbox]
+	if !ok {
+		value = LabelValueBotSandboxNone
+	}
+	return value == BotSandboxLabelValue(sandbox)
+}
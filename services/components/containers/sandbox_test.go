@@ -0,0 +1,77 @@
+package containers
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/security"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox(t *testing.T) {
+	r := require.New(t)
+
+	fortaDir := t.TempDir()
+	r.NoError(os.WriteFile(path.Join(fortaDir, "seccomp.json"), []byte("{\n  \"defaultAction\": \"SCMP_ACT_ERRNO\"\n}"), 0644))
+	sandbox := NewSandbox(config.AgentSandboxConfig{
+		Enable:         true,
+		Capabilities:   []string{"NET_BIND_SERVICE"},
+		SeccompProfile: "seccomp.json",
+		TmpfsSizeMiB:   64,
+		Overrides:      []config.AgentSandboxOverrideConfig{{BotID: "0xbot2", WritableRootfs: true, Unconfined: true}},
+	}, fortaDir)
+
+	botConfig := config.AgentConfig{ID: "0xbot1"}
+	containerCfg := NewBotContainerConfig("network", botConfig, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
+	r.NoError(sandbox.Apply(&containerCfg, botConfig.ID))
+	r.True(containerCfg.ReadOnlyRootfs)
+	r.Equal(BotFilesDir, containerCfg.FilesDir)
+	r.Equal([]string{"ALL"}, containerCfg.CapDrop)
+	r.Equal([]string{"NET_BIND_SERVICE"}, containerCfg.CapAdd)
+	r.Equal([]string{"no-new-privileges:true", `seccomp={"defaultAction":"SCMP_ACT_ERRNO"}`}, containerCfg.SecurityOpt)
+	r.Equal(map[string]string{"/tmp": "rw,nosuid,nodev,size=64m"}, containerCfg.Tmpfs)
+
+	// the files are added under the files dir, since the root filesystem is read-only
+	ca, err := security.LoadOrCreateCA(t.TempDir())
+	r.NoError(err)
+	r.NoError(AddBotTLSFiles(&containerCfg, ca, botConfig))
+	r.Contains(containerCfg.Files, path.Join(BotFilesDir, BotTLSCertPath))
+	r.Equal(path.Join(BotFilesDir, BotTLSCertPath), containerCfg.Env[config.EnvAgentTLSCertFile])
+
+	container := &types.Container{Labels: containerCfg.Labels}
+	r.True(HasBotSandbox(container, config.GetBotSandbox(sandbox.cfg, botConfig.ID)))
+	r.False(HasBotSandbox(container, nil))
+
+	// the override relaxes the sandbox
+	containerCfg = NewBotContainerConfig("network", config.AgentConfig{ID: "0xbot2"}, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
+	r.NoError(sandbox.Apply(&containerCfg, "0xbot2"))
+	r.False(containerCfg.ReadOnlyRootfs)
+	r.Empty(containerCfg.FilesDir)
+	r.Equal([]string{"no-new-privileges:true", "seccomp=unconfined"}, containerCfg.SecurityOpt)
+}
+
+func TestSandbox_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	containerCfg := NewBotContainerConfig("network", config.AgentConfig{ID: "0xbot1"}, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
+	r.NoError(NewSandbox(config.AgentSandboxConfig{}, "").Apply(&containerCfg, "0xbot1"))
+	r.False(containerCfg.ReadOnlyRootfs)
+	r.Empty(containerCfg.CapDrop)
+	r.Equal(LabelValueBotSandboxNone, containerCfg.Labels[docker.LabelFortaBotSandbox])
+
+	// the containers without the label are not sandboxed
+	r.True(HasBotSandbox(&types.Container{}, nil))
+
+	var sandbox *Sandbox
+	r.NoError(sandbox.Apply(&containerCfg, "0xbot1"))
+}
+
+func TestSandbox_MissingProfile(t *testing.T) {
+	containerCfg := NewBotContainerConfig("network", config.AgentConfig{ID: "0xbot1"}, config.LogConfig{}, config.ResourcesConfig{}, config.AgentNetworkConfig{})
+	sandbox := NewSandbox(config.AgentSandboxConfig{Enable: true, SeccompProfile: "missing.json", TmpfsSizeMiB: 64}, t.TempDir())
+	require.Error(t, sandbox.Apply(&containerCfg, "0xbot1"))
+}
@@ -0,0 +1,241 @@
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const publishTimeout = 30 * time.Second
+
+// Tracker counts the blocks which the node sees, processes and dispatches to the bots and the
+// blocks which the bots acknowledge, and saves a coverage report at the end of each period. The
+// blocks which are acknowledged after the end of a period are counted in the next report. The
+// methods of a nil tracker do nothing.
+type Tracker struct {
+	ctx        context.Context
+	cfg        config.CoverageConfig
+	store      store.CoverageStore
+	httpClient *http.Client
+
+	start  time.Time
+	chains map[uint64]*chainCoverage
+	mu     sync.Mutex
+}
+
+type blockSet map[uint64]struct{}
+
+type chainCoverage struct {
+	seen      uint64
+	processed blockSet
+	agents    map[string]*agentCoverage
+}
+
+type agentCoverage struct {
+	dispatched   blockSet
+	acknowledged blockSet
+}
+
+// NewTracker creates a new coverage tracker.
+func NewTracker(ctx context.Context, cfg config.CoverageConfig, reports store.CoverageStore) *Tracker {
+	return &Tracker{
+		ctx:        ctx,
+		cfg:        cfg,
+		store:      reports,
+		httpClient: &http.Client{Timeout: publishTimeout},
+		start:      time.Now(),
+		chains:     make(map[uint64]*chainCoverage),
+	}
+}
+
+// Seen counts a block which the node received from the feed.
+func (t *Tracker) Seen(chainID uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.chain(chainID).seen++
+}
+
+// Processed counts a block which the node sent to the bots.
+func (t *Tracker) Processed(chainID, blockNumber uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.chain(chainID).processed[blockNumber] = struct{}{}
+}
+
+// Dispatched counts a block request which was queued for the bot.
+func (t *Tracker) Dispatched(chainID uint64, botID string, blockNumber uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.agent(chainID, botID).dispatched[blockNumber] = struct{}{}
+}
+
+// Acknowledged counts a block request which the bot responded to successfully.
+func (t *Tracker) Acknowledged(chainID uint64, botID string, blockNumber uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.agent(chainID, botID).acknowledged[blockNumber] = struct{}{}
+}
+
+func (t *Tracker) chain(chainID uint64) *chainCoverage {
+	chain, ok := t.chains[chainID]
+	if !ok {
+		chain = &chainCoverage{processed: make(blockSet), agents: make(map[string]*agentCoverage)}
+		t.chains[chainID] = chain
+	}
+	return chain
+}
+
+func (t *Tracker) agent(chainID uint64, botID string) *agentCoverage {
+	chain := t.chain(chainID)
+	agent, ok := chain.agents[botID]
+	if !ok {
+		agent = &agentCoverage{dispatched: make(blockSet), acknowledged: make(blockSet)}
+		chain.agents[botID] = agent
+	}
+	return agent
+}
+
+// Start implements services.Service.
+func (t *Tracker) Start() error {
+	go func() {
+		ticker := time.NewTicker(time.Duration(t.cfg.IntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.ctx.Done():
+				return
+			case <-ticker.C:
+				t.Report()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service. The last report covers the period until the shutdown.
+func (t *Tracker) Stop() error {
+	t.Report()
+	return nil
+}
+
+// Name implements services.Service.
+func (t *Tracker) Name() string {
+	return "coverage"
+}
+
+// Report saves the report of the current period and starts the next period.
+func (t *Tracker) Report() *store.CoverageReport {
+	t.mu.Lock()
+	report := t.makeReport(time.Now())
+	t.start = report.End
+	t.chains = make(map[uint64]*chainCoverage)
+	t.mu.Unlock()
+
+	if err := t.store.PutCoverageReport(report); err != nil {
+		log.WithError(err).Warn("failed to save the coverage report")
+	}
+	if len(t.cfg.PublishURL) > 0 {
+		if err := t.publish(report); err != nil {
+			log.WithError(err).Warn("failed to publish the coverage report")
+		}
+	}
+	return report
+}
+
+func (t *Tracker) makeReport(end time.Time) *store.CoverageReport {
+	report := &store.CoverageReport{Start: t.start.UTC(), End: end.UTC()}
+	for chainID, chain := range t.chains {
+		chainReport := &store.ChainCoverage{
+			ChainID:         chainID,
+			Seen:            chain.seen,
+			Processed:       uint64(len(chain.processed)),
+			ProcessedBlocks: chain.processed.ranges(),
+		}
+		for botID, agent := range chain.agents {
+			unacknowledged := make(blockSet)
+			for blockNumber := range agent.dispatched {
+				if _, ok := agent.acknowledged[blockNumber]; !ok {
+					unacknowledged[blockNumber] = struct{}{}
+				}
+			}
+			chainReport.Agents = append(chainReport.Agents, &store.AgentCoverage{
+				ID:                   botID,
+				Dispatched:           uint64(len(agent.dispatched)),
+				Acknowledged:         uint64(len(agent.acknowledged)),
+				AcknowledgedBlocks:   agent.acknowledged.ranges(),
+				UnacknowledgedBlocks: unacknowledged.ranges(),
+			})
+		}
+		sort.Slice(chainReport.Agents, func(i, j int) bool {
+			return chainReport.Agents[i].ID < chainReport.Agents[j].ID
+		})
+		report.Chains = append(report.Chains, chainReport)
+	}
+	sort.Slice(report.Chains, func(i, j int) bool {
+		return report.Chains[i].ChainID < report.Chains[j].ChainID
+	})
+	return report
+}
+
+// ranges returns the blocks in the set as the ranges of the consecutive blocks.
+func (set blockSet) ranges() []store.BlockRange {
+	blockNumbers := make([]uint64, 0, len(set))
+	for blockNumber := range set {
+		blockNumbers = append(blockNumbers, blockNumber)
+	}
+	sort.Slice(blockNumbers, func(i, j int) bool {
+		return blockNumbers[i] < blockNumbers[j]
+	})
+	var ranges []store.BlockRange
+	for _, blockNumber := range blockNumbers {
+		if n := len(ranges); n > 0 && ranges[n-1].To+1 == blockNumber {
+			ranges[n-1].To = blockNumber
+			continue
+		}
+		ranges = append(ranges, store.BlockRange{From: blockNumber, To: blockNumber})
+	}
+	return ranges
+}
+
+// publish posts the report to the publish URL.
+func (t *Tracker) publish(report *store.CoverageReport) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode the report: %v", err)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, t.cfg.PublishURL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create the request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post the report: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package coverage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+type testStore struct {
+	reports []*store.CoverageReport
+}
+
+func (ts *testStore) PutCoverageReport(report *store.CoverageReport) error {
+	ts.reports = append(ts.reports, report)
+	return nil
+}
+
+func (ts *testStore) ListCoverageReports() ([]*store.CoverageReport, error) {
+	return ts.reports, nil
+}
+
+func TestTracker(t *testing.T) {
+	r := require.New(t)
+
+	published := make(chan *store.CoverageReport, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var report store.CoverageReport
+		r.NoError(json.NewDecoder(req.Body).Decode(&report))
+		published <- &report
+	}))
+	defer server.Close()
+
+	reports := &testStore{}
+	tracker := NewTracker(context.Background(), config.CoverageConfig{PublishURL: server.URL}, reports)
+	for blockNumber := uint64(10); blockNumber <= 14; blockNumber++ {
+		tracker.Seen(1)
+		// block 12 is skipped
+		if blockNumber == 12 {
+			continue
+		}
+		tracker.Processed(1, blockNumber)
+		tracker.Dispatched(1, "0xbot1", blockNumber)
+		tracker.Dispatched(1, "0xbot2", blockNumber)
+		tracker.Acknowledged(1, "0xbot1", blockNumber)
+		// bot 2 misses block 14
+		if blockNumber < 14 {
+			tracker.Acknowledged(1, "0xbot2", blockNumber)
+		}
+	}
+	tracker.Seen(137)
+
+	report := tracker.Report()
+	r.Len(reports.reports, 1)
+	r.Len(report.Chains, 2)
+
+	chain := report.Chains[0]
+	r.Equal(uint64(1), chain.ChainID)
+	r.Equal(uint64(5), chain.Seen)
+	r.Equal(uint64(4), chain.Processed)
+	r.Equal([]store.BlockRange{{From: 10, To: 11}, {From: 13, To: 14}}, chain.ProcessedBlocks)
+	r.Len(chain.Agents, 2)
+	r.Equal("0xbot1", chain.Agents[0].ID)
+	r.Equal(uint64(4), chain.Agents[0].Acknowledged)
+	r.Empty(chain.Agents[0].UnacknowledgedBlocks)
+	r.Equal(uint64(4), chain.Agents[1].Dispatched)
+	r.Equal(uint64(3), chain.Agents[1].Acknowledged)
+	r.Equal([]store.BlockRange{{From: 14, To: 14}}, chain.Agents[1].UnacknowledgedBlocks)
+
+	r.Equal(uint64(137), report.Chains[1].ChainID)
+	r.Equal(uint64(1), report.Chains[1].Seen)
+
+	r.Equal(uint64(5), (<-published).Chains[0].Seen)
+
+	// the next report starts from the end of the previous one
+	next := tracker.Report()
+	r.Equal(report.End, next.Start)
+	r.Empty(next.Chains)
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+	tracker.Seen(1)
+	tracker.Processed(1, 1)
+	tracker.Dispatched(1, "0xbot", 1)
+	tracker.Acknowledged(1, "0xbot", 1)
+}
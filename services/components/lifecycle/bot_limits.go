@@ -0,0 +1,138 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+)
+
+// Limited resources
+const (
+	ResourceCPU    = "cpu"
+	ResourceMemory = "memory"
+)
+
+// the containers cannot use more than their limits so the usage which is very
+// close to a limit is counted as a violation
+const limitViolationRatio = 0.95
+
+// BotLimitNotifier lets the other services know about the bots which exceeded their resource limits.
+type BotLimitNotifier interface {
+	NotifyLimitExceeded(messaging.AgentLimitPayload) error
+}
+
+// botUsage is the previous usage sample and the consecutive violations of a bot container.
+type botUsage struct {
+	read       time.Time
+	cpuTotal   uint64
+	violations map[string]int
+}
+
+// limitTracker tracks the resource usage of the bot containers by container name.
+type limitTracker struct {
+	usages      map[string]*botUsage
+	oomReported map[string]string
+}
+
+func newLimitTracker() *limitTracker {
+	return &limitTracker{
+		usages:      make(map[string]*botUsage),
+		oomReported: make(map[string]string),
+	}
+}
+
+// Check saves the stats sample of the container and returns the exceeded limit if the container
+// has been at the limit for the given amount of consecutive checks.
+func (lt *limitTracker) Check(
+	containerName string, stats *types.StatsJSON, limits *config.BotResourceLimits, maxViolations int,
+) *messaging.AgentLimitPayload {
+	if maxViolations < 1 {
+		maxViolations = 1
+	}
+
+	usage, ok := lt.usages[containerName]
+	if !ok {
+		usage = &botUsage{violations: make(map[string]int)}
+		lt.usages[containerName] = usage
+	}
+
+	exceeded := make(map[string]*messaging.AgentLimitPayload)
+	if limits.Memory > 0 {
+		memory := float64(memoryUsage(stats))
+		if memory >= float64(limits.Memory)*limitViolationRatio {
+			exceeded[ResourceMemory] = &messaging.AgentLimitPayload{
+				Resource: ResourceMemory,
+				Usage:    memory,
+				Limit:    float64(limits.Memory),
+			}
+		}
+	}
+	// the cpu usage is calculated by using the previous sample and the total usage is reset if
+	// the container was restarted since the previous sample
+	cpuTotal := stats.CPUStats.CPUUsage.TotalUsage
+	if limits.CPUQuota > 0 && !usage.read.IsZero() && stats.Read.After(usage.read) && cpuTotal >= usage.cpuTotal {
+		cpus := float64(cpuTotal-usage.cpuTotal) / float64(stats.Read.Sub(usage.read).Nanoseconds())
+		if cpus >= limits.CPUs()*limitViolationRatio {
+			exceeded[ResourceCPU] = &messaging.AgentLimitPayload{
+				Resource: ResourceCPU,
+				Usage:    cpus,
+				Limit:    limits.CPUs(),
+			}
+		}
+	}
+	usage.read = stats.Read
+	usage.cpuTotal = cpuTotal
+
+	for _, resource := range []string{ResourceMemory, ResourceCPU} {
+		if exceeded[resource] == nil {
+			usage.violations[resource] = 0
+			continue
+		}
+		usage.violations[resource]++
+		if usage.violations[resource] >= maxViolations {
+			// start over since the container is going to be restarted
+			delete(lt.usages, containerName)
+			return exceeded[resource]
+		}
+	}
+	return nil
+}
+
+// ReportOOM tells if the out-of-memory exit of the container was not reported before.
+func (lt *limitTracker) ReportOOM(containerName string, finishedAt string) bool {
+	if lt.oomReported[containerName] == finishedAt {
+		return false
+	}
+	lt.oomReported[containerName] = finishedAt
+	return true
+}
+
+// Prune deletes the data of the containers which are not in the given set.
+func (lt *limitTracker) Prune(containerNames map[string]bool) {
+	for containerName := range lt.usages {
+		if !containerNames[containerName] {
+			delete(lt.usages, containerName)
+		}
+	}
+	for containerName := range lt.oomReported {
+		if !containerNames[containerName] {
+			delete(lt.oomReported, containerName)
+		}
+	}
+}
+
+// memoryUsage returns the memory usage without the inactive page cache, like the docker CLI.
+func memoryUsage(stats *types.StatsJSON) uint64 {
+	memStats := stats.MemoryStats
+	// cgroup v1 and v2 use different keys
+	inactive, ok := memStats.Stats["total_inactive_file"]
+	if !ok {
+		inactive = memStats.Stats["inactive_file"]
+	}
+	if inactive > memStats.Usage {
+		return 0
+	}
+	return memStats.Usage - inactive
+}
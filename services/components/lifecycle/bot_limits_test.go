@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLimitsContainer = "test-container"
+)
+
+func testStats(read time.Time, cpuTotal, memory uint64) *types.StatsJSON {
+	var stats types.StatsJSON
+	stats.Read = read
+	stats.CPUStats.CPUUsage.TotalUsage = cpuTotal
+	stats.MemoryStats.Usage = memory
+	stats.MemoryStats.Stats = map[string]uint64{"inactive_file": 100}
+	return &stats
+}
+
+func TestLimitTrackerCPU(t *testing.T) {
+	r := require.New(t)
+
+	lt := newLimitTracker()
+	limits := &config.BotResourceLimits{CPUQuota: config.CPUsToMicroseconds(0.5)}
+	now := time.Now()
+
+	// the first sample cannot tell the cpu usage
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, 0, 0), limits, 2))
+
+	// using half a cpu for a second
+	now = now.Add(time.Second)
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, uint64(time.Second/2), 0), limits, 2))
+	r.Equal(1, lt.usages[testLimitsContainer].violations[ResourceCPU])
+
+	// the violations should reset with less usage
+	now = now.Add(time.Second)
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, uint64(time.Second/2)+1000, 0), limits, 2))
+	r.Equal(0, lt.usages[testLimitsContainer].violations[ResourceCPU])
+
+	now = now.Add(time.Second)
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, uint64(time.Second), 0), limits, 2))
+	now = now.Add(time.Second)
+	exceeded := lt.Check(testLimitsContainer, testStats(now, uint64(time.Second*3/2), 0), limits, 2)
+	r.NotNil(exceeded)
+	r.Equal(ResourceCPU, exceeded.Resource)
+	r.InDelta(0.5, exceeded.Usage, 0.01)
+	r.Equal(0.5, exceeded.Limit)
+
+	// should start over after exceeding
+	r.NotContains(lt.usages, testLimitsContainer)
+}
+
+func TestLimitTrackerMemory(t *testing.T) {
+	r := require.New(t)
+
+	lt := newLimitTracker()
+	limits := &config.BotResourceLimits{Memory: 1000}
+	now := time.Now()
+
+	// the inactive page cache should not be counted
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, 0, 1000), limits, 1))
+
+	exceeded := lt.Check(testLimitsContainer, testStats(now.Add(time.Second), 0, 1100), limits, 1)
+	r.NotNil(exceeded)
+	r.Equal(ResourceMemory, exceeded.Resource)
+	r.Equal(float64(1000), exceeded.Usage)
+	r.Equal(float64(1000), exceeded.Limit)
+}
+
+func TestLimitTrackerNoLimits(t *testing.T) {
+	r := require.New(t)
+
+	lt := newLimitTracker()
+	limits := &config.BotResourceLimits{}
+	now := time.Now()
+
+	r.Nil(lt.Check(testLimitsContainer, testStats(now, 0, 1000), limits, 1))
+	r.Nil(lt.Check(testLimitsContainer, testStats(now.Add(time.Second), uint64(time.Second), 1000), limits, 1))
+}
+
+func TestLimitTrackerOOM(t *testing.T) {
+	r := require.New(t)
+
+	lt := newLimitTracker()
+	r.True(lt.ReportOOM(testLimitsContainer, "1"))
+	r.False(lt.ReportOOM(testLimitsContainer, "1"))
+	r.True(lt.ReportOOM(testLimitsContainer, "2"))
+
+	lt.Prune(map[string]bool{})
+	r.True(lt.ReportOOM(testLimitsContainer, "2"))
+}
@@ -1,6 +1,8 @@
 package lifecycle
 
 import (
+	"strings"
+
 	"github.com/forta-network/forta-node/config"
 )
 
@@ -77,3 +79,23 @@ func FindBot(containerName string, botList []config.AgentConfig) (config.AgentCo
 	}
 	return config.AgentConfig{}, false
 }
+
+// IsSameBot tells if the configs belong to the same bot or the same shard of a bot,
+// regardless of the bot version.
+func IsSameBot(bot1, bot2 config.AgentConfig) bool {
+	return strings.EqualFold(bot1.ID, bot2.ID) && bot1.ShardID() == bot2.ShardID()
+}
+
+// FindSwappedBots finds the bots in the removed list which are replaced by their new versions
+// in the added list.
+func FindSwappedBots(removed, added []config.AgentConfig) (resultList []config.AgentConfig) {
+	for _, removedBot := range removed {
+		for _, addedBot := range added {
+			if IsSameBot(removedBot, addedBot) && removedBot.ContainerName() != addedBot.ContainerName() {
+				resultList = append(resultList, removedBot)
+				break
+			}
+		}
+	}
+	return
+}
@@ -130,3 +130,39 @@ func TestDrop(t *testing.T) {
 	r.Equal("10", result[0].ID)
 	r.Equal("40", result[1].ID)
 }
+
+func TestFindSwappedBots(t *testing.T) {
+	r := require.New(t)
+
+	removed := []config.AgentConfig{
+		{
+			ID:    "10",
+			Image: testSwapImageRef1,
+		},
+		{
+			ID:    "20",
+			Image: testSwapImageRef1,
+		},
+		{
+			ID:          "30",
+			Image:       testSwapImageRef1,
+			ShardConfig: &config.ShardConfig{ShardID: 0, Shards: 2, Target: 1},
+		},
+	}
+	added := []config.AgentConfig{
+		{
+			ID:    "10",
+			Image: testSwapImageRef2,
+		},
+		{
+			ID:          "30",
+			Image:       testSwapImageRef2,
+			ShardConfig: &config.ShardConfig{ShardID: 1, Shards: 2, Target: 1},
+		},
+	}
+
+	result := FindSwappedBots(removed, added)
+	r.Len(result, 1)
+	r.Equal("10", result[0].ID)
+	r.Equal(testSwapImageRef1, result[0].Image)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/containers"
 	"github.com/forta-network/forta-node/services/components/metrics"
@@ -17,6 +19,7 @@ import (
 // Timeouts
 var (
 	botRemoveTimeout = time.Second * 5
+	botSwapTimeout   = time.Minute * 10
 )
 
 // BotLifecycleManager manages lifecycles of running bots.
@@ -24,27 +27,47 @@ type BotLifecycleManager interface {
 	ManageBots(ctx context.Context) error
 	CleanupUnusedBots(ctx context.Context) error
 	ExitInactiveBots(ctx context.Context) error
+	EnforceBotLimits(ctx context.Context) error
 	RestartExitedBots(ctx context.Context) error
 	TearDownRunningBots(ctx context.Context)
 }
 
+// BotSwapUpdater retires the old bot versions after the new versions replace them.
+type BotSwapUpdater interface {
+	RetireSwappedBots(messaging.AgentPayload) error
+}
+
+// retiringBot is an old bot version which is kept running until the new version replaces it.
+type retiringBot struct {
+	config   config.AgentConfig
+	deadline time.Time
+	swapped  bool
+}
+
 type botLifecycleManager struct {
 	botRegistry      registry.BotRegistry
 	botClient        containers.BotClient
 	botPool          BotPoolUpdater
 	lifecycleMetrics metrics.Lifecycle
 	botMonitor       BotMonitor
-
-	runningBots []config.AgentConfig
+	restartBackoff   *restartBackoff
+	resourcesConfig  config.ResourcesConfig
+	limitNotifier    BotLimitNotifier
+	limitTracker     *limitTracker
+
+	runningBots  []config.AgentConfig
+	retiringBots []*retiringBot
+	retiringMu   sync.Mutex
 }
 
 var _ BotLifecycleManager = &botLifecycleManager{}
+var _ BotSwapUpdater = &botLifecycleManager{}
 
 // NewManager creates new.
 func NewManager(
 	botRegistry registry.BotRegistry, botClient containers.BotClient,
 	botPool BotPoolUpdater, lifecycleMetrics metrics.Lifecycle,
-	botMonitor BotMonitor,
+	botMonitor BotMonitor, resourcesConfig config.ResourcesConfig, limitNotifier BotLimitNotifier,
 ) *botLifecycleManager {
 	return &botLifecycleManager{
 		botRegistry:      botRegistry,
@@ -52,12 +75,19 @@ func NewManager(
 		botPool:          botPool,
 		lifecycleMetrics: lifecycleMetrics,
 		botMonitor:       botMonitor,
+		restartBackoff:   newRestartBackoff(),
+		resourcesConfig:  resourcesConfig,
+		limitNotifier:    limitNotifier,
+		limitTracker:     newLimitTracker(),
 	}
 }
 
 // ManageBots starts containers for assigned bots and stops the containers for unassigned
-// bots and lets other services know.
+// bots and lets other services know. The old versions of the updated bots keep running
+// until the new versions are ready to replace them.
 func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
+	blm.tearDownRetiredBots(ctx)
+
 	assignedBots, err := blm.botRegistry.LoadAssignedBots()
 	if err != nil {
 		blm.lifecycleMetrics.SystemError("load.assigned.bots", err)
@@ -65,8 +95,11 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 	}
 	blm.lifecycleMetrics.SystemStatus("load.assigned.bots", strconv.Itoa(len(assignedBots)))
 
-	// find the removed bots and remove them from the pool
+	// find the removed bots and remove them from the pool, except the old versions of the updated bots
 	removedBotConfigs := FindMissingBots(blm.runningBots, assignedBots)
+	addedBotConfigs := FindExtraBots(blm.runningBots, assignedBots)
+	swappedBotConfigs := FindSwappedBots(removedBotConfigs, addedBotConfigs)
+	removedBotConfigs = FindMissingBots(removedBotConfigs, swappedBotConfigs)
 	if len(removedBotConfigs) > 0 {
 		if err := blm.botPool.RemoveBotsWithConfigs(removedBotConfigs); err != nil {
 			log.WithError(err).Error("error removing bots")
@@ -81,6 +114,9 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 
 	// then stop the containers
 	for _, removedBotConfig := range removedBotConfigs {
+		if removedBotConfig.IsAttached() || removedBotConfig.IsHTTP() {
+			continue
+		}
 		if err := blm.botClient.TearDownBot(ctx, removedBotConfig.ContainerName(), true); err != nil {
 			log.WithError(err).WithField("container", removedBotConfig.ContainerName()).
 				Warn("failed to tear down unassigned bot container")
@@ -88,9 +124,6 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 		}
 	}
 
-	// find the bot containers to start
-	addedBotConfigs := FindExtraBots(blm.runningBots, assignedBots)
-
 	// then download all images concurrently
 	var downloadErrs []error
 	if len(addedBotConfigs) > 0 {
@@ -125,6 +158,11 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 		}
 	}
 
+	// keep running the old versions of the bots which could not start
+	startedSwaps := FindSwappedBots(swappedBotConfigs, assignedBots)
+	assignedBots = append(assignedBots, FindMissingBots(swappedBotConfigs, startedSwaps)...)
+	blm.retireBots(startedSwaps)
+
 	// then update the pool with latest bots
 	if err := blm.botPool.UpdateBotsWithLatestConfigs(assignedBots); err != nil {
 		blm.lifecycleMetrics.SystemError("update.bots.with.latest.configs", err)
@@ -154,7 +192,7 @@ func (blm *botLifecycleManager) CleanupUnusedBots(ctx context.Context) error {
 	for _, botContainer := range botContainers {
 		botContainerName := botContainer.Names[0][1:]
 		_, ok := blm.findBotConfig(botContainerName)
-		if ok {
+		if ok || blm.isRetiring(botContainerName) {
 			continue
 		}
 
@@ -195,6 +233,94 @@ func (blm *botLifecycleManager) ExitInactiveBots(ctx context.Context) error {
 	return nil
 }
 
+// EnforceBotLimits lets other services know about the bots which keep using all of their CPU or memory
+// and stops the bots at their memory limits if the enforcement is enabled. The stopped bots are restarted
+// like the other exited bots.
+func (blm *botLifecycleManager) EnforceBotLimits(ctx context.Context) error {
+	resourcesCfg := blm.resourcesConfig
+	if resourcesCfg.DisableAgentLimits {
+		return nil
+	}
+
+	botContainers, err := blm.botClient.LoadBotContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load bot containers during limit checks: %v", err)
+	}
+
+	checked := make(map[string]bool)
+	for _, botContainer := range botContainers {
+		containerName := docker.GetContainerName(botContainer)
+		botConfig, found := blm.findBotConfig(containerName)
+		if !found {
+			continue
+		}
+		checked[containerName] = true
+		limits := config.GetAgentResourceLimits(resourcesCfg, botConfig.ID)
+		logger := log.WithField("botId", botConfig.ID)
+
+		switch botContainer.State {
+		case "running":
+			stats, err := blm.botClient.GetBotContainerStats(ctx, botContainer.ID)
+			if err != nil {
+				logger.WithError(err).Warn("failed to get bot container stats")
+				continue
+			}
+			exceeded := blm.limitTracker.Check(containerName, stats, limits, resourcesCfg.LimitViolationChecks)
+			if exceeded == nil {
+				continue
+			}
+			logger = logger.WithFields(log.Fields{
+				"resource": exceeded.Resource,
+				"usage":    exceeded.Usage,
+				"limit":    exceeded.Limit,
+			})
+			if exceeded.Resource != ResourceMemory || !resourcesCfg.EnforceMemoryLimits {
+				logger.Warn("bot is at its resource limit")
+				blm.notifyLimitExceeded(botConfig, exceeded)
+				continue
+			}
+			logger.Warn("stopping bot which exceeded its resource limit")
+			if err := blm.botClient.StopBot(ctx, botConfig); err != nil {
+				logger.WithError(err).Error("failed to stop the bot which exceeded its resource limit")
+				blm.lifecycleMetrics.FailureStop(fmt.Errorf("failed to stop the bot which exceeded its resource limit: %v", err), botConfig)
+				continue
+			}
+			exceeded.Restarted = true
+			blm.notifyLimitExceeded(botConfig, exceeded)
+
+		case "exited":
+			// docker kills the containers which exceed their memory limits
+			info, err := blm.botClient.InspectBotContainer(ctx, botContainer.ID)
+			if err != nil {
+				logger.WithError(err).Warn("failed to inspect exited bot container")
+				continue
+			}
+			if info.State == nil || !info.State.OOMKilled || !blm.limitTracker.ReportOOM(containerName, info.State.FinishedAt) {
+				continue
+			}
+			logger.Warn("bot was killed after exceeding its memory limit")
+			blm.notifyLimitExceeded(botConfig, &messaging.AgentLimitPayload{
+				Resource:  ResourceMemory,
+				Usage:     float64(limits.Memory),
+				Limit:     float64(limits.Memory),
+				Restarted: true,
+			})
+		}
+	}
+	blm.limitTracker.Prune(checked)
+	return nil
+}
+
+func (blm *botLifecycleManager) notifyLimitExceeded(botConfig config.AgentConfig, exceeded *messaging.AgentLimitPayload) {
+	blm.lifecycleMetrics.FailureResourceLimit(
+		fmt.Errorf("exceeded %s limit: usage %v, limit %v", exceeded.Resource, exceeded.Usage, exceeded.Limit), botConfig,
+	)
+	exceeded.Agent = botConfig
+	if err := blm.limitNotifier.NotifyLimitExceeded(*exceeded); err != nil {
+		blm.lifecycleMetrics.SystemError("notify.limit.exceeded", err)
+	}
+}
+
 // RestartExitedBots restarts bot containers when they are down and lets other services know.
 func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 	botContainers, err := blm.botClient.LoadBotContainers(ctx)
@@ -205,12 +331,17 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 
 	// find exited bot containers and restart them
 	var restartedBotConfigs []config.AgentConfig
+	existing := make(map[string]bool)
 	for _, botContainer := range botContainers {
+		containerName := docker.GetContainerName(botContainer)
+		existing[containerName] = true
 		if botContainer.State != "exited" {
+			if botContainer.State == "running" {
+				blm.restartBackoff.Running(containerName)
+			}
 			continue
 		}
 
-		containerName := docker.GetContainerName(botContainer)
 		logger := log.WithField("container", containerName)
 		restartedBotConfig, found := blm.findBotConfig(containerName)
 		if !found {
@@ -218,8 +349,14 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 			continue
 		}
 		logger = log.WithField("botId", restartedBotConfig.ID)
+		// avoid restarting the crashing bots too often
+		if !blm.restartBackoff.ShouldRestart(containerName) {
+			logger.Info("delaying the restart of exited bot container")
+			continue
+		}
 		logger.Warn("restarting bot container")
 		blm.lifecycleMetrics.ActionRestart(restartedBotConfig)
+		blm.restartBackoff.Restarted(containerName)
 		if err := blm.botClient.StartWaitBotContainer(ctx, botContainer.ID); err != nil {
 			logger.WithError(err).Error("failed to start exited bot container")
 			blm.lifecycleMetrics.BotError("start.exited.bot.container", fmt.Errorf("failed to start exited bot container: %v", err.Error()), restartedBotConfig)
@@ -227,6 +364,7 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 		}
 		restartedBotConfigs = append(restartedBotConfigs, restartedBotConfig)
 	}
+	blm.restartBackoff.Prune(existing)
 
 	// let the bot pool reconnect to the restarted bots
 	if len(restartedBotConfigs) > 0 {
@@ -239,13 +377,22 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 
 // TearDownRunningBots tears down all running bots.
 func (blm *botLifecycleManager) TearDownRunningBots(ctx context.Context) {
-	if len(blm.runningBots) == 0 {
+	// the old versions of the updated bots are torn down too
+	blm.retiringMu.Lock()
+	botConfigs := append([]config.AgentConfig{}, blm.runningBots...)
+	for _, retiring := range blm.retiringBots {
+		botConfigs = append(botConfigs, retiring.config)
+	}
+	blm.retiringBots = nil
+	blm.retiringMu.Unlock()
+
+	if len(botConfigs) == 0 {
 		return
 	}
-	log.WithField("count", len(blm.runningBots)).Info("tearing down running bots")
+	log.WithField("count", len(botConfigs)).Info("tearing down running bots")
 
 	// remove all bots from the pool
-	if err := blm.botPool.RemoveBotsWithConfigs(blm.runningBots); err != nil {
+	if err := blm.botPool.RemoveBotsWithConfigs(botConfigs); err != nil {
 		blm.lifecycleMetrics.SystemError("teardown.remove.bots.with.configs", err)
 		log.WithError(err).Error("error removing bots with configs")
 	}
@@ -255,7 +402,7 @@ func (blm *botLifecycleManager) TearDownRunningBots(ctx context.Context) {
 	time.Sleep(botRemoveTimeout)
 
 	// then stop the containers
-	for _, runningBotConfig := range blm.runningBots {
+	for _, runningBotConfig := range botConfigs {
 		err := blm.botClient.TearDownBot(ctx, runningBotConfig.ContainerName(), false)
 		if err != nil {
 			blm.lifecycleMetrics.BotError("teardown.bot", err, runningBotConfig)
@@ -265,6 +412,69 @@ func (blm *botLifecycleManager) TearDownRunningBots(ctx context.Context) {
 	}
 }
 
+// RetireSwappedBots lets the old bot versions be torn down after they are replaced.
+func (blm *botLifecycleManager) RetireSwappedBots(payload messaging.AgentPayload) error {
+	blm.retiringMu.Lock()
+	defer blm.retiringMu.Unlock()
+
+	for _, retiring := range blm.retiringBots {
+		if _, found := FindBot(retiring.config.ContainerName(), payload); found {
+			retiring.swapped = true
+		}
+	}
+	return nil
+}
+
+func (blm *botLifecycleManager) retireBots(botConfigs []config.AgentConfig) {
+	blm.retiringMu.Lock()
+	defer blm.retiringMu.Unlock()
+
+	for _, botConfig := range botConfigs {
+		log.WithField("container", botConfig.ContainerName()).Info("retiring old bot version")
+		blm.retiringBots = append(blm.retiringBots, &retiringBot{
+			config:   botConfig,
+			deadline: time.Now().Add(botSwapTimeout),
+		})
+	}
+}
+
+// tearDownRetiredBots tears down the old bot versions which were swapped or did not
+// get swapped in time.
+func (blm *botLifecycleManager) tearDownRetiredBots(ctx context.Context) {
+	blm.retiringMu.Lock()
+	var retired []config.AgentConfig
+	var stillRetiring []*retiringBot
+	for _, retiring := range blm.retiringBots {
+		if retiring.swapped || time.Now().After(retiring.deadline) {
+			retired = append(retired, retiring.config)
+			continue
+		}
+		stillRetiring = append(stillRetiring, retiring)
+	}
+	blm.retiringBots = stillRetiring
+	blm.retiringMu.Unlock()
+
+	for _, botConfig := range retired {
+		if err := blm.botClient.TearDownBot(ctx, botConfig.ContainerName(), true); err != nil {
+			log.WithError(err).WithField("container", botConfig.ContainerName()).
+				Warn("failed to tear down old bot version")
+			blm.lifecycleMetrics.BotError("retired.teardown", err, botConfig)
+		}
+	}
+}
+
+func (blm *botLifecycleManager) isRetiring(containerName string) bool {
+	blm.retiringMu.Lock()
+	defer blm.retiringMu.Unlock()
+
+	for _, retiring := range blm.retiringBots {
+		if retiring.config.ContainerName() == containerName {
+			return true
+		}
+	}
+	return false
+}
+
 func (blm *botLifecycleManager) findBotConfig(containerName string) (config.AgentConfig, bool) {
 	for _, bot := range blm.runningBots {
 		if bot.ContainerName() == containerName {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
+	"github.com/forta-network/forta-node/clients/messaging"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
 	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
@@ -30,6 +31,7 @@ type BotLifecycleManagerTestSuite struct {
 	botContainers    *mock_containers.MockBotClient
 	botPool          *mock_lifecycle.MockBotPoolUpdater
 	botMonitor       *mock_lifecycle.MockBotMonitor
+	limitNotifier    *mock_lifecycle.MockBotLimitNotifier
 
 	botManager *botLifecycleManager
 
@@ -52,8 +54,12 @@ func (s *BotLifecycleManagerTestSuite) SetupTest() {
 	s.botContainers = mock_containers.NewMockBotClient(ctrl)
 	s.botPool = mock_lifecycle.NewMockBotPoolUpdater(ctrl)
 	s.botMonitor = mock_lifecycle.NewMockBotMonitor(ctrl)
+	s.limitNotifier = mock_lifecycle.NewMockBotLimitNotifier(ctrl)
 
-	s.botManager = NewManager(s.botRegistry, s.botContainers, s.botPool, s.lifecycleMetrics, s.botMonitor)
+	s.botManager = NewManager(
+		s.botRegistry, s.botContainers, s.botPool, s.lifecycleMetrics, s.botMonitor,
+		config.ResourcesConfig{AgentMaxMemoryMiB: 100, EnforceMemoryLimits: true, LimitViolationChecks: 2}, s.limitNotifier,
+	)
 }
 
 func (s *BotLifecycleManagerTestSuite) TestAddUpdateRemove() {
@@ -111,6 +117,85 @@ func (s *BotLifecycleManagerTestSuite) TestAddUpdateRemove() {
 	s.r.NoError(s.botManager.ManageBots(context.Background()))
 }
 
+func (s *BotLifecycleManagerTestSuite) TestSwap() {
+	oldConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef1,
+	}
+	newConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef2,
+	}
+	latestAssigned := []config.AgentConfig{newConfig}
+
+	s.botManager.runningBots = []config.AgentConfig{oldConfig}
+
+	// the old version should not be removed before the new version replaces it
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(latestAssigned, nil)
+	s.lifecycleMetrics.EXPECT().SystemStatus("load.assigned.bots", "1")
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), latestAssigned).Return([]error{nil})
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), newConfig).Return(nil)
+	s.lifecycleMetrics.EXPECT().StatusRunning(latestAssigned)
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(latestAssigned)
+	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(latestAssigned))
+
+	s.r.NoError(s.botManager.ManageBots(context.Background()))
+	s.r.True(s.botManager.isRetiring(oldConfig.ContainerName()))
+
+	// the cleanup should not touch the old version
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
+		{
+			ID:    testContainerID1,
+			Names: []string{fmt.Sprintf("/%s", oldConfig.ContainerName())},
+		},
+		{
+			ID:    testContainerID2,
+			Names: []string{fmt.Sprintf("/%s", newConfig.ContainerName())},
+		},
+	}, nil)
+	s.r.NoError(s.botManager.CleanupUnusedBots(context.Background()))
+
+	// the old version should be torn down after it is swapped
+	s.r.NoError(s.botManager.RetireSwappedBots(messaging.AgentPayload{oldConfig}))
+	s.botContainers.EXPECT().TearDownBot(gomock.Any(), oldConfig.ContainerName(), true)
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(latestAssigned, nil)
+	s.lifecycleMetrics.EXPECT().SystemStatus("load.assigned.bots", "1")
+	s.lifecycleMetrics.EXPECT().StatusRunning(latestAssigned)
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(latestAssigned)
+	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(latestAssigned))
+
+	s.r.NoError(s.botManager.ManageBots(context.Background()))
+	s.r.False(s.botManager.isRetiring(oldConfig.ContainerName()))
+}
+
+func (s *BotLifecycleManagerTestSuite) TestSwapLaunchError() {
+	oldConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef1,
+	}
+	newConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef2,
+	}
+
+	s.botManager.runningBots = []config.AgentConfig{oldConfig}
+
+	// the old version should keep running if the new version cannot start
+	err := errors.New("failed to launch")
+	s.botRegistry.EXPECT().LoadAssignedBots().Return([]config.AgentConfig{newConfig}, nil)
+	s.lifecycleMetrics.EXPECT().SystemStatus("load.assigned.bots", "1")
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), []config.AgentConfig{newConfig}).Return([]error{nil})
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), newConfig).Return(err)
+	s.lifecycleMetrics.EXPECT().FailureLaunch(err, newConfig)
+	s.lifecycleMetrics.EXPECT().StatusRunning(oldConfig)
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(messaging.AgentPayload{oldConfig})
+	s.botMonitor.EXPECT().MonitorBots([]string{testBotID1})
+
+	s.r.NoError(s.botManager.ManageBots(context.Background()))
+	s.r.Equal([]config.AgentConfig{oldConfig}, s.botManager.runningBots)
+	s.r.False(s.botManager.isRetiring(oldConfig.ContainerName()))
+}
+
 func (s *BotLifecycleManagerTestSuite) TestLoadBotsError() {
 	err := errors.New("test err asigned bots")
 	s.botRegistry.EXPECT().LoadAssignedBots().Return(nil, err).Times(1)
@@ -185,6 +270,113 @@ func (s *BotLifecycleManagerTestSuite) TestExit() {
 	s.r.NoError(s.botManager.ExitInactiveBots(context.Background()))
 }
 
+func (s *BotLifecycleManagerTestSuite) TestEnforceLimits() {
+	botConfigs := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID2,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID3,
+			Image: testImageRef,
+		},
+	}
+
+	s.botManager.runningBots = botConfigs
+
+	botContainers := []types.Container{
+		{
+			ID:    testContainerID1,
+			Names: []string{fmt.Sprintf("/%s", botConfigs[0].ContainerName())},
+			State: "running",
+		},
+		{
+			ID:    testContainerID2,
+			Names: []string{fmt.Sprintf("/%s", botConfigs[1].ContainerName())},
+			State: "running",
+		},
+		{
+			ID:    testContainerID3,
+			Names: []string{fmt.Sprintf("/%s", botConfigs[2].ContainerName())},
+			State: "exited",
+		},
+	}
+	memoryLimit := config.MiBToBytes(100)
+	var highUsage, lowUsage types.StatsJSON
+	highUsage.MemoryStats.Usage = uint64(memoryLimit)
+	lowUsage.MemoryStats.Usage = 1000
+	oomKilled := &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{OOMKilled: true, FinishedAt: "finished-at"},
+		},
+	}
+
+	// the first bot is at the memory limit and the third bot was killed by docker
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return(botContainers, nil)
+	s.botContainers.EXPECT().GetBotContainerStats(gomock.Any(), testContainerID1).Return(&highUsage, nil)
+	s.botContainers.EXPECT().GetBotContainerStats(gomock.Any(), testContainerID2).Return(&lowUsage, nil)
+	s.botContainers.EXPECT().InspectBotContainer(gomock.Any(), testContainerID3).Return(oomKilled, nil)
+	s.lifecycleMetrics.EXPECT().FailureResourceLimit(gomock.Any(), botConfigs[2])
+	s.limitNotifier.EXPECT().NotifyLimitExceeded(gomock.Any()).DoAndReturn(func(payload messaging.AgentLimitPayload) error {
+		s.r.Equal(testBotID3, payload.Agent.ID)
+		s.r.Equal(ResourceMemory, payload.Resource)
+		s.r.True(payload.Restarted)
+		return nil
+	})
+	s.r.NoError(s.botManager.EnforceBotLimits(context.Background()))
+
+	// the first bot should be stopped for staying at the limit and the third bot should not be reported again
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return(botContainers, nil)
+	s.botContainers.EXPECT().GetBotContainerStats(gomock.Any(), testContainerID1).Return(&highUsage, nil)
+	s.botContainers.EXPECT().GetBotContainerStats(gomock.Any(), testContainerID2).Return(&lowUsage, nil)
+	s.botContainers.EXPECT().InspectBotContainer(gomock.Any(), testContainerID3).Return(oomKilled, nil)
+	s.botContainers.EXPECT().StopBot(gomock.Any(), botConfigs[0])
+	s.lifecycleMetrics.EXPECT().FailureResourceLimit(gomock.Any(), botConfigs[0])
+	s.limitNotifier.EXPECT().NotifyLimitExceeded(gomock.Any()).DoAndReturn(func(payload messaging.AgentLimitPayload) error {
+		s.r.Equal(testBotID1, payload.Agent.ID)
+		s.r.Equal(ResourceMemory, payload.Resource)
+		s.r.Equal(float64(memoryLimit), payload.Limit)
+		s.r.True(payload.Restarted)
+		return nil
+	})
+	s.r.NoError(s.botManager.EnforceBotLimits(context.Background()))
+}
+
+func (s *BotLifecycleManagerTestSuite) TestEnforceLimitsDisabled() {
+	s.botManager.resourcesConfig.EnforceMemoryLimits = false
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+	s.botManager.runningBots = []config.AgentConfig{botConfig}
+	botContainers := []types.Container{
+		{
+			ID:    testContainerID1,
+			Names: []string{fmt.Sprintf("/%s", botConfig.ContainerName())},
+			State: "running",
+		},
+	}
+	var highUsage types.StatsJSON
+	highUsage.MemoryStats.Usage = uint64(config.MiBToBytes(100))
+
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return(botContainers, nil).Times(2)
+	s.botContainers.EXPECT().GetBotContainerStats(gomock.Any(), testContainerID1).Return(&highUsage, nil).Times(2)
+	s.r.NoError(s.botManager.EnforceBotLimits(context.Background()))
+
+	// the bot should only be reported for staying at the limit
+	s.lifecycleMetrics.EXPECT().FailureResourceLimit(gomock.Any(), botConfig)
+	s.limitNotifier.EXPECT().NotifyLimitExceeded(gomock.Any()).DoAndReturn(func(payload messaging.AgentLimitPayload) error {
+		s.r.Equal(ResourceMemory, payload.Resource)
+		s.r.False(payload.Restarted)
+		return nil
+	})
+	s.r.NoError(s.botManager.EnforceBotLimits(context.Background()))
+}
+
 func (s *BotLifecycleManagerTestSuite) TestCleanup() {
 	botConfigs := []config.AgentConfig{
 		{
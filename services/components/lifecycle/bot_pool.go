@@ -2,6 +2,8 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -17,6 +19,8 @@ import (
 type BotPool interface {
 	BotPoolUpdater
 	botio.BotPool
+	Drain(timeout time.Duration) error
+	PendingRequests() []botio.PendingRequest
 }
 
 // BotPoolUpdater updates bots.
@@ -26,10 +30,31 @@ type BotPoolUpdater interface {
 	ReconnectToBotsWithConfigs(messaging.AgentPayload) error
 }
 
+// BotSwapNotifier lets the bot manager know about the replaced bot versions which do not
+// receive any requests anymore.
+type BotSwapNotifier interface {
+	NotifyBotsSwapped(messaging.AgentPayload) error
+}
+
+var drainCheckInterval = time.Millisecond * 100
+
+// Bot swap timeouts
+var (
+	botSwapWarmUpTimeout = botio.DefaultInitializeTimeout
+	botSwapDrainTimeout  = time.Minute
+)
+
+// botSwap is a new bot version which is warming up to replace the old version.
+type botSwap struct {
+	oldBot botio.BotClient
+	newBot botio.BotClient
+}
+
 type botPool struct {
 	ctx context.Context
 
 	botClients []botio.BotClient
+	swaps      map[string]*botSwap // by the container names of the new versions
 	mu         sync.RWMutex
 
 	waitBots int
@@ -39,20 +64,23 @@ type botPool struct {
 
 	lifecycleMetrics metrics.Lifecycle
 	botClientFactory botio.BotClientFactory
+	swapNotifier     BotSwapNotifier
 }
 
 var _ BotPool = &botPool{}
 
-// NewBotPool creates a new bot pool.
+// NewBotPool creates a new bot pool. The swap notifier is optional.
 func NewBotPool(
 	ctx context.Context, lifecycleMetrics metrics.Lifecycle,
-	botClientFactory botio.BotClientFactory, waitBots int,
+	botClientFactory botio.BotClientFactory, waitBots int, swapNotifier BotSwapNotifier,
 ) *botPool {
 	botPool := &botPool{
 		ctx:              ctx,
+		swaps:            make(map[string]*botSwap),
 		waitBots:         waitBots,
 		lifecycleMetrics: lifecycleMetrics,
 		botClientFactory: botClientFactory,
+		swapNotifier:     swapNotifier,
 	}
 	if waitBots > 0 {
 		botPool.botWg = &sync.WaitGroup{}
@@ -84,6 +112,7 @@ func (bp *botPool) doLogBotStatuses() {
 }
 
 // UpdateBotsWithLatestConfigs starts and adds new bots and updates the config of updated bots.
+// The new versions of the running bots replace the old versions after warming up.
 func (bp *botPool) UpdateBotsWithLatestConfigs(latestConfigs messaging.AgentPayload) error {
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
@@ -99,6 +128,21 @@ func (bp *botPool) UpdateBotsWithLatestConfigs(latestConfigs messaging.AgentPayl
 			continue
 		}
 
+		// the old version keeps receiving the requests until the new version is ready
+		if swap, ok := bp.swaps[botConfig.ContainerName()]; ok {
+			logger.Debug("new bot version is still warming up - skipping update")
+			latestBotClients = append(latestBotClients, swap.oldBot)
+			continue
+		}
+		if oldBot, ok := bp.findReplacedBot(botConfig, latestConfigs); ok {
+			logger.WithField("oldImage", oldBot.Config().Image).Info("warming up new bot version")
+			swap := &botSwap{oldBot: oldBot, newBot: bp.startBotClient(botConfig)}
+			bp.swaps[botConfig.ContainerName()] = swap
+			go bp.swapBots(botConfig.ContainerName(), swap)
+			latestBotClients = append(latestBotClients, oldBot)
+			continue
+		}
+
 		if ok && botClient.IsClosed() {
 			logger.Info("replacing closed bot client")
 		} else {
@@ -132,6 +176,76 @@ func (bp *botPool) UpdateBotsWithLatestConfigs(latestConfigs messaging.AgentPayl
 	return nil
 }
 
+// findReplacedBot finds the running old version of the bot which is not in the latest configs anymore.
+func (bp *botPool) findReplacedBot(botConfig config.AgentConfig, latestConfigs []config.AgentConfig) (botio.BotClient, bool) {
+	for _, botClient := range bp.botClients {
+		oldConfig := botClient.Config()
+		if !IsSameBot(oldConfig, botConfig) || oldConfig.ContainerName() == botConfig.ContainerName() || botClient.IsClosed() {
+			continue
+		}
+		if _, found := FindBot(oldConfig.ContainerName(), latestConfigs); found {
+			continue
+		}
+		return botClient, true
+	}
+	return nil, false
+}
+
+// swapBots waits for the new bot version to warm up, switches the requests to the new version
+// and then drains and closes the old version. The old version keeps running if the new version
+// fails to initialize.
+func (bp *botPool) swapBots(containerName string, swap *botSwap) {
+	var swapErr error
+	select {
+	case <-swap.newBot.Initialized():
+	case <-swap.newBot.Closed():
+		swapErr = errors.New("new bot version closed before initializing")
+	case <-bp.ctx.Done():
+		return
+	case <-time.After(botSwapWarmUpTimeout):
+		swapErr = fmt.Errorf("new bot version did not initialize in %s", botSwapWarmUpTimeout)
+	}
+
+	bp.mu.Lock()
+	if bp.swaps[containerName] != swap {
+		// cancelled by the removal of the new version
+		bp.mu.Unlock()
+		return
+	}
+	delete(bp.swaps, containerName)
+	if swapErr != nil {
+		bp.mu.Unlock()
+		newConfig := swap.newBot.Config()
+		botLogger(newConfig).WithError(swapErr).Warn("failed to upgrade bot - keeping old version")
+		bp.lifecycleMetrics.FailureInitialize(swapErr, newConfig)
+		_ = swap.newBot.Close()
+		return
+	}
+	latestBotClients := make([]botio.BotClient, 0, len(bp.botClients))
+	for _, botClient := range bp.botClients {
+		if botClient == swap.oldBot {
+			botClient = swap.newBot
+		}
+		latestBotClients = append(latestBotClients, botClient)
+	}
+	bp.botClients = latestBotClients
+	bp.mu.Unlock()
+
+	oldConfig := swap.oldBot.Config()
+	logger := botLogger(oldConfig)
+	logger.Info("switched to new bot version - draining old version")
+	if err := waitForIdle(swap.oldBot, botSwapDrainTimeout); err != nil {
+		logger.WithError(err).Warn("failed to drain old bot version")
+	}
+	_ = swap.oldBot.Close()
+
+	if bp.swapNotifier != nil {
+		if err := bp.swapNotifier.NotifyBotsSwapped(messaging.AgentPayload{oldConfig}); err != nil {
+			logger.WithError(err).Error("failed to notify about the swapped bot")
+		}
+	}
+}
+
 func (bp *botPool) startBotClient(botConfig config.AgentConfig) botio.BotClient {
 	botClient := bp.botClientFactory.NewBotClient(bp.ctx, botConfig)
 
@@ -151,8 +265,17 @@ func (bp *botPool) RemoveBotsWithConfigs(removedBotConfigs messaging.AgentPayloa
 	defer bp.mu.Unlock()
 
 	// close and discard the removed bots
+	var cancelledSwaps []botio.BotClient
 	for _, removedBotConfig := range removedBotConfigs {
 		logger := botLogger(removedBotConfig)
+		// the new version is removed while warming up so discard both versions
+		if swap, ok := bp.swaps[removedBotConfig.ContainerName()]; ok {
+			delete(bp.swaps, removedBotConfig.ContainerName())
+			_ = swap.newBot.Close()
+			_ = swap.oldBot.Close()
+			cancelledSwaps = append(cancelledSwaps, swap.oldBot)
+			continue
+		}
 		botClient, ok := bp.getBotClient(removedBotConfig.ContainerName())
 		if !ok {
 			logger.Info("could not find the removed bot! skipping")
@@ -165,7 +288,7 @@ func (bp *botPool) RemoveBotsWithConfigs(removedBotConfigs messaging.AgentPayloa
 	var preservedBots []botio.BotClient
 	for _, preservedBotConfig := range FindExtraBots(removedBotConfigs, bp.getConfigsUnsafe()) {
 		botClient, ok := bp.getBotClient(preservedBotConfig.ContainerName())
-		if ok {
+		if ok && !containsBotClient(cancelledSwaps, botClient) {
 			preservedBots = append(preservedBots, botClient)
 		}
 	}
@@ -212,6 +335,62 @@ func (bp *botPool) WaitForAll() {
 	bp.botWg.Wait()
 }
 
+// Drain waits for all bots to finish processing their buffered and in-flight requests
+// and closes the bots afterwards. The bots are closed also when the timeout is reached.
+func (bp *botPool) Drain(timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	defer bp.closeAll()
+
+	for !bp.allIdle() {
+		select {
+		case <-deadline:
+			return fmt.Errorf("timed out after %s while draining bots", timeout)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func waitForIdle(botClient botio.BotClient, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for !botClient.IsIdle() {
+		select {
+		case <-deadline:
+			return fmt.Errorf("timed out after %s while draining bot", timeout)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (bp *botPool) allIdle() bool {
+	for _, botClient := range bp.GetCurrentBotClients() {
+		if !botClient.IsIdle() {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingRequests returns the requests which the bots did not finish processing.
+func (bp *botPool) PendingRequests() (reqs []botio.PendingRequest) {
+	for _, botClient := range bp.GetCurrentBotClients() {
+		reqs = append(reqs, botClient.PendingRequests()...)
+	}
+	return
+}
+
+func (bp *botPool) closeAll() {
+	for _, botClient := range bp.GetCurrentBotClients() {
+		_ = botClient.Close()
+	}
+}
+
 func botLogger(botConfig config.AgentConfig) *log.Entry {
 	return log.WithField("bot", botConfig.ID).WithField("container", botConfig.ContainerName())
 }
@@ -225,6 +404,15 @@ func (bp *botPool) getBotClient(containerName string) (botio.BotClient, bool) {
 	return nil, false
 }
 
+func containsBotClient(botClients []botio.BotClient, botClient botio.BotClient) bool {
+	for _, currBotClient := range botClients {
+		if currBotClient == botClient {
+			return true
+		}
+	}
+	return false
+}
+
 func (bp *botPool) getConfigsUnsafe() (allConfigs []config.AgentConfig) {
 	for _, botClient := range bp.botClients {
 		allConfigs = append(allConfigs, botClient.Config())
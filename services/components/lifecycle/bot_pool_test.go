@@ -3,16 +3,24 @@ package lifecycle
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio"
 	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
 	mock_metrics "github.com/forta-network/forta-node/services/components/metrics/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
+const (
+	testSwapImageRef1 = "bafybeibvkqkf7i4mpl5nr2cuetqc7yuxhmqkn7hvavdyxsvpwptotuzmzy@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testSwapImageRef2 = "bafybeibvkqkf7i4mpl5nr2cuetqc7yuxhmqkn7hvavdyxsvpwptotuzmzy@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
 // BotPoolTestSuite has unit tests for the bot client pool.
 type BotPoolTestSuite struct {
 	r *require.Assertions
@@ -21,6 +29,7 @@ type BotPoolTestSuite struct {
 	botClientFactory *mock_botio.MockBotClientFactory
 	botClient1       *mock_botio.MockBotClient
 	botClient2       *mock_botio.MockBotClient
+	swapNotifier     *mock_lifecycle.MockBotSwapNotifier
 
 	botPool *botPool
 
@@ -40,8 +49,9 @@ func (s *BotPoolTestSuite) SetupTest() {
 	s.botClientFactory = mock_botio.NewMockBotClientFactory(ctrl)
 	s.botClient1 = mock_botio.NewMockBotClient(ctrl)
 	s.botClient2 = mock_botio.NewMockBotClient(ctrl)
+	s.swapNotifier = mock_lifecycle.NewMockBotSwapNotifier(ctrl)
 
-	s.botPool = NewBotPool(context.Background(), s.lifecycleMetrics, s.botClientFactory, 0)
+	s.botPool = NewBotPool(context.Background(), s.lifecycleMetrics, s.botClientFactory, 0, s.swapNotifier)
 	s.botPool.waitInit = true
 }
 
@@ -68,7 +78,7 @@ func (s *BotPoolTestSuite) TestAddUpdate() {
 
 	s.botPool.botClients = []botio.BotClient{s.botClient2}
 
-	s.botClient2.EXPECT().Config().Return(assigned[0]).Times(3)
+	s.botClient2.EXPECT().Config().Return(assigned[0]).Times(4)
 	s.botClient2.EXPECT().IsClosed().Return(false)
 	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), updated[0]).Return(s.botClient1)
 	s.botClient1.EXPECT().Initialize()
@@ -86,6 +96,116 @@ func (s *BotPoolTestSuite) TestAddUpdate() {
 	s.r.Equal(s.botPool.botClients[1], s.botClient1)
 }
 
+func (s *BotPoolTestSuite) TestSwap() {
+	oldConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef1,
+	}
+	newConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef2,
+	}
+
+	s.botPool.botClients = []botio.BotClient{s.botClient1}
+
+	initialized := make(chan struct{})
+	swapped := make(chan struct{})
+	s.botClient1.EXPECT().Config().Return(oldConfig).AnyTimes()
+	s.botClient1.EXPECT().IsClosed().Return(false)
+	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), newConfig).Return(s.botClient2)
+	s.botClient2.EXPECT().Initialize()
+	s.botClient2.EXPECT().StartProcessing()
+	s.botClient2.EXPECT().Initialized().Return(initialized)
+	s.botClient2.EXPECT().Closed().Return(nil)
+
+	s.r.NoError(s.botPool.UpdateBotsWithLatestConfigs([]config.AgentConfig{newConfig}))
+
+	// the old version should keep receiving the requests while the new version is warming up
+	s.r.Equal([]botio.BotClient{s.botClient1}, s.botPool.GetCurrentBotClients())
+
+	s.botClient1.EXPECT().IsIdle().Return(true)
+	s.botClient1.EXPECT().Close()
+	s.swapNotifier.EXPECT().NotifyBotsSwapped(messaging.AgentPayload{oldConfig}).DoAndReturn(
+		func(payload messaging.AgentPayload) error {
+			close(swapped)
+			return nil
+		},
+	)
+	close(initialized)
+	<-swapped
+
+	s.r.Equal([]botio.BotClient{s.botClient2}, s.botPool.GetCurrentBotClients())
+	s.r.Empty(s.botPool.swaps)
+}
+
+func (s *BotPoolTestSuite) TestSwapFailed() {
+	oldConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef1,
+	}
+	newConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef2,
+	}
+
+	s.botPool.botClients = []botio.BotClient{s.botClient1}
+
+	closed := make(chan struct{})
+	discarded := make(chan struct{})
+	s.botClient1.EXPECT().Config().Return(oldConfig).AnyTimes()
+	s.botClient1.EXPECT().IsClosed().Return(false)
+	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), newConfig).Return(s.botClient2)
+	s.botClient2.EXPECT().Initialize()
+	s.botClient2.EXPECT().StartProcessing()
+	s.botClient2.EXPECT().Initialized().Return(make(chan struct{}))
+	s.botClient2.EXPECT().Closed().Return(closed)
+	s.botClient2.EXPECT().Config().Return(newConfig)
+
+	s.r.NoError(s.botPool.UpdateBotsWithLatestConfigs([]config.AgentConfig{newConfig}))
+
+	// the old version should keep running if the new version fails to initialize
+	s.lifecycleMetrics.EXPECT().FailureInitialize(gomock.Any(), newConfig)
+	s.botClient2.EXPECT().Close().Do(func() {
+		close(discarded)
+	})
+	close(closed)
+	<-discarded
+
+	s.r.Equal([]botio.BotClient{s.botClient1}, s.botPool.GetCurrentBotClients())
+	s.r.Empty(s.botPool.swaps)
+}
+
+func (s *BotPoolTestSuite) TestSwapRemoved() {
+	oldConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef1,
+	}
+	newConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testSwapImageRef2,
+	}
+
+	s.botPool.botClients = []botio.BotClient{s.botClient1}
+
+	s.botClient1.EXPECT().Config().Return(oldConfig).AnyTimes()
+	s.botClient1.EXPECT().IsClosed().Return(false)
+	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), newConfig).Return(s.botClient2)
+	s.botClient2.EXPECT().Initialize()
+	s.botClient2.EXPECT().StartProcessing()
+	s.botClient2.EXPECT().Initialized().Return(make(chan struct{})).AnyTimes()
+	s.botClient2.EXPECT().Closed().Return(nil).AnyTimes()
+
+	s.r.NoError(s.botPool.UpdateBotsWithLatestConfigs([]config.AgentConfig{newConfig}))
+
+	// both versions should be discarded if the new version is removed while warming up
+	s.botClient1.EXPECT().Close()
+	s.botClient2.EXPECT().Close()
+	s.r.NoError(s.botPool.RemoveBotsWithConfigs([]config.AgentConfig{newConfig}))
+
+	s.r.Empty(s.botPool.GetCurrentBotClients())
+	s.r.Empty(s.botPool.swaps)
+}
+
 func (s *BotPoolTestSuite) TestRemove() {
 	assigned := []config.AgentConfig{
 		{
@@ -143,7 +263,7 @@ func (s *BotPoolTestSuite) TestWaitForAll() {
 			Image: testImageRef,
 		},
 	}
-	botPool := NewBotPool(context.Background(), s.lifecycleMetrics, s.botClientFactory, len(latest))
+	botPool := NewBotPool(context.Background(), s.lifecycleMetrics, s.botClientFactory, len(latest), nil)
 	botPool.waitInit = true
 
 	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), latest[0]).Return(s.botClient1)
@@ -158,3 +278,28 @@ func (s *BotPoolTestSuite) TestWaitForAll() {
 	s.r.Len(botPool.botClients, 1)
 	s.r.Equal(botPool.botClients[0], s.botClient1)
 }
+
+func (s *BotPoolTestSuite) TestDrain() {
+	drainCheckInterval = time.Millisecond
+	s.botPool.botClients = []botio.BotClient{s.botClient1, s.botClient2}
+
+	gomock.InOrder(
+		s.botClient1.EXPECT().IsIdle().Return(false),
+		s.botClient1.EXPECT().IsIdle().Return(true),
+	)
+	s.botClient2.EXPECT().IsIdle().Return(true)
+	s.botClient1.EXPECT().Close()
+	s.botClient2.EXPECT().Close()
+
+	s.r.NoError(s.botPool.Drain(time.Second))
+}
+
+func (s *BotPoolTestSuite) TestDrainTimeout() {
+	drainCheckInterval = time.Millisecond
+	s.botPool.botClients = []botio.BotClient{s.botClient1}
+
+	s.botClient1.EXPECT().IsIdle().Return(false).AnyTimes()
+	s.botClient1.EXPECT().Close()
+
+	s.r.Error(s.botPool.Drain(time.Millisecond * 10))
+}
@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"time"
+)
+
+// Restart backoff limits
+var (
+	minRestartBackoff = time.Minute
+	maxRestartBackoff = time.Minute * 30
+)
+
+// restartBackoff keeps track of the consecutive restarts of bot containers and
+// delays the restarts exponentially so that crashing bots are not restarted in a tight loop.
+type restartBackoff struct {
+	restarts map[string]*restartRecord
+}
+
+type restartRecord struct {
+	attempts    int
+	nextRestart time.Time
+}
+
+func newRestartBackoff() *restartBackoff {
+	return &restartBackoff{
+		restarts: make(map[string]*restartRecord),
+	}
+}
+
+// ShouldRestart tells if enough time has passed since the last restart of the container.
+func (rb *restartBackoff) ShouldRestart(containerName string) bool {
+	record, ok := rb.restarts[containerName]
+	if !ok {
+		return true
+	}
+	return !time.Now().Before(record.nextRestart)
+}
+
+// Restarted saves a restart attempt and calculates the time of the next allowed restart.
+func (rb *restartBackoff) Restarted(containerName string) {
+	record, ok := rb.restarts[containerName]
+	if !ok {
+		record = &restartRecord{}
+		rb.restarts[containerName] = record
+	}
+	backoff := minRestartBackoff << record.attempts
+	if backoff > maxRestartBackoff || backoff <= 0 {
+		backoff = maxRestartBackoff
+	} else {
+		record.attempts++
+	}
+	record.nextRestart = time.Now().Add(backoff)
+}
+
+// Running resets the backoff for the container when it is seen running.
+func (rb *restartBackoff) Running(containerName string) {
+	delete(rb.restarts, containerName)
+}
+
+// Prune deletes the records of the containers which do not exist anymore, like the removed bots
+// and the replaced bot versions.
+func (rb *restartBackoff) Prune(containerNames map[string]bool) {
+	for containerName := range rb.restarts {
+		if !containerNames[containerName] {
+			delete(rb.restarts, containerName)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRestartContainer = "test-container"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	r := require.New(t)
+
+	rb := newRestartBackoff()
+	r.True(rb.ShouldRestart(testRestartContainer))
+
+	rb.Restarted(testRestartContainer)
+	r.False(rb.ShouldRestart(testRestartContainer))
+	r.Equal(1, rb.restarts[testRestartContainer].attempts)
+
+	// should be allowed after the backoff
+	rb.restarts[testRestartContainer].nextRestart = time.Now().Add(-time.Second)
+	r.True(rb.ShouldRestart(testRestartContainer))
+
+	// the backoff should grow exponentially
+	rb.Restarted(testRestartContainer)
+	r.WithinDuration(time.Now().Add(minRestartBackoff*2), rb.restarts[testRestartContainer].nextRestart, time.Second)
+
+	// running containers should start from scratch
+	rb.Running(testRestartContainer)
+	r.True(rb.ShouldRestart(testRestartContainer))
+}
+
+func TestRestartBackoffMax(t *testing.T) {
+	r := require.New(t)
+
+	rb := newRestartBackoff()
+	for i := 0; i < 100; i++ {
+		rb.Restarted(testRestartContainer)
+	}
+	r.WithinDuration(time.Now().Add(maxRestartBackoff), rb.restarts[testRestartContainer].nextRestart, time.Second)
+}
+
+func TestRestartBackoffPrune(t *testing.T) {
+	r := require.New(t)
+
+	rb := newRestartBackoff()
+	rb.Restarted(testRestartContainer)
+	rb.Restarted("removed-container")
+
+	rb.Prune(map[string]bool{testRestartContainer: true})
+	r.Len(rb.restarts, 1)
+	r.False(rb.ShouldRestart(testRestartContainer))
+	r.True(rb.ShouldRestart("removed-container"))
+}
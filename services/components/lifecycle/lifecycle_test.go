@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
 	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
@@ -71,6 +73,7 @@ func TestLifecycleTestSuite(t *testing.T) {
 func (s *LifecycleTestSuite) SetupTest() {
 	s.r = s.Require()
 	botRemoveTimeout = 0
+	botio.MinRedialBackoff = time.Hour // avoid redialing in the background
 
 	ctrl := gomock.NewController(s.T())
 	s.msgClient = mock_clients.NewMockMessageClient(ctrl)
@@ -82,10 +85,15 @@ func (s *LifecycleTestSuite) SetupTest() {
 	s.resultChannels = botreq.MakeResultChannels()
 	s.botMonitor = mock_lifecycle.NewMockBotMonitor(ctrl)
 
-	botClientFactory := botio.NewBotClientFactory(s.resultChannels.SendOnly(), s.msgClient, s.lifecycleMetrics, s.dialer)
-	s.botPool = NewBotPool(context.Background(), s.lifecycleMetrics, botClientFactory, 0)
+	botClientFactory := botio.NewBotClientFactory(
+		s.resultChannels.SendOnly(), s.msgClient, s.lifecycleMetrics, s.dialer, config.ScannerConfig{}, nil, "", nil, agentgrpc.SizeLimits{},
+	)
+	s.botPool = NewBotPool(context.Background(), s.lifecycleMetrics, botClientFactory, 0, nil)
 	s.botPool.waitInit = true // hack to make testing synchronous
-	s.botManager = NewManager(s.botRegistry, s.botContainers, s.botPool, s.lifecycleMetrics, s.botMonitor)
+	s.botManager = NewManager(
+		s.botRegistry, s.botContainers, s.botPool, s.lifecycleMetrics, s.botMonitor,
+		config.ResourcesConfig{}, nil,
+	)
 }
 
 func (s *LifecycleTestSuite) TestDownloadTimeout() {
@@ -178,7 +186,7 @@ func (s *LifecycleTestSuite) TestLaunchFailure() {
 }
 
 func (s *LifecycleTestSuite) TestDialFailure() {
-	s.T().Log("should not reload or redial a bot if dialing finally fails")
+	s.T().Log("should not reload a bot and should redial in the background if dialing finally fails")
 
 	assigned := []config.AgentConfig{
 		{
@@ -197,7 +205,9 @@ func (s *LifecycleTestSuite) TestDialFailure() {
 	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[0]).Return(nil).Times(1)
 	s.lifecycleMetrics.EXPECT().StatusRunning(assigned[0]).Times(2)
 	s.lifecycleMetrics.EXPECT().ClientDial(assigned[0]).Times(1)
-	s.dialer.EXPECT().DialBot(assigned[0]).Return(nil, errors.New("failed to dial")).Times(1)
+	dialErr := errors.New("failed to dial")
+	s.dialer.EXPECT().DialBot(assigned[0]).Return(nil, dialErr).Times(1)
+	s.lifecycleMetrics.EXPECT().FailureDial(dialErr, assigned[0]).Times(1)
 
 	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(assigned)).Times(2)
 
@@ -16,7 +16,10 @@ type lifecycleMediator struct {
 type Mediator interface {
 	ConnectBotPool(botPool lifecycle.BotPoolUpdater)
 	ConnectBotMonitor(botMonitor lifecycle.BotMonitorUpdater)
+	ConnectBotManager(botManager lifecycle.BotSwapUpdater)
 	lifecycle.BotPoolUpdater
+	lifecycle.BotSwapNotifier
+	lifecycle.BotLimitNotifier
 }
 
 // New creates a new bot lifecycle mediator for given bot client pool.
@@ -50,6 +53,13 @@ func (lm *lifecycleMediator) ConnectBotMonitor(botMonitor lifecycle.BotMonitorUp
 	)
 }
 
+// ConnectBotManager connects given bot manager by subscribing to bot swap messages.
+func (lm *lifecycleMediator) ConnectBotManager(botManager lifecycle.BotSwapUpdater) {
+	lm.msgClient.Subscribe(
+		messaging.SubjectAgentsStatusSwapped, messaging.AgentsHandler(botManager.RetireSwappedBots),
+	)
+}
+
 // implement the BotPoolUpdater interface by publishing the lifecycle management messages
 
 func (lm *lifecycleMediator) UpdateBotsWithLatestConfigs(payload messaging.AgentPayload) error {
@@ -66,3 +76,13 @@ func (lm *lifecycleMediator) ReconnectToBotsWithConfigs(payload messaging.AgentP
 	lm.msgClient.Publish(messaging.SubjectAgentsStatusRestarted, payload)
 	return nil
 }
+
+func (lm *lifecycleMediator) NotifyLimitExceeded(payload messaging.AgentLimitPayload) error {
+	lm.msgClient.Publish(messaging.SubjectAgentsStatusLimited, payload)
+	return nil
+}
+
+func (lm *lifecycleMediator) NotifyBotsSwapped(payload messaging.AgentPayload) error {
+	lm.msgClient.Publish(messaging.SubjectAgentsStatusSwapped, payload)
+	return nil
+}
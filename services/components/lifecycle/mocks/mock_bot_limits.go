@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/components/lifecycle/bot_limits.go
+
+// Package mock_lifecycle is a generated GoMock package.
+package mock_lifecycle
+
+import (
+	reflect "reflect"
+
+	messaging "github.com/forta-network/forta-node/clients/messaging"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBotLimitNotifier is a mock of BotLimitNotifier interface.
+type MockBotLimitNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotLimitNotifierMockRecorder
+}
+
+// MockBotLimitNotifierMockRecorder is the mock recorder for MockBotLimitNotifier.
+type MockBotLimitNotifierMockRecorder struct {
+	mock *MockBotLimitNotifier
+}
+
+// NewMockBotLimitNotifier creates a new mock instance.
+func NewMockBotLimitNotifier(ctrl *gomock.Controller) *MockBotLimitNotifier {
+	mock := &MockBotLimitNotifier{ctrl: ctrl}
+	mock.recorder = &MockBotLimitNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotLimitNotifier) EXPECT() *MockBotLimitNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyLimitExceeded mocks base method.
+func (m *MockBotLimitNotifier) NotifyLimitExceeded(arg0 messaging.AgentLimitPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyLimitExceeded", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyLimitExceeded indicates an expected call of NotifyLimitExceeded.
+func (mr *MockBotLimitNotifierMockRecorder) NotifyLimitExceeded(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyLimitExceeded", reflect.TypeOf((*MockBotLimitNotifier)(nil).NotifyLimitExceeded), arg0)
+}
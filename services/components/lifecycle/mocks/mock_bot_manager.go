@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	messaging "github.com/forta-network/forta-node/clients/messaging"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +49,20 @@ func (mr *MockBotLifecycleManagerMockRecorder) CleanupUnusedBots(ctx interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupUnusedBots", reflect.TypeOf((*MockBotLifecycleManager)(nil).CleanupUnusedBots), ctx)
 }
 
+// EnforceBotLimits mocks base method.
+func (m *MockBotLifecycleManager) EnforceBotLimits(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnforceBotLimits", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnforceBotLimits indicates an expected call of EnforceBotLimits.
+func (mr *MockBotLifecycleManagerMockRecorder) EnforceBotLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnforceBotLimits", reflect.TypeOf((*MockBotLifecycleManager)(nil).EnforceBotLimits), ctx)
+}
+
 // ExitInactiveBots mocks base method.
 func (m *MockBotLifecycleManager) ExitInactiveBots(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -101,3 +116,40 @@ func (mr *MockBotLifecycleManagerMockRecorder) TearDownRunningBots(ctx interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TearDownRunningBots", reflect.TypeOf((*MockBotLifecycleManager)(nil).TearDownRunningBots), ctx)
 }
+
+// MockBotSwapUpdater is a mock of BotSwapUpdater interface.
+type MockBotSwapUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotSwapUpdaterMockRecorder
+}
+
+// MockBotSwapUpdaterMockRecorder is the mock recorder for MockBotSwapUpdater.
+type MockBotSwapUpdaterMockRecorder struct {
+	mock *MockBotSwapUpdater
+}
+
+// NewMockBotSwapUpdater creates a new mock instance.
+func NewMockBotSwapUpdater(ctrl *gomock.Controller) *MockBotSwapUpdater {
+	mock := &MockBotSwapUpdater{ctrl: ctrl}
+	mock.recorder = &MockBotSwapUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotSwapUpdater) EXPECT() *MockBotSwapUpdaterMockRecorder {
+	return m.recorder
+}
+
+// RetireSwappedBots mocks base method.
+func (m *MockBotSwapUpdater) RetireSwappedBots(arg0 messaging.AgentPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireSwappedBots", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireSwappedBots indicates an expected call of RetireSwappedBots.
+func (mr *MockBotSwapUpdaterMockRecorder) RetireSwappedBots(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireSwappedBots", reflect.TypeOf((*MockBotSwapUpdater)(nil).RetireSwappedBots), arg0)
+}
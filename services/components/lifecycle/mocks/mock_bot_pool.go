@@ -6,6 +6,7 @@ package mock_lifecycle
 
 import (
 	reflect "reflect"
+	time "time"
 
 	messaging "github.com/forta-network/forta-node/clients/messaging"
 	botio "github.com/forta-network/forta-node/services/components/botio"
@@ -35,6 +36,20 @@ func (m *MockBotPool) EXPECT() *MockBotPoolMockRecorder {
 	return m.recorder
 }
 
+// Drain mocks base method.
+func (m *MockBotPool) Drain(timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockBotPoolMockRecorder) Drain(timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockBotPool)(nil).Drain), timeout)
+}
+
 // GetCurrentBotClients mocks base method.
 func (m *MockBotPool) GetCurrentBotClients() []botio.BotClient {
 	m.ctrl.T.Helper()
@@ -49,6 +64,20 @@ func (mr *MockBotPoolMockRecorder) GetCurrentBotClients() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentBotClients", reflect.TypeOf((*MockBotPool)(nil).GetCurrentBotClients))
 }
 
+// PendingRequests mocks base method.
+func (m *MockBotPool) PendingRequests() []botio.PendingRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingRequests")
+	ret0, _ := ret[0].([]botio.PendingRequest)
+	return ret0
+}
+
+// PendingRequests indicates an expected call of PendingRequests.
+func (mr *MockBotPoolMockRecorder) PendingRequests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingRequests", reflect.TypeOf((*MockBotPool)(nil).PendingRequests))
+}
+
 // ReconnectToBotsWithConfigs mocks base method.
 func (m *MockBotPool) ReconnectToBotsWithConfigs(arg0 messaging.AgentPayload) error {
 	m.ctrl.T.Helper()
@@ -167,3 +196,40 @@ func (mr *MockBotPoolUpdaterMockRecorder) UpdateBotsWithLatestConfigs(arg0 inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBotsWithLatestConfigs", reflect.TypeOf((*MockBotPoolUpdater)(nil).UpdateBotsWithLatestConfigs), arg0)
 }
+
+// MockBotSwapNotifier is a mock of BotSwapNotifier interface.
+type MockBotSwapNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotSwapNotifierMockRecorder
+}
+
+// MockBotSwapNotifierMockRecorder is the mock recorder for MockBotSwapNotifier.
+type MockBotSwapNotifierMockRecorder struct {
+	mock *MockBotSwapNotifier
+}
+
+// NewMockBotSwapNotifier creates a new mock instance.
+func NewMockBotSwapNotifier(ctrl *gomock.Controller) *MockBotSwapNotifier {
+	mock := &MockBotSwapNotifier{ctrl: ctrl}
+	mock.recorder = &MockBotSwapNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotSwapNotifier) EXPECT() *MockBotSwapNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyBotsSwapped mocks base method.
+func (m *MockBotSwapNotifier) NotifyBotsSwapped(arg0 messaging.AgentPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyBotsSwapped", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyBotsSwapped indicates an expected call of NotifyBotsSwapped.
+func (mr *MockBotSwapNotifierMockRecorder) NotifyBotsSwapped(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyBotsSwapped", reflect.TypeOf((*MockBotSwapNotifier)(nil).NotifyBotsSwapped), arg0)
+}
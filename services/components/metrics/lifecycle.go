@@ -36,6 +36,7 @@ const (
 	MetricFailureInitializeResponse = "agent.failure.initialize.response"
 	MetricFailureInitializeValidate = "agent.failure.initialize.validate"
 	MetricFailureTooManyErrs        = "agent.failure.too-many-errs"
+	MetricFailureResourceLimit      = "agent.failure.resource-limit"
 )
 
 // Lifecycle creates lifecycle metrics. It is useful in
@@ -64,6 +65,7 @@ type Lifecycle interface {
 	FailureInitializeResponse(error, ...config.AgentConfig)
 	FailureInitializeValidate(error, ...config.AgentConfig)
 	FailureTooManyErrs(error, ...config.AgentConfig)
+	FailureResourceLimit(error, ...config.AgentConfig)
 
 	BotError(metricName string, err error, cfgs ...config.AgentConfig)
 	SystemError(metricName string, err error)
@@ -162,6 +164,10 @@ func (lc *lifecycle) FailureTooManyErrs(err error, botConfigs ...config.AgentCon
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricFailureTooManyErrs, err.Error(), botConfigs))
 }
 
+func (lc *lifecycle) FailureResourceLimit(err error, botConfigs ...config.AgentConfig) {
+	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricFailureResourceLimit, err.Error(), botConfigs))
+}
+
 func (lc *lifecycle) BotError(metricName string, err error, botConfigs ...config.AgentConfig) {
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(fmt.Sprintf("agent.error.%s", metricName), err.Error(), botConfigs))
 }
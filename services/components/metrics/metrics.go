@@ -32,16 +32,41 @@ const (
 	MetricJSONRPCRequest          = "jsonrpc.request"
 	MetricJSONRPCSuccess          = "jsonrpc.success"
 	MetricJSONRPCThrottled        = "jsonrpc.throttled"
+	MetricJSONRPCBlocked          = "jsonrpc.blocked"
+	MetricJSONRPCCacheHit         = "jsonrpc.cache.hit"
+	MetricJSONRPCCacheMiss        = "jsonrpc.cache.miss"
 	MetricPublicAPIProxyLatency   = "publicapi.latency"
 	MetricPublicAPIProxyRequest   = "publicapi.request"
 	MetricPublicAPIProxySuccess   = "publicapi.success"
 	MetricPublicAPIProxyThrottled = "publicapi.throttled"
 	MetricFindingsDropped         = "findings.dropped"
+	MetricFindingInvalid          = "finding.invalid"
+	MetricAlertDuplicate          = "alert.duplicate"
+	MetricAlertThrottled          = "alert.throttled"
+	MetricAlertSuppressed         = "alert.suppressed"
+	MetricBlockDuplicate          = "block.duplicate"
+	MetricTxDuplicate             = "tx.duplicate"
+	MetricTxContentTruncated      = "tx.content.truncated"
+	MetricTxContentDropped        = "tx.content.dropped"
 	MetricCombinerRequest         = "combiner.request"
 	MetricCombinerLatency         = "combiner.latency"
 	MetricCombinerError           = "combiner.error"
 	MetricCombinerSuccess         = "combiner.success"
 	MetricCombinerDrop            = "combiner.drop"
+	MetricCircuitOpen             = "circuit.open"
+	MetricCircuitClosed           = "circuit.closed"
+	MetricHealthFailing           = "health.failing"
+	MetricHealthRecovered         = "health.recovered"
+	MetricPerformanceDisabled     = "performance.disabled"
+	MetricPerformanceEnabled      = "performance.enabled"
+	MetricRequestDeadLetter       = "request.dead-letter"
+	MetricRequestReplay           = "request.replay"
+
+	MetricAlertFeedLatency    = "alert.feed.latency"
+	MetricAlertQueueLatency   = "alert.queue.latency"
+	MetricAlertBotLatency     = "alert.bot.latency"
+	MetricAlertPublishLatency = "alert.publish.latency"
+	MetricAlertTotalLatency   = "alert.total.latency"
 )
 
 func SendAgentMetrics(client clients.MessageClient, ms []*protocol.AgentMetric) {
@@ -133,6 +158,18 @@ func GetCombinerMetrics(agt config.AgentConfig, resp *protocol.EvaluateAlertResp
 	return createMetrics(agt, resp.Timestamp, metrics)
 }
 
+// GetAlertLatencyMetrics returns the latency breakdown of a published alert as metrics.
+func GetAlertLatencyMetrics(botID string, at time.Time, latency *clients.LatencyBreakdown) []*protocol.AgentMetric {
+	values := map[string]float64{
+		MetricAlertFeedLatency:    float64(latency.Feed.Milliseconds()),
+		MetricAlertQueueLatency:   float64(latency.Queue.Milliseconds()),
+		MetricAlertBotLatency:     float64(latency.Bot.Milliseconds()),
+		MetricAlertPublishLatency: float64(latency.Publish.Milliseconds()),
+		MetricAlertTotalLatency:   float64(latency.Total.Milliseconds()),
+	}
+	return createMetrics(config.AgentConfig{ID: botID}, at.Format(time.RFC3339), values)
+}
+
 func GetJSONRPCMetrics(agt config.AgentConfig, at time.Time, success, throttled int, latencyMs time.Duration) []*protocol.AgentMetric {
 	values := make(map[string]float64)
 	if latencyMs > 0 {
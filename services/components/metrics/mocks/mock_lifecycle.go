@@ -242,6 +242,23 @@ func (mr *MockLifecycleMockRecorder) FailurePull(arg0 interface{}, arg1 ...inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailurePull", reflect.TypeOf((*MockLifecycle)(nil).FailurePull), varargs...)
 }
 
+// FailureResourceLimit mocks base method.
+func (m *MockLifecycle) FailureResourceLimit(arg0 error, arg1 ...config.AgentConfig) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "FailureResourceLimit", varargs...)
+}
+
+// FailureResourceLimit indicates an expected call of FailureResourceLimit.
+func (mr *MockLifecycleMockRecorder) FailureResourceLimit(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureResourceLimit", reflect.TypeOf((*MockLifecycle)(nil).FailureResourceLimit), varargs...)
+}
+
 // FailureStop mocks base method.
 func (m *MockLifecycle) FailureStop(arg0 error, arg1 ...config.AgentConfig) {
 	m.ctrl.T.Helper()
@@ -77,3 +77,18 @@ func (mr *MockBotRegistryMockRecorder) Name() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockBotRegistry)(nil).Name))
 }
+
+// PrepareLocalBots mocks base method.
+func (m *MockBotRegistry) PrepareLocalBots(cfg config.Config) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareLocalBots", cfg)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareLocalBots indicates an expected call of PrepareLocalBots.
+func (mr *MockBotRegistryMockRecorder) PrepareLocalBots(cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareLocalBots", reflect.TypeOf((*MockBotRegistry)(nil).PrepareLocalBots), cfg)
+}
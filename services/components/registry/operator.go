@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// OperatorRegistry adds the bots which the operator adds from the admin API to the assigned bots
+// and leaves out the bots which the operator removes. The changes are kept until the restart.
+type OperatorRegistry struct {
+	botRegistry BotRegistry
+	chainID     int
+
+	// the added bots and the removed bot IDs
+	added   []config.AgentConfig
+	removed map[string]bool
+	mu      sync.Mutex
+}
+
+// NewOperatorRegistry wraps the bot registry. The added bots scan the chain if they do not
+// declare a chain.
+func NewOperatorRegistry(botRegistry BotRegistry, chainID int) *OperatorRegistry {
+	return &OperatorRegistry{
+		botRegistry: botRegistry,
+		chainID:     chainID,
+		removed:     make(map[string]bool),
+	}
+}
+
+// AddBots adds the bots or replaces the added bots with the same IDs.
+func (reg *OperatorRegistry) AddBots(bots []config.AgentConfig) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, bot := range bots {
+		if len(bot.ID) == 0 {
+			return errors.New("bot id is required")
+		}
+	}
+	for _, bot := range bots {
+		if bot.ChainID == 0 {
+			bot.ChainID = reg.chainID
+		}
+		reg.removeAdded(bot.ID)
+		reg.added = append(reg.added, bot)
+		delete(reg.removed, bot.ID)
+		log.WithField("bot", bot.ID).WithField("image", bot.Image).Info("operator added bot")
+	}
+	return nil
+}
+
+// RemoveBots removes the bots with the IDs of the given bots, whether they are added by the
+// operator or assigned to the node.
+func (reg *OperatorRegistry) RemoveBots(bots []config.AgentConfig) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, bot := range bots {
+		reg.removeAdded(bot.ID)
+		reg.removed[bot.ID] = true
+		log.WithField("bot", bot.ID).Info("operator removed bot")
+	}
+	return nil
+}
+
+func (reg *OperatorRegistry) removeAdded(botID string) {
+	var added []config.AgentConfig
+	for _, bot := range reg.added {
+		if bot.ID != botID {
+			added = append(added, bot)
+		}
+	}
+	reg.added = added
+}
+
+// PrepareLocalBots implements the BotRegistry interface. The added and the removed bots are kept.
+func (reg *OperatorRegistry) PrepareLocalBots(cfg config.Config) (func(), error) {
+	return reg.botRegistry.PrepareLocalBots(cfg)
+}
+
+// LoadAssignedBots implements the BotRegistry interface.
+func (reg *OperatorRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
+	assigned, err := reg.botRegistry.LoadAssignedBots()
+	if err != nil {
+		return nil, err
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if len(reg.added) == 0 && len(reg.removed) == 0 {
+		return assigned, nil
+	}
+	added := make(map[string]bool)
+	for _, bot := range reg.added {
+		added[bot.ID] = true
+	}
+	var bots []config.AgentConfig
+	for _, bot := range assigned {
+		// the added bots replace the assigned bots with the same IDs
+		if reg.removed[bot.ID] || added[bot.ID] {
+			continue
+		}
+		bots = append(bots, bot)
+	}
+	return append(bots, reg.added...), nil
+}
+
+// Name implements the health.Reporter interface.
+func (reg *OperatorRegistry) Name() string {
+	return reg.botRegistry.Name()
+}
+
+// Health implements the health.Reporter interface.
+func (reg *OperatorRegistry) Health() health.Reports {
+	return reg.botRegistry.Health()
+}
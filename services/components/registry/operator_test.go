@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	mock_registry "github.com/forta-network/forta-node/services/components/registry/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperatorRegistry(t *testing.T) {
+	r := require.New(t)
+
+	botReg := mock_registry.NewMockBotRegistry(gomock.NewController(t))
+	assigned := []config.AgentConfig{{ID: "bot1", Image: "image1"}, {ID: "bot2", Image: "image2"}}
+	botReg.EXPECT().LoadAssignedBots().Return(assigned, nil).AnyTimes()
+
+	reg := NewOperatorRegistry(botReg, 1)
+	bots, err := reg.LoadAssignedBots()
+	r.NoError(err)
+	r.Equal(assigned, bots)
+
+	r.Error(reg.AddBots([]config.AgentConfig{{Image: "image3"}}))
+	r.NoError(reg.AddBots([]config.AgentConfig{{ID: "bot3", Image: "image3"}, {ID: "bot2", Image: "image2-new"}}))
+	r.NoError(reg.RemoveBots([]config.AgentConfig{{ID: "bot1"}}))
+	bots, err = reg.LoadAssignedBots()
+	r.NoError(err)
+	r.Equal([]config.AgentConfig{
+		{ID: "bot3", Image: "image3", ChainID: 1},
+		{ID: "bot2", Image: "image2-new", ChainID: 1},
+	}, bots)
+
+	// removing the added bot leaves out also the assigned bot with the same ID
+	r.NoError(reg.RemoveBots([]config.AgentConfig{{ID: "bot2"}}))
+	r.NoError(reg.AddBots([]config.AgentConfig{{ID: "bot1", Image: "image1", ChainID: 137}}))
+	bots, err = reg.LoadAssignedBots()
+	r.NoError(err)
+	r.Equal([]config.AgentConfig{
+		{ID: "bot3", Image: "image3", ChainID: 1},
+		{ID: "bot1", Image: "image1", ChainID: 137},
+	}, bots)
+}
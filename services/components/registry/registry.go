@@ -15,6 +15,7 @@ import (
 // BotRegistry loads the latest bots from the registry store.
 type BotRegistry interface {
 	LoadAssignedBots() ([]config.AgentConfig, error)
+	PrepareLocalBots(cfg config.Config) (func(), error)
 	health.Reporter
 }
 
@@ -58,6 +59,7 @@ func New(cfg config.Config, scannerAddress common.Address) (BotRegistry, error)
 func (br *botRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	br.lastChecked.Set()
 	agts, changed, err := br.registryStore.GetAgentsIfChanged(br.scannerAddress.Hex())
+	br.lastErr.Set(err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get the latest bot list: %v", err)
 	}
@@ -65,8 +67,14 @@ func (br *botRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	logger := log.WithField("component", "bot-loader")
 	if changed {
 		br.lastChangeDetected.Set()
+		added, updated, removed := diffBotLists(br.botConfigs, agts)
 		br.botConfigs = agts
-		logger.WithField("count", len(agts)).Info("updated bot list")
+		logger.WithFields(log.Fields{
+			"count":   len(agts),
+			"added":   len(added),
+			"updated": len(updated),
+			"removed": len(removed),
+		}).Info("updated bot list")
 	} else {
 		logger.Debug("no bot list changes detected")
 	}
@@ -74,6 +82,50 @@ func (br *botRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	return br.botConfigs, nil
 }
 
+// PrepareLocalBots returns the function which replaces the local mode bot list with the bots of the
+// reloaded config, so that the next bot list loads start and stop the changed bots. The bot list
+// does not change outside the local mode.
+func (br *botRegistry) PrepareLocalBots(cfg config.Config) (func(), error) {
+	// only the private registry store loads the bots from the local mode config
+	localStore, ok := br.registryStore.(interface {
+		SetLocalModeConfig(localModeCfg config.LocalModeConfig)
+	})
+	if !ok || !cfg.LocalModeConfig.Enable {
+		return func() {}, nil
+	}
+	localModeCfg := cfg.LocalModeConfig
+	return func() {
+		localStore.SetLocalModeConfig(localModeCfg)
+	}, nil
+}
+
+// diffBotLists finds the bots which are added to, updated in and removed from the previous list.
+// The diff is only logged: the lifecycle manager applies the latest list to the bot containers and
+// to the bot pool, which starts the added bots, swaps the new versions, updates the configs of the
+// bots with the updated manifests and removes the unassigned bots.
+func diffBotLists(prev, latest []config.AgentConfig) (added, updated, removed []config.AgentConfig) {
+	prevBots := make(map[string]config.AgentConfig)
+	for _, bot := range prev {
+		prevBots[bot.ContainerName()] = bot
+	}
+	for _, bot := range latest {
+		prevBot, ok := prevBots[bot.ContainerName()]
+		switch {
+		case !ok:
+			added = append(added, bot)
+		case !prevBot.Equal(bot):
+			updated = append(updated, bot)
+		}
+		delete(prevBots, bot.ContainerName())
+	}
+	for _, bot := range prev {
+		if _, ok := prevBots[bot.ContainerName()]; ok {
+			removed = append(removed, bot)
+		}
+	}
+	return
+}
+
 // Name implements health.Reporter interface.
 func (br *botRegistry) Name() string {
 	return "bot-registry"
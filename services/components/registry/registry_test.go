@@ -41,3 +41,21 @@ func TestLoadAssignedBots(t *testing.T) {
 	r.Error(err)
 	r.Nil(retCfgs)
 }
+
+func TestDiffBotLists(t *testing.T) {
+	r := require.New(t)
+
+	prev := []config.AgentConfig{
+		{ID: "0x1", Manifest: "manifest1"},
+		{ID: "0x2", Manifest: "manifest2"},
+	}
+	latest := []config.AgentConfig{
+		{ID: "0x2", Manifest: "manifest2-updated"},
+		{ID: "0x3", Manifest: "manifest3"},
+	}
+
+	added, updated, removed := diffBotLists(prev, latest)
+	r.Equal([]config.AgentConfig{latest[1]}, added)
+	r.Equal([]config.AgentConfig{latest[0]}, updated)
+	r.Equal([]config.AgentConfig{prev[0]}, removed)
+}
@@ -0,0 +1,253 @@
+package reload
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// The settings which can be reloaded by their YAML paths
+const (
+	SettingLogLevel     = "log.level"
+	SettingAlertFilter  = "alertFilter"
+	SettingSinks        = "publish.sinks"
+	SettingRoutes       = "publish.routes"
+	SettingRateLimit    = "jsonRpcProxy.rateLimit"
+	SettingBotRateLimit = "jsonRpcProxy.botRateLimits"
+)
+
+// ScannerSettings are the settings which the scanner reloads.
+var ScannerSettings = []string{SettingLogLevel, SettingAlertFilter, SettingSinks, SettingRoutes}
+
+// RateLimitSettings are the settings of the JSON-RPC proxy rate limits, which the json-rpc container reloads.
+var RateLimitSettings = []string{SettingRateLimit, SettingBotRateLimit}
+
+// LocalBotSettings are the settings of the local mode bot list, which the supervisor reloads.
+var LocalBotSettings = []string{
+	"localMode.botImages",
+	"localMode.botIds",
+	"localMode.shardedBots",
+	"localMode.attachedBots",
+	"localMode.httpBots",
+	"localMode.botFilters",
+}
+
+// Prepare checks the new config and creates what the settings need without applying them, so
+// that a reload applies all of the changes or none of them. The returned function applies the settings.
+type Prepare func(cfg config.Config) (apply func(), err error)
+
+type handler struct {
+	settings []string
+	// nil if the settings are applied by the other containers
+	prepare Prepare
+}
+
+// Report tells which of the changed settings were applied and which need a restart.
+type Report struct {
+	Applied         []string `json:"applied"`
+	RequiresRestart []string `json:"requiresRestart"`
+}
+
+// Reloader reloads the config and applies the changed settings which do not need a restart.
+type Reloader struct {
+	load      func() (config.Config, error)
+	msgClient clients.MessageClient
+
+	current  config.Config
+	handlers []*handler
+	mu       sync.Mutex
+}
+
+// New creates a new reloader with the currently loaded config. The reloader publishes the reload
+// message after the applied changes, so that the other containers reload their settings, if the
+// message client is set.
+func New(load func() (config.Config, error), msgClient clients.MessageClient) (*Reloader, error) {
+	current, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the config: %v", err)
+	}
+	return &Reloader{load: load, msgClient: msgClient, current: current}, nil
+}
+
+// Handle applies the settings with the prepare function when any of them change.
+func (r *Reloader) Handle(prepare Prepare, settings ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, &handler{settings: settings, prepare: prepare})
+}
+
+// Delegate marks the settings as reloadable by the other containers when they receive the reload message.
+func (r *Reloader) Delegate(settings ...string) {
+	r.Handle(nil, settings...)
+}
+
+// Reload loads the config again and applies the changed settings.
+func (r *Reloader) Reload() (*Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report, err := r.reload()
+	if err != nil {
+		log.WithError(err).Error("failed to reload the config")
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"applied":         report.Applied,
+		"requiresRestart": report.RequiresRestart,
+	}).Info("reloaded the config")
+	if r.msgClient != nil && len(report.Applied) > 0 {
+		r.msgClient.Publish(messaging.SubjectAdminConfigReload, messaging.ConfigReloadPayload{})
+	}
+	return report, nil
+}
+
+func (r *Reloader) reload() (*Report, error) {
+	next, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the config: %v", err)
+	}
+
+	var (
+		changed []*handler
+		applies []func()
+	)
+	for _, h := range r.handlers {
+		handlerChanged, err := r.changed(next, h.settings)
+		if err != nil {
+			return nil, err
+		}
+		if !handlerChanged {
+			continue
+		}
+		changed = append(changed, h)
+		if err := validateSettings(next, h.settings); err != nil {
+			return nil, err
+		}
+		if h.prepare == nil {
+			continue
+		}
+		apply, err := h.prepare(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload %s: %v", strings.Join(h.settings, ", "), err)
+		}
+		applies = append(applies, apply)
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+	report := &Report{Applied: []string{}, RequiresRestart: []string{}}
+	for _, h := range changed {
+		for _, setting := range h.settings {
+			currentValue, _ := settingValue(&r.current, setting)
+			nextValue, _ := settingValue(&next, setting)
+			if reflect.DeepEqual(currentValue.Interface(), nextValue.Interface()) {
+				continue
+			}
+			currentValue.Set(nextValue)
+			report.Applied = append(report.Applied, setting)
+		}
+	}
+	// the applied settings are the same now, so the rest of the differences need a restart
+	diff("", reflect.ValueOf(r.current), reflect.ValueOf(next), &report.RequiresRestart)
+	return report, nil
+}
+
+func (r *Reloader) changed(next config.Config, settings []string) (bool, error) {
+	for _, setting := range settings {
+		currentValue, err := settingValue(&r.current, setting)
+		if err != nil {
+			return false, err
+		}
+		nextValue, _ := settingValue(&next, setting)
+		if !reflect.DeepEqual(currentValue.Interface(), nextValue.Interface()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateSettings checks only the settings, since the other invalid settings are not applied.
+func validateSettings(cfg config.Config, settings []string) error {
+	err := cfg.Validate()
+	var validationErrs config.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	var errs config.ValidationErrors
+	for _, validationErr := range validationErrs {
+		for _, setting := range settings {
+			if strings.HasPrefix(validationErr, setting+":") || strings.HasPrefix(validationErr, setting+".") ||
+				strings.HasPrefix(validationErr, setting+"[") {
+				errs = append(errs, validationErr)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// settingValue finds the config field by the YAML path.
+func settingValue(cfg *config.Config, setting string) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, name := range strings.Split(setting, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown setting: %s", setting)
+		}
+		field, ok := fieldByYAMLName(v, name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("unknown setting: %s", setting)
+		}
+		v = field
+	}
+	return v, nil
+}
+
+func fieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func yamlName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+	if !field.IsExported() || name == "-" {
+		return ""
+	}
+	return name
+}
+
+// diff appends the YAML paths of the different values. The structs are compared by their fields.
+func diff(path string, a, b reflect.Value, changed *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, path)
+		}
+		return
+	}
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if len(name) == 0 {
+			continue
+		}
+		fieldPath := name
+		if len(path) > 0 {
+			fieldPath = path + "." + name
+		}
+		diff(fieldPath, a.Field(i), b.Field(i), changed)
+	}
+}
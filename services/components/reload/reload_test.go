@@ -0,0 +1,128 @@
+package reload
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfigFile struct {
+	cfg config.Config
+	err error
+}
+
+func (file *testConfigFile) load() (config.Config, error) {
+	return file.cfg, file.err
+}
+
+func newTestConfigFile(t *testing.T) *testConfigFile {
+	var cfg config.Config
+	require.NoError(t, defaults.Set(&cfg))
+	return &testConfigFile{cfg: cfg}
+}
+
+func TestReload(t *testing.T) {
+	r := require.New(t)
+
+	file := newTestConfigFile(t)
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	reloader, err := New(file.load, msgClient)
+	r.NoError(err)
+
+	var logLevel string
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		return func() { logLevel = cfg.Log.Level }, nil
+	}, SettingLogLevel)
+	var maxAlerts int
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		return func() { maxAlerts = cfg.AlertFilter.MaxAlertsPerMinute }, nil
+	}, SettingAlertFilter)
+	reloader.Delegate(LocalBotSettings...)
+
+	// nothing changed
+	report, err := reloader.Reload()
+	r.NoError(err)
+	r.Empty(report.Applied)
+	r.Empty(report.RequiresRestart)
+
+	file.cfg.Log.Level = "debug"
+	file.cfg.LocalModeConfig.BotImages = []string{"bot-image"}
+	file.cfg.Scan.JsonRpc.Url = "http://changed:8545"
+	msgClient.EXPECT().Publish(messaging.SubjectAdminConfigReload, messaging.ConfigReloadPayload{})
+	report, err = reloader.Reload()
+	r.NoError(err)
+	r.Equal([]string{SettingLogLevel, "localMode.botImages"}, report.Applied)
+	r.Equal([]string{"scan.jsonRpc.url"}, report.RequiresRestart)
+	r.Equal("debug", logLevel)
+	r.Zero(maxAlerts)
+
+	// the applied settings are not applied again but the restart is still needed
+	report, err = reloader.Reload()
+	r.NoError(err)
+	r.Empty(report.Applied)
+	r.Equal([]string{"scan.jsonRpc.url"}, report.RequiresRestart)
+}
+
+func TestReload_Atomic(t *testing.T) {
+	r := require.New(t)
+
+	file := newTestConfigFile(t)
+	reloader, err := New(file.load, nil)
+	r.NoError(err)
+
+	var applied int
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		return func() { applied++ }, nil
+	}, SettingLogLevel)
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		return nil, errors.New("failed to connect")
+	}, SettingSinks)
+
+	file.cfg.Log.Level = "debug"
+	file.cfg.Publish.Sinks = []config.AlertSinkConfig{{Name: "sink"}}
+	_, err = reloader.Reload()
+	r.Error(err)
+	r.Zero(applied)
+
+	// the invalid reloadable settings are not applied
+	file.cfg.Publish.Sinks = nil
+	file.cfg.AlertFilter.MaxAlertsPerMinute = -1
+	reloader.Handle(func(cfg config.Config) (func(), error) {
+		return func() { applied++ }, nil
+	}, SettingAlertFilter)
+	_, err = reloader.Reload()
+	r.Error(err)
+	r.Contains(err.Error(), "alertFilter.maxAlertsPerMinute")
+	r.Zero(applied)
+
+	// the invalid settings which need a restart do not stop the reload
+	file.cfg.AlertFilter.MaxAlertsPerMinute = 0
+	file.cfg.Scan.BotConcurrency = 0
+	report, err := reloader.Reload()
+	r.NoError(err)
+	r.Equal([]string{SettingLogLevel}, report.Applied)
+	r.Equal([]string{"scan.botConcurrency"}, report.RequiresRestart)
+	r.Equal(1, applied)
+
+	file.err = errors.New("yaml error")
+	_, err = reloader.Reload()
+	r.Error(err)
+}
+
+func TestReload_UnknownSetting(t *testing.T) {
+	r := require.New(t)
+
+	file := newTestConfigFile(t)
+	reloader, err := New(file.load, nil)
+	r.NoError(err)
+	reloader.Delegate("log.unknown")
+
+	_, err = reloader.Reload()
+	r.EqualError(err, "unknown setting: log.unknown")
+}
@@ -0,0 +1,201 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"time"
+)
+
+// TLS file names
+const (
+	TLSDirName        = ".agent-tls"
+	TLSCACertFileName = "ca.pem"
+	TLSCAKeyFileName  = "ca-key.pem"
+)
+
+// Certificate validity periods
+const (
+	CAValidity   = time.Hour * 24 * 365 * 10
+	CertValidity = time.Hour * 24 * 365
+)
+
+// CA issues the certificates which the node and the bots use to authenticate
+// each other in the gRPC connections.
+type CA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+// TLSDir returns the directory of the bot certificate authority files.
+func TLSDir(fortaDir string) string {
+	return path.Join(fortaDir, TLSDirName)
+}
+
+// LoadOrCreateCA loads the certificate authority from the directory or creates it if it does not exist.
+func LoadOrCreateCA(dir string) (*CA, error) {
+	ca, err := LoadCA(dir)
+	if err == nil {
+		return ca, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ca, err = newCA()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the bot certificate authority: %v", err)
+	}
+	keyPEM, err := encodeKey(ca.key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the tls dir: %v", err)
+	}
+	if err := os.WriteFile(path.Join(dir, TLSCAKeyFileName), keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the ca key: %v", err)
+	}
+	if err := os.WriteFile(path.Join(dir, TLSCACertFileName), ca.certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the ca cert: %v", err)
+	}
+	return ca, nil
+}
+
+// LoadCA loads the certificate authority from the directory.
+func LoadCA(dir string) (*CA, error) {
+	certPEM, err := os.ReadFile(path.Join(dir, TLSCACertFileName))
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(path.Join(dir, TLSCAKeyFileName))
+	if err != nil {
+		return nil, err
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the ca key pair: %v", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ca cert: %v", err)
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("ca key is not an ecdsa key")
+	}
+	return &CA{cert: cert, certPEM: certPEM, key: key}, nil
+}
+
+func newCA() (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "forta-node-bot-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}, nil
+}
+
+// CertPEM returns the certificate of the certificate authority in PEM format.
+func (ca *CA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// IssueCert issues a new certificate which can be used both for serving and dialing.
+// The DNS names are verified by the clients which connect to the certificate owner.
+func (ca *CA) IssueCert(commonName string, dnsNames ...string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(CertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to issue cert: %v", err)
+	}
+	keyPEM, err = encodeKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM, nil
+}
+
+// ClientTLSConfig issues a node certificate and returns the config to dial the bots with mutual TLS.
+// The bot certificates are verified by using the server name of each connection.
+func (ca *CA) ClientTLSConfig() (*tls.Config, error) {
+	certPEM, keyPEM, err := ca.IssueCert("forta-node")
+	if err != nil {
+		return nil, err
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the node key pair: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
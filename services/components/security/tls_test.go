@@ -0,0 +1,81 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreateCA(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	_, err := LoadCA(dir)
+	r.Error(err)
+
+	ca, err := LoadOrCreateCA(dir)
+	r.NoError(err)
+
+	loadedCA, err := LoadCA(dir)
+	r.NoError(err)
+	r.Equal(ca.CertPEM(), loadedCA.CertPEM())
+}
+
+func TestMutualTLS(t *testing.T) {
+	r := require.New(t)
+
+	ca, err := LoadOrCreateCA(t.TempDir())
+	r.NoError(err)
+
+	certPEM, keyPEM, err := ca.IssueCert("bot", "localhost")
+	r.NoError(err)
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	r.NoError(err)
+	pool := x509.NewCertPool()
+	r.True(pool.AppendCertsFromPEM(ca.CertPEM()))
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	r.NoError(err)
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+
+	// the node certificate should be accepted by the bot
+	clientConfig, err := ca.ClientTLSConfig()
+	r.NoError(err)
+	conn, err := tls.Dial("tcp", net.JoinHostPort("localhost", port), clientConfig)
+	r.NoError(err)
+	b, err := io.ReadAll(conn)
+	r.NoError(err)
+	r.Equal("ok", string(b))
+	conn.Close()
+
+	// the bot certificate should be rejected if the name does not match
+	clientConfig.ServerName = "other-bot"
+	_, err = tls.Dial("tcp", net.JoinHostPort("localhost", port), clientConfig)
+	r.Error(err)
+
+	// the clients without a node certificate should be rejected
+	conn, err = tls.Dial("tcp", net.JoinHostPort("localhost", port), &tls.Config{RootCAs: pool})
+	if err == nil {
+		_, err = io.ReadAll(conn)
+	}
+	r.Error(err)
+}
@@ -0,0 +1,397 @@
+package tokens
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// TransferTopic is the signature of the ERC-20 Transfer(address,address,uint256) event.
+const TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// The tags of the alerts which contain the annotations.
+const (
+	// the transfers as JSON
+	TagTransfers = "tokenTransfers"
+	// the total USD value of the transfers which have a price
+	TagTransfersUSD = "tokenTransfersUsd"
+)
+
+const (
+	selectorSymbol   = "0x95d89b41"
+	selectorDecimals = "0x313ce567"
+
+	cacheSize = 10000
+	// the metadata lookups which fail are retried after this interval
+	metadataRetryInterval = time.Minute * 10
+	// bounds all of the lookups of a finding
+	lookupTimeout = time.Second * 5
+	maxPriceSize  = 1 << 20
+	maxSymbolLen  = 32
+)
+
+// Transfer is a token transfer of the transaction which the finding is about.
+type Transfer struct {
+	Token    string `json:"token"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals *uint8 `json:"decimals,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	// the raw amount as a decimal number
+	Value string `json:"value"`
+	// the amount with the decimals of the token
+	Amount   string   `json:"amount,omitempty"`
+	USDValue *float64 `json:"usdValue,omitempty"`
+
+	value *big.Int
+}
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type tokenEntry struct {
+	address string
+
+	symbol          string
+	decimals        *uint8
+	metadataFetched time.Time
+	metadataErr     error
+
+	price        *float64
+	priceFetched time.Time
+}
+
+// Enricher annotates the findings with the token transfers of the transactions. The metadata and
+// the prices of the tokens are cached.
+type Enricher struct {
+	rpcClient  rpcCaller
+	httpClient *http.Client
+	chainID    int
+	cfg        config.TokenEnrichmentConfig
+
+	// ordered from the least recently used to the most recently used
+	order   *list.List
+	entries map[string]*list.Element
+	mu      sync.Mutex
+}
+
+// NewEnricher creates a new enricher which reads the token metadata from the json-rpc api.
+func NewEnricher(ctx context.Context, url string, chainID int, cfg config.TokenEnrichmentConfig) (*Enricher, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial token metadata api: %v", err)
+	}
+	return newEnricher(rpcClient, httpclient.Default, chainID, cfg), nil
+}
+
+func newEnricher(rpcClient rpcCaller, httpClient *http.Client, chainID int, cfg config.TokenEnrichmentConfig) *Enricher {
+	return &Enricher{
+		rpcClient:  rpcClient,
+		httpClient: httpClient,
+		chainID:    chainID,
+		cfg:        cfg,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Transfers finds the ERC-20 transfers of the transaction which involve the addresses as the token,
+// the sender or the receiver. All of the transfers are returned if there are no addresses.
+func Transfers(event *protocol.TransactionEvent, addresses []string) []*Transfer {
+	logs := event.GetLogs()
+	if len(logs) == 0 {
+		logs = event.GetReceipt().GetLogs()
+	}
+	involved := make(map[string]bool)
+	for _, address := range addresses {
+		involved[strings.ToLower(address)] = true
+	}
+	var transfers []*Transfer
+	for _, txLog := range logs {
+		// the ERC-721 transfers have the same signature and the token ID as the third indexed topic
+		if len(txLog.Topics) != 3 || !strings.EqualFold(txLog.Topics[0], TransferTopic) || txLog.Removed {
+			continue
+		}
+		data, err := hexutil.Decode(txLog.Data)
+		if err != nil || len(data) != 32 {
+			continue
+		}
+		transfer := &Transfer{
+			Token: strings.ToLower(txLog.Address),
+			From:  strings.ToLower(common.HexToAddress(txLog.Topics[1]).Hex()),
+			To:    strings.ToLower(common.HexToAddress(txLog.Topics[2]).Hex()),
+			value: new(big.Int).SetBytes(data),
+		}
+		transfer.Value = transfer.value.String()
+		if len(involved) > 0 && !involved[transfer.Token] && !involved[transfer.From] && !involved[transfer.To] {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
+
+// Enrich annotates the alert with the token transfers which the finding is about. It does nothing
+// if there are no such transfers.
+func (e *Enricher) Enrich(ctx context.Context, event *protocol.TransactionEvent, alert *protocol.Alert) {
+	if alert.Finding == nil {
+		return
+	}
+	transfers := Transfers(event, alert.Finding.Addresses)
+	if len(transfers) == 0 {
+		return
+	}
+	if len(transfers) > e.cfg.MaxTransfers {
+		transfers = transfers[:e.cfg.MaxTransfers]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	var (
+		total  float64
+		priced bool
+	)
+	for _, transfer := range transfers {
+		entry := e.getEntry(ctx, transfer.Token)
+		transfer.Symbol, transfer.Decimals = entry.symbol, entry.decimals
+		if transfer.Decimals == nil {
+			continue
+		}
+		amount := new(big.Float).Quo(new(big.Float).SetInt(transfer.value), pow10(*transfer.Decimals))
+		transfer.Amount = formatAmount(transfer.value, *transfer.Decimals)
+		price, ok := e.getPrice(ctx, entry)
+		if !ok {
+			continue
+		}
+		amountFloat, _ := amount.Float64()
+		usdValue := math.Round(amountFloat*price*100) / 100
+		transfer.USDValue = &usdValue
+		total += usdValue
+		priced = true
+	}
+
+	b, err := json.Marshal(transfers)
+	if err != nil {
+		log.WithError(err).Warn("failed to encode the token transfers")
+		return
+	}
+	if alert.Tags == nil {
+		alert.Tags = make(map[string]string)
+	}
+	alert.Tags[TagTransfers] = string(b)
+	if priced {
+		alert.Tags[TagTransfersUSD] = strconv.FormatFloat(total, 'f', 2, 64)
+	}
+}
+
+// getEntry returns the cached entry of the token after loading the metadata if it is not loaded yet.
+func (e *Enricher) getEntry(ctx context.Context, token string) *tokenEntry {
+	e.mu.Lock()
+	elem, ok := e.entries[token]
+	if ok {
+		e.order.MoveToBack(elem)
+	} else {
+		elem = e.order.PushBack(&tokenEntry{address: token})
+		e.entries[token] = elem
+		for e.order.Len() > cacheSize {
+			oldest := e.order.Front()
+			e.order.Remove(oldest)
+			delete(e.entries, oldest.Value.(*tokenEntry).address)
+		}
+	}
+	entry := elem.Value.(*tokenEntry)
+	shouldFetch := entry.metadataFetched.IsZero() ||
+		(entry.metadataErr != nil && time.Since(entry.metadataFetched) > metadataRetryInterval)
+	e.mu.Unlock()
+
+	if !shouldFetch {
+		return entry
+	}
+	symbol, decimals, err := e.fetchMetadata(ctx, token)
+	if err != nil {
+		log.WithError(err).WithField("token", token).Debug("failed to get the token metadata")
+	}
+	e.mu.Lock()
+	entry.symbol, entry.decimals, entry.metadataErr = symbol, decimals, err
+	entry.metadataFetched = time.Now()
+	e.mu.Unlock()
+	return entry
+}
+
+// fetchMetadata reads the symbol and the decimals from the token contract. The contracts which
+// do not implement them have an empty symbol and nil decimals.
+func (e *Enricher) fetchMetadata(ctx context.Context, token string) (symbol string, decimals *uint8, err error) {
+	b, err := e.call(ctx, token, selectorSymbol)
+	if err != nil {
+		return "", nil, err
+	}
+	symbol = decodeSymbol(b)
+	b, err = e.call(ctx, token, selectorDecimals)
+	if err != nil {
+		return "", nil, err
+	}
+	return symbol, decodeDecimals(b), nil
+}
+
+func (e *Enricher) call(ctx context.Context, to, data string) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := e.rpcClient.CallContext(ctx, &result, "eth_call", map[string]string{"to": to, "data": data}, "latest")
+	if err != nil {
+		// the reverts tell that the contract does not implement the method
+		if _, ok := err.(rpc.DataError); ok {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to call the token contract: %v", err)
+	}
+	return result, nil
+}
+
+// getPrice returns the cached price or requests the price when the cached one expires. The failures
+// are cached too, so that the price source is not requested for each finding.
+func (e *Enricher) getPrice(ctx context.Context, entry *tokenEntry) (float64, bool) {
+	if len(e.cfg.PriceURL) == 0 {
+		return 0, false
+	}
+	e.mu.Lock()
+	fresh := time.Since(entry.priceFetched) < time.Duration(e.cfg.PriceCacheSeconds)*time.Second
+	price := entry.price
+	e.mu.Unlock()
+	if !fresh {
+		var err error
+		price, err = e.fetchPrice(ctx, entry.address)
+		if err != nil {
+			log.WithError(err).WithField("token", entry.address).Debug("failed to get the token price")
+		}
+		e.mu.Lock()
+		entry.price, entry.priceFetched = price, time.Now()
+		e.mu.Unlock()
+	}
+	if price == nil {
+		return 0, false
+	}
+	return *price, true
+}
+
+func (e *Enricher) fetchPrice(ctx context.Context, token string) (*float64, error) {
+	url := strings.NewReplacer("{chainId}", strconv.Itoa(e.chainID), "{address}", token).Replace(e.cfg.PriceURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxPriceSize))
+	if err != nil {
+		return nil, err
+	}
+	return parsePrice(b, token)
+}
+
+// parsePrice reads the USD price from the top level or from under the token address.
+func parsePrice(b []byte, token string) (*float64, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode the price: %v", err)
+	}
+	for key, value := range fields {
+		if strings.EqualFold(key, token) {
+			return parsePrice(value, token)
+		}
+	}
+	value, ok := fields["usd"]
+	if !ok {
+		return nil, nil
+	}
+	var price float64
+	if err := json.Unmarshal(value, &price); err != nil {
+		return nil, fmt.Errorf("failed to decode the price: %v", err)
+	}
+	return &price, nil
+}
+
+// decodeSymbol decodes the symbol either as an ABI string or as bytes32, which some old tokens
+// return. The symbols which are not printable are ignored.
+func decodeSymbol(b []byte) string {
+	var symbol string
+	switch {
+	case len(b) >= 64:
+		offset := new(big.Int).SetBytes(b[:32])
+		if !offset.IsUint64() || offset.Uint64() > uint64(len(b)-32) {
+			return ""
+		}
+		start := offset.Uint64() + 32
+		length := new(big.Int).SetBytes(b[offset.Uint64():start])
+		if !length.IsUint64() || length.Uint64() > uint64(len(b))-start {
+			return ""
+		}
+		symbol = string(b[start : start+length.Uint64()])
+	case len(b) == 32:
+		symbol = strings.TrimRight(string(b), "\x00")
+	}
+	if len(symbol) > maxSymbolLen {
+		return ""
+	}
+	for _, c := range symbol {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return symbol
+}
+
+func decodeDecimals(b []byte) *uint8 {
+	if len(b) < 32 {
+		return nil
+	}
+	value := new(big.Int).SetBytes(b[:32])
+	if !value.IsUint64() || value.Uint64() > math.MaxUint8 {
+		return nil
+	}
+	decimals := uint8(value.Uint64())
+	return &decimals
+}
+
+func pow10(decimals uint8) *big.Float {
+	return new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+// formatAmount formats the raw amount as a decimal number with the decimals of the token.
+func formatAmount(value *big.Int, decimals uint8) string {
+	digits := value.String()
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(decimals)
+	fraction := strings.TrimRight(digits[point:], "0")
+	if len(fraction) == 0 {
+		return digits[:point]
+	}
+	return digits[:point] + "." + fraction
+}
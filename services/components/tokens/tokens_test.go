@@ -0,0 +1,215 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken    = "0x00000000000000000000000000000000000000aa"
+	testOldToken = "0x00000000000000000000000000000000000000bb"
+	testSender   = "0x0000000000000000000000000000000000000001"
+	testReceiver = "0x0000000000000000000000000000000000000002"
+
+	// ABI encoded "USDC"
+	testSymbolString = "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"5553444300000000000000000000000000000000000000000000000000000000"
+	// "MKR" as bytes32
+	testSymbolBytes32 = "0x4d4b520000000000000000000000000000000000000000000000000000000000"
+	testDecimals6     = "0x0000000000000000000000000000000000000000000000000000000000000006"
+	testDecimals18    = "0x0000000000000000000000000000000000000000000000000000000000000012"
+)
+
+type testRPCClient struct {
+	results map[string]string
+	calls   int
+	err     error
+}
+
+func (c *testRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	msg := args[0].(map[string]string)
+	return json.Unmarshal([]byte(fmt.Sprintf(`"%s"`, c.results[msg["to"]+msg["data"]])), result)
+}
+
+func testTopic(address string) string {
+	return "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+}
+
+func testTransferLog(token, from, to, value string) *protocol.TransactionEvent_Log {
+	return &protocol.TransactionEvent_Log{
+		Address: token,
+		Topics:  []string{TransferTopic, testTopic(from), testTopic(to)},
+		Data:    value,
+	}
+}
+
+func testEvent() *protocol.TransactionEvent {
+	return &protocol.TransactionEvent{
+		Logs: []*protocol.TransactionEvent_Log{
+			// 1.5 USDC
+			testTransferLog(testToken, testSender, testReceiver, "0x000000000000000000000000000000000000000000000000000000000016e360"),
+			// 2 MKR
+			testTransferLog(testOldToken, testReceiver, testSender, "0x0000000000000000000000000000000000000000000000001bc16d674ec80000"),
+			// an ERC-721 transfer
+			{
+				Address: testToken,
+				Topics:  []string{TransferTopic, testTopic(testSender), testTopic(testReceiver), "0x01"},
+				Data:    "0x",
+			},
+		},
+	}
+}
+
+func testRPC() *testRPCClient {
+	return &testRPCClient{
+		results: map[string]string{
+			testToken + selectorSymbol:      testSymbolString,
+			testToken + selectorDecimals:    testDecimals6,
+			testOldToken + selectorSymbol:   testSymbolBytes32,
+			testOldToken + selectorDecimals: testDecimals18,
+		},
+	}
+}
+
+func TestTransfers(t *testing.T) {
+	r := require.New(t)
+
+	event := testEvent()
+	transfers := Transfers(event, nil)
+	r.Len(transfers, 2)
+	r.Equal(testToken, transfers[0].Token)
+	r.Equal(testSender, transfers[0].From)
+	r.Equal(testReceiver, transfers[0].To)
+	r.Equal("1500000", transfers[0].Value)
+
+	// only the transfers which involve the addresses
+	transfers = Transfers(event, []string{strings.ToUpper(testOldToken)})
+	r.Len(transfers, 1)
+	r.Equal(testOldToken, transfers[0].Token)
+	r.Len(Transfers(event, []string{"0x0000000000000000000000000000000000000003"}), 0)
+
+	// the receipt logs are used if there are no logs
+	receiptEvent := &protocol.TransactionEvent{Receipt: &protocol.TransactionEvent_EthReceipt{Logs: event.Logs}}
+	r.Len(Transfers(receiptEvent, []string{testSender}), 2)
+}
+
+func TestEnrich(t *testing.T) {
+	r := require.New(t)
+
+	var priceRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		priceRequests++
+		r.Equal("/prices/1", req.URL.Path)
+		switch address := req.URL.Query().Get("address"); address {
+		case testToken:
+			fmt.Fprintf(w, `{"%s": {"usd": 1.001}}`, strings.ToUpper(testToken))
+		case testOldToken:
+			w.Write([]byte(`{"usd": 700}`))
+		}
+	}))
+	defer server.Close()
+
+	rpcClient := testRPC()
+	enricher := newEnricher(rpcClient, server.Client(), 1, config.TokenEnrichmentConfig{
+		Enable:            true,
+		PriceURL:          server.URL + "/prices/{chainId}?address={address}",
+		PriceCacheSeconds: 300,
+		MaxTransfers:      10,
+	})
+
+	alert := &protocol.Alert{Finding: &protocol.Finding{}}
+	enricher.Enrich(context.Background(), testEvent(), alert)
+
+	var transfers []*Transfer
+	r.NoError(json.Unmarshal([]byte(alert.Tags[TagTransfers]), &transfers))
+	r.Len(transfers, 2)
+	r.Equal("USDC", transfers[0].Symbol)
+	r.Equal(uint8(6), *transfers[0].Decimals)
+	r.Equal("1.5", transfers[0].Amount)
+	r.Equal(1.5, *transfers[0].USDValue)
+	r.Equal("MKR", transfers[1].Symbol)
+	r.Equal("2", transfers[1].Amount)
+	r.Equal(float64(1400), *transfers[1].USDValue)
+	r.Equal("1401.50", alert.Tags[TagTransfersUSD])
+
+	// the metadata and the prices should be cached
+	alert = &protocol.Alert{Finding: &protocol.Finding{}}
+	enricher.Enrich(context.Background(), testEvent(), alert)
+	r.Equal(4, rpcClient.calls)
+	r.Equal(2, priceRequests)
+	r.Equal("1401.50", alert.Tags[TagTransfersUSD])
+}
+
+func TestEnrich_NoPrices(t *testing.T) {
+	r := require.New(t)
+
+	enricher := newEnricher(testRPC(), http.DefaultClient, 1, config.TokenEnrichmentConfig{
+		Enable:            true,
+		PriceCacheSeconds: 300,
+		MaxTransfers:      1,
+	})
+	alert := &protocol.Alert{Finding: &protocol.Finding{Addresses: []string{testSender}}}
+	enricher.Enrich(context.Background(), testEvent(), alert)
+
+	var transfers []*Transfer
+	r.NoError(json.Unmarshal([]byte(alert.Tags[TagTransfers]), &transfers))
+	r.Len(transfers, 1)
+	r.Equal("1.5", transfers[0].Amount)
+	r.Nil(transfers[0].USDValue)
+	r.NotContains(alert.Tags, TagTransfersUSD)
+}
+
+func TestEnrich_MetadataFailure(t *testing.T) {
+	r := require.New(t)
+
+	rpcClient := &testRPCClient{err: errors.New("failed")}
+	enricher := newEnricher(rpcClient, http.DefaultClient, 1, config.TokenEnrichmentConfig{
+		Enable:            true,
+		PriceCacheSeconds: 300,
+		MaxTransfers:      10,
+	})
+	alert := &protocol.Alert{Finding: &protocol.Finding{}}
+	enricher.Enrich(context.Background(), testEvent(), alert)
+
+	// the raw values are annotated without the metadata
+	var transfers []*Transfer
+	r.NoError(json.Unmarshal([]byte(alert.Tags[TagTransfers]), &transfers))
+	r.Len(transfers, 2)
+	r.Empty(transfers[0].Symbol)
+	r.Nil(transfers[0].Decimals)
+	r.Equal("1500000", transfers[0].Value)
+
+	// the failures should not be retried right away
+	enricher.Enrich(context.Background(), testEvent(), &protocol.Alert{Finding: &protocol.Finding{}})
+	r.Equal(2, rpcClient.calls)
+}
+
+func TestFormatAmount(t *testing.T) {
+	r := require.New(t)
+
+	value := func(s string) *Transfer {
+		transfers := Transfers(&protocol.TransactionEvent{Logs: []*protocol.TransactionEvent_Log{
+			testTransferLog(testToken, testSender, testReceiver, s),
+		}}, nil)
+		return transfers[0]
+	}
+	r.Equal("0.000001", formatAmount(value("0x0000000000000000000000000000000000000000000000000000000000000001").value, 6))
+	r.Equal("1000", formatAmount(value("0x00000000000000000000000000000000000000000000000000000000000003e8").value, 0))
+	r.Equal("0", formatAmount(value("0x0000000000000000000000000000000000000000000000000000000000000000").value, 18))
+}
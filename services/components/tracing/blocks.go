@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const blockSpansSize = 1000
+
+// blockSpans keeps the span contexts of the recently fetched blocks. The pipeline stages receive
+// the blocks and the transactions through channels, so they continue the block traces by
+// looking up the span contexts with the block hashes.
+type blockSpans struct {
+	spans map[string]trace.SpanContext
+	order []string
+	mu    sync.RWMutex
+}
+
+var recentBlocks = &blockSpans{spans: make(map[string]trace.SpanContext)}
+
+func (bs *blockSpans) put(blockHash string, spanCtx trace.SpanContext) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	blockHash = strings.ToLower(blockHash)
+	if _, ok := bs.spans[blockHash]; !ok {
+		bs.order = append(bs.order, blockHash)
+	}
+	bs.spans[blockHash] = spanCtx
+	if len(bs.order) > blockSpansSize {
+		delete(bs.spans, bs.order[0])
+		bs.order = bs.order[1:]
+	}
+}
+
+func (bs *blockSpans) get(blockHash string) (trace.SpanContext, bool) {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	spanCtx, ok := bs.spans[strings.ToLower(blockHash)]
+	return spanCtx, ok
+}
+
+// BlockContext returns a context which continues the trace of the block, if the block fetch was traced.
+func BlockContext(ctx context.Context, blockHash string) context.Context {
+	spanCtx, ok := recentBlocks.get(blockHash)
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, spanCtx)
+}
+
+// ethClient starts a new trace for each block it fetches.
+type ethClient struct {
+	ethereum.Client
+	chainID int
+}
+
+// NewEthClient wraps the client of a block feed so that the blocks are traced from the fetch.
+func NewEthClient(client ethereum.Client, chainID int) ethereum.Client {
+	return &ethClient{Client: client, chainID: chainID}
+}
+
+// BlockByNumber fetches the block in a new trace.
+func (client *ethClient) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	ctx, span := Tracer().Start(ctx, SpanBlockFetch, trace.WithNewRoot(), trace.WithAttributes(
+		attribute.Int("chain.id", client.chainID),
+	))
+	defer span.End()
+
+	block, err := client.Client.BlockByNumber(ctx, number)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.String("block.number", block.Number),
+		attribute.String("block.hash", block.Hash),
+		attribute.Int("block.txs", len(block.Transactions)),
+	)
+	if span.SpanContext().IsSampled() {
+		recentBlocks.put(block.Hash, span.SpanContext())
+	}
+	return block, nil
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the node spans.
+const TracerName = "github.com/forta-network/forta-node"
+
+// Span names
+const (
+	SpanBlockFetch   = "block.fetch"
+	SpanBlockConvert = "block.convert"
+	SpanTxConvert    = "tx.convert"
+	SpanBotEvaluate  = "bot.evaluate"
+	SpanAlertPublish = "alert.publish"
+)
+
+const shutdownTimeout = time.Second * 10
+
+// Tracer returns the tracer of the node spans. The spans are not recorded unless
+// a provider is initialized.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Provider exports the spans until it is stopped.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider creates the OTLP exporter and registers the provider globally, together with the
+// W3C trace context propagator which carries the traces to the bots.
+func NewProvider(ctx context.Context, cfg config.TracingConfig, serviceName string) (*Provider, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace exporter: %v", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace resource: %v", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return &Provider{provider: provider}, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (*otlptrace.Exporter, error) {
+	switch cfg.Exporter {
+	case config.TracingExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.Endpoint),
+			otlptracehttp.WithHeaders(cfg.Headers),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithHeaders(cfg.Headers),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+}
+
+// Start implements services.Service.
+func (p *Provider) Start() error {
+	return nil
+}
+
+// Stop exports the remaining spans.
+func (p *Provider) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return p.provider.Shutdown(ctx)
+}
+
+// Name implements services.Service.
+func (p *Provider) Name() string {
+	return "tracing"
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+type testEthClient struct {
+	ethereum.Client
+}
+
+func (client *testEthClient) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	return &domain.Block{Number: "0x1", Hash: "0xAbCd"}, nil
+}
+
+func TestBlockTrace(t *testing.T) {
+	r := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	_, err := NewEthClient(&testEthClient{}, 1).BlockByNumber(context.Background(), big.NewInt(1))
+	r.NoError(err)
+
+	ctx, span := Tracer().Start(BlockContext(context.Background(), "0xabcd"), SpanBotEvaluate)
+	span.End()
+
+	spans := recorder.Ended()
+	r.Len(spans, 2)
+	r.Equal(SpanBlockFetch, spans[0].Name())
+	r.Equal(SpanBotEvaluate, spans[1].Name())
+	r.Equal(spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	r.Equal(spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+
+	md, ok := metadata.FromOutgoingContext(InjectGRPC(ctx))
+	r.True(ok)
+	r.Len(md.Get("traceparent"), 1)
+
+	extracted := ExtractGRPC(metadata.NewIncomingContext(context.Background(), md))
+	r.Equal(span.SpanContext().SpanID(), trace.SpanContextFromContext(extracted).SpanID())
+}
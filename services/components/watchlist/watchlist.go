@@ -0,0 +1,294 @@
+package watchlist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const maxListSize = 64 << 20
+
+// Tags are the labels of the watched addresses of a transaction, by the lowercase addresses.
+type Tags map[string][]string
+
+// HasLabel tells if any of the addresses has the label.
+func (tags Tags) HasLabel(label string) bool {
+	for _, labels := range tags {
+		for _, l := range labels {
+			if l == label {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Watchlists knows the labels of the addresses from the configured lists and refreshes the lists
+// periodically. The last loaded addresses of a list are kept if the list fails to refresh.
+type Watchlists struct {
+	ctx context.Context
+	cfg config.WatchlistsConfig
+
+	// the addresses of each list
+	lists  []map[string][]string
+	labels map[string][]string
+	mu     sync.RWMutex
+
+	lastRefresh    health.TimeTracker
+	lastRefreshErr health.ErrorTracker
+}
+
+// NewWatchlists loads the watchlists and starts refreshing them. It returns nil if there are
+// no watchlists.
+func NewWatchlists(ctx context.Context, cfg config.WatchlistsConfig) *Watchlists {
+	if len(cfg.Lists) == 0 {
+		return nil
+	}
+	wl := &Watchlists{
+		ctx:   ctx,
+		cfg:   cfg,
+		lists: make([]map[string][]string, len(cfg.Lists)),
+	}
+	wl.refresh()
+	go wl.refreshLoop()
+	return wl
+}
+
+func (wl *Watchlists) refreshLoop() {
+	ticker := time.NewTicker(time.Duration(wl.cfg.RefreshIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wl.ctx.Done():
+			return
+		case <-ticker.C:
+			wl.refresh()
+		}
+	}
+}
+
+func (wl *Watchlists) refresh() {
+	var lastErr error
+	for i, listCfg := range wl.cfg.Lists {
+		addresses, err := loadList(wl.ctx, listCfg)
+		if err != nil {
+			log.WithError(err).WithField("source", listCfg.Source).Warn("failed to load the watchlist")
+			lastErr = err
+			continue
+		}
+		wl.lists[i] = addresses
+	}
+
+	labels := make(map[string][]string)
+	for _, addresses := range wl.lists {
+		for address, addressLabels := range addresses {
+			labels[address] = mergeLabels(labels[address], addressLabels)
+		}
+	}
+	wl.mu.Lock()
+	wl.labels = labels
+	wl.mu.Unlock()
+
+	wl.lastRefreshErr.Set(lastErr)
+	wl.lastRefresh.Set()
+}
+
+// Labels returns the labels of the address.
+func (wl *Watchlists) Labels(address string) []string {
+	wl.mu.RLock()
+	defer wl.mu.RUnlock()
+	return wl.labels[strings.ToLower(address)]
+}
+
+// Tag attaches the labels of the watched addresses of the transaction to the message. It does
+// nothing if the transaction does not involve any watched addresses.
+func (wl *Watchlists) Tag(msg *protocol.TransactionEvent) {
+	tags := make(Tags)
+	wl.mu.RLock()
+	for address := range msg.GetAddresses() {
+		if labels, ok := wl.labels[strings.ToLower(address)]; ok {
+			tags[strings.ToLower(address)] = labels
+		}
+	}
+	wl.mu.RUnlock()
+	if len(tags) == 0 {
+		return
+	}
+	if err := Attach(msg, tags); err != nil {
+		log.WithError(err).WithField("tx", msg.GetTransaction().GetHash()).Warn("failed to attach the watchlist tags")
+	}
+}
+
+// Name implements the health.Reporter interface.
+func (wl *Watchlists) Name() string {
+	return "watchlists"
+}
+
+// Health implements the health.Reporter interface.
+func (wl *Watchlists) Health() health.Reports {
+	wl.mu.RLock()
+	addresses := len(wl.labels)
+	wl.mu.RUnlock()
+	return health.Reports{
+		&health.Report{
+			Name:    "watchlist.addresses",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(addresses),
+		},
+		wl.lastRefresh.GetReport("watchlist.refresh.time"),
+		wl.lastRefreshErr.GetReport("watchlist.refresh.error"),
+	}
+}
+
+// loadList reads the list from the file or the URL.
+func loadList(ctx context.Context, listCfg config.WatchlistConfig) (map[string][]string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if strings.HasPrefix(listCfg.Source, "http://") || strings.HasPrefix(listCfg.Source, "https://") {
+		b, err = get(ctx, listCfg.Source)
+	} else {
+		b, err = os.ReadFile(listCfg.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseList(b, listCfg.Label)
+}
+
+func get(ctx context.Context, reqURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxListSize))
+}
+
+// ParseList parses a JSON object of the addresses and their labels, or a list which contains an
+// address and its labels on each line, separated with commas. The label of the list is added to
+// all addresses. The empty lines, the lines which start with "#" and the lines which do not start
+// with an address, like the CSV headers, are skipped.
+func ParseList(b []byte, listLabel string) (map[string][]string, error) {
+	addresses := make(map[string][]string)
+	add := func(address string, labels []string) {
+		if !common.IsHexAddress(address) {
+			return
+		}
+		if len(listLabel) > 0 {
+			labels = append(labels, listLabel)
+		}
+		address = strings.ToLower(address)
+		addresses[address] = mergeLabels(addresses[address], labels)
+	}
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		var list map[string][]string
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode the watchlist: %v", err)
+		}
+		for address, labels := range list {
+			add(address, labels)
+		}
+		return addresses, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		add(strings.TrimSpace(fields[0]), fields[1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the watchlist: %v", err)
+	}
+	return addresses, nil
+}
+
+// mergeLabels returns the sorted unique labels.
+func mergeLabels(labels, more []string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, label := range append(append([]string{}, labels...), more...) {
+		label = strings.TrimSpace(label)
+		if len(label) == 0 || seen[label] {
+			continue
+		}
+		seen[label] = true
+		merged = append(merged, label)
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// Attach adds the tags to the message as an unknown field.
+func Attach(msg *protocol.TransactionEvent, tags Tags) error {
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode the watchlist tags: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.WatchlistTagsFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// FromMessage reads the tags from the message. It returns nil if the message does not have them.
+func FromMessage(msg *protocol.TransactionEvent) (Tags, error) {
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == agentgrpc.WatchlistTagsFieldNumber && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			var tags Tags
+			if err := json.Unmarshal(b, &tags); err != nil {
+				return nil, fmt.Errorf("failed to decode the watchlist tags: %v", err)
+			}
+			return tags, nil
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, nil
+}
@@ -0,0 +1,98 @@
+package watchlist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAddress1 = "0x00000000000000000000000000000000000000aa"
+	testAddress2 = "0x00000000000000000000000000000000000000BB"
+	testAddress3 = "0x00000000000000000000000000000000000000cc"
+)
+
+func TestParseList(t *testing.T) {
+	r := require.New(t)
+
+	addresses, err := ParseList([]byte(`address,label
+# the exploiters
+`+testAddress1+`, exploiter , phishing
+`+testAddress2+`
+
+invalid,exploiter
+`+testAddress1+`,exploiter
+`), "bad")
+	r.NoError(err)
+	r.Equal(map[string][]string{
+		testAddress1: {"bad", "exploiter", "phishing"},
+		"0x00000000000000000000000000000000000000bb": {"bad"},
+	}, addresses)
+
+	addresses, err = ParseList([]byte(`{"`+testAddress2+`": ["team multisig"]}`), "")
+	r.NoError(err)
+	r.Equal(map[string][]string{"0x00000000000000000000000000000000000000bb": {"team multisig"}}, addresses)
+
+	_, err = ParseList([]byte(`{"invalid`), "")
+	r.Error(err)
+}
+
+func TestWatchlists(t *testing.T) {
+	r := require.New(t)
+
+	listPath := path.Join(t.TempDir(), "exploiters.csv")
+	r.NoError(os.WriteFile(listPath, []byte(testAddress1+",phishing\n"), 0644))
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(testAddress2 + "\n" + testAddress1 + "\n"))
+	}))
+	defer server.Close()
+	listURL := server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Nil(NewWatchlists(ctx, config.WatchlistsConfig{}))
+	wl := NewWatchlists(ctx, config.WatchlistsConfig{
+		Lists: []config.WatchlistConfig{
+			{Source: listPath, Label: "exploiter"},
+			{Source: listURL, Label: "sanctioned"},
+		},
+		RefreshIntervalSeconds: 300,
+	})
+	r.Equal([]string{"exploiter", "phishing", "sanctioned"}, wl.Labels(testAddress1))
+	r.Equal([]string{"sanctioned"}, wl.Labels(testAddress2))
+	r.Empty(wl.Labels(testAddress3))
+
+	msg := &protocol.TransactionEvent{Addresses: map[string]bool{testAddress2: true, testAddress3: true}}
+	wl.Tag(msg)
+	tags, err := FromMessage(msg)
+	r.NoError(err)
+	r.Equal(Tags{"0x00000000000000000000000000000000000000bb": {"sanctioned"}}, tags)
+	r.True(tags.HasLabel("sanctioned"))
+	r.False(tags.HasLabel("exploiter"))
+
+	untagged := &protocol.TransactionEvent{Addresses: map[string]bool{testAddress3: true}}
+	wl.Tag(untagged)
+	tags, err = FromMessage(untagged)
+	r.NoError(err)
+	r.Nil(tags)
+
+	// the last addresses of a list are kept if the list fails to refresh
+	failing.Store(true)
+	r.NoError(os.WriteFile(listPath, []byte(testAddress3+"\n"), 0644))
+	wl.refresh()
+	r.Equal([]string{"sanctioned"}, wl.Labels(testAddress1))
+	r.Equal([]string{"exploiter"}, wl.Labels(testAddress3))
+}
@@ -0,0 +1,225 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespace = "forta"
+
+// Evaluation request types
+const (
+	requestTypeTx       = "tx"
+	requestTypeBlock    = "block"
+	requestTypeCombiner = "combiner"
+)
+
+// Exporter collects the node and the bot metrics and serves them in Prometheus format.
+type Exporter struct {
+	ctx    context.Context
+	cfg    ExporterConfig
+	server *http.Server
+
+	registry       *prometheus.Registry
+	botRequests    *prometheus.CounterVec
+	botErrors      *prometheus.CounterVec
+	botDrops       *prometheus.CounterVec
+	botLatency     *prometheus.HistogramVec
+	findings       *prometheus.CounterVec
+	circuitChanges *prometheus.CounterVec
+
+	lastMetrics health.TimeTracker
+}
+
+// ExporterConfig contains the exporter configuration and the metric sources.
+type ExporterConfig struct {
+	Port      string
+	MsgClient clients.MessageClient
+
+	// Processed returns the amount of events processed per event type.
+	Processed map[string]func() uint64
+	// QueueDepths returns the amount of items waiting in the channels per channel name.
+	QueueDepths map[string]func() int
+	// BlockLags returns the amount of blocks which the node is behind the chain head per chain id.
+	BlockLags map[string]func() uint64
+}
+
+// NewExporter creates a new exporter.
+func NewExporter(ctx context.Context, cfg ExporterConfig) (*Exporter, error) {
+	if len(cfg.Port) == 0 {
+		return nil, fmt.Errorf("exporter port is required")
+	}
+	exp := &Exporter{
+		ctx:      ctx,
+		cfg:      cfg,
+		registry: prometheus.NewRegistry(),
+		botRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bot_requests_total",
+			Help:      "Evaluation requests sent to the bots",
+		}, []string{"bot", "type"}),
+		botErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bot_errors_total",
+			Help:      "Evaluation requests which resulted with an error response",
+		}, []string{"bot", "type"}),
+		botDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bot_drops_total",
+			Help:      "Evaluation requests dropped before reaching the bots",
+		}, []string{"bot", "type"}),
+		botLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "bot_evaluation_latency_seconds",
+			Help:      "Evaluation latency of the bots",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"bot", "type"}),
+		findings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "findings_total",
+			Help:      "Findings emitted by the bots",
+		}, []string{"bot"}),
+		circuitChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bot_circuit_changes_total",
+			Help:      "Circuit breaker state changes of the bots",
+		}, []string{"bot", "state"}),
+	}
+
+	exp.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		exp.botRequests, exp.botErrors, exp.botDrops, exp.botLatency,
+		exp.findings, exp.circuitChanges,
+	)
+	for eventType, count := range cfg.Processed {
+		exp.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "events_processed_total",
+			Help:        "Events processed by the node",
+			ConstLabels: prometheus.Labels{"type": eventType},
+		}, countFunc(count)))
+	}
+	for queue, depth := range cfg.QueueDepths {
+		exp.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "queue_depth",
+			Help:        "Items waiting in the node queues",
+			ConstLabels: prometheus.Labels{"queue": queue},
+		}, depthFunc(depth)))
+	}
+	for chainID, lag := range cfg.BlockLags {
+		exp.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "block_lag",
+			Help:        "Blocks which the node is behind the chain head",
+			ConstLabels: prometheus.Labels{"chain": chainID},
+		}, countFunc(lag)))
+	}
+
+	return exp, nil
+}
+
+func countFunc(count func() uint64) func() float64 {
+	return func() float64 {
+		return float64(count())
+	}
+}
+
+func depthFunc(depth func() int) func() float64 {
+	return func() float64 {
+		return float64(depth())
+	}
+}
+
+// Start subscribes to the bot metrics and starts the metrics server.
+func (exp *Exporter) Start() error {
+	exp.cfg.MsgClient.Subscribe(messaging.SubjectMetricAgent, messaging.AgentMetricHandler(exp.handleMetrics))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(exp.registry, promhttp.HandlerOpts{}))
+	exp.server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", exp.cfg.Port),
+		Handler: mux,
+	}
+	utils.GoListenAndServe(exp.server)
+	return nil
+}
+
+func (exp *Exporter) handleMetrics(list *protocol.AgentMetricList) error {
+	for _, metric := range list.Metrics {
+		exp.observe(metric)
+	}
+	exp.lastMetrics.Set()
+	return nil
+}
+
+// observe maps the bot metric to the matching Prometheus metric. The metrics which are
+// not useful in Prometheus format are ignored.
+func (exp *Exporter) observe(metric *protocol.AgentMetric) {
+	botID := metric.AgentId
+	switch metric.Name {
+	case metrics.MetricFinding:
+		exp.findings.WithLabelValues(botID).Add(metric.Value)
+	case metrics.MetricCircuitOpen:
+		exp.circuitChanges.WithLabelValues(botID, "open").Add(metric.Value)
+	case metrics.MetricCircuitClosed:
+		exp.circuitChanges.WithLabelValues(botID, "closed").Add(metric.Value)
+	case metrics.MetricTxRequest, metrics.MetricBlockRequest, metrics.MetricCombinerRequest:
+		exp.botRequests.WithLabelValues(botID, requestType(metric.Name)).Add(metric.Value)
+	case metrics.MetricTxError, metrics.MetricBlockError, metrics.MetricCombinerError:
+		exp.botErrors.WithLabelValues(botID, requestType(metric.Name)).Add(metric.Value)
+	case metrics.MetricTxDrop, metrics.MetricBlockDrop, metrics.MetricCombinerDrop:
+		exp.botDrops.WithLabelValues(botID, requestType(metric.Name)).Add(metric.Value)
+	case metrics.MetricTxLatency, metrics.MetricBlockLatency, metrics.MetricCombinerLatency:
+		// latency values are in milliseconds
+		exp.botLatency.WithLabelValues(botID, requestType(metric.Name)).Observe(metric.Value / 1000)
+	default:
+		log.WithField("metric", metric.Name).Trace("ignoring metric in exporter")
+	}
+}
+
+// requestType extracts the request type from the metric name prefix.
+func requestType(metricName string) string {
+	switch {
+	case strings.HasPrefix(metricName, "tx."):
+		return requestTypeTx
+	case strings.HasPrefix(metricName, "block."):
+		return requestTypeBlock
+	default:
+		return requestTypeCombiner
+	}
+}
+
+// Stop stops the metrics server.
+func (exp *Exporter) Stop() error {
+	if exp.server != nil {
+		return exp.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (exp *Exporter) Name() string {
+	return "metrics-exporter"
+}
+
+// Health implements the health.Reporter interface.
+func (exp *Exporter) Health() health.Reports {
+	return health.Reports{
+		exp.lastMetrics.GetReport("event.metrics.time"),
+	}
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotID = "0x1"
+
+func findMetric(t *testing.T, exp *Exporter, name string) *dto.Metric {
+	families, err := exp.registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == name {
+			require.NotEmpty(t, family.Metric)
+			return family.Metric[0]
+		}
+	}
+	require.FailNow(t, "metric not found", name)
+	return nil
+}
+
+func TestExporter_handleMetrics(t *testing.T) {
+	r := require.New(t)
+
+	exp, err := NewExporter(context.Background(), ExporterConfig{
+		Port: "9107",
+		Processed: map[string]func() uint64{
+			"tx": func() uint64 { return 5 },
+		},
+		QueueDepths: map[string]func() int{
+			"tx": func() int { return 3 },
+		},
+		BlockLags: map[string]func() uint64{
+			"1": func() uint64 { return 7 },
+		},
+	})
+	r.NoError(err)
+
+	r.NoError(exp.handleMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{
+			{AgentId: testBotID, Name: metrics.MetricTxRequest, Value: 1},
+			{AgentId: testBotID, Name: metrics.MetricTxError, Value: 1},
+			{AgentId: testBotID, Name: metrics.MetricTxLatency, Value: 250},
+			{AgentId: testBotID, Name: metrics.MetricFinding, Value: 2},
+			{AgentId: testBotID, Name: metrics.MetricStatusRunning, Value: 1},
+		},
+	}))
+
+	r.Equal(float64(1), findMetric(t, exp, "forta_bot_requests_total").GetCounter().GetValue())
+	r.Equal(float64(1), findMetric(t, exp, "forta_bot_errors_total").GetCounter().GetValue())
+	r.Equal(float64(2), findMetric(t, exp, "forta_findings_total").GetCounter().GetValue())
+
+	latency := findMetric(t, exp, "forta_bot_evaluation_latency_seconds").GetHistogram()
+	r.Equal(uint64(1), latency.GetSampleCount())
+	r.Equal(0.25, latency.GetSampleSum())
+
+	r.Equal(float64(5), findMetric(t, exp, "forta_events_processed_total").GetCounter().GetValue())
+	r.Equal(float64(3), findMetric(t, exp, "forta_queue_depth").GetGauge().GetValue())
+	r.Equal(float64(7), findMetric(t, exp, "forta_block_lag").GetGauge().GetValue())
+}
+
+func TestRequestType(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(requestTypeTx, requestType(metrics.MetricTxLatency))
+	r.Equal(requestTypeBlock, requestType(metrics.MetricBlockLatency))
+	r.Equal(requestTypeCombiner, requestType(metrics.MetricCombinerLatency))
+}
@@ -0,0 +1,59 @@
+package history
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"golang.org/x/sync/singleflight"
+)
+
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache keeps the loaded values until they expire or they are evicted as the least recently
+// used. The concurrent loads of the same key wait for the first one instead of loading together.
+type ttlCache[K comparable, V any] struct {
+	ttl     time.Duration
+	entries *lru.Cache[K, cacheEntry[V]]
+	group   singleflight.Group
+
+	hits   uint64
+	misses uint64
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration, maxEntries int) *ttlCache[K, V] {
+	return &ttlCache[K, V]{
+		ttl:     ttl,
+		entries: lru.NewCache[K, cacheEntry[V]](maxEntries),
+	}
+}
+
+// get returns the cached value of the key or loads it. The failed loads are not cached.
+func (c *ttlCache[K, V]) get(key K, load func() (V, error)) (V, error) {
+	if entry, ok := c.entries.Get(key); ok && time.Now().Before(entry.expiresAt) {
+		atomic.AddUint64(&c.hits, 1)
+		return entry.value, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+	v, err, _ := c.group.Do(fmt.Sprint(key), func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.entries.Add(key, cacheEntry[V]{value: value, expiresAt: time.Now().Add(c.ttl)})
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return v.(V), nil
+}
+
+func (c *ttlCache[K, V]) stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
@@ -0,0 +1,132 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// HistoryServiceName is the name of the gRPC service which serves the historical lookups to the bots.
+// The requests and the responses are JSON in string values, so that the bots do not need the
+// generated code of the service.
+const HistoryServiceName = "network.forta.History"
+
+// History gRPC methods
+const (
+	MethodGetAddressTransactions = "/network.forta.History/GetAddressTransactions"
+	MethodGetBotAlerts           = "/network.forta.History/GetBotAlerts"
+	MethodGetBlockTimestamp      = "/network.forta.History/GetBlockTimestamp"
+)
+
+// HistoryServer is the server side of the history service.
+type HistoryServer interface {
+	GetAddressTransactions(context.Context, *AddressTransactionsRequest) (*AddressTransactionsResponse, error)
+	GetBotAlerts(context.Context, *BotAlertsRequest) (*BotAlertsResponse, error)
+	GetBlockTimestamp(context.Context, *BlockTimestampRequest) (*BlockTimestampResponse, error)
+}
+
+var historyServiceDesc = grpc.ServiceDesc{
+	ServiceName: HistoryServiceName,
+	HandlerType: (*HistoryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("GetAddressTransactions", MethodGetAddressTransactions, HistoryServer.GetAddressTransactions),
+		unaryMethod("GetBotAlerts", MethodGetBotAlerts, HistoryServer.GetBotAlerts),
+		unaryMethod("GetBlockTimestamp", MethodGetBlockTimestamp, HistoryServer.GetBlockTimestamp),
+	},
+}
+
+// unaryMethod creates the handler of a method which decodes the request from and encodes the
+// response to JSON. The errors which are not gRPC status errors are internal errors.
+func unaryMethod[Req, Resp any](
+	name, method string, call func(HistoryServer, context.Context, *Req) (*Resp, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				var request Req
+				if err := json.Unmarshal([]byte(req.(*wrapperspb.StringValue).GetValue()), &request); err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "failed to decode the request: %v", err)
+				}
+				resp, err := call(srv.(HistoryServer), ctx, &request)
+				if err != nil {
+					if _, ok := status.FromError(err); ok {
+						return nil, err
+					}
+					return nil, status.Error(codes.Internal, err.Error())
+				}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to encode the response: %v", err)
+				}
+				return wrapperspb.String(string(b)), nil
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+		},
+	}
+}
+
+// RegisterHistoryServer registers the history service to the gRPC server.
+func RegisterHistoryServer(s *grpc.Server, srv HistoryServer) {
+	s.RegisterService(&historyServiceDesc, srv)
+}
+
+// Client makes the historical lookups through the history API.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient creates a new client with the connection.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
+
+// GetAddressTransactions returns the recent transactions to an address from the newest to the oldest.
+func (client *Client) GetAddressTransactions(ctx context.Context, req *AddressTransactionsRequest) (*AddressTransactionsResponse, error) {
+	resp := new(AddressTransactionsResponse)
+	if err := client.invoke(ctx, MethodGetAddressTransactions, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBotAlerts returns the previous alerts of the calling bot from the newest to the oldest.
+func (client *Client) GetBotAlerts(ctx context.Context, req *BotAlertsRequest) (*BotAlertsResponse, error) {
+	resp := new(BotAlertsResponse)
+	if err := client.invoke(ctx, MethodGetBotAlerts, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockTimestamp returns the timestamp of a block.
+func (client *Client) GetBlockTimestamp(ctx context.Context, req *BlockTimestampRequest) (*BlockTimestampResponse, error) {
+	resp := new(BlockTimestampResponse)
+	if err := client.invoke(ctx, MethodGetBlockTimestamp, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (client *Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	out := new(wrapperspb.StringValue)
+	if err := client.conn.Invoke(ctx, method, wrapperspb.String(string(b)), out); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(out.GetValue()), resp)
+}
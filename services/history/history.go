@@ -0,0 +1,363 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// the latest block number is cached shortly, so that the lookups of the bots in the same block
+	// share it
+	blockNumberTTL = time.Second
+	// the alerts are cached shortly because the bots query their alerts as they create new ones
+	alertsTTL       = 5 * time.Second
+	maxCachedAlerts = 1000
+	// the amount of the blocks which an address lookup reads concurrently
+	scanBatchSize = 10
+	// the loads are shared by the concurrent requests, so they do not use the request contexts
+	loadTimeout = 30 * time.Second
+)
+
+// Block is a block with its transactions.
+type Block struct {
+	Number       uint64         `json:"number"`
+	Hash         string         `json:"hash"`
+	Timestamp    uint64         `json:"timestamp"`
+	Transactions []*Transaction `json:"-"`
+}
+
+// Transaction is a transaction in a block.
+type Transaction struct {
+	Hash        string `json:"hash"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Timestamp   uint64 `json:"timestamp"`
+}
+
+// AddressTransactionsRequest asks for the recent transactions to an address.
+type AddressTransactionsRequest struct {
+	Address string `json:"address"`
+	// limited by the max transactions of the node if it is zero or greater
+	Limit int `json:"limit"`
+	// the latest block if it is zero
+	ToBlock uint64 `json:"toBlock"`
+}
+
+// AddressTransactionsResponse contains the transactions from the newest to the oldest and the block
+// range which was scanned.
+type AddressTransactionsResponse struct {
+	Transactions []*Transaction `json:"transactions"`
+	FromBlock    uint64         `json:"fromBlock"`
+	ToBlock      uint64         `json:"toBlock"`
+}
+
+// BotAlertsRequest asks for the previous alerts of the calling bot.
+type BotAlertsRequest struct {
+	// all chains if it is zero
+	ChainID uint64 `json:"chainId"`
+	// limited by the max alerts of the node if it is zero or greater
+	Limit int `json:"limit"`
+}
+
+// BotAlertsResponse contains the alerts from the newest to the oldest.
+type BotAlertsResponse struct {
+	Alerts []*store.StoredAlert `json:"alerts"`
+}
+
+// BlockTimestampRequest asks for the timestamp of a block.
+type BlockTimestampRequest struct {
+	// the latest block if it is not set
+	Number *uint64 `json:"number"`
+}
+
+// BlockTimestampResponse contains the block with the timestamp.
+type BlockTimestampResponse struct {
+	Block *Block `json:"block"`
+}
+
+type botAlertsKey struct {
+	botID   string
+	chainID uint64
+	limit   int
+}
+
+type botIDKey struct{}
+
+// API serves the historical lookups to the bots over gRPC. The results are cached for all bots, so
+// that the bots which look up the same data do not multiply the load on the JSON-RPC API.
+type API struct {
+	ctx        context.Context
+	cfg        APIConfig
+	grpcServer *grpc.Server
+
+	rateLimiter ratelimiter.RateLimiter
+	blocks      *ttlCache[uint64, *Block]
+	blockNumber *ttlCache[string, uint64]
+	alerts      *ttlCache[botAlertsKey, []*store.StoredAlert]
+
+	requests  uint64
+	throttled uint64
+}
+
+// APIConfig contains the history API configuration.
+type APIConfig struct {
+	Port             string
+	Config           config.HistoryAPIConfig
+	Blocks           BlockSource
+	BotAuthenticator clients.IPAuthenticator
+	// the previous alerts are not served if it is nil
+	Alerts AlertSource
+}
+
+// NewAPI creates a new history API.
+func NewAPI(ctx context.Context, cfg APIConfig) (*API, error) {
+	if len(cfg.Port) == 0 {
+		return nil, fmt.Errorf("history api port is required")
+	}
+	if cfg.Blocks == nil || cfg.BotAuthenticator == nil {
+		return nil, fmt.Errorf("history api block source and bot authenticator are required")
+	}
+	return &API{
+		ctx:         ctx,
+		cfg:         cfg,
+		rateLimiter: ratelimiter.NewRateLimiter(cfg.Config.Rate, cfg.Config.Burst),
+		blocks:      newTTLCache[uint64, *Block](time.Duration(cfg.Config.CacheTTLSeconds)*time.Second, cfg.Config.CacheMaxBlocks),
+		blockNumber: newTTLCache[string, uint64](blockNumberTTL, 1),
+		alerts:      newTTLCache[botAlertsKey, []*store.StoredAlert](alertsTTL, maxCachedAlerts),
+	}, nil
+}
+
+// Start starts the gRPC server.
+func (api *API) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", api.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on the history api port: %v", err)
+	}
+	api.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(api.authorize))
+	RegisterHistoryServer(api.grpcServer, api)
+	go func() {
+		if err := api.grpcServer.Serve(lis); err != nil {
+			log.WithError(err).Error("history api server stopped")
+		}
+	}()
+	return nil
+}
+
+// Stop stops the server.
+func (api *API) Stop() error {
+	if api.grpcServer != nil {
+		api.grpcServer.Stop()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *API) Name() string {
+	return "history-api"
+}
+
+// Health implements the health.Reporter interface.
+func (api *API) Health() health.Reports {
+	hits, misses := api.blocks.stats()
+	return health.Reports{
+		&health.Report{
+			Name:    "requests",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&api.requests), 10),
+		},
+		&health.Report{
+			Name:    "requests.throttled",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&api.throttled), 10),
+		},
+		&health.Report{
+			Name:    "cache.blocks.hits",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(hits, 10),
+		},
+		&health.Report{
+			Name:    "cache.blocks.misses",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(misses, 10),
+		},
+	}
+}
+
+// authorize finds the calling bot from the remote address and rate limits it.
+func (api *API) authorize(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unknown caller")
+	}
+	botConfig, err := api.cfg.BotAuthenticator.FindAgentFromRemoteAddr(p.Addr.String())
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "the caller is not a bot")
+	}
+	atomic.AddUint64(&api.requests, 1)
+	if api.rateLimiter.ExceedsLimit(botConfig.ID) {
+		atomic.AddUint64(&api.throttled, 1)
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return handler(context.WithValue(ctx, botIDKey{}, botConfig.ID), req)
+}
+
+// GetAddressTransactions scans the latest blocks for the transactions to the address.
+func (api *API) GetAddressTransactions(ctx context.Context, req *AddressTransactionsRequest) (*AddressTransactionsResponse, error) {
+	if len(req.Address) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "address is required")
+	}
+	limit := capLimit(req.Limit, api.cfg.Config.MaxTransactions)
+	toBlock := req.ToBlock
+	if toBlock == 0 {
+		latest, err := api.latestBlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		toBlock = latest
+	}
+	var fromBlock uint64
+	if maxBlocks := uint64(api.cfg.Config.MaxBlocks); toBlock >= maxBlocks {
+		fromBlock = toBlock - maxBlocks + 1
+	}
+
+	resp := &AddressTransactionsResponse{Transactions: []*Transaction{}, FromBlock: toBlock + 1, ToBlock: toBlock}
+	for batchEnd := toBlock; batchEnd >= fromBlock && len(resp.Transactions) < limit; batchEnd -= scanBatchSize {
+		batchStart := fromBlock
+		if batchEnd-fromBlock >= scanBatchSize {
+			batchStart = batchEnd - scanBatchSize + 1
+		}
+		blocks, err := api.getBlocks(batchStart, batchEnd)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(blocks) - 1; i >= 0 && len(resp.Transactions) < limit; i-- {
+			txs := blocks[i].Transactions
+			for j := len(txs) - 1; j >= 0 && len(resp.Transactions) < limit; j-- {
+				if strings.EqualFold(txs[j].To, req.Address) {
+					resp.Transactions = append(resp.Transactions, txs[j])
+				}
+			}
+			resp.FromBlock = blocks[i].Number
+		}
+		if batchStart == fromBlock {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// GetBotAlerts gets the previous alerts of the calling bot.
+func (api *API) GetBotAlerts(ctx context.Context, req *BotAlertsRequest) (*BotAlertsResponse, error) {
+	if api.cfg.Alerts == nil {
+		return nil, status.Error(codes.Unavailable, "the alert query api of the node is disabled")
+	}
+	botID, _ := ctx.Value(botIDKey{}).(string)
+	if len(botID) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "the caller is not a bot")
+	}
+	key := botAlertsKey{
+		botID:   botID,
+		chainID: req.ChainID,
+		limit:   capLimit(req.Limit, api.cfg.Config.MaxAlerts),
+	}
+	alerts, err := api.alerts.get(key, func() ([]*store.StoredAlert, error) {
+		ctx, cancel := context.WithTimeout(api.ctx, loadTimeout)
+		defer cancel()
+		return api.cfg.Alerts.BotAlerts(ctx, key.botID, key.chainID, key.limit)
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if alerts == nil {
+		alerts = []*store.StoredAlert{}
+	}
+	return &BotAlertsResponse{Alerts: alerts}, nil
+}
+
+// GetBlockTimestamp gets the block with the timestamp.
+func (api *API) GetBlockTimestamp(ctx context.Context, req *BlockTimestampRequest) (*BlockTimestampResponse, error) {
+	var number uint64
+	if req.Number != nil {
+		number = *req.Number
+	} else {
+		latest, err := api.latestBlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		number = latest
+	}
+	block, err := api.getBlock(number)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockTimestampResponse{Block: block}, nil
+}
+
+func (api *API) latestBlockNumber() (uint64, error) {
+	number, err := api.blockNumber.get("latest", func() (uint64, error) {
+		ctx, cancel := context.WithTimeout(api.ctx, loadTimeout)
+		defer cancel()
+		return api.cfg.Blocks.BlockNumber(ctx)
+	})
+	if err != nil {
+		return 0, status.Error(codes.Unavailable, err.Error())
+	}
+	return number, nil
+}
+
+func (api *API) getBlock(number uint64) (*Block, error) {
+	block, err := api.blocks.get(number, func() (*Block, error) {
+		ctx, cancel := context.WithTimeout(api.ctx, loadTimeout)
+		defer cancel()
+		return api.cfg.Blocks.BlockByNumber(ctx, number)
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return block, nil
+}
+
+// getBlocks gets the blocks in the inclusive range concurrently in the ascending order.
+func (api *API) getBlocks(start, end uint64) ([]*Block, error) {
+	blocks := make([]*Block, end-start+1)
+	var g errgroup.Group
+	for i := range blocks {
+		i := i
+		g.Go(func() (err error) {
+			blocks[i], err = api.getBlock(start + uint64(i))
+			return
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func capLimit(limit, max int) int {
+	if limit <= 0 || limit > max {
+		return max
+	}
+	return limit
+}
@@ -0,0 +1,243 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testBotID    = "0xbot"
+	testAddress  = "0xAbC0000000000000000000000000000000000001"
+	testLatest   = 50
+	testTxsCount = 2
+)
+
+// testBlocks has a transaction to the test address in every fifth block.
+type testBlocks struct {
+	reads uint64
+}
+
+func (blocks *testBlocks) BlockNumber(ctx context.Context) (uint64, error) {
+	return testLatest, nil
+}
+
+func (blocks *testBlocks) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	atomic.AddUint64(&blocks.reads, 1)
+	if number > testLatest {
+		return nil, errors.New("block not found")
+	}
+	block := &Block{Number: number, Hash: fmt.Sprintf("0x%x", number), Timestamp: 1000 + number}
+	for i := 0; i < testTxsCount; i++ {
+		to := "0x0000000000000000000000000000000000000002"
+		if number%5 == 0 && i == 0 {
+			to = testAddress
+		}
+		block.Transactions = append(block.Transactions, &Transaction{
+			Hash: fmt.Sprintf("0x%x-%d", number, i), To: to, BlockNumber: number, Timestamp: block.Timestamp,
+		})
+	}
+	return block, nil
+}
+
+type testAlerts struct {
+	botIDs []string
+}
+
+func (alerts *testAlerts) BotAlerts(ctx context.Context, botID string, chainID uint64, limit int) ([]*store.StoredAlert, error) {
+	alerts.botIDs = append(alerts.botIDs, botID)
+	return []*store.StoredAlert{{ID: "0xalert", BotID: botID}}, nil
+}
+
+func testConfig() config.HistoryAPIConfig {
+	return config.HistoryAPIConfig{
+		Rate:            100,
+		Burst:           100,
+		MaxBlocks:       30,
+		MaxTransactions: 3,
+		MaxAlerts:       10,
+		CacheTTLSeconds: 60,
+		CacheMaxBlocks:  100,
+	}
+}
+
+func newTestAPI(t *testing.T, cfg config.HistoryAPIConfig, alerts AlertSource) (*API, *testBlocks) {
+	blocks := &testBlocks{}
+	api, err := NewAPI(context.Background(), APIConfig{
+		Port:             "0",
+		Config:           cfg,
+		Blocks:           blocks,
+		BotAuthenticator: mock_clients.NewMockIPAuthenticator(gomock.NewController(t)),
+		Alerts:           alerts,
+	})
+	require.NoError(t, err)
+	return api, blocks
+}
+
+func TestGetAddressTransactions(t *testing.T) {
+	r := require.New(t)
+
+	api, blocks := newTestAPI(t, testConfig(), nil)
+
+	resp, err := api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{Address: testAddress})
+	r.NoError(err)
+	r.Len(resp.Transactions, 3)
+	r.Equal(uint64(50), resp.Transactions[0].BlockNumber)
+	r.Equal(uint64(45), resp.Transactions[1].BlockNumber)
+	r.Equal(uint64(40), resp.Transactions[2].BlockNumber)
+	r.Equal(uint64(50), resp.ToBlock)
+	r.Equal(uint64(40), resp.FromBlock)
+	reads := atomic.LoadUint64(&blocks.reads)
+
+	// served from the cache
+	resp, err = api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{Address: testAddress, Limit: 1})
+	r.NoError(err)
+	r.Len(resp.Transactions, 1)
+	r.Equal(reads, atomic.LoadUint64(&blocks.reads))
+
+	// the scan stops at the max blocks
+	resp, err = api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{
+		Address: "0x0000000000000000000000000000000000000009",
+	})
+	r.NoError(err)
+	r.Empty(resp.Transactions)
+	r.Equal(uint64(21), resp.FromBlock)
+	r.Equal(uint64(50), resp.ToBlock)
+
+	// the scan does not go below the first block
+	resp, err = api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{Address: testAddress, ToBlock: 12, Limit: 10})
+	r.NoError(err)
+	r.Len(resp.Transactions, 3)
+	r.Equal(uint64(0), resp.Transactions[2].BlockNumber)
+	r.Equal(uint64(0), resp.FromBlock)
+
+	_, err = api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = api.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{Address: testAddress, ToBlock: 100})
+	r.Equal(codes.Unavailable, status.Code(err))
+}
+
+func TestGetBlockTimestamp(t *testing.T) {
+	r := require.New(t)
+
+	api, _ := newTestAPI(t, testConfig(), nil)
+
+	resp, err := api.GetBlockTimestamp(context.Background(), &BlockTimestampRequest{})
+	r.NoError(err)
+	r.Equal(uint64(testLatest), resp.Block.Number)
+	r.Equal(uint64(1000+testLatest), resp.Block.Timestamp)
+
+	number := uint64(0)
+	resp, err = api.GetBlockTimestamp(context.Background(), &BlockTimestampRequest{Number: &number})
+	r.NoError(err)
+	r.Equal(uint64(1000), resp.Block.Timestamp)
+}
+
+func TestGetBotAlerts(t *testing.T) {
+	r := require.New(t)
+
+	api, _ := newTestAPI(t, testConfig(), nil)
+	_, err := api.GetBotAlerts(context.WithValue(context.Background(), botIDKey{}, testBotID), &BotAlertsRequest{})
+	r.Equal(codes.Unavailable, status.Code(err))
+
+	alerts := &testAlerts{}
+	api, _ = newTestAPI(t, testConfig(), alerts)
+	_, err = api.GetBotAlerts(context.Background(), &BotAlertsRequest{})
+	r.Equal(codes.PermissionDenied, status.Code(err))
+
+	ctx := context.WithValue(context.Background(), botIDKey{}, testBotID)
+	resp, err := api.GetBotAlerts(ctx, &BotAlertsRequest{})
+	r.NoError(err)
+	r.Len(resp.Alerts, 1)
+	r.Equal(testBotID, resp.Alerts[0].BotID)
+
+	// served from the cache
+	_, err = api.GetBotAlerts(ctx, &BotAlertsRequest{})
+	r.NoError(err)
+	r.Equal([]string{testBotID}, alerts.botIDs)
+}
+
+func TestAlertQuerySource(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/alerts", req.URL.Path)
+		r.Equal(testBotID, req.URL.Query().Get("bot"))
+		r.Equal("5", req.URL.Query().Get("limit"))
+		r.Equal("137", req.URL.Query().Get("chainId"))
+		r.Equal("Bearer test-token", req.Header.Get("Authorization"))
+		w.Write([]byte(`{"alerts": [{"id": "0xalert"}], "count": 1}`))
+	}))
+	defer server.Close()
+
+	alerts, err := NewAlertQuerySource(server.URL, "test-token", http.DefaultClient).BotAlerts(context.Background(), testBotID, 137, 5)
+	r.NoError(err)
+	r.Len(alerts, 1)
+	r.Equal("0xalert", alerts[0].ID)
+}
+
+func TestHistoryGrpc(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+
+	cfg := testConfig()
+	cfg.Rate = 0.001
+	cfg.Burst = 2
+	alerts := &testAlerts{}
+	api, err := NewAPI(context.Background(), APIConfig{
+		Port:             "0",
+		Config:           cfg,
+		Blocks:           &testBlocks{},
+		BotAuthenticator: authenticator,
+		Alerts:           alerts,
+	})
+	r.NoError(err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(api.authorize))
+	RegisterHistoryServer(grpcServer, api)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	r.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(&config.AgentConfig{ID: testBotID}, nil).Times(3)
+
+	timestampResp, err := client.GetBlockTimestamp(context.Background(), &BlockTimestampRequest{})
+	r.NoError(err)
+	r.Equal(uint64(testLatest), timestampResp.Block.Number)
+
+	alertsResp, err := client.GetBotAlerts(context.Background(), &BotAlertsRequest{})
+	r.NoError(err)
+	r.Len(alertsResp.Alerts, 1)
+	r.Equal([]string{testBotID}, alerts.botIDs)
+
+	_, err = client.GetAddressTransactions(context.Background(), &AddressTransactionsRequest{Address: testAddress})
+	r.Equal(codes.ResourceExhausted, status.Code(err))
+
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(nil, errors.New("bot container not found"))
+	_, err = client.GetBlockTimestamp(context.Background(), &BlockTimestampRequest{})
+	r.Equal(codes.PermissionDenied, status.Code(err))
+}
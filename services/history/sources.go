@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-node/services/alertquery"
+	"github.com/forta-network/forta-node/store"
+)
+
+// BlockSource reads the blocks from the chain.
+type BlockSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number uint64) (*Block, error)
+}
+
+// AlertSource reads the previous alerts of the bots.
+type AlertSource interface {
+	BotAlerts(ctx context.Context, botID string, chainID uint64, limit int) ([]*store.StoredAlert, error)
+}
+
+type rpcBlockSource struct {
+	rpcClient *rpc.Client
+}
+
+// NewRPCBlockSource creates a block source which reads from the JSON-RPC API.
+func NewRPCBlockSource(rpcClient *rpc.Client) BlockSource {
+	return &rpcBlockSource{rpcClient: rpcClient}
+}
+
+type rpcBlock struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Hash         string         `json:"hash"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	Transactions []struct {
+		Hash  string  `json:"hash"`
+		From  string  `json:"from"`
+		To    *string `json:"to"`
+		Value string  `json:"value"`
+	} `json:"transactions"`
+}
+
+func (source *rpcBlockSource) BlockNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	if err := source.rpcClient.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+		return 0, fmt.Errorf("failed to get the block number: %v", err)
+	}
+	return uint64(number), nil
+}
+
+func (source *rpcBlockSource) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	var result *rpcBlock
+	if err := source.rpcClient.CallContext(ctx, &result, "eth_getBlockByNumber", hexutil.EncodeUint64(number), true); err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %v", number, err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	block := &Block{
+		Number:    uint64(result.Number),
+		Hash:      result.Hash,
+		Timestamp: uint64(result.Timestamp),
+	}
+	for _, tx := range result.Transactions {
+		var to string
+		if tx.To != nil {
+			to = *tx.To
+		}
+		block.Transactions = append(block.Transactions, &Transaction{
+			Hash:        tx.Hash,
+			From:        tx.From,
+			To:          to,
+			Value:       tx.Value,
+			BlockNumber: block.Number,
+			Timestamp:   block.Timestamp,
+		})
+	}
+	return block, nil
+}
+
+type alertQuerySource struct {
+	apiURL     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewAlertQuerySource creates an alert source which reads from the alert query API of the scanner.
+func NewAlertQuerySource(apiURL, token string, httpClient *http.Client) AlertSource {
+	return &alertQuerySource{apiURL: apiURL, token: token, httpClient: httpClient}
+}
+
+func (source *alertQuerySource) BotAlerts(ctx context.Context, botID string, chainID uint64, limit int) ([]*store.StoredAlert, error) {
+	values := url.Values{}
+	values.Set("bot", botID)
+	values.Set("limit", strconv.Itoa(limit))
+	if chainID != 0 {
+		values.Set("chainId", strconv.FormatUint(chainID, 10))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/alerts?%s", source.apiURL, values.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+source.token)
+	resp, err := source.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the alerts: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alert query api responded with status %d", resp.StatusCode)
+	}
+	var alertsResp alertquery.AlertsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&alertsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode the alerts: %v", err)
+	}
+	return alertsResp.Alerts, nil
+}
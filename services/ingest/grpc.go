@@ -0,0 +1,128 @@
+package ingest
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// IngestServiceName is the name of the gRPC service which receives the events. The responses
+// contain the request IDs which the results and the alerts of the events can be traced with.
+const IngestServiceName = "network.forta.Ingest"
+
+// Ingest gRPC methods
+const (
+	MethodPushTx    = "/network.forta.Ingest/PushTx"
+	MethodPushBlock = "/network.forta.Ingest/PushBlock"
+)
+
+// IngestServer is the server side of the ingest service.
+type IngestServer interface {
+	PushTx(*protocol.TransactionEvent) (string, error)
+	PushBlock(*protocol.BlockEvent) (string, error)
+}
+
+var ingestServiceDesc = grpc.ServiceDesc{
+	ServiceName: IngestServiceName,
+	HandlerType: (*IngestServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PushTx",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(protocol.TransactionEvent)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return callUnary(srv, ctx, in, MethodPushTx, interceptor, func(req interface{}) (string, error) {
+					return srv.(IngestServer).PushTx(req.(*protocol.TransactionEvent))
+				})
+			},
+		},
+		{
+			MethodName: "PushBlock",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(protocol.BlockEvent)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return callUnary(srv, ctx, in, MethodPushBlock, interceptor, func(req interface{}) (string, error) {
+					return srv.(IngestServer).PushBlock(req.(*protocol.BlockEvent))
+				})
+			},
+		},
+	},
+}
+
+func callUnary(
+	srv interface{}, ctx context.Context, in interface{}, method string,
+	interceptor grpc.UnaryServerInterceptor, push func(interface{}) (string, error),
+) (interface{}, error) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		requestID, err := push(req)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return wrapperspb.String(requestID), nil
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+}
+
+// RegisterIngestServer registers the ingest service to the gRPC server.
+func RegisterIngestServer(s *grpc.Server, srv IngestServer) {
+	s.RegisterService(&ingestServiceDesc, srv)
+}
+
+func (api *API) authenticateGrpc(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+	if !api.authorized(header) {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return handler(ctx, req)
+}
+
+// Client pushes the events to a node.
+type Client struct {
+	conn  *grpc.ClientConn
+	token string
+}
+
+// NewClient creates a new client with the connection. The token is sent with each request if it is set.
+func NewClient(conn *grpc.ClientConn, token string) *Client {
+	return &Client{conn: conn, token: token}
+}
+
+// PushTx pushes a transaction event and returns the request ID.
+func (client *Client) PushTx(ctx context.Context, evt *protocol.TransactionEvent) (string, error) {
+	return client.push(ctx, MethodPushTx, evt)
+}
+
+// PushBlock pushes a block event and returns the request ID.
+func (client *Client) PushBlock(ctx context.Context, evt *protocol.BlockEvent) (string, error) {
+	return client.push(ctx, MethodPushBlock, evt)
+}
+
+func (client *Client) push(ctx context.Context, method string, evt interface{}) (string, error) {
+	if len(client.token) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+client.token)
+	}
+	out := new(wrapperspb.StringValue)
+	if err := client.conn.Invoke(ctx, method, evt, out); err != nil {
+		return "", err
+	}
+	return out.GetValue(), nil
+}
@@ -0,0 +1,269 @@
+package ingest
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxEventSize is the size limit of a pushed event.
+const maxEventSize = 32 << 20
+
+// API receives the transaction and the block events from the external systems and sends them to
+// the bots like the events from the chain feeds. The bot results are handled by the analyzers.
+type API struct {
+	ctx context.Context
+	cfg APIConfig
+
+	server     *http.Server
+	grpcServer *grpc.Server
+
+	lastEvent  health.TimeTracker
+	txCount    uint64
+	blockCount uint64
+}
+
+// APIConfig contains the ingest API configuration.
+type APIConfig struct {
+	Port     string
+	GrpcPort string
+	// the bearer token which the clients should send
+	Token         string
+	RequestSender botio.Sender
+}
+
+// NewAPI creates a new ingest API.
+func NewAPI(ctx context.Context, cfg APIConfig) (*API, error) {
+	if len(cfg.Port) == 0 && len(cfg.GrpcPort) == 0 {
+		return nil, errors.New("ingest api port is required")
+	}
+	// the api would let anyone who can reach the ports send events to the bots
+	if len(cfg.Token) == 0 {
+		return nil, errors.New("ingest api token is required")
+	}
+	return &API{ctx: ctx, cfg: cfg}, nil
+}
+
+// Start starts the HTTP and the gRPC servers.
+func (api *API) Start() error {
+	if len(api.cfg.GrpcPort) > 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", api.cfg.GrpcPort))
+		if err != nil {
+			return fmt.Errorf("failed to listen on the ingest grpc port: %v", err)
+		}
+		api.grpcServer = grpc.NewServer(grpc.MaxRecvMsgSize(maxEventSize), grpc.UnaryInterceptor(api.authenticateGrpc))
+		RegisterIngestServer(api.grpcServer, api)
+		go func() {
+			if err := api.grpcServer.Serve(lis); err != nil {
+				log.WithError(err).Error("ingest grpc server stopped")
+			}
+		}()
+	}
+	if len(api.cfg.Port) > 0 {
+		api.server = &http.Server{
+			Addr:    fmt.Sprintf(":%s", api.cfg.Port),
+			Handler: api.Handler(),
+		}
+		utils.GoListenAndServe(api.server)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler of the API.
+func (api *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ingest/tx", api.handleTx)
+	mux.HandleFunc("/ingest/block", api.handleBlock)
+	return mux
+}
+
+// Stop stops the servers.
+func (api *API) Stop() error {
+	if api.grpcServer != nil {
+		api.grpcServer.Stop()
+	}
+	if api.server != nil {
+		return api.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *API) Name() string {
+	return "ingest-api"
+}
+
+// Health implements the health.Reporter interface.
+func (api *API) Health() health.Reports {
+	return health.Reports{
+		api.lastEvent.GetReport("event.input.time"),
+		&health.Report{
+			Name:    "event.tx.count",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprint(atomic.LoadUint64(&api.txCount)),
+		},
+		&health.Report{
+			Name:    "event.block.count",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprint(atomic.LoadUint64(&api.blockCount)),
+		},
+	}
+}
+
+// PushTx sends the transaction event to the bots and returns the request ID.
+func (api *API) PushTx(evt *protocol.TransactionEvent) (string, error) {
+	if err := validateTx(evt); err != nil {
+		return "", err
+	}
+	setFeedTime(&evt.Timestamps)
+	requestID := uuid.Must(uuid.NewUUID()).String()
+	api.cfg.RequestSender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{RequestId: requestID, Event: evt})
+	atomic.AddUint64(&api.txCount, 1)
+	api.lastEvent.Set()
+	return requestID, nil
+}
+
+// PushBlock sends the block event to the bots and returns the request ID.
+func (api *API) PushBlock(evt *protocol.BlockEvent) (string, error) {
+	if err := validateBlock(evt); err != nil {
+		return "", err
+	}
+	setFeedTime(&evt.Timestamps)
+	requestID := uuid.Must(uuid.NewUUID()).String()
+	api.cfg.RequestSender.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{RequestId: requestID, Event: evt})
+	atomic.AddUint64(&api.blockCount, 1)
+	api.lastEvent.Set()
+	return requestID, nil
+}
+
+// validateTx checks the fields which the analyzers need to create the alerts.
+func validateTx(evt *protocol.TransactionEvent) error {
+	switch {
+	case evt == nil:
+		return errors.New("event is empty")
+	case len(evt.GetNetwork().GetChainId()) == 0:
+		return errors.New("network.chainId is required")
+	case len(evt.GetTransaction().GetHash()) == 0:
+		return errors.New("transaction.hash is required")
+	case evt.Block == nil:
+		return errors.New("block is required")
+	}
+	return nil
+}
+
+// validateBlock checks the fields which the analyzers need to create the alerts.
+func validateBlock(evt *protocol.BlockEvent) error {
+	switch {
+	case evt == nil:
+		return errors.New("event is empty")
+	case len(evt.GetNetwork().GetChainId()) == 0:
+		return errors.New("network.chainId is required")
+	case len(evt.BlockHash) == 0:
+		return errors.New("blockHash is required")
+	case len(evt.BlockNumber) == 0:
+		return errors.New("blockNumber is required")
+	}
+	return nil
+}
+
+func setFeedTime(timestamps **protocol.TrackingTimestamps) {
+	if *timestamps == nil {
+		*timestamps = &protocol.TrackingTimestamps{}
+	}
+	if len((*timestamps).Feed) == 0 {
+		(*timestamps).Feed = time.Now().UTC().Format(domain.TimeTrackingTimestampFormat)
+	}
+}
+
+func (api *API) authorized(header string) bool {
+	if len(api.cfg.Token) == 0 {
+		return false
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(api.cfg.Token)) == 1
+}
+
+func (api *API) handleTx(w http.ResponseWriter, r *http.Request) {
+	var evt protocol.TransactionEvent
+	if !api.readEvent(w, r, &evt) {
+		return
+	}
+	requestID, err := api.PushTx(&evt)
+	api.writeResult(w, requestID, err)
+}
+
+func (api *API) handleBlock(w http.ResponseWriter, r *http.Request) {
+	var evt protocol.BlockEvent
+	if !api.readEvent(w, r, &evt) {
+		return
+	}
+	requestID, err := api.PushBlock(&evt)
+	api.writeResult(w, requestID, err)
+}
+
+// readEvent reads the event from the request body as protobuf JSON.
+func (api *API) readEvent(w http.ResponseWriter, r *http.Request, evt proto.Message) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if !api.authorized(r.Header.Get("Authorization")) {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxEventSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to read the event: %v", err))
+		return false
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, evt); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to decode the event: %v", err))
+		return false
+	}
+	return true
+}
+
+func (api *API) writeResult(w http.ResponseWriter, requestID string, err error) {
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, &PushResponse{RequestID: requestID})
+}
+
+// PushResponse is the response of the HTTP endpoints.
+type PushResponse struct {
+	RequestID string `json:"requestId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, &PushResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Warn("failed to write ingest api response")
+	}
+}
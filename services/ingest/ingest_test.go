@@ -0,0 +1,122 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "test-token"
+
+var testTxJSON = `{
+	"network": {"chainId": "0x1"},
+	"transaction": {"hash": "0xabc"},
+	"block": {"blockHash": "0x123", "blockNumber": "0x10"}
+}`
+
+func TestNewAPI_Token(t *testing.T) {
+	r := require.New(t)
+
+	_, err := NewAPI(context.Background(), APIConfig{Port: "9109"})
+	r.Error(err)
+}
+
+func TestAPI_HTTP(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	sender := mock_botio.NewMockSender(ctrl)
+	api, err := NewAPI(context.Background(), APIConfig{Port: "9109", Token: testToken, RequestSender: sender})
+	r.NoError(err)
+	handler := api.Handler()
+
+	// no token
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ingest/tx", strings.NewReader(testTxJSON)))
+	r.Equal(http.StatusUnauthorized, rec.Code)
+
+	var sent *protocol.EvaluateTxRequest
+	sender.EXPECT().SendEvaluateTxRequest(gomock.Any()).Do(func(req *protocol.EvaluateTxRequest) {
+		sent = req
+	})
+	req := httptest.NewRequest(http.MethodPost, "/ingest/tx", strings.NewReader(testTxJSON))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	r.Equal(http.StatusAccepted, rec.Code)
+
+	var resp PushResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.Equal(sent.RequestId, resp.RequestID)
+	r.Equal("0xabc", sent.Event.Transaction.Hash)
+	r.NotEmpty(sent.Event.Timestamps.Feed)
+
+	// the block is missing
+	req = httptest.NewRequest(http.MethodPost, "/ingest/tx", strings.NewReader(`{"network": {"chainId": "0x1"}, "transaction": {"hash": "0xabc"}}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	r.Equal(http.StatusBadRequest, rec.Code)
+	r.Contains(rec.Body.String(), "block is required")
+
+	r.Equal("1", api.Health()[1].Details)
+}
+
+func TestAPI_Grpc(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	sender := mock_botio.NewMockSender(ctrl)
+	api, err := NewAPI(context.Background(), APIConfig{GrpcPort: "9110", Token: testToken, RequestSender: sender})
+	r.NoError(err)
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(api.authenticateGrpc))
+	RegisterIngestServer(server, api)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(
+		"bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	r.NoError(err)
+	defer conn.Close()
+
+	block := &protocol.BlockEvent{
+		BlockHash:   "0x123",
+		BlockNumber: "0x10",
+		Network:     &protocol.BlockEvent_Network{ChainId: "0x1"},
+	}
+	_, err = NewClient(conn, "").PushBlock(context.Background(), block)
+	r.Equal(codes.Unauthenticated, status.Code(err))
+
+	var sent *protocol.EvaluateBlockRequest
+	sender.EXPECT().SendEvaluateBlockRequest(gomock.Any()).Do(func(req *protocol.EvaluateBlockRequest) {
+		sent = req
+	})
+	client := NewClient(conn, testToken)
+	requestID, err := client.PushBlock(context.Background(), block)
+	r.NoError(err)
+	r.Equal(sent.RequestId, requestID)
+	r.Equal("0x123", sent.Event.BlockHash)
+
+	_, err = client.PushBlock(context.Background(), &protocol.BlockEvent{BlockHash: "0x123"})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+}
@@ -180,6 +180,10 @@ func (ins *Inspector) registerMessageHandlers() {
 }
 
 func (ins *Inspector) handleScannerBlock(payload messaging.ScannerPayload) error {
+	// inspect only by the main chain blocks
+	if payload.ChainID != 0 && payload.ChainID != uint64(ins.cfg.Config.ChainID) {
+		return nil
+	}
 	if payload.LatestBlockInput > 0 && ins.blockNumRemainder(payload.LatestBlockInput) == 0 {
 		// inspect from N blocks back to avoid synchronizations issues
 		inspectionBlockNum := payload.LatestBlockInput - uint64(ins.inspectEvery)
@@ -0,0 +1,271 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultCachedMethods is used when the cached methods are not configured. These methods read
+// the chain state, so their results are the same for the same params in a block.
+var DefaultCachedMethods = []string{
+	"eth_call", "eth_getBalance", "eth_getCode", "eth_getStorageAt", "eth_getTransactionCount",
+	"eth_chainId", "net_version",
+}
+
+// maxCachedResultSize is the size limit of a single cached result.
+const maxCachedResultSize = 1 << 20
+
+// cacheStatus tells how a request is handled by the cache.
+type cacheStatus int
+
+const (
+	cacheSkipped cacheStatus = iota
+	cacheHit
+	cacheMiss
+)
+
+type cacheRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type cacheResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+type cacheEntry struct {
+	result    json.RawMessage
+	expiresAt time.Time
+}
+
+type cacheKey struct {
+	key       string
+	expiresAt time.Time
+}
+
+// responseCache keeps the successful results of the single JSON-RPC requests for a short
+// time. The concurrent requests with the same key wait for the first one to complete instead
+// of going to the upstream together.
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	methods    methodAllowlist
+
+	entries map[string]*cacheEntry
+	// the keys in insertion order, which is also the expiration order
+	order    []cacheKey
+	inflight map[string]chan struct{}
+	mu       sync.Mutex
+
+	hits   uint64
+	misses uint64
+}
+
+// newResponseCache creates a new cache. It returns nil if the cache is disabled.
+func newResponseCache(cfg config.JsonRpcCacheConfig) *responseCache {
+	if cfg.Disable {
+		return nil
+	}
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = DefaultCachedMethods
+	}
+	return &responseCache{
+		ttl:        time.Duration(cfg.TTLSeconds) * time.Second,
+		maxEntries: cfg.MaxEntries,
+		methods:    methodAllowlist(methods),
+		entries:    make(map[string]*cacheEntry),
+		inflight:   make(map[string]chan struct{}),
+	}
+}
+
+// key returns the cache key of the request if the request can be cached.
+func (c *responseCache) key(b []byte) (*cacheRequest, string, bool) {
+	b = bytes.TrimSpace(b)
+	// the batch requests are proxied as they are
+	if len(b) == 0 || b[0] != '{' {
+		return nil, "", false
+	}
+	var req cacheRequest
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, "", false
+	}
+	if !c.methods.allows(req.Method) {
+		return nil, "", false
+	}
+	// the pending state changes with every new transaction
+	if bytes.Contains(req.Params, []byte(`"pending"`)) {
+		return nil, "", false
+	}
+	var params bytes.Buffer
+	if len(req.Params) > 0 {
+		if err := json.Compact(&params, req.Params); err != nil {
+			return nil, "", false
+		}
+	}
+	return &req, req.Method + ":" + params.String(), true
+}
+
+// serve responds from the cache or proxies the request to the upstream and caches the result.
+func (c *responseCache) serve(w http.ResponseWriter, req *http.Request, next http.Handler) cacheStatus {
+	b, err := peekBody(w, req)
+	if err != nil {
+		next.ServeHTTP(w, req)
+		return cacheSkipped
+	}
+	rpcReq, key, ok := c.key(b)
+	if !ok {
+		next.ServeHTTP(w, req)
+		return cacheSkipped
+	}
+
+	c.mu.Lock()
+	if result, ok := c.lookup(key); ok {
+		c.mu.Unlock()
+		return c.hit(w, rpcReq, result)
+	}
+	if done, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-req.Context().Done():
+			return cacheSkipped
+		}
+		c.mu.Lock()
+		result, ok := c.lookup(key)
+		c.mu.Unlock()
+		if ok {
+			return c.hit(w, rpcReq, result)
+		}
+		// the first request failed and this one goes to the upstream without being cached
+		atomic.AddUint64(&c.misses, 1)
+		next.ServeHTTP(w, req)
+		return cacheMiss
+	}
+	done := make(chan struct{})
+	c.inflight[key] = done
+	c.mu.Unlock()
+	defer c.done(key, done)
+
+	atomic.AddUint64(&c.misses, 1)
+	// the response is decoded to be cached, so it should not be compressed
+	req.Header.Del("Accept-Encoding")
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(recorder, req)
+	c.store(key, recorder)
+	return cacheMiss
+}
+
+// lookup returns the result if it is not expired. The lock should be held.
+func (c *responseCache) lookup(key string) (json.RawMessage, bool) {
+	entry, ok := c.entries[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *responseCache) hit(w http.ResponseWriter, rpcReq *cacheRequest, result json.RawMessage) cacheStatus {
+	atomic.AddUint64(&c.hits, 1)
+	writeCachedResult(w, rpcReq.ID, result)
+	return cacheHit
+}
+
+func (c *responseCache) done(key string, done chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.inflight, key)
+	close(done)
+}
+
+func (c *responseCache) store(key string, recorder *responseRecorder) {
+	if recorder.status != http.StatusOK || recorder.overflow {
+		return
+	}
+	var resp cacheResponse
+	if err := json.Unmarshal(recorder.body.Bytes(), &resp); err != nil {
+		return
+	}
+	if len(resp.Error) > 0 || len(resp.Result) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	expiresAt := now.Add(c.ttl)
+	c.entries[key] = &cacheEntry{result: resp.Result, expiresAt: expiresAt}
+	c.order = append(c.order, cacheKey{key: key, expiresAt: expiresAt})
+	for len(c.order) > 0 && (len(c.entries) > c.maxEntries || now.After(c.order[0].expiresAt)) {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		// the key can be in the order again if it expired and was cached again
+		if entry, ok := c.entries[oldest.key]; ok && entry.expiresAt.Equal(oldest.expiresAt) {
+			delete(c.entries, oldest.key)
+		}
+	}
+}
+
+// stats returns the hit and miss counts.
+func (c *responseCache) stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
+
+func writeCachedResult(w http.ResponseWriter, id json.RawMessage, result json.RawMessage) {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(&cacheResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result:  result,
+	}); err != nil {
+		log.WithError(err).Error("failed to write cached jsonrpc response body")
+	}
+}
+
+// responseRecorder copies the upstream response while writing it to the bot.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if !rr.overflow {
+		if rr.body.Len()+len(b) > maxCachedResultSize {
+			rr.overflow = true
+			rr.body.Reset()
+		} else {
+			rr.body.Write(b)
+		}
+	}
+	return rr.ResponseWriter.Write(b)
+}
+
+func (rr *responseRecorder) Flush() {
+	if flusher, ok := rr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
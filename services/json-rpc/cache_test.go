@@ -0,0 +1,146 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testUpstream struct {
+	calls int32
+	delay time.Duration
+	fail  bool
+}
+
+func (u *testUpstream) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt32(&u.calls, 1)
+	time.Sleep(u.delay)
+	var rpcReq cacheRequest
+	b, _ := peekBody(w, req)
+	_ = json.Unmarshal(b, &rpcReq)
+	if u.fail {
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"execution reverted"}}`, rpcReq.ID)
+		return
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%d"}`, rpcReq.ID, atomic.LoadInt32(&u.calls))
+}
+
+func testCacheRequest(cache *responseCache, upstream http.Handler, body string) (cacheStatus, string) {
+	req := httptest.NewRequest(http.MethodPost, "http://proxy", bytes.NewBufferString(body))
+	recorder := httptest.NewRecorder()
+	status := cache.serve(recorder, req, upstream)
+	return status, recorder.Body.String()
+}
+
+func testCache() *responseCache {
+	return newResponseCache(config.JsonRpcCacheConfig{TTLSeconds: 60, MaxEntries: 2})
+}
+
+func TestResponseCache(t *testing.T) {
+	r := require.New(t)
+
+	cache := testCache()
+	upstream := &testUpstream{}
+
+	call := `{"jsonrpc":"2.0","id":%d,"method":"eth_call","params":[{"to":"0x1","data":"0x06fdde03"}, "0x10"]}`
+	status, body := testCacheRequest(cache, upstream, fmt.Sprintf(call, 1))
+	r.Equal(cacheMiss, status)
+	r.JSONEq(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`, body)
+
+	// the same call of another bot is responded from the cache with its own id
+	status, body = testCacheRequest(cache, upstream, fmt.Sprintf(call, 2))
+	r.Equal(cacheHit, status)
+	r.JSONEq(`{"jsonrpc":"2.0","id":2,"result":"0x1"}`, body)
+	r.EqualValues(1, upstream.calls)
+
+	// the formatting of the params does not matter
+	status, _ = testCacheRequest(cache, upstream, `{"jsonrpc":"2.0","id":3,"method":"eth_call","params":[{"to":"0x1", "data":"0x06fdde03"},"0x10"]}`)
+	r.Equal(cacheHit, status)
+
+	// another block is another key
+	status, _ = testCacheRequest(cache, upstream, `{"jsonrpc":"2.0","id":4,"method":"eth_call","params":[{"to":"0x1","data":"0x06fdde03"},"0x11"]}`)
+	r.Equal(cacheMiss, status)
+	r.EqualValues(2, upstream.calls)
+
+	hits, misses := cache.stats()
+	r.EqualValues(2, hits)
+	r.EqualValues(2, misses)
+}
+
+func TestResponseCache_Skipped(t *testing.T) {
+	r := require.New(t)
+
+	cache := testCache()
+	upstream := &testUpstream{}
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x1234"]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x1","pending"]}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}]`,
+	} {
+		status, _ := testCacheRequest(cache, upstream, body)
+		r.Equal(cacheSkipped, status)
+		status, _ = testCacheRequest(cache, upstream, body)
+		r.Equal(cacheSkipped, status)
+	}
+	r.EqualValues(6, upstream.calls)
+}
+
+func TestResponseCache_Errors(t *testing.T) {
+	r := require.New(t)
+
+	cache := testCache()
+	upstream := &testUpstream{fail: true}
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x1"},"latest"]}`
+	status, resp := testCacheRequest(cache, upstream, body)
+	r.Equal(cacheMiss, status)
+	r.Contains(resp, "execution reverted")
+
+	// the errors are not cached
+	status, _ = testCacheRequest(cache, upstream, body)
+	r.Equal(cacheMiss, status)
+	r.EqualValues(2, upstream.calls)
+}
+
+func TestResponseCache_Concurrent(t *testing.T) {
+	r := require.New(t)
+
+	cache := testCache()
+	upstream := &testUpstream{delay: 100 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, body := testCacheRequest(cache, upstream, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_chainId"}`, i))
+			r.JSONEq(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0x1"}`, i), body)
+		}(i)
+	}
+	wg.Wait()
+	r.EqualValues(1, upstream.calls)
+}
+
+func TestResponseCache_MaxEntries(t *testing.T) {
+	r := require.New(t)
+
+	cache := testCache()
+	upstream := &testUpstream{}
+
+	for i := 0; i < 3; i++ {
+		testCacheRequest(cache, upstream, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x%d","latest"]}`, i))
+	}
+	r.Len(cache.entries, 2)
+	status, _ := testCacheRequest(cache, upstream, `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0","latest"]}`)
+	r.Equal(cacheMiss, status)
+}
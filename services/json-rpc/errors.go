@@ -2,19 +2,23 @@ package json_rpc
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// requestPayload keeps the ID as it is, since the IDs can be numbers, strings or null.
 type requestPayload struct {
-	ID int `json:"id"`
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
 }
 
 type errorResponse struct {
-	JSONRPC string       `json:"jsonrpc"`
-	ID      int          `json:"id"`
-	Error   jsonRpcError `json:"error"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   jsonRpcError    `json:"error"`
 }
 
 type jsonRpcError struct {
@@ -42,3 +46,53 @@ func writeTooManyReqsErr(w http.ResponseWriter, req *http.Request) {
 		log.WithError(err).Error("failed to write jsonrpc error response body")
 	}
 }
+
+func writeMethodNotAllowedErr(w http.ResponseWriter, reqID json.RawMessage, method string) {
+	w.WriteHeader(http.StatusForbidden)
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		JSONRPC: "2.0",
+		ID:      reqID,
+		Error: jsonRpcError{
+			Code:    -32601,
+			Message: fmt.Sprintf("method '%s' is not allowed by scan node", method),
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write jsonrpc error response body")
+	}
+}
+
+// writeUnknownChainErr responds to the requests to a chain which has no json-rpc api.
+func writeUnknownChainErr(w http.ResponseWriter, chainID string) {
+	w.WriteHeader(http.StatusNotFound)
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		JSONRPC: "2.0",
+		Error: jsonRpcError{
+			Code:    -32000,
+			Message: fmt.Sprintf("chain '%s' is not scanned by scan node", chainID),
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write jsonrpc error response body")
+	}
+}
+
+// writeInvalidRequestErr responds to the requests which cannot be checked against the allowed methods.
+func writeInvalidRequestErr(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		JSONRPC: "2.0",
+		Error: jsonRpcError{
+			Code:    -32600,
+			Message: fmt.Sprintf("invalid request: %v", err),
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write jsonrpc error response body")
+	}
+}
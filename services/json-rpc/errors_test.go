@@ -28,7 +28,7 @@ func TestTooManyReqsError(t *testing.T) {
 	var errResp errorResponse
 	r.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
 	r.Equal("2.0", errResp.JSONRPC)
-	r.Equal(testRequestID, errResp.ID)
+	r.Equal(json.RawMessage(fmt.Sprint(testRequestID)), errResp.ID)
 	r.Equal(-32000, errResp.Error.Code)
 	r.Contains(errResp.Error.Message, "exceeds")
 }
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/forta-network/forta-node/clients"
@@ -19,26 +22,72 @@ import (
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components/metrics"
 )
 
+// chainPathPrefix is the path prefix of the requests to a specific chain: /chains/<chain id>
+const chainPathPrefix = "/chains/"
+
 // JsonRpcProxy proxies requests from agents to json-rpc endpoint
 type JsonRpcProxy struct {
 	ctx       context.Context
-	cfg       config.JsonRpcConfig
+	cfg       config.Config
 	server    *http.Server
 	msgClient clients.MessageClient
 
-	rateLimiter ratelimiter.RateLimiter
+	// the json-rpc apis of the main chain and the additional chains
+	upstreams map[int]*chainUpstream
+
+	rateLimiter     ratelimiter.RateLimiter
+	botRateLimiters map[string]ratelimiter.RateLimiter
+	// the rate limiters are replaced when the config is reloaded
+	rateLimitersMu sync.RWMutex
+	allowedMethods methodAllowlist
 
 	lastErr          health.ErrorTracker
 	botAuthenticator clients.IPAuthenticator
 }
 
+// chainUpstream is the json-rpc api of a chain.
+type chainUpstream struct {
+	cfg     config.JsonRpcConfig
+	handler http.Handler
+	// nil if the cache is disabled - the chains have separate caches
+	cache *responseCache
+}
+
 func (p *JsonRpcProxy) Start() error {
-	rpcUrl, err := url.Parse(p.cfg.Url)
+	c := cors.New(cors.Options{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	})
+	for _, up := range p.upstreams {
+		rp, err := newReverseProxy(up.cfg)
+		if err != nil {
+			return err
+		}
+		up.handler = c.Handler(rp)
+	}
+
+	p.server = &http.Server{
+		Addr:    ":8545",
+		Handler: p.metricHandler(),
+	}
+	utils.GoListenAndServe(p.server)
+
+	go p.apiHealthChecker()
+
+	// the scanner lets the containers know after reloading the config
+	p.msgClient.Subscribe(messaging.SubjectAdminConfigReload, messaging.ConfigReloadHandler(p.handleConfigReload))
+
+	return nil
+}
+
+func newReverseProxy(cfg config.JsonRpcConfig) (*httputil.ReverseProxy, error) {
+	rpcUrl, err := url.Parse(cfg.Url)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	rp := httputil.NewSingleHostReverseProxy(rpcUrl)
 
@@ -47,32 +96,28 @@ func (p *JsonRpcProxy) Start() error {
 		d(r)
 		r.Host = rpcUrl.Host
 		r.URL = rpcUrl
-		for h, v := range p.cfg.Headers {
+		for h, v := range cfg.Headers {
 			r.Header.Set(h, v)
 		}
 	}
+	return rp, nil
+}
 
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowCredentials: true,
-	})
-
-	p.server = &http.Server{
-		Addr:    ":8545",
-		Handler: p.metricHandler(c.Handler(rp)),
-	}
-	utils.GoListenAndServe(p.server)
-
-	go p.apiHealthChecker()
-
+func (p *JsonRpcProxy) handleConfigReload(payload messaging.ConfigReloadPayload) error {
+	services.TriggerReload()
 	return nil
 }
 
-func (p *JsonRpcProxy) metricHandler(h http.Handler) http.Handler {
+func (p *JsonRpcProxy) metricHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		t := time.Now()
 		agentConfig, err := p.botAuthenticator.FindAgentFromRemoteAddr(req.RemoteAddr)
-		if err == nil && p.rateLimiter.ExceedsLimit(agentConfig.ID) {
+		up, chainID := p.upstreamFor(req, agentConfig)
+		if up == nil {
+			writeUnknownChainErr(w, chainID)
+			return
+		}
+		if err == nil && p.getRateLimiter(agentConfig.ID).ExceedsLimit(agentConfig.ID) {
 			writeTooManyReqsErr(w, req)
 			p.msgClient.PublishProto(
 				messaging.SubjectMetricAgent, &protocol.AgentMetricList{
@@ -82,19 +127,75 @@ func (p *JsonRpcProxy) metricHandler(h http.Handler) http.Handler {
 			return
 		}
 
-		h.ServeHTTP(w, req)
+		// the requests which cannot be checked are not proxied
+		reqs, readErr := readRequests(w, req)
+		if readErr != nil {
+			writeInvalidRequestErr(w, readErr)
+			return
+		}
+		if disallowed := p.allowedMethods.findDisallowed(reqs); disallowed != nil {
+			writeMethodNotAllowedErr(w, disallowed.ID, disallowed.Method)
+			if err == nil {
+				metrics.SendAgentMetrics(p.msgClient, []*protocol.AgentMetric{
+					metrics.CreateAgentMetric(*agentConfig, metrics.MetricJSONRPCBlocked, 1),
+				})
+			}
+			return
+		}
+
+		cached := cacheSkipped
+		if up.cache != nil {
+			cached = up.cache.serve(w, req, up.handler)
+		} else {
+			up.handler.ServeHTTP(w, req)
+		}
 
 		if err == nil {
 			duration := time.Since(t)
+			agentMetrics := metrics.GetJSONRPCMetrics(*agentConfig, t, 1, 0, duration)
+			switch cached {
+			case cacheHit:
+				agentMetrics = append(agentMetrics, metrics.CreateAgentMetric(*agentConfig, metrics.MetricJSONRPCCacheHit, 1))
+			case cacheMiss:
+				agentMetrics = append(agentMetrics, metrics.CreateAgentMetric(*agentConfig, metrics.MetricJSONRPCCacheMiss, 1))
+			}
 			p.msgClient.PublishProto(
 				messaging.SubjectMetricAgent, &protocol.AgentMetricList{
-					Metrics: metrics.GetJSONRPCMetrics(*agentConfig, t, 1, 0, duration),
+					Metrics: agentMetrics,
 				},
 			)
 		}
 	})
 }
 
+// upstreamFor returns the json-rpc api of the chain in the request path or the chain of the bot. It
+// returns nil and the requested chain id if the chain is not scanned.
+func (p *JsonRpcProxy) upstreamFor(req *http.Request, agentConfig *config.AgentConfig) (*chainUpstream, string) {
+	chainID := p.cfg.ChainID
+	if strings.HasPrefix(req.URL.Path, chainPathPrefix) {
+		chainIDStr := strings.Trim(strings.TrimPrefix(req.URL.Path, chainPathPrefix), "/")
+		id, err := strconv.Atoi(chainIDStr)
+		if err != nil {
+			return nil, chainIDStr
+		}
+		chainID = id
+	} else if agentConfig != nil {
+		chainID = p.cfg.BotChainID(agentConfig.ID)
+	}
+	return p.upstreams[chainID], strconv.Itoa(chainID)
+}
+
+// getRateLimiter returns the bot specific rate limiter if the bot has a different limit.
+func (p *JsonRpcProxy) getRateLimiter(botID string) ratelimiter.RateLimiter {
+	p.rateLimitersMu.RLock()
+	defer p.rateLimitersMu.RUnlock()
+
+	if rl, ok := p.botRateLimiters[strings.ToLower(botID)]; ok {
+		return rl
+	}
+	return p.rateLimiter
+}
+
 func (p *JsonRpcProxy) Stop() error {
 	if p.server != nil {
 		return p.server.Close()
@@ -108,9 +209,37 @@ func (p *JsonRpcProxy) Name() string {
 
 // Health implements health.Reporter interface.
 func (p *JsonRpcProxy) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		p.lastErr.GetReport("api"),
 	}
+	var (
+		cached       bool
+		hits, misses uint64
+	)
+	for _, up := range p.upstreams {
+		if up.cache == nil {
+			continue
+		}
+		cached = true
+		upHits, upMisses := up.cache.stats()
+		hits += upHits
+		misses += upMisses
+	}
+	if cached {
+		reports = append(reports,
+			&health.Report{
+				Name:    "cache.hits",
+				Status:  health.StatusInfo,
+				Details: strconv.FormatUint(hits, 10),
+			},
+			&health.Report{
+				Name:    "cache.misses",
+				Status:  health.StatusInfo,
+				Details: strconv.FormatUint(misses, 10),
+			},
+		)
+	}
+	return reports
 }
 
 func (p *JsonRpcProxy) apiHealthChecker() {
@@ -122,7 +251,12 @@ func (p *JsonRpcProxy) apiHealthChecker() {
 }
 
 func (p *JsonRpcProxy) testAPI() {
-	err := ethereum.TestAPI(p.ctx, p.cfg.Url)
+	var err error
+	for _, up := range p.upstreams {
+		if err = ethereum.TestAPI(p.ctx, up.cfg.Url); err != nil {
+			break
+		}
+	}
 	p.lastErr.Set(err)
 }
 
@@ -132,9 +266,9 @@ func NewJsonRpcProxy(ctx context.Context, cfg config.Config) (*JsonRpcProxy, err
 		jCfg = cfg.JsonRpcProxy.JsonRpc
 	}
 
-	rateLimiting := cfg.JsonRpcProxy.RateLimitConfig
-	if rateLimiting == nil {
-		rateLimiting = (*config.RateLimitConfig)(settings.GetChainSettings(cfg.ChainID).JsonRpcRateLimiting)
+	rateLimiter, botRateLimiters, err := newRateLimiters(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	msgClient := messaging.NewClient("json-rpc", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
@@ -144,14 +278,66 @@ func NewJsonRpcProxy(ctx context.Context, cfg config.Config) (*JsonRpcProxy, err
 		return nil, err
 	}
 
+	upstreams := map[int]*chainUpstream{
+		cfg.ChainID: {cfg: jCfg, cache: newResponseCache(cfg.JsonRpcProxy.Cache)},
+	}
+	for _, chain := range cfg.Chains {
+		if len(chain.JsonRpc.Url) == 0 {
+			continue
+		}
+		upstreams[chain.ChainID] = &chainUpstream{cfg: chain.JsonRpc, cache: newResponseCache(cfg.JsonRpcProxy.Cache)}
+	}
+
 	return &JsonRpcProxy{
 		ctx:              ctx,
-		cfg:              jCfg,
+		cfg:              cfg,
+		upstreams:        upstreams,
 		botAuthenticator: botAuthenticator,
 		msgClient:        msgClient,
-		rateLimiter: ratelimiter.NewRateLimiter(
-			rateLimiting.Rate,
-			rateLimiting.Burst,
-		),
+		rateLimiter:      rateLimiter,
+		botRateLimiters:  botRateLimiters,
+		allowedMethods:   newMethodAllowlist(cfg.JsonRpcProxy.AllowedMethods),
+	}, nil
+}
+
+// newRateLimiters creates the default rate limiter and the rate limiters of the bots with different limits.
+func newRateLimiters(cfg config.Config) (ratelimiter.RateLimiter, map[string]ratelimiter.RateLimiter, error) {
+	rateLimiting := cfg.JsonRpcProxy.RateLimitConfig
+	if rateLimiting == nil {
+		rateLimiting = (*config.RateLimitConfig)(settings.GetChainSettings(cfg.ChainID).JsonRpcRateLimiting)
+	}
+	if rateLimiting.Rate <= 0 {
+		return nil, nil, fmt.Errorf("invalid json-rpc proxy rate limit: %v", rateLimiting.Rate)
+	}
+
+	botRateLimiters := make(map[string]ratelimiter.RateLimiter)
+	for botID, botRateLimiting := range cfg.JsonRpcProxy.BotRateLimits {
+		if botRateLimiting == nil {
+			continue
+		}
+		if botRateLimiting.Rate <= 0 {
+			return nil, nil, fmt.Errorf("invalid json-rpc proxy rate limit of bot %s: %v", botID, botRateLimiting.Rate)
+		}
+		botRateLimiters[strings.ToLower(botID)] = ratelimiter.NewRateLimiter(
+			botRateLimiting.Rate,
+			botRateLimiting.Burst,
+		)
+	}
+	return ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst), botRateLimiters, nil
+}
+
+// PrepareRateLimits creates the rate limiters of the reloaded config. The returned function replaces
+// the current ones, so the bots start with full bursts.
+func (p *JsonRpcProxy) PrepareRateLimits(cfg config.Config) (func(), error) {
+	rateLimiter, botRateLimiters, err := newRateLimiters(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		p.rateLimitersMu.Lock()
+		defer p.rateLimitersMu.Unlock()
+
+		p.rateLimiter = rateLimiter
+		p.botRateLimiters = botRateLimiters
 	}, nil
 }
@@ -0,0 +1,88 @@
+package json_rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareRateLimits(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	cfg.ChainID = 1
+	cfg.JsonRpcProxy.RateLimitConfig = &config.RateLimitConfig{Rate: 1, Burst: 1}
+	rateLimiter, botRateLimiters, err := newRateLimiters(cfg)
+	r.NoError(err)
+	proxy := &JsonRpcProxy{rateLimiter: rateLimiter, botRateLimiters: botRateLimiters}
+
+	r.False(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+	r.True(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+
+	cfg.JsonRpcProxy.BotRateLimits = map[string]*config.RateLimitConfig{"0xBOT": {Rate: 1, Burst: 2}}
+	apply, err := proxy.PrepareRateLimits(cfg)
+	r.NoError(err)
+	r.True(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+	apply()
+
+	// the new limits start with full bursts
+	r.False(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+	r.False(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+	r.True(proxy.getRateLimiter("0xBot").ExceedsLimit("0xBot"))
+
+	cfg.JsonRpcProxy.RateLimitConfig.Rate = 0
+	_, err = proxy.PrepareRateLimits(cfg)
+	r.Error(err)
+}
+
+func TestProxyChains(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+	mainChain, otherChain := &testUpstream{}, &testUpstream{}
+	proxy := &JsonRpcProxy{
+		cfg: config.Config{
+			ChainID: 1,
+			Scan:    config.ScannerConfig{Bots: []string{"0xbot1"}},
+			Chains:  []config.ChainConfig{{ChainID: 137, Bots: []string{"0xbot2"}}},
+		},
+		upstreams: map[int]*chainUpstream{
+			1:   {handler: mainChain},
+			137: {handler: otherChain},
+		},
+		botAuthenticator: authenticator,
+		rateLimiter:      ratelimiter.NewRateLimiter(100, 100),
+		allowedMethods:   newMethodAllowlist(nil),
+		msgClient:        msgClient,
+	}
+	handler := proxy.metricHandler()
+
+	request := func(botID, target string) int {
+		authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(&config.AgentConfig{ID: botID}, nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString(`{"id":1,"method":"eth_chainId"}`)))
+		return recorder.Code
+	}
+
+	// the requests go to the chain of the bot
+	r.Equal(http.StatusOK, request("0xbot1", "http://proxy"))
+	r.Equal(http.StatusOK, request("0xbot2", "http://proxy"))
+	r.Equal(int32(1), mainChain.calls)
+	r.Equal(int32(1), otherChain.calls)
+
+	// or to the chain in the path
+	r.Equal(http.StatusOK, request("0xbot1", "http://proxy/chains/137"))
+	r.Equal(int32(2), otherChain.calls)
+	r.Equal(http.StatusNotFound, request("0xbot1", "http://proxy/chains/10"))
+	r.Equal(http.StatusNotFound, request("0xbot1", "http://proxy/chains/x"))
+}
@@ -0,0 +1,88 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxRequestBodySize is the size limit of the request bodies which are read before proxying.
+const maxRequestBodySize = 10 << 20
+
+// DefaultAllowedMethods is used when the allowed methods are not configured.
+var DefaultAllowedMethods = []string{"eth_*", "net_version", "web3_clientVersion"}
+
+// methodAllowlist matches the JSON-RPC methods with the allowed method patterns.
+// A pattern which ends with '*' allows all methods with the same prefix.
+type methodAllowlist []string
+
+func newMethodAllowlist(patterns []string) methodAllowlist {
+	if len(patterns) == 0 {
+		patterns = DefaultAllowedMethods
+	}
+	return methodAllowlist(patterns)
+}
+
+func (list methodAllowlist) allows(method string) bool {
+	for _, pattern := range list {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if method == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// readRequests reads the single or the batch JSON-RPC request from the body and puts
+// the body back so that the request can be proxied later.
+func readRequests(w http.ResponseWriter, req *http.Request) ([]*requestPayload, error) {
+	b, err := peekBody(w, req)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var reqs []*requestPayload
+	if b[0] == '[' {
+		err = json.Unmarshal(b, &reqs)
+		return reqs, err
+	}
+	var single requestPayload
+	if err := json.Unmarshal(b, &single); err != nil {
+		return nil, err
+	}
+	return append(reqs, &single), nil
+}
+
+// peekBody reads the request body up to the size limit and puts it back.
+func peekBody(w http.ResponseWriter, req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestBodySize))
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// findDisallowed returns the first request which uses a method that is not allowed.
+func (list methodAllowlist) findDisallowed(reqs []*requestPayload) *requestPayload {
+	for _, req := range reqs {
+		if !list.allows(req.Method) {
+			return req
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodAllowlist(t *testing.T) {
+	r := require.New(t)
+
+	list := newMethodAllowlist(nil)
+	r.True(list.allows("eth_getBlockByNumber"))
+	r.True(list.allows("net_version"))
+	r.False(list.allows("net_peerCount"))
+	r.False(list.allows("debug_traceTransaction"))
+	r.False(list.allows("admin_peers"))
+
+	list = newMethodAllowlist([]string{"eth_call", "trace_*"})
+	r.True(list.allows("eth_call"))
+	r.True(list.allows("trace_block"))
+	r.False(list.allows("eth_sendRawTransaction"))
+}
+
+func TestReadRequests(t *testing.T) {
+	r := require.New(t)
+
+	body := `[{"id":1,"method":"eth_chainId"},{"id":2,"method":"admin_peers"}]`
+	req, err := http.NewRequest("POST", "http://asdf.asdf", bytes.NewBufferString(body))
+	r.NoError(err)
+
+	reqs, err := readRequests(httptest.NewRecorder(), req)
+	r.NoError(err)
+	r.Len(reqs, 2)
+	disallowed := newMethodAllowlist(nil).findDisallowed(reqs)
+	r.NotNil(disallowed)
+	r.Equal(json.RawMessage("2"), disallowed.ID)
+	r.Equal("admin_peers", disallowed.Method)
+
+	// the body is still readable
+	b, err := io.ReadAll(req.Body)
+	r.NoError(err)
+	r.Equal(body, string(b))
+
+	req, err = http.NewRequest("POST", "http://asdf.asdf", bytes.NewBufferString(`{"id":1,"method":"eth_chainId"}`))
+	r.NoError(err)
+	reqs, err = readRequests(httptest.NewRecorder(), req)
+	r.NoError(err)
+	r.Len(reqs, 1)
+	r.Nil(newMethodAllowlist(nil).findDisallowed(reqs))
+}
+
+func TestMethodNotAllowedError(t *testing.T) {
+	r := require.New(t)
+
+	recorder := httptest.NewRecorder()
+	writeMethodNotAllowedErr(recorder, json.RawMessage(`"x"`), "admin_peers")
+
+	resp := recorder.Result()
+	r.Equal(http.StatusForbidden, resp.StatusCode)
+	r.Contains(recorder.Body.String(), "admin_peers")
+	r.Contains(recorder.Body.String(), "-32601")
+	r.Contains(recorder.Body.String(), `"id":"x"`)
+}
+
+func TestReadRequests_IDs(t *testing.T) {
+	r := require.New(t)
+
+	// the ids can be strings or null too
+	body := `[{"id":"x","method":"eth_chainId"},{"id":null,"method":"debug_traceCall"},{"method":"eth_call"}]`
+	req, err := http.NewRequest("POST", "http://asdf.asdf", bytes.NewBufferString(body))
+	r.NoError(err)
+	reqs, err := readRequests(httptest.NewRecorder(), req)
+	r.NoError(err)
+	r.Len(reqs, 3)
+	r.Equal(json.RawMessage(`"x"`), reqs[0].ID)
+	disallowed := newMethodAllowlist(nil).findDisallowed(reqs)
+	r.NotNil(disallowed)
+	r.Equal("debug_traceCall", disallowed.Method)
+
+	for _, body := range []string{`{"id":1,"method":`, `[{"id":1,"method":1}]`, `"eth_call"`} {
+		req, err = http.NewRequest("POST", "http://asdf.asdf", bytes.NewBufferString(body))
+		r.NoError(err)
+		_, err = readRequests(httptest.NewRecorder(), req)
+		r.Error(err, body)
+	}
+
+	req, err = http.NewRequest("POST", "http://asdf.asdf", bytes.NewReader(make([]byte, maxRequestBodySize+1)))
+	r.NoError(err)
+	_, err = readRequests(httptest.NewRecorder(), req)
+	var maxBytesErr *http.MaxBytesError
+	r.True(errors.As(err, &maxBytesErr))
+}
+
+func TestProxyAllowedMethods(t *testing.T) {
+	r := require.New(t)
+
+	authenticator := mock_clients.NewMockIPAuthenticator(gomock.NewController(t))
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(nil, errors.New("not a bot")).AnyTimes()
+	upstream := &testUpstream{}
+	proxy := &JsonRpcProxy{
+		cfg:              config.Config{ChainID: 1},
+		upstreams:        map[int]*chainUpstream{1: {handler: upstream}},
+		botAuthenticator: authenticator,
+		allowedMethods:   newMethodAllowlist(nil),
+	}
+	handler := proxy.metricHandler()
+
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"id":1,"method":"eth_chainId"}`, http.StatusOK},
+		{`{"id":"x","method":"debug_traceCall"}`, http.StatusForbidden},
+		{`{"id":null,"method":"debug_traceCall"}`, http.StatusForbidden},
+		{`[{"id":1,"method":"eth_chainId"},{"id":"x","method":"debug_traceCall"}]`, http.StatusForbidden},
+		{`{"id":1,"method":["debug_traceCall"]}`, http.StatusBadRequest},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "http://proxy", bytes.NewBufferString(tc.body)))
+		r.Equal(tc.status, recorder.Code, tc.body)
+	}
+	// only the allowed request reached the upstream
+	r.Equal(int32(1), upstream.calls)
+}
@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const alertPruneInterval = time.Hour
+
+// AlertStore returns the store which keeps the alerts for the local queries. It is nil if the
+// alert query api is disabled.
+func (pub *Publisher) AlertStore() store.AlertStore {
+	return pub.alertStore
+}
+
+// storeAlert keeps the alert in the local store so that it can be queried later.
+func (pub *Publisher) storeAlert(alert *protocol.SignedAlert, chainID, blockNumber uint64) {
+	if pub.alertStore == nil {
+		return
+	}
+	b, err := protojson.Marshal(alert)
+	if err != nil {
+		log.WithError(err).Error("failed to encode alert for the local store")
+		return
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, alert.GetAlert().GetTimestamp())
+	if err != nil {
+		timestamp = time.Now().UTC()
+	}
+	var addresses []string
+	for _, address := range alert.GetAlert().GetFinding().GetAddresses() {
+		addresses = append(addresses, strings.ToLower(address))
+	}
+	if err := pub.alertStore.PutAlert(&store.StoredAlert{
+		ID:          alert.GetAlert().GetId(),
+		ChainID:     chainID,
+		BotID:       strings.ToLower(alert.GetAlert().GetAgent().GetId()),
+		Severity:    alert.GetAlert().GetFinding().GetSeverity().String(),
+		Addresses:   addresses,
+		BlockNumber: blockNumber,
+		Timestamp:   timestamp,
+		Alert:       b,
+	}); err != nil {
+		log.WithError(err).WithField("alertId", alert.GetAlert().GetId()).Error("failed to store alert")
+	}
+}
+
+// pruneAlerts deletes the stored alerts which are older than the retention period.
+func (pub *Publisher) pruneAlerts() {
+	retention := time.Duration(pub.cfg.Config.AlertQueryAPI.RetentionHours) * time.Hour
+	ticker := time.NewTicker(alertPruneInterval)
+	defer ticker.Stop()
+	for {
+		if err := pub.alertStore.PruneAlerts(time.Now().Add(-retention)); err != nil {
+			log.WithError(err).Warn("failed to prune stored alerts")
+		}
+		select {
+		case <-pub.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
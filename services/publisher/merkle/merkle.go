@@ -0,0 +1,137 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// the leaves and the nodes are hashed with different prefixes, so that a node can not be proven
+// as a leaf
+var (
+	leafPrefix = []byte{0x00}
+	nodePrefix = []byte{0x01}
+)
+
+// ErrNotFound is returned when the alert is not in the tree.
+var ErrNotFound = errors.New("alert not found in the tree")
+
+// Receipt is signed by the scanner for each published batch, so that an alert can be proven to be
+// in a specific batch with the root.
+type Receipt struct {
+	Batch      string `json:"batch"`
+	ChainID    uint64 `json:"chainId"`
+	BlockStart uint64 `json:"blockStart"`
+	BlockEnd   uint64 `json:"blockEnd"`
+	AlertCount int    `json:"alertCount"`
+	Root       string `json:"root"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// Proof is the inclusion proof of an alert.
+type Proof struct {
+	AlertID string `json:"alertId"`
+	Leaf    string `json:"leaf"`
+	// the sibling hashes from the leaf to the root
+	Path []string `json:"path"`
+	Root string   `json:"root"`
+}
+
+// Leaf returns the leaf hash of the alert.
+func Leaf(alertID string) common.Hash {
+	return crypto.Keccak256Hash(leafPrefix, []byte(alertID))
+}
+
+// hashPair hashes the nodes in the sorted order, so that the proofs do not need the positions.
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(nodePrefix, a.Bytes(), b.Bytes())
+}
+
+// Tree is a Merkle tree of the alert IDs. The last node of an odd level is moved up as is.
+type Tree struct {
+	alertIDs []string
+	// the levels from the leaves to the root
+	levels [][]common.Hash
+}
+
+// NewTree builds the tree from the alert IDs in the given order.
+func NewTree(alertIDs []string) *Tree {
+	tree := &Tree{alertIDs: alertIDs}
+	level := make([]common.Hash, len(alertIDs))
+	for i, alertID := range alertIDs {
+		level[i] = Leaf(alertID)
+	}
+	tree.levels = append(tree.levels, level)
+	for len(level) > 1 {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashPair(level[i], level[i+1]))
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree
+}
+
+// Root returns the root of the tree. It is the zero hash if the tree has no alerts.
+func (tree *Tree) Root() common.Hash {
+	top := tree.levels[len(tree.levels)-1]
+	if len(top) == 0 {
+		return common.Hash{}
+	}
+	return top[0]
+}
+
+// Proof generates the inclusion proof of the alert.
+func (tree *Tree) Proof(alertID string) (*Proof, error) {
+	index := -1
+	for i, id := range tree.alertIDs {
+		if id == alertID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrNotFound
+	}
+	proof := &Proof{
+		AlertID: alertID,
+		Leaf:    tree.levels[0][index].Hex(),
+		Root:    tree.Root().Hex(),
+		Path:    []string{},
+	}
+	for _, level := range tree.levels[:len(tree.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Path = append(proof.Path, level[sibling].Hex())
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify verifies that the proof leads from the alert to the root.
+func Verify(proof *Proof) error {
+	leaf := Leaf(proof.AlertID)
+	if leaf.Hex() != proof.Leaf {
+		return fmt.Errorf("the leaf does not match the alert")
+	}
+	hash := leaf
+	for _, sibling := range proof.Path {
+		hash = hashPair(hash, common.HexToHash(sibling))
+	}
+	if hash.Hex() != proof.Root {
+		return fmt.Errorf("the proof does not lead to the root")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlertIDs(n int) (alertIDs []string) {
+	for i := 0; i < n; i++ {
+		alertIDs = append(alertIDs, fmt.Sprintf("0xalert%d", i))
+	}
+	return
+}
+
+func TestTree(t *testing.T) {
+	r := require.New(t)
+
+	for n := 1; n <= 9; n++ {
+		alertIDs := testAlertIDs(n)
+		tree := NewTree(alertIDs)
+		for _, alertID := range alertIDs {
+			proof, err := tree.Proof(alertID)
+			r.NoError(err)
+			r.Equal(tree.Root().Hex(), proof.Root)
+			r.NoError(Verify(proof), "%d alerts", n)
+		}
+	}
+
+	// a single alert is the root
+	r.Equal(Leaf("0xalert0"), NewTree(testAlertIDs(1)).Root())
+	r.Equal(common.Hash{}, NewTree(nil).Root())
+
+	_, err := NewTree(testAlertIDs(3)).Proof("0xunknown")
+	r.ErrorIs(err, ErrNotFound)
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	tree := NewTree(testAlertIDs(5))
+	proof, err := tree.Proof("0xalert2")
+	r.NoError(err)
+
+	// another alert
+	invalid := *proof
+	invalid.AlertID = "0xalert3"
+	r.Error(Verify(&invalid))
+
+	// another root
+	invalid = *proof
+	invalid.Root = NewTree(testAlertIDs(4)).Root().Hex()
+	r.Error(Verify(&invalid))
+
+	// a node can not be proven as a leaf
+	invalid = *proof
+	invalid.Path = proof.Path[1:]
+	invalid.Leaf = hashPair(Leaf("0xalert2"), common.HexToHash(proof.Path[0])).Hex()
+	r.Error(Verify(&invalid))
+}
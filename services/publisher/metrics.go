@@ -1,7 +1,9 @@
 package publisher
 
 import (
+	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,21 +12,44 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// MaxMetricSamples is the limit of the data points which are kept for each metric of a bot in
+// a bucket to calculate the percentiles. The counts, the sums and the max values are exact.
+const MaxMetricSamples = 1000
+
+// latencyPercentiles are published as separate summaries for the latency metrics, in addition
+// to the P95 of each summary. The percentile is the max, the average and the P95 of the summary.
+var latencyPercentiles = []struct {
+	suffix string
+	p      int
+}{
+	{suffix: "p50", p: 50},
+	{suffix: "p99", p: 99},
+}
+
 // AgentMetricsAggregator aggregates agents' metrics and produces a list of summary of them when flushed.
 type AgentMetricsAggregator struct {
 	buckets        []*metricsBucket
 	bucketInterval time.Duration
 	lastFlush      time.Time
+	rand           *rand.Rand
 	mu             sync.RWMutex
 }
 
 type metricsBucket struct {
-	Time           time.Time
+	Time time.Time
+	// a sample of the data points of each metric
 	MetricCounters map[string][]uint32
 	MetricDetails  map[string]string
+	metricTotals   map[string]*metricTotals
 	protocol.AgentMetrics
 }
 
+type metricTotals struct {
+	count uint32
+	sum   float64
+	max   float64
+}
+
 func (mb *metricsBucket) CreateAndGetSummary(name string) *protocol.MetricSummary {
 	for _, summary := range mb.Metrics {
 		if summary.Name == name {
@@ -42,6 +67,7 @@ func NewMetricsAggregator(bucketInterval time.Duration) *AgentMetricsAggregator
 		mu:             sync.RWMutex{},
 		bucketInterval: bucketInterval,
 		lastFlush:      time.Now(), // avoid flushing immediately
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -60,6 +86,7 @@ func (ama *AgentMetricsAggregator) findBucket(agentID string, t time.Time) *metr
 		Time:           bucketTime,
 		MetricCounters: make(map[string][]uint32),
 		MetricDetails:  make(map[string]string),
+		metricTotals:   make(map[string]*metricTotals),
 	}
 	bucket.AgentId = agentID
 	bucket.Timestamp = utils.FormatTime(bucketTime)
@@ -84,7 +111,7 @@ func (ama *AgentMetricsAggregator) AddAgentMetrics(ms *protocol.AgentMetricList)
 	for _, m := range ms.Metrics {
 		t, _ := time.Parse(time.RFC3339, m.Timestamp)
 		bucket := ama.findBucket(m.AgentId, t)
-		bucket.MetricCounters[m.Name] = append(bucket.MetricCounters[m.Name], uint32(m.Value))
+		ama.addDataPoint(bucket, m.Name, uint32(m.Value))
 		if m.Details != "" {
 			bucket.MetricDetails[m.Name] = m.Details
 		}
@@ -92,6 +119,30 @@ func (ama *AgentMetricsAggregator) AddAgentMetrics(ms *protocol.AgentMetricList)
 	return nil
 }
 
+// addDataPoint adds the value to the totals and to the sample of the metric. The sample is
+// a uniform random sample of the data points after the limit is reached. The lock should be held.
+func (ama *AgentMetricsAggregator) addDataPoint(bucket *metricsBucket, name string, value uint32) {
+	totals, ok := bucket.metricTotals[name]
+	if !ok {
+		totals = &metricTotals{}
+		bucket.metricTotals[name] = totals
+	}
+	totals.count++
+	totals.sum += float64(value)
+	if float64(value) > totals.max {
+		totals.max = float64(value)
+	}
+
+	samples := bucket.MetricCounters[name]
+	if len(samples) < MaxMetricSamples {
+		bucket.MetricCounters[name] = append(samples, value)
+		return
+	}
+	if i := ama.rand.Int63n(int64(totals.count)); i < MaxMetricSamples {
+		samples[i] = value
+	}
+}
+
 // ForceFlush flushes without asking questions
 func (ama *AgentMetricsAggregator) ForceFlush() []*protocol.AgentMetrics {
 	ama.mu.Lock()
@@ -158,14 +209,50 @@ func (allMetrics allAgentMetrics) PrepareMetrics() {
 				summary.Max = maxDataPoint(list)
 				summary.P95 = calcP95(list)
 				summary.Sum = sumNums(list)
+				if totals, ok := agentMetrics.metricTotals[metricName]; ok && totals.count > summary.Count {
+					summary.Count = totals.count
+					summary.Average = avgTotals(totals)
+					summary.Max = totals.max
+					summary.Sum = totals.sum
+				}
 				if details, ok := agentMetrics.MetricDetails[metricName]; ok {
 					summary.Details = details
 				}
+				if strings.HasSuffix(metricName, ".latency") {
+					agentMetrics.addPercentiles(metricName, summary.Count, list)
+				}
 			}
 		}
 	}
 }
 
+// addPercentiles adds the percentile summaries of the latency metric. The data is sorted while
+// calculating the P95.
+func (mb *metricsBucket) addPercentiles(metricName string, count uint32, sorted []uint32) {
+	for _, percentile := range latencyPercentiles {
+		value := float64(sorted[nearestRank(len(sorted), percentile.p)-1])
+		summary := mb.CreateAndGetSummary(metricName + "." + percentile.suffix)
+		summary.Count = count
+		summary.Max = value
+		summary.Average = value
+		summary.P95 = value
+	}
+}
+
+// nearestRank returns the nearest rank of the percentile in n values.
+func nearestRank(n, p int) int {
+	rank := (p*n + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return rank
+}
+
+func avgTotals(totals *metricTotals) float64 {
+	f, _ := decimal.NewFromFloat(totals.sum).Div(decimal.NewFromInt(int64(totals.count))).Round(2).Float64()
+	return f
+}
+
 func avgMetricArray(data []uint32) float64 {
 	sum := decimal.NewFromInt(0)
 	for _, dataPoint := range data {
@@ -101,3 +101,52 @@ func TestAgentMetricsAggregator_math(t *testing.T) {
 	}
 
 }
+
+func TestAgentMetricsAggregator_LatencyPercentiles(t *testing.T) {
+	var metrics []*protocol.AgentMetric
+	for i := 1; i <= 100; i++ {
+		metrics = append(metrics, &protocol.AgentMetric{
+			AgentId:   "agentID",
+			Timestamp: utils.FormatTime(testNow),
+			Name:      "tx.latency",
+			Value:     float64(i),
+		})
+	}
+
+	aggregator := publisher.NewMetricsAggregator(testBucketInterval)
+	assert.NoError(t, aggregator.AddAgentMetrics(&protocol.AgentMetricList{Metrics: metrics}))
+	res := aggregator.ForceFlush()
+
+	assert.Len(t, res, 1)
+	summaries := make(map[string]*protocol.MetricSummary)
+	for _, summary := range res[0].Metrics {
+		summaries[summary.Name] = summary
+	}
+	assert.Len(t, summaries, 3)
+	assert.Equal(t, float64(95), summaries["tx.latency"].P95)
+	assert.Equal(t, float64(50), summaries["tx.latency.p50"].Average)
+	assert.Equal(t, float64(99), summaries["tx.latency.p99"].Average)
+	assert.Equal(t, uint32(100), summaries["tx.latency.p99"].Count)
+}
+
+func TestAgentMetricsAggregator_MaxSamples(t *testing.T) {
+	var metrics []*protocol.AgentMetric
+	for i := 0; i < publisher.MaxMetricSamples*3; i++ {
+		metrics = append(metrics, &protocol.AgentMetric{
+			AgentId:   "agentID",
+			Timestamp: utils.FormatTime(testNow),
+			Name:      "tx.request",
+			Value:     2,
+		})
+	}
+
+	aggregator := publisher.NewMetricsAggregator(testBucketInterval)
+	assert.NoError(t, aggregator.AddAgentMetrics(&protocol.AgentMetricList{Metrics: metrics}))
+	res := aggregator.ForceFlush()
+
+	// the totals are exact although the data points are sampled
+	assert.Len(t, res, 1)
+	assert.Equal(t, uint32(publisher.MaxMetricSamples*3), res[0].Metrics[0].Count)
+	assert.Equal(t, float64(publisher.MaxMetricSamples*6), res[0].Metrics[0].Sum)
+	assert.Equal(t, float64(2), res[0].Metrics[0].Average)
+}
@@ -0,0 +1,92 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/services/publisher/merkle"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const proofPruneInterval = time.Hour
+
+// BatchProofStore returns the store which keeps the proofs of the published batches. It is nil if
+// the batch proofs are disabled.
+func (pub *Publisher) BatchProofStore() store.BatchProofStore {
+	return pub.proofStore
+}
+
+// batchAlertIDs returns the IDs of all alerts in the batch in the order of the batch.
+func batchAlertIDs(batch *protocol.AlertBatch) []string {
+	var alertIDs []string
+	add := func(results []*protocol.AgentAlerts) {
+		for _, agentAlerts := range results {
+			for _, alert := range agentAlerts.Alerts {
+				alertIDs = append(alertIDs, alert.GetAlert().GetId())
+			}
+		}
+	}
+	for _, blockResults := range batch.Results {
+		add(blockResults.Results)
+		for _, txResults := range blockResults.Transactions {
+			add(txResults.Results)
+		}
+	}
+	for _, combinationResults := range batch.CombinationAlerts {
+		add(combinationResults.Results)
+	}
+	add(batch.PrivateAlerts)
+	return alertIDs
+}
+
+// makeBatchProof builds the Merkle tree of the alerts in the batch and signs the receipt with the
+// root.
+func (pub *Publisher) makeBatchProof(batch *protocol.AlertBatch, ref string) (*store.BatchProof, *merkle.Receipt, error) {
+	alertIDs := batchAlertIDs(batch)
+	now := time.Now().UTC()
+	receipt := &merkle.Receipt{
+		Batch:      ref,
+		ChainID:    batch.ChainId,
+		BlockStart: batch.BlockStart,
+		BlockEnd:   batch.BlockEnd,
+		AlertCount: len(alertIDs),
+		Root:       merkle.NewTree(alertIDs).Root().Hex(),
+		Timestamp:  now.Format(time.RFC3339),
+	}
+	b, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode batch receipt: %v", err)
+	}
+	signature, err := security.SignBytes(pub.cfg.Key, b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign batch receipt: %v", err)
+	}
+	return &store.BatchProof{
+		Batch:     ref,
+		Receipt:   string(b),
+		Signature: signature,
+		AlertIDs:  alertIDs,
+		Timestamp: now,
+	}, receipt, nil
+}
+
+// pruneProofs deletes the batch proofs which are older than the retention period.
+func (pub *Publisher) pruneProofs() {
+	retention := time.Duration(pub.cfg.PublisherConfig.Proofs.RetentionHours) * time.Hour
+	ticker := time.NewTicker(proofPruneInterval)
+	defer ticker.Stop()
+	for {
+		if err := pub.proofStore.PruneBatchProofs(time.Now().Add(-retention)); err != nil {
+			log.WithError(err).Warn("failed to prune batch proofs")
+		}
+		select {
+		case <-pub.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/services/publisher/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func testAgentAlerts(alertIDs ...string) []*protocol.AgentAlerts {
+	agentAlerts := &protocol.AgentAlerts{}
+	for _, alertID := range alertIDs {
+		agentAlerts.Alerts = append(agentAlerts.Alerts, &protocol.SignedAlert{Alert: &protocol.Alert{Id: alertID}})
+	}
+	return []*protocol.AgentAlerts{agentAlerts}
+}
+
+func TestMakeBatchProof(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	key := &keystore.Key{PrivateKey: privateKey, Address: crypto.PubkeyToAddress(privateKey.PublicKey)}
+	pub := &Publisher{cfg: PublisherConfig{Key: key}}
+
+	batch := &protocol.AlertBatch{
+		ChainId:    1,
+		BlockStart: 10,
+		BlockEnd:   20,
+		Results: []*protocol.BlockResults{
+			{
+				Results: testAgentAlerts("block-alert"),
+				Transactions: []*protocol.TransactionResults{
+					{Results: testAgentAlerts("tx-alert1", "tx-alert2")},
+				},
+			},
+		},
+		CombinationAlerts: []*protocol.CombinationAlertResults{{Results: testAgentAlerts("combination-alert")}},
+		PrivateAlerts:     testAgentAlerts("private-alert"),
+	}
+	alertIDs := []string{"block-alert", "tx-alert1", "tx-alert2", "combination-alert", "private-alert"}
+	r.Equal(alertIDs, batchAlertIDs(batch))
+
+	batchProof, receipt, err := pub.makeBatchProof(batch, "batch-ref")
+	r.NoError(err)
+	r.Equal(alertIDs, batchProof.AlertIDs)
+	r.Equal("batch-ref", batchProof.Batch)
+	r.Equal(5, receipt.AlertCount)
+
+	// the receipt is signed by the scanner and has the root of the alerts
+	r.NoError(security.VerifySignature([]byte(batchProof.Receipt), key.Address.Hex(), batchProof.Signature.Signature))
+	var signedReceipt merkle.Receipt
+	r.NoError(json.Unmarshal([]byte(batchProof.Receipt), &signedReceipt))
+	r.Equal(*receipt, signedReceipt)
+
+	proof, err := merkle.NewTree(batchProof.AlertIDs).Proof("tx-alert2")
+	r.NoError(err)
+	r.Equal(signedReceipt.Root, proof.Root)
+	r.NoError(merkle.Verify(proof))
+}
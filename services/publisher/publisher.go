@@ -9,6 +9,9 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"reflect"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -22,6 +25,7 @@ import (
 	"github.com/forta-network/forta-core-go/ipfs"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/protocol/transform"
+	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/release"
 	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-core-go/utils"
@@ -31,6 +35,8 @@ import (
 	"github.com/forta-network/forta-node/clients/storagegrpc"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/publisher/merkle"
+	"github.com/forta-network/forta-node/services/publisher/sinks"
 	"github.com/forta-network/forta-node/services/publisher/webhooklog"
 	"github.com/forta-network/forta-node/services/storage"
 	"github.com/forta-network/forta-node/store"
@@ -66,6 +72,12 @@ type Publisher struct {
 	batchRefStore    store.StringStore
 	lastReceiptStore store.StringStore
 
+	// unpublishedStore keeps the batches until they are published so they are retried after restarts
+	unpublishedStore store.BatchStore
+	storedBatches    map[*protocol.AlertBatch]string
+	retryAttempts    map[*protocol.AlertBatch]int
+	storedBatchesMu  sync.Mutex
+
 	server *grpc.Server
 
 	initialize    sync.Once
@@ -76,6 +88,9 @@ type Publisher struct {
 	latestChainID uint64
 	notifCh       chan *protocol.NotifyRequest
 	batchCh       chan *protocol.AlertBatch
+	flushCh       chan chan struct{}
+
+	pendingBatches sync.WaitGroup
 
 	lastBatchPublish        health.TimeTracker
 	lastBatchPublishAttempt health.TimeTracker
@@ -84,6 +99,22 @@ type Publisher struct {
 	lastBatchPublishErr     health.ErrorTracker
 	lastMetricsFlush        health.TimeTracker
 
+	publishStats   PublishStats
+	publishStatsMu sync.RWMutex
+
+	// receive all alerts in addition to the batches
+	sinks []*sinks.BufferedSink
+	// the configs of the sinks by name, to find the changed sinks when the config is reloaded
+	sinkConfigs map[string]config.AlertSinkConfig
+	// decides the destinations of the alerts by the severity
+	router  *alertRouter
+	sinksMu sync.RWMutex
+
+	// keeps the alerts for the local queries if the alert query api is enabled
+	alertStore store.AlertStore
+	// keeps the Merkle proofs of the published batches if the batch proofs are enabled
+	proofStore store.BatchProofStore
+
 	// these help following single ticker and keep send intervals on track
 	batchTicker          *time.Ticker
 	lastBatchReady       time.Time
@@ -93,11 +124,15 @@ type Publisher struct {
 	botConfigs  []config.AgentConfig
 	botConfigMu sync.RWMutex
 
-	latestBlockInput   uint64
+	// the latest block inputs by chain id
+	latestBlockInputs  map[uint64]uint64
 	latestBlockInputMu sync.RWMutex
 
 	latestInspectionResults   *protocol.InspectionResults
 	latestInspectionResultsMu sync.RWMutex
+
+	// verifies the scanner if there is a policy for the unverified scanners
+	scannerCheck *scannerCheck
 }
 
 // LocalAlertClient sends the local alerts.
@@ -135,8 +170,9 @@ func (pub *Publisher) Notify(ctx context.Context, req *protocol.NotifyRequest) (
 }
 
 func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bool, err error) {
-	// flush only if we are publishing so we can make the best use of aggregated metrics
-	if _, skip := pub.shouldSkipPublishing(batch); !skip {
+	// flush only if we are publishing so we can make the best use of aggregated metrics, and keep
+	// the metrics of the earlier attempts if this batch is retried
+	if _, skip := pub.shouldSkipPublishing(batch); !skip && len(batch.Metrics) == 0 {
 		var flushed bool
 		batch.Metrics, flushed = pub.metricsAggregator.TryFlush()
 		// detect the active bots from metrics
@@ -170,7 +206,7 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 
 	// use the latest block input from scanner, fall back to latest block number from the batch
 	pub.latestBlockInputMu.RLock()
-	batch.LatestBlockInput = pub.latestBlockInput
+	batch.LatestBlockInput = pub.latestBlockInputs[batch.ChainId]
 	pub.latestBlockInputMu.RUnlock()
 	if batch.LatestBlockInput == 0 {
 		batch.LatestBlockInput = batch.BlockEnd
@@ -202,6 +238,20 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, nil
 	}
 
+	if pub.scannerCheck.shouldRefuse() {
+		const reason = "skipping batch, because the scanner is not registered or staked enough"
+		log.WithFields(
+			log.Fields{
+				"blockStart": batch.BlockStart,
+				"blockEnd":   batch.BlockEnd,
+				"alertCount": batch.AlertCount,
+			},
+		).Warn(reason)
+		pub.lastBatchSkip.Set()
+		pub.lastBatchSkipReason.Set(reason)
+		return false, nil
+	}
+
 	if reason, skip := pub.shouldSkipPublishing(batch); skip {
 		log.WithField("reason", reason).Info("skipping batch")
 		pub.lastBatchSkip.Set()
@@ -289,11 +339,25 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, err
 	}
 
-	scannerJwt, err := security.CreateScannerJWT(
-		pub.cfg.Key, map[string]interface{}{
-			"batch": cid,
-		},
-	)
+	claims := map[string]interface{}{
+		"batch": cid,
+	}
+	var batchProof *store.BatchProof
+	if pub.proofStore != nil {
+		var receipt *merkle.Receipt
+		batchProof, receipt, err = pub.makeBatchProof(batch, cid)
+		if err != nil {
+			logger.WithError(err).Error("failed to make batch proof")
+			return false, err
+		}
+		claims["alertsRoot"] = receipt.Root
+		logger = logger.WithField("alertsRoot", receipt.Root)
+	}
+	if pub.scannerCheck.shouldMark() {
+		claims["unverified"] = "true"
+		logger = logger.WithField("unverified", true)
+	}
+	scannerJwt, err := security.CreateScannerJWT(pub.cfg.Key, claims)
 
 	if err != nil {
 		logger.WithError(err).Error("failed to sign cid")
@@ -318,6 +382,12 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, fmt.Errorf("failed to send the alert tx: %v", err)
 	}
 
+	if batchProof != nil {
+		if err := pub.proofStore.PutBatchProof(batchProof); err != nil {
+			logger.WithError(err).Error("failed to store batch proof")
+		}
+	}
+
 	if resp.SignedReceipt != nil {
 		// store off receipt id
 		if err := pub.lastReceiptStore.Put(resp.ReceiptID); err != nil {
@@ -420,6 +490,9 @@ func (pub *Publisher) registerMessageHandlers() {
 	pub.messageClient.Subscribe(messaging.SubjectScannerAlert, messaging.ScannerHandler(pub.handleScannerAlert))
 	pub.messageClient.Subscribe(messaging.SubjectInspectionDone, messaging.InspectionResultsHandler(pub.handleInspectionResults))
 	pub.messageClient.Subscribe(messaging.SubjectAgentsStatusRunning, messaging.AgentsHandler(pub.handleRunningBots))
+	if pub.cfg.Config.AdminAPI.Enable {
+		pub.messageClient.Subscribe(messaging.SubjectAdminLogLevel, messaging.LogLevelHandler(messaging.SetLogLevel))
+	}
 }
 
 func (pub *Publisher) handleRunningBots(payload messaging.AgentPayload) error {
@@ -433,18 +506,24 @@ func (pub *Publisher) handleScannerBlock(payload messaging.ScannerPayload) error
 	pub.latestBlockInputMu.Lock()
 	defer pub.latestBlockInputMu.Unlock()
 
+	chainID := payload.ChainID
+	if chainID == 0 {
+		chainID = uint64(pub.cfg.ChainID)
+	}
+	latestBlockInput := pub.latestBlockInputs[chainID]
 	logger := log.WithFields(
 		log.Fields{
+			"chainId":              chainID,
 			"newLatestBlockInput":  payload.LatestBlockInput,
-			"prevLatestBlockInput": pub.latestBlockInput,
+			"prevLatestBlockInput": latestBlockInput,
 		},
 	)
-	if payload.LatestBlockInput < pub.latestBlockInput {
+	if payload.LatestBlockInput < latestBlockInput {
 		logger.Warn("skipping scanner update (lower than previous)")
 		return nil
 	}
 	logger.Info("received scanner update")
-	pub.latestBlockInput = payload.LatestBlockInput
+	pub.latestBlockInputs[chainID] = payload.LatestBlockInput
 	return nil
 }
 
@@ -471,6 +550,194 @@ func (pub *Publisher) publishBatches() {
 		if err != nil {
 			log.Errorf("failed to publish alert batch: %v", err)
 		}
+		pub.countBatch(batch, published, err)
+		if pub.handleStoredBatch(batch, published, err) {
+			// still pending until it is published
+			go pub.retryLater(batch)
+			continue
+		}
+		pub.pendingBatches.Done()
+	}
+}
+
+// retryLater enqueues the batch again after the backoff. The batch stays in the store if
+// the node stops before that, and it is retried after the restart.
+func (pub *Publisher) retryLater(batch *protocol.AlertBatch) {
+	pub.storedBatchesMu.Lock()
+	pub.retryAttempts[batch]++
+	attempts := pub.retryAttempts[batch]
+	pub.storedBatchesMu.Unlock()
+
+	backoff := retryBackoff(pub.cfg.PublisherConfig.Retry, attempts)
+	log.WithFields(log.Fields{
+		"blockStart": batch.BlockStart,
+		"blockEnd":   batch.BlockEnd,
+		"alertCount": batch.AlertCount,
+		"attempts":   attempts,
+		"backoff":    backoff,
+	}).Warn("retrying unpublished batch later")
+
+	select {
+	case <-pub.ctx.Done():
+		pub.pendingBatches.Done()
+		return
+	case <-time.After(backoff):
+	}
+	select {
+	case <-pub.ctx.Done():
+		pub.pendingBatches.Done()
+	case pub.batchCh <- batch:
+	}
+}
+
+// retryingBatches returns the count of the batches which are waiting to be published again.
+func (pub *Publisher) retryingBatches() int {
+	pub.storedBatchesMu.Lock()
+	defer pub.storedBatchesMu.Unlock()
+
+	return len(pub.retryAttempts)
+}
+
+// retryBackoff returns the backoff before the next attempt after the failed attempts.
+func retryBackoff(cfg config.RetryConfig, attempts int) time.Duration {
+	backoff := time.Duration(cfg.InitialBackoffSeconds) * time.Second
+	maxBackoff := time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// PublishStats contains the counts of the batches handled since the start.
+type PublishStats struct {
+	PublishedBatches uint64    `json:"publishedBatches"`
+	PublishedAlerts  uint64    `json:"publishedAlerts"`
+	SkippedBatches   uint64    `json:"skippedBatches"`
+	FailedBatches    uint64    `json:"failedBatches"`
+	LastPublished    time.Time `json:"lastPublished,omitempty"`
+}
+
+func (pub *Publisher) countBatch(batch *protocol.AlertBatch, published bool, err error) {
+	pub.publishStatsMu.Lock()
+	defer pub.publishStatsMu.Unlock()
+
+	switch {
+	case published:
+		pub.publishStats.PublishedBatches++
+		pub.publishStats.PublishedAlerts += uint64(batch.AlertCount)
+		pub.publishStats.LastPublished = time.Now().UTC()
+	case err != nil:
+		pub.publishStats.FailedBatches++
+	default:
+		pub.publishStats.SkippedBatches++
+	}
+}
+
+// PublishStats returns the publish counts.
+func (pub *Publisher) PublishStats() PublishStats {
+	pub.publishStatsMu.RLock()
+	defer pub.publishStatsMu.RUnlock()
+
+	return pub.publishStats
+}
+
+// storeBatch persists the batch before it is published, so that the alerts are not lost if
+// the node stops before the batch is acknowledged. The batches without alerts are stored only
+// if they fail to publish.
+func (pub *Publisher) storeBatch(batch *protocol.AlertBatch) {
+	if pub.unpublishedStore == nil || batch.AlertCount == 0 {
+		return
+	}
+
+	pub.storedBatchesMu.Lock()
+	defer pub.storedBatchesMu.Unlock()
+
+	pub.putBatch(batch)
+}
+
+// putBatch puts the batch to the store if it is not stored yet. The lock should be held.
+func (pub *Publisher) putBatch(batch *protocol.AlertBatch) {
+	if _, stored := pub.storedBatches[batch]; stored {
+		return
+	}
+	id, err := pub.unpublishedStore.Put(batch)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"blockStart": batch.BlockStart,
+			"blockEnd":   batch.BlockEnd,
+			"alertCount": batch.AlertCount,
+		}).WithError(err).Error("failed to store unpublished batch")
+		return
+	}
+	pub.storedBatches[batch] = id
+}
+
+// handleStoredBatch keeps the batch in the store if it failed to publish and deletes it from
+// the store after it is published or skipped. It tells if the batch should be retried.
+func (pub *Publisher) handleStoredBatch(batch *protocol.AlertBatch, published bool, publishErr error) (retry bool) {
+	if pub.unpublishedStore == nil {
+		return false
+	}
+
+	pub.storedBatchesMu.Lock()
+	defer pub.storedBatchesMu.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"blockStart": batch.BlockStart,
+		"blockEnd":   batch.BlockEnd,
+		"alertCount": batch.AlertCount,
+	})
+
+	if publishErr != nil && !published {
+		pub.putBatch(batch)
+		return true
+	}
+
+	delete(pub.retryAttempts, batch)
+	id, stored := pub.storedBatches[batch]
+	if !stored {
+		return false
+	}
+	if err := pub.unpublishedStore.Delete(id); err != nil {
+		logger.WithError(err).Error("failed to delete stored batch")
+		return false
+	}
+	delete(pub.storedBatches, batch)
+	logger.WithField("storedBatch", id).Debug("deleted published batch from the store")
+	return false
+}
+
+// restoreBatches enqueues the batches which could not be published before the last restart.
+func (pub *Publisher) restoreBatches() {
+	if pub.unpublishedStore == nil {
+		return
+	}
+
+	storedBatches, err := pub.unpublishedStore.List()
+	if err != nil {
+		log.WithError(err).Error("failed to list stored batches")
+		return
+	}
+	if len(storedBatches) == 0 {
+		return
+	}
+	log.WithField("count", len(storedBatches)).Info("retrying unpublished batches")
+
+	pub.storedBatchesMu.Lock()
+	for _, storedBatch := range storedBatches {
+		pub.storedBatches[storedBatch.Batch] = storedBatch.ID
+	}
+	pub.storedBatchesMu.Unlock()
+
+	for _, storedBatch := range storedBatches {
+		pub.pendingBatches.Add(1)
+		pub.batchCh <- storedBatch.Batch
 	}
 }
 
@@ -711,13 +978,46 @@ func (tr *TransactionResults) GetAgentAlerts(agent *protocol.AgentInfo) *protoco
 	return aa
 }
 
+// addLatencyMetrics adds the latency breakdown of the alert to the metrics of the bot.
+func (pub *Publisher) addLatencyMetrics(alert *protocol.SignedAlert) {
+	latency, ok := clients.LatencyBreakdownFromMetadata(alert.Alert.Metadata)
+	if !ok || alert.Alert.Agent == nil {
+		return
+	}
+	pub.metricsAggregator.AddAgentMetrics(&protocol.AgentMetricList{
+		Metrics: metrics.GetAlertLatencyMetrics(alert.Alert.Agent.Id, time.Now(), latency),
+	})
+}
+
+// notifChainID returns the chain of the notification. The combiner alerts are
+// batched with the main chain alerts.
+func (pub *Publisher) notifChainID(notif *protocol.NotifyRequest) uint64 {
+	var chainIDHex string
+	if notif.EvalBlockRequest != nil {
+		chainIDHex = notif.EvalBlockRequest.Event.GetNetwork().GetChainId()
+	} else if notif.EvalTxRequest != nil {
+		chainIDHex = notif.EvalTxRequest.Event.GetNetwork().GetChainId()
+	}
+	chainID, _ := hexutil.DecodeUint64(chainIDHex)
+	if chainID == 0 {
+		return uint64(pub.cfg.ChainID)
+	}
+	return chainID
+}
+
+// prepareLatestBatch prepares a batch for the main chain and for each of the other chains
+// which was scanned since the last batch.
 func (pub *Publisher) prepareLatestBatch() {
-	batch := (*BatchData)(&protocol.AlertBatch{ChainId: uint64(pub.cfg.ChainID)})
+	mainChainID := uint64(pub.cfg.ChainID)
+	batches := map[uint64]*BatchData{
+		mainChainID: {ChainId: mainChainID},
+	}
 
 	var (
 		timedOut  bool
 		batchTime time.Time
 		i         int
+		flushDone chan struct{}
 	)
 	for i < pub.batchLimit {
 		select {
@@ -725,12 +1025,19 @@ func (pub *Publisher) prepareLatestBatch() {
 			alert := notif.SignedAlert
 			hasAlert := alert != nil
 			if hasAlert {
-				log.WithField("alertId", alert.Alert.Id).Debug("publisher received alert")
+				log.WithFields(log.Fields{
+					"alertId":   alert.Alert.Id,
+					"requestId": alert.Alert.Tags["requestId"],
+				}).Debug("publisher received alert")
 			}
 
+			// the alerts which are not routed to the batch are still sent to the other destinations
+			router := pub.alertRouter()
+			batched := hasAlert && router.sendsTo(alert, config.AlertRouteBatch)
+
 			// Notifications with empty alerts shouldn't be taken into account while limiting the batch.
 			// Otherwise, we create too many batches very quickly.
-			if hasAlert {
+			if batched {
 				i++
 			}
 
@@ -748,6 +1055,23 @@ func (pub *Publisher) prepareLatestBatch() {
 				log.Errorf("failed to parse alert notif block number: %v", err)
 				continue
 			}
+
+			chainID := pub.notifChainID(notif)
+			if hasAlert {
+				pub.addLatencyMetrics(alert)
+				pub.sendToSinks(alert, chainID)
+				if router.sendsTo(alert, config.AlertRouteLocal) {
+					pub.storeAlert(alert, chainID, notifBlockNum)
+				}
+			}
+			if hasAlert && !batched {
+				notif = withoutAlert(notif)
+			}
+			batch, ok := batches[chainID]
+			if !ok {
+				batch = &BatchData{ChainId: chainID}
+				batches[chainID] = batch
+			}
 			if batch.BlockStart == 0 || (batch.BlockStart > 0 && notifBlockNum < batch.BlockStart) {
 				batch.BlockStart = notifBlockNum
 			}
@@ -755,16 +1079,18 @@ func (pub *Publisher) prepareLatestBatch() {
 				batch.BlockEnd = notifBlockNum
 			}
 
-			if hasAlert && alert.Alert.Finding.Severity > batch.MaxSeverity {
+			if batched && alert.Alert.Finding.Severity > batch.MaxSeverity {
 				batch.MaxSeverity = alert.Alert.Finding.Severity
 			}
 
 			batch.AppendAlert(notif)
 
+		case flushDone = <-pub.flushCh:
 		case batchTime, timedOut = <-pub.batchTicker.C:
 		}
 
-		if timedOut {
+		// finish the batch with a flush request only after the received notifications are included
+		if timedOut || (flushDone != nil && len(pub.notifCh) == 0) {
 			break
 		}
 	}
@@ -777,12 +1103,144 @@ func (pub *Publisher) prepareLatestBatch() {
 	pub.lastBatchReady = batchTime
 	pub.lastBatchReadyMu.Unlock()
 
-	pub.batchCh <- (*protocol.AlertBatch)(batch)
+	// the main chain batch goes first and the rest are in chain id order
+	chainIDs := make([]uint64, 0, len(batches))
+	for chainID := range batches {
+		if chainID != mainChainID {
+			chainIDs = append(chainIDs, chainID)
+		}
+	}
+	sort.Slice(chainIDs, func(i, j int) bool {
+		return chainIDs[i] < chainIDs[j]
+	})
+	for _, chainID := range append([]uint64{mainChainID}, chainIDs...) {
+		batch := (*protocol.AlertBatch)(batches[chainID])
+		pub.storeBatch(batch)
+		pub.pendingBatches.Add(1)
+		pub.batchCh <- batch
+	}
+	if flushDone != nil {
+		close(flushDone)
+	}
+}
+
+// sendToSinks buffers the alert for the alert sinks, which send the buffered alerts in the background.
+func (pub *Publisher) sendToSinks(alert *protocol.SignedAlert, chainID uint64) {
+	pub.sinksMu.RLock()
+	defer pub.sinksMu.RUnlock()
+
+	if len(pub.sinks) == 0 {
+		return
+	}
+	record, err := sinks.NewRecord(alert, chainID)
+	if err != nil {
+		log.WithError(err).Error("failed to create alert sink record")
+		return
+	}
+	record.Unverified = pub.scannerCheck.shouldMark()
+	for _, sink := range pub.sinks {
+		if !pub.router.sendsTo(alert, sink.Name()) {
+			continue
+		}
+		if err := sink.Add(record); err != nil {
+			log.WithError(err).WithField("sink", sink.Name()).Error("failed to buffer alert for sink")
+		}
+	}
+}
+
+// alertRouter returns the current alert router, which changes when the routes are reloaded.
+func (pub *Publisher) alertRouter() *alertRouter {
+	pub.sinksMu.RLock()
+	defer pub.sinksMu.RUnlock()
+
+	return pub.router
+}
+
+// PrepareSinks creates the alert router and the changed alert sinks of the reloaded config. The returned
+// function replaces the current ones: the removed and the changed sinks are closed before the new
+// sinks start sending the segments in the same dirs.
+func (pub *Publisher) PrepareSinks(cfg config.Config) (func(), error) {
+	router, err := newAlertRouter(cfg.Publish)
+	if err != nil {
+		return nil, err
+	}
+	pub.sinksMu.RLock()
+	currentConfigs := pub.sinkConfigs
+	pub.sinksMu.RUnlock()
+
+	newConfigs := make(map[string]config.AlertSinkConfig)
+	newSinks := make(map[string]sinks.Sink)
+	for _, sinkCfg := range cfg.Publish.Sinks {
+		sinkCfg.URL = utils.ConvertToDockerHostURL(sinkCfg.URL)
+		newConfigs[sinkCfg.Name] = sinkCfg
+		if currentCfg, ok := currentConfigs[sinkCfg.Name]; ok && reflect.DeepEqual(currentCfg, sinkCfg) {
+			continue
+		}
+		sink, err := sinks.New(sinkCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create alert sink %s: %v", sinkCfg.Name, err)
+		}
+		newSinks[sinkCfg.Name] = sink
+	}
+
+	return func() {
+		pub.sinksMu.Lock()
+		defer pub.sinksMu.Unlock()
+
+		timeout := time.Duration(pub.cfg.Config.Scan.ShutdownTimeoutSeconds) * time.Second
+		currentSinks := make(map[string]*sinks.BufferedSink)
+		for _, bufferedSink := range pub.sinks {
+			if _, ok := newSinks[bufferedSink.Name()]; ok || !hasSink(newConfigs, bufferedSink.Name()) {
+				if err := bufferedSink.Close(timeout); err != nil {
+					log.WithError(err).WithField("sink", bufferedSink.Name()).Warn("failed to close alert sink")
+				}
+				continue
+			}
+			currentSinks[bufferedSink.Name()] = bufferedSink
+		}
+		var bufferedSinks []*sinks.BufferedSink
+		for _, sinkCfg := range cfg.Publish.Sinks {
+			if bufferedSink, ok := currentSinks[sinkCfg.Name]; ok {
+				bufferedSinks = append(bufferedSinks, bufferedSink)
+				continue
+			}
+			bufferedSink, err := newBufferedSink(pub.ctx, cfg, newConfigs[sinkCfg.Name], newSinks[sinkCfg.Name])
+			if err != nil {
+				log.WithError(err).WithField("sink", sinkCfg.Name).Error("failed to reload alert sink")
+				continue
+			}
+			bufferedSink.Start()
+			bufferedSinks = append(bufferedSinks, bufferedSink)
+		}
+		pub.sinks = bufferedSinks
+		pub.sinkConfigs = newConfigs
+		pub.router = router
+	}, nil
+}
+
+func hasSink(sinkConfigs map[string]config.AlertSinkConfig, name string) bool {
+	_, ok := sinkConfigs[name]
+	return ok
 }
 
 func (pub *Publisher) Start() error {
+	pub.sinksMu.RLock()
+	for _, sink := range pub.sinks {
+		sink.Start()
+	}
+	pub.sinksMu.RUnlock()
 	go pub.prepareBatches()
 	go pub.publishBatches()
+	go pub.restoreBatches()
+	if pub.alertStore != nil {
+		go pub.pruneAlerts()
+	}
+	if pub.proofStore != nil {
+		go pub.pruneProofs()
+	}
+	if pub.scannerCheck != nil {
+		go pub.scannerCheck.run(pub.ctx)
+	}
 	pub.registerMessageHandlers()
 	return nil
 }
@@ -791,7 +1249,46 @@ func (pub *Publisher) Stop() error {
 	if pub.server != nil {
 		pub.server.Stop()
 	}
-	return nil
+	timeout := time.Duration(pub.cfg.Config.Scan.ShutdownTimeoutSeconds) * time.Second
+	err := pub.flush(timeout)
+	pub.sinksMu.RLock()
+	defer pub.sinksMu.RUnlock()
+	for _, sink := range pub.sinks {
+		if err := sink.Close(timeout); err != nil {
+			log.WithError(err).WithField("sink", sink.Name()).Warn("failed to close alert sink")
+		}
+	}
+	return err
+}
+
+// flush publishes the pending alerts without waiting for the batch interval and
+// waits until all prepared batches are published.
+func (pub *Publisher) flush(timeout time.Duration) error {
+	deadline := time.After(timeout)
+
+	flushDone := make(chan struct{})
+	select {
+	case pub.flushCh <- flushDone:
+	case <-deadline:
+		return fmt.Errorf("timed out after %s while requesting flush", timeout)
+	}
+	select {
+	case <-flushDone:
+	case <-deadline:
+		return fmt.Errorf("timed out after %s while waiting for the last batch", timeout)
+	}
+
+	published := make(chan struct{})
+	go func() {
+		pub.pendingBatches.Wait()
+		close(published)
+	}()
+	select {
+	case <-published:
+		return nil
+	case <-deadline:
+		return fmt.Errorf("timed out after %s while publishing the pending batches", timeout)
+	}
 }
 
 func (pub *Publisher) Name() string {
@@ -800,7 +1297,7 @@ func (pub *Publisher) Name() string {
 
 // Health implements the health.Reporter interface.
 func (pub *Publisher) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		pub.lastBatchPublish.GetReport("event.batch-publish.time"),
 		pub.lastBatchPublishAttempt.GetReport("event.batch-publish-attempt.time"),
 		pub.lastBatchPublishErr.GetReport("event.batch-publish.error"),
@@ -811,7 +1308,18 @@ func (pub *Publisher) Health() health.Reports {
 		},
 		pub.lastBatchSkipReason.GetReport("event.batch-skip.reason"),
 		pub.lastMetricsFlush.GetReport("event.metrics-flush.time"),
+		&health.Report{
+			Name:    "batch.retrying",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(pub.retryingBatches()),
+		},
+	}
+	pub.sinksMu.RLock()
+	defer pub.sinksMu.RUnlock()
+	for _, sink := range pub.sinks {
+		reports = append(reports, sink.Health()...)
 	}
+	return reports
 }
 
 func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
@@ -840,13 +1348,81 @@ func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
 		}
 	}
 
-	return initPublisher(ctx, msgClient, lifecycleMetrics, apiClient, storageClient, PublisherConfig{
+	pub, err := initPublisher(ctx, msgClient, lifecycleMetrics, apiClient, storageClient, PublisherConfig{
 		ChainID:         cfg.ChainID,
 		Key:             key,
 		PublisherConfig: cfg.Publish,
 		ReleaseSummary:  releaseSummary,
 		Config:          cfg,
 	})
+	if err != nil {
+		return nil, err
+	}
+	pub.router, err = newAlertRouter(cfg.Publish)
+	if err != nil {
+		return nil, err
+	}
+	pub.sinks, pub.sinkConfigs, err = initSinks(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	pub.scannerCheck, err = initScannerCheck(ctx, cfg, key)
+	if err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// initScannerCheck creates the scanner check if the unverified scanners are not only warned about.
+func initScannerCheck(ctx context.Context, cfg config.Config, key *keystore.Key) (*scannerCheck, error) {
+	checkCfg := cfg.Registry.ScannerCheck
+	if cfg.LocalModeConfig.Enable || checkCfg.OnUnverified == config.UnverifiedScannerWarn {
+		return nil, nil
+	}
+	regClient, err := store.GetRegistryClient(ctx, cfg, registry.ClientConfig{
+		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
+		ENSAddress: cfg.ENSConfig.ContractAddress,
+		Name:       "publisher-scanner-check",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %v", err)
+	}
+	return newScannerCheck(regClient, key.Address.Hex(), checkCfg)
+}
+
+// initSinks creates the alert sinks which buffer the alerts in the forta dir.
+func initSinks(ctx context.Context, cfg config.Config) ([]*sinks.BufferedSink, map[string]config.AlertSinkConfig, error) {
+	var bufferedSinks []*sinks.BufferedSink
+	sinkConfigs := make(map[string]config.AlertSinkConfig)
+	for _, sinkCfg := range cfg.Publish.Sinks {
+		sinkCfg.URL = utils.ConvertToDockerHostURL(sinkCfg.URL)
+		sink, err := sinks.New(sinkCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create alert sink %s: %v", sinkCfg.Name, err)
+		}
+		bufferedSink, err := newBufferedSink(ctx, cfg, sinkCfg, sink)
+		if err != nil {
+			return nil, nil, err
+		}
+		bufferedSinks = append(bufferedSinks, bufferedSink)
+		sinkConfigs[sinkCfg.Name] = sinkCfg
+	}
+	return bufferedSinks, sinkConfigs, nil
+}
+
+func newBufferedSink(
+	ctx context.Context, cfg config.Config, sinkCfg config.AlertSinkConfig, sink sinks.Sink,
+) (*sinks.BufferedSink, error) {
+	bufferedSink, err := sinks.NewBufferedSink(ctx, sink, sinks.BufferedSinkConfig{
+		Dir:           path.Join(cfg.FortaDir, config.DefaultAlertSinksDirName, sinkCfg.Name),
+		MaxRecords:    sinkCfg.MaxBufferedAlerts,
+		FlushInterval: time.Duration(sinkCfg.FlushIntervalSeconds) * time.Second,
+		Filter:        sinks.NewFilter(sinkCfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert sink buffer %s: %v", sinkCfg.Name, err)
+	}
+	return bufferedSink, nil
 }
 
 func initPublisher(
@@ -869,6 +1445,11 @@ func initPublisher(
 		batchLimit = *cfg.PublisherConfig.Batch.MaxAlerts
 	}
 
+	localStore, err := store.NewLocalStore(path.Join(cfg.Config.FortaDir, ".publisher-db"))
+	if err != nil {
+		return nil, err
+	}
+
 	var localAlertClient LocalAlertClient
 	localAlertDest := cfg.Config.LocalModeConfig.WebhookURL
 	if cfg.Config.LocalModeConfig.Enable && len(localAlertDest) > 0 {
@@ -890,6 +1471,15 @@ func initPublisher(
 		}
 	}
 
+	var alertStore store.AlertStore
+	if cfg.Config.AlertQueryAPI.Enable {
+		alertStore = localStore
+	}
+	var proofStore store.BatchProofStore
+	if cfg.PublisherConfig.Proofs.Enable {
+		proofStore = localStore
+	}
+
 	return &Publisher{
 		ctx:               ctx,
 		cfg:               cfg,
@@ -902,6 +1492,12 @@ func initPublisher(
 		lifecycleMetrics:  lifecycleMetrics,
 		batchRefStore:     store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-batch")),
 		lastReceiptStore:  store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-receipt")),
+		unpublishedStore:  localStore,
+		alertStore:        alertStore,
+		proofStore:        proofStore,
+		storedBatches:     make(map[*protocol.AlertBatch]string),
+		retryAttempts:     make(map[*protocol.AlertBatch]int),
+		latestBlockInputs: make(map[uint64]uint64),
 
 		skipEmpty:     cfg.PublisherConfig.Batch.SkipEmpty,
 		skipPublish:   cfg.PublisherConfig.SkipPublish,
@@ -909,6 +1505,7 @@ func initPublisher(
 		batchLimit:    batchLimit,
 		notifCh:       make(chan *protocol.NotifyRequest, defaultBatchLimit),
 		batchCh:       make(chan *protocol.AlertBatch, defaultBatchBufferSize),
+		flushCh:       make(chan chan struct{}),
 
 		batchTicker: time.NewTicker(batchInterval),
 	}, nil
@@ -1,11 +1,17 @@
 package publisher
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -185,3 +191,245 @@ func TestShouldSkipPublishing(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareLatestBatch_Flush(t *testing.T) {
+	r := require.New(t)
+
+	pub := &Publisher{
+		batchLimit:    10,
+		batchInterval: time.Hour,
+		notifCh:       make(chan *protocol.NotifyRequest, 1),
+		batchCh:       make(chan *protocol.AlertBatch, 1),
+		flushCh:       make(chan chan struct{}),
+		batchTicker:   time.NewTicker(time.Hour),
+	}
+	pub.notifCh <- &protocol.NotifyRequest{
+		SignedAlert: &protocol.SignedAlert{
+			Alert: &protocol.Alert{Id: "alertId", Finding: &protocol.Finding{}},
+		},
+		EvalTxRequest: &protocol.EvaluateTxRequest{
+			Event: &protocol.TransactionEvent{
+				Block: &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+			},
+		},
+		EvalTxResponse: &protocol.EvaluateTxResponse{Private: true},
+		AgentInfo:      &protocol.AgentInfo{Manifest: "agentInfo"},
+	}
+
+	go pub.prepareLatestBatch()
+
+	flushDone := make(chan struct{})
+	pub.flushCh <- flushDone
+	<-flushDone
+
+	batch := <-pub.batchCh
+	r.Len(batch.PrivateAlerts, 1)
+	r.Equal(uint64(1), batch.BlockEnd)
+	pub.pendingBatches.Done()
+}
+
+func TestPrepareLatestBatch_MultiChain(t *testing.T) {
+	r := require.New(t)
+
+	pub := &Publisher{
+		cfg:           PublisherConfig{ChainID: 1},
+		batchLimit:    10,
+		batchInterval: time.Hour,
+		notifCh:       make(chan *protocol.NotifyRequest, 2),
+		batchCh:       make(chan *protocol.AlertBatch, 2),
+		flushCh:       make(chan chan struct{}),
+		batchTicker:   time.NewTicker(time.Hour),
+	}
+	for _, chainID := range []string{"0x89", "0x1"} {
+		pub.notifCh <- &protocol.NotifyRequest{
+			EvalBlockRequest: &protocol.EvaluateBlockRequest{
+				Event: &protocol.BlockEvent{
+					BlockNumber: "0x2",
+					Block:       &protocol.BlockEvent_EthBlock{},
+					Network:     &protocol.BlockEvent_Network{ChainId: chainID},
+				},
+			},
+			EvalBlockResponse: &protocol.EvaluateBlockResponse{},
+			AgentInfo:         &protocol.AgentInfo{Manifest: "agentInfo"},
+		}
+	}
+
+	go pub.prepareLatestBatch()
+
+	flushDone := make(chan struct{})
+	pub.flushCh <- flushDone
+	<-flushDone
+
+	mainBatch := <-pub.batchCh
+	r.Equal(uint64(1), mainBatch.ChainId)
+	r.Equal(uint64(2), mainBatch.BlockEnd)
+	otherBatch := <-pub.batchCh
+	r.Equal(uint64(137), otherBatch.ChainId)
+	r.Equal(uint64(2), otherBatch.BlockEnd)
+	pub.pendingBatches.Add(-2)
+}
+
+func TestStoredBatches(t *testing.T) {
+	r := require.New(t)
+
+	batchStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer batchStore.Close()
+	pub := &Publisher{
+		unpublishedStore: batchStore,
+		storedBatches:    make(map[*protocol.AlertBatch]string),
+		batchCh:          make(chan *protocol.AlertBatch, 1),
+	}
+
+	// failed batch is stored
+	pub.handleStoredBatch(&protocol.AlertBatch{BlockStart: 1, BlockEnd: 2}, false, errors.New("failed"))
+	storedBatches, err := batchStore.List()
+	r.NoError(err)
+	r.Len(storedBatches, 1)
+
+	// stored batch is restored after a restart and deleted after it is published
+	pub.storedBatches = make(map[*protocol.AlertBatch]string)
+	pub.restoreBatches()
+	batch := <-pub.batchCh
+	r.Equal(uint64(2), batch.BlockEnd)
+	pub.handleStoredBatch(batch, true, nil)
+	pub.pendingBatches.Done()
+
+	storedBatches, err = batchStore.List()
+	r.NoError(err)
+	r.Empty(storedBatches)
+	r.Empty(pub.storedBatches)
+}
+
+func TestStoredBatches_AtLeastOnce(t *testing.T) {
+	r := require.New(t)
+
+	batchStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer batchStore.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	pub := &Publisher{
+		ctx:              ctx,
+		unpublishedStore: batchStore,
+		storedBatches:    make(map[*protocol.AlertBatch]string),
+		retryAttempts:    make(map[*protocol.AlertBatch]int),
+		batchCh:          make(chan *protocol.AlertBatch, 1),
+	}
+
+	// the batch with alerts is stored before it is published
+	batch := &protocol.AlertBatch{BlockStart: 1, BlockEnd: 2, AlertCount: 1}
+	pub.storeBatch(batch)
+	storedBatches, err := batchStore.List()
+	r.NoError(err)
+	r.Len(storedBatches, 1)
+
+	// and is kept in the store to retry after a failure
+	r.True(pub.handleStoredBatch(batch, false, errors.New("failed")))
+	storedBatches, err = batchStore.List()
+	r.NoError(err)
+	r.Len(storedBatches, 1)
+
+	// the retry stops with the context and the batch is still stored
+	pub.pendingBatches.Add(1)
+	cancel()
+	pub.retryLater(batch)
+	pub.pendingBatches.Wait()
+	r.Equal(1, pub.retryAttempts[batch])
+
+	// and is deleted only after it is published
+	r.False(pub.handleStoredBatch(batch, true, nil))
+	storedBatches, err = batchStore.List()
+	r.NoError(err)
+	r.Empty(storedBatches)
+	r.Empty(pub.retryAttempts)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r := require.New(t)
+
+	cfg := config.RetryConfig{InitialBackoffSeconds: 5, MaxBackoffSeconds: 60}
+	r.Equal(5*time.Second, retryBackoff(cfg, 1))
+	r.Equal(10*time.Second, retryBackoff(cfg, 2))
+	r.Equal(40*time.Second, retryBackoff(cfg, 4))
+	r.Equal(60*time.Second, retryBackoff(cfg, 5))
+	r.Equal(60*time.Second, retryBackoff(cfg, 100))
+}
+
+func TestPrepareSinks(t *testing.T) {
+	r := require.New(t)
+
+	newSinkCfg := func(name, topic string) config.AlertSinkConfig {
+		return config.AlertSinkConfig{
+			Name: name, Type: config.AlertSinkKafka, URL: "http://kafka:8082", Topic: topic,
+			MaxBufferedAlerts: 10, FlushIntervalSeconds: 60,
+		}
+	}
+	cfg := config.Config{FortaDir: t.TempDir()}
+	cfg.Publish.Sinks = []config.AlertSinkConfig{newSinkCfg("kept", "alerts"), newSinkCfg("changed", "alerts"), newSinkCfg("removed", "alerts")}
+
+	pub := &Publisher{ctx: context.Background()}
+	var err error
+	pub.sinks, pub.sinkConfigs, err = initSinks(pub.ctx, cfg)
+	r.NoError(err)
+	pub.router, err = newAlertRouter(cfg.Publish)
+	r.NoError(err)
+	kept := pub.sinks[0]
+	changed := pub.sinks[1]
+
+	cfg.Publish.Sinks = []config.AlertSinkConfig{newSinkCfg("kept", "alerts"), newSinkCfg("changed", "other-alerts"), newSinkCfg("added", "alerts")}
+	cfg.Publish.Routes = []config.AlertRouteConfig{{Severities: []string{"CRITICAL"}, To: []string{"added"}}}
+	apply, err := pub.PrepareSinks(cfg)
+	r.NoError(err)
+	r.Len(pub.sinks, 3)
+	apply()
+
+	r.Len(pub.sinks, 3)
+	r.Equal([]string{"kept", "changed", "added"}, []string{pub.sinks[0].Name(), pub.sinks[1].Name(), pub.sinks[2].Name()})
+	r.Same(kept, pub.sinks[0])
+	r.NotSame(changed, pub.sinks[1])
+	r.Equal("other-alerts", pub.sinkConfigs["changed"].Topic)
+
+	// the unknown route destinations are not applied
+	cfg.Publish.Routes = []config.AlertRouteConfig{{Severities: []string{"CRITICAL"}, To: []string{"unknown"}}}
+	_, err = pub.PrepareSinks(cfg)
+	r.Error(err)
+}
+
+func TestAddLatencyMetrics(t *testing.T) {
+	r := require.New(t)
+
+	pub := &Publisher{metricsAggregator: NewMetricsAggregator(time.Minute)}
+
+	block := time.Now().UTC().Add(-time.Minute)
+	latency := clients.NewLatencyBreakdown(&domain.TrackingTimestamps{
+		Block:       block,
+		Feed:        block.Add(2 * time.Second),
+		BotRequest:  block.Add(3 * time.Second),
+		BotResponse: block.Add(5 * time.Second),
+	}, block.Add(6*time.Second))
+	r.Equal(2*time.Second, latency.Feed)
+	r.Equal(time.Second, latency.Queue)
+	r.Equal(2*time.Second, latency.Bot)
+	r.Equal(time.Second, latency.Publish)
+	r.Equal(6*time.Second, latency.Total)
+
+	// without the latency metadata
+	pub.addLatencyMetrics(&protocol.SignedAlert{Alert: &protocol.Alert{Agent: &protocol.AgentInfo{Id: "0xbot"}}})
+	r.Empty(pub.metricsAggregator.ForceFlush())
+
+	pub.addLatencyMetrics(&protocol.SignedAlert{
+		Alert: &protocol.Alert{Agent: &protocol.AgentInfo{Id: "0xbot"}, Metadata: latency.Metadata()},
+	})
+	flushed := pub.metricsAggregator.ForceFlush()
+	r.Len(flushed, 1)
+	r.Equal("0xbot", flushed[0].AgentId)
+	summaries := make(map[string]float64)
+	for _, summary := range flushed[0].Metrics {
+		summaries[summary.Name] = summary.Max
+	}
+	r.Equal(float64(2000), summaries[metrics.MetricAlertFeedLatency])
+	r.Equal(float64(1000), summaries[metrics.MetricAlertQueueLatency])
+	r.Equal(float64(2000), summaries[metrics.MetricAlertBotLatency])
+	r.Equal(float64(1000), summaries[metrics.MetricAlertPublishLatency])
+	r.Equal(float64(6000), summaries[metrics.MetricAlertTotalLatency])
+}
@@ -0,0 +1,78 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+)
+
+// alertRouter decides the destinations of the alerts by the severity.
+type alertRouter struct {
+	// the destinations by the severity
+	routes map[string]map[string]bool
+}
+
+// newAlertRouter creates the router from the configured routes. It returns nil if no routes are
+// configured, so that all alerts are sent to all destinations.
+func newAlertRouter(cfg config.PublisherConfig) (*alertRouter, error) {
+	if len(cfg.Routes) == 0 {
+		return nil, nil
+	}
+	destinations := map[string]bool{
+		config.AlertRouteBatch: true,
+		config.AlertRouteLocal: true,
+	}
+	for _, sinkCfg := range cfg.Sinks {
+		destinations[sinkCfg.Name] = true
+	}
+
+	router := &alertRouter{routes: make(map[string]map[string]bool)}
+	for _, route := range cfg.Routes {
+		for _, dest := range route.To {
+			if !destinations[dest] {
+				return nil, fmt.Errorf("unknown alert route destination: %s", dest)
+			}
+		}
+		for _, severity := range route.Severities {
+			severity = strings.ToUpper(severity)
+			routed, ok := router.routes[severity]
+			if !ok {
+				routed = make(map[string]bool)
+				router.routes[severity] = routed
+			}
+			for _, dest := range route.To {
+				routed[dest] = true
+			}
+		}
+	}
+	return router, nil
+}
+
+// sendsTo tells if the alert should be sent to the destination.
+func (router *alertRouter) sendsTo(alert *protocol.SignedAlert, dest string) bool {
+	if router == nil {
+		return true
+	}
+	routed, ok := router.routes[alert.GetAlert().GetFinding().GetSeverity().String()]
+	if !ok {
+		return true
+	}
+	return routed[dest]
+}
+
+// withoutAlert copies the notification without the alert, so that the batch still includes what
+// the bot has processed.
+func withoutAlert(notif *protocol.NotifyRequest) *protocol.NotifyRequest {
+	return &protocol.NotifyRequest{
+		EvalTxRequest:     notif.EvalTxRequest,
+		EvalTxResponse:    notif.EvalTxResponse,
+		EvalBlockRequest:  notif.EvalBlockRequest,
+		EvalBlockResponse: notif.EvalBlockResponse,
+		AgentInfo:         notif.AgentInfo,
+		Timestamps:        notif.Timestamps,
+		EvalAlertRequest:  notif.EvalAlertRequest,
+		EvalAlertResponse: notif.EvalAlertResponse,
+	}
+}
@@ -0,0 +1,106 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeverityAlert(severity protocol.Finding_Severity) *protocol.SignedAlert {
+	return &protocol.SignedAlert{
+		Alert: &protocol.Alert{Id: "alertId", Finding: &protocol.Finding{Severity: severity}},
+	}
+}
+
+func TestAlertRouter(t *testing.T) {
+	r := require.New(t)
+
+	cfg := config.PublisherConfig{
+		Sinks: []config.AlertSinkConfig{{Name: "pager"}},
+		Routes: []config.AlertRouteConfig{
+			{Severities: []string{"CRITICAL"}, To: []string{"pager", config.AlertRouteBatch}},
+			{Severities: []string{"info"}, To: []string{config.AlertRouteBatch}},
+			{Severities: []string{"UNKNOWN"}, To: []string{config.AlertRouteLocal}},
+		},
+	}
+	router, err := newAlertRouter(cfg)
+	r.NoError(err)
+
+	critical := testSeverityAlert(protocol.Finding_CRITICAL)
+	r.True(router.sendsTo(critical, "pager"))
+	r.True(router.sendsTo(critical, config.AlertRouteBatch))
+	r.False(router.sendsTo(critical, config.AlertRouteLocal))
+
+	info := testSeverityAlert(protocol.Finding_INFO)
+	r.False(router.sendsTo(info, "pager"))
+	r.True(router.sendsTo(info, config.AlertRouteBatch))
+
+	unknown := testSeverityAlert(protocol.Finding_UNKNOWN)
+	r.False(router.sendsTo(unknown, config.AlertRouteBatch))
+	r.True(router.sendsTo(unknown, config.AlertRouteLocal))
+
+	// the severities without a route are sent everywhere
+	high := testSeverityAlert(protocol.Finding_HIGH)
+	r.True(router.sendsTo(high, "pager"))
+	r.True(router.sendsTo(high, config.AlertRouteLocal))
+
+	// no routing without routes
+	router, err = newAlertRouter(config.PublisherConfig{})
+	r.NoError(err)
+	r.Nil(router)
+	r.True(router.sendsTo(unknown, config.AlertRouteBatch))
+
+	cfg.Routes = append(cfg.Routes, config.AlertRouteConfig{Severities: []string{"LOW"}, To: []string{"slack"}})
+	_, err = newAlertRouter(cfg)
+	r.Error(err)
+}
+
+func TestPrepareLatestBatch_Routes(t *testing.T) {
+	r := require.New(t)
+
+	router, err := newAlertRouter(config.PublisherConfig{
+		Routes: []config.AlertRouteConfig{
+			{Severities: []string{"INFO"}, To: []string{config.AlertRouteLocal}},
+		},
+	})
+	r.NoError(err)
+	pub := &Publisher{
+		cfg:           PublisherConfig{ChainID: 1},
+		router:        router,
+		batchLimit:    10,
+		batchInterval: time.Hour,
+		notifCh:       make(chan *protocol.NotifyRequest, 2),
+		batchCh:       make(chan *protocol.AlertBatch, 1),
+		flushCh:       make(chan chan struct{}),
+		batchTicker:   time.NewTicker(time.Hour),
+	}
+	for _, severity := range []protocol.Finding_Severity{protocol.Finding_INFO, protocol.Finding_HIGH} {
+		pub.notifCh <- &protocol.NotifyRequest{
+			SignedAlert: testSeverityAlert(severity),
+			EvalBlockRequest: &protocol.EvaluateBlockRequest{
+				Event: &protocol.BlockEvent{
+					BlockNumber: "0x2",
+					Block:       &protocol.BlockEvent_EthBlock{},
+				},
+			},
+			EvalBlockResponse: &protocol.EvaluateBlockResponse{},
+			AgentInfo:         &protocol.AgentInfo{Manifest: "agentInfo"},
+		}
+	}
+
+	go pub.prepareLatestBatch()
+
+	flushDone := make(chan struct{})
+	pub.flushCh <- flushDone
+	<-flushDone
+
+	// only the high severity alert is in the batch
+	batch := <-pub.batchCh
+	r.Equal(uint32(1), batch.AlertCount)
+	r.Equal(protocol.Finding_HIGH, batch.MaxSeverity)
+	r.Len(batch.Agents, 1)
+	pub.pendingBatches.Done()
+}
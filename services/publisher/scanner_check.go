@@ -0,0 +1,86 @@
+package publisher
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// scannerCheck verifies the scanner in the registry periodically, so that the publisher can refuse
+// to publish or mark the alerts as unverified if the scanner is deregistered or unstaked.
+type scannerCheck struct {
+	registry    store.ScannerRegistry
+	scannerAddr string
+	minStake    *big.Int
+	policy      string
+	interval    time.Duration
+
+	// the scanner is considered verified until the registry tells otherwise
+	unverified atomic.Bool
+}
+
+func newScannerCheck(
+	reg store.ScannerRegistry, scannerAddr string, cfg config.ScannerCheckConfig,
+) (*scannerCheck, error) {
+	minStake, err := store.ParseMinStake(cfg.MinStakeFORT)
+	if err != nil {
+		return nil, err
+	}
+	return &scannerCheck{
+		registry:    reg,
+		scannerAddr: scannerAddr,
+		minStake:    minStake,
+		policy:      cfg.OnUnverified,
+		interval:    time.Duration(cfg.CheckIntervalSeconds) * time.Second,
+	}, nil
+}
+
+func (sc *scannerCheck) run(ctx context.Context) {
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+	for {
+		sc.check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check updates the verification state. The last known state is kept if the registry is not
+// reachable.
+func (sc *scannerCheck) check() {
+	logger := log.WithFields(log.Fields{
+		"scanner": sc.scannerAddr,
+		"policy":  sc.policy,
+	})
+	state, err := store.CheckScanner(sc.registry, sc.scannerAddr, sc.minStake)
+	if err != nil {
+		logger.WithError(err).Warn("failed to check the scanner state")
+		return
+	}
+	if !state.Verified() {
+		logger.WithFields(log.Fields{
+			"registered": state.Registered,
+			"enabled":    state.Enabled,
+			"staked":     state.Staked(),
+		}).Warn("scanner is not registered or staked enough")
+	}
+	sc.unverified.Store(!state.Verified())
+}
+
+// shouldRefuse tells if the batches should not be published.
+func (sc *scannerCheck) shouldRefuse() bool {
+	return sc != nil && sc.policy == config.UnverifiedScannerRefuse && sc.unverified.Load()
+}
+
+// shouldMark tells if the alerts should be marked as unverified.
+func (sc *scannerCheck) shouldMark() bool {
+	return sc != nil && sc.policy == config.UnverifiedScannerMark && sc.unverified.Load()
+}
@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/registry"
+	mock_registry "github.com/forta-network/forta-core-go/registry/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testScannerAddr = "0x0000000000000000000000000000000000000001"
+
+func TestScannerCheck(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regClient := mock_registry.NewMockClient(ctrl)
+
+	refuse, err := newScannerCheck(regClient, testScannerAddr, config.ScannerCheckConfig{
+		OnUnverified:         config.UnverifiedScannerRefuse,
+		CheckIntervalSeconds: 1,
+	})
+	r.NoError(err)
+	mark, err := newScannerCheck(regClient, testScannerAddr, config.ScannerCheckConfig{
+		OnUnverified:         config.UnverifiedScannerMark,
+		CheckIntervalSeconds: 1,
+	})
+	r.NoError(err)
+
+	// verified until checked
+	r.False(refuse.shouldRefuse())
+	r.False(mark.shouldMark())
+
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(nil, nil).Times(2)
+	refuse.check()
+	mark.check()
+	r.True(refuse.shouldRefuse())
+	r.False(refuse.shouldMark())
+	r.True(mark.shouldMark())
+	r.False(mark.shouldRefuse())
+
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(&registry.Scanner{Enabled: true, PoolID: "1"}, nil)
+	refuse.check()
+	r.False(refuse.shouldRefuse())
+
+	// no check for the warn policy
+	var noCheck *scannerCheck
+	r.False(noCheck.shouldRefuse())
+	r.False(noCheck.shouldMark())
+}
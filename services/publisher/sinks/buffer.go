@@ -0,0 +1,334 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	segmentSuffix     = ".jsonl"
+	openSegmentSuffix = ".open"
+	maxSendRecords    = 500
+)
+
+// BufferedSink writes the records to the segment files on the disk and sends the segments to the sink
+// in the background, oldest first. A segment is deleted only after all of its records are delivered,
+// so the records survive the restarts and the sink outages and may be delivered more than once.
+type BufferedSink struct {
+	ctx           context.Context
+	sink          Sink
+	dir           string
+	maxRecords    int
+	flushInterval time.Duration
+	filter        *Filter
+
+	current      *os.File
+	currentPath  string
+	currentCount int
+	// the amount of records in the closed segments by segment path
+	segments map[string]int
+	buffered int
+	mu       sync.Mutex
+
+	// serializes the flushes
+	flushMu sync.Mutex
+
+	// stops the background sends when the sink is closed
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	lastSend    health.TimeTracker
+	lastSendErr health.ErrorTracker
+	lastDrop    health.TimeTracker
+}
+
+// BufferedSinkConfig contains the buffered sink settings.
+type BufferedSinkConfig struct {
+	Dir           string
+	MaxRecords    int
+	FlushInterval time.Duration
+	// only the matching records are buffered - all records are buffered if nil
+	Filter *Filter
+}
+
+// NewBufferedSink creates a new buffered sink which keeps the segments in the directory. The segments
+// left from the previous runs are sent first.
+func NewBufferedSink(ctx context.Context, sink Sink, cfg BufferedSinkConfig) (*BufferedSink, error) {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create alert sink buffer dir: %v", err)
+	}
+	bs := &BufferedSink{
+		ctx:           ctx,
+		sink:          sink,
+		dir:           cfg.Dir,
+		maxRecords:    cfg.MaxRecords,
+		flushInterval: cfg.FlushInterval,
+		filter:        cfg.Filter,
+		segments:      make(map[string]int),
+		closed:        make(chan struct{}),
+	}
+	if err := bs.loadSegments(); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// loadSegments finds the segments from the previous runs and closes the ones which were open.
+func (bs *BufferedSink) loadSegments() error {
+	entries, err := os.ReadDir(bs.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read alert sink buffer dir: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		segmentPath := path.Join(bs.dir, name)
+		if strings.HasSuffix(name, openSegmentSuffix) {
+			closedPath := strings.TrimSuffix(segmentPath, openSegmentSuffix) + segmentSuffix
+			if err := os.Rename(segmentPath, closedPath); err != nil {
+				return fmt.Errorf("failed to close alert sink segment: %v", err)
+			}
+			segmentPath = closedPath
+		} else if !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		records, err := readSegment(segmentPath)
+		if err != nil {
+			return err
+		}
+		bs.segments[segmentPath] = len(records)
+		bs.buffered += len(records)
+	}
+	return nil
+}
+
+// Name returns the name of the sink.
+func (bs *BufferedSink) Name() string {
+	return bs.sink.Name()
+}
+
+// Add writes the record to the current segment. The records which do not match the filter are ignored.
+func (bs *BufferedSink) Add(record *Record) error {
+	if !bs.filter.Match(record) {
+		return nil
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.current == nil {
+		bs.currentPath = path.Join(bs.dir, fmt.Sprintf("%020d%s", time.Now().UnixNano(), openSegmentSuffix))
+		bs.current, err = os.OpenFile(bs.currentPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			bs.current = nil
+			return fmt.Errorf("failed to create alert sink segment: %v", err)
+		}
+	}
+	if _, err := bs.current.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write to alert sink segment: %v", err)
+	}
+	bs.currentCount++
+	bs.buffered++
+	return nil
+}
+
+// Buffered returns the amount of records which are not delivered yet.
+func (bs *BufferedSink) Buffered() int {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	return bs.buffered
+}
+
+// Start starts sending the segments periodically.
+func (bs *BufferedSink) Start() {
+	go func() {
+		ticker := time.NewTicker(bs.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bs.ctx.Done():
+				return
+			case <-bs.closed:
+				return
+			case <-ticker.C:
+				_ = bs.Flush(bs.ctx)
+			}
+		}
+	}()
+}
+
+// Flush closes the current segment and sends all segments to the sink. It stops at the first failure,
+// so that the records are delivered in order.
+func (bs *BufferedSink) Flush(ctx context.Context) error {
+	bs.flushMu.Lock()
+	defer bs.flushMu.Unlock()
+
+	if err := bs.rollSegment(); err != nil {
+		log.WithError(err).WithField("sink", bs.Name()).Error("failed to close the alert sink segment")
+	}
+	bs.dropOldest()
+
+	for _, segmentPath := range bs.closedSegments() {
+		if err := bs.sendSegment(ctx, segmentPath); err != nil {
+			bs.lastSendErr.Set(err)
+			log.WithError(err).WithField("sink", bs.Name()).Warn("failed to send alerts to sink - will retry")
+			return err
+		}
+	}
+	bs.lastSendErr.Set(nil)
+	return nil
+}
+
+func (bs *BufferedSink) rollSegment() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.current == nil {
+		return nil
+	}
+	if err := bs.current.Close(); err != nil {
+		return err
+	}
+	closedPath := strings.TrimSuffix(bs.currentPath, openSegmentSuffix) + segmentSuffix
+	if err := os.Rename(bs.currentPath, closedPath); err != nil {
+		return err
+	}
+	bs.segments[closedPath] = bs.currentCount
+	bs.current = nil
+	bs.currentPath = ""
+	bs.currentCount = 0
+	return nil
+}
+
+// dropOldest deletes the oldest segments while there are too many buffered records.
+func (bs *BufferedSink) dropOldest() {
+	for _, segmentPath := range bs.closedSegments() {
+		bs.mu.Lock()
+		if bs.buffered <= bs.maxRecords {
+			bs.mu.Unlock()
+			return
+		}
+		count := bs.segments[segmentPath]
+		bs.mu.Unlock()
+
+		if err := os.Remove(segmentPath); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField("sink", bs.Name()).Error("failed to drop the alert sink segment")
+			return
+		}
+		bs.removeSegment(segmentPath)
+		bs.lastDrop.Set()
+		log.WithFields(log.Fields{
+			"sink":   bs.Name(),
+			"alerts": count,
+		}).Warn("alert sink buffer is full - dropped the oldest alerts")
+	}
+}
+
+func (bs *BufferedSink) closedSegments() []string {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	segmentPaths := make([]string, 0, len(bs.segments))
+	for segmentPath := range bs.segments {
+		segmentPaths = append(segmentPaths, segmentPath)
+	}
+	sort.Strings(segmentPaths)
+	return segmentPaths
+}
+
+func (bs *BufferedSink) removeSegment(segmentPath string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.buffered -= bs.segments[segmentPath]
+	delete(bs.segments, segmentPath)
+}
+
+func (bs *BufferedSink) sendSegment(ctx context.Context, segmentPath string) error {
+	records, err := readSegment(segmentPath)
+	if err != nil {
+		return err
+	}
+	for len(records) > 0 {
+		n := maxSendRecords
+		if len(records) < n {
+			n = len(records)
+		}
+		if err := bs.sink.Send(ctx, records[:n]); err != nil {
+			return err
+		}
+		bs.lastSend.Set()
+		records = records[n:]
+	}
+	if err := os.Remove(segmentPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete the sent alert sink segment: %v", err)
+	}
+	bs.removeSegment(segmentPath)
+	return nil
+}
+
+// readSegment reads the records from the segment. The incomplete records which may be left
+// from a crash are skipped.
+func readSegment(segmentPath string) ([]*Record, error) {
+	f, err := os.Open(segmentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open alert sink segment: %v", err)
+	}
+	defer f.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.WithError(err).WithField("segment", segmentPath).Warn("skipping invalid alert sink record")
+			continue
+		}
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert sink segment: %v", err)
+	}
+	return records, nil
+}
+
+// Close makes a last attempt to send the buffered records and closes the sink. The records
+// which could not be sent stay on the disk.
+func (bs *BufferedSink) Close(timeout time.Duration) error {
+	bs.closeOnce.Do(func() { close(bs.closed) })
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_ = bs.Flush(ctx)
+	return bs.sink.Close()
+}
+
+// Health implements the health.Reporter interface.
+func (bs *BufferedSink) Health() health.Reports {
+	prefix := fmt.Sprintf("sink.%s.", bs.Name())
+	return health.Reports{
+		bs.lastSend.GetReport(prefix + "event.send.time"),
+		bs.lastSendErr.GetReport(prefix + "event.send.error"),
+		bs.lastDrop.GetReport(prefix + "event.drop.time"),
+		&health.Report{
+			Name:    prefix + "buffered",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprint(bs.Buffered()),
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testSink struct {
+	fail    bool
+	records []*Record
+}
+
+func (ts *testSink) Name() string {
+	return "test"
+}
+
+func (ts *testSink) Send(ctx context.Context, records []*Record) error {
+	if ts.fail {
+		return errors.New("sink is unavailable")
+	}
+	ts.records = append(ts.records, records...)
+	return nil
+}
+
+func (ts *testSink) Close() error {
+	return nil
+}
+
+func testRecord(i int) *Record {
+	return &Record{ID: fmt.Sprintf("0x%d", i), ChainID: 1, BotID: "0xbot", Value: []byte(`{}`)}
+}
+
+func TestBufferedSink(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	sink := &testSink{fail: true}
+	bs, err := NewBufferedSink(context.Background(), sink, BufferedSinkConfig{Dir: dir, MaxRecords: 100, FlushInterval: time.Second})
+	r.NoError(err)
+
+	r.NoError(bs.Add(testRecord(1)))
+	r.NoError(bs.Add(testRecord(2)))
+	r.Error(bs.Flush(context.Background()))
+	r.Equal(2, bs.Buffered())
+
+	// the records should survive the restarts
+	r.NoError(bs.Add(testRecord(3)))
+	bs, err = NewBufferedSink(context.Background(), sink, BufferedSinkConfig{Dir: dir, MaxRecords: 100, FlushInterval: time.Second})
+	r.NoError(err)
+	r.Equal(3, bs.Buffered())
+
+	sink.fail = false
+	r.NoError(bs.Flush(context.Background()))
+	r.Zero(bs.Buffered())
+	r.Len(sink.records, 3)
+	for i, record := range sink.records {
+		r.Equal(testRecord(i+1).ID, record.ID)
+	}
+
+	// nothing is sent again
+	r.NoError(bs.Flush(context.Background()))
+	r.Len(sink.records, 3)
+}
+
+func TestBufferedSink_DropOldest(t *testing.T) {
+	r := require.New(t)
+
+	sink := &testSink{fail: true}
+	bs, err := NewBufferedSink(context.Background(), sink, BufferedSinkConfig{Dir: t.TempDir(), MaxRecords: 2, FlushInterval: time.Second})
+	r.NoError(err)
+
+	for i := 1; i <= 3; i++ {
+		r.NoError(bs.Add(testRecord(i)))
+		r.Error(bs.Flush(context.Background()))
+	}
+	r.Equal(2, bs.Buffered())
+
+	sink.fail = false
+	r.NoError(bs.Flush(context.Background()))
+	r.Len(sink.records, 2)
+	r.Equal(testRecord(2).ID, sink.records[0].ID)
+	r.Equal(testRecord(3).ID, sink.records[1].ID)
+}
+
+func TestBufferedSink_Filter(t *testing.T) {
+	r := require.New(t)
+
+	sink := &testSink{}
+	bs, err := NewBufferedSink(context.Background(), sink, BufferedSinkConfig{
+		Dir:           t.TempDir(),
+		MaxRecords:    100,
+		FlushInterval: time.Second,
+		Filter:        NewFilter(config.AlertSinkConfig{Severities: []string{"HIGH", "CRITICAL"}, Bots: []string{"0xBOT"}}),
+	})
+	r.NoError(err)
+
+	high := testRecord(1)
+	high.Severity = "HIGH"
+	low := testRecord(2)
+	low.Severity = "LOW"
+	otherBot := testRecord(3)
+	otherBot.Severity = "CRITICAL"
+	otherBot.BotID = "0xother"
+
+	for _, record := range []*Record{high, low, otherBot} {
+		r.NoError(bs.Add(record))
+	}
+	r.NoError(bs.Flush(context.Background()))
+	r.Len(sink.records, 1)
+	r.Equal(high.ID, sink.records[0].ID)
+}
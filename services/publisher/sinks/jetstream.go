@@ -0,0 +1,61 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/nats-io/nats.go"
+)
+
+type jetStreamPublisher interface {
+	Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// jetStreamSink publishes the alerts to the subjects under the topic, which should be captured by
+// a JetStream stream. The partition key makes the last tokens of the subject (e.g. <topic>.<chain>.<bot>)
+// and the alert id is the message id, so that the stream ignores the duplicates of the alerts which are sent again.
+type jetStreamSink struct {
+	cfg  config.AlertSinkConfig
+	conn *nats.Conn
+	js   jetStreamPublisher
+}
+
+// NewJetStreamSink creates a new NATS JetStream sink.
+func NewJetStreamSink(cfg config.AlertSinkConfig) (*jetStreamSink, error) {
+	// the alerts are buffered until the server is reachable
+	conn, err := nats.Connect(cfg.URL, nats.MaxReconnects(-1), nats.RetryOnFailedConnect(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %v", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %v", err)
+	}
+	return &jetStreamSink{cfg: cfg, conn: conn, js: js}, nil
+}
+
+// Name implements Sink.
+func (jss *jetStreamSink) Name() string {
+	return jss.cfg.Name
+}
+
+// Send implements Sink.
+func (jss *jetStreamSink) Send(ctx context.Context, records []*Record) error {
+	for _, record := range records {
+		subject := fmt.Sprintf("%s.%s", jss.cfg.Topic, record.PartitionKey(jss.cfg.PartitionBy))
+		if _, err := jss.js.Publish(subject, record.Value, nats.MsgId(record.ID), nats.Context(ctx)); err != nil {
+			return fmt.Errorf("failed to publish to jetstream: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (jss *jetStreamSink) Close() error {
+	if jss.conn != nil {
+		jss.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+	kafkaTimeout     = 30 * time.Second
+)
+
+// kafkaSink produces the alerts to a Kafka topic through the Kafka REST proxy. The records
+// are keyed by the partition key, so that Kafka assigns the same partition to the same key.
+type kafkaSink struct {
+	cfg    config.AlertSinkConfig
+	client *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []*kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition *int    `json:"partition"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaSink creates a new Kafka sink.
+func NewKafkaSink(cfg config.AlertSinkConfig) *kafkaSink {
+	return &kafkaSink{
+		cfg:    cfg,
+		client: &http.Client{Timeout: kafkaTimeout},
+	}
+}
+
+// Name implements Sink.
+func (ks *kafkaSink) Name() string {
+	return ks.cfg.Name
+}
+
+// Send implements Sink.
+func (ks *kafkaSink) Send(ctx context.Context, records []*Record) error {
+	var produceReq kafkaProduceRequest
+	for _, record := range records {
+		produceReq.Records = append(produceReq.Records, &kafkaRecord{
+			Key:   record.PartitionKey(ks.cfg.PartitionBy),
+			Value: record.Value,
+		})
+	}
+	b, err := json.Marshal(&produceReq)
+	if err != nil {
+		return err
+	}
+
+	topicURL := fmt.Sprintf("%s/topics/%s", strings.TrimRight(ks.cfg.URL, "/"), url.PathEscape(ks.cfg.Topic))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, topicURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+	for key, value := range ks.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka rest proxy responded with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// the proxy responds with a success even if some of the records failed
+	var produceResp kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produceResp); err != nil {
+		return fmt.Errorf("failed to decode kafka rest proxy response: %v", err)
+	}
+	for _, offset := range produceResp.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			var errMsg string
+			if offset.Error != nil {
+				errMsg = *offset.Error
+			}
+			return fmt.Errorf("kafka failed to store a record: %s", errMsg)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (ks *kafkaSink) Close() error {
+	ks.client.CloseIdleConnections()
+	return nil
+}
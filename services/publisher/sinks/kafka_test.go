@@ -0,0 +1,47 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaSink(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		received   kafkaProduceRequest
+		respBody   = `{"offsets":[{"partition":0,"offset":1}]}`
+		authHeader string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/topics/forta-alerts", req.URL.Path)
+		r.Equal(kafkaContentType, req.Header.Get("Content-Type"))
+		authHeader = req.Header.Get("Authorization")
+		r.NoError(json.NewDecoder(req.Body).Decode(&received))
+		_, _ = w.Write([]byte(respBody))
+	}))
+	defer server.Close()
+
+	ks := NewKafkaSink(config.AlertSinkConfig{
+		Name:        "kafka",
+		URL:         server.URL,
+		Topic:       "forta-alerts",
+		Headers:     map[string]string{"Authorization": "Basic abc"},
+		PartitionBy: config.AlertSinkPartitionByChainBot,
+	})
+	r.NoError(ks.Send(context.Background(), []*Record{testRecord(1)}))
+	r.Len(received.Records, 1)
+	r.Equal("1.0xbot", received.Records[0].Key)
+	r.JSONEq(`{}`, string(received.Records[0].Value))
+	r.Equal("Basic abc", authHeader)
+
+	// the record errors should fail the send
+	respBody = `{"offsets":[{"error_code":50002,"error":"broker not available"}]}`
+	r.Error(ks.Send(context.Background(), []*Record{testRecord(1)}))
+}
@@ -0,0 +1,113 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Sink sends the alerts to a streaming platform or a webhook.
+type Sink interface {
+	Name() string
+	// Send delivers all of the records or returns an error. The records can be sent again after an error.
+	Send(ctx context.Context, records []*Record) error
+	Close() error
+}
+
+// Record is an alert which is sent to the sinks.
+type Record struct {
+	ID       string          `json:"id"`
+	ChainID  uint64          `json:"chainId"`
+	BotID    string          `json:"botId"`
+	Severity string          `json:"severity,omitempty"`
+	Value    json.RawMessage `json:"value"`
+	// the scanner was not registered or staked enough when the alert was published
+	Unverified bool `json:"unverified,omitempty"`
+}
+
+// NewRecord creates a new record from the signed alert.
+func NewRecord(alert *protocol.SignedAlert, chainID uint64) (*Record, error) {
+	b, err := protojson.Marshal(alert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the alert: %v", err)
+	}
+	return &Record{
+		ID:       alert.GetAlert().GetId(),
+		ChainID:  chainID,
+		BotID:    strings.ToLower(alert.GetAlert().GetAgent().GetId()),
+		Severity: alert.GetAlert().GetFinding().GetSeverity().String(),
+		Value:    b,
+	}, nil
+}
+
+// PartitionKey returns the key which decides the partition of the record, so that the
+// alerts of the same chain and/or bot are kept in order.
+func (record *Record) PartitionKey(partitionBy string) string {
+	chainID := strconv.FormatUint(record.ChainID, 10)
+	switch partitionBy {
+	case config.AlertSinkPartitionByChain:
+		return chainID
+	case config.AlertSinkPartitionByBot:
+		return record.BotID
+	default:
+		return fmt.Sprintf("%s.%s", chainID, record.BotID)
+	}
+}
+
+// Filter matches the records by the severity and the bot.
+type Filter struct {
+	severities map[string]bool
+	bots       map[string]bool
+}
+
+// NewFilter creates a new filter from the sink config. It returns nil if the sink accepts all records.
+func NewFilter(cfg config.AlertSinkConfig) *Filter {
+	if len(cfg.Severities) == 0 && len(cfg.Bots) == 0 {
+		return nil
+	}
+	filter := &Filter{
+		severities: make(map[string]bool),
+		bots:       make(map[string]bool),
+	}
+	for _, severity := range cfg.Severities {
+		filter.severities[strings.ToUpper(severity)] = true
+	}
+	for _, botID := range cfg.Bots {
+		filter.bots[strings.ToLower(botID)] = true
+	}
+	return filter
+}
+
+// Match tells if the record matches both the severities and the bots.
+func (filter *Filter) Match(record *Record) bool {
+	if filter == nil {
+		return true
+	}
+	if len(filter.severities) > 0 && !filter.severities[strings.ToUpper(record.Severity)] {
+		return false
+	}
+	if len(filter.bots) > 0 && !filter.bots[strings.ToLower(record.BotID)] {
+		return false
+	}
+	return true
+}
+
+// New creates a new sink from the config.
+func New(cfg config.AlertSinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case config.AlertSinkKafka:
+		return NewKafkaSink(cfg), nil
+	case config.AlertSinkNATS:
+		return NewJetStreamSink(cfg)
+	case config.AlertSinkWebhook:
+		return NewWebhookSink(cfg)
+	default:
+		return nil, fmt.Errorf("unknown alert sink type: %s", cfg.Type)
+	}
+}
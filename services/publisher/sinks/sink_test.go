@@ -0,0 +1,48 @@
+package sinks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+type testJetStream struct {
+	subjects []string
+}
+
+func (js *testJetStream) Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	js.subjects = append(js.subjects, subj)
+	return &nats.PubAck{}, nil
+}
+
+func TestNewRecord(t *testing.T) {
+	r := require.New(t)
+
+	record, err := NewRecord(&protocol.SignedAlert{
+		Alert: &protocol.Alert{Id: "0xalert", Agent: &protocol.AgentInfo{Id: "0xBOT"}},
+	}, 137)
+	r.NoError(err)
+	r.Equal("0xalert", record.ID)
+	r.Equal("0xbot", record.BotID)
+	r.Contains(string(record.Value), "0xalert")
+
+	r.Equal("137", record.PartitionKey(config.AlertSinkPartitionByChain))
+	r.Equal("0xbot", record.PartitionKey(config.AlertSinkPartitionByBot))
+	r.Equal("137.0xbot", record.PartitionKey(config.AlertSinkPartitionByChainBot))
+}
+
+func TestJetStreamSink(t *testing.T) {
+	r := require.New(t)
+
+	js := &testJetStream{}
+	jss := &jetStreamSink{
+		cfg: config.AlertSinkConfig{Name: "nats", Topic: "forta.alerts", PartitionBy: config.AlertSinkPartitionByChainBot},
+		js:  js,
+	}
+	r.NoError(jss.Send(context.Background(), []*Record{testRecord(1)}))
+	r.Equal([]string{"forta.alerts.1.0xbot"}, js.subjects)
+}
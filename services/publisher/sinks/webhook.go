@@ -0,0 +1,270 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	webhookTimeout         = 30 * time.Second
+	webhookRetryInterval   = time.Second
+	webhookMaxSentIDs      = 10000
+	webhookTimestampHeader = "X-Forta-Timestamp"
+	webhookSignatureHeader = "X-Forta-Signature"
+)
+
+// webhookTemplates are the preset body formats.
+var webhookTemplates = map[string]string{
+	config.WebhookFormatGeneric: `{{ .Raw }}`,
+	config.WebhookFormatSlack:   `{"text": {{ printf "*[%s] %s*\n%s\nbot: %s\nchain: %d\nalert: %s" .Severity .Finding.Name .Finding.Description .BotID .ChainID .Alert.Id | json }}}`,
+	config.WebhookFormatPagerDuty: `{
+  "routing_key": {{ json .Params.routingKey }},
+  "event_action": "trigger",
+  "dedup_key": {{ json .Alert.Id }},
+  "payload": {
+    "summary": {{ printf "[%s] %s" .Severity .Finding.Name | json }},
+    "source": {{ json .BotID }},
+    "severity": {{ pagerDutySeverity .Severity | json }},
+    "custom_details": {
+      "description": {{ json .Finding.Description }},
+      "alertId": {{ json .Finding.AlertId }},
+      "chainId": {{ .ChainID }},
+      "alertHash": {{ json .Alert.Id }}
+    }
+  }
+}`,
+}
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"pagerDutySeverity": func(severity string) string {
+		switch severity {
+		case protocol.Finding_CRITICAL.String():
+			return "critical"
+		case protocol.Finding_HIGH.String():
+			return "error"
+		case protocol.Finding_MEDIUM.String():
+			return "warning"
+		default:
+			return "info"
+		}
+	},
+}
+
+// WebhookData is the data which is passed to the webhook body templates.
+type WebhookData struct {
+	Alert    *protocol.Alert
+	Finding  *protocol.Finding
+	ChainID  uint64
+	BotID    string
+	Severity string
+	// the signed alert as JSON
+	Raw    string
+	Params map[string]string
+}
+
+// webhookSink posts each alert to the URL with a body from the template. The body is signed
+// with the secret so that the receivers can verify that the request is from this node.
+type webhookSink struct {
+	cfg      config.AlertSinkConfig
+	client   *http.Client
+	template *template.Template
+
+	// the alerts which are posted recently, so that they are not posted again when a failed
+	// batch is sent again
+	sentIDs   map[string]bool
+	sentOrder []string
+	mu        sync.Mutex
+}
+
+// NewWebhookSink creates a new webhook sink.
+func NewWebhookSink(cfg config.AlertSinkConfig) (*webhookSink, error) {
+	text := cfg.Webhook.Template
+	if cfg.Webhook.Format != config.WebhookFormatCustom {
+		var ok bool
+		text, ok = webhookTemplates[cfg.Webhook.Format]
+		if !ok {
+			return nil, fmt.Errorf("unknown webhook format: %s", cfg.Webhook.Format)
+		}
+	}
+	tmpl, err := template.New(cfg.Name).Funcs(webhookFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the webhook template: %v", err)
+	}
+	if cfg.Webhook.MaxAttempts < 1 {
+		cfg.Webhook.MaxAttempts = 1
+	}
+	return &webhookSink{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: webhookTimeout},
+		template: tmpl,
+		sentIDs:  make(map[string]bool),
+	}, nil
+}
+
+// Name implements Sink.
+func (ws *webhookSink) Name() string {
+	return ws.cfg.Name
+}
+
+// Send implements Sink.
+func (ws *webhookSink) Send(ctx context.Context, records []*Record) error {
+	for _, record := range records {
+		if ws.isSent(record.ID) {
+			continue
+		}
+		body, err := ws.render(record)
+		if err != nil {
+			// the record would fail the same way every time
+			log.WithError(err).WithFields(log.Fields{
+				"sink":  ws.Name(),
+				"alert": record.ID,
+			}).Error("failed to render the webhook body - skipping alert")
+			continue
+		}
+		retry, err := ws.post(ctx, body)
+		if err != nil && retry {
+			return err
+		}
+		if err != nil {
+			// the receiver rejects the alert and would reject it again
+			log.WithError(err).WithFields(log.Fields{
+				"sink":  ws.Name(),
+				"alert": record.ID,
+			}).Error("webhook rejected the alert - skipping alert")
+		}
+		ws.setSent(record.ID)
+	}
+	return nil
+}
+
+func (ws *webhookSink) render(record *Record) ([]byte, error) {
+	var signedAlert protocol.SignedAlert
+	if err := protojson.Unmarshal(record.Value, &signedAlert); err != nil {
+		return nil, fmt.Errorf("failed to decode the alert: %v", err)
+	}
+	alert := signedAlert.GetAlert()
+	if alert == nil {
+		alert = &protocol.Alert{}
+	}
+	finding := alert.GetFinding()
+	if finding == nil {
+		finding = &protocol.Finding{}
+	}
+	data := &WebhookData{
+		Alert:    alert,
+		Finding:  finding,
+		ChainID:  record.ChainID,
+		BotID:    record.BotID,
+		Severity: record.Severity,
+		Raw:      string(record.Value),
+		Params:   ws.cfg.Webhook.TemplateParams,
+	}
+	var buf bytes.Buffer
+	if err := ws.template.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// post posts the body and retries a few times if the receiver is unavailable. It tells if
+// the failure is temporary.
+func (ws *webhookSink) post(ctx context.Context, body []byte) (retry bool, err error) {
+	for attempt := 1; attempt <= ws.cfg.Webhook.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return true, ctx.Err()
+			case <-time.After(webhookRetryInterval * time.Duration(attempt-1)):
+			}
+		}
+		retry, err = ws.tryPost(ctx, body)
+		if err == nil || !retry {
+			return
+		}
+	}
+	return
+}
+
+func (ws *webhookSink) tryPost(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range ws.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	if len(ws.cfg.Webhook.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(webhookTimestampHeader, timestamp)
+		req.Header.Set(webhookSignatureHeader, SignWebhook(ws.cfg.Webhook.Secret, timestamp, body))
+	}
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post to webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// SignWebhook returns the signature header value for the webhook request body. The receivers
+// should compute the HMAC-SHA256 of "<timestamp>.<body>" with the secret and compare.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (ws *webhookSink) isSent(id string) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.sentIDs[id]
+}
+
+func (ws *webhookSink) setSent(id string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.sentIDs[id] = true
+	ws.sentOrder = append(ws.sentOrder, id)
+	if len(ws.sentOrder) > webhookMaxSentIDs {
+		delete(ws.sentIDs, ws.sentOrder[0])
+		ws.sentOrder = ws.sentOrder[1:]
+	}
+}
+
+// Close implements Sink.
+func (ws *webhookSink) Close() error {
+	ws.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,131 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testWebhookRecord(r *require.Assertions) *Record {
+	record, err := NewRecord(&protocol.SignedAlert{
+		Alert: &protocol.Alert{
+			Id:      "0xalert",
+			Agent:   &protocol.AgentInfo{Id: "0xBOT"},
+			Finding: &protocol.Finding{Name: "Exploit", Description: "Funds are drained", Severity: protocol.Finding_CRITICAL},
+		},
+	}, 1)
+	r.NoError(err)
+	return record
+}
+
+func TestWebhookSink_PagerDuty(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		body      []byte
+		signature string
+		timestamp string
+		requests  int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		body, _ = io.ReadAll(req.Body)
+		signature = req.Header.Get(webhookSignatureHeader)
+		timestamp = req.Header.Get(webhookTimestampHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ws, err := NewWebhookSink(config.AlertSinkConfig{
+		Name: "pagerduty",
+		URL:  server.URL,
+		Webhook: config.WebhookSinkConfig{
+			Format:         config.WebhookFormatPagerDuty,
+			TemplateParams: map[string]string{"routingKey": "key"},
+			Secret:         "secret",
+			MaxAttempts:    1,
+		},
+	})
+	r.NoError(err)
+
+	record := testWebhookRecord(r)
+	r.NoError(ws.Send(context.Background(), []*Record{record}))
+
+	var event struct {
+		RoutingKey string `json:"routing_key"`
+		DedupKey   string `json:"dedup_key"`
+		Payload    struct {
+			Summary  string `json:"summary"`
+			Severity string `json:"severity"`
+		} `json:"payload"`
+	}
+	r.NoError(json.Unmarshal(body, &event))
+	r.Equal("key", event.RoutingKey)
+	r.Equal("0xalert", event.DedupKey)
+	r.Equal("[CRITICAL] Exploit", event.Payload.Summary)
+	r.Equal("critical", event.Payload.Severity)
+	r.Equal(SignWebhook("secret", timestamp, body), signature)
+
+	// the sent alerts are not posted again
+	r.NoError(ws.Send(context.Background(), []*Record{record}))
+	r.Equal(1, requests)
+}
+
+func TestWebhookSink_Custom(t *testing.T) {
+	r := require.New(t)
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ = io.ReadAll(req.Body)
+	}))
+	defer server.Close()
+
+	ws, err := NewWebhookSink(config.AlertSinkConfig{
+		Name: "custom",
+		URL:  server.URL,
+		Webhook: config.WebhookSinkConfig{
+			Format:      config.WebhookFormatCustom,
+			Template:    `{"msg": {{ printf "%s on chain %d" .Finding.Name .ChainID | json }}}`,
+			MaxAttempts: 1,
+		},
+	})
+	r.NoError(err)
+
+	r.NoError(ws.Send(context.Background(), []*Record{testWebhookRecord(r)}))
+	r.JSONEq(`{"msg": "Exploit on chain 1"}`, string(body))
+}
+
+func TestWebhookSink_Retry(t *testing.T) {
+	r := require.New(t)
+
+	var requests int
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ws, err := NewWebhookSink(config.AlertSinkConfig{
+		Name:    "generic",
+		URL:     server.URL,
+		Webhook: config.WebhookSinkConfig{Format: config.WebhookFormatGeneric, MaxAttempts: 2},
+	})
+	r.NoError(err)
+
+	// the unavailable receiver is retried and the error is returned to the buffer
+	r.Error(ws.Send(context.Background(), []*Record{testRecord(1)}))
+	r.Equal(2, requests)
+
+	// the rejected alerts are skipped
+	status = http.StatusBadRequest
+	r.NoError(ws.Send(context.Background(), []*Record{testRecord(2)}))
+	r.Equal(3, requests)
+}
@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -328,15 +329,29 @@ func (runner *Runner) startSupervisor(logger *log.Entry, latestRefs store.ImageR
 	if err != nil {
 		return err
 	}
+	env := map[string]string{
+		// supervisor needs to know and mount the forta dir on the host os
+		config.EnvHostFortaDir: runner.cfg.FortaDir,
+		config.EnvReleaseInfo:  latestRefs.ReleaseInfo.String(),
+		config.EnvDevelopment:  strconv.FormatBool(runner.cfg.Development),
+	}
+	// let supervisor pass the replay range to the scanner
+	for envVar, value := range runner.cfg.ReplayRangeEnv() {
+		env[envVar] = value
+	}
+	// let supervisor pass the config overrides to the node containers
+	for envVar, value := range config.OverrideEnv(os.Environ()) {
+		env[envVar] = value
+	}
+	// let supervisor read the bot secrets from the env vars
+	for envVar, value := range runner.cfg.SecretEnv() {
+		env[envVar] = value
+	}
 	sc, err := runner.dockerClient.StartContainer(runner.ctx, docker.ContainerConfig{
 		Name:  config.DockerSupervisorContainerName,
 		Image: supervisorRef,
 		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "supervisor"},
-		Env: map[string]string{
-			// supervisor needs to know and mount the forta dir on the host os
-			config.EnvHostFortaDir: runner.cfg.FortaDir,
-			config.EnvReleaseInfo:  latestRefs.ReleaseInfo.String(),
-		},
+		Env:   env,
 		Volumes: map[string]string{
 			// give access to host docker
 			"/var/run/docker.sock": "/var/run/docker.sock",
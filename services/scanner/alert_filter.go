@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const throttleWindow = time.Minute
+
+// AlertFilter drops the duplicate alerts of a bot within the dedupe window and
+// throttles the bots which create more alerts per minute than the max.
+type AlertFilter struct {
+	dedupeWindow time.Duration
+	maxPerMinute int
+	history      store.AlertHistoryStore
+
+	seen      map[string]time.Time
+	lastPrune time.Time
+	windows   map[string]*throttleCounter
+	mu        sync.Mutex
+}
+
+type throttleCounter struct {
+	start time.Time
+	count int
+}
+
+// NewAlertFilter creates a new alert filter. Zero values disable deduplication and throttling.
+// The alert history is optional and keeps deduplicating the alerts after restarts.
+func NewAlertFilter(dedupeWindow time.Duration, maxPerMinute int, history store.AlertHistoryStore) *AlertFilter {
+	return &AlertFilter{
+		dedupeWindow: dedupeWindow,
+		maxPerMinute: maxPerMinute,
+		history:      history,
+		seen:         make(map[string]time.Time),
+		windows:      make(map[string]*throttleCounter),
+	}
+}
+
+// Check tells if the alert should be dropped and returns the drop metric.
+func (af *AlertFilter) Check(botID string, finding *protocol.Finding, now time.Time) (metric string, drop bool) {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	if af.dedupeWindow > 0 {
+		af.prune(now)
+		key := dedupeKey(botID, finding)
+		if seenAt, ok := af.seenAt(key); ok && now.Sub(seenAt) < af.dedupeWindow {
+			return metrics.MetricAlertDuplicate, true
+		}
+		af.seen[key] = now
+		if af.history != nil {
+			if err := af.history.PutAlertTime(key, now); err != nil {
+				log.WithError(err).Warn("failed to save alert history")
+			}
+		}
+	}
+
+	if af.maxPerMinute > 0 {
+		counter, ok := af.windows[botID]
+		if !ok || now.Sub(counter.start) >= throttleWindow {
+			counter = &throttleCounter{start: now}
+			af.windows[botID] = counter
+		}
+		counter.count++
+		if counter.count > af.maxPerMinute {
+			return metrics.MetricAlertThrottled, true
+		}
+	}
+
+	return "", false
+}
+
+// seenAt returns the last time the alert was seen, either in memory or in the alert history.
+func (af *AlertFilter) seenAt(key string) (time.Time, bool) {
+	if seenAt, ok := af.seen[key]; ok {
+		return seenAt, true
+	}
+	if af.history == nil {
+		return time.Time{}, false
+	}
+	seenAt, ok, err := af.history.GetAlertTime(key)
+	if err != nil {
+		log.WithError(err).Warn("failed to get alert history")
+	}
+	return seenAt, ok
+}
+
+// prune deallocates the expired entries once per dedupe window.
+func (af *AlertFilter) prune(now time.Time) {
+	if now.Sub(af.lastPrune) < af.dedupeWindow {
+		return
+	}
+	if af.history != nil {
+		if err := af.history.PruneAlertHistory(now.Add(-af.dedupeWindow)); err != nil {
+			log.WithError(err).Warn("failed to prune alert history")
+		}
+	}
+	for key, seenAt := range af.seen {
+		if now.Sub(seenAt) >= af.dedupeWindow {
+			delete(af.seen, key)
+		}
+	}
+	for botID, counter := range af.windows {
+		if now.Sub(counter.start) >= throttleWindow {
+			delete(af.windows, botID)
+		}
+	}
+	af.lastPrune = now
+}
+
+// dedupeKey identifies the identical findings of a bot regardless of the event.
+func dedupeKey(botID string, finding *protocol.Finding) string {
+	addresses := make([]string, 0, len(finding.Addresses))
+	for _, address := range finding.Addresses {
+		addresses = append(addresses, strings.ToLower(address))
+	}
+	sort.Strings(addresses)
+	return strings.Join([]string{strings.ToLower(botID), finding.AlertId, strings.Join(addresses, ",")}, "|")
+}
+
+// filteringAlertSender applies the alert filter before sending the alerts.
+type filteringAlertSender struct {
+	clients.AlertSender
+	msgClient clients.MessageClient
+	filter    *AlertFilter
+}
+
+// NewFilteringAlertSender wraps the alert sender with the alert filter.
+func NewFilteringAlertSender(alertSender clients.AlertSender, msgClient clients.MessageClient, filter *AlertFilter) clients.AlertSender {
+	return &filteringAlertSender{
+		AlertSender: alertSender,
+		msgClient:   msgClient,
+		filter:      filter,
+	}
+}
+
+// SignAlertAndNotify implements clients.AlertSender.
+func (fas *filteringAlertSender) SignAlertAndNotify(
+	rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps,
+) error {
+	if alert.Finding != nil {
+		if metric, drop := fas.filter.Check(rt.AgentConfig.ID, alert.Finding, time.Now()); drop {
+			log.WithFields(log.Fields{
+				"bot":     rt.AgentConfig.ID,
+				"alertId": alert.Finding.AlertId,
+				"reason":  metric,
+			}).Debug("dropping alert")
+			metrics.SendAgentMetrics(fas.msgClient, []*protocol.AgentMetric{
+				metrics.CreateAgentMetric(rt.AgentConfig, metric, 1),
+			})
+			return nil
+		}
+	}
+	return fas.AlertSender.SignAlertAndNotify(rt, alert, chainID, blockNumber, ts)
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertFilter_Dedupe(t *testing.T) {
+	r := require.New(t)
+
+	filter := NewAlertFilter(time.Minute, 0, nil)
+	now := time.Now()
+
+	finding := &protocol.Finding{AlertId: "ALERT-1", Addresses: []string{"0xB", "0xa"}}
+	sameFinding := &protocol.Finding{AlertId: "ALERT-1", Addresses: []string{"0xA", "0xb"}}
+	otherFinding := &protocol.Finding{AlertId: "ALERT-2", Addresses: []string{"0xa", "0xb"}}
+
+	_, drop := filter.Check("0x1", finding, now)
+	r.False(drop)
+	metric, drop := filter.Check("0x1", sameFinding, now.Add(time.Second))
+	r.True(drop)
+	r.Equal(metrics.MetricAlertDuplicate, metric)
+
+	_, drop = filter.Check("0x1", otherFinding, now)
+	r.False(drop)
+	_, drop = filter.Check("0x2", finding, now)
+	r.False(drop)
+
+	// allowed again after the window
+	_, drop = filter.Check("0x1", finding, now.Add(2*time.Minute))
+	r.False(drop)
+}
+
+func TestAlertFilter_DedupeHistory(t *testing.T) {
+	r := require.New(t)
+
+	history, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer history.Close()
+
+	now := time.Now()
+	finding := &protocol.Finding{AlertId: "ALERT-1"}
+
+	_, drop := NewAlertFilter(time.Minute, 0, history).Check("0x1", finding, now)
+	r.False(drop)
+
+	// a new filter remembers the alert from the history
+	metric, drop := NewAlertFilter(time.Minute, 0, history).Check("0x1", finding, now.Add(time.Second))
+	r.True(drop)
+	r.Equal(metrics.MetricAlertDuplicate, metric)
+}
+
+func TestAlertFilter_Throttle(t *testing.T) {
+	r := require.New(t)
+
+	filter := NewAlertFilter(0, 2, nil)
+	now := time.Now()
+	finding := &protocol.Finding{AlertId: "ALERT-1"}
+
+	_, drop := filter.Check("0x1", finding, now)
+	r.False(drop)
+	_, drop = filter.Check("0x1", finding, now)
+	r.False(drop)
+	metric, drop := filter.Check("0x1", finding, now)
+	r.True(drop)
+	r.Equal(metrics.MetricAlertThrottled, metric)
+
+	// other bots are not affected
+	_, drop = filter.Check("0x2", finding, now)
+	r.False(drop)
+
+	// next window
+	_, drop = filter.Check("0x1", finding, now.Add(time.Minute))
+	r.False(drop)
+}
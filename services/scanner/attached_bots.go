@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// logAttachedBotResponse prints the response of a bot which is attached from the host of a
+// developer, with the findings as JSON. The findings are logged before they are filtered, so
+// that the developer can see everything which the bot returns.
+func logAttachedBotResponse(
+	agentConfig config.AgentConfig, requestID string, fields log.Fields,
+	status protocol.ResponseStatus, errs []*protocol.Error, latencyMs uint32, findings []*protocol.Finding,
+) {
+	if !agentConfig.IsAttached() {
+		return
+	}
+	logger := log.WithFields(fields).WithFields(log.Fields{
+		"bot":       agentConfig.ID,
+		"request":   requestID,
+		"status":    status.String(),
+		"latencyMs": latencyMs,
+		"findings":  len(findings),
+	})
+	for _, err := range errs {
+		logger.WithField("error", err.GetMessage()).Warn("attached bot returned an error")
+	}
+	if len(findings) == 0 {
+		logger.Debug("attached bot returned no findings")
+		return
+	}
+	for i, finding := range findings {
+		b, err := protojson.Marshal(finding)
+		if err != nil {
+			logger.WithError(err).Error("failed to encode the finding of the attached bot")
+			continue
+		}
+		logger.WithField("index", i).Infof("attached bot finding: %s", b)
+	}
+}
@@ -0,0 +1,203 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// BeaconFeed gets the blocks of the beacon chain from a consensus client and sends the
+// attestations, the proposals, the slashings and the exits of each slot to the bots which
+// subscribe to the beacon events, so that the bots can monitor the staking infrastructure.
+type BeaconFeed struct {
+	ctx context.Context
+	cfg BeaconFeedConfig
+
+	// the next slot to get the block of
+	nextSlot uint64
+	lastSlot uint64
+
+	lastEvent    health.TimeTracker
+	lastCheckErr health.ErrorTracker
+	eventCount   uint64
+	missedCount  uint64
+}
+
+// BeaconFeedConfig contains the beacon feed configuration.
+type BeaconFeedConfig struct {
+	ChainID       int
+	Client        beacon.Client
+	BotPool       botio.BotPool
+	RequestSender botio.Sender
+	MaxSlotRange  uint64
+	Interval      time.Duration
+	// starts from the head slot if zero
+	Start uint64
+}
+
+// NewBeaconFeed creates a new beacon feed.
+func NewBeaconFeed(ctx context.Context, cfg BeaconFeedConfig) (*BeaconFeed, error) {
+	if cfg.MaxSlotRange == 0 {
+		return nil, errors.New("beacon feed max slot range is required")
+	}
+	return &BeaconFeed{
+		ctx:      ctx,
+		cfg:      cfg,
+		nextSlot: cfg.Start,
+	}, nil
+}
+
+// Start implements services.Service.
+func (bf *BeaconFeed) Start() error {
+	go func() {
+		ticker := time.NewTicker(bf.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bf.ctx.Done():
+				return
+			case <-ticker.C:
+				err := bf.poll()
+				bf.lastCheckErr.Set(err)
+				if err != nil {
+					log.WithError(err).WithField("chainId", bf.cfg.ChainID).Warn("failed to get the beacon events for the bots")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (bf *BeaconFeed) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (bf *BeaconFeed) Name() string {
+	return "beacon-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (bf *BeaconFeed) Health() health.Reports {
+	return health.Reports{
+		bf.lastEvent.GetReport("event.beacon.time"),
+		bf.lastCheckErr.GetReport("event.beacon.error"),
+		&health.Report{
+			Name:    "event.beacon.count",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&bf.eventCount), 10),
+		},
+		&health.Report{
+			Name:    "event.beacon.missed",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&bf.missedCount), 10),
+		},
+		&health.Report{
+			Name:    "event.beacon.slot",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&bf.lastSlot), 10),
+		},
+	}
+}
+
+// poll sends the events of the slots from the next slot until the head slot, up to the max slot
+// range in each poll.
+func (bf *BeaconFeed) poll() error {
+	head, err := bf.cfg.Client.HeadSlot(bf.ctx)
+	if err != nil {
+		return err
+	}
+	if bf.nextSlot == 0 {
+		bf.nextSlot = head
+	}
+	// the slots are skipped if no bot subscribes to the beacon events
+	if !bf.hasSubscribers() {
+		if head >= bf.nextSlot {
+			bf.nextSlot = head + 1
+		}
+		return nil
+	}
+	to := head
+	if to >= bf.nextSlot+bf.cfg.MaxSlotRange {
+		to = bf.nextSlot + bf.cfg.MaxSlotRange - 1
+	}
+	for bf.nextSlot <= to {
+		if err := bf.ctx.Err(); err != nil {
+			return err
+		}
+		if err := bf.processSlot(bf.nextSlot); err != nil {
+			return err
+		}
+		atomic.StoreUint64(&bf.lastSlot, bf.nextSlot)
+		bf.nextSlot++
+	}
+	return nil
+}
+
+func (bf *BeaconFeed) processSlot(slot uint64) error {
+	evt, err := bf.cfg.Client.Event(bf.ctx, slot)
+	if errors.Is(err, beacon.ErrMissedSlot) {
+		atomic.AddUint64(&bf.missedCount, 1)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	req, err := BeaconEventToRequest(bf.cfg.ChainID, evt)
+	if err != nil {
+		return fmt.Errorf("failed to create the beacon request of slot %d: %v", slot, err)
+	}
+	bf.cfg.RequestSender.SendEvaluateBeaconRequest(req)
+	atomic.AddUint64(&bf.eventCount, 1)
+	bf.lastEvent.Set()
+	return nil
+}
+
+// hasSubscribers tells if any bot subscribes to the beacon events of this chain.
+func (bf *BeaconFeed) hasSubscribers() bool {
+	for _, bot := range bf.cfg.BotPool.GetCurrentBotClients() {
+		filters := bot.Config().Filters
+		if filters != nil && containsString(filters.EventTypes, config.BotEventBeacon) && filtersChain(filters, bf.cfg.ChainID) {
+			return true
+		}
+	}
+	return false
+}
+
+// BeaconEventToRequest converts the beacon event to a block evaluation request. The block event
+// refers to the execution-layer block of the slot and contains the beacon event.
+func BeaconEventToRequest(chainID int, evt *beacon.Event) (*protocol.EvaluateBlockRequest, error) {
+	blockNumber := hexutil.EncodeUint64(evt.ExecutionBlockNumber)
+	blockEvt := &protocol.BlockEvent{
+		Type:        protocol.BlockEvent_BLOCK,
+		BlockHash:   evt.ExecutionBlockHash,
+		BlockNumber: blockNumber,
+		Network:     &protocol.BlockEvent_Network{ChainId: hexutil.EncodeUint64(uint64(chainID))},
+		Block: &protocol.BlockEvent_EthBlock{
+			Hash:      evt.ExecutionBlockHash,
+			Number:    blockNumber,
+			Timestamp: hexutil.EncodeUint64(evt.Timestamp),
+		},
+		Timestamps: &protocol.TrackingTimestamps{Feed: time.Now().UTC().Format(time.RFC3339Nano)},
+	}
+	if err := beacon.AttachToBlock(blockEvt, evt); err != nil {
+		return nil, err
+	}
+	return &protocol.EvaluateBlockRequest{
+		RequestId: uuid.Must(uuid.NewUUID()).String(),
+		Event:     blockEvt,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testBeaconClient struct {
+	head   uint64
+	missed map[uint64]bool
+}
+
+func (c *testBeaconClient) HeadSlot(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *testBeaconClient) Event(ctx context.Context, slot uint64) (*beacon.Event, error) {
+	if c.missed[slot] {
+		return nil, beacon.ErrMissedSlot
+	}
+	return &beacon.Event{
+		Slot:                 slot,
+		Epoch:                slot / beacon.SlotsPerEpoch,
+		ExecutionBlockNumber: slot + 1000,
+		ExecutionBlockHash:   "0xblock",
+	}, nil
+}
+
+func TestBeaconFeed(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	sender := mock_botio.NewMockSender(ctrl)
+	beaconBot := mock_botio.NewMockBotClient(ctrl)
+	beaconBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x1", Filters: &config.BotFilters{
+		EventTypes: []string{config.BotEventBeacon},
+	}}).AnyTimes()
+	txBot := mock_botio.NewMockBotClient(ctrl)
+	txBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x2"}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{beaconBot, txBot}).AnyTimes()
+
+	client := &testBeaconClient{head: 110, missed: map[uint64]bool{102: true}}
+	bf, err := NewBeaconFeed(context.Background(), BeaconFeedConfig{
+		ChainID:       1,
+		Client:        client,
+		BotPool:       botPool,
+		RequestSender: sender,
+		MaxSlotRange:  5,
+		Start:         100,
+	})
+	r.NoError(err)
+
+	var slots []uint64
+	sender.EXPECT().SendEvaluateBeaconRequest(gomock.Any()).Do(func(req *protocol.EvaluateBlockRequest) {
+		evt, err := beacon.FromBlock(req.Event)
+		r.NoError(err)
+		r.Equal("0x1", req.Event.Network.ChainId)
+		r.Equal("0xblock", req.Event.BlockHash)
+		slots = append(slots, evt.Slot)
+	}).Times(4)
+
+	// the missed slot is skipped and the rest of the slots are left for the next poll
+	r.NoError(bf.poll())
+	r.Equal([]uint64{100, 101, 103, 104}, slots)
+	r.Equal(uint64(105), bf.nextSlot)
+	r.Equal(uint64(1), bf.missedCount)
+}
+
+func TestBeaconFeed_NoSubscribers(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	sender := mock_botio.NewMockSender(ctrl)
+	txBot := mock_botio.NewMockBotClient(ctrl)
+	txBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x2"}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{txBot}).AnyTimes()
+
+	bf, err := NewBeaconFeed(context.Background(), BeaconFeedConfig{
+		ChainID:       1,
+		Client:        &testBeaconClient{head: 110},
+		BotPool:       botPool,
+		RequestSender: sender,
+		MaxSlotRange:  5,
+		Start:         100,
+	})
+	r.NoError(err)
+
+	r.NoError(bf.poll())
+	r.Equal(uint64(111), bf.nextSlot)
+}
@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/clients/rawtx"
+	log "github.com/sirupsen/logrus"
+)
+
+// BlobSource provides the blob sidecars of the EIP-4844 transactions.
+type BlobSource interface {
+	Blobs(ctx context.Context, blockTimestamp uint64, versionedHashes []string) ([]*beacon.BlobSidecar, error)
+}
+
+// getBlobs returns the blob sidecars of the transaction or nil if the transaction has no blobs or
+// the sidecars are not available, e.g. after the retention period of the beacon node.
+func getBlobs(ctx context.Context, source BlobSource, blockTimestampHex string, rawTx *rawtx.RawTx) []*beacon.BlobSidecar {
+	if source == nil || rawTx == nil || len(rawTx.BlobVersionedHashes) == 0 || len(blockTimestampHex) == 0 {
+		return nil
+	}
+	blockTimestamp, err := hexutil.DecodeUint64(blockTimestampHex)
+	if err != nil {
+		return nil
+	}
+	sidecars, err := source.Blobs(ctx, blockTimestamp, rawTx.BlobVersionedHashes)
+	if err != nil {
+		log.WithError(err).WithField("tx", rawTx.Hash).Debug("failed to get the blob sidecars")
+		return nil
+	}
+	return sidecars
+}
@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/clients/rawtx"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlobSource struct {
+	sidecars map[string]*beacon.BlobSidecar
+}
+
+func (source *testBlobSource) Blobs(ctx context.Context, blockTimestamp uint64, versionedHashes []string) ([]*beacon.BlobSidecar, error) {
+	var sidecars []*beacon.BlobSidecar
+	for _, versionedHash := range versionedHashes {
+		sidecar, ok := source.sidecars[versionedHash]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+func TestGetBlobs(t *testing.T) {
+	r := require.New(t)
+
+	sidecar := &beacon.BlobSidecar{VersionedHash: "0x01aa"}
+	source := &testBlobSource{sidecars: map[string]*beacon.BlobSidecar{"0x01aa": sidecar}}
+	blobTx := &rawtx.RawTx{TypeName: rawtx.TypeBlob, BlobVersionedHashes: []string{"0x01aa"}}
+
+	r.Equal([]*beacon.BlobSidecar{sidecar}, getBlobs(context.Background(), source, "0x10", blobTx))
+	r.Nil(getBlobs(context.Background(), source, "0x10", &rawtx.RawTx{BlobVersionedHashes: []string{"0x01bb"}}))
+	// the transactions without blobs
+	r.Nil(getBlobs(context.Background(), source, "0x10", &rawtx.RawTx{TypeName: rawtx.TypeDynamicFee}))
+	r.Nil(getBlobs(context.Background(), source, "0x10", nil))
+	r.Nil(getBlobs(context.Background(), source, "invalid", blobTx))
+	r.Nil(getBlobs(context.Background(), nil, "0x10", blobTx))
+}
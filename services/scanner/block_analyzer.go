@@ -2,18 +2,27 @@ package scanner
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol/alerthash"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/clients/feehistory"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/components/tracing"
+	"github.com/forta-network/forta-node/store"
 
-	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
@@ -21,7 +30,20 @@ import (
 	"github.com/forta-network/forta-node/clients"
 )
 
-// BlockAnalyzerService reads TX info, calls agents, and emits results
+// BlockCheckpoint is the checkpoint name of the block feed.
+const BlockCheckpoint = "block"
+
+// ChainBlockCheckpoint returns the checkpoint name of the block feed of an additional chain.
+func ChainBlockCheckpoint(chainID int) string {
+	return fmt.Sprintf("%s-%d", BlockCheckpoint, chainID)
+}
+
+// PendingBlockSource knows the oldest block which is not sent to the block analyzer yet.
+type PendingBlockSource interface {
+	LowestPendingBlock() (uint64, bool)
+}
+
+// BlockAnalyzerService reads block info, calls agents, and emits results
 type BlockAnalyzerService struct {
 	ctx           context.Context
 	cfg           BlockAnalyzerServiceConfig
@@ -29,12 +51,33 @@ type BlockAnalyzerService struct {
 
 	lastInputActivity  health.TimeTracker
 	lastOutputActivity health.TimeTracker
+
+	processed uint64
+	inputDone chan struct{}
+
+	lastBlockNumber uint64
+	lastBlockTime   time.Time
+	lastBlockMu     sync.RWMutex
 }
 
 type BlockAnalyzerServiceConfig struct {
-	BlockChannel <-chan *domain.BlockEvent
-	AlertSender  clients.AlertSender
-	MsgClient    clients.MessageClient
+	BlockChannel  <-chan *domain.BlockEvent
+	ReorgDetector *ReorgDetector
+	AlertSender   clients.AlertSender
+	MsgClient     clients.MessageClient
+	BotWarnings   *BotWarnings
+	// logs a sample of the bot responses - nil logs nothing
+	ResponseLogger *ResponseLogger
+	Checkpoints    store.CheckpointStore
+	// the checkpoint name is BlockCheckpoint if not specified
+	Checkpoint string
+	// the blocks of the other shards are skipped - nil processes all blocks
+	Shard *Shard
+	// the checkpoint does not move past the blocks which are still pending - nil if the blocks
+	// are received in order
+	PendingBlocks PendingBlockSource
+	// attaches the gas context of the blocks - nil sends the blocks without it
+	GasContext GasContextSource
 	components.BotProcessing
 }
 
@@ -69,6 +112,7 @@ func (t *BlockAnalyzerService) findingToAlert(result *botreq.BlockResult, ts tim
 		"agentImage": result.AgentConfig.Image,
 		"agentId":    result.AgentConfig.ID,
 		"chainId":    chainId.String(),
+		"requestId":  result.Request.RequestId,
 	}
 
 	alertType := protocol.AlertType_PRIVATE
@@ -76,6 +120,12 @@ func (t *BlockAnalyzerService) findingToAlert(result *botreq.BlockResult, ts tim
 		alertType = protocol.AlertType_BLOCK
 		tags["blockHash"] = result.Request.Event.BlockHash
 		tags["blockNumber"] = blockNumber.String()
+		if beaconEvt, _ := beacon.FromBlock(result.Request.Event); beaconEvt != nil {
+			tags["beaconSlot"] = strconv.FormatUint(beaconEvt.Slot, 10)
+		}
+		if schedule, _ := agentgrpc.ScheduleFromBlock(result.Request.Event); schedule != nil {
+			tags["scheduleIntervalSeconds"] = strconv.Itoa(schedule.IntervalSeconds)
+		}
 	}
 
 	addressBloomFilter, err := t.createBloomFilter(f)
@@ -108,13 +158,22 @@ func (t *BlockAnalyzerService) Start() error {
 		for result := range t.cfg.Results.Block {
 			ts := time.Now().UTC()
 
-			m := jsonpb.Marshaler{}
-			resStr, err := m.MarshalToString(result.Response)
-			if err != nil {
-				log.Error("error marshaling response", err)
-				continue
+			t.cfg.ResponseLogger.Log(result.AgentConfig.ID, result.Response)
+			logAttachedBotResponse(
+				result.AgentConfig, result.Request.RequestId,
+				log.Fields{"block": result.Request.Event.GetBlockNumber()},
+				result.Response.Status, result.Response.Errors, result.Response.LatencyMs, result.Response.Findings,
+			)
+
+			beaconEvt, _ := beacon.FromBlock(result.Request.Event)
+			schedule, _ := agentgrpc.ScheduleFromBlock(result.Request.Event)
+			if t.cfg.Coverage != nil && beaconEvt == nil && schedule == nil {
+				chainID, _ := hexutil.DecodeUint64(result.Request.Event.GetNetwork().GetChainId())
+				blockNumber, _ := hexutil.DecodeUint64(result.Request.Event.GetBlockNumber())
+				t.cfg.Coverage.Acknowledged(chainID, result.AgentConfig.ID, blockNumber)
 			}
-			log.Debugf(resStr)
+
+			result.Response.Findings = filterFindings(t.cfg.MsgClient, t.cfg.BotWarnings, result.AgentConfig, result.Response.Findings)
 
 			rt := &clients.AgentRoundTrip{
 				AgentConfig:       result.AgentConfig,
@@ -133,14 +192,16 @@ func (t *BlockAnalyzerService) Start() error {
 			for _, f := range result.Response.Findings {
 				alert, err := t.findingToAlert(result, ts, f)
 				if err != nil {
-					log.WithError(err).Error("failed to transform finding to alert")
+					log.WithError(err).WithField("request", result.Request.RequestId).Error("failed to transform finding to alert")
 					continue
 				}
+				_, span := startPublishSpan(t.ctx, result.Request.Event.BlockHash, result.AgentConfig.ID, alert)
 				if err := t.cfg.AlertSender.SignAlertAndNotify(
 					rt, alert, result.Request.Event.Network.ChainId, result.Request.Event.BlockNumber, result.Timestamps,
 				); err != nil {
 					log.WithError(err).Panic("failed sign alert and notify")
 				}
+				span.End()
 			}
 			t.publishMetrics(result)
 
@@ -150,21 +211,61 @@ func (t *BlockAnalyzerService) Start() error {
 
 	// Gear 1: loops over blocks and distributes to all agents
 	go func() {
+		defer close(t.inputDone)
+
 		// for each block
-		for block := range t.cfg.BlockChannel {
+		for {
+			var (
+				block *domain.BlockEvent
+				ok    bool
+			)
+			select {
+			case <-t.ctx.Done():
+				return
+			case block, ok = <-t.cfg.BlockChannel:
+			}
+			if !ok {
+				return
+			}
+
 			// convert to message
+			_, span := startConvertSpan(t.ctx, tracing.SpanBlockConvert, block.Block)
 			blockEvt, err := block.ToMessage()
+			span.End()
 			if err != nil {
 				log.WithError(err).Error("error converting block event to message (skipping)")
 				continue
 			}
+			if t.cfg.ReorgDetector != nil && t.cfg.ReorgDetector.Observe(block.Block) {
+				log.WithFields(log.Fields{
+					"blockNumber": block.Block.Number,
+					"blockHash":   block.Block.Hash,
+				}).Warn("detected chain reorganization")
+				blockEvt.Type = protocol.BlockEvent_REORG
+			}
+			if gasContext := getGasContext(t.ctx, t.cfg.GasContext, blockEvt.BlockNumber); gasContext != nil {
+				if err := feehistory.AttachToBlock(blockEvt, gasContext); err != nil {
+					log.WithError(err).Warn("failed to attach the gas context")
+				}
+			}
 
-			// create a request
-			requestId := uuid.Must(uuid.NewUUID())
-			request := &protocol.EvaluateBlockRequest{RequestId: requestId.String(), Event: blockEvt}
+			// the skipped blocks still move the checkpoint and the last block of this node
+			if t.cfg.Shard.OwnsBlock(block.Block) {
+				// create a request
+				requestId := uuid.Must(uuid.NewUUID())
+				request := &protocol.EvaluateBlockRequest{RequestId: requestId.String(), Event: blockEvt}
 
-			// forward to the pool
-			t.cfg.RequestSender.SendEvaluateBlockRequest(request)
+				// forward to the pool
+				t.cfg.RequestSender.SendEvaluateBlockRequest(request)
+				atomic.AddUint64(&t.processed, 1)
+				if t.cfg.Coverage != nil {
+					chainID, _ := hexutil.DecodeUint64(blockEvt.GetNetwork().GetChainId())
+					blockNumber, _ := hexutil.DecodeUint64(blockEvt.BlockNumber)
+					t.cfg.Coverage.Processed(chainID, blockNumber)
+				}
+			}
+			t.saveCheckpoint(block)
+			t.setLastBlock(block)
 
 			t.lastInputActivity.Set()
 		}
@@ -173,8 +274,72 @@ func (t *BlockAnalyzerService) Start() error {
 	return nil
 }
 
+// saveCheckpoint saves the block number so the node can resume from it after restarts.
+func (t *BlockAnalyzerService) saveCheckpoint(block *domain.BlockEvent) {
+	if t.cfg.Checkpoints == nil || block.Block == nil {
+		return
+	}
+	blockNumber, err := hexutil.DecodeUint64(block.Block.Number)
+	if err != nil {
+		log.WithError(err).Warn("failed to decode block number for checkpoint")
+		return
+	}
+	if t.cfg.PendingBlocks != nil {
+		if lowest, ok := t.cfg.PendingBlocks.LowestPendingBlock(); ok && lowest <= blockNumber {
+			if lowest == 0 {
+				return
+			}
+			blockNumber = lowest - 1
+		}
+	}
+	checkpoint := t.cfg.Checkpoint
+	if len(checkpoint) == 0 {
+		checkpoint = BlockCheckpoint
+	}
+	if err := t.cfg.Checkpoints.PutCheckpoint(checkpoint, blockNumber); err != nil {
+		log.WithError(err).Warn("failed to save block checkpoint")
+	}
+}
+
+func (t *BlockAnalyzerService) setLastBlock(block *domain.BlockEvent) {
+	if block.Block == nil {
+		return
+	}
+	blockNumber, err := hexutil.DecodeUint64(block.Block.Number)
+	if err != nil {
+		return
+	}
+	blockTime, _ := block.Block.GetTimestamp()
+
+	t.lastBlockMu.Lock()
+	defer t.lastBlockMu.Unlock()
+
+	// the older blocks are backfilled after the newer ones if the blocks are prioritized
+	if t.cfg.PendingBlocks != nil && blockNumber < t.lastBlockNumber {
+		return
+	}
+	t.lastBlockNumber = blockNumber
+	if blockTime != nil {
+		t.lastBlockTime = *blockTime
+	}
+}
+
+// LastBlock returns the number and the timestamp of the last block sent to the bots.
+func (t *BlockAnalyzerService) LastBlock() (uint64, time.Time) {
+	t.lastBlockMu.RLock()
+	defer t.lastBlockMu.RUnlock()
+
+	return t.lastBlockNumber, t.lastBlockTime
+}
+
+// ProcessedCount returns the amount of blocks sent to the bots so far.
+func (t *BlockAnalyzerService) ProcessedCount() uint64 {
+	return atomic.LoadUint64(&t.processed)
+}
+
+// Stop waits for the input loop to stop consuming new events.
 func (t *BlockAnalyzerService) Stop() error {
-	return nil
+	return waitInputLoop(t.inputDone)
 }
 
 func (t *BlockAnalyzerService) Name() string {
@@ -191,7 +356,8 @@ func (t *BlockAnalyzerService) Health() health.Reports {
 
 func NewBlockAnalyzerService(ctx context.Context, cfg BlockAnalyzerServiceConfig) (*BlockAnalyzerService, error) {
 	return &BlockAnalyzerService{
-		cfg: cfg,
-		ctx: ctx,
+		cfg:       cfg,
+		ctx:       ctx,
+		inputDone: make(chan struct{}),
 	}, nil
 }
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockResult(private bool) *botreq.BlockResult {
+	return &botreq.BlockResult{
+		AgentConfig: config.AgentConfig{
+			ID:    "0x1",
+			Image: "bafybeibvkqkf7i3ixwdm3aj4q4wkz7spsbrt3lmdhizdlgzxsyhia5e7ey",
+		},
+		Request: &protocol.EvaluateBlockRequest{
+			RequestId: "1",
+			Event: &protocol.BlockEvent{
+				BlockHash:   "0xabc",
+				BlockNumber: "0x10",
+				Network:     &protocol.BlockEvent_Network{ChainId: "0x1"},
+				Block:       &protocol.BlockEvent_EthBlock{},
+			},
+		},
+		Response:   &protocol.EvaluateBlockResponse{Private: private},
+		Timestamps: &domain.TrackingTimestamps{},
+	}
+}
+
+func TestBlockAnalyzerService_findingToAlert(t *testing.T) {
+	r := require.New(t)
+
+	blockAnalyzer := &BlockAnalyzerService{}
+	finding := &protocol.Finding{AlertId: "ALERT-1", Addresses: []string{"0xaaa"}}
+
+	alert, err := blockAnalyzer.findingToAlert(testBlockResult(false), time.Now(), finding)
+	r.NoError(err)
+	r.Equal(protocol.AlertType_BLOCK, alert.Type)
+	r.Equal("1", alert.Tags["chainId"])
+	r.Equal("16", alert.Tags["blockNumber"])
+	r.Equal("0xabc", alert.Tags["blockHash"])
+	r.Equal("1", alert.Tags["requestId"])
+	r.NotEmpty(alert.Id)
+	r.NotNil(alert.AddressBloomFilter)
+}
+
+func TestBlockAnalyzerService_findingToAlertPrivate(t *testing.T) {
+	r := require.New(t)
+
+	blockAnalyzer := &BlockAnalyzerService{}
+	finding := &protocol.Finding{AlertId: "ALERT-1"}
+
+	alert, err := blockAnalyzer.findingToAlert(testBlockResult(true), time.Now(), finding)
+	r.NoError(err)
+	r.Equal(protocol.AlertType_PRIVATE, alert.Type)
+	r.Empty(alert.Tags["blockHash"])
+	r.Empty(alert.Tags["blockNumber"])
+	// the request can be traced even if the alert is private
+	r.Equal("1", alert.Tags["requestId"])
+}
+
+type testPendingBlocks struct {
+	lowest uint64
+	ok     bool
+}
+
+func (tpb *testPendingBlocks) LowestPendingBlock() (uint64, bool) {
+	return tpb.lowest, tpb.ok
+}
+
+func TestBlockAnalyzerService_saveCheckpointPending(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	pending := &testPendingBlocks{lowest: 8, ok: true}
+	analyzer := &BlockAnalyzerService{cfg: BlockAnalyzerServiceConfig{Checkpoints: localStore, PendingBlocks: pending}}
+
+	// the checkpoint stays behind the oldest pending block
+	analyzer.saveCheckpoint(testBlockEvent(10))
+	analyzer.setLastBlock(testBlockEvent(10))
+	checkpoint, _, err := localStore.GetCheckpoint(BlockCheckpoint)
+	r.NoError(err)
+	r.Equal(uint64(7), checkpoint)
+
+	// the backfilled blocks do not move the last block back
+	pending.ok = false
+	analyzer.saveCheckpoint(testBlockEvent(9))
+	analyzer.setLastBlock(testBlockEvent(9))
+	checkpoint, _, err = localStore.GetCheckpoint(BlockCheckpoint)
+	r.NoError(err)
+	r.Equal(uint64(9), checkpoint)
+	lastBlock, _ := analyzer.LastBlock()
+	r.Equal(uint64(10), lastBlock)
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/services/components/botio"
+	log "github.com/sirupsen/logrus"
+)
+
+// LastBlockSource knows the last block which was sent to the bots.
+type LastBlockSource interface {
+	LastBlock() (uint64, time.Time)
+}
+
+// BlockLagMonitor compares the chain head with the last processed block and raises an
+// alarm when the difference exceeds the threshold.
+type BlockLagMonitor struct {
+	ctx context.Context
+	cfg BlockLagMonitorConfig
+
+	lag     uint64
+	alarmed atomic.Bool
+
+	lastCheck    health.TimeTracker
+	lastCheckErr health.ErrorTracker
+}
+
+// BlockLagMonitorConfig contains the block lag monitor configuration.
+type BlockLagMonitorConfig struct {
+	ChainID   int
+	EthClient ethereum.Client
+	Blocks    LastBlockSource
+	// the bots receive a warning finding when the alarm is raised
+	BotPool     botio.BotPool
+	BotWarnings *BotWarnings
+	Threshold   uint64
+	Interval    time.Duration
+}
+
+// NewBlockLagMonitor creates a new block lag monitor.
+func NewBlockLagMonitor(ctx context.Context, cfg BlockLagMonitorConfig) (*BlockLagMonitor, error) {
+	if cfg.Threshold == 0 {
+		return nil, errors.New("block lag threshold is required")
+	}
+	return &BlockLagMonitor{
+		ctx: ctx,
+		cfg: cfg,
+	}, nil
+}
+
+// Start implements services.Service.
+func (blm *BlockLagMonitor) Start() error {
+	go func() {
+		ticker := time.NewTicker(blm.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-blm.ctx.Done():
+				return
+			case <-ticker.C:
+				blm.check()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (blm *BlockLagMonitor) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (blm *BlockLagMonitor) Name() string {
+	return "block-lag-monitor"
+}
+
+// Lag returns the amount of blocks which the node was behind the chain head at the last check.
+func (blm *BlockLagMonitor) Lag() uint64 {
+	return atomic.LoadUint64(&blm.lag)
+}
+
+// Health implements the health.Reporter interface.
+func (blm *BlockLagMonitor) Health() health.Reports {
+	lagReport := &health.Report{
+		Name:    "blocks",
+		Status:  health.StatusOK,
+		Details: strconv.FormatUint(blm.Lag(), 10),
+	}
+	if blm.alarmed.Load() {
+		lagReport.Status = health.StatusLagging
+	}
+	return health.Reports{
+		lagReport,
+		blm.lastCheck.GetReport("event.checked.time"),
+		blm.lastCheckErr.GetReport("event.checked.error"),
+	}
+}
+
+func (blm *BlockLagMonitor) check() {
+	head, err := blm.cfg.EthClient.BlockNumber(blm.ctx)
+	blm.lastCheckErr.Set(err)
+	if err != nil {
+		log.WithError(err).Warn("failed to get the chain head for block lag check")
+		return
+	}
+	blm.lastCheck.Set()
+
+	last, lastTime := blm.cfg.Blocks.LastBlock()
+	// nothing is processed yet
+	if last == 0 {
+		return
+	}
+	var lag uint64
+	if head.Uint64() > last {
+		lag = head.Uint64() - last
+	}
+	atomic.StoreUint64(&blm.lag, lag)
+
+	logger := log.WithFields(log.Fields{
+		"chainId":   blm.cfg.ChainID,
+		"head":      head.Uint64(),
+		"lastBlock": last,
+		"lag":       lag,
+	})
+	if lag <= blm.cfg.Threshold {
+		if blm.alarmed.CompareAndSwap(true, false) {
+			logger.Info("caught up with the chain head")
+		}
+		return
+	}
+	// warn only once until the node catches up
+	if !blm.alarmed.CompareAndSwap(false, true) {
+		return
+	}
+	logger.WithField("threshold", blm.cfg.Threshold).Warn("processed blocks are falling behind the chain head")
+	if blm.cfg.BotPool == nil || blm.cfg.BotWarnings == nil {
+		return
+	}
+	// the sharded bots have a client for each shard
+	warned := make(map[string]bool)
+	for _, bot := range blm.cfg.BotPool.GetCurrentBotClients() {
+		botID := strings.ToLower(bot.Config().ID)
+		if warned[botID] {
+			continue
+		}
+		warned[botID] = true
+		blm.cfg.BotWarnings.Add(botID, blockLagWarning(blm.cfg.ChainID, head.Uint64(), last, lastTime, blm.cfg.Threshold))
+	}
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testLastBlock uint64
+
+func (lb *testLastBlock) LastBlock() (uint64, time.Time) {
+	return uint64(*lb), time.Now().Add(-time.Minute)
+}
+
+func TestBlockLagMonitor(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	bot := mock_botio.NewMockBotClient(ctrl)
+	bot.EXPECT().Config().Return(config.AgentConfig{ID: "0xABC"}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	// two shards of the same bot
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{bot, bot})
+
+	lastBlock := testLastBlock(0)
+	alertSender := &countingAlertSender{}
+	botWarnings := NewBotWarnings(BotWarningsConfig{ChainID: 1, AlertSender: alertSender, Cooldown: time.Hour})
+	blm, err := NewBlockLagMonitor(context.Background(), BlockLagMonitorConfig{
+		ChainID:     1,
+		EthClient:   ethClient,
+		Blocks:      &lastBlock,
+		BotPool:     botPool,
+		BotWarnings: botWarnings,
+		Threshold:   10,
+	})
+	r.NoError(err)
+
+	// nothing is processed yet
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(100), nil)
+	blm.check()
+	r.Zero(blm.Lag())
+
+	lastBlock = 95
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(100), nil)
+	blm.check()
+	r.Equal(uint64(5), blm.Lag())
+	r.Equal(health.StatusOK, blm.Health()[0].Status)
+	r.Empty(alertSender.sent)
+
+	// should warn the bots only once until caught up
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(120), nil).Times(2)
+	blm.check()
+	blm.check()
+	r.Equal(uint64(25), blm.Lag())
+	r.Equal(health.StatusLagging, blm.Health()[0].Status)
+	r.Len(alertSender.sent, 1)
+	finding := alertSender.sent[0].Finding
+	r.Equal(BlockLagAlertID, finding.AlertId)
+	r.Equal("25", finding.Metadata["lag"])
+	r.NoError(validateFinding(finding))
+
+	lastBlock = 120
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(121), nil)
+	blm.check()
+	r.Equal(uint64(1), blm.Lag())
+	r.Equal(health.StatusOK, blm.Health()[0].Status)
+}
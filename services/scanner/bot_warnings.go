@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	log "github.com/sirupsen/logrus"
+)
+
+// BotWarnings publishes the node warning findings about the bots as the findings of the node, so
+// that the warnings are not published as the results of the bots. A warning of the same kind is
+// not repeated for a bot until the cooldown passes.
+type BotWarnings struct {
+	cfg      BotWarningsConfig
+	cooldown *findingCooldown
+}
+
+// BotWarningsConfig contains the bot warnings configuration.
+type BotWarningsConfig struct {
+	ChainID     int
+	AlertSender clients.AlertSender
+	// the resource limit and the throttling warnings are received from the messages - nil disables them
+	MsgClient clients.MessageClient
+	Cooldown  time.Duration
+}
+
+// NewBotWarnings creates new bot warnings and subscribes to the resource limit and the throttling messages.
+func NewBotWarnings(cfg BotWarningsConfig) *BotWarnings {
+	bw := &BotWarnings{cfg: cfg, cooldown: newFindingCooldown(cfg.Cooldown)}
+	if cfg.MsgClient != nil {
+		cfg.MsgClient.Subscribe(messaging.SubjectAgentsStatusLimited, messaging.AgentLimitHandler(bw.handleLimitExceeded))
+		cfg.MsgClient.Subscribe(messaging.SubjectAgentsStatusThrottled, messaging.AgentThrottleHandler(bw.handleThrottled))
+	}
+	return bw
+}
+
+func (bw *BotWarnings) handleLimitExceeded(payload messaging.AgentLimitPayload) error {
+	bw.Add(payload.Agent.ID, resourceLimitWarning(payload))
+	return nil
+}
+
+func (bw *BotWarnings) handleThrottled(payload messaging.AgentThrottlePayload) error {
+	bw.Add(payload.Agent.ID, throttledWarning(payload))
+	return nil
+}
+
+// Add publishes the warning finding about the bot unless the same kind of warning was published
+// for the bot within the cooldown.
+func (bw *BotWarnings) Add(botID string, finding *protocol.Finding) {
+	if bw == nil || bw.cfg.AlertSender == nil {
+		return
+	}
+
+	key := strings.Join([]string{finding.AlertId, strings.ToLower(botID)}, "|")
+	if !bw.cooldown.allow(key) {
+		return
+	}
+	if err := sendNodeFinding(bw.cfg.AlertSender, bw.cfg.ChainID, key, finding); err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"alertId": finding.AlertId,
+			"botId":   botID,
+		}).Warn("failed to send bot warning")
+	}
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotWarnings(t *testing.T) {
+	r := require.New(t)
+
+	alertSender := &countingAlertSender{}
+	bw := NewBotWarnings(BotWarningsConfig{ChainID: 1, AlertSender: alertSender, Cooldown: time.Hour})
+	r.NoError(bw.handleLimitExceeded(messaging.AgentLimitPayload{
+		Agent:     config.AgentConfig{ID: "0xABC", Image: "bot-image"},
+		Resource:  "memory",
+		Usage:     1000,
+		Limit:     1000,
+		Restarted: true,
+	}))
+
+	r.Len(alertSender.sent, 1)
+	alert := alertSender.sent[0]
+	r.Equal(NodeBotID, alert.Agent.Id)
+	r.True(alert.Finding.Private)
+	finding := alert.Finding
+	r.Equal(ResourceLimitAlertID, finding.AlertId)
+	r.Equal("memory", finding.Metadata["resource"])
+	r.Equal("1000", finding.Metadata["limit"])
+	r.Equal("bot-image", finding.Metadata["botImage"])
+	r.Equal("true", finding.Metadata["restarted"])
+	r.Contains(finding.Description, "restarted")
+	r.NoError(validateFinding(finding))
+
+	// should not repeat the warning for the same bot within the cooldown
+	bw.Add("0xabc", resourceLimitWarning(messaging.AgentLimitPayload{
+		Agent:    config.AgentConfig{ID: "0xabc"},
+		Resource: "cpu",
+	}))
+	r.Len(alertSender.sent, 1)
+
+	// should warn about the other bots
+	bw.Add("0xdef", resourceLimitWarning(messaging.AgentLimitPayload{
+		Agent:    config.AgentConfig{ID: "0xdef"},
+		Resource: "cpu",
+	}))
+	r.Len(alertSender.sent, 2)
+
+	// should warn about the throttled bots
+	r.NoError(bw.handleThrottled(messaging.AgentThrottlePayload{
+		Agent:   config.AgentConfig{ID: "0xabc"},
+		Policy:  config.BackpressureDropNewest,
+		Dropped: 10,
+	}))
+	r.Len(alertSender.sent, 3)
+	finding = alertSender.sent[2].Finding
+	r.Equal(ThrottledAlertID, finding.AlertId)
+	r.Equal("10", finding.Metadata["dropped"])
+	r.Equal(config.BackpressureDropNewest, finding.Metadata["policy"])
+	r.NoError(validateFinding(finding))
+
+	var nilWarnings *BotWarnings
+	nilWarnings.Add("0xabc", finding)
+}
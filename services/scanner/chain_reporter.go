@@ -0,0 +1,24 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+)
+
+// ChainReporter prefixes the name of a reporter from an additional chain pipeline with the
+// chain id, so that the reports do not collide with the reports of the main chain pipeline.
+type ChainReporter struct {
+	health.Reporter
+	chainID int
+}
+
+// NewChainReporter creates a new chain reporter.
+func NewChainReporter(chainID int, reporter health.Reporter) *ChainReporter {
+	return &ChainReporter{Reporter: reporter, chainID: chainID}
+}
+
+// Name returns the name of the reporter.
+func (cr *ChainReporter) Name() string {
+	return fmt.Sprintf("chain-%d.%s", cr.chainID, cr.Reporter.Name())
+}
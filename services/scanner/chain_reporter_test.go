@@ -0,0 +1,16 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainReporter(t *testing.T) {
+	r := require.New(t)
+
+	reporter := NewChainReporter(137, NewIdentityReporter(common.HexToAddress("0x1")))
+	r.Equal("chain-137.identity", reporter.Name())
+	r.Len(reporter.Health(), 1)
+}
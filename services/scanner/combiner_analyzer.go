@@ -16,7 +16,6 @@ import (
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
 
-	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
@@ -38,7 +37,12 @@ type CombinerAlertAnalyzerServiceConfig struct {
 	AlertChannel <-chan *domain.AlertEvent
 	AlertSender  clients.AlertSender
 	MsgClient    clients.MessageClient
-	ChainID      string
+	BotWarnings  *BotWarnings
+	// logs a sample of the bot responses - nil logs nothing
+	ResponseLogger *ResponseLogger
+	ChainID        string
+	// the alerts of the other shards are skipped - nil processes all alerts
+	Shard *Shard
 	components.BotProcessing
 }
 
@@ -64,6 +68,7 @@ func (aas *CombinerAlertAnalyzerService) findingToAlert(result *botreq.Combinati
 		"agentImage": result.AgentConfig.Image,
 		"agentId":    result.AgentConfig.ID,
 		"chainId":    chainId.String(),
+		"requestId":  result.Request.RequestId,
 	}
 
 	alertType := protocol.AlertType_PRIVATE
@@ -101,13 +106,9 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 		for result := range aas.cfg.Results.CombinationAlert {
 			ts := time.Now().UTC()
 
-			m := jsonpb.Marshaler{}
-			resStr, err := m.MarshalToString(result.Response)
-			if err != nil {
-				log.Error("error marshaling response", err)
-				continue
-			}
-			log.Debugf(resStr)
+			aas.cfg.ResponseLogger.Log(result.AgentConfig.ID, result.Response)
+
+			result.Response.Findings = filterFindings(aas.cfg.MsgClient, aas.cfg.BotWarnings, result.AgentConfig, result.Response.Findings)
 
 			rt := &clients.AgentRoundTrip{
 				AgentConfig:       result.AgentConfig,
@@ -128,14 +129,16 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 			for _, f := range result.Response.Findings {
 				alert, err := aas.findingToAlert(result, ts, f)
 				if err != nil {
-					log.WithError(err).Error("failed to transform finding to alert")
+					log.WithError(err).WithField("request", result.Request.RequestId).Error("failed to transform finding to alert")
 					continue
 				}
+				_, span := startPublishSpan(aas.ctx, "", result.AgentConfig.ID, alert)
 				if err := aas.cfg.AlertSender.SignAlertAndNotify(
 					rt, alert, chainID, "", result.Timestamps,
 				); err != nil {
 					log.WithError(err).Panic("failed sign alert and notify")
 				}
+				span.End()
 			}
 			aas.publishMetrics(result)
 
@@ -156,6 +159,11 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 			)
 
 			logger.Debug("received alert")
+			if !aas.cfg.Shard.OwnsAlert(alertEvt.Event.Alert.Hash) {
+				logger.Debug("alert is in another shard (skipping)")
+				aas.lastInputActivity.Set()
+				continue
+			}
 
 			// convert to message
 			alertEvtMsg, err := alertEvt.ToMessage()
@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/domain"
@@ -22,13 +24,23 @@ type CombinerAlertStreamService struct {
 	subscribeChan     chan string
 	unSubscribeChan   chan string
 	lastAlertActivity health.TimeTracker
+
+	// the alerts which are received from both of the feeds are sent once
+	seenAlerts map[string]time.Time
+	lastPrune  time.Time
+	mu         sync.Mutex
 }
 
 type CombinerAlertStreamServiceConfig struct {
 	Start uint64
 	End   uint64
+
+	// LocalFeed is the optional feed of the alerts of the bots on this node.
+	LocalFeed feeds.AlertFeed
 }
 
+const seenAlertsWindow = time.Hour
+
 func (t *CombinerAlertStreamService) registerMessageHandlers() {
 	t.msgClient.Subscribe(messaging.SubjectAgentsAlertSubscribe, messaging.SubscriptionHandler(t.handleMessageSubscribe))
 	t.msgClient.Subscribe(messaging.SubjectAgentsAlertUnsubscribe, messaging.SubscriptionHandler(t.handleMessageUnsubscribe))
@@ -45,6 +57,10 @@ func (t *CombinerAlertStreamService) handleAlert(evt *domain.AlertEvent) error {
 	default:
 	}
 
+	if t.isDuplicate(evt) {
+		return nil
+	}
+
 	log.WithFields(
 		log.Fields{
 			"subscribee":     evt.Event.Alert.Source.Bot.Id,
@@ -60,12 +76,45 @@ func (t *CombinerAlertStreamService) handleAlert(evt *domain.AlertEvent) error {
 	return nil
 }
 
+// isDuplicate tells if the alert was already sent to the subscriber through the other feed.
+func (t *CombinerAlertStreamService) isDuplicate(evt *domain.AlertEvent) bool {
+	if t.cfg.LocalFeed == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastPrune) >= seenAlertsWindow {
+		for key, seenAt := range t.seenAlerts {
+			if now.Sub(seenAt) >= seenAlertsWindow {
+				delete(t.seenAlerts, key)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	key := evt.Subscriber.BotID + "|" + evt.Event.Alert.Hash
+	if _, ok := t.seenAlerts[key]; ok {
+		return true
+	}
+	t.seenAlerts[key] = now
+	return false
+}
+
 func (t *CombinerAlertStreamService) Start() error {
 	t.registerMessageHandlers()
 	go func() {
 		t.alertFeed.RegisterHandler(t.handleAlert)
 		t.alertFeed.Start()
 	}()
+	if t.cfg.LocalFeed != nil {
+		go func() {
+			t.cfg.LocalFeed.RegisterHandler(t.handleAlert)
+			t.cfg.LocalFeed.Start()
+		}()
+	}
 	return nil
 }
 
@@ -104,6 +153,10 @@ func (t *CombinerAlertStreamService) handleMessageSubscribe(payload messaging.Su
 			continue
 		}
 
+		if t.cfg.LocalFeed != nil {
+			// the error is the same with the one below if any
+			_ = t.cfg.LocalFeed.AddSubscription(subscription)
+		}
 		err := t.alertFeed.AddSubscription(subscription)
 		if err != nil {
 			log.WithFields(
@@ -126,6 +179,9 @@ func (t *CombinerAlertStreamService) handleMessageUnsubscribe(payload messaging.
 		}
 
 		t.alertFeed.RemoveSubscription(subscription)
+		if t.cfg.LocalFeed != nil {
+			t.cfg.LocalFeed.RemoveSubscription(subscription)
+		}
 	}
 
 	return nil
@@ -140,5 +196,6 @@ func NewCombinerAlertStreamService(ctx context.Context, alertFeed feeds.AlertFee
 		msgClient:   msgClient,
 		alertOutput: alertOutput,
 		alertFeed:   alertFeed,
+		seenAlerts:  make(map[string]time.Time),
 	}, nil
 }
@@ -0,0 +1,180 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ElidedContent tells what was elided from a transaction event.
+type ElidedContent struct {
+	// the calldata size of the transaction before it was cut
+	InputBytes int `json:"inputBytes,omitempty"`
+	// the amount of the trace inputs which were cut
+	TraceInputs int `json:"traceInputs,omitempty"`
+	// the amount of the traces which were removed
+	Traces int `json:"traces,omitempty"`
+	// the amount of the logs which were removed
+	Logs int `json:"logs,omitempty"`
+}
+
+func (elided *ElidedContent) empty() bool {
+	return *elided == ElidedContent{}
+}
+
+// ContentLimits caps the content of the transaction events.
+type ContentLimits struct {
+	cfg       config.ContentLimitsConfig
+	msgClient clients.MessageClient
+}
+
+// NewContentLimits creates new content limits. It returns nil if nothing is limited.
+func NewContentLimits(cfg config.ContentLimitsConfig, msgClient clients.MessageClient) *ContentLimits {
+	if cfg.MaxCalldataBytes == 0 && cfg.MaxTraceDepth == 0 && cfg.MaxLogs == 0 {
+		return nil
+	}
+	return &ContentLimits{cfg: cfg, msgClient: msgClient}
+}
+
+// Apply elides the content of the event which exceeds the limits and marks the event. It returns
+// false if the event exceeds the limits and the policy is to drop such events.
+func (cl *ContentLimits) Apply(msg *protocol.TransactionEvent) bool {
+	if cl == nil {
+		return true
+	}
+	elided := cl.elide(msg, cl.cfg.Policy == config.ContentLimitDrop)
+	if elided.empty() {
+		return true
+	}
+	if cl.cfg.Policy == config.ContentLimitDrop {
+		cl.sendMetric(metrics.MetricTxContentDropped)
+		return false
+	}
+	if err := attachElided(msg, elided); err != nil {
+		log.WithError(err).WithField("tx", msg.GetTransaction().GetHash()).Warn("failed to mark the elided content")
+	}
+	cl.sendMetric(metrics.MetricTxContentTruncated)
+	return true
+}
+
+// elide cuts the content which exceeds the limits. It only checks the limits if dryRun is true.
+func (cl *ContentLimits) elide(msg *protocol.TransactionEvent, dryRun bool) (elided ElidedContent) {
+	if max := cl.cfg.MaxCalldataBytes; max > 0 {
+		if tx := msg.Transaction; tx != nil && hexSize(tx.Input) > max {
+			elided.InputBytes = hexSize(tx.Input)
+			if !dryRun {
+				tx.Input = cutHex(tx.Input, max)
+			}
+		}
+		for _, trace := range msg.Traces {
+			if action := trace.GetAction(); action != nil && hexSize(action.Input) > max {
+				elided.TraceInputs++
+				if !dryRun {
+					action.Input = cutHex(action.Input, max)
+				}
+			}
+		}
+	}
+
+	if max := cl.cfg.MaxTraceDepth; max > 0 {
+		traces := msg.Traces[:0:0]
+		for _, trace := range msg.Traces {
+			if len(trace.TraceAddress) > max {
+				elided.Traces++
+				continue
+			}
+			traces = append(traces, trace)
+		}
+		if !dryRun && elided.Traces > 0 {
+			msg.Traces = traces
+		}
+	}
+
+	if max := cl.cfg.MaxLogs; max > 0 && len(msg.Logs) > max {
+		elided.Logs = len(msg.Logs) - max
+		if !dryRun {
+			msg.Logs = msg.Logs[:max]
+		}
+	}
+	// the receipt logs are usually the same logs
+	if max := cl.cfg.MaxLogs; max > 0 && msg.Receipt != nil && len(msg.Receipt.Logs) > max {
+		if elided.Logs == 0 {
+			elided.Logs = len(msg.Receipt.Logs) - max
+		}
+		if !dryRun {
+			msg.Receipt.Logs = msg.Receipt.Logs[:max]
+		}
+	}
+	return
+}
+
+func (cl *ContentLimits) sendMetric(name string) {
+	metrics.SendAgentMetrics(cl.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(config.AgentConfig{ID: "system"}, name, 1),
+	})
+}
+
+// hexSize returns the size of the hex data in bytes.
+func hexSize(data string) int {
+	if len(data) >= 2 && data[:2] == "0x" {
+		data = data[2:]
+	}
+	return len(data) / 2
+}
+
+// cutHex cuts the hex data to the max size in bytes.
+func cutHex(data string, max int) string {
+	prefix := ""
+	if len(data) >= 2 && data[:2] == "0x" {
+		prefix, data = "0x", data[2:]
+	}
+	return prefix + data[:max*2]
+}
+
+func attachElided(msg *protocol.TransactionEvent, elided ElidedContent) error {
+	b, err := json.Marshal(elided)
+	if err != nil {
+		return fmt.Errorf("failed to encode the elided content: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.ElidedFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+// ElidedFromMessage reads what was elided from the event. It returns nil if nothing was elided.
+func ElidedFromMessage(msg *protocol.TransactionEvent) (*ElidedContent, error) {
+	unknown := msg.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+		if num == agentgrpc.ElidedFieldNumber && typ == protowire.BytesType {
+			b, n := protowire.ConsumeBytes(unknown)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			var elided ElidedContent
+			if err := json.Unmarshal(b, &elided); err != nil {
+				return nil, fmt.Errorf("failed to decode the elided content: %v", err)
+			}
+			return &elided, nil
+		}
+		n = protowire.ConsumeFieldValue(num, typ, unknown)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		unknown = unknown[n:]
+	}
+	return nil, nil
+}
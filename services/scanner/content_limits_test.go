@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testLargeTxEvent() *protocol.TransactionEvent {
+	logs := []*protocol.TransactionEvent_Log{{LogIndex: "0x0"}, {LogIndex: "0x1"}, {LogIndex: "0x2"}}
+	return &protocol.TransactionEvent{
+		Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0x1", Input: "0xaabbccddeeff"},
+		Traces: []*protocol.TransactionEvent_Trace{
+			{Action: &protocol.TransactionEvent_TraceAction{Input: "0x0102"}},
+			{Action: &protocol.TransactionEvent_TraceAction{Input: "0x010203040506"}, TraceAddress: []int64{0}},
+			{Action: &protocol.TransactionEvent_TraceAction{Input: "0x01"}, TraceAddress: []int64{0, 0}},
+			{Action: &protocol.TransactionEvent_TraceAction{Input: "0x01"}, TraceAddress: []int64{0, 0, 1}},
+		},
+		Logs:    logs,
+		Receipt: &protocol.TransactionEvent_EthReceipt{Logs: logs},
+	}
+}
+
+func TestContentLimits(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(NewContentLimits(config.ContentLimitsConfig{Policy: config.ContentLimitTruncate}, nil))
+	r.True((*ContentLimits)(nil).Apply(testLargeTxEvent()))
+
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	limits := NewContentLimits(config.ContentLimitsConfig{
+		MaxCalldataBytes: 2,
+		MaxTraceDepth:    1,
+		MaxLogs:          2,
+		Policy:           config.ContentLimitTruncate,
+	}, msgClient)
+
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	msg := testLargeTxEvent()
+	r.True(limits.Apply(msg))
+	r.Equal("0xaabb", msg.Transaction.Input)
+	r.Len(msg.Traces, 2)
+	r.Equal("0x0102", msg.Traces[0].Action.Input)
+	r.Equal("0x0102", msg.Traces[1].Action.Input)
+	r.Len(msg.Logs, 2)
+	r.Len(msg.Receipt.Logs, 2)
+
+	elided, err := ElidedFromMessage(msg)
+	r.NoError(err)
+	r.Equal(&ElidedContent{InputBytes: 6, TraceInputs: 1, Traces: 2, Logs: 1}, elided)
+
+	// the events within the limits are not marked
+	msg = &protocol.TransactionEvent{Transaction: &protocol.TransactionEvent_EthTransaction{Input: "0x01"}}
+	r.True(limits.Apply(msg))
+	elided, err = ElidedFromMessage(msg)
+	r.NoError(err)
+	r.Nil(elided)
+}
+
+func TestContentLimits_Drop(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	limits := NewContentLimits(config.ContentLimitsConfig{MaxLogs: 2, Policy: config.ContentLimitDrop}, msgClient)
+
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	msg := testLargeTxEvent()
+	r.False(limits.Apply(msg))
+	// the dropped event is left as it is
+	r.Len(msg.Logs, 3)
+
+	r.True(limits.Apply(&protocol.TransactionEvent{}))
+}
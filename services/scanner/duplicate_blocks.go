@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// the amount of latest skipped blocks which the transactions are skipped for
+const maxSkippedBlocks = 16
+
+// DuplicateBlocks skips the blocks which were already dispatched and the transactions of these
+// blocks. The feed can deliver the same block again after a failover between the json-rpc
+// providers, which would make the bots create the same alerts twice.
+type DuplicateBlocks struct {
+	feed      string
+	window    time.Duration
+	store     store.DispatchStore
+	msgClient clients.MessageClient
+
+	skipped   []string
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+// NewDuplicateBlocks creates a new duplicate block filter for the feed. The blocks are remembered
+// for the window.
+func NewDuplicateBlocks(
+	feed string, window time.Duration, dispatches store.DispatchStore, msgClient clients.MessageClient,
+) *DuplicateBlocks {
+	return &DuplicateBlocks{
+		feed:      feed,
+		window:    window,
+		store:     dispatches,
+		msgClient: msgClient,
+	}
+}
+
+// Forget lets the blocks from the start block be dispatched again, since the feed restarts
+// from the checkpoint of the last processed block on purpose.
+func (db *DuplicateBlocks) Forget(startBlock uint64) error {
+	return db.store.ForgetDispatched(db.feed, startBlock)
+}
+
+// ShouldDispatch saves the block as dispatched and tells if it was not dispatched before. The
+// block is dispatched if the store fails.
+func (db *DuplicateBlocks) ShouldDispatch(evt *domain.BlockEvent, now time.Time) bool {
+	if evt.Block == nil || len(evt.Block.Hash) == 0 {
+		return true
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.prune(now)
+	number, _ := hexutil.DecodeUint64(evt.Block.Number)
+	first, err := db.store.PutDispatched(db.feed, evt.Block.Hash, number, now)
+	if err != nil {
+		log.WithError(err).Warn("failed to check the dispatched blocks")
+		return true
+	}
+	if first {
+		return true
+	}
+	log.WithFields(log.Fields{
+		"block": evt.Block.Number,
+		"hash":  evt.Block.Hash,
+	}).Info("skipping the block which was already dispatched")
+	db.skipped = append(db.skipped, evt.Block.Hash)
+	if len(db.skipped) > maxSkippedBlocks {
+		db.skipped = db.skipped[1:]
+	}
+	db.sendMetric(metrics.MetricBlockDuplicate)
+	return false
+}
+
+// ShouldDispatchTx tells if the block of the transaction was dispatched.
+func (db *DuplicateBlocks) ShouldDispatchTx(evt *domain.TransactionEvent) bool {
+	if evt.BlockEvt == nil || evt.BlockEvt.Block == nil {
+		return true
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, hash := range db.skipped {
+		if hash == evt.BlockEvt.Block.Hash {
+			db.sendMetric(metrics.MetricTxDuplicate)
+			return false
+		}
+	}
+	return true
+}
+
+func (db *DuplicateBlocks) sendMetric(name string) {
+	metrics.SendAgentMetrics(db.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(config.AgentConfig{ID: "system"}, name, 1),
+	})
+}
+
+// prune deletes the expired blocks once per window.
+func (db *DuplicateBlocks) prune(now time.Time) {
+	if now.Sub(db.lastPrune) < db.window {
+		return
+	}
+	if err := db.store.PruneDispatched(now.Add(-db.window)); err != nil {
+		log.WithError(err).Warn("failed to prune the dispatched blocks")
+	}
+	db.lastPrune = now
+}
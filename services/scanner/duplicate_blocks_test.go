@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testDuplicateBlockEvent(number, hash string) *domain.BlockEvent {
+	return &domain.BlockEvent{Block: &domain.Block{Number: number, Hash: hash}}
+}
+
+func TestDuplicateBlocks(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	localStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	duplicates := NewDuplicateBlocks(BlockCheckpoint, time.Hour, localStore, msgClient)
+	now := time.Now()
+	block1 := testDuplicateBlockEvent("0x1", "0xaa")
+	block2 := testDuplicateBlockEvent("0x2", "0xbb")
+	r.True(duplicates.ShouldDispatch(block1, now))
+	r.True(duplicates.ShouldDispatch(block2, now))
+	r.True(duplicates.ShouldDispatchTx(&domain.TransactionEvent{BlockEvt: block2}))
+
+	// the block is delivered again with its transactions
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).Times(2)
+	r.False(duplicates.ShouldDispatch(testDuplicateBlockEvent("0x1", "0xaa"), now))
+	r.False(duplicates.ShouldDispatchTx(&domain.TransactionEvent{BlockEvt: block1}))
+	r.True(duplicates.ShouldDispatchTx(&domain.TransactionEvent{BlockEvt: block2}))
+
+	// the reorged block at the same height is not a duplicate
+	r.True(duplicates.ShouldDispatch(testDuplicateBlockEvent("0x2", "0xcc"), now))
+
+	// a restarted filter still remembers the blocks until they are forgotten
+	duplicates = NewDuplicateBlocks(BlockCheckpoint, time.Hour, localStore, msgClient)
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	r.False(duplicates.ShouldDispatch(block1, now))
+	r.NoError(duplicates.Forget(2))
+	r.True(duplicates.ShouldDispatch(block2, now))
+
+	// the blocks expire after the window
+	r.True(duplicates.ShouldDispatch(block1, now.Add(time.Hour*2)))
+}
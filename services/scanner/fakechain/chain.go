@@ -0,0 +1,294 @@
+package fakechain
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultAccounts is the amount of the accounts if none is configured.
+const DefaultAccounts = 100
+
+const (
+	transferGas = 21000
+	gasLimit    = 30000000
+)
+
+// Config configures the fake chain.
+type Config struct {
+	ChainID    *big.Int
+	StartBlock uint64
+	// the last block to generate - zero generates the blocks until the context is cancelled
+	EndBlock uint64
+	// zero generates the blocks as fast as the subscribers handle them
+	BlockInterval time.Duration
+	TxsPerBlock   int
+	// the amount of the accounts which send and receive the transactions
+	Accounts int
+	// the same seed generates the same blocks and transactions
+	Seed int64
+	// the timestamp of the start block - zero uses the time when the chain is started
+	StartTime time.Time
+	// the distribution of the transactions - uses the default profile if not set
+	Profile *TxProfile
+}
+
+type handler struct {
+	handle func(evt *domain.BlockEvent) error
+	errCh  chan error
+}
+
+// Chain generates synthetic blocks and transactions deterministically from the seed. It implements
+// the block feed, so that the scanner pipeline can be tested and load tested without a chain.
+type Chain struct {
+	ctx      context.Context
+	cfg      Config
+	accounts []string
+
+	started   bool
+	startTime time.Time
+	mu        sync.RWMutex
+
+	handlers   []*handler
+	handlersMu sync.RWMutex
+
+	lastBlock health.MessageTracker
+}
+
+var _ feeds.BlockFeed = &Chain{}
+
+// New creates a new fake chain.
+func New(ctx context.Context, cfg Config) *Chain {
+	if cfg.ChainID == nil {
+		cfg.ChainID = big.NewInt(1)
+	}
+	if cfg.TxsPerBlock < 0 {
+		cfg.TxsPerBlock = 0
+	}
+	if cfg.Accounts <= 0 {
+		cfg.Accounts = DefaultAccounts
+	}
+	if cfg.Profile == nil {
+		cfg.Profile = DefaultProfile()
+	}
+	accounts := make([]string, cfg.Accounts)
+	for i := range accounts {
+		accounts[i] = common.BytesToAddress(hashOf(cfg.Seed, "account", uint64(i)).Bytes()).Hex()
+	}
+	return &Chain{
+		ctx:       ctx,
+		cfg:       cfg,
+		accounts:  accounts,
+		startTime: cfg.StartTime,
+	}
+}
+
+// Start starts generating the blocks from the start block to the end block.
+func (c *Chain) Start() {
+	if !c.setStarted() {
+		return
+	}
+	go c.loop(c.cfg.StartBlock, c.cfg.EndBlock, c.cfg.BlockInterval)
+}
+
+// StartRange generates a specific range of blocks at the given rate in milliseconds.
+func (c *Chain) StartRange(start int64, end int64, rate int64) {
+	if !c.setStarted() {
+		return
+	}
+	go c.loop(uint64(start), uint64(end), time.Duration(rate)*time.Millisecond)
+}
+
+// IsStarted tells if the chain is generating the blocks.
+func (c *Chain) IsStarted() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.started
+}
+
+func (c *Chain) setStarted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return false
+	}
+	c.started = true
+	if c.startTime.IsZero() {
+		c.startTime = time.Now()
+	}
+	return true
+}
+
+// Subscribe adds a handler which receives each block. The returned channel receives the error
+// which stopped the chain, including feeds.ErrEndBlockReached.
+func (c *Chain) Subscribe(handle func(evt *domain.BlockEvent) error) <-chan error {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	errCh := make(chan error)
+	c.handlers = append(c.handlers, &handler{handle: handle, errCh: errCh})
+	return errCh
+}
+
+func (c *Chain) loop(start, end uint64, interval time.Duration) {
+	err := c.forEachBlock(start, end, interval)
+	if err != feeds.ErrEndBlockReached && err != context.Canceled {
+		log.WithError(err).Warn("fake chain stopped")
+	}
+
+	c.handlersMu.RLock()
+	handlers := c.handlers
+	c.handlersMu.RUnlock()
+	for _, h := range handlers {
+		h.errCh <- err
+	}
+}
+
+func (c *Chain) forEachBlock(start, end uint64, interval time.Duration) error {
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+	}
+	for number := start; end == 0 || number <= end; number++ {
+		if ticker != nil {
+			select {
+			case <-c.ctx.Done():
+				return c.ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		default:
+		}
+
+		evt := c.BlockEvent(number)
+		c.handlersMu.RLock()
+		handlers := c.handlers
+		c.handlersMu.RUnlock()
+		for _, h := range handlers {
+			if err := h.handle(evt); err != nil {
+				return err
+			}
+		}
+		c.lastBlock.Set(evt.Block.Number)
+	}
+	return feeds.ErrEndBlockReached
+}
+
+// BlockEvent returns the block event of the block number.
+func (c *Chain) BlockEvent(number uint64) *domain.BlockEvent {
+	block := c.Block(number)
+	blockTs, _ := block.GetTimestamp()
+	return &domain.BlockEvent{
+		EventType: domain.EventTypeBlock,
+		ChainID:   c.cfg.ChainID,
+		Block:     block,
+		Timestamps: &domain.TrackingTimestamps{
+			Block: *blockTs,
+			Feed:  time.Now().UTC(),
+		},
+	}
+}
+
+// Block generates the block of the number. The same seed and block number always generate the
+// same block and transactions.
+func (c *Chain) Block(number uint64) *domain.Block {
+	rnd := rand.New(rand.NewSource(c.cfg.Seed ^ int64(number)))
+	blockHash := hashOf(c.cfg.Seed, "block", number).Hex()
+	numberHex := hexutil.EncodeUint64(number)
+
+	baseFee := hexutil.EncodeUint64(uint64(rnd.Int63n(100)+1) * 1e9)
+	miner := c.accounts[rnd.Intn(len(c.accounts))]
+	block := &domain.Block{
+		BaseFeePerGas: &baseFee,
+		GasLimit:      strPtr(hexutil.EncodeUint64(gasLimit)),
+		GasUsed:       strPtr(hexutil.EncodeUint64(uint64(c.cfg.TxsPerBlock) * transferGas)),
+		Hash:          blockHash,
+		Miner:         &miner,
+		Number:        numberHex,
+		ParentHash:    hashOf(c.cfg.Seed, "block", number-1).Hex(),
+		Timestamp:     hexutil.EncodeUint64(uint64(c.blockTime(number).Unix())),
+	}
+	for i := 0; i < c.cfg.TxsPerBlock; i++ {
+		block.Transactions = append(block.Transactions, c.transaction(rnd, block, uint64(i)))
+	}
+	return block
+}
+
+func (c *Chain) transaction(rnd *rand.Rand, block *domain.Block, index uint64) domain.Transaction {
+	to := c.accounts[rnd.Intn(len(c.accounts))]
+	value := "0x0"
+	if rnd.Float64() < c.cfg.Profile.ValueTransferRatio {
+		value = hexutil.EncodeBig(big.NewInt(rnd.Int63()))
+	}
+	input := "0x"
+	if rnd.Float64() < c.cfg.Profile.ContractCallRatio {
+		data := make([]byte, c.cfg.Profile.inputSize(rnd))
+		rnd.Read(data)
+		input = hexutil.Encode(data)
+	}
+	number, _ := hexutil.DecodeUint64(block.Number)
+	return domain.Transaction{
+		BlockHash:        block.Hash,
+		BlockNumber:      block.Number,
+		From:             c.accounts[rnd.Intn(len(c.accounts))],
+		Gas:              hexutil.EncodeUint64(transferGas),
+		GasPrice:         *block.BaseFeePerGas,
+		Hash:             hashOf(c.cfg.Seed, "tx", number<<16|index).Hex(),
+		Input:            &input,
+		Nonce:            hexutil.EncodeUint64(uint64(rnd.Intn(1000))),
+		To:               &to,
+		TransactionIndex: hexutil.EncodeUint64(index),
+		Value:            &value,
+		V:                "0x1",
+		R:                hashOf(c.cfg.Seed, "r", number<<16|index).Hex(),
+		S:                hashOf(c.cfg.Seed, "s", number<<16|index).Hex(),
+	}
+}
+
+func (c *Chain) blockTime(number uint64) time.Time {
+	c.mu.RLock()
+	startTime := c.startTime
+	c.mu.RUnlock()
+	if startTime.IsZero() {
+		startTime = time.Unix(0, 0)
+	}
+	return startTime.Add(time.Duration(number-c.cfg.StartBlock) * c.cfg.BlockInterval)
+}
+
+// Name returns the name of this implementation.
+func (c *Chain) Name() string {
+	return "fake-chain"
+}
+
+// Health implements the health.Reporter interface.
+func (c *Chain) Health() health.Reports {
+	return health.Reports{
+		c.lastBlock.GetReport("last-block"),
+	}
+}
+
+func hashOf(seed int64, kind string, n uint64) common.Hash {
+	b := make([]byte, 16, 16+len(kind))
+	binary.BigEndian.PutUint64(b, uint64(seed))
+	binary.BigEndian.PutUint64(b[8:], n)
+	return crypto.Keccak256Hash(append(b, kind...))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
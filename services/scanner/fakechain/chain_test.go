@@ -0,0 +1,97 @@
+package fakechain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlock_Deterministic(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{TxsPerBlock: 5, Seed: 42, StartBlock: 10, StartTime: time.Unix(1000, 0), BlockInterval: time.Second}
+	chain1 := New(context.Background(), cfg)
+	chain2 := New(context.Background(), cfg)
+
+	block := chain1.Block(12)
+	r.Equal(block, chain2.Block(12))
+	r.Equal("0xc", block.Number)
+	r.Equal("0x3ea", block.Timestamp)
+	r.Equal(chain1.Block(11).Hash, block.ParentHash)
+	r.Len(block.Transactions, 5)
+	for _, tx := range block.Transactions {
+		r.Equal(block.Hash, tx.BlockHash)
+		r.Equal(block.Number, tx.BlockNumber)
+	}
+
+	cfg.Seed = 43
+	r.NotEqual(block.Hash, New(context.Background(), cfg).Block(12).Hash)
+}
+
+func TestSubscribe(t *testing.T) {
+	r := require.New(t)
+
+	chain := New(context.Background(), Config{StartBlock: 1, EndBlock: 3, TxsPerBlock: 1})
+	var numbers []string
+	errCh := chain.Subscribe(func(evt *domain.BlockEvent) error {
+		numbers = append(numbers, evt.Block.Number)
+		return nil
+	})
+	chain.Start()
+	r.True(chain.IsStarted())
+	r.ErrorIs(<-errCh, feeds.ErrEndBlockReached)
+	r.Equal([]string{"0x1", "0x2", "0x3"}, numbers)
+}
+
+func TestSubscribe_HandlerError(t *testing.T) {
+	r := require.New(t)
+
+	chain := New(context.Background(), Config{StartBlock: 1})
+	handlerErr := errors.New("failed")
+	errCh := chain.Subscribe(func(evt *domain.BlockEvent) error {
+		return handlerErr
+	})
+	chain.StartRange(5, 10, 1)
+	r.ErrorIs(<-errCh, handlerErr)
+}
+
+func TestSubscribe_Cancel(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chain := New(ctx, Config{BlockInterval: time.Millisecond})
+	errCh := chain.Subscribe(func(evt *domain.BlockEvent) error {
+		cancel()
+		return nil
+	})
+	chain.Start()
+	r.ErrorIs(<-errCh, context.Canceled)
+}
+
+func TestProfileFromBlocks(t *testing.T) {
+	r := require.New(t)
+
+	call, transfer := "0x12345678", "0x10"
+	empty, zero := "0x", "0x0"
+	profile := ProfileFromBlocks([]*domain.Block{
+		{Transactions: []domain.Transaction{{Input: &call, Value: &zero}, {Input: &empty, Value: &transfer}}},
+		{Transactions: []domain.Transaction{{Input: &empty, Value: &transfer}, {Input: &empty, Value: &zero}}},
+	})
+	r.Equal(0.25, profile.ContractCallRatio)
+	r.Equal(0.5, profile.ValueTransferRatio)
+	r.Equal([]int{4}, profile.InputSizes)
+
+	// the generated transactions follow the profile
+	chain := New(context.Background(), Config{TxsPerBlock: 100, Profile: &TxProfile{ContractCallRatio: 1, InputSizes: []int{8}}})
+	for _, tx := range chain.Block(1).Transactions {
+		r.Len(*tx.Input, 2+16)
+		r.Equal("0x0", *tx.Value)
+	}
+
+	r.Equal(DefaultProfile(), ProfileFromBlocks(nil))
+}
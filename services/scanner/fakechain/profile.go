@@ -0,0 +1,70 @@
+package fakechain
+
+import (
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+)
+
+// defaultInputSize is a function selector and a single argument.
+const defaultInputSize = 4 + 32
+
+// TxProfile is the distribution of the generated transactions. It can be recorded from the blocks
+// of a real chain, so that the synthetic traffic looks like the chain.
+type TxProfile struct {
+	// the ratio of the transactions which have input data
+	ContractCallRatio float64 `json:"contractCallRatio"`
+	// the ratio of the transactions which transfer value
+	ValueTransferRatio float64 `json:"valueTransferRatio"`
+	// the input sizes in bytes which are sampled for the transactions with input data
+	InputSizes []int `json:"inputSizes"`
+}
+
+// DefaultProfile returns the profile which is used if none is configured.
+func DefaultProfile() *TxProfile {
+	return &TxProfile{
+		ContractCallRatio:  0.25,
+		ValueTransferRatio: 1,
+		InputSizes:         []int{defaultInputSize},
+	}
+}
+
+func (profile *TxProfile) inputSize(rnd *rand.Rand) int {
+	if len(profile.InputSizes) == 0 {
+		return defaultInputSize
+	}
+	return profile.InputSizes[rnd.Intn(len(profile.InputSizes))]
+}
+
+// ProfileFromBlocks records the distribution of the transactions in the blocks.
+func ProfileFromBlocks(blocks []*domain.Block) *TxProfile {
+	var (
+		txs, calls, transfers int
+		inputSizes            []int
+	)
+	for _, block := range blocks {
+		for _, tx := range block.Transactions {
+			txs++
+			if tx.Input != nil {
+				if input, err := hexutil.Decode(*tx.Input); err == nil && len(input) > 0 {
+					calls++
+					inputSizes = append(inputSizes, len(input))
+				}
+			}
+			if tx.Value != nil {
+				if value, err := hexutil.DecodeBig(*tx.Value); err == nil && value.Sign() > 0 {
+					transfers++
+				}
+			}
+		}
+	}
+	if txs == 0 {
+		return DefaultProfile()
+	}
+	return &TxProfile{
+		ContractCallRatio:  float64(calls) / float64(txs),
+		ValueTransferRatio: float64(transfers) / float64(txs),
+		InputSizes:         inputSizes,
+	}
+}
@@ -0,0 +1,17 @@
+package scanner
+
+import (
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+)
+
+// Feed is a source of the chain events which the scanner pipeline is built on. The block feed
+// which follows the chain and the fake chain implement it.
+type Feed interface {
+	Start()
+	Subscribe(handler func(evt *domain.BlockEvent) error) <-chan error
+	health.Reporter
+}
+
+var _ Feed = (feeds.BlockFeed)(nil)
@@ -0,0 +1,227 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Finding validation errors
+var (
+	errFindingNil              = errors.New("finding is nil")
+	errFindingNoAlertID        = errors.New("finding has no alert id")
+	errFindingNoName           = errors.New("finding has no name")
+	errFindingBadSeverity      = errors.New("finding has unknown severity")
+	errFindingFieldTooLong     = errors.New("finding field is too long")
+	errFindingMetadataTooLarge = errors.New("finding metadata is too large")
+)
+
+// Finding limits
+const (
+	maxFindingAlertIDLength     = 128
+	maxFindingNameLength        = 256
+	maxFindingDescriptionLength = 4096
+	maxFindingProtocolLength    = 64
+	maxFindingMetadataKeys      = 100
+	maxFindingMetadataSize      = 16 * 1024 // total bytes of the keys and the values
+)
+
+// InvalidFindingsAlertID is the alert id of the warning finding which the node creates
+// when a bot sends invalid findings.
+const InvalidFindingsAlertID = "FORTA-NODE-INVALID-FINDINGS"
+
+// ResourceLimitAlertID is the alert id of the warning finding which the node creates
+// when a bot is restarted after exceeding a resource limit.
+const ResourceLimitAlertID = "FORTA-NODE-RESOURCE-LIMIT"
+
+// ThrottledAlertID is the alert id of the warning finding which the node creates
+// when a bot can not keep up with the requests and the requests are dropped.
+const ThrottledAlertID = "FORTA-NODE-BOT-THROTTLED"
+
+// BlockLagAlertID is the alert id of the warning finding which the node creates
+// when the processed blocks fall behind the chain head.
+const BlockLagAlertID = "FORTA-NODE-BLOCK-LAG"
+
+// normalizeFinding cleans up the finding fields which are safe to fix.
+func normalizeFinding(finding *protocol.Finding) {
+	finding.AlertId = strings.TrimSpace(finding.AlertId)
+	finding.Name = strings.TrimSpace(finding.Name)
+	finding.Description = strings.TrimSpace(finding.Description)
+	finding.Protocol = strings.TrimSpace(finding.Protocol)
+
+	if _, ok := protocol.Finding_FindingType_name[int32(finding.Type)]; !ok {
+		finding.Type = protocol.Finding_UNKNOWN_TYPE
+	}
+
+	for key := range finding.Metadata {
+		if len(strings.TrimSpace(key)) == 0 {
+			delete(finding.Metadata, key)
+		}
+	}
+}
+
+// validateFinding checks if the finding has the required fields and fits in the limits.
+func validateFinding(finding *protocol.Finding) error {
+	switch {
+	case finding == nil:
+		return errFindingNil
+	case len(finding.AlertId) == 0:
+		return errFindingNoAlertID
+	case len(finding.Name) == 0:
+		return errFindingNoName
+	}
+
+	if _, ok := protocol.Finding_Severity_name[int32(finding.Severity)]; !ok {
+		return fmt.Errorf("%w: %d", errFindingBadSeverity, finding.Severity)
+	}
+
+	for _, field := range []struct {
+		name   string
+		value  string
+		maxLen int
+	}{
+		{name: "alertId", value: finding.AlertId, maxLen: maxFindingAlertIDLength},
+		{name: "name", value: finding.Name, maxLen: maxFindingNameLength},
+		{name: "description", value: finding.Description, maxLen: maxFindingDescriptionLength},
+		{name: "protocol", value: finding.Protocol, maxLen: maxFindingProtocolLength},
+	} {
+		if len(field.value) > field.maxLen {
+			return fmt.Errorf("%w: %s has %d bytes (max %d)", errFindingFieldTooLong, field.name, len(field.value), field.maxLen)
+		}
+	}
+
+	if len(finding.Metadata) > maxFindingMetadataKeys {
+		return fmt.Errorf("%w: %d keys (max %d)", errFindingMetadataTooLarge, len(finding.Metadata), maxFindingMetadataKeys)
+	}
+	var metadataSize int
+	for key, value := range finding.Metadata {
+		metadataSize += len(key) + len(value)
+	}
+	if metadataSize > maxFindingMetadataSize {
+		return fmt.Errorf("%w: %d bytes (max %d)", errFindingMetadataTooLarge, metadataSize, maxFindingMetadataSize)
+	}
+
+	return nil
+}
+
+// invalidFindingsWarning creates the node warning finding about a bot which sent invalid findings.
+func invalidFindingsWarning(botConfig config.AgentConfig, invalidCount int, lastErr error) *protocol.Finding {
+	return &protocol.Finding{
+		AlertId:     InvalidFindingsAlertID,
+		Name:        "Invalid bot findings",
+		Description: fmt.Sprintf("Bot %s sent %d invalid findings which were dropped by the node", botConfig.ID, invalidCount),
+		Protocol:    "forta",
+		Severity:    protocol.Finding_LOW,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata: map[string]string{
+			"botId":        botConfig.ID,
+			"botImage":     botConfig.Image,
+			"invalidCount": strconv.Itoa(invalidCount),
+			"error":        lastErr.Error(),
+		},
+	}
+}
+
+// resourceLimitWarning creates the node warning finding about a bot which exceeded a resource limit.
+func resourceLimitWarning(payload messaging.AgentLimitPayload) *protocol.Finding {
+	description := fmt.Sprintf("Bot %s is at its %s limit", payload.Agent.ID, payload.Resource)
+	if payload.Restarted {
+		description = fmt.Sprintf("Bot %s exceeded its %s limit and was restarted by the node", payload.Agent.ID, payload.Resource)
+	}
+	return &protocol.Finding{
+		AlertId:     ResourceLimitAlertID,
+		Name:        "Bot resource limit exceeded",
+		Description: description,
+		Protocol:    "forta",
+		Severity:    protocol.Finding_MEDIUM,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata: map[string]string{
+			"botId":     payload.Agent.ID,
+			"botImage":  payload.Agent.Image,
+			"resource":  payload.Resource,
+			"usage":     strconv.FormatFloat(payload.Usage, 'f', -1, 64),
+			"limit":     strconv.FormatFloat(payload.Limit, 'f', -1, 64),
+			"restarted": strconv.FormatBool(payload.Restarted),
+		},
+	}
+}
+
+// throttledWarning creates the node warning finding about a bot which can not keep up with the requests.
+func throttledWarning(payload messaging.AgentThrottlePayload) *protocol.Finding {
+	return &protocol.Finding{
+		AlertId:     ThrottledAlertID,
+		Name:        "Bot can not keep up with the requests",
+		Description: fmt.Sprintf("Bot %s can not keep up and %d requests were dropped", payload.Agent.ID, payload.Dropped),
+		Protocol:    "forta",
+		Severity:    protocol.Finding_MEDIUM,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata: map[string]string{
+			"botId":    payload.Agent.ID,
+			"botImage": payload.Agent.Image,
+			"policy":   payload.Policy,
+			"dropped":  strconv.FormatUint(payload.Dropped, 10),
+		},
+	}
+}
+
+// blockLagWarning creates the node warning finding about the processed blocks falling behind the chain head.
+func blockLagWarning(chainID int, head, last uint64, lastTime time.Time, threshold uint64) *protocol.Finding {
+	metadata := map[string]string{
+		"chainId":   strconv.Itoa(chainID),
+		"head":      strconv.FormatUint(head, 10),
+		"lastBlock": strconv.FormatUint(last, 10),
+		"lag":       strconv.FormatUint(head-last, 10),
+		"threshold": strconv.FormatUint(threshold, 10),
+	}
+	if !lastTime.IsZero() {
+		metadata["lastBlockAgeSeconds"] = strconv.FormatInt(int64(time.Since(lastTime).Seconds()), 10)
+	}
+	return &protocol.Finding{
+		AlertId:     BlockLagAlertID,
+		Name:        "Node is falling behind the chain",
+		Description: fmt.Sprintf("Node is %d blocks behind the head of chain %d", head-last, chainID),
+		Protocol:    "forta",
+		Severity:    protocol.Finding_MEDIUM,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata:    metadata,
+	}
+}
+
+// filterFindings normalizes the findings from a bot response and drops the invalid ones.
+// If any findings are dropped, a warning finding about the bot is published by the node.
+func filterFindings(
+	msgClient clients.MessageClient, botWarnings *BotWarnings, botConfig config.AgentConfig, findings []*protocol.Finding,
+) (valid []*protocol.Finding) {
+	var (
+		invalidCount int
+		lastErr      error
+	)
+	for _, finding := range findings {
+		if finding != nil {
+			normalizeFinding(finding)
+		}
+		if err := validateFinding(finding); err != nil {
+			log.WithError(err).WithField("bot", botConfig.ID).Warn("dropping invalid finding")
+			invalidCount++
+			lastErr = err
+			continue
+		}
+		valid = append(valid, finding)
+	}
+	if invalidCount > 0 {
+		metrics.SendAgentMetrics(msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botConfig, metrics.MetricFindingInvalid, float64(invalidCount)),
+		})
+		botWarnings.Add(botConfig.ID, invalidFindingsWarning(botConfig, invalidCount, lastErr))
+	}
+	return
+}
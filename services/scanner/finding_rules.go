@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// FindingRules suppress or tag the findings before they are published.
+type FindingRules []config.FindingRule
+
+// Apply applies the first matching drop rule, or all of the matching tag rules to the alert tags,
+// and tells if the alert should be dropped.
+func (rules FindingRules) Apply(botID string, alert *protocol.Alert) (drop bool) {
+	for _, rule := range rules {
+		if !findingRuleMatches(rule, botID, alert.Finding) {
+			continue
+		}
+		if rule.Action != config.FindingRuleActionTag {
+			return true
+		}
+		if alert.Tags == nil {
+			alert.Tags = make(map[string]string)
+		}
+		for key, value := range rule.Tags {
+			alert.Tags[key] = value
+		}
+	}
+	return false
+}
+
+func findingRuleMatches(rule config.FindingRule, botID string, finding *protocol.Finding) bool {
+	if len(rule.Bots) > 0 && !containsFold(rule.Bots, botID) {
+		return false
+	}
+	if len(rule.AlertIDs) > 0 && !containsFold(rule.AlertIDs, finding.AlertId) {
+		return false
+	}
+	if len(rule.Severities) > 0 && !containsFold(rule.Severities, finding.Severity.String()) {
+		return false
+	}
+	if len(rule.Addresses) > 0 {
+		var found bool
+		for _, address := range finding.Addresses {
+			if containsFold(rule.Addresses, address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleAlertSender applies the finding rules before sending the alerts.
+type ruleAlertSender struct {
+	clients.AlertSender
+	msgClient clients.MessageClient
+	rules     FindingRules
+}
+
+// NewRuleAlertSender wraps the alert sender with the finding rules.
+func NewRuleAlertSender(alertSender clients.AlertSender, msgClient clients.MessageClient, rules FindingRules) clients.AlertSender {
+	return &ruleAlertSender{
+		AlertSender: alertSender,
+		msgClient:   msgClient,
+		rules:       rules,
+	}
+}
+
+// SignAlertAndNotify implements clients.AlertSender.
+func (ras *ruleAlertSender) SignAlertAndNotify(
+	rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps,
+) error {
+	if alert.Finding != nil && ras.rules.Apply(rt.AgentConfig.ID, alert) {
+		log.WithFields(log.Fields{
+			"bot":     rt.AgentConfig.ID,
+			"alertId": alert.Finding.AlertId,
+		}).Debug("suppressing alert by finding rule")
+		metrics.SendAgentMetrics(ras.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(rt.AgentConfig, metrics.MetricAlertSuppressed, 1),
+		})
+		return nil
+	}
+	return ras.AlertSender.SignAlertAndNotify(rt, alert, chainID, blockNumber, ts)
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testRuleAddress = "0x5b38Da6a701c568545dCfcB03FcB875f56beddC4"
+
+func TestFindingRules_Apply(t *testing.T) {
+	r := require.New(t)
+
+	rules := FindingRules{
+		{
+			AlertIDs: []string{"ALERT-1"},
+			Action:   config.FindingRuleActionTag,
+			Tags:     map[string]string{"team": "security"},
+		},
+		{
+			Bots:       []string{"0x1"},
+			Severities: []string{"LOW"},
+			Addresses:  []string{testRuleAddress},
+		},
+	}
+
+	newAlert := func(severity protocol.Finding_Severity, addresses ...string) *protocol.Alert {
+		return &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-1", Severity: severity, Addresses: addresses}}
+	}
+
+	// noisy alert for the muted address is dropped
+	r.True(rules.Apply("0x1", newAlert(protocol.Finding_LOW, "0x1", "0x5b38da6a701c568545dcfcb03fcb875f56beddc4")))
+
+	// other bots, severities and addresses are only tagged
+	for _, tc := range []struct {
+		botID string
+		alert *protocol.Alert
+	}{
+		{botID: "0x2", alert: newAlert(protocol.Finding_LOW, testRuleAddress)},
+		{botID: "0x1", alert: newAlert(protocol.Finding_HIGH, testRuleAddress)},
+		{botID: "0x1", alert: newAlert(protocol.Finding_LOW, "0x1")},
+	} {
+		r.False(rules.Apply(tc.botID, tc.alert))
+		r.Equal("security", tc.alert.Tags["team"])
+	}
+
+	// other alert ids are untouched
+	untouched := &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-2"}}
+	r.False(rules.Apply("0x2", untouched))
+	r.Nil(untouched.Tags)
+}
+
+type countingAlertSender struct {
+	clients.AlertSender
+	sent []*protocol.Alert
+}
+
+func (cas *countingAlertSender) SignAlertAndNotify(
+	rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps,
+) error {
+	cas.sent = append(cas.sent, alert)
+	return nil
+}
+
+func TestRuleAlertSender(t *testing.T) {
+	r := require.New(t)
+
+	alertSender := &countingAlertSender{}
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	sender := NewRuleAlertSender(alertSender, msgClient, FindingRules{{AlertIDs: []string{"ALERT-1"}}})
+
+	rt := &clients.AgentRoundTrip{AgentConfig: config.AgentConfig{ID: "0x1"}}
+
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+	r.NoError(sender.SignAlertAndNotify(rt, &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-1"}}, "0x1", "0x1", nil))
+
+	alert := &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-2"}}
+	r.NoError(sender.SignAlertAndNotify(rt, alert, "0x1", "0x1", nil))
+	r.Equal([]*protocol.Alert{alert}, alertSender.sent)
+}
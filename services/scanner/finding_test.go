@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeFinding(t *testing.T) {
+	r := require.New(t)
+
+	finding := &protocol.Finding{
+		AlertId: " ALERT-1 ",
+		Name:    "\tname\n",
+		Type:    protocol.Finding_FindingType(100),
+		Metadata: map[string]string{
+			" ":   "empty",
+			"key": "value",
+		},
+	}
+	normalizeFinding(finding)
+
+	r.Equal("ALERT-1", finding.AlertId)
+	r.Equal("name", finding.Name)
+	r.Equal(protocol.Finding_UNKNOWN_TYPE, finding.Type)
+	r.Equal(map[string]string{"key": "value"}, finding.Metadata)
+}
+
+func TestValidateFinding(t *testing.T) {
+	r := require.New(t)
+
+	r.ErrorIs(validateFinding(nil), errFindingNil)
+	r.ErrorIs(validateFinding(&protocol.Finding{Name: "name"}), errFindingNoAlertID)
+	r.ErrorIs(validateFinding(&protocol.Finding{AlertId: "ALERT-1"}), errFindingNoName)
+	r.NoError(validateFinding(&protocol.Finding{AlertId: "ALERT-1", Name: "name"}))
+
+	r.ErrorIs(validateFinding(&protocol.Finding{
+		AlertId: "ALERT-1", Name: "name", Severity: protocol.Finding_Severity(100),
+	}), errFindingBadSeverity)
+	r.ErrorIs(validateFinding(&protocol.Finding{
+		AlertId: "ALERT-1", Name: strings.Repeat("a", maxFindingNameLength+1),
+	}), errFindingFieldTooLong)
+	r.ErrorIs(validateFinding(&protocol.Finding{
+		AlertId: "ALERT-1", Name: "name", Metadata: map[string]string{"key": strings.Repeat("a", maxFindingMetadataSize)},
+	}), errFindingMetadataTooLarge)
+}
+
+func TestFilterFindings(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any())
+
+	valid := &protocol.Finding{AlertId: "ALERT-1", Name: "name"}
+	alertSender := &countingAlertSender{}
+	botWarnings := NewBotWarnings(BotWarningsConfig{ChainID: 1, AlertSender: alertSender, Cooldown: time.Hour})
+	findings := filterFindings(msgClient, botWarnings, config.AgentConfig{ID: "0x1"}, []*protocol.Finding{
+		valid, nil, {AlertId: "  "},
+	})
+	r.Equal([]*protocol.Finding{valid}, findings)
+
+	// the warning is published as a finding of the node
+	r.Len(alertSender.sent, 1)
+	warning := alertSender.sent[0].Finding
+	r.Equal(InvalidFindingsAlertID, warning.AlertId)
+	r.Equal("2", warning.Metadata["invalidCount"])
+	r.NoError(validateFinding(warning))
+}
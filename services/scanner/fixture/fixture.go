@@ -0,0 +1,235 @@
+package fixture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/scanner"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// maxMessageSize is the size limit of a single message in the protobuf fixtures.
+const maxMessageSize = 64 << 20
+
+// Fixture contains the chain data in the JSON-RPC format: the blocks with the full transactions
+// (eth_getBlockByNumber), the logs (eth_getLogs), the traces (trace_block) and the receipts
+// (eth_getTransactionReceipt). Only the blocks are required.
+type Fixture struct {
+	ChainID  uint64                       `json:"chainId"`
+	Blocks   []*domain.Block              `json:"blocks"`
+	Logs     []domain.LogEntry            `json:"logs"`
+	Traces   []domain.Trace               `json:"traces"`
+	Receipts []*domain.TransactionReceipt `json:"receipts"`
+}
+
+// Event is a block or a transaction event which is sent to the bot.
+type Event struct {
+	Block *protocol.BlockEvent
+	Tx    *protocol.TransactionEvent
+}
+
+// Kind returns the event kind.
+func (evt *Event) Kind() string {
+	if evt.Block != nil {
+		return "block"
+	}
+	return "tx"
+}
+
+// String returns a short description of the event.
+func (evt *Event) String() string {
+	if evt.Block != nil {
+		return fmt.Sprintf("block %s", evt.Block.BlockNumber)
+	}
+	return fmt.Sprintf("tx %s (block %s)", evt.Tx.GetTransaction().GetHash(), evt.Tx.GetBlock().GetBlockNumber())
+}
+
+// Load reads the events from a JSON or a protobuf fixture file. The files with the .pb or .bin
+// extension are read as protobuf fixtures.
+func Load(fixturePath string) ([]*Event, error) {
+	f, err := os.Open(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(fixturePath)) {
+	case ".pb", ".bin":
+		return ReadProto(f)
+	default:
+		return ReadJSON(f)
+	}
+}
+
+// ReadJSON reads a JSON fixture and converts it to the events in the order of the blocks. Each block
+// event is followed by the events of the transactions in the block.
+func ReadJSON(r io.Reader) ([]*Event, error) {
+	var fixture Fixture
+	if err := json.NewDecoder(r).Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture: %v", err)
+	}
+	return fixture.Events()
+}
+
+// Events converts the fixture data to the bot events.
+func (fixture *Fixture) Events() ([]*Event, error) {
+	if len(fixture.Blocks) == 0 {
+		return nil, errors.New("fixture has no blocks")
+	}
+	chainID := big.NewInt(int64(fixture.ChainID))
+	if fixture.ChainID == 0 {
+		chainID = big.NewInt(1)
+	}
+	receipts := make(map[string]*domain.TransactionReceipt)
+	for _, receipt := range fixture.Receipts {
+		if receipt.TransactionHash != nil {
+			receipts[strings.ToLower(*receipt.TransactionHash)] = receipt
+		}
+	}
+
+	var events []*Event
+	for i, block := range fixture.Blocks {
+		if block == nil {
+			return nil, fmt.Errorf("fixture block %d is empty", i)
+		}
+		blockEvt := &domain.BlockEvent{
+			EventType: domain.EventTypeBlock,
+			ChainID:   chainID,
+			Block:     block,
+			Logs:      blockLogs(block, fixture.Logs),
+			Traces:    blockTraces(block, fixture.Traces),
+			Timestamps: &domain.TrackingTimestamps{
+				Block: blockTime(block),
+				Feed:  time.Now().UTC(),
+			},
+		}
+		blockMsg, err := blockEvt.ToMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert block %s: %v", block.Number, err)
+		}
+		events = append(events, &Event{Block: blockMsg})
+
+		for j := range block.Transactions {
+			tx := &block.Transactions[j]
+			txMsg, err := scanner.TxEventToMessage(&domain.TransactionEvent{
+				BlockEvt:    blockEvt,
+				Transaction: tx,
+				Receipt:     receipts[strings.ToLower(tx.Hash)],
+				Timestamps:  blockEvt.Timestamps,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert tx %s: %v", tx.Hash, err)
+			}
+			events = append(events, &Event{Tx: txMsg})
+		}
+	}
+	return events, nil
+}
+
+// blockLogs returns the logs of the block. The logs without a block hash are assumed to be in the block.
+func blockLogs(block *domain.Block, logs []domain.LogEntry) (blockLogs []domain.LogEntry) {
+	for _, l := range logs {
+		if l.BlockHash == nil || strings.EqualFold(*l.BlockHash, block.Hash) {
+			blockLogs = append(blockLogs, l)
+		}
+	}
+	return
+}
+
+// blockTraces returns the traces of the block. The traces without a block hash are assumed to be in the block.
+func blockTraces(block *domain.Block, traces []domain.Trace) (blockTraces []domain.Trace) {
+	for _, trace := range traces {
+		if trace.BlockHash == nil || strings.EqualFold(*trace.BlockHash, block.Hash) {
+			blockTraces = append(blockTraces, trace)
+		}
+	}
+	return
+}
+
+func blockTime(block *domain.Block) time.Time {
+	ts, err := block.GetTimestamp()
+	if err != nil || ts == nil {
+		return time.Now().UTC()
+	}
+	return ts.UTC()
+}
+
+// ReadProto reads a protobuf fixture which is a stream of varint length-delimited google.protobuf.Any
+// messages. Each message contains a network.forta.BlockEvent or a network.forta.TransactionEvent.
+func ReadProto(r io.Reader) ([]*Event, error) {
+	br := bufio.NewReader(r)
+	var events []*Event
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture message size: %v", err)
+		}
+		if size > maxMessageSize {
+			return nil, fmt.Errorf("fixture message %d is too large: %d bytes", len(events), size)
+		}
+		b := make([]byte, size)
+		if _, err := io.ReadFull(br, b); err != nil {
+			return nil, fmt.Errorf("failed to read fixture message: %v", err)
+		}
+		var anyMsg anypb.Any
+		if err := proto.Unmarshal(b, &anyMsg); err != nil {
+			return nil, fmt.Errorf("failed to decode fixture message: %v", err)
+		}
+		msg, err := anyMsg.UnmarshalNew()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode fixture message: %v", err)
+		}
+		switch evt := msg.(type) {
+		case *protocol.BlockEvent:
+			events = append(events, &Event{Block: evt})
+		case *protocol.TransactionEvent:
+			events = append(events, &Event{Tx: evt})
+		default:
+			return nil, fmt.Errorf("unexpected fixture message type: %s", anyMsg.GetTypeUrl())
+		}
+	}
+	if len(events) == 0 {
+		return nil, errors.New("fixture has no events")
+	}
+	return events, nil
+}
+
+// WriteProto writes the events as a protobuf fixture.
+func WriteProto(w io.Writer, events []*Event) error {
+	for _, evt := range events {
+		var msg proto.Message = evt.Tx
+		if evt.Block != nil {
+			msg = evt.Block
+		}
+		anyMsg, err := anypb.New(msg)
+		if err != nil {
+			return err
+		}
+		b, err := proto.Marshal(anyMsg)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(binary.AppendUvarint(nil, uint64(len(b)))); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
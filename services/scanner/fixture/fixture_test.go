@@ -0,0 +1,129 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testFixture = `{
+  "chainId": 137,
+  "blocks": [
+    {
+      "hash": "0xb1",
+      "number": "0x10",
+      "timestamp": "0x64a5c9c0",
+      "transactions": [
+        {"hash": "0xt1", "from": "0xF1", "to": "0xC1", "nonce": "0x1", "blockNumber": "0x10"},
+        {"hash": "0xt2", "from": "0xF2", "nonce": "0x0", "blockNumber": "0x10"}
+      ]
+    },
+    {"hash": "0xb2", "number": "0x11", "timestamp": "0x64a5c9cc", "transactions": []}
+  ],
+  "logs": [
+    {"address": "0xL1", "blockHash": "0xb1", "transactionHash": "0xt1", "topics": ["0xddf2"], "data": "0x"}
+  ],
+  "receipts": [
+    {"transactionHash": "0xt1", "status": "0x0", "gasUsed": "0x5208"}
+  ]
+}`
+
+func TestReadJSON(t *testing.T) {
+	r := require.New(t)
+
+	events, err := ReadJSON(strings.NewReader(testFixture))
+	r.NoError(err)
+	r.Len(events, 4)
+
+	r.Equal("0x10", events[0].Block.BlockNumber)
+	r.Equal("0x89", events[0].Block.Network.ChainId)
+
+	tx := events[1].Tx
+	r.Equal("0xt1", tx.Transaction.Hash)
+	r.Equal("0x89", tx.Network.ChainId)
+	r.Len(tx.Logs, 1)
+	r.Equal("0xl1", tx.Logs[0].Address)
+	r.Equal("0x0", tx.Receipt.Status)
+	r.Equal("0x5208", tx.Receipt.GasUsed)
+
+	r.True(events[2].Tx.IsContractDeployment)
+	r.Equal("0x11", events[3].Block.BlockNumber)
+
+	_, err = ReadJSON(strings.NewReader(`{"blocks": []}`))
+	r.Error(err)
+}
+
+func TestProto(t *testing.T) {
+	r := require.New(t)
+
+	events, err := ReadJSON(strings.NewReader(testFixture))
+	r.NoError(err)
+
+	var buf bytes.Buffer
+	r.NoError(WriteProto(&buf, events))
+	decoded, err := ReadProto(&buf)
+	r.NoError(err)
+	r.Len(decoded, len(events))
+	for i := range events {
+		r.Equal(events[i].Kind(), decoded[i].Kind())
+		r.Equal(events[i].String(), decoded[i].String())
+	}
+}
+
+func TestRunner(t *testing.T) {
+	r := require.New(t)
+
+	events, err := ReadJSON(strings.NewReader(testFixture))
+	r.NoError(err)
+
+	client := mock_agentgrpc.NewMockClient(gomock.NewController(t))
+	client.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unimplemented, "unimplemented"))
+	client.EXPECT().EvaluateBlock(gomock.Any(), gomock.Any()).Return(&protocol.EvaluateBlockResponse{Status: protocol.ResponseStatus_SUCCESS}, nil).Times(2)
+	client.EXPECT().EvaluateTx(gomock.Any(), gomock.Any()).Return(&protocol.EvaluateTxResponse{
+		Status:   protocol.ResponseStatus_SUCCESS,
+		Findings: []*protocol.Finding{{Name: "Deployment", Severity: protocol.Finding_HIGH}},
+	}, nil)
+	client.EXPECT().EvaluateTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("bot crashed"))
+
+	runner := NewRunner(client, time.Second)
+	r.NoError(runner.Initialize(context.Background(), "0xbot"))
+
+	var results []*Result
+	report, err := runner.Run(context.Background(), events, func(result *Result) {
+		results = append(results, result)
+	})
+	r.NoError(err)
+	r.Len(results, 4)
+	r.Len(results[1].Findings, 1)
+	r.Error(results[2].Err)
+
+	r.Equal(4, report.Events)
+	r.Equal(1, report.Findings)
+	r.Equal(1, report.Errors)
+	r.Equal(2, report.Latency["block"].Count)
+	r.Equal(1, report.Latency["tx"].Count)
+}
+
+func TestPercentile(t *testing.T) {
+	r := require.New(t)
+
+	var values []time.Duration
+	for i := 1; i <= 100; i++ {
+		values = append(values, time.Duration(i))
+	}
+	stats := summarizeLatencies(values)
+	r.Equal(time.Duration(1), stats.Min)
+	r.Equal(time.Duration(50), stats.P50)
+	r.Equal(time.Duration(95), stats.P95)
+	r.Equal(time.Duration(100), stats.Max)
+}
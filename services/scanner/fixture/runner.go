@@ -0,0 +1,149 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Result is the bot response to an event.
+type Result struct {
+	Event    *Event
+	Findings []*protocol.Finding
+	Latency  time.Duration
+	Err      error
+}
+
+// LatencyStats summarizes the bot response latencies.
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Avg   time.Duration `json:"avg"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	Max   time.Duration `json:"max"`
+}
+
+// Report summarizes a fixture run.
+type Report struct {
+	Events   int                     `json:"events"`
+	Errors   int                     `json:"errors"`
+	Findings int                     `json:"findings"`
+	Latency  map[string]LatencyStats `json:"latency"`
+}
+
+// Runner sends the fixture events to a bot one by one, like the analyzers do.
+type Runner struct {
+	client  protocol.AgentClient
+	timeout time.Duration
+}
+
+// NewRunner creates a new runner which waits for each bot response until the timeout.
+func NewRunner(client protocol.AgentClient, timeout time.Duration) *Runner {
+	return &Runner{client: client, timeout: timeout}
+}
+
+// Initialize initializes the bot. The bots are not required to implement the initialize method.
+func (runner *Runner) Initialize(ctx context.Context, botID string) error {
+	ctx, cancel := context.WithTimeout(ctx, runner.timeout)
+	defer cancel()
+	resp, err := runner.client.Initialize(ctx, &protocol.InitializeRequest{AgentId: botID})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to initialize the bot: %v", err)
+	}
+	if resp.GetStatus() == protocol.ResponseStatus_ERROR {
+		return fmt.Errorf("bot initialization returned an error response: %v", agentgrpc.Error(resp.Errors))
+	}
+	return nil
+}
+
+// Run sends the events to the bot and calls the handler with each result. It stops early
+// only if the context is done.
+func (runner *Runner) Run(ctx context.Context, events []*Event, handler func(*Result)) (*Report, error) {
+	latencies := make(map[string][]time.Duration)
+	report := &Report{Latency: make(map[string]LatencyStats)}
+	for _, evt := range events {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := runner.evaluate(ctx, evt)
+		report.Events++
+		if result.Err != nil {
+			report.Errors++
+		} else {
+			latencies[evt.Kind()] = append(latencies[evt.Kind()], result.Latency)
+		}
+		report.Findings += len(result.Findings)
+		if handler != nil {
+			handler(result)
+		}
+	}
+	for kind, values := range latencies {
+		report.Latency[kind] = summarizeLatencies(values)
+	}
+	return report, nil
+}
+
+func (runner *Runner) evaluate(ctx context.Context, evt *Event) *Result {
+	ctx, cancel := context.WithTimeout(ctx, runner.timeout)
+	defer cancel()
+
+	var (
+		respStatus protocol.ResponseStatus
+		respErrors []*protocol.Error
+	)
+	result := &Result{Event: evt}
+	requestID := uuid.Must(uuid.NewUUID()).String()
+	startTime := time.Now()
+	if evt.Block != nil {
+		resp, err := runner.client.EvaluateBlock(ctx, &protocol.EvaluateBlockRequest{RequestId: requestID, Event: evt.Block})
+		result.Err = err
+		respStatus, respErrors, result.Findings = resp.GetStatus(), resp.GetErrors(), resp.GetFindings()
+	} else {
+		resp, err := runner.client.EvaluateTx(ctx, &protocol.EvaluateTxRequest{RequestId: requestID, Event: evt.Tx})
+		result.Err = err
+		respStatus, respErrors, result.Findings = resp.GetStatus(), resp.GetErrors(), resp.GetFindings()
+	}
+	result.Latency = time.Since(startTime)
+	if result.Err == nil && respStatus == protocol.ResponseStatus_ERROR {
+		result.Err = agentgrpc.Error(respErrors)
+	}
+	return result
+}
+
+func summarizeLatencies(values []time.Duration) LatencyStats {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	var total time.Duration
+	for _, value := range values {
+		total += value
+	}
+	return LatencyStats{
+		Count: len(values),
+		Min:   values[0],
+		Avg:   total / time.Duration(len(values)),
+		P50:   percentile(values, 50),
+		P95:   percentile(values, 95),
+		Max:   values[len(values)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
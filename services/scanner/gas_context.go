@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-node/clients/feehistory"
+	log "github.com/sirupsen/logrus"
+)
+
+// GasContextSource provides the gas context of the blocks.
+type GasContextSource interface {
+	GasContext(ctx context.Context, blockNumber uint64) (*feehistory.GasContext, error)
+}
+
+// getGasContext returns the gas context of the block or nil if it is not available. The events are
+// still sent to the bots without the gas context.
+func getGasContext(ctx context.Context, source GasContextSource, blockNumberHex string) *feehistory.GasContext {
+	if source == nil || len(blockNumberHex) == 0 {
+		return nil
+	}
+	blockNumber, err := hexutil.DecodeUint64(blockNumberHex)
+	if err != nil {
+		return nil
+	}
+	gasContext, err := source.GasContext(ctx, blockNumber)
+	if err != nil {
+		log.WithError(err).WithField("block", blockNumberHex).Debug("failed to get the gas context")
+		return nil
+	}
+	return gasContext
+}
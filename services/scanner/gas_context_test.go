@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/feehistory"
+	"github.com/stretchr/testify/require"
+)
+
+type testGasContextSource struct {
+	blocks map[uint64]*feehistory.GasContext
+}
+
+func (source *testGasContextSource) GasContext(ctx context.Context, blockNumber uint64) (*feehistory.GasContext, error) {
+	gasContext, ok := source.blocks[blockNumber]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return gasContext, nil
+}
+
+func TestGetGasContext(t *testing.T) {
+	r := require.New(t)
+
+	expected := &feehistory.GasContext{BlockNumber: "0x10", BaseFeePerGas: "0x1"}
+	source := &testGasContextSource{blocks: map[uint64]*feehistory.GasContext{16: expected}}
+
+	r.Equal(expected, getGasContext(context.Background(), source, "0x10"))
+	r.Nil(getGasContext(context.Background(), source, "0x11"))
+	r.Nil(getGasContext(context.Background(), source, "invalid"))
+	// the pending transactions do not have a block
+	r.Nil(getGasContext(context.Background(), source, ""))
+	r.Nil(getGasContext(context.Background(), nil, "0x10"))
+}
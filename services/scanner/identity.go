@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/clients/health"
+)
+
+// IdentityReporter reports the scanner address which the alerts and the batches are signed with,
+// so that the address is visible in the node status and the health endpoint.
+type IdentityReporter struct {
+	address common.Address
+}
+
+// NewIdentityReporter creates a new identity reporter.
+func NewIdentityReporter(address common.Address) *IdentityReporter {
+	return &IdentityReporter{address: address}
+}
+
+// Name returns the name of the reporter.
+func (ir *IdentityReporter) Name() string {
+	return "identity"
+}
+
+// Health implements the health.Reporter interface.
+func (ir *IdentityReporter) Health() health.Reports {
+	return health.Reports{
+		&health.Report{
+			Name:    "scanner.address",
+			Status:  health.StatusInfo,
+			Details: ir.address.Hex(),
+		},
+	}
+}
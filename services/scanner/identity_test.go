@@ -0,0 +1,20 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityReporter(t *testing.T) {
+	r := require.New(t)
+
+	address := common.HexToAddress("0x1")
+	reports := NewIdentityReporter(address).Health()
+	r.Len(reports, 1)
+	r.Equal("scanner.address", reports[0].Name)
+	r.Equal(health.StatusInfo, reports[0].Status)
+	r.Equal(address.Hex(), reports[0].Details)
+}
@@ -0,0 +1,326 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/forta-network/forta-node/services/scanner/fakechain"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// the transactions are generated in small steps to keep the rate smooth
+	pacingInterval   = 10 * time.Millisecond
+	resourceInterval = time.Second
+)
+
+// Config configures a load test.
+type Config struct {
+	ChainID uint64
+	// the target amount of the transactions per second
+	TPS      int
+	Duration time.Duration
+	// the amount of the concurrent requests to each bot
+	Concurrency int
+	// the transactions are dropped if a bot is behind by more than this
+	BufferSize int
+	Timeout    time.Duration
+	Seed       int64
+	// the distribution of the transactions - uses the default profile if not set
+	Profile *fakechain.TxProfile
+}
+
+// Bot is a bot which receives the synthetic traffic.
+type Bot struct {
+	Name   string
+	Client protocol.AgentClient
+}
+
+// LatencyStats summarizes the bot response latencies.
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Avg   time.Duration `json:"avg"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// BotReport is the result of a load test for a bot.
+type BotReport struct {
+	Name      string `json:"name"`
+	Sent      int    `json:"sent"`
+	Completed int    `json:"completed"`
+	Errors    int    `json:"errors"`
+	Dropped   int    `json:"dropped"`
+	Findings  int    `json:"findings"`
+	// the completed transactions per second
+	Throughput float64      `json:"throughput"`
+	Latency    LatencyStats `json:"latency"`
+
+	latencies []time.Duration
+	mu        sync.Mutex
+}
+
+// ResourceUsage is the resource usage of the load generator process.
+type ResourceUsage struct {
+	CPUTime      time.Duration `json:"cpuTime"`
+	MaxHeapBytes uint64        `json:"maxHeapBytes"`
+	Goroutines   int           `json:"goroutines"`
+}
+
+// Report is the result of a load test.
+type Report struct {
+	Duration  time.Duration `json:"duration"`
+	TargetTPS int           `json:"targetTps"`
+	Generated int           `json:"generated"`
+	// the generated transactions per second
+	GeneratedTPS float64       `json:"generatedTps"`
+	Bots         []*BotReport  `json:"bots"`
+	Resources    ResourceUsage `json:"resources"`
+}
+
+type botRunner struct {
+	bot      *Bot
+	requests chan *protocol.EvaluateTxRequest
+	report   *BotReport
+}
+
+// Run generates the synthetic transactions at the target rate during the configured duration,
+// sends them to the bots and reports how the bots keep up.
+func Run(ctx context.Context, cfg Config, bots []*Bot) (*Report, error) {
+	if cfg.TPS <= 0 {
+		return nil, errors.New("target tps should be greater than zero")
+	}
+	if len(bots) == 0 {
+		return nil, errors.New("no bots to load test")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	runners := make([]*botRunner, len(bots))
+	var workers sync.WaitGroup
+	for i, bot := range bots {
+		runner := &botRunner{
+			bot:      bot,
+			requests: make(chan *protocol.EvaluateTxRequest, cfg.BufferSize),
+			report:   &BotReport{Name: bot.Name},
+		}
+		runners[i] = runner
+		for j := 0; j < cfg.Concurrency; j++ {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				runner.work(ctx, cfg.Timeout)
+			}()
+		}
+	}
+
+	cpuStart := cpuTime()
+	startTime := time.Now()
+	generated, maxHeap, err := generate(ctx, cfg, runners)
+	for _, runner := range runners {
+		close(runner.requests)
+	}
+	// the queued requests are still evaluated after the generation ends
+	workers.Wait()
+	elapsed := time.Since(startTime)
+
+	report := &Report{
+		Duration:     elapsed,
+		TargetTPS:    cfg.TPS,
+		Generated:    generated,
+		GeneratedTPS: float64(generated) / elapsed.Seconds(),
+		Resources: ResourceUsage{
+			CPUTime:      cpuTime() - cpuStart,
+			MaxHeapBytes: maxHeap,
+			Goroutines:   runtime.NumGoroutine(),
+		},
+	}
+	for _, runner := range runners {
+		botReport := runner.report
+		botReport.Throughput = float64(botReport.Completed) / elapsed.Seconds()
+		botReport.Latency = summarizeLatencies(botReport.latencies)
+		report.Bots = append(report.Bots, botReport)
+	}
+	return report, err
+}
+
+// generate sends the transactions of the fake chain to the bots until the duration is over.
+func generate(ctx context.Context, cfg Config, runners []*botRunner) (generated int, maxHeap uint64, err error) {
+	chainID := cfg.ChainID
+	if chainID == 0 {
+		chainID = 1
+	}
+	// each block has the transactions of a second
+	chain := fakechain.New(ctx, fakechain.Config{
+		ChainID:       big.NewInt(int64(chainID)),
+		StartBlock:    1,
+		BlockInterval: time.Second,
+		TxsPerBlock:   cfg.TPS,
+		Seed:          cfg.Seed,
+		StartTime:     time.Now(),
+		Profile:       cfg.Profile,
+	})
+
+	var (
+		blockNumber uint64 = 1
+		pending     []*protocol.TransactionEvent
+	)
+	nextTx := func() (*protocol.TransactionEvent, error) {
+		if len(pending) == 0 {
+			blockEvt := chain.BlockEvent(blockNumber)
+			blockNumber++
+			for i := range blockEvt.Block.Transactions {
+				txMsg, err := scanner.TxEventToMessage(&domain.TransactionEvent{
+					BlockEvt:    blockEvt,
+					Transaction: &blockEvt.Block.Transactions[i],
+					Timestamps:  blockEvt.Timestamps,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert tx: %v", err)
+				}
+				pending = append(pending, txMsg)
+			}
+		}
+		txMsg := pending[0]
+		pending = pending[1:]
+		return txMsg, nil
+	}
+
+	ticker := time.NewTicker(pacingInterval)
+	defer ticker.Stop()
+	startTime := time.Now()
+	lastResourceCheck := startTime
+	maxHeap = heapBytes()
+	for {
+		select {
+		case <-ctx.Done():
+			return generated, maxHeap, ctx.Err()
+		case <-ticker.C:
+		}
+		elapsed := time.Since(startTime)
+		if elapsed >= cfg.Duration {
+			return generated, maxHeap, nil
+		}
+		if time.Since(lastResourceCheck) >= resourceInterval {
+			if heap := heapBytes(); heap > maxHeap {
+				maxHeap = heap
+			}
+			lastResourceCheck = time.Now()
+		}
+
+		// catch up with the target rate
+		for target := int(elapsed.Seconds() * float64(cfg.TPS)); generated < target; generated++ {
+			txMsg, err := nextTx()
+			if err != nil {
+				return generated, maxHeap, err
+			}
+			for _, runner := range runners {
+				runner.enqueue(&protocol.EvaluateTxRequest{
+					RequestId: uuid.Must(uuid.NewUUID()).String(),
+					Event:     txMsg,
+				})
+			}
+		}
+	}
+}
+
+func (runner *botRunner) enqueue(req *protocol.EvaluateTxRequest) {
+	runner.report.mu.Lock()
+	defer runner.report.mu.Unlock()
+
+	select {
+	case runner.requests <- req:
+		runner.report.Sent++
+	default:
+		runner.report.Dropped++
+	}
+}
+
+func (runner *botRunner) work(ctx context.Context, timeout time.Duration) {
+	for req := range runner.requests {
+		reqCtx, cancel := context.WithTimeout(ctx, timeout)
+		startTime := time.Now()
+		resp, err := runner.bot.Client.EvaluateTx(reqCtx, req)
+		latency := time.Since(startTime)
+		cancel()
+		if err == nil && resp.GetStatus() == protocol.ResponseStatus_ERROR {
+			err = agentgrpc.Error(resp.GetErrors())
+		}
+		runner.record(latency, len(resp.GetFindings()), err)
+	}
+}
+
+func (runner *botRunner) record(latency time.Duration, findings int, err error) {
+	runner.report.mu.Lock()
+	defer runner.report.mu.Unlock()
+
+	if err != nil {
+		runner.report.Errors++
+		log.WithError(err).WithField("bot", runner.bot.Name).Debug("bot failed to evaluate tx")
+		return
+	}
+	runner.report.Completed++
+	runner.report.Findings += findings
+	runner.report.latencies = append(runner.report.latencies, latency)
+}
+
+func summarizeLatencies(values []time.Duration) LatencyStats {
+	if len(values) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	var total time.Duration
+	for _, value := range values {
+		total += value
+	}
+	return LatencyStats{
+		Count: len(values),
+		Min:   values[0],
+		Avg:   total / time.Duration(len(values)),
+		P50:   percentile(values, 50),
+		P90:   percentile(values, 90),
+		P99:   percentile(values, 99),
+		Max:   values[len(values)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func heapBytes() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func cpuTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
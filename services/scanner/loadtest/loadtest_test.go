@@ -0,0 +1,91 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type testBotClient struct {
+	protocol.AgentClient
+	delay time.Duration
+	err   error
+	calls int64
+}
+
+func (client *testBotClient) EvaluateTx(ctx context.Context, in *protocol.EvaluateTxRequest, opts ...grpc.CallOption) (*protocol.EvaluateTxResponse, error) {
+	atomic.AddInt64(&client.calls, 1)
+	time.Sleep(client.delay)
+	if client.err != nil {
+		return nil, client.err
+	}
+	return &protocol.EvaluateTxResponse{
+		Status:   protocol.ResponseStatus_SUCCESS,
+		Findings: []*protocol.Finding{{AlertId: "ALERT"}},
+	}, nil
+}
+
+func TestRun(t *testing.T) {
+	r := require.New(t)
+
+	fast := &testBotClient{}
+	slow := &testBotClient{delay: 50 * time.Millisecond}
+	failing := &testBotClient{err: errors.New("failed")}
+	report, err := Run(context.Background(), Config{
+		TPS:         200,
+		Duration:    500 * time.Millisecond,
+		Concurrency: 2,
+		BufferSize:  10,
+		Timeout:     time.Second,
+	}, []*Bot{{Name: "fast", Client: fast}, {Name: "slow", Client: slow}, {Name: "failing", Client: failing}})
+	r.NoError(err)
+
+	r.Greater(report.Generated, 0)
+	r.Len(report.Bots, 3)
+	for _, botReport := range report.Bots {
+		r.Equal(report.Generated, botReport.Sent+botReport.Dropped, botReport.Name)
+		r.Equal(botReport.Sent, botReport.Completed+botReport.Errors, botReport.Name)
+	}
+
+	fastReport, slowReport, failingReport := report.Bots[0], report.Bots[1], report.Bots[2]
+	r.Equal(report.Generated, fastReport.Completed)
+	r.Equal(fastReport.Completed, fastReport.Findings)
+	r.Equal(fastReport.Completed, fastReport.Latency.Count)
+	r.LessOrEqual(fastReport.Latency.P50, fastReport.Latency.P99)
+
+	// the slow bot cannot keep up with the rate
+	r.Greater(slowReport.Dropped, 0)
+	r.GreaterOrEqual(slowReport.Latency.Min, 50*time.Millisecond)
+
+	r.Equal(failingReport.Sent, failingReport.Errors)
+	r.Zero(failingReport.Latency.Count)
+}
+
+func TestRun_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	_, err := Run(context.Background(), Config{TPS: 0}, []*Bot{{Name: "bot", Client: &testBotClient{}}})
+	r.Error(err)
+	_, err = Run(context.Background(), Config{TPS: 1}, nil)
+	r.Error(err)
+}
+
+func TestPercentile(t *testing.T) {
+	r := require.New(t)
+
+	var values []time.Duration
+	for i := 1; i <= 100; i++ {
+		values = append(values, time.Duration(i))
+	}
+	stats := summarizeLatencies(values)
+	r.Equal(time.Duration(50), stats.P50)
+	r.Equal(time.Duration(90), stats.P90)
+	r.Equal(time.Duration(99), stats.P99)
+	r.Equal(LatencyStats{}, summarizeLatencies(nil))
+}
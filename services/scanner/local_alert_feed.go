@@ -0,0 +1,259 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	log "github.com/sirupsen/logrus"
+)
+
+const localAlertFeedBufferSize = 1000
+
+// LocalAlertFeed feeds the alerts of the bots that run on this node to the subscribed
+// combiner bots, without waiting for the alerts to be published and queried from the alert API.
+type LocalAlertFeed struct {
+	ctx    context.Context
+	events chan *domain.AlertEvent
+
+	subscriptions []*domain.CombinerBotSubscription
+	handlers      []func(evt *domain.AlertEvent) error
+	mu            sync.RWMutex
+
+	lastAlert health.TimeTracker
+}
+
+var _ feeds.AlertFeed = &LocalAlertFeed{}
+
+// NewLocalAlertFeed creates a new local alert feed.
+func NewLocalAlertFeed(ctx context.Context) *LocalAlertFeed {
+	return &LocalAlertFeed{
+		ctx:    ctx,
+		events: make(chan *domain.AlertEvent, localAlertFeedBufferSize),
+	}
+}
+
+// Start implements feeds.AlertFeed. It sends the published alerts to the handlers until the
+// context is done. The handlers are called separately from the publishers so that the
+// combiner bots do not block the analyzers which send their alerts.
+func (lf *LocalAlertFeed) Start() {
+	for {
+		select {
+		case <-lf.ctx.Done():
+			return
+		case evt := <-lf.events:
+			lf.mu.RLock()
+			handlers := lf.handlers
+			lf.mu.RUnlock()
+			for _, handler := range handlers {
+				if err := handler(evt); err != nil {
+					log.WithError(err).WithField("alert", evt.Event.Alert.Hash).Warn("error executing local alert handler")
+				}
+			}
+			lf.lastAlert.Set()
+		}
+	}
+}
+
+// AddSubscription implements feeds.AlertFeed.
+func (lf *LocalAlertFeed) AddSubscription(subscription *domain.CombinerBotSubscription) error {
+	if subscription == nil || subscription.Subscription == nil || subscription.Subscriber == nil {
+		return errors.New("nil subscription data")
+	}
+	if len(subscription.Subscription.BotId) == 0 {
+		return errors.New("subscription must have valid bot id")
+	}
+
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	for _, existing := range lf.subscriptions {
+		if existing.Equal(subscription) {
+			return errors.New("incoming subscription already exists")
+		}
+	}
+	lf.subscriptions = append(lf.subscriptions, subscription)
+	return nil
+}
+
+// RemoveSubscription implements feeds.AlertFeed.
+func (lf *LocalAlertFeed) RemoveSubscription(subscription *domain.CombinerBotSubscription) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	for i, existing := range lf.subscriptions {
+		if existing.Equal(subscription) {
+			lf.subscriptions = append(lf.subscriptions[:i], lf.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Subscriptions implements feeds.AlertFeed.
+func (lf *LocalAlertFeed) Subscriptions() []*domain.CombinerBotSubscription {
+	lf.mu.RLock()
+	defer lf.mu.RUnlock()
+
+	return append([]*domain.CombinerBotSubscription{}, lf.subscriptions...)
+}
+
+// RegisterHandler implements feeds.AlertFeed. The returned channel never receives an error
+// because the local feed does not stop.
+func (lf *LocalAlertFeed) RegisterHandler(alertHandler func(evt *domain.AlertEvent) error) <-chan error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	lf.handlers = append(lf.handlers, alertHandler)
+	return make(chan error, 1)
+}
+
+// PublishAlert queues the alert once for each of the matching subscriptions. The alerts are
+// dropped if the queue is full.
+func (lf *LocalAlertFeed) PublishAlert(alert *protocol.Alert) {
+	if alert.Type == protocol.AlertType_PRIVATE || alert.Finding == nil || alert.Agent == nil {
+		return
+	}
+	event := alertToEvent(alert)
+
+	lf.mu.RLock()
+	defer lf.mu.RUnlock()
+
+	for _, subscription := range lf.subscriptions {
+		if !subscriptionMatches(subscription.Subscription, event.Alert) {
+			continue
+		}
+		evt := &domain.AlertEvent{
+			Event: event,
+			Timestamps: &domain.TrackingTimestamps{
+				Feed:        time.Now().UTC(),
+				SourceAlert: time.Now().UTC(),
+			},
+			Subscriber: subscription.Subscriber,
+		}
+		select {
+		case lf.events <- evt:
+		default:
+			log.WithFields(log.Fields{
+				"alert":      alert.Id,
+				"subscriber": subscription.Subscriber.BotID,
+			}).Warn("local alert feed is full - dropping alert")
+		}
+	}
+}
+
+// Name implements health.Reporter.
+func (lf *LocalAlertFeed) Name() string {
+	return "local-alert-feed"
+}
+
+// Health implements health.Reporter.
+func (lf *LocalAlertFeed) Health() health.Reports {
+	return health.Reports{
+		lf.lastAlert.GetReport("event.alert.time"),
+	}
+}
+
+// subscriptionMatches tells if the alert is from the subscribed bot and matches the alert id
+// and the chain filters of the subscription.
+func subscriptionMatches(subscription *protocol.CombinerBotSubscription, alert *protocol.AlertEvent_Alert) bool {
+	if !strings.EqualFold(subscription.BotId, alert.Source.Bot.Id) {
+		return false
+	}
+	if subscription.ChainId != 0 && subscription.ChainId != alert.ChainId {
+		return false
+	}
+	if len(subscription.AlertId) > 0 && subscription.AlertId != alert.AlertId {
+		return false
+	}
+	if len(subscription.AlertIds) > 0 {
+		for _, alertID := range subscription.AlertIds {
+			if alertID == alert.AlertId {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// alertToEvent converts the alert to the form which the combiner bots receive from the alert API.
+func alertToEvent(alert *protocol.Alert) *protocol.AlertEvent {
+	finding := alert.Finding
+	chainID, _ := strconv.ParseUint(alert.Tags["chainId"], 10, 64)
+	blockNumber, _ := strconv.ParseUint(alert.Tags["blockNumber"], 10, 64)
+
+	var labels []*protocol.AlertEvent_Alert_Label
+	for _, label := range finding.Labels {
+		labels = append(labels, &protocol.AlertEvent_Alert_Label{
+			Label:      label.Label,
+			Confidence: label.Confidence,
+			Entity:     label.Entity,
+			EntityType: label.EntityType.String(),
+			Remove:     label.Remove,
+			Metadata:   label.Metadata,
+		})
+	}
+
+	return &protocol.AlertEvent{
+		Alert: &protocol.AlertEvent_Alert{
+			AlertId:     finding.AlertId,
+			Addresses:   finding.Addresses,
+			CreatedAt:   alert.Timestamp,
+			Description: finding.Description,
+			Hash:        alert.Id,
+			Metadata:    finding.Metadata,
+			Name:        finding.Name,
+			Severity:    finding.Severity.String(),
+			FindingType: finding.Type.String(),
+			Source: &protocol.AlertEvent_Alert_Source{
+				TransactionHash: alert.Tags["txHash"],
+				Bot: &protocol.AlertEvent_Alert_Bot{
+					Id:    alert.Agent.Id,
+					Image: alert.Agent.Image,
+				},
+				Block: &protocol.AlertEvent_Alert_Block{
+					Number:  blockNumber,
+					Hash:    alert.Tags["blockHash"],
+					ChainId: chainID,
+				},
+			},
+			RelatedAlerts:      finding.RelatedAlerts,
+			ChainId:            chainID,
+			Labels:             labels,
+			Truncated:          alert.Truncated,
+			AddressBloomFilter: alert.AddressBloomFilter,
+		},
+	}
+}
+
+type localFeedAlertSender struct {
+	clients.AlertSender
+	feed *LocalAlertFeed
+}
+
+// NewLocalFeedAlertSender creates an alert sender which also feeds the sent alerts to the local alert feed.
+func NewLocalFeedAlertSender(alertSender clients.AlertSender, feed *LocalAlertFeed) clients.AlertSender {
+	return &localFeedAlertSender{
+		AlertSender: alertSender,
+		feed:        feed,
+	}
+}
+
+// SignAlertAndNotify implements clients.AlertSender.
+func (lfs *localFeedAlertSender) SignAlertAndNotify(
+	rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps,
+) error {
+	if err := lfs.AlertSender.SignAlertAndNotify(rt, alert, chainID, blockNumber, ts); err != nil {
+		return err
+	}
+	lfs.feed.PublishAlert(alert)
+	return nil
+}
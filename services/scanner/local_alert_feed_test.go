@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLocalSourceBot   = "0x1d646c4045189991fdfd24a66b192a294158b839a6ec121d740474bdacb3ab23"
+	testLocalCombinerBot = "0x2d646c4045189991fdfd24a66b192a294158b839a6ec121d740474bdacb3ab23"
+)
+
+func testLocalAlert(alertID string) *protocol.Alert {
+	return &protocol.Alert{
+		Id:        "0xalerthash-" + alertID,
+		Type:      protocol.AlertType_TRANSACTION,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Finding: &protocol.Finding{
+			AlertId:  alertID,
+			Name:     "name",
+			Severity: protocol.Finding_HIGH,
+		},
+		Agent: &protocol.AgentInfo{Id: testLocalSourceBot},
+		Tags: map[string]string{
+			"chainId":     "1",
+			"blockNumber": "123",
+			"txHash":      "0x1",
+		},
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	r := require.New(t)
+
+	alert := alertToEvent(testLocalAlert("ALERT-1")).Alert
+	r.Equal(uint64(1), alert.ChainId)
+	r.Equal(uint64(123), alert.Source.Block.Number)
+	r.Equal("HIGH", alert.Severity)
+
+	r.True(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot}, alert))
+	r.True(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot, AlertId: "ALERT-1", ChainId: 1}, alert))
+	r.True(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot, AlertIds: []string{"ALERT-2", "ALERT-1"}}, alert))
+	r.False(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalCombinerBot}, alert))
+	r.False(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot, AlertId: "ALERT-2"}, alert))
+	r.False(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot, AlertIds: []string{"ALERT-2"}}, alert))
+	r.False(subscriptionMatches(&protocol.CombinerBotSubscription{BotId: testLocalSourceBot, ChainId: 137}, alert))
+}
+
+func TestLocalAlertFeed(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feed := NewLocalAlertFeed(ctx)
+	subscription := &domain.CombinerBotSubscription{
+		Subscription: &protocol.CombinerBotSubscription{BotId: testLocalSourceBot, AlertId: "ALERT-1"},
+		Subscriber:   &domain.Subscriber{BotID: testLocalCombinerBot},
+	}
+	r.NoError(feed.AddSubscription(subscription))
+	r.Error(feed.AddSubscription(subscription))
+
+	events := make(chan *domain.AlertEvent, 10)
+	feed.RegisterHandler(func(evt *domain.AlertEvent) error {
+		events <- evt
+		return nil
+	})
+	go feed.Start()
+
+	privateAlert := testLocalAlert("ALERT-1")
+	privateAlert.Type = protocol.AlertType_PRIVATE
+	feed.PublishAlert(privateAlert)
+	feed.PublishAlert(testLocalAlert("ALERT-2"))
+	feed.PublishAlert(testLocalAlert("ALERT-1"))
+
+	select {
+	case evt := <-events:
+		r.Equal(testLocalCombinerBot, evt.Subscriber.BotID)
+		r.Equal("ALERT-1", evt.Event.Alert.AlertId)
+	case <-time.After(time.Second):
+		r.FailNow("timed out waiting for the alert")
+	}
+	r.Len(events, 0)
+
+	feed.RemoveSubscription(subscription)
+	r.Empty(feed.Subscriptions())
+}
+
+func TestCombinerAlertStreamService_Dedupe(t *testing.T) {
+	r := require.New(t)
+
+	stream, err := NewCombinerAlertStreamService(
+		context.Background(), NewLocalAlertFeed(context.Background()), nil,
+		CombinerAlertStreamServiceConfig{LocalFeed: NewLocalAlertFeed(context.Background())},
+	)
+	r.NoError(err)
+
+	evt := &domain.AlertEvent{
+		Event:      alertToEvent(testLocalAlert("ALERT-1")),
+		Subscriber: &domain.Subscriber{BotID: testLocalCombinerBot},
+	}
+	r.False(stream.isDuplicate(evt))
+	r.True(stream.isDuplicate(evt))
+
+	otherSubscriber := &domain.AlertEvent{
+		Event:      evt.Event,
+		Subscriber: &domain.Subscriber{BotID: testLocalSourceBot},
+	}
+	r.False(stream.isDuplicate(otherSubscriber))
+}
@@ -0,0 +1,330 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/abidecoder"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/forta-network/forta-node/services/components/watchlist"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// LogFeed gets the logs which the bots subscribe to with eth_getLogs and sends them to the bots
+// as transaction events which contain only the logs. This is much cheaper than streaming all of
+// the transactions for the bots which care only about a few events, like the token transfers.
+type LogFeed struct {
+	ctx context.Context
+	cfg LogFeedConfig
+
+	// the next block to get the logs from
+	nextBlock uint64
+	lastBlock uint64
+
+	lastLogs     health.TimeTracker
+	lastCheckErr health.ErrorTracker
+	logCount     uint64
+}
+
+// LogFeedConfig contains the log feed configuration.
+type LogFeedConfig struct {
+	ChainID       int
+	EthClient     ethereum.Client
+	BotPool       botio.BotPool
+	RequestSender botio.Sender
+	// the logs are requested for the blocks which are this many blocks behind the head
+	Offset        int
+	MaxBlockRange uint64
+	Interval      time.Duration
+	// starts from the chain head if nil
+	Start *big.Int
+	// tags the log events with the labels of the watched addresses - nil sends them without the tags
+	Watchlists *watchlist.Watchlists
+}
+
+// logFilter is the aggregated eth_getLogs filter of the bots. A nil list matches all.
+type logFilter struct {
+	addresses  []common.Address
+	signatures []common.Hash
+}
+
+// NewLogFeed creates a new log feed.
+func NewLogFeed(ctx context.Context, cfg LogFeedConfig) (*LogFeed, error) {
+	if cfg.MaxBlockRange == 0 {
+		return nil, errors.New("log feed max block range is required")
+	}
+	lf := &LogFeed{
+		ctx: ctx,
+		cfg: cfg,
+	}
+	if cfg.Start != nil {
+		lf.nextBlock = cfg.Start.Uint64()
+	}
+	return lf, nil
+}
+
+// Start implements services.Service.
+func (lf *LogFeed) Start() error {
+	go func() {
+		ticker := time.NewTicker(lf.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lf.ctx.Done():
+				return
+			case <-ticker.C:
+				err := lf.poll()
+				lf.lastCheckErr.Set(err)
+				if err != nil {
+					log.WithError(err).WithField("chainId", lf.cfg.ChainID).Warn("failed to get the logs for the bots")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (lf *LogFeed) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (lf *LogFeed) Name() string {
+	return "log-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (lf *LogFeed) Health() health.Reports {
+	return health.Reports{
+		lf.lastLogs.GetReport("event.log.time"),
+		lf.lastCheckErr.GetReport("event.log.error"),
+		&health.Report{
+			Name:    "event.log.count",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&lf.logCount), 10),
+		},
+		&health.Report{
+			Name:    "event.log.block",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&lf.lastBlock), 10),
+		},
+	}
+}
+
+// poll gets the logs from the next block until the latest block behind the offset, in ranges.
+func (lf *LogFeed) poll() error {
+	head, err := lf.cfg.EthClient.BlockNumber(lf.ctx)
+	if err != nil {
+		return err
+	}
+	if head.Int64() < int64(lf.cfg.Offset) {
+		return nil
+	}
+	latest := head.Uint64() - uint64(lf.cfg.Offset)
+	if lf.nextBlock == 0 {
+		lf.nextBlock = latest
+	}
+	for lf.nextBlock <= latest {
+		if err := lf.ctx.Err(); err != nil {
+			return err
+		}
+		to := lf.nextBlock + lf.cfg.MaxBlockRange - 1
+		if to > latest {
+			to = latest
+		}
+		if err := lf.processRange(lf.nextBlock, to); err != nil {
+			return err
+		}
+		atomic.StoreUint64(&lf.lastBlock, to)
+		lf.nextBlock = to + 1
+	}
+	return nil
+}
+
+func (lf *LogFeed) processRange(from, to uint64) error {
+	filter, ok := lf.aggregateFilter()
+	// the blocks are skipped if no bot subscribes to the logs
+	if !ok {
+		return nil
+	}
+	logs, err := lf.cfg.EthClient.GetLogs(lf.ctx, geth.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: filter.addresses,
+		Topics:    filter.topicQuery(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, evt := range LogsToTxEvents(lf.cfg.ChainID, logs) {
+		if lf.cfg.Watchlists != nil {
+			lf.cfg.Watchlists.Tag(evt)
+		}
+		lf.cfg.RequestSender.SendEvaluateLogRequest(&protocol.EvaluateTxRequest{
+			RequestId: uuid.Must(uuid.NewUUID()).String(),
+			Event:     evt,
+		})
+	}
+	atomic.AddUint64(&lf.logCount, uint64(len(logs)))
+	lf.lastLogs.Set()
+	return nil
+}
+
+// aggregateFilter merges the filters of the bots which subscribe to the logs of this chain. It
+// returns false if there are no such bots.
+func (lf *LogFeed) aggregateFilter() (*logFilter, bool) {
+	var (
+		found        bool
+		allAddresses bool
+		allTopics    bool
+		addresses    = make(map[common.Address]bool)
+		signatures   = make(map[common.Hash]bool)
+	)
+	for _, bot := range lf.cfg.BotPool.GetCurrentBotClients() {
+		filters := bot.Config().Filters
+		if filters == nil || !containsString(filters.EventTypes, config.BotEventLog) || !filtersChain(filters, lf.cfg.ChainID) {
+			continue
+		}
+		found = true
+		if len(filters.Addresses) == 0 {
+			allAddresses = true
+		}
+		if len(filters.Topics) == 0 && len(filters.Events) == 0 {
+			allTopics = true
+		}
+		for _, address := range filters.Addresses {
+			addresses[common.HexToAddress(address)] = true
+		}
+		for _, topic := range filters.Topics {
+			signatures[common.HexToHash(topic)] = true
+		}
+		for _, event := range filters.Events {
+			signatures[abidecoder.SignatureHash(event)] = true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+	filter := &logFilter{}
+	if !allAddresses {
+		for address := range addresses {
+			filter.addresses = append(filter.addresses, address)
+		}
+		sort.Slice(filter.addresses, func(i, j int) bool {
+			return filter.addresses[i].Hex() < filter.addresses[j].Hex()
+		})
+	}
+	if !allTopics {
+		for signature := range signatures {
+			filter.signatures = append(filter.signatures, signature)
+		}
+		sort.Slice(filter.signatures, func(i, j int) bool {
+			return filter.signatures[i].Hex() < filter.signatures[j].Hex()
+		})
+	}
+	return filter, true
+}
+
+// topicQuery returns the topics of the query, where the signatures are the first topic.
+func (filter *logFilter) topicQuery() [][]common.Hash {
+	if len(filter.signatures) == 0 {
+		return nil
+	}
+	return [][]common.Hash{filter.signatures}
+}
+
+func filtersChain(filters *config.BotFilters, chainID int) bool {
+	if len(filters.ChainIDs) == 0 {
+		return true
+	}
+	for _, filterChainID := range filters.ChainIDs {
+		if filterChainID == uint64(chainID) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LogsToTxEvents groups the logs by the transactions and converts them to the transaction events
+// which contain only the transaction hash, the block hash and number and the logs. The removed
+// logs are skipped.
+func LogsToTxEvents(chainID int, logs []types.Log) []*protocol.TransactionEvent {
+	var (
+		events []*protocol.TransactionEvent
+		byTx   = make(map[common.Hash]*protocol.TransactionEvent)
+	)
+	network := &protocol.TransactionEvent_Network{ChainId: hexutil.EncodeUint64(uint64(chainID))}
+	feedTime := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		evt, ok := byTx[l.TxHash]
+		if !ok {
+			txHash := l.TxHash.Hex()
+			blockHash := l.BlockHash.Hex()
+			blockNumber := hexutil.EncodeUint64(l.BlockNumber)
+			evt = &protocol.TransactionEvent{
+				Type:        protocol.TransactionEvent_BLOCK,
+				Transaction: &protocol.TransactionEvent_EthTransaction{Hash: txHash},
+				Receipt: &protocol.TransactionEvent_EthReceipt{
+					TransactionHash: txHash,
+					BlockHash:       blockHash,
+					BlockNumber:     blockNumber,
+				},
+				Network:     network,
+				Addresses:   make(map[string]bool),
+				TxAddresses: make(map[string]bool),
+				Block: &protocol.TransactionEvent_EthBlock{
+					BlockHash:   blockHash,
+					BlockNumber: blockNumber,
+				},
+				Timestamps: &protocol.TrackingTimestamps{Feed: feedTime},
+			}
+			byTx[l.TxHash] = evt
+			events = append(events, evt)
+		}
+		txLog := &protocol.TransactionEvent_Log{
+			Address:          strings.ToLower(l.Address.Hex()),
+			Data:             hexutil.Encode(l.Data),
+			BlockNumber:      hexutil.EncodeUint64(l.BlockNumber),
+			TransactionHash:  l.TxHash.Hex(),
+			TransactionIndex: hexutil.EncodeUint64(uint64(l.TxIndex)),
+			BlockHash:        l.BlockHash.Hex(),
+			LogIndex:         hexutil.EncodeUint64(uint64(l.Index)),
+		}
+		evt.Addresses[txLog.Address] = true
+		evt.TxAddresses[txLog.Address] = true
+		for _, topic := range l.Topics {
+			txLog.Topics = append(txLog.Topics, topic.Hex())
+		}
+		evt.Logs = append(evt.Logs, txLog)
+		evt.Receipt.Logs = evt.Logs
+	}
+	return events
+}
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLogAddress = "0x00000000000000000000000000000000000000aa"
+	testLogTopic   = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+)
+
+func TestLogFeed(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	sender := mock_botio.NewMockSender(ctrl)
+	logBot := mock_botio.NewMockBotClient(ctrl)
+	logBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x1", Filters: &config.BotFilters{
+		EventTypes: []string{config.BotEventLog},
+		Addresses:  []string{testLogAddress},
+		Topics:     []string{testLogTopic},
+	}}).AnyTimes()
+	txBot := mock_botio.NewMockBotClient(ctrl)
+	txBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x2"}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{logBot, txBot}).AnyTimes()
+
+	lf, err := NewLogFeed(context.Background(), LogFeedConfig{
+		ChainID:       1,
+		EthClient:     ethClient,
+		BotPool:       botPool,
+		RequestSender: sender,
+		Offset:        2,
+		MaxBlockRange: 5,
+		Start:         big.NewInt(100),
+	})
+	r.NoError(err)
+
+	txHash := common.HexToHash("0x01")
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(108), nil)
+	ethClient.EXPECT().GetLogs(gomock.Any(), geth.FilterQuery{
+		FromBlock: big.NewInt(100),
+		ToBlock:   big.NewInt(104),
+		Addresses: []common.Address{common.HexToAddress(testLogAddress)},
+		Topics:    [][]common.Hash{{common.HexToHash(testLogTopic)}},
+	}).Return([]types.Log{
+		{Address: common.HexToAddress(testLogAddress), Topics: []common.Hash{common.HexToHash(testLogTopic)}, TxHash: txHash, BlockNumber: 101},
+		{Address: common.HexToAddress(testLogAddress), Topics: []common.Hash{common.HexToHash(testLogTopic)}, TxHash: txHash, BlockNumber: 101, Index: 1},
+		{Address: common.HexToAddress(testLogAddress), TxHash: common.HexToHash("0x02"), BlockNumber: 101, Removed: true},
+	}, nil)
+	ethClient.EXPECT().GetLogs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q geth.FilterQuery) ([]types.Log, error) {
+		r.Equal(int64(105), q.FromBlock.Int64())
+		r.Equal(int64(106), q.ToBlock.Int64())
+		return nil, nil
+	})
+	sender.EXPECT().SendEvaluateLogRequest(gomock.Any()).Do(func(req *protocol.EvaluateTxRequest) {
+		r.Equal(txHash.Hex(), req.Event.Transaction.Hash)
+		r.Equal(txHash.Hex(), req.Event.Receipt.TransactionHash)
+		r.Equal("0x65", req.Event.Block.BlockNumber)
+		r.Equal("0x1", req.Event.Network.ChainId)
+		r.Len(req.Event.Logs, 2)
+		r.True(req.Event.Addresses[testLogAddress])
+	})
+
+	r.NoError(lf.poll())
+	r.Equal(uint64(107), lf.nextBlock)
+}
+
+func TestLogFeed_NoLogBots(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	bot := mock_botio.NewMockBotClient(ctrl)
+	bot.EXPECT().Config().Return(config.AgentConfig{ID: "0x1", Filters: &config.BotFilters{
+		ChainIDs:   []uint64{137},
+		EventTypes: []string{config.BotEventLog},
+	}}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{bot}).AnyTimes()
+
+	lf, err := NewLogFeed(context.Background(), LogFeedConfig{
+		ChainID:       1,
+		EthClient:     ethClient,
+		BotPool:       botPool,
+		MaxBlockRange: 100,
+	})
+	r.NoError(err)
+
+	// starts from the head and does not request the logs for the other chains
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(100), nil)
+	r.NoError(lf.poll())
+	r.Equal(uint64(101), lf.nextBlock)
+}
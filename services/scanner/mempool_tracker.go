@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Mempool statuses
+const (
+	MempoolStatusPending  = "pending"
+	MempoolStatusDropped  = "dropped"
+	MempoolStatusReplaced = "replaced"
+	MempoolStatusStuck    = "stuck"
+)
+
+// Mempool replacement kinds
+const (
+	ReplacementSpeedUp = "speed-up"
+	ReplacementCancel  = "cancel"
+	ReplacementOther   = "replace"
+)
+
+// MempoolStatus tells what happened to a pending transaction which did not get mined.
+type MempoolStatus struct {
+	Status string `json:"status"`
+	// the kind of the replacement and the hash of the replacing transaction - only for the replaced
+	Replacement string `json:"replacement,omitempty"`
+	ReplacedBy  string `json:"replacedBy,omitempty"`
+	// how long the transaction was seen in the mempool
+	PendingSeconds float64 `json:"pendingSeconds"`
+}
+
+// MempoolStatusEvent is a pending transaction with its mempool status.
+type MempoolStatusEvent struct {
+	Event  *domain.TransactionEvent
+	Status *MempoolStatus
+}
+
+// AttachMempoolStatus adds the mempool status to the message as an unknown field.
+func AttachMempoolStatus(msg *protocol.TransactionEvent, status *MempoolStatus) error {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode the mempool status: %v", err)
+	}
+	unknown := msg.ProtoReflect().GetUnknown()
+	unknown = protowire.AppendTag(unknown, agentgrpc.MempoolStatusFieldNumber, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, b)
+	msg.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
+
+type trackedTx struct {
+	tx        *domain.Transaction
+	key       string
+	firstSeen time.Time
+	// the transaction is checked after the timeout from this time
+	checkAt time.Time
+	// the transaction was found stuck before
+	stuck bool
+}
+
+// MempoolTracker keeps a bounded table of the pending transactions by the sender and the nonce, so
+// that the replaced transactions are found when a new transaction with the same nonce arrives and
+// the transactions which stay too long are checked for being dropped. The oldest transactions
+// are forgotten when the table is full.
+type MempoolTracker struct {
+	maxTracked int
+	timeout    time.Duration
+
+	// ordered by the check times
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewMempoolTracker creates a new mempool tracker.
+func NewMempoolTracker(maxTracked int, timeout time.Duration) *MempoolTracker {
+	return &MempoolTracker{
+		maxTracked: maxTracked,
+		timeout:    timeout,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Len returns the amount of the tracked transactions.
+func (mt *MempoolTracker) Len() int {
+	return mt.order.Len()
+}
+
+// Observe tracks the pending transaction. It returns the tracked transaction which is replaced by
+// this one and its status, if there is any.
+func (mt *MempoolTracker) Observe(tx *domain.Transaction, now time.Time) (*domain.Transaction, *MempoolStatus) {
+	key := mempoolKey(tx)
+	var (
+		replacedTx *domain.Transaction
+		status     *MempoolStatus
+	)
+	if elem, ok := mt.entries[key]; ok {
+		entry := elem.Value.(*trackedTx)
+		if strings.EqualFold(entry.tx.Hash, tx.Hash) {
+			return nil, nil
+		}
+		mt.remove(elem)
+		replacedTx = entry.tx
+		status = &MempoolStatus{
+			Status:         MempoolStatusReplaced,
+			Replacement:    replacementKind(entry.tx, tx),
+			ReplacedBy:     tx.Hash,
+			PendingSeconds: now.Sub(entry.firstSeen).Seconds(),
+		}
+	}
+	mt.push(&trackedTx{tx: tx, key: key, firstSeen: now, checkAt: now})
+	return replacedTx, status
+}
+
+// Expired removes and returns the transactions which are pending for longer than the timeout, so
+// that they can be checked.
+func (mt *MempoolTracker) Expired(now time.Time) (expired []*trackedTx) {
+	for elem := mt.order.Front(); elem != nil; elem = mt.order.Front() {
+		entry := elem.Value.(*trackedTx)
+		if now.Sub(entry.checkAt) < mt.timeout {
+			break
+		}
+		mt.remove(elem)
+		expired = append(expired, entry)
+	}
+	return
+}
+
+// Retrack tracks the expired transaction again after it is found to be still pending, unless a
+// replacement is tracked already. It is checked again after the timeout.
+func (mt *MempoolTracker) Retrack(entry *trackedTx, now time.Time) {
+	if _, ok := mt.entries[entry.key]; ok {
+		return
+	}
+	entry.checkAt = now
+	entry.stuck = true
+	mt.push(entry)
+}
+
+func (mt *MempoolTracker) push(entry *trackedTx) {
+	mt.entries[entry.key] = mt.order.PushBack(entry)
+	for mt.maxTracked > 0 && mt.order.Len() > mt.maxTracked {
+		mt.remove(mt.order.Front())
+	}
+}
+
+func (mt *MempoolTracker) remove(elem *list.Element) {
+	mt.order.Remove(elem)
+	delete(mt.entries, elem.Value.(*trackedTx).key)
+}
+
+func mempoolKey(tx *domain.Transaction) string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(tx.From), strings.ToLower(tx.Nonce))
+}
+
+// replacementKind tells if the replacing transaction is the same transaction with a higher fee or a
+// cancellation, which is an empty transfer to the sender.
+func replacementKind(replaced, replacing *domain.Transaction) string {
+	replacingTo := strings.ToLower(stringValue(replacing.To))
+	if replacingTo == strings.ToLower(replacing.From) && isZeroHex(stringValue(replacing.Value)) && isEmptyHex(stringValue(replacing.Input)) {
+		return ReplacementCancel
+	}
+	if strings.EqualFold(stringValue(replaced.To), replacingTo) &&
+		strings.EqualFold(stringValue(replaced.Value), stringValue(replacing.Value)) &&
+		strings.EqualFold(stringValue(replaced.Input), stringValue(replacing.Input)) {
+		return ReplacementSpeedUp
+	}
+	return ReplacementOther
+}
+
+func isZeroHex(s string) bool {
+	return len(strings.TrimLeft(strings.TrimPrefix(s, "0x"), "0")) == 0
+}
+
+func isEmptyHex(s string) bool {
+	return len(strings.TrimPrefix(s, "0x")) == 0
+}
@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func testPendingTx(hash, nonce, to, value, input string) *domain.Transaction {
+	return &domain.Transaction{
+		Hash:  hash,
+		From:  "0xsender",
+		Nonce: nonce,
+		To:    &to,
+		Value: &value,
+		Input: &input,
+	}
+}
+
+func TestMempoolTracker_Replaced(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	tracker := NewMempoolTracker(10, time.Minute)
+
+	replacedTx, status := tracker.Observe(testPendingTx("0x1", "0x1", "0xtoken", "0x0", "0xa9059cbb"), now)
+	r.Nil(replacedTx)
+	r.Nil(status)
+	// the same tx again
+	replacedTx, _ = tracker.Observe(testPendingTx("0x1", "0x1", "0xtoken", "0x0", "0xa9059cbb"), now)
+	r.Nil(replacedTx)
+
+	replacedTx, status = tracker.Observe(testPendingTx("0x2", "0x1", "0xtoken", "0x0", "0xa9059cbb"), now.Add(time.Second))
+	r.Equal("0x1", replacedTx.Hash)
+	r.Equal(MempoolStatusReplaced, status.Status)
+	r.Equal(ReplacementSpeedUp, status.Replacement)
+	r.Equal("0x2", status.ReplacedBy)
+	r.Equal(float64(1), status.PendingSeconds)
+
+	_, status = tracker.Observe(testPendingTx("0x3", "0x1", "0xSENDER", "0x0", "0x"), now)
+	r.Equal(ReplacementCancel, status.Replacement)
+
+	_, status = tracker.Observe(testPendingTx("0x4", "0x1", "0xother", "0x1", "0x"), now)
+	r.Equal(ReplacementOther, status.Replacement)
+	r.Equal(1, tracker.Len())
+}
+
+func TestMempoolTracker_Expired(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	tracker := NewMempoolTracker(2, time.Minute)
+
+	tracker.Observe(testPendingTx("0x1", "0x1", "0xto", "0x0", "0x"), now)
+	tracker.Observe(testPendingTx("0x2", "0x2", "0xto", "0x0", "0x"), now.Add(time.Second))
+	tracker.Observe(testPendingTx("0x3", "0x3", "0xto", "0x0", "0x"), now.Add(2*time.Second))
+	// the oldest is forgotten
+	r.Equal(2, tracker.Len())
+
+	r.Empty(tracker.Expired(now.Add(time.Second)))
+	expired := tracker.Expired(now.Add(time.Minute + time.Second))
+	r.Len(expired, 1)
+	r.Equal("0x2", expired[0].tx.Hash)
+	r.Equal(1, tracker.Len())
+
+	tracker.Retrack(expired[0], now.Add(time.Minute+time.Second))
+	r.Equal(2, tracker.Len())
+	expired = tracker.Expired(now.Add(2*time.Minute + 2*time.Second))
+	r.Len(expired, 2)
+	r.Equal("0x3", expired[0].tx.Hash)
+	r.Equal("0x2", expired[1].tx.Hash)
+	r.True(expired[1].stuck)
+}
+
+func TestAttachMempoolStatus(t *testing.T) {
+	r := require.New(t)
+
+	msg := &protocol.TransactionEvent{}
+	r.NoError(AttachMempoolStatus(msg, &MempoolStatus{Status: MempoolStatusDropped, PendingSeconds: 300}))
+
+	unknown := msg.ProtoReflect().GetUnknown()
+	num, typ, n := protowire.ConsumeTag(unknown)
+	r.Equal(agentgrpc.MempoolStatusFieldNumber, num)
+	r.Equal(protowire.BytesType, typ)
+	b, _ := protowire.ConsumeBytes(unknown[n:])
+	r.JSONEq(`{"status":"dropped","pendingSeconds":300}`, string(b))
+}
@@ -0,0 +1,243 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	pendingTxSubscription  = "newPendingTransactions"
+	pendingTxResubscribe   = time.Second * 10
+	pendingTxBufferSize    = 1000
+	pendingTxLookupTimeout = time.Second * 5
+	mempoolSweepInterval   = time.Second * 30
+)
+
+// pendingTxClient is the subset of the websocket RPC client which the pending tx stream needs.
+type pendingTxClient interface {
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	Close()
+}
+
+// PendingTxStreamService subscribes to the pending transactions of the mempool and emits
+// them to a channel as transaction events without any block info.
+type PendingTxStreamService struct {
+	cfg      PendingTxStreamServiceConfig
+	ctx      context.Context
+	client   pendingTxClient
+	txOutput chan *domain.TransactionEvent
+	// the dropped, replaced and stuck transactions
+	statusOutput chan *MempoolStatusEvent
+
+	lastTxActivity health.TimeTracker
+	lastErr        health.ErrorTracker
+}
+
+// PendingTxStreamServiceConfig contains the pending tx stream config.
+type PendingTxStreamServiceConfig struct {
+	WebsocketURL string
+	ChainID      *big.Int
+	// tracks the pending transactions to find the dropped and the replaced ones - nil disables it
+	Tracker *MempoolTracker
+}
+
+// ReadOnlyPendingTxStream returns the pending tx output channel.
+func (t *PendingTxStreamService) ReadOnlyPendingTxStream() <-chan *domain.TransactionEvent {
+	return t.txOutput
+}
+
+// ReadOnlyMempoolStatusStream returns the mempool status output channel. The channel is nil if
+// the pending transactions are not tracked.
+func (t *PendingTxStreamService) ReadOnlyMempoolStatusStream() <-chan *MempoolStatusEvent {
+	return t.statusOutput
+}
+
+func (t *PendingTxStreamService) Start() error {
+	client, err := rpc.DialContext(t.ctx, t.cfg.WebsocketURL)
+	if err != nil {
+		return fmt.Errorf("failed to dial the pending tx websocket: %v", err)
+	}
+	t.client = client
+
+	go t.subscribeLoop()
+	return nil
+}
+
+func (t *PendingTxStreamService) subscribeLoop() {
+	for {
+		err := t.subscribe()
+		if errors.Is(err, context.Canceled) || t.ctx.Err() != nil {
+			log.Info("pending tx stream stopped")
+			return
+		}
+		t.lastErr.Set(err)
+		log.WithError(err).Warn("pending tx subscription failed - resubscribing")
+
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(pendingTxResubscribe):
+		}
+	}
+}
+
+func (t *PendingTxStreamService) subscribe() error {
+	hashes := make(chan string, pendingTxBufferSize)
+	sub, err := t.client.EthSubscribe(t.ctx, hashes, pendingTxSubscription)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to pending txs: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the sweep ticker is never selected if the pending transactions are not tracked
+	var sweep <-chan time.Time
+	if t.cfg.Tracker != nil {
+		ticker := time.NewTicker(mempoolSweepInterval)
+		defer ticker.Stop()
+		sweep = ticker.C
+	}
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return t.ctx.Err()
+
+		case err := <-sub.Err():
+			return err
+
+		case <-sweep:
+			if err := t.sweepExpired(); err != nil {
+				return err
+			}
+
+		case hash := <-hashes:
+			tx, err := t.getPendingTx(hash)
+			if err != nil {
+				log.WithError(err).WithField("tx", hash).Debug("failed to get pending tx")
+				continue
+			}
+			// skip if the tx is already gone from the mempool or it is already mined
+			if tx == nil || len(tx.BlockNumber) > 0 {
+				continue
+			}
+			select {
+			case <-t.ctx.Done():
+				return t.ctx.Err()
+			case t.txOutput <- t.makePendingTxEvent(tx):
+			}
+			t.lastTxActivity.Set()
+			if t.cfg.Tracker != nil {
+				if replacedTx, status := t.cfg.Tracker.Observe(tx, time.Now()); replacedTx != nil {
+					if err := t.emitStatus(replacedTx, status); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// sweepExpired checks the transactions which are pending for too long. The transactions which are
+// gone from the mempool without being mined are dropped, and the ones which are still pending are
+// stuck. The stuck transactions are reported once.
+func (t *PendingTxStreamService) sweepExpired() error {
+	now := time.Now()
+	for _, entry := range t.cfg.Tracker.Expired(now) {
+		tx, err := t.getPendingTx(entry.tx.Hash)
+		if err != nil {
+			log.WithError(err).WithField("tx", entry.tx.Hash).Debug("failed to check pending tx")
+			t.cfg.Tracker.Retrack(entry, now)
+			continue
+		}
+		status := &MempoolStatus{PendingSeconds: now.Sub(entry.firstSeen).Seconds()}
+		switch {
+		case tx == nil:
+			status.Status = MempoolStatusDropped
+		case len(tx.BlockNumber) > 0:
+			// mined
+			continue
+		case entry.stuck:
+			t.cfg.Tracker.Retrack(entry, now)
+			continue
+		default:
+			status.Status = MempoolStatusStuck
+			t.cfg.Tracker.Retrack(entry, now)
+		}
+		if err := t.emitStatus(entry.tx, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PendingTxStreamService) emitStatus(tx *domain.Transaction, status *MempoolStatus) error {
+	select {
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	case t.statusOutput <- &MempoolStatusEvent{Event: t.makePendingTxEvent(tx), Status: status}:
+	}
+	return nil
+}
+
+func (t *PendingTxStreamService) getPendingTx(hash string) (tx *domain.Transaction, err error) {
+	ctx, cancel := context.WithTimeout(t.ctx, pendingTxLookupTimeout)
+	defer cancel()
+	err = t.client.CallContext(ctx, &tx, "eth_getTransactionByHash", hash)
+	return
+}
+
+func (t *PendingTxStreamService) makePendingTxEvent(tx *domain.Transaction) *domain.TransactionEvent {
+	return &domain.TransactionEvent{
+		BlockEvt: &domain.BlockEvent{
+			EventType: domain.EventTypeBlock,
+			ChainID:   t.cfg.ChainID,
+			Block:     &domain.Block{},
+		},
+		Transaction: tx,
+		Timestamps:  &domain.TrackingTimestamps{Feed: time.Now().UTC()},
+	}
+}
+
+func (t *PendingTxStreamService) Stop() error {
+	if t.client != nil {
+		t.client.Close()
+	}
+	return nil
+}
+
+func (t *PendingTxStreamService) Name() string {
+	return "pending-tx-stream"
+}
+
+// Health implements health.Reporter interface.
+func (t *PendingTxStreamService) Health() health.Reports {
+	return health.Reports{
+		t.lastTxActivity.GetReport("event.transaction.time"),
+		t.lastErr.GetReport("subscription"),
+	}
+}
+
+// NewPendingTxStreamService creates a new pending tx stream service.
+func NewPendingTxStreamService(ctx context.Context, cfg PendingTxStreamServiceConfig) (*PendingTxStreamService, error) {
+	if len(cfg.WebsocketURL) == 0 {
+		return nil, errors.New("pending tx stream requires a websocket url")
+	}
+	pts := &PendingTxStreamService{
+		cfg:      cfg,
+		ctx:      ctx,
+		txOutput: make(chan *domain.TransactionEvent),
+	}
+	if cfg.Tracker != nil {
+		pts.statusOutput = make(chan *MempoolStatusEvent)
+	}
+	return pts, nil
+}
@@ -0,0 +1,201 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/privateflow"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/forta-network/forta-node/services/components/watchlist"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// privateFlowSeenBundles is the amount of the latest bundles which are remembered, so that the
+// bundles which the provider returns again are not sent twice.
+const privateFlowSeenBundles = 10000
+
+// PrivateFlowFeed gets the bundles of the private order flow from a provider and sends their
+// transactions only to the permitted bots, so that the operators which have access to the private
+// order flow can detect the transactions before they are included. The transactions are sent
+// like the pending transactions, without any block info.
+type PrivateFlowFeed struct {
+	ctx context.Context
+	cfg PrivateFlowFeedConfig
+
+	seen *lru.Cache[string, struct{}]
+
+	lastEvent    health.TimeTracker
+	lastCheckErr health.ErrorTracker
+	bundleCount  uint64
+	txCount      uint64
+}
+
+// PrivateFlowFeedConfig contains the private order flow feed configuration.
+type PrivateFlowFeedConfig struct {
+	ChainID       int
+	Source        string
+	Client        privateflow.Client
+	RequestSender botio.Sender
+	// the only bots which receive the transactions
+	Bots     []string
+	Interval time.Duration
+	// nil does not limit the content of the transactions
+	ContentLimits *ContentLimits
+	// tags the transactions with the labels of the watched addresses - nil sends them without the tags
+	Watchlists *watchlist.Watchlists
+}
+
+// NewPrivateFlowFeed creates a new private order flow feed.
+func NewPrivateFlowFeed(ctx context.Context, cfg PrivateFlowFeedConfig) (*PrivateFlowFeed, error) {
+	if len(cfg.Bots) == 0 {
+		return nil, errors.New("private flow feed requires the permitted bots")
+	}
+	return &PrivateFlowFeed{
+		ctx:  ctx,
+		cfg:  cfg,
+		seen: lru.NewCache[string, struct{}](privateFlowSeenBundles),
+	}, nil
+}
+
+// Start implements services.Service.
+func (pf *PrivateFlowFeed) Start() error {
+	go func() {
+		ticker := time.NewTicker(pf.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pf.ctx.Done():
+				return
+			case <-ticker.C:
+				err := pf.poll()
+				pf.lastCheckErr.Set(err)
+				if err != nil {
+					log.WithError(err).WithField("source", pf.cfg.Source).Warn("failed to get the private order flow")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (pf *PrivateFlowFeed) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (pf *PrivateFlowFeed) Name() string {
+	return "private-flow-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (pf *PrivateFlowFeed) Health() health.Reports {
+	return health.Reports{
+		pf.lastEvent.GetReport("event.private-flow.time"),
+		pf.lastCheckErr.GetReport("event.private-flow.error"),
+		&health.Report{
+			Name:    "event.private-flow.bundles",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&pf.bundleCount), 10),
+		},
+		&health.Report{
+			Name:    "event.private-flow.txs",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&pf.txCount), 10),
+		},
+	}
+}
+
+// poll sends the transactions of the bundles which were not sent before.
+func (pf *PrivateFlowFeed) poll() error {
+	bundles, err := pf.cfg.Client.Bundles(pf.ctx)
+	if err != nil {
+		return err
+	}
+	for _, bundle := range bundles {
+		if err := pf.ctx.Err(); err != nil {
+			return err
+		}
+		bundleID := bundle.Hash()
+		if pf.seen.Contains(bundleID) {
+			continue
+		}
+		pf.seen.Add(bundleID, struct{}{})
+		pf.processBundle(bundleID, bundle)
+		atomic.AddUint64(&pf.bundleCount, 1)
+		pf.lastEvent.Set()
+	}
+	return nil
+}
+
+func (pf *PrivateFlowFeed) processBundle(bundleID string, bundle *privateflow.Bundle) {
+	chainID := big.NewInt(int64(pf.cfg.ChainID))
+	lg := log.WithFields(log.Fields{
+		"source": pf.cfg.Source,
+		"bundle": bundleID,
+	})
+	for i, rawTx := range bundle.Txs {
+		tx, err := privateflow.DecodeTx(chainID, rawTx)
+		if err != nil {
+			lg.WithError(err).WithField("index", i).Debug("failed to decode private tx (skipping)")
+			continue
+		}
+		msg, err := TxEventToMessage(&domain.TransactionEvent{
+			BlockEvt: &domain.BlockEvent{
+				EventType: domain.EventTypeBlock,
+				ChainID:   chainID,
+				Block:     &domain.Block{},
+			},
+			Transaction: tx,
+			Timestamps:  &domain.TrackingTimestamps{Feed: time.Now().UTC()},
+		})
+		if err != nil {
+			lg.WithError(err).WithField("tx", tx.Hash).Error("error converting private tx to message (skipping)")
+			continue
+		}
+		if !pf.cfg.ContentLimits.Apply(msg) {
+			continue
+		}
+		if pf.cfg.Watchlists != nil {
+			pf.cfg.Watchlists.Tag(msg)
+		}
+		if err := AttachMempoolStatus(msg, &MempoolStatus{Status: MempoolStatusPending}); err != nil {
+			lg.WithError(err).Error("failed to mark the private tx as pending (skipping)")
+			continue
+		}
+		if err := privateflow.AttachToTx(msg, &privateflow.PrivateTx{
+			Source:      pf.cfg.Source,
+			BundleID:    bundleID,
+			Index:       i,
+			TargetBlock: bundle.BlockNumber,
+			CanRevert:   containsHash(bundle.RevertingTxHashes, tx.Hash),
+		}); err != nil {
+			lg.WithError(err).Error("failed to attach private tx (skipping)")
+			continue
+		}
+		pf.cfg.RequestSender.SendEvaluatePrivateTxRequest(&protocol.EvaluateTxRequest{
+			RequestId: uuid.Must(uuid.NewUUID()).String(),
+			Event:     msg,
+		}, pf.cfg.Bots)
+		atomic.AddUint64(&pf.txCount, 1)
+	}
+}
+
+func containsHash(hashes []string, hash string) bool {
+	for _, h := range hashes {
+		if strings.EqualFold(h, hash) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/privateflow"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testPrivateFlowClient struct {
+	bundles []*privateflow.Bundle
+}
+
+func (c *testPrivateFlowClient) Bundles(ctx context.Context) ([]*privateflow.Bundle, error) {
+	return c.bundles, nil
+}
+
+func TestPrivateFlowFeed(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	key, err := crypto.GenerateKey()
+	r.NoError(err)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{
+		Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &to,
+	})
+	r.NoError(err)
+	b, err := tx.MarshalBinary()
+	r.NoError(err)
+
+	client := &testPrivateFlowClient{bundles: []*privateflow.Bundle{{
+		ID:                "0xbundle",
+		Txs:               []string{"0x1234", hexutil.Encode(b)},
+		BlockNumber:       "0x64",
+		RevertingTxHashes: []string{tx.Hash().Hex()},
+	}}}
+	sender := mock_botio.NewMockSender(ctrl)
+	pf, err := NewPrivateFlowFeed(context.Background(), PrivateFlowFeedConfig{
+		ChainID:       1,
+		Source:        "relay",
+		Client:        client,
+		RequestSender: sender,
+		Bots:          []string{"0x1"},
+	})
+	r.NoError(err)
+
+	// the invalid tx is skipped and the bundle is sent once
+	sender.EXPECT().SendEvaluatePrivateTxRequest(gomock.Any(), []string{"0x1"}).Do(func(req *protocol.EvaluateTxRequest, botIDs []string) {
+		r.Equal(tx.Hash().Hex(), req.Event.Transaction.Hash)
+		r.True(agentgrpc.IsPendingTx(req.Event))
+		// marked as pending before the private tx
+		unknown := req.Event.ProtoReflect().GetUnknown()
+		num, _, n := protowire.ConsumeTag(unknown)
+		r.Equal(agentgrpc.MempoolStatusFieldNumber, num)
+		status, _ := protowire.ConsumeBytes(unknown[n:])
+		r.JSONEq(`{"status":"pending","pendingSeconds":0}`, string(status))
+		privateTx, err := privateflow.FromTx(req.Event)
+		r.NoError(err)
+		r.Equal(&privateflow.PrivateTx{
+			Source: "relay", BundleID: "0xbundle", Index: 1, TargetBlock: "0x64", CanRevert: true,
+		}, privateTx)
+	}).Times(1)
+	r.NoError(pf.poll())
+	r.NoError(pf.poll())
+	r.Equal(uint64(1), pf.bundleCount)
+	r.Equal(uint64(1), pf.txCount)
+}
+
+func TestPrivateFlowFeed_NoBots(t *testing.T) {
+	_, err := NewPrivateFlowFeed(context.Background(), PrivateFlowFeedConfig{Client: &testPrivateFlowClient{}})
+	require.Error(t, err)
+}
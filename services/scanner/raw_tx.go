@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-node/clients/rawtx"
+	log "github.com/sirupsen/logrus"
+)
+
+// RawTxSource provides the raw transactions.
+type RawTxSource interface {
+	RawTx(ctx context.Context, blockNumber uint64, txHash string) (*rawtx.RawTx, error)
+}
+
+// getRawTx returns the raw transaction or nil if it is not available. The pending transactions
+// are not in a block yet.
+func getRawTx(ctx context.Context, source RawTxSource, blockNumberHex, txHash string) *rawtx.RawTx {
+	if source == nil || len(blockNumberHex) == 0 || len(txHash) == 0 {
+		return nil
+	}
+	blockNumber, err := hexutil.DecodeUint64(blockNumberHex)
+	if err != nil {
+		return nil
+	}
+	rawTx, err := source.RawTx(ctx, blockNumber, txHash)
+	if err != nil {
+		log.WithError(err).WithField("block", blockNumberHex).Debug("failed to get the raw tx")
+		return nil
+	}
+	return rawTx
+}
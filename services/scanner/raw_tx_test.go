@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/rawtx"
+	"github.com/stretchr/testify/require"
+)
+
+type testRawTxSource struct {
+	txs map[string]*rawtx.RawTx
+}
+
+func (source *testRawTxSource) RawTx(ctx context.Context, blockNumber uint64, txHash string) (*rawtx.RawTx, error) {
+	rawTx, ok := source.txs[txHash]
+	if !ok || blockNumber != 16 {
+		return nil, errors.New("not found")
+	}
+	return rawTx, nil
+}
+
+func TestGetRawTx(t *testing.T) {
+	r := require.New(t)
+
+	expected := &rawtx.RawTx{Hash: "0xaa", Type: "0x2", TypeName: rawtx.TypeDynamicFee}
+	source := &testRawTxSource{txs: map[string]*rawtx.RawTx{"0xaa": expected}}
+
+	r.Equal(expected, getRawTx(context.Background(), source, "0x10", "0xaa"))
+	r.Nil(getRawTx(context.Background(), source, "0x11", "0xaa"))
+	r.Nil(getRawTx(context.Background(), source, "0x10", "0xbb"))
+	r.Nil(getRawTx(context.Background(), source, "invalid", "0xaa"))
+	// the pending transactions do not have a block
+	r.Nil(getRawTx(context.Background(), source, "", "0xaa"))
+	r.Nil(getRawTx(context.Background(), nil, "0x10", "0xaa"))
+}
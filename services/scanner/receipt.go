@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// TxEventToMessage converts the transaction event to the message which is sent to the bots.
+func TxEventToMessage(tx *domain.TransactionEvent) (*protocol.TransactionEvent, error) {
+	msg, err := tx.ToMessage()
+	if err != nil {
+		return nil, err
+	}
+	if tx.Receipt != nil {
+		applyReceipt(msg, tx.Receipt)
+	}
+	normalizeTx(msg.Transaction)
+	return msg, nil
+}
+
+// normalizeTx fills the numeric fields which are missing in the system transactions of some
+// chains, like the Optimism deposits and the Arbitrum internal transactions, so that the bots
+// can parse all transactions in the same way.
+func normalizeTx(tx *protocol.TransactionEvent_EthTransaction) {
+	if tx == nil {
+		return
+	}
+	for _, field := range []*string{&tx.Nonce, &tx.GasPrice, &tx.Gas, &tx.Value} {
+		if len(*field) == 0 {
+			*field = "0x0"
+		}
+	}
+	if len(tx.Input) == 0 {
+		tx.Input = "0x"
+	}
+}
+
+// applyReceipt replaces the receipt values which are approximated from the transaction
+// with the actual values from the transaction receipt.
+func applyReceipt(msg *protocol.TransactionEvent, receipt *domain.TransactionReceipt) {
+	if msg.Receipt == nil {
+		msg.Receipt = &protocol.TransactionEvent_EthReceipt{Logs: msg.Logs}
+	}
+	if receipt.Status != nil {
+		msg.Receipt.Status = *receipt.Status
+	}
+	if receipt.GasUsed != nil {
+		msg.Receipt.GasUsed = *receipt.GasUsed
+	}
+	if receipt.CumulativeGasUsed != nil {
+		msg.Receipt.CumulativeGasUsed = *receipt.CumulativeGasUsed
+	}
+	if receipt.LogsBloom != nil {
+		msg.Receipt.LogsBloom = *receipt.LogsBloom
+	}
+	if receipt.ContractAddress != nil {
+		msg.Receipt.ContractAddress = strings.ToLower(*receipt.ContractAddress)
+	}
+	// the logs from the block are preferred but the receipt logs are useful
+	// if the block logs are not available
+	if len(msg.Logs) == 0 && len(receipt.Logs) > 0 {
+		for _, l := range receipt.Logs {
+			msg.Logs = append(msg.Logs, &protocol.TransactionEvent_Log{
+				Address:          strings.ToLower(stringValue(l.Address)),
+				Topics:           stringValues(l.Topics),
+				Data:             stringValue(l.Data),
+				BlockNumber:      stringValue(l.BlockNumber),
+				TransactionHash:  stringValue(l.TransactionHash),
+				TransactionIndex: stringValue(l.TransactionIndex),
+				BlockHash:        stringValue(l.BlockHash),
+				LogIndex:         stringValue(l.LogIndex),
+				Removed:          l.Removed != nil && *l.Removed,
+			})
+		}
+		msg.Receipt.Logs = msg.Logs
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func stringValues(ss []*string) (values []string) {
+	for _, s := range ss {
+		values = append(values, stringValue(s))
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyReceipt(t *testing.T) {
+	r := require.New(t)
+
+	blockLogs := []*protocol.TransactionEvent_Log{{Address: "0x1"}}
+	msg := &protocol.TransactionEvent{
+		Logs: blockLogs,
+		Receipt: &protocol.TransactionEvent_EthReceipt{
+			Status:  "0x1",
+			GasUsed: "0x5208",
+			Logs:    blockLogs,
+		},
+	}
+
+	applyReceipt(msg, &domain.TransactionReceipt{
+		Status:            utils.StringPtr("0x0"),
+		GasUsed:           utils.StringPtr("0x5000"),
+		CumulativeGasUsed: utils.StringPtr("0x9000"),
+		LogsBloom:         utils.StringPtr("0xbloom"),
+		ContractAddress:   utils.StringPtr("0xABCD"),
+		Logs:              []domain.LogEntry{{Address: utils.StringPtr("0x2")}},
+	})
+
+	r.Equal("0x0", msg.Receipt.Status)
+	r.Equal("0x5000", msg.Receipt.GasUsed)
+	r.Equal("0x9000", msg.Receipt.CumulativeGasUsed)
+	r.Equal("0xbloom", msg.Receipt.LogsBloom)
+	r.Equal("0xabcd", msg.Receipt.ContractAddress)
+	// block logs are kept
+	r.Equal(blockLogs, msg.Logs)
+}
+
+func TestApplyReceipt_Logs(t *testing.T) {
+	r := require.New(t)
+
+	msg := &protocol.TransactionEvent{}
+	applyReceipt(msg, &domain.TransactionReceipt{
+		Status: utils.StringPtr("0x1"),
+		Logs: []domain.LogEntry{{
+			Address: utils.StringPtr("0xABCD"),
+			Topics:  []*string{utils.StringPtr("0xtopic")},
+			Data:    utils.StringPtr("0xdata"),
+		}},
+	})
+
+	r.Len(msg.Logs, 1)
+	r.Equal("0xabcd", msg.Logs[0].Address)
+	r.Equal([]string{"0xtopic"}, msg.Logs[0].Topics)
+	r.Equal("0xdata", msg.Logs[0].Data)
+	r.Equal(msg.Logs, msg.Receipt.Logs)
+	r.Equal("0x1", msg.Receipt.Status)
+}
+
+func TestNormalizeTx(t *testing.T) {
+	r := require.New(t)
+
+	// an Optimism deposit transaction without the gas price and the nonce
+	to := "0x4200000000000000000000000000000000000015"
+	msg, err := TxEventToMessage(&domain.TransactionEvent{
+		BlockEvt: &domain.BlockEvent{
+			Block: &domain.Block{Hash: "0x1", Number: "0x1", Timestamp: "0x1"},
+		},
+		Transaction: &domain.Transaction{Hash: "0x2", From: "0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001", To: &to, Gas: "0xf4240"},
+		Timestamps:  &domain.TrackingTimestamps{},
+	})
+	r.NoError(err)
+	r.Equal("0x0", msg.Transaction.GasPrice)
+	r.Equal("0x0", msg.Transaction.Nonce)
+	r.Equal("0x0", msg.Transaction.Value)
+	r.Equal("0xf4240", msg.Transaction.Gas)
+	r.Equal("0x", msg.Transaction.Input)
+}
@@ -0,0 +1,225 @@
+package scanner
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+)
+
+// RecencyQueue sits between the tx stream and the analyzers and sends the newest buffered blocks
+// and transactions first. When the node falls behind the chain, the feed fills the queue faster
+// than the bots process it, so the latest blocks are dispatched before the older ones which are
+// backfilled afterwards. The transactions of the same block keep their order.
+type RecencyQueue struct {
+	ctx context.Context
+	cfg RecencyQueueConfig
+
+	blocks *recencyHeap[*domain.BlockEvent]
+	txs    *recencyHeap[*domain.TransactionEvent]
+
+	blockOutput chan *domain.BlockEvent
+	txOutput    chan *domain.TransactionEvent
+}
+
+// RecencyQueueConfig contains the recency queue configuration.
+type RecencyQueueConfig struct {
+	BlockChannel <-chan *domain.BlockEvent
+	TxChannel    <-chan *domain.TransactionEvent
+	// the inputs are not read while the queues are full
+	MaxBlocks int
+	MaxTxs    int
+}
+
+// NewRecencyQueue creates a new recency queue.
+func NewRecencyQueue(ctx context.Context, cfg RecencyQueueConfig) *RecencyQueue {
+	return &RecencyQueue{
+		ctx:         ctx,
+		cfg:         cfg,
+		blocks:      &recencyHeap[*domain.BlockEvent]{},
+		txs:         &recencyHeap[*domain.TransactionEvent]{},
+		blockOutput: make(chan *domain.BlockEvent),
+		txOutput:    make(chan *domain.TransactionEvent),
+	}
+}
+
+// ReadOnlyBlockStream returns the prioritized block events.
+func (rq *RecencyQueue) ReadOnlyBlockStream() <-chan *domain.BlockEvent {
+	return rq.blockOutput
+}
+
+// ReadOnlyTxStream returns the prioritized transaction events.
+func (rq *RecencyQueue) ReadOnlyTxStream() <-chan *domain.TransactionEvent {
+	return rq.txOutput
+}
+
+// BlockQueueLen returns the amount of the buffered blocks.
+func (rq *RecencyQueue) BlockQueueLen() int {
+	return rq.blocks.size()
+}
+
+// TxQueueLen returns the amount of the buffered transactions.
+func (rq *RecencyQueue) TxQueueLen() int {
+	return rq.txs.size()
+}
+
+// LowestPendingBlock returns the oldest block which is not sent to the block analyzer yet. The
+// block checkpoint should not move past it.
+func (rq *RecencyQueue) LowestPendingBlock() (uint64, bool) {
+	return rq.blocks.lowest()
+}
+
+// Start implements services.Service.
+func (rq *RecencyQueue) Start() error {
+	go runRecencyQueue(rq.ctx, rq.cfg.BlockChannel, rq.blockOutput, rq.blocks, rq.cfg.MaxBlocks, blockEventNumber)
+	go runRecencyQueue(rq.ctx, rq.cfg.TxChannel, rq.txOutput, rq.txs, rq.cfg.MaxTxs, txEventNumber)
+	return nil
+}
+
+// Stop implements services.Service.
+func (rq *RecencyQueue) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (rq *RecencyQueue) Name() string {
+	return "recency-queue"
+}
+
+// runRecencyQueue moves the events from the input to the queue and sends the newest event to the
+// output. The output is closed after the input is closed and the queue is empty.
+func runRecencyQueue[E any](
+	ctx context.Context, input <-chan E, output chan<- E, queue *recencyHeap[E], max int,
+	blockNumber func(E) uint64,
+) {
+	for {
+		var (
+			inputCh  = input
+			outputCh chan<- E
+			next     E
+			hasNext  bool
+		)
+		if queue.size() >= max {
+			inputCh = nil
+		}
+		if next, hasNext = queue.peek(); hasNext {
+			outputCh = output
+		}
+		if input == nil && !hasNext {
+			close(output)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-inputCh:
+			if !ok {
+				input = nil
+				continue
+			}
+			queue.add(evt, blockNumber(evt))
+		case outputCh <- next:
+			queue.remove()
+		}
+	}
+}
+
+func blockEventNumber(evt *domain.BlockEvent) uint64 {
+	if evt.Block == nil {
+		return 0
+	}
+	n, _ := hexutil.DecodeUint64(evt.Block.Number)
+	return n
+}
+
+func txEventNumber(evt *domain.TransactionEvent) uint64 {
+	if evt.BlockEvt == nil {
+		return 0
+	}
+	return blockEventNumber(evt.BlockEvt)
+}
+
+type recencyItem[E any] struct {
+	event       E
+	blockNumber uint64
+	seq         uint64
+}
+
+// recencyHeap is a thread-safe max heap of the events by the block number. The events of the
+// same block are in the insertion order.
+type recencyHeap[E any] struct {
+	items   recencyItems[E]
+	nextSeq uint64
+	mu      sync.Mutex
+}
+
+func (rh *recencyHeap[E]) add(event E, blockNumber uint64) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	heap.Push(&rh.items, &recencyItem[E]{event: event, blockNumber: blockNumber, seq: rh.nextSeq})
+	rh.nextSeq++
+}
+
+func (rh *recencyHeap[E]) peek() (event E, ok bool) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if len(rh.items) == 0 {
+		return
+	}
+	return rh.items[0].event, true
+}
+
+func (rh *recencyHeap[E]) remove() {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if len(rh.items) > 0 {
+		heap.Pop(&rh.items)
+	}
+}
+
+func (rh *recencyHeap[E]) size() int {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	return len(rh.items)
+}
+
+func (rh *recencyHeap[E]) lowest() (lowest uint64, ok bool) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	for _, item := range rh.items {
+		if !ok || item.blockNumber < lowest {
+			lowest, ok = item.blockNumber, true
+		}
+	}
+	return
+}
+
+type recencyItems[E any] []*recencyItem[E]
+
+func (items recencyItems[E]) Len() int {
+	return len(items)
+}
+
+func (items recencyItems[E]) Less(i, j int) bool {
+	if items[i].blockNumber != items[j].blockNumber {
+		return items[i].blockNumber > items[j].blockNumber
+	}
+	return items[i].seq < items[j].seq
+}
+
+func (items recencyItems[E]) Swap(i, j int) {
+	items[i], items[j] = items[j], items[i]
+}
+
+func (items *recencyItems[E]) Push(x interface{}) {
+	*items = append(*items, x.(*recencyItem[E]))
+}
+
+func (items *recencyItems[E]) Pop() interface{} {
+	old := *items
+	item := old[len(old)-1]
+	*items = old[:len(old)-1]
+	return item
+}
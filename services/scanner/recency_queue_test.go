@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockEvent(number uint64) *domain.BlockEvent {
+	return &domain.BlockEvent{Block: &domain.Block{Number: hexutil.EncodeUint64(number)}}
+}
+
+func TestRecencyQueue(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks := make(chan *domain.BlockEvent, 3)
+	txs := make(chan *domain.TransactionEvent, 3)
+	rq := NewRecencyQueue(ctx, RecencyQueueConfig{
+		BlockChannel: blocks,
+		TxChannel:    txs,
+		MaxBlocks:    10,
+		MaxTxs:       10,
+	})
+
+	for _, number := range []uint64{1, 2, 3} {
+		blocks <- testBlockEvent(number)
+	}
+	txs <- &domain.TransactionEvent{BlockEvt: testBlockEvent(1), Transaction: &domain.Transaction{Hash: "0x1"}}
+	txs <- &domain.TransactionEvent{BlockEvt: testBlockEvent(2), Transaction: &domain.Transaction{Hash: "0x2"}}
+	txs <- &domain.TransactionEvent{BlockEvt: testBlockEvent(2), Transaction: &domain.Transaction{Hash: "0x3"}}
+	close(blocks)
+	close(txs)
+
+	r.NoError(rq.Start())
+	r.Eventually(func() bool {
+		return rq.BlockQueueLen() == 3 && rq.TxQueueLen() == 3
+	}, time.Second, 10*time.Millisecond)
+
+	lowest, ok := rq.LowestPendingBlock()
+	r.True(ok)
+	r.Equal(uint64(1), lowest)
+
+	// the newest block first
+	var numbers []string
+	for block := range rq.ReadOnlyBlockStream() {
+		numbers = append(numbers, block.Block.Number)
+	}
+	r.Equal([]string{"0x3", "0x2", "0x1"}, numbers)
+	_, ok = rq.LowestPendingBlock()
+	r.False(ok)
+
+	// the transactions of the same block in order
+	var hashes []string
+	for tx := range rq.ReadOnlyTxStream() {
+		hashes = append(hashes, tx.Transaction.Hash)
+	}
+	r.Equal([]string{"0x2", "0x3", "0x1"}, hashes)
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+)
+
+// DefaultReorgDetectionWindow is the amount of latest blocks which the reorg detector remembers.
+const DefaultReorgDetectionWindow = 256
+
+// ReorgDetector tracks the block hashes by block number and detects the chain reorganizations.
+// The result is cached per block hash so that the block and the transactions of the same block
+// are marked consistently, regardless of which analyzer observes the block first.
+type ReorgDetector struct {
+	window uint64
+	blocks map[uint64]*reorgRecord
+	max    uint64
+	mu     sync.Mutex
+}
+
+type reorgRecord struct {
+	canonicalHash string
+	reorgs        map[string]bool
+}
+
+// NewReorgDetector creates a new reorg detector.
+func NewReorgDetector(window uint64) *ReorgDetector {
+	if window == 0 {
+		window = DefaultReorgDetectionWindow
+	}
+	return &ReorgDetector{
+		window: window,
+		blocks: make(map[uint64]*reorgRecord),
+	}
+}
+
+// Observe saves the block as the latest canonical block at its height and tells if the block
+// replaces a previously observed block or does not follow the previously observed parent.
+func (rd *ReorgDetector) Observe(block *domain.Block) bool {
+	if block == nil || len(block.Number) == 0 {
+		return false
+	}
+	number, err := hexutil.DecodeUint64(block.Number)
+	if err != nil {
+		return false
+	}
+
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	record, ok := rd.blocks[number]
+	if ok {
+		if isReorg, seen := record.reorgs[block.Hash]; seen {
+			return isReorg
+		}
+	} else {
+		record = &reorgRecord{reorgs: make(map[string]bool)}
+		rd.blocks[number] = record
+	}
+
+	isReorg := len(record.canonicalHash) > 0 && record.canonicalHash != block.Hash
+	if parent, ok := rd.blocks[number-1]; ok && len(block.ParentHash) > 0 && parent.canonicalHash != block.ParentHash {
+		isReorg = true
+	}
+	record.canonicalHash = block.Hash
+	record.reorgs[block.Hash] = isReorg
+
+	if number > rd.max {
+		rd.max = number
+		rd.prune()
+	}
+	return isReorg
+}
+
+// IsReplaced tells if a different block was observed as the canonical block at the height.
+func (rd *ReorgDetector) IsReplaced(number uint64, hash string) bool {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	record, ok := rd.blocks[number]
+	return ok && len(record.canonicalHash) > 0 && record.canonicalHash != hash
+}
+
+func (rd *ReorgDetector) prune() {
+	if rd.max < rd.window {
+		return
+	}
+	for number := range rd.blocks {
+		if number <= rd.max-rd.window {
+			delete(rd.blocks, number)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorgDetector(t *testing.T) {
+	r := require.New(t)
+
+	rd := NewReorgDetector(0)
+
+	r.False(rd.Observe(&domain.Block{Number: "0x1", Hash: "0xa1"}))
+	r.False(rd.Observe(&domain.Block{Number: "0x2", Hash: "0xa2", ParentHash: "0xa1"}))
+	// observing the same block again should give the same result
+	r.False(rd.Observe(&domain.Block{Number: "0x2", Hash: "0xa2", ParentHash: "0xa1"}))
+
+	// a different block at the same height
+	r.True(rd.Observe(&domain.Block{Number: "0x2", Hash: "0xb2", ParentHash: "0xa1"}))
+	r.True(rd.Observe(&domain.Block{Number: "0x2", Hash: "0xb2", ParentHash: "0xa1"}))
+
+	// following the new canonical block is fine
+	r.False(rd.Observe(&domain.Block{Number: "0x3", Hash: "0xb3", ParentHash: "0xb2"}))
+
+	// not following the canonical parent is a reorg
+	r.True(rd.Observe(&domain.Block{Number: "0x4", Hash: "0xc4", ParentHash: "0xc3"}))
+
+	// pending txs have no block info
+	r.False(rd.Observe(&domain.Block{}))
+}
+
+func TestReorgDetectorPrune(t *testing.T) {
+	r := require.New(t)
+
+	rd := NewReorgDetector(2)
+	r.False(rd.Observe(&domain.Block{Number: "0x1", Hash: "0xa1"}))
+	r.False(rd.Observe(&domain.Block{Number: "0x2", Hash: "0xa2", ParentHash: "0xa1"}))
+	r.False(rd.Observe(&domain.Block{Number: "0x3", Hash: "0xa3", ParentHash: "0xa2"}))
+	r.Len(rd.blocks, 2)
+}
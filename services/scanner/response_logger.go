@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+)
+
+// responseLogQueueSize is the amount of sampled responses which can wait to be logged.
+const responseLogQueueSize = 100
+
+type responseLogEntry struct {
+	botID    string
+	response proto.Message
+}
+
+// ResponseLogger logs a sample of the bot responses as JSON at the debug level. The responses are
+// marshaled in the background so that the analyzers do not wait for it, and the samples are
+// dropped if the logger falls behind. A nil logger logs nothing.
+type ResponseLogger struct {
+	sampleRate uint64
+	count      uint64
+	entries    chan *responseLogEntry
+	buffers    sync.Pool
+}
+
+// NewResponseLogger creates a new response logger which logs one of every sampleRate responses.
+func NewResponseLogger(ctx context.Context, sampleRate int) *ResponseLogger {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	rl := &ResponseLogger{
+		sampleRate: uint64(sampleRate),
+		entries:    make(chan *responseLogEntry, responseLogQueueSize),
+		buffers: sync.Pool{
+			New: func() interface{} {
+				return new(bytes.Buffer)
+			},
+		},
+	}
+	go rl.run(ctx)
+	return rl
+}
+
+// Log samples the response. The response is copied, so it can be changed after this call.
+func (rl *ResponseLogger) Log(botID string, response proto.Message) {
+	if rl == nil || !log.IsLevelEnabled(log.DebugLevel) {
+		return
+	}
+	if (atomic.AddUint64(&rl.count, 1)-1)%rl.sampleRate != 0 {
+		return
+	}
+	select {
+	case rl.entries <- &responseLogEntry{botID: botID, response: proto.Clone(response)}:
+	default:
+	}
+}
+
+func (rl *ResponseLogger) run(ctx context.Context) {
+	m := jsonpb.Marshaler{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-rl.entries:
+			buf := rl.buffers.Get().(*bytes.Buffer)
+			if err := m.Marshal(buf, entry.response); err != nil {
+				log.WithError(err).WithField("bot", entry.botID).Error("error marshaling response")
+			} else {
+				log.WithField("bot", entry.botID).Debug(buf.String())
+			}
+			buf.Reset()
+			rl.buffers.Put(buf)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseLogger(t *testing.T) {
+	r := require.New(t)
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(level)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rl := NewResponseLogger(ctx, 3)
+
+	for i := 0; i < 7; i++ {
+		resp := &protocol.EvaluateTxResponse{Findings: []*protocol.Finding{{Name: "finding"}}}
+		rl.Log("0xbot", resp)
+		// the logger does not see the later changes
+		resp.Findings = nil
+	}
+
+	// one of every three responses
+	r.Eventually(func() bool {
+		return len(hook.AllEntries()) == 3
+	}, time.Second, 10*time.Millisecond)
+	for _, entry := range hook.AllEntries() {
+		r.Equal("0xbot", entry.Data["bot"])
+		r.Contains(entry.Message, "finding")
+	}
+
+	// nil logger logs nothing
+	var nilLogger *ResponseLogger
+	nilLogger.Log("0xbot", &protocol.EvaluateTxResponse{})
+}
@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultScheduleTickInterval is how often the schedule feed checks which bots are due.
+const DefaultScheduleTickInterval = 10 * time.Second
+
+// LatestBlockSource gets the latest block of the chain.
+type LatestBlockSource interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error)
+}
+
+// ScheduleFeed evaluates the bots which subscribe to the schedule events periodically with the
+// latest block, so that the bots which check a state from time to time, like the TVL of a protocol,
+// do not need to receive every block.
+type ScheduleFeed struct {
+	ctx context.Context
+	cfg ScheduleFeedConfig
+
+	// the last evaluations by the bot IDs
+	lastRuns map[string]time.Time
+
+	lastEvent    health.TimeTracker
+	lastCheckErr health.ErrorTracker
+	eventCount   uint64
+}
+
+// ScheduleFeedConfig contains the schedule feed configuration.
+type ScheduleFeedConfig struct {
+	ChainID       int
+	Blocks        LatestBlockSource
+	BotPool       botio.BotPool
+	RequestSender botio.Sender
+	// the interval of the bots which do not set one
+	DefaultInterval time.Duration
+	// the shorter intervals of the bots are raised to this
+	MinInterval  time.Duration
+	TickInterval time.Duration
+}
+
+// NewScheduleFeed creates a new schedule feed.
+func NewScheduleFeed(ctx context.Context, cfg ScheduleFeedConfig) (*ScheduleFeed, error) {
+	if cfg.DefaultInterval <= 0 || cfg.MinInterval <= 0 {
+		return nil, errors.New("schedule feed intervals are required")
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = DefaultScheduleTickInterval
+	}
+	return &ScheduleFeed{
+		ctx:      ctx,
+		cfg:      cfg,
+		lastRuns: make(map[string]time.Time),
+	}, nil
+}
+
+// Start implements services.Service.
+func (sf *ScheduleFeed) Start() error {
+	go func() {
+		ticker := time.NewTicker(sf.cfg.TickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sf.ctx.Done():
+				return
+			case now := <-ticker.C:
+				err := sf.tick(now)
+				sf.lastCheckErr.Set(err)
+				if err != nil {
+					log.WithError(err).WithField("chainId", sf.cfg.ChainID).Warn("failed to evaluate the scheduled bots")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (sf *ScheduleFeed) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (sf *ScheduleFeed) Name() string {
+	return "schedule-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (sf *ScheduleFeed) Health() health.Reports {
+	return health.Reports{
+		sf.lastEvent.GetReport("event.schedule.time"),
+		sf.lastCheckErr.GetReport("event.schedule.error"),
+		&health.Report{
+			Name:    "event.schedule.count",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&sf.eventCount), 10),
+		},
+	}
+}
+
+// tick sends the latest block to the bots which are due. The bots which have the same interval
+// share the same request. The bots are evaluated at the first tick after they are added.
+func (sf *ScheduleFeed) tick(now time.Time) error {
+	dueBots := make(map[time.Duration][]string)
+	current := make(map[string]bool)
+	for _, bot := range sf.cfg.BotPool.GetCurrentBotClients() {
+		botConfig := bot.Config()
+		interval, ok := sf.botInterval(botConfig.Filters)
+		if !ok {
+			continue
+		}
+		current[botConfig.ID] = true
+		if lastRun, ok := sf.lastRuns[botConfig.ID]; ok && now.Sub(lastRun) < interval {
+			continue
+		}
+		dueBots[interval] = append(dueBots[interval], botConfig.ID)
+	}
+	// forget the removed bots, so that they are evaluated again as soon as they are added back
+	for botID := range sf.lastRuns {
+		if !current[botID] {
+			delete(sf.lastRuns, botID)
+		}
+	}
+	if len(dueBots) == 0 {
+		return nil
+	}
+
+	block, err := sf.cfg.Blocks.BlockByNumber(sf.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest block: %v", err)
+	}
+	intervals := make([]time.Duration, 0, len(dueBots))
+	for interval := range dueBots {
+		intervals = append(intervals, interval)
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	for _, interval := range intervals {
+		req, err := ScheduledBlockToRequest(sf.cfg.ChainID, block, &agentgrpc.Schedule{
+			IntervalSeconds: int(interval / time.Second),
+			Time:            now.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the scheduled request of block %s: %v", block.Number, err)
+		}
+		sf.cfg.RequestSender.SendEvaluateScheduledRequest(req, dueBots[interval])
+		for _, botID := range dueBots[interval] {
+			sf.lastRuns[botID] = now
+		}
+		atomic.AddUint64(&sf.eventCount, 1)
+	}
+	sf.lastEvent.Set()
+	return nil
+}
+
+// botInterval returns the interval of a bot which subscribes to the schedule events of this chain.
+func (sf *ScheduleFeed) botInterval(filters *config.BotFilters) (time.Duration, bool) {
+	if filters == nil || !containsString(filters.EventTypes, config.BotEventSchedule) || !filtersChain(filters, sf.cfg.ChainID) {
+		return 0, false
+	}
+	interval := sf.cfg.DefaultInterval
+	if filters.ScheduleIntervalSeconds > 0 {
+		interval = time.Duration(filters.ScheduleIntervalSeconds) * time.Second
+	}
+	if interval < sf.cfg.MinInterval {
+		interval = sf.cfg.MinInterval
+	}
+	return interval, true
+}
+
+// ScheduledBlockToRequest converts the latest block to a scheduled evaluation request.
+func ScheduledBlockToRequest(chainID int, block *domain.Block, schedule *agentgrpc.Schedule) (*protocol.EvaluateBlockRequest, error) {
+	blockEvt, err := (&domain.BlockEvent{
+		EventType:  domain.EventTypeBlock,
+		ChainID:    big.NewInt(int64(chainID)),
+		Block:      block,
+		Timestamps: &domain.TrackingTimestamps{Feed: time.Now().UTC()},
+	}).ToMessage()
+	if err != nil {
+		return nil, err
+	}
+	if err := agentgrpc.AttachSchedule(blockEvt, schedule); err != nil {
+		return nil, err
+	}
+	return &protocol.EvaluateBlockRequest{
+		RequestId: uuid.Must(uuid.NewUUID()).String(),
+		Event:     blockEvt,
+	}, nil
+}
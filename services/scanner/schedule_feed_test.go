@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testLatestBlockSource struct {
+	calls int
+}
+
+func (s *testLatestBlockSource) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	s.calls++
+	return &domain.Block{Hash: "0xblock", Number: "0x64", Timestamp: "0x1"}, nil
+}
+
+func TestScheduleFeed(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	sender := mock_botio.NewMockSender(ctrl)
+	// uses the default interval
+	defaultBot := mock_botio.NewMockBotClient(ctrl)
+	defaultBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x1", Filters: &config.BotFilters{
+		EventTypes: []string{config.BotEventSchedule},
+	}}).AnyTimes()
+	// the interval is raised to the min interval
+	fastBot := mock_botio.NewMockBotClient(ctrl)
+	fastBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x2", Filters: &config.BotFilters{
+		EventTypes:              []string{config.BotEventSchedule},
+		ScheduleIntervalSeconds: 1,
+	}}).AnyTimes()
+	txBot := mock_botio.NewMockBotClient(ctrl)
+	txBot.EXPECT().Config().Return(config.AgentConfig{ID: "0x3"}).AnyTimes()
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{defaultBot, fastBot, txBot}).AnyTimes()
+
+	blocks := &testLatestBlockSource{}
+	sf, err := NewScheduleFeed(context.Background(), ScheduleFeedConfig{
+		ChainID:         1,
+		Blocks:          blocks,
+		BotPool:         botPool,
+		RequestSender:   sender,
+		DefaultInterval: 10 * time.Minute,
+		MinInterval:     time.Minute,
+	})
+	r.NoError(err)
+
+	sent := make(map[int][]string)
+	sender.EXPECT().SendEvaluateScheduledRequest(gomock.Any(), gomock.Any()).Do(func(req *protocol.EvaluateBlockRequest, botIDs []string) {
+		schedule, err := agentgrpc.ScheduleFromBlock(req.Event)
+		r.NoError(err)
+		r.NotNil(schedule)
+		r.Equal("0x1", req.Event.Network.ChainId)
+		r.Equal("0x64", req.Event.BlockNumber)
+		sent[schedule.IntervalSeconds] = append(sent[schedule.IntervalSeconds], botIDs...)
+	}).AnyTimes()
+
+	// all of the bots are due at the first tick
+	start := time.Now()
+	r.NoError(sf.tick(start))
+	r.Equal(map[int][]string{600: {"0x1"}, 60: {"0x2"}}, sent)
+
+	// none of the bots are due before the min interval
+	sent = make(map[int][]string)
+	r.NoError(sf.tick(start.Add(30 * time.Second)))
+	r.Empty(sent)
+	r.Equal(1, blocks.calls)
+
+	// only the fast bot is due after the min interval
+	r.NoError(sf.tick(start.Add(time.Minute)))
+	r.Equal(map[int][]string{60: {"0x2"}}, sent)
+}
@@ -0,0 +1,288 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Self finding alert ids
+const (
+	RPCFailureAlertID     = "FORTA-NODE-RPC-FAILURE"
+	BotCrashAlertID       = "FORTA-NODE-BOT-CRASH"
+	LagSpikeAlertID       = "FORTA-NODE-LAG-SPIKE"
+	PublishFailureAlertID = "FORTA-NODE-PUBLISH-FAILURE"
+	BotDisabledAlertID    = "FORTA-NODE-BOT-DISABLED"
+)
+
+// NodeBotID is the bot id of the self findings, so that they can be told apart from the
+// findings of the bots.
+var NodeBotID = crypto.Keccak256Hash([]byte("forta-node")).Hex()
+
+// SelfFindings creates the findings about the node itself and sends them with the alerts of the
+// bots, so that the operators can monitor the node with the same alert consumers. The findings are
+// private, since they are about the operator's infrastructure.
+type SelfFindings struct {
+	ctx context.Context
+	cfg SelfFindingsConfig
+
+	cooldown *findingCooldown
+
+	lastCheck   health.TimeTracker
+	lastSendErr health.ErrorTracker
+}
+
+// SelfFindingsConfig contains the self findings configuration. The failing reports of the
+// reporters are turned into findings by the kind of the reporters.
+type SelfFindingsConfig struct {
+	ChainID     int
+	AlertSender clients.AlertSender
+	// the bot restarts and the disabled bots are received from the messages - nil disables the
+	// bot crash and the disabled bot findings
+	MsgClient clients.MessageClient
+	// the json-rpc clients
+	RPCReporters []health.Reporter
+	// the reporters which are lagging when the node falls behind the chain
+	LagReporters []health.Reporter
+	// the alert publishers
+	PublishReporters []health.Reporter
+	Interval         time.Duration
+	Cooldown         time.Duration
+}
+
+// NewSelfFindings creates new self findings.
+func NewSelfFindings(ctx context.Context, cfg SelfFindingsConfig) (*SelfFindings, error) {
+	if cfg.AlertSender == nil {
+		return nil, errors.New("self findings need an alert sender")
+	}
+	return &SelfFindings{
+		ctx:      ctx,
+		cfg:      cfg,
+		cooldown: newFindingCooldown(cfg.Cooldown),
+	}, nil
+}
+
+// Start implements services.Service.
+func (sf *SelfFindings) Start() error {
+	if sf.cfg.MsgClient != nil {
+		sf.cfg.MsgClient.Subscribe(messaging.SubjectAgentsStatusRestarted, messaging.AgentsHandler(sf.handleBotsRestarted))
+		sf.cfg.MsgClient.Subscribe(messaging.SubjectAgentsStatusDisabled, messaging.AgentPerformanceHandler(sf.handleBotDisabled))
+	}
+	go func() {
+		ticker := time.NewTicker(sf.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sf.ctx.Done():
+				return
+			case <-ticker.C:
+				sf.check()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements services.Service.
+func (sf *SelfFindings) Stop() error {
+	return nil
+}
+
+// Name implements services.Service.
+func (sf *SelfFindings) Name() string {
+	return "self-findings"
+}
+
+// Health implements the health.Reporter interface.
+func (sf *SelfFindings) Health() health.Reports {
+	return health.Reports{
+		sf.lastCheck.GetReport("event.checked.time"),
+		sf.lastSendErr.GetReport("event.sent.error"),
+	}
+}
+
+func (sf *SelfFindings) handleBotsRestarted(payload messaging.AgentPayload) error {
+	for _, bot := range payload {
+		sf.send(bot.ID, &protocol.Finding{
+			AlertId:     BotCrashAlertID,
+			Name:        "Bot crashed",
+			Description: fmt.Sprintf("Bot %s stopped working and was restarted by the node", bot.ID),
+			Protocol:    "forta",
+			Severity:    protocol.Finding_MEDIUM,
+			Type:        protocol.Finding_INFORMATION,
+			Metadata: map[string]string{
+				"botId":    bot.ID,
+				"botImage": bot.Image,
+			},
+		})
+	}
+	return nil
+}
+
+func (sf *SelfFindings) handleBotDisabled(payload messaging.AgentPerformancePayload) error {
+	sf.send(payload.Agent.ID, &protocol.Finding{
+		AlertId:     BotDisabledAlertID,
+		Name:        "Bot disabled",
+		Description: fmt.Sprintf("Bot %s was disabled by the node because of its low performance score", payload.Agent.ID),
+		Protocol:    "forta",
+		Severity:    protocol.Finding_MEDIUM,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata: map[string]string{
+			"botId":        payload.Agent.ID,
+			"botImage":     payload.Agent.Image,
+			"score":        strconv.FormatFloat(payload.Score, 'f', 2, 64),
+			"timeoutRate":  strconv.FormatFloat(payload.TimeoutRate, 'f', 2, 64),
+			"errorRate":    strconv.FormatFloat(payload.ErrorRate, 'f', 2, 64),
+			"avgLatencyMs": strconv.FormatFloat(payload.AvgLatencyMs, 'f', 0, 64),
+		},
+	})
+	return nil
+}
+
+func (sf *SelfFindings) check() {
+	for _, kind := range []struct {
+		alertID   string
+		name      string
+		severity  protocol.Finding_Severity
+		reporters []health.Reporter
+		statuses  []health.Status
+	}{
+		{
+			alertID:   RPCFailureAlertID,
+			name:      "JSON-RPC requests are failing",
+			severity:  protocol.Finding_HIGH,
+			reporters: sf.cfg.RPCReporters,
+			statuses:  []health.Status{health.StatusFailing, health.StatusDown},
+		},
+		{
+			alertID:   LagSpikeAlertID,
+			name:      "Node is lagging",
+			severity:  protocol.Finding_MEDIUM,
+			reporters: sf.cfg.LagReporters,
+			statuses:  []health.Status{health.StatusLagging},
+		},
+		{
+			alertID:   PublishFailureAlertID,
+			name:      "Alert publishing is failing",
+			severity:  protocol.Finding_HIGH,
+			reporters: sf.cfg.PublishReporters,
+			statuses:  []health.Status{health.StatusFailing, health.StatusDown},
+		},
+	} {
+		for _, reporter := range kind.reporters {
+			for _, report := range reporter.Health() {
+				if !hasStatus(report.Status, kind.statuses) {
+					continue
+				}
+				subject := fmt.Sprintf("%s.%s", reporter.Name(), report.Name)
+				sf.send(subject, selfFinding(kind.alertID, kind.name, kind.severity, subject, report))
+			}
+		}
+	}
+	sf.lastCheck.Set()
+}
+
+func hasStatus(status health.Status, statuses []health.Status) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+func selfFinding(
+	alertID, name string, severity protocol.Finding_Severity, subject string, report *health.Report,
+) *protocol.Finding {
+	description := fmt.Sprintf("%s: %s is %s", name, subject, report.Status)
+	if len(report.Details) > 0 {
+		description = fmt.Sprintf("%s (%s)", description, report.Details)
+	}
+	return &protocol.Finding{
+		AlertId:     alertID,
+		Name:        name,
+		Description: description,
+		Protocol:    "forta",
+		Severity:    severity,
+		Type:        protocol.Finding_INFORMATION,
+		Metadata: map[string]string{
+			"report":  subject,
+			"status":  string(report.Status),
+			"details": report.Details,
+		},
+	}
+}
+
+// send sends the finding unless a finding was sent for the same failure within the cooldown.
+func (sf *SelfFindings) send(subject string, finding *protocol.Finding) {
+	key := strings.Join([]string{finding.AlertId, subject}, "|")
+	if !sf.cooldown.allow(key) {
+		return
+	}
+	err := sendNodeFinding(sf.cfg.AlertSender, sf.cfg.ChainID, key, finding)
+	sf.lastSendErr.Set(err)
+	if err != nil {
+		log.WithError(err).WithField("alertId", finding.AlertId).Warn("failed to send self finding")
+	}
+}
+
+// findingCooldown tells if a finding can be sent again after the cooldown.
+type findingCooldown struct {
+	cooldown time.Duration
+	// the last finding times by the key
+	lastFindings map[string]time.Time
+	mu           sync.Mutex
+}
+
+func newFindingCooldown(cooldown time.Duration) *findingCooldown {
+	return &findingCooldown{cooldown: cooldown, lastFindings: make(map[string]time.Time)}
+}
+
+func (fc *findingCooldown) allow(key string) bool {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := fc.lastFindings[key]; ok && now.Sub(last) < fc.cooldown {
+		return false
+	}
+	fc.lastFindings[key] = now
+	return true
+}
+
+// sendNodeFinding sends the finding as a private alert of the node. The key makes the alert
+// id unique together with the time.
+func sendNodeFinding(alertSender clients.AlertSender, chainIDInt int, key string, finding *protocol.Finding) error {
+	now := time.Now()
+	finding.Private = true
+	nodeBot := config.AgentConfig{ID: NodeBotID}
+	chainID := strconv.Itoa(chainIDInt)
+	alert := &protocol.Alert{
+		Id:        crypto.Keccak256Hash([]byte(fmt.Sprintf("%s|%d", key, now.UnixNano()))).Hex(),
+		Finding:   finding,
+		Timestamp: now.UTC().Format(utils.AlertTimeFormat),
+		Type:      protocol.AlertType_PRIVATE,
+		Agent:     nodeBot.ToAgentInfo(),
+		Tags: map[string]string{
+			"agentId": NodeBotID,
+			"chainId": chainID,
+		},
+	}
+	return alertSender.SignAlertAndNotify(
+		&clients.AgentRoundTrip{AgentConfig: nodeBot}, alert, chainID, "", &domain.TrackingTimestamps{},
+	)
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testReporter struct {
+	name    string
+	reports health.Reports
+}
+
+func (tr *testReporter) Name() string {
+	return tr.name
+}
+
+func (tr *testReporter) Health() health.Reports {
+	return tr.reports
+}
+
+func TestSelfFindings(t *testing.T) {
+	r := require.New(t)
+
+	var rpcErr health.ErrorTracker
+	rpcErr.Set(errors.New("connection refused"))
+	rpc := &testReporter{name: "chain-json-rpc", reports: health.Reports{rpcErr.GetReport("request.block-by-number.error")}}
+	lag := &testReporter{name: "block-lag-monitor", reports: health.Reports{{Name: "blocks", Status: health.StatusOK}}}
+
+	alertSender := &countingAlertSender{}
+	sf, err := NewSelfFindings(context.Background(), SelfFindingsConfig{
+		ChainID:      1,
+		AlertSender:  alertSender,
+		RPCReporters: []health.Reporter{rpc},
+		LagReporters: []health.Reporter{lag},
+		Cooldown:     time.Hour,
+	})
+	r.NoError(err)
+
+	sf.check()
+	r.Len(alertSender.sent, 1)
+	alert := alertSender.sent[0]
+	r.Equal(RPCFailureAlertID, alert.Finding.AlertId)
+	r.Equal("chain-json-rpc.request.block-by-number.error", alert.Finding.Metadata["report"])
+	r.Equal("connection refused", alert.Finding.Metadata["details"])
+	r.True(alert.Finding.Private)
+	r.Equal(protocol.AlertType_PRIVATE, alert.Type)
+	r.Equal(NodeBotID, alert.Agent.Id)
+
+	// the same failure is not repeated within the cooldown
+	lag.reports[0].Status = health.StatusLagging
+	sf.check()
+	r.Len(alertSender.sent, 2)
+	r.Equal(LagSpikeAlertID, alertSender.sent[1].Finding.AlertId)
+
+	r.NoError(sf.handleBotsRestarted(messaging.AgentPayload{{ID: "0x1"}, {ID: "0x2"}}))
+	r.NoError(sf.handleBotsRestarted(messaging.AgentPayload{config.AgentConfig{ID: "0x1"}}))
+	r.Len(alertSender.sent, 4)
+	r.Equal(BotCrashAlertID, alertSender.sent[3].Finding.AlertId)
+	r.Equal("0x2", alertSender.sent[3].Finding.Metadata["botId"])
+
+	r.NoError(sf.handleBotDisabled(messaging.AgentPerformancePayload{
+		Agent: config.AgentConfig{ID: "0x1"}, Score: 0.25, TimeoutRate: 0.5, AvgLatencyMs: 1200,
+	}))
+	r.Len(alertSender.sent, 5)
+	r.Equal(BotDisabledAlertID, alertSender.sent[4].Finding.AlertId)
+	r.Equal("0.25", alertSender.sent[4].Finding.Metadata["score"])
+	r.Equal("1200", alertSender.sent[4].Finding.Metadata["avgLatencyMs"])
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-node/config"
+)
+
+// Shard decides which chain events this node processes when multiple nodes share a chain. All
+// nodes compute the same assignment, so each event is processed by exactly one of them. A nil
+// shard owns all events.
+type Shard struct {
+	shards uint64
+	id     uint64
+	byTx   bool
+}
+
+// NewShard creates a new shard. It returns nil if the events are not split.
+func NewShard(cfg config.ShardingConfig) *Shard {
+	if cfg.Shards <= 1 {
+		return nil
+	}
+	return &Shard{
+		shards: uint64(cfg.Shards),
+		id:     uint64(cfg.ShardID),
+		byTx:   cfg.By == config.ShardByTx,
+	}
+}
+
+// OwnsBlock tells if this node should send the block to the bots.
+func (shard *Shard) OwnsBlock(block *domain.Block) bool {
+	if shard == nil || block == nil {
+		return true
+	}
+	blockNumber, err := hexutil.DecodeUint64(block.Number)
+	if err != nil {
+		// better to have duplicates than to miss the block in all nodes
+		return true
+	}
+	return blockNumber%shard.shards == shard.id
+}
+
+// OwnsTx tells if this node should send the transaction to the bots. The pending transactions
+// are not in a block yet, so they are always split by the hash.
+func (shard *Shard) OwnsTx(tx *domain.TransactionEvent) bool {
+	if shard == nil {
+		return true
+	}
+	var block *domain.Block
+	if tx.BlockEvt != nil {
+		block = tx.BlockEvt.Block
+	}
+	if shard.byTx || block == nil || len(block.Number) == 0 {
+		return shard.ownsHash(tx.Transaction.Hash)
+	}
+	return shard.OwnsBlock(block)
+}
+
+// OwnsAlert tells if this node should send the alert to the combiner bots.
+func (shard *Shard) OwnsAlert(alertHash string) bool {
+	if shard == nil {
+		return true
+	}
+	return shard.ownsHash(alertHash)
+}
+
+func (shard *Shard) ownsHash(hash string) bool {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(hash)))
+	return h.Sum64()%shard.shards == shard.id
+}
+
+// String returns the shard as "<id>/<shards>".
+func (shard *Shard) String() string {
+	if shard == nil {
+		return "0/1"
+	}
+	return fmt.Sprintf("%d/%d", shard.id, shard.shards)
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testShardTx(blockNumber string, i int) *domain.TransactionEvent {
+	return &domain.TransactionEvent{
+		BlockEvt:    &domain.BlockEvent{Block: &domain.Block{Number: blockNumber}},
+		Transaction: &domain.Transaction{Hash: fmt.Sprintf("0x%064x", i)},
+	}
+}
+
+func TestShard_NotSplit(t *testing.T) {
+	r := require.New(t)
+
+	shard := NewShard(config.ShardingConfig{Shards: 1})
+	r.Nil(shard)
+	r.True(shard.OwnsBlock(&domain.Block{Number: "0x1"}))
+	r.True(shard.OwnsTx(testShardTx("0x1", 1)))
+	r.True(shard.OwnsAlert("0x1"))
+}
+
+func TestShard_ByBlock(t *testing.T) {
+	r := require.New(t)
+
+	shards := []*Shard{
+		NewShard(config.ShardingConfig{Shards: 3, ShardID: 0, By: config.ShardByBlock}),
+		NewShard(config.ShardingConfig{Shards: 3, ShardID: 1, By: config.ShardByBlock}),
+		NewShard(config.ShardingConfig{Shards: 3, ShardID: 2, By: config.ShardByBlock}),
+	}
+	r.True(shards[1].OwnsBlock(&domain.Block{Number: "0x4"}))
+	r.False(shards[0].OwnsBlock(&domain.Block{Number: "0x4"}))
+
+	// the transactions follow their blocks
+	for i := 0; i < 10; i++ {
+		r.True(shards[1].OwnsTx(testShardTx("0x4", i)))
+		r.False(shards[2].OwnsTx(testShardTx("0x4", i)))
+	}
+}
+
+func TestShard_ByTx(t *testing.T) {
+	r := require.New(t)
+
+	var shards []*Shard
+	for i := uint(0); i < 4; i++ {
+		shards = append(shards, NewShard(config.ShardingConfig{Shards: 4, ShardID: i, By: config.ShardByTx}))
+	}
+
+	// each event is owned by exactly one shard
+	owned := make([]int, len(shards))
+	for i := 0; i < 1000; i++ {
+		tx := testShardTx("0x4", i)
+		alertHash := fmt.Sprintf("0xalert%d", i)
+		txOwners, alertOwners := 0, 0
+		for j, shard := range shards {
+			if shard.OwnsTx(tx) {
+				txOwners++
+				owned[j]++
+			}
+			if shard.OwnsAlert(alertHash) {
+				alertOwners++
+			}
+		}
+		r.Equal(1, txOwners)
+		r.Equal(1, alertOwners)
+	}
+	for _, count := range owned {
+		r.Greater(count, 150)
+	}
+
+	// the blocks are still split by the number
+	r.True(shards[0].OwnsBlock(&domain.Block{Number: "0x4"}))
+	r.False(shards[1].OwnsBlock(&domain.Block{Number: "0x4"}))
+}
+
+func TestShard_PendingTx(t *testing.T) {
+	r := require.New(t)
+
+	shard := NewShard(config.ShardingConfig{Shards: 2, ShardID: 0, By: config.ShardByBlock})
+	other := NewShard(config.ShardingConfig{Shards: 2, ShardID: 1, By: config.ShardByBlock})
+	tx := testShardTx("", 1)
+	r.NotEqual(shard.OwnsTx(tx), other.OwnsTx(tx))
+}
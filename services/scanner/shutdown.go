@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/forta-network/forta-node/services/components"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// inputStopTimeout bounds the wait for an input loop which can be blocked while sending to the bots.
+var inputStopTimeout = time.Second * 5
+
+var errInputLoopTimeout = errors.New("timed out while waiting for the input loop to stop")
+
+func waitInputLoop(inputDone <-chan struct{}) error {
+	select {
+	case <-inputDone:
+		return nil
+	case <-time.After(inputStopTimeout):
+		return errInputLoopTimeout
+	}
+}
+
+// BotDrainService waits for the in-flight bot requests during the shutdown and closes the bot
+// connections afterwards. It should be stopped after the analyzers and before the publisher
+// so that the results of the in-flight requests can be published.
+//
+// The requests which could not be drained are saved in a snapshot, so that the blocks of these
+// requests are dispatched again after the restart.
+type BotDrainService struct {
+	botProcessing components.BotProcessing
+	timeout       time.Duration
+	snapshots     store.SnapshotStore
+	// the block checkpoints of the chains
+	checkpoints map[uint64]string
+}
+
+// NewBotDrainService creates a new bot drain service. The snapshot is not saved if the snapshot
+// store is nil.
+func NewBotDrainService(
+	botProcessing components.BotProcessing, timeout time.Duration,
+	snapshots store.SnapshotStore, checkpoints map[uint64]string,
+) *BotDrainService {
+	return &BotDrainService{
+		botProcessing: botProcessing,
+		timeout:       timeout,
+		snapshots:     snapshots,
+		checkpoints:   checkpoints,
+	}
+}
+
+// Start implements the services.Service interface.
+func (bds *BotDrainService) Start() error {
+	return nil
+}
+
+// Stop drains the bot requests.
+func (bds *BotDrainService) Stop() error {
+	return bds.Drain()
+}
+
+// Drain waits for the bot requests, closes the bots and saves the snapshot of the requests which
+// could not be drained. The bots do not receive the requests anymore after the drain, so the
+// feeds should be stopped before.
+func (bds *BotDrainService) Drain() error {
+	start := time.Now()
+	drainErr := bds.botProcessing.BotPool.Drain(bds.timeout)
+	if err := bds.SaveSnapshot(); err != nil {
+		log.WithError(err).Error("failed to save the pipeline snapshot")
+	}
+	if drainErr != nil {
+		return drainErr
+	}
+	log.WithField("duration", time.Since(start)).Info("drained bot requests")
+	return nil
+}
+
+// SaveSnapshot saves the lowest block of the unfinished requests of each chain as the cursor.
+func (bds *BotDrainService) SaveSnapshot() error {
+	if bds.snapshots == nil {
+		return nil
+	}
+	snapshot := &store.PipelineSnapshot{
+		Time:    time.Now().UTC(),
+		Cursors: make(map[string]uint64),
+	}
+	for _, req := range bds.botProcessing.BotPool.PendingRequests() {
+		snapshot.InFlightRequests = append(snapshot.InFlightRequests, req.RequestID)
+		checkpoint, ok := bds.checkpoints[req.ChainID]
+		if !ok {
+			continue
+		}
+		cursor, ok := snapshot.Cursors[checkpoint]
+		if !ok || req.BlockNumber < cursor {
+			snapshot.Cursors[checkpoint] = req.BlockNumber
+		}
+	}
+	sort.Strings(snapshot.InFlightRequests)
+	if len(snapshot.InFlightRequests) > 0 {
+		log.WithFields(log.Fields{
+			"requests": len(snapshot.InFlightRequests),
+			"cursors":  snapshot.Cursors,
+		}).Warn("saving the unfinished bot requests to dispatch again after the restart")
+	}
+	return bds.snapshots.PutSnapshot(snapshot)
+}
+
+// SnapshotCursor returns the block to resume from for the checkpoint if the snapshot has
+// unfinished requests of the chain.
+func SnapshotCursor(snapshot *store.PipelineSnapshot, checkpoint string) (uint64, bool) {
+	if snapshot == nil {
+		return 0, false
+	}
+	cursor, ok := snapshot.Cursors[checkpoint]
+	return cursor, ok
+}
+
+// Name implements the services.Service interface.
+func (bds *BotDrainService) Name() string {
+	return "bot-drainer"
+}
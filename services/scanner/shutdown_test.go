@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/services/components"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotDrainService_Snapshot(t *testing.T) {
+	r := require.New(t)
+
+	botPool := mock_lifecycle.NewMockBotPool(gomock.NewController(t))
+	localStore, err := store.NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	drainer := NewBotDrainService(
+		components.BotProcessing{BotPool: botPool}, time.Second,
+		localStore, map[uint64]string{1: BlockCheckpoint, 137: ChainBlockCheckpoint(137)},
+	)
+
+	botPool.EXPECT().Drain(time.Second).Return(errors.New("timed out"))
+	botPool.EXPECT().PendingRequests().Return([]botio.PendingRequest{
+		{RequestID: "3", ChainID: 1, BlockNumber: 12},
+		{RequestID: "1", ChainID: 1, BlockNumber: 10},
+		{RequestID: "2", ChainID: 137, BlockNumber: 20},
+		{RequestID: "4", ChainID: 5, BlockNumber: 1},
+	})
+	r.Error(drainer.Stop())
+
+	snapshot, ok, err := localStore.GetSnapshot()
+	r.NoError(err)
+	r.True(ok)
+	r.Equal([]string{"1", "2", "3", "4"}, snapshot.InFlightRequests)
+
+	cursor, ok := SnapshotCursor(snapshot, BlockCheckpoint)
+	r.True(ok)
+	r.Equal(uint64(10), cursor)
+	cursor, ok = SnapshotCursor(snapshot, ChainBlockCheckpoint(137))
+	r.True(ok)
+	r.Equal(uint64(20), cursor)
+	_, ok = SnapshotCursor(snapshot, ChainBlockCheckpoint(5))
+	r.False(ok)
+	_, ok = SnapshotCursor(nil, BlockCheckpoint)
+	r.False(ok)
+}
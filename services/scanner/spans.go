@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startConvertSpan starts the span of converting an event to a bot request, in the trace of the block.
+func startConvertSpan(ctx context.Context, name string, block *domain.Block) (context.Context, trace.Span) {
+	var blockNumber, blockHash string
+	if block != nil {
+		blockNumber = block.Number
+		blockHash = block.Hash
+	}
+	return tracing.Tracer().Start(tracing.BlockContext(ctx, blockHash), name, trace.WithAttributes(
+		attribute.String("block.number", blockNumber),
+		attribute.String("block.hash", blockHash),
+	))
+}
+
+// startPublishSpan starts the span of signing and sending an alert to the publisher, in the trace
+// of the block. The combiner alerts do not have a block trace so they start new traces.
+func startPublishSpan(ctx context.Context, blockHash, botID string, alert *protocol.Alert) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(tracing.BlockContext(ctx, blockHash), tracing.SpanAlertPublish, trace.WithAttributes(
+		attribute.String("bot.id", botID),
+		attribute.String("alert.id", alert.Id),
+		attribute.String("alert.alertId", alert.GetFinding().GetAlertId()),
+	))
+}
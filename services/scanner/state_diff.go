@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-node/clients/statediff"
+	log "github.com/sirupsen/logrus"
+)
+
+// StateDiffSource provides the state diffs of the transactions.
+type StateDiffSource interface {
+	StateDiff(ctx context.Context, blockNumber uint64, txHash string) (*statediff.StateDiff, error)
+}
+
+// getStateDiff returns the state diff of the transaction or nil if it is not available. The pending
+// transactions do not have a block and a diff yet.
+func getStateDiff(ctx context.Context, source StateDiffSource, blockNumberHex, txHash string) *statediff.StateDiff {
+	if source == nil || len(blockNumberHex) == 0 || len(txHash) == 0 {
+		return nil
+	}
+	blockNumber, err := hexutil.DecodeUint64(blockNumberHex)
+	if err != nil {
+		return nil
+	}
+	diff, err := source.StateDiff(ctx, blockNumber, txHash)
+	if err != nil {
+		log.WithError(err).WithField("block", blockNumberHex).Debug("failed to get the state diff")
+		return nil
+	}
+	return diff
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/statediff"
+	"github.com/stretchr/testify/require"
+)
+
+type testStateDiffSource struct {
+	diffs map[uint64]*statediff.StateDiff
+}
+
+func (source *testStateDiffSource) StateDiff(ctx context.Context, blockNumber uint64, txHash string) (*statediff.StateDiff, error) {
+	diff, ok := source.diffs[blockNumber]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return diff, nil
+}
+
+func TestGetStateDiff(t *testing.T) {
+	r := require.New(t)
+
+	expected := &statediff.StateDiff{TxHash: "0xaa"}
+	source := &testStateDiffSource{diffs: map[uint64]*statediff.StateDiff{16: expected}}
+
+	r.Equal(expected, getStateDiff(context.Background(), source, "0x10", "0xaa"))
+	r.Nil(getStateDiff(context.Background(), source, "0x11", "0xaa"))
+	r.Nil(getStateDiff(context.Background(), source, "invalid", "0xaa"))
+	// the pending transactions do not have a block
+	r.Nil(getStateDiff(context.Background(), source, "", "0xaa"))
+	r.Nil(getStateDiff(context.Background(), nil, "0x10", "0xaa"))
+}
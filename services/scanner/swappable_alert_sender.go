@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"sync/atomic"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+)
+
+// alertSenderBox keeps the same concrete type in the atomic value.
+type alertSenderBox struct {
+	clients.AlertSender
+}
+
+// SwappableAlertSender sends the alerts with an alert sender which can be replaced
+// while the alerts are being sent, e.g. when the alert filters are reloaded.
+type SwappableAlertSender struct {
+	current atomic.Value
+}
+
+// NewSwappableAlertSender creates a new swappable alert sender.
+func NewSwappableAlertSender(alertSender clients.AlertSender) *SwappableAlertSender {
+	sas := &SwappableAlertSender{}
+	sas.Swap(alertSender)
+	return sas
+}
+
+// Swap replaces the alert sender for the next alerts.
+func (sas *SwappableAlertSender) Swap(alertSender clients.AlertSender) {
+	sas.current.Store(alertSenderBox{AlertSender: alertSender})
+}
+
+func (sas *SwappableAlertSender) get() clients.AlertSender {
+	return sas.current.Load().(alertSenderBox).AlertSender
+}
+
+// SignAlertAndNotify implements clients.AlertSender.
+func (sas *SwappableAlertSender) SignAlertAndNotify(
+	rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps,
+) error {
+	return sas.get().SignAlertAndNotify(rt, alert, chainID, blockNumber, ts)
+}
+
+// NotifyWithoutAlert implements clients.AlertSender.
+func (sas *SwappableAlertSender) NotifyWithoutAlert(rt *clients.AgentRoundTrip, ts *domain.TrackingTimestamps) error {
+	return sas.get().NotifyWithoutAlert(rt, ts)
+}
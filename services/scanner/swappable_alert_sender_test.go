@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwappableAlertSender(t *testing.T) {
+	r := require.New(t)
+
+	first := &countingAlertSender{}
+	second := &countingAlertSender{}
+	sender := NewSwappableAlertSender(first)
+
+	rt := &clients.AgentRoundTrip{AgentConfig: config.AgentConfig{ID: "0x1"}}
+	firstAlert := &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-1"}}
+	r.NoError(sender.SignAlertAndNotify(rt, firstAlert, "0x1", "0x1", nil))
+
+	sender.Swap(second)
+	secondAlert := &protocol.Alert{Finding: &protocol.Finding{AlertId: "ALERT-2"}}
+	r.NoError(sender.SignAlertAndNotify(rt, secondAlert, "0x1", "0x1", nil))
+
+	r.Equal([]*protocol.Alert{firstAlert}, first.sent)
+	r.Equal([]*protocol.Alert{secondAlert}, second.sent)
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
@@ -10,11 +11,22 @@ import (
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/components/tracing"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/beacon"
+	"github.com/forta-network/forta-node/clients/feehistory"
+	"github.com/forta-network/forta-node/clients/privateflow"
+	"github.com/forta-network/forta-node/clients/rawtx"
+	"github.com/forta-network/forta-node/clients/statediff"
+	"github.com/forta-network/forta-node/services/components/abidecoder"
+	"github.com/forta-network/forta-node/services/components/tokens"
+	"github.com/forta-network/forta-node/services/components/watchlist"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -27,12 +39,41 @@ type TxAnalyzerService struct {
 
 	lastInputActivity  health.TimeTracker
 	lastOutputActivity health.TimeTracker
+
+	processed uint64
+	inputDone chan struct{}
 }
 
 type TxAnalyzerServiceConfig struct {
-	TxChannel   <-chan *domain.TransactionEvent
-	AlertSender clients.AlertSender
-	MsgClient   clients.MessageClient
+	TxChannel        <-chan *domain.TransactionEvent
+	PendingTxChannel <-chan *domain.TransactionEvent
+	// the dropped, replaced and stuck pending transactions - nil if they are not tracked
+	MempoolStatusChannel <-chan *MempoolStatusEvent
+	ReorgDetector        *ReorgDetector
+	AlertSender          clients.AlertSender
+	MsgClient            clients.MessageClient
+	BotWarnings          *BotWarnings
+	// logs a sample of the bot responses - nil logs nothing
+	ResponseLogger *ResponseLogger
+	// the transactions of the other shards are skipped - nil processes all transactions
+	Shard *Shard
+	// decodes the function calls and the logs for the bots - nil sends them as they are
+	Decoder *abidecoder.Registry
+	// attaches the gas context of the blocks - nil sends the transactions without it
+	GasContext GasContextSource
+	// attaches the state diffs of the transactions - nil sends the transactions without them
+	StateDiff StateDiffSource
+	// attaches the raw RLP, the type and the access lists of the transactions - nil sends the transactions without them
+	RawTx RawTxSource
+	// attaches the blob sidecars of the EIP-4844 transactions, which are found with the raw transactions -
+	// nil sends the transactions without them
+	Blobs BlobSource
+	// tags the transactions with the labels of the watched addresses - nil sends them without the tags
+	Watchlists *watchlist.Watchlists
+	// annotates the findings with the token transfers - nil publishes them without
+	Tokens *tokens.Enricher
+	// caps the content of the events - nil sends them as they are
+	ContentLimits *ContentLimits
 	components.BotProcessing
 }
 
@@ -53,10 +94,6 @@ func (t *TxAnalyzerService) findingToAlert(result *botreq.TxResult, ts time.Time
 		},
 	)
 
-	blockNumber, err := utils.HexToBigInt(result.Request.Event.Block.BlockNumber)
-	if err != nil {
-		return nil, err
-	}
 	chainId, err := utils.HexToBigInt(result.Request.Event.Network.ChainId)
 	if err != nil {
 		return nil, err
@@ -66,14 +103,34 @@ func (t *TxAnalyzerService) findingToAlert(result *botreq.TxResult, ts time.Time
 		"agentImage": result.AgentConfig.Image,
 		"agentId":    result.AgentConfig.ID,
 		"chainId":    chainId.String(),
+		"requestId":  result.Request.RequestId,
+	}
+
+	isPending := agentgrpc.IsPendingTx(result.Request.Event)
+	if isPending {
+		tags["isPending"] = "true"
+	}
+
+	// the alerts about the private order flow are private, so that the transactions are not leaked
+	// before they are included
+	privateTx, _ := privateflow.FromTx(result.Request.Event)
+	if privateTx != nil {
+		tags["privateFlow"] = privateTx.Source
 	}
 
 	alertType := protocol.AlertType_PRIVATE
-	if !f.Private && !result.Response.Private {
+	if !f.Private && !result.Response.Private && privateTx == nil {
 		alertType = protocol.AlertType_TRANSACTION
 		tags["txHash"] = result.Request.Event.Transaction.Hash
-		tags["blockHash"] = result.Request.Event.Block.BlockHash
-		tags["blockNumber"] = blockNumber.String()
+		// pending txs do not have any block info yet
+		if !isPending {
+			blockNumber, err := utils.HexToBigInt(result.Request.Event.Block.BlockNumber)
+			if err != nil {
+				return nil, err
+			}
+			tags["blockHash"] = result.Request.Event.Block.BlockHash
+			tags["blockNumber"] = blockNumber.String()
+		}
 	}
 
 	addressBloomFilter, err := t.createBloomFilter(f, result.Request.Event)
@@ -112,6 +169,15 @@ func (t *TxAnalyzerService) Start() error {
 		for result := range t.cfg.BotProcessing.Results.Tx {
 			ts := time.Now().UTC()
 
+			t.cfg.ResponseLogger.Log(result.AgentConfig.ID, result.Response)
+			logAttachedBotResponse(
+				result.AgentConfig, result.Request.RequestId,
+				log.Fields{"tx": result.Request.Event.GetTransaction().GetHash(), "block": result.Request.Event.GetBlock().GetBlockNumber()},
+				result.Response.Status, result.Response.Errors, result.Response.LatencyMs, result.Response.Findings,
+			)
+
+			result.Response.Findings = filterFindings(t.cfg.MsgClient, t.cfg.BotWarnings, result.AgentConfig, result.Response.Findings)
+
 			rt := &clients.AgentRoundTrip{
 				AgentConfig:    result.AgentConfig,
 				EvalTxRequest:  result.Request,
@@ -126,18 +192,22 @@ func (t *TxAnalyzerService) Start() error {
 				}
 			}
 
-			// TODO: validate finding returned is well-formed
 			for _, f := range result.Response.Findings {
 				alert, err := t.findingToAlert(result, ts, f)
 				if err != nil {
-					log.WithError(err).Error("failed to transform finding to alert")
+					log.WithError(err).WithField("request", result.Request.RequestId).Error("failed to transform finding to alert")
 					continue
 				}
+				if t.cfg.Tokens != nil {
+					t.cfg.Tokens.Enrich(t.ctx, result.Request.Event, alert)
+				}
+				_, span := startPublishSpan(t.ctx, result.Request.Event.Block.BlockHash, result.AgentConfig.ID, alert)
 				if err := t.cfg.AlertSender.SignAlertAndNotify(
 					rt, alert, result.Request.Event.Network.ChainId, result.Request.Event.Block.BlockNumber, result.Timestamps,
 				); err != nil {
 					log.WithError(err).Panic("failed to sign alert and notify")
 				}
+				span.End()
 			}
 			t.publishMetrics(result)
 
@@ -147,14 +217,87 @@ func (t *TxAnalyzerService) Start() error {
 
 	// Gear 1: loops over transactions and distributes to all agents
 	go func() {
+		defer close(t.inputDone)
+
+		mempoolStatusCh := t.cfg.MempoolStatusChannel
 		// for each transaction
-		for tx := range t.cfg.TxChannel {
+		for {
+			var (
+				tx *domain.TransactionEvent
+				ok bool
+			)
+			// the pending tx channels are nil and never selected if the pending tx stream is disabled
+			select {
+			case <-t.ctx.Done():
+				return
+			case tx, ok = <-t.cfg.TxChannel:
+			case tx, ok = <-t.cfg.PendingTxChannel:
+			case statusEvt, statusOk := <-mempoolStatusCh:
+				if !statusOk {
+					mempoolStatusCh = nil
+				} else {
+					t.sendMempoolStatus(statusEvt)
+				}
+				continue
+			}
+			if !ok {
+				return
+			}
+			if !t.cfg.Shard.OwnsTx(tx) {
+				t.lastInputActivity.Set()
+				continue
+			}
+
 			// convert to message
-			msg, err := tx.ToMessage()
+			_, span := startConvertSpan(t.ctx, tracing.SpanTxConvert, tx.BlockEvt.Block)
+			span.SetAttributes(attribute.String("tx.hash", tx.Transaction.Hash))
+			msg, err := TxEventToMessage(tx)
 			if err != nil {
 				log.WithError(err).Error("error converting tx event to message (skipping)")
+				span.End()
+				continue
+			}
+			if !t.cfg.ContentLimits.Apply(msg) {
+				log.WithField("tx", tx.Transaction.Hash).Info("tx event exceeds the content limits - dropped event")
+				span.End()
+				t.lastInputActivity.Set()
 				continue
 			}
+			if agentgrpc.IsPendingTx(msg) {
+				if err := AttachMempoolStatus(msg, &MempoolStatus{Status: MempoolStatusPending}); err != nil {
+					log.WithError(err).Warn("failed to mark the pending tx")
+				}
+			}
+			if t.cfg.ReorgDetector != nil && t.cfg.ReorgDetector.Observe(tx.BlockEvt.Block) {
+				msg.Type = protocol.TransactionEvent_REORG
+			}
+			if t.cfg.Decoder != nil {
+				t.cfg.Decoder.Annotate(msg)
+			}
+			if t.cfg.Watchlists != nil {
+				t.cfg.Watchlists.Tag(msg)
+			}
+			if gasContext := getGasContext(t.ctx, t.cfg.GasContext, msg.GetBlock().GetBlockNumber()); gasContext != nil {
+				if err := feehistory.AttachToTx(msg, gasContext); err != nil {
+					log.WithError(err).Warn("failed to attach the gas context")
+				}
+			}
+			if rawTx := getRawTx(t.ctx, t.cfg.RawTx, msg.GetBlock().GetBlockNumber(), msg.GetTransaction().GetHash()); rawTx != nil {
+				if err := rawtx.AttachToTx(msg, rawTx); err != nil {
+					log.WithError(err).Warn("failed to attach the raw tx")
+				}
+				if sidecars := getBlobs(t.ctx, t.cfg.Blobs, msg.GetBlock().GetBlockTimestamp(), rawTx); len(sidecars) > 0 {
+					if err := beacon.AttachBlobsToTx(msg, sidecars); err != nil {
+						log.WithError(err).Warn("failed to attach the blob sidecars")
+					}
+				}
+			}
+			if diff := getStateDiff(t.ctx, t.cfg.StateDiff, msg.GetBlock().GetBlockNumber(), msg.GetTransaction().GetHash()); diff != nil {
+				if err := statediff.AttachToTx(msg, diff); err != nil {
+					log.WithError(err).Warn("failed to attach the state diff")
+				}
+			}
+			span.End()
 
 			// create a request
 			requestId := uuid.Must(uuid.NewUUID())
@@ -163,6 +306,7 @@ func (t *TxAnalyzerService) Start() error {
 			// forward to the pool
 			t.cfg.RequestSender.SendEvaluateTxRequest(request)
 
+			atomic.AddUint64(&t.processed, 1)
 			t.lastInputActivity.Set()
 		}
 	}()
@@ -170,8 +314,41 @@ func (t *TxAnalyzerService) Start() error {
 	return nil
 }
 
+// sendMempoolStatus sends the pending transaction to the bots with its mempool status.
+func (t *TxAnalyzerService) sendMempoolStatus(statusEvt *MempoolStatusEvent) {
+	if !t.cfg.Shard.OwnsTx(statusEvt.Event) {
+		return
+	}
+	msg, err := TxEventToMessage(statusEvt.Event)
+	if err != nil {
+		log.WithError(err).Error("error converting mempool status event to message (skipping)")
+		return
+	}
+	if !t.cfg.ContentLimits.Apply(msg) {
+		return
+	}
+	if t.cfg.Watchlists != nil {
+		t.cfg.Watchlists.Tag(msg)
+	}
+	if err := AttachMempoolStatus(msg, statusEvt.Status); err != nil {
+		log.WithError(err).Error("failed to attach mempool status (skipping)")
+		return
+	}
+	t.cfg.RequestSender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{
+		RequestId: uuid.Must(uuid.NewUUID()).String(),
+		Event:     msg,
+	})
+	t.lastInputActivity.Set()
+}
+
+// ProcessedCount returns the amount of transactions sent to the bots so far.
+func (t *TxAnalyzerService) ProcessedCount() uint64 {
+	return atomic.LoadUint64(&t.processed)
+}
+
+// Stop waits for the input loop to stop consuming new events.
 func (t *TxAnalyzerService) Stop() error {
-	return nil
+	return waitInputLoop(t.inputDone)
 }
 
 func (t *TxAnalyzerService) Name() string {
@@ -188,7 +365,8 @@ func (t *TxAnalyzerService) Health() health.Reports {
 
 func NewTxAnalyzerService(ctx context.Context, cfg TxAnalyzerServiceConfig) (*TxAnalyzerService, error) {
 	return &TxAnalyzerService{
-		cfg: cfg,
-		ctx: ctx,
+		cfg:       cfg,
+		ctx:       ctx,
+		inputDone: make(chan struct{}),
 	}, nil
 }
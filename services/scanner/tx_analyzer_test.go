@@ -2,12 +2,19 @@ package scanner
 
 import (
 	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTxAnalyzerService_createBloomFilter(t *testing.T) {
@@ -63,3 +70,32 @@ func TestTxAnalyzerService_createBloomFilter(t *testing.T) {
 		)
 	}
 }
+
+func TestTxAnalyzerService_findingToAlertPendingTx(t *testing.T) {
+	r := require.New(t)
+
+	pendingStream := &PendingTxStreamService{cfg: PendingTxStreamServiceConfig{ChainID: big.NewInt(1)}}
+	to := "0xbbb"
+	evt, err := pendingStream.makePendingTxEvent(&domain.Transaction{
+		Hash:  "0xabc",
+		From:  "0xaaa",
+		To:    &to,
+		Nonce: "0x1",
+	}).ToMessage()
+	r.NoError(err)
+	r.True(agentgrpc.IsPendingTx(evt))
+
+	txAnalyzer := &TxAnalyzerService{}
+	alert, err := txAnalyzer.findingToAlert(&botreq.TxResult{
+		AgentConfig: config.AgentConfig{ID: "0x1"},
+		Request:     &protocol.EvaluateTxRequest{RequestId: "1", Event: evt},
+		Response:    &protocol.EvaluateTxResponse{},
+		Timestamps:  &domain.TrackingTimestamps{},
+	}, time.Now(), &protocol.Finding{AlertId: "ALERT-1"})
+	r.NoError(err)
+	r.Equal(protocol.AlertType_TRANSACTION, alert.Type)
+	r.Equal("true", alert.Tags["isPending"])
+	r.Equal("0xabc", alert.Tags["txHash"])
+	r.Empty(alert.Tags["blockNumber"])
+	r.Equal("1", alert.Tags["requestId"])
+}
@@ -2,17 +2,33 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"time"
 
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/ethereum"
 	"github.com/forta-network/forta-core-go/feeds"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/coverage"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// maxRedispatchedBlocks is the max amount of the canonical blocks which are dispatched again after a reorg.
+const maxRedispatchedBlocks = 64
+
+// EventStream provides the block and the transaction events to the analyzers.
+type EventStream interface {
+	ReadOnlyBlockStream() <-chan *domain.BlockEvent
+	ReadOnlyTxStream() <-chan *domain.TransactionEvent
+}
+
 // TxStreamService pulls TX info from providers and emits to channel
 type TxStreamService struct {
 	cfg         TxStreamServiceConfig
@@ -20,6 +36,7 @@ type TxStreamService struct {
 	blockOutput chan *domain.BlockEvent
 	txOutput    chan *domain.TransactionEvent
 	txFeed      feeds.TransactionFeed
+	ethClient   ethereum.Client
 
 	lastBlockActivity health.TimeTracker
 	lastTxActivity    health.TimeTracker
@@ -29,6 +46,18 @@ type TxStreamServiceConfig struct {
 	JsonRpcConfig       config.JsonRpcConfig
 	TraceJsonRpcConfig  config.JsonRpcConfig
 	SkipBlocksOlderThan *time.Duration
+	FetchReceipts       bool
+	// caches the gas context of each block for the analyzers - nil does not fetch it
+	GasContext GasContextSource
+	// skips the blocks which were already dispatched - nil dispatches all blocks
+	DuplicateBlocks *DuplicateBlocks
+	// counts the blocks from the feed - nil counts nothing
+	Coverage *coverage.Tracker
+	// tracks the dispatched blocks to dispatch the canonical blocks which replace them after a reorg
+	// - nil does not dispatch the replaced blocks again
+	Reorgs *ReorgDetector
+	// traces the canonical blocks which are dispatched again - nil if the tracing is disabled
+	TraceClient ethereum.Client
 }
 
 func (t *TxStreamService) ReadOnlyBlockStream() <-chan *domain.BlockEvent {
@@ -45,9 +74,124 @@ func (t *TxStreamService) handleBlock(evt *domain.BlockEvent) error {
 		return nil
 	default:
 	}
+	if t.cfg.Coverage != nil && evt.ChainID != nil {
+		t.cfg.Coverage.Seen(evt.ChainID.Uint64())
+	}
+	if t.cfg.Reorgs != nil && evt.Block != nil {
+		t.redispatchCanonicalBlocks(evt)
+		t.cfg.Reorgs.Observe(evt.Block)
+	}
+	t.dispatchBlock(evt)
+	return nil
+}
+
+func (t *TxStreamService) dispatchBlock(evt *domain.BlockEvent) {
+	if t.cfg.DuplicateBlocks != nil && !t.cfg.DuplicateBlocks.ShouldDispatch(evt, time.Now()) {
+		return
+	}
+	if t.cfg.GasContext != nil && evt.Block != nil {
+		// the transactions of the block are analyzed after the block so they find it in the cache
+		_ = getGasContext(t.ctx, t.cfg.GasContext, evt.Block.Number)
+	}
 	t.blockOutput <- evt
 	t.lastBlockActivity.Set()
-	return nil
+}
+
+// redispatchCanonicalBlocks finds the ancestors of the block which replace the dispatched blocks and
+// dispatches them with their transactions before the block, so that the bots receive the canonical
+// chain after a reorg. The feed does not deliver them again, since it moves only forward.
+func (t *TxStreamService) redispatchCanonicalBlocks(evt *domain.BlockEvent) {
+	number, err := hexutil.DecodeUint64(evt.Block.Number)
+	if err != nil {
+		return
+	}
+	var ancestors []*domain.Block
+	parentHash := evt.Block.ParentHash
+	for len(ancestors) < maxRedispatchedBlocks && number > 0 && len(parentHash) > 0 {
+		number--
+		if !t.cfg.Reorgs.IsReplaced(number, parentHash) {
+			break
+		}
+		block, err := t.ethClient.BlockByHash(t.ctx, parentHash)
+		if err != nil {
+			log.WithError(err).WithField("blockHash", parentHash).Warn("failed to get the canonical block after reorg")
+			break
+		}
+		ancestors = append(ancestors, block)
+		parentHash = block.ParentHash
+	}
+
+	// the oldest first
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		blockEvt, err := t.makeBlockEvent(evt.ChainID, ancestors[i])
+		if err != nil {
+			log.WithError(err).WithField("blockHash", ancestors[i].Hash).Warn("failed to dispatch the canonical block after reorg")
+			return
+		}
+		log.WithFields(log.Fields{
+			"blockNumber": blockEvt.Block.Number,
+			"blockHash":   blockEvt.Block.Hash,
+		}).Info("dispatching the canonical block after reorg")
+		t.cfg.Reorgs.Observe(blockEvt.Block)
+		t.dispatchBlock(blockEvt)
+		for _, tx := range blockEvt.Block.Transactions {
+			tx := tx
+			_ = t.handleTx(&domain.TransactionEvent{
+				BlockEvt:    blockEvt,
+				Transaction: &tx,
+				Timestamps: &domain.TrackingTimestamps{
+					Block: blockEvt.Timestamps.Block,
+					Feed:  time.Now().UTC(),
+				},
+			})
+		}
+	}
+}
+
+// makeBlockEvent creates the block event like the block feed does.
+func (t *TxStreamService) makeBlockEvent(chainID *big.Int, block *domain.Block) (*domain.BlockEvent, error) {
+	blockTs, err := block.GetTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block timestamp: %v", err)
+	}
+	blockHash := common.HexToHash(block.Hash)
+	logs, err := t.ethClient.GetLogs(t.ctx, eth.FilterQuery{BlockHash: &blockHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %v", err)
+	}
+	var logEntries []domain.LogEntry
+	b, err := json.Marshal(logs)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &logEntries); err != nil {
+		return nil, err
+	}
+
+	var traces []domain.Trace
+	if t.cfg.TraceClient != nil {
+		number, _ := hexutil.DecodeBig(block.Number)
+		traces, err = t.cfg.TraceClient.TraceBlock(t.ctx, number)
+		if err != nil {
+			log.WithError(err).WithField("blockHash", block.Hash).Warn("failed to trace the canonical block")
+		}
+		// the trace api may return the traces of another block at the same height
+		if len(traces) > 0 && (traces[0].BlockHash == nil || *traces[0].BlockHash != block.Hash) {
+			traces = nil
+		}
+	}
+
+	return &domain.BlockEvent{
+		EventType: domain.EventTypeBlock,
+		ChainID:   chainID,
+		Block:     block,
+		Logs:      logEntries,
+		Traces:    traces,
+		Timestamps: &domain.TrackingTimestamps{
+			Block: *blockTs,
+			Feed:  time.Now().UTC(),
+		},
+	}, nil
 }
 
 func (t *TxStreamService) handleTx(evt *domain.TransactionEvent) error {
@@ -56,11 +200,31 @@ func (t *TxStreamService) handleTx(evt *domain.TransactionEvent) error {
 		return nil
 	default:
 	}
+	if t.cfg.DuplicateBlocks != nil && !t.cfg.DuplicateBlocks.ShouldDispatchTx(evt) {
+		return nil
+	}
+	if t.cfg.FetchReceipts {
+		t.fetchReceipt(evt)
+	}
 	t.txOutput <- evt
 	t.lastTxActivity.Set()
 	return nil
 }
 
+// fetchReceipt sets the receipt of the transaction. The transaction is still sent
+// to the bots without the receipt if the receipt is not available.
+func (t *TxStreamService) fetchReceipt(evt *domain.TransactionEvent) {
+	if evt.Transaction == nil || evt.Receipt != nil {
+		return
+	}
+	receipt, err := t.ethClient.TransactionReceipt(t.ctx, evt.Transaction.Hash)
+	if err != nil {
+		log.WithError(err).WithField("tx", evt.Transaction.Hash).Warn("failed to get transaction receipt")
+		return
+	}
+	evt.Receipt = receipt
+}
+
 func (t *TxStreamService) Start() error {
 	go func() {
 		if err := t.txFeed.ForEachTransaction(t.handleBlock, t.handleTx); err != nil {
@@ -133,5 +297,6 @@ func NewTxStreamService(ctx context.Context, ethClient ethereum.Client, blockFee
 		blockOutput: blockOutput,
 		txOutput:    txOutput,
 		txFeed:      txFeed,
+		ethClient:   ethClient,
 	}, nil
 }
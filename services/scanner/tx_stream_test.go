@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/services/scanner/fakechain"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxStream_FakeChain(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the tx stream subscribes to the chain asynchronously, so the chain should not end before
+	chain := fakechain.New(ctx, fakechain.Config{StartBlock: 1, BlockInterval: 10 * time.Millisecond, TxsPerBlock: 4})
+	txStream, err := NewTxStreamService(ctx, nil, chain, TxStreamServiceConfig{})
+	r.NoError(err)
+	r.NoError(txStream.Start())
+	chain.Start()
+
+	var blocks, txs int
+	for blocks < 3 || txs < 12 {
+		select {
+		case evt := <-txStream.ReadOnlyBlockStream():
+			r.Len(evt.Block.Transactions, 4)
+			blocks++
+		case evt := <-txStream.ReadOnlyTxStream():
+			r.Equal(evt.BlockEvt.Block.Hash, evt.Transaction.BlockHash)
+			txs++
+		}
+	}
+}
+
+func TestTxStream_RedispatchCanonicalBlocks(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+
+	txStream := &TxStreamService{
+		ctx:         ctx,
+		cfg:         TxStreamServiceConfig{Reorgs: NewReorgDetector(0)},
+		ethClient:   ethClient,
+		blockOutput: make(chan *domain.BlockEvent, 10),
+		txOutput:    make(chan *domain.TransactionEvent, 10),
+	}
+
+	makeBlockEvt := func(block *domain.Block) *domain.BlockEvent {
+		block.Timestamp = "0x1"
+		return &domain.BlockEvent{EventType: domain.EventTypeBlock, ChainID: big.NewInt(1), Block: block}
+	}
+	r.NoError(txStream.handleBlock(makeBlockEvt(&domain.Block{Number: "0x1", Hash: "0xa1"})))
+	r.NoError(txStream.handleBlock(makeBlockEvt(&domain.Block{Number: "0x2", Hash: "0xa2", ParentHash: "0xa1"})))
+	r.NoError(txStream.handleBlock(makeBlockEvt(&domain.Block{Number: "0x3", Hash: "0xa3", ParentHash: "0xa2"})))
+	for i := 0; i < 3; i++ {
+		<-txStream.blockOutput
+	}
+
+	// the new block replaces the blocks 2 and 3 and the feed does not deliver the replacements
+	canonical2 := &domain.Block{
+		Number: "0x2", Hash: "0xb2", ParentHash: "0xa1", Timestamp: "0x2",
+		Transactions: []domain.Transaction{{Hash: "0xt2", BlockHash: "0xb2"}},
+	}
+	canonical3 := &domain.Block{Number: "0x3", Hash: "0xb3", ParentHash: "0xb2", Timestamp: "0x3"}
+	ethClient.EXPECT().BlockByHash(gomock.Any(), "0xb3").Return(canonical3, nil)
+	ethClient.EXPECT().BlockByHash(gomock.Any(), "0xb2").Return(canonical2, nil)
+	ethClient.EXPECT().GetLogs(gomock.Any(), gomock.Any()).Return([]types.Log{{Index: 1}}, nil).Times(2)
+
+	r.NoError(txStream.handleBlock(makeBlockEvt(&domain.Block{Number: "0x4", Hash: "0xb4", ParentHash: "0xb3"})))
+
+	var hashes []string
+	for i := 0; i < 3; i++ {
+		evt := <-txStream.blockOutput
+		hashes = append(hashes, evt.Block.Hash)
+		if evt.Block.Hash != "0xb4" {
+			r.Len(evt.Logs, 1)
+			r.NotNil(evt.Timestamps)
+		}
+	}
+	r.Equal([]string{"0xb2", "0xb3", "0xb4"}, hashes)
+
+	txEvt := <-txStream.txOutput
+	r.Equal("0xt2", txEvt.Transaction.Hash)
+	r.Equal("0xb2", txEvt.BlockEvt.Block.Hash)
+
+	// the next block follows the canonical chain
+	r.NoError(txStream.handleBlock(makeBlockEvt(&domain.Block{Number: "0x5", Hash: "0xb5", ParentHash: "0xb4"})))
+	r.Equal("0xb5", (<-txStream.blockOutput).Block.Hash)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,6 +22,7 @@ const (
 
 const (
 	GracefulShutdownSignal = syscall.SIGTERM
+	ReloadSignal           = syscall.SIGHUP
 
 	ExitCodeTriggered = 77
 )
@@ -114,14 +116,44 @@ func InitMainContext() (context.Context, context.CancelFunc) {
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
 	go func() {
-		sig := <-sigc
-		log.Infof("received signal: %s", sig.String())
-		gracefulShutdown = sig == GracefulShutdownSignal
-		cancel()
+		for {
+			sig := <-sigc
+			log.Infof("received signal: %s", sig.String())
+			if sig == ReloadSignal {
+				go TriggerReload()
+				continue
+			}
+			gracefulShutdown = sig == GracefulShutdownSignal
+			cancel()
+			return
+		}
 	}()
 	return ctx, cancel
 }
 
+var (
+	reloadHook func()
+	reloadMu   sync.Mutex
+)
+
+// OnReload sets the function which reloads the config on the reload signal.
+func OnReload(reload func()) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHook = reload
+}
+
+// TriggerReload reloads the config with the function set by OnReload.
+func TriggerReload() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if reloadHook == nil {
+		log.Warn("config reload is not supported - ignoring")
+		return
+	}
+	reloadHook()
+}
+
 // InterruptMainContext interrupts by sending a fake interrup signal from within runtime.
 func InterruptMainContext() {
 	select {
@@ -0,0 +1,414 @@
+package statusapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/publisher"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultAgentLogTail = 100
+
+// Agent states
+const (
+	AgentStateInitializing = "initializing"
+	AgentStateReady        = "ready"
+	AgentStateUnhealthy    = "unhealthy"
+	AgentStateDegraded     = "degraded"
+	AgentStateDisabled     = "disabled"
+	AgentStateClosed       = "closed"
+)
+
+// BlockSource knows the last block which the node processed on a chain.
+type BlockSource interface {
+	LastBlock() (number uint64, timestamp time.Time)
+}
+
+// PublishSource knows the publish counts of the node.
+type PublishSource interface {
+	PublishStats() publisher.PublishStats
+}
+
+// StatusAPI serves the health, the status and the agent statistics of the node over HTTP.
+type StatusAPI struct {
+	ctx       context.Context
+	cfg       StatusAPIConfig
+	server    *http.Server
+	startedAt time.Time
+
+	agentStats map[string]*AgentStats
+	mu         sync.RWMutex
+}
+
+// StatusAPIConfig contains the status API configuration and the status sources.
+type StatusAPIConfig struct {
+	Port      string
+	MsgClient clients.MessageClient
+
+	// Health returns the health reports of the node components.
+	Health  health.HealthChecker
+	BotPool botio.BotPool
+	// Chains are the block sources by chain id.
+	Chains  map[int]BlockSource
+	Publish PublishSource
+	// AgentLogs contains the captured bot logs.
+	AgentLogs store.AgentLogStore
+	// Token is required as a bearer token to read the bot logs, since they may contain secrets.
+	Token string
+}
+
+// HealthResponse is the response of the health endpoints.
+type HealthResponse struct {
+	Live    bool           `json:"live"`
+	Ready   bool           `json:"ready"`
+	Summary *health.Report `json:"summary,omitempty"`
+	// Failing contains the names of the failing components.
+	Failing []string `json:"failing,omitempty"`
+}
+
+// StatusResponse is the response of the status endpoint.
+type StatusResponse struct {
+	StartedAt time.Time               `json:"startedAt"`
+	Chains    []*ChainStatus          `json:"chains"`
+	Agents    []*AgentStatus          `json:"agents"`
+	Publish   *publisher.PublishStats `json:"publish,omitempty"`
+}
+
+// ChainStatus contains the last processed block of a chain.
+type ChainStatus struct {
+	ChainID        int       `json:"chainId"`
+	BlockNumber    uint64    `json:"blockNumber"`
+	BlockTimestamp time.Time `json:"blockTimestamp,omitempty"`
+	// LagSeconds is the age of the last processed block.
+	LagSeconds float64 `json:"lagSeconds"`
+}
+
+// AgentStatus contains the state of a bot client.
+type AgentStatus struct {
+	ID      string `json:"id"`
+	Image   string `json:"image"`
+	ShardID int32  `json:"shardId"`
+	State   string `json:"state"`
+	// Performance is the rolling performance score of the bot if the scoring is enabled.
+	Performance *botio.PerformanceScore `json:"performance,omitempty"`
+}
+
+// AgentLogsResponse contains the last captured log lines of a bot.
+type AgentLogsResponse struct {
+	ID    string   `json:"id"`
+	Lines []string `json:"lines"`
+}
+
+// ErrorResponse is the response of the failed requests.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// AgentStats contains the evaluation statistics of a bot since the start of the node.
+type AgentStats struct {
+	ID             string  `json:"id"`
+	Requests       uint64  `json:"requests"`
+	Errors         uint64  `json:"errors"`
+	Drops          uint64  `json:"drops"`
+	Findings       uint64  `json:"findings"`
+	ErrorRate      float64 `json:"errorRate"`
+	AvgLatencyMs   float64 `json:"avgLatencyMs"`
+	MaxLatencyMs   float64 `json:"maxLatencyMs"`
+	LastLatencyMs  float64 `json:"lastLatencyMs"`
+	LatencySamples uint64  `json:"latencySamples"`
+
+	totalLatencyMs float64
+}
+
+// NewStatusAPI creates a new status API.
+func NewStatusAPI(ctx context.Context, cfg StatusAPIConfig) (*StatusAPI, error) {
+	if len(cfg.Port) == 0 {
+		return nil, fmt.Errorf("status api port is required")
+	}
+	if cfg.AgentLogs != nil && len(cfg.Token) == 0 {
+		return nil, fmt.Errorf("status api token is required to serve the agent logs")
+	}
+	return &StatusAPI{
+		ctx:        ctx,
+		cfg:        cfg,
+		startedAt:  time.Now().UTC(),
+		agentStats: make(map[string]*AgentStats),
+	}, nil
+}
+
+// Start subscribes to the bot metrics and starts the status server.
+func (api *StatusAPI) Start() error {
+	api.cfg.MsgClient.Subscribe(messaging.SubjectMetricAgent, messaging.AgentMetricHandler(api.handleMetrics))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", api.handleLiveness)
+	mux.HandleFunc("/health/live", api.handleLiveness)
+	mux.HandleFunc("/health/ready", api.handleReadiness)
+	mux.HandleFunc("/status", api.handleStatus)
+	mux.HandleFunc("/agents", api.handleAgents)
+	mux.HandleFunc("/agents/logs", api.handleAgentLogs)
+	api.server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", api.cfg.Port),
+		Handler: mux,
+	}
+	utils.GoListenAndServe(api.server)
+	return nil
+}
+
+// Stop stops the status server.
+func (api *StatusAPI) Stop() error {
+	if api.server != nil {
+		return api.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (api *StatusAPI) Name() string {
+	return "status-api"
+}
+
+// handleLiveness responds as long as the node is serving. The readiness is included for convenience.
+func (api *StatusAPI) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, api.checkHealth())
+}
+
+// handleReadiness responds with an error status if any of the components is failing.
+func (api *StatusAPI) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	resp := api.checkHealth()
+	code := http.StatusOK
+	if !resp.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, resp)
+}
+
+func (api *StatusAPI) checkHealth() *HealthResponse {
+	resp := &HealthResponse{Live: true, Ready: true}
+	if api.cfg.Health == nil {
+		return resp
+	}
+	for _, report := range api.cfg.Health() {
+		if report.Name == "summary" {
+			resp.Summary = report
+		}
+		if report.Status == health.StatusFailing || report.Status == health.StatusDown {
+			resp.Ready = false
+			resp.Failing = append(resp.Failing, report.Name)
+		}
+	}
+	return resp
+}
+
+func (api *StatusAPI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	resp := &StatusResponse{
+		StartedAt: api.startedAt,
+		Chains:    api.chainStatuses(),
+		Agents:    api.agentStatuses(),
+	}
+	if api.cfg.Publish != nil {
+		stats := api.cfg.Publish.PublishStats()
+		resp.Publish = &stats
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (api *StatusAPI) handleAgents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, api.AgentStats())
+}
+
+// handleAgentLogs responds with the last captured log lines of the bot with the id or the id prefix.
+func (api *StatusAPI) handleAgentLogs(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(api.cfg.Token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(api.cfg.Token)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, &ErrorResponse{Error: "unauthorized"})
+		return
+	}
+	if api.cfg.AgentLogs == nil {
+		writeJSON(w, http.StatusNotFound, &ErrorResponse{Error: "agent log capture is disabled"})
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if len(id) == 0 {
+		botIDs, err := api.cfg.AgentLogs.List()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, &ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, botIDs)
+		return
+	}
+	tail := defaultAgentLogTail
+	if tailStr := r.URL.Query().Get("tail"); len(tailStr) > 0 {
+		var err error
+		tail, err = strconv.Atoi(tailStr)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid tail value"})
+			return
+		}
+	}
+
+	botID, err := store.FindAgentLogs(api.cfg.AgentLogs, id)
+	if err != nil {
+		writeAgentLogsError(w, err)
+		return
+	}
+	lines, err := api.cfg.AgentLogs.Tail(botID, tail)
+	if err != nil {
+		writeAgentLogsError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &AgentLogsResponse{ID: botID, Lines: lines})
+}
+
+func writeAgentLogsError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if err == store.ErrAgentLogsNotFound {
+		code = http.StatusNotFound
+	}
+	writeJSON(w, code, &ErrorResponse{Error: err.Error()})
+}
+
+func (api *StatusAPI) chainStatuses() (statuses []*ChainStatus) {
+	for chainID, source := range api.cfg.Chains {
+		number, timestamp := source.LastBlock()
+		status := &ChainStatus{
+			ChainID:        chainID,
+			BlockNumber:    number,
+			BlockTimestamp: timestamp,
+		}
+		if !timestamp.IsZero() {
+			status.LagSeconds = time.Since(timestamp).Seconds()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ChainID < statuses[j].ChainID
+	})
+	return
+}
+
+func (api *StatusAPI) agentStatuses() (statuses []*AgentStatus) {
+	if api.cfg.BotPool == nil {
+		return
+	}
+	for _, bot := range api.cfg.BotPool.GetCurrentBotClients() {
+		botConfig := bot.Config()
+		status := &AgentStatus{
+			ID:      botConfig.ID,
+			Image:   botConfig.Image,
+			ShardID: botConfig.ShardID(),
+			State:   agentState(bot),
+		}
+		if score, ok := bot.PerformanceScore(); ok {
+			status.Performance = &score
+		}
+		statuses = append(statuses, status)
+	}
+	return
+}
+
+func agentState(bot botio.BotClient) string {
+	switch {
+	case bot.IsClosed():
+		return AgentStateClosed
+	case bot.IsDegraded():
+		return AgentStateDegraded
+	case !bot.IsInitialized():
+		return AgentStateInitializing
+	case bot.IsDisabled():
+		return AgentStateDisabled
+	case !bot.IsReady():
+		return AgentStateUnhealthy
+	default:
+		return AgentStateReady
+	}
+}
+
+// AgentStats returns the evaluation statistics of the bots ordered by the bot IDs.
+func (api *StatusAPI) AgentStats() []*AgentStats {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	stats := make([]*AgentStats, 0, len(api.agentStats))
+	for _, botStats := range api.agentStats {
+		copied := *botStats
+		if copied.Requests > 0 {
+			copied.ErrorRate = float64(copied.Errors) / float64(copied.Requests)
+		}
+		if copied.LatencySamples > 0 {
+			copied.AvgLatencyMs = copied.totalLatencyMs / float64(copied.LatencySamples)
+		}
+		stats = append(stats, &copied)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ID < stats[j].ID
+	})
+	return stats
+}
+
+func (api *StatusAPI) handleMetrics(list *protocol.AgentMetricList) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	for _, metric := range list.Metrics {
+		api.observe(metric)
+	}
+	return nil
+}
+
+// observe counts the evaluation metrics of the bots and ignores the rest.
+func (api *StatusAPI) observe(metric *protocol.AgentMetric) {
+	botID := strings.ToLower(metric.AgentId)
+	getStats := func() *AgentStats {
+		botStats, ok := api.agentStats[botID]
+		if !ok {
+			botStats = &AgentStats{ID: botID}
+			api.agentStats[botID] = botStats
+		}
+		return botStats
+	}
+	switch metric.Name {
+	case metrics.MetricTxRequest, metrics.MetricBlockRequest, metrics.MetricCombinerRequest:
+		getStats().Requests += uint64(metric.Value)
+	case metrics.MetricTxError, metrics.MetricBlockError, metrics.MetricCombinerError:
+		getStats().Errors += uint64(metric.Value)
+	case metrics.MetricTxDrop, metrics.MetricBlockDrop, metrics.MetricCombinerDrop:
+		getStats().Drops += uint64(metric.Value)
+	case metrics.MetricFinding:
+		getStats().Findings += uint64(metric.Value)
+	case metrics.MetricTxLatency, metrics.MetricBlockLatency, metrics.MetricCombinerLatency:
+		botStats := getStats()
+		botStats.LatencySamples++
+		botStats.totalLatencyMs += metric.Value
+		botStats.LastLatencyMs = metric.Value
+		if metric.Value > botStats.MaxLatencyMs {
+			botStats.MaxLatencyMs = metric.Value
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Warn("failed to write status api response")
+	}
+}
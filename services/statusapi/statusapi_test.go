@@ -0,0 +1,219 @@
+package statusapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/publisher"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBotID = "0x1"
+	testToken = "test-token"
+)
+
+type testBlockSource struct {
+	number    uint64
+	timestamp time.Time
+}
+
+func (bs *testBlockSource) LastBlock() (uint64, time.Time) {
+	return bs.number, bs.timestamp
+}
+
+type testPublishSource publisher.PublishStats
+
+func (ps testPublishSource) PublishStats() publisher.PublishStats {
+	return publisher.PublishStats(ps)
+}
+
+func TestStatusAPI_Health(t *testing.T) {
+	r := require.New(t)
+
+	var reports health.Reports
+	api, err := NewStatusAPI(context.Background(), StatusAPIConfig{
+		Port: "9108",
+		Health: func() health.Reports {
+			return reports
+		},
+	})
+	r.NoError(err)
+
+	reports = health.Reports{
+		{Name: "summary", Status: health.StatusOK},
+		{Name: "service.json-rpc-proxy", Status: health.StatusOK},
+	}
+	rec := httptest.NewRecorder()
+	api.handleReadiness(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	r.Equal(http.StatusOK, rec.Code)
+
+	reports = health.Reports{
+		{Name: "summary", Status: health.StatusFailing},
+		{Name: "service.json-rpc-proxy", Status: health.StatusDown},
+	}
+	rec = httptest.NewRecorder()
+	api.handleReadiness(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	r.Equal(http.StatusServiceUnavailable, rec.Code)
+
+	var resp HealthResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.True(resp.Live)
+	r.False(resp.Ready)
+	r.Equal([]string{"summary", "service.json-rpc-proxy"}, resp.Failing)
+
+	// the node is still alive
+	rec = httptest.NewRecorder()
+	api.handleLiveness(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	r.Equal(http.StatusOK, rec.Code)
+}
+
+func TestStatusAPI_Status(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	bot := mock_botio.NewMockBotClient(ctrl)
+	bot.EXPECT().Config().Return(config.AgentConfig{ID: testBotID, Image: "bot-image"}).AnyTimes()
+	bot.EXPECT().IsClosed().Return(false)
+	bot.EXPECT().IsDegraded().Return(false)
+	bot.EXPECT().IsInitialized().Return(true)
+	bot.EXPECT().IsDisabled().Return(false)
+	bot.EXPECT().IsReady().Return(true)
+	bot.EXPECT().PerformanceScore().Return(botio.PerformanceScore{Score: 0.9, Requests: 100}, true)
+	botPool := mock_botio.NewMockBotPool(ctrl)
+	botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{bot})
+
+	blockTime := time.Now().Add(-time.Minute)
+	api, err := NewStatusAPI(context.Background(), StatusAPIConfig{
+		Port:    "9108",
+		BotPool: botPool,
+		Chains: map[int]BlockSource{
+			137: &testBlockSource{},
+			1:   &testBlockSource{number: 100, timestamp: blockTime},
+		},
+		Publish: testPublishSource{PublishedBatches: 2, PublishedAlerts: 5},
+	})
+	r.NoError(err)
+
+	rec := httptest.NewRecorder()
+	api.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	r.Equal(http.StatusOK, rec.Code)
+
+	var resp StatusResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.Len(resp.Chains, 2)
+	r.Equal(1, resp.Chains[0].ChainID)
+	r.Equal(uint64(100), resp.Chains[0].BlockNumber)
+	r.GreaterOrEqual(resp.Chains[0].LagSeconds, float64(60))
+	r.Equal(137, resp.Chains[1].ChainID)
+	r.Zero(resp.Chains[1].LagSeconds)
+
+	r.Len(resp.Agents, 1)
+	r.Equal(testBotID, resp.Agents[0].ID)
+	r.Equal(AgentStateReady, resp.Agents[0].State)
+	r.Equal(0.9, resp.Agents[0].Performance.Score)
+
+	r.NotNil(resp.Publish)
+	r.Equal(uint64(2), resp.Publish.PublishedBatches)
+	r.Equal(uint64(5), resp.Publish.PublishedAlerts)
+}
+
+func TestStatusAPI_AgentStats(t *testing.T) {
+	r := require.New(t)
+
+	api, err := NewStatusAPI(context.Background(), StatusAPIConfig{Port: "9108"})
+	r.NoError(err)
+
+	r.NoError(api.handleMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{
+			{AgentId: testBotID, Name: metrics.MetricTxRequest, Value: 1},
+			{AgentId: testBotID, Name: metrics.MetricBlockRequest, Value: 1},
+			{AgentId: testBotID, Name: metrics.MetricTxError, Value: 1},
+			{AgentId: testBotID, Name: metrics.MetricTxLatency, Value: 100},
+			{AgentId: testBotID, Name: metrics.MetricBlockLatency, Value: 300},
+			{AgentId: testBotID, Name: metrics.MetricFinding, Value: 2},
+			{AgentId: "0x2", Name: metrics.MetricStatusRunning, Value: 1},
+		},
+	}))
+
+	rec := httptest.NewRecorder()
+	api.handleAgents(rec, httptest.NewRequest(http.MethodGet, "/agents", nil))
+	r.Equal(http.StatusOK, rec.Code)
+
+	var stats []*AgentStats
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &stats))
+	r.Len(stats, 1)
+	r.Equal(testBotID, stats[0].ID)
+	r.Equal(uint64(2), stats[0].Requests)
+	r.Equal(uint64(1), stats[0].Errors)
+	r.Equal(uint64(2), stats[0].Findings)
+	r.Equal(0.5, stats[0].ErrorRate)
+	r.Equal(float64(200), stats[0].AvgLatencyMs)
+	r.Equal(float64(300), stats[0].MaxLatencyMs)
+	r.Equal(uint64(2), stats[0].LatencySamples)
+}
+
+func TestStatusAPI_AgentLogs(t *testing.T) {
+	r := require.New(t)
+
+	agentLogs := store.NewAgentLogStore(t.TempDir(), 0, 0)
+	r.NoError(agentLogs.Append(testBotID, []byte("line 1\nline 2\nline 3\n")))
+	_, err := NewStatusAPI(context.Background(), StatusAPIConfig{
+		Port:      "9108",
+		AgentLogs: agentLogs,
+	})
+	r.Error(err)
+	api, err := NewStatusAPI(context.Background(), StatusAPIConfig{
+		Port:      "9108",
+		AgentLogs: agentLogs,
+		Token:     testToken,
+	})
+	r.NoError(err)
+
+	// the logs are served only with the token
+	rec := httptest.NewRecorder()
+	api.handleAgentLogs(rec, httptest.NewRequest(http.MethodGet, "/agents/logs?id=0x&tail=2", nil))
+	r.Equal(http.StatusUnauthorized, rec.Code)
+	req := httptest.NewRequest(http.MethodGet, "/agents/logs?id=0x&tail=2", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	api.handleAgentLogs(rec, req)
+	r.Equal(http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	api.handleAgentLogs(rec, newAgentLogsRequest("/agents/logs?id=0x&tail=2"))
+	r.Equal(http.StatusOK, rec.Code)
+	var resp AgentLogsResponse
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	r.Equal(testBotID, resp.ID)
+	r.Equal([]string{"line 2", "line 3"}, resp.Lines)
+
+	rec = httptest.NewRecorder()
+	api.handleAgentLogs(rec, newAgentLogsRequest("/agents/logs"))
+	r.Equal(http.StatusOK, rec.Code)
+	var botIDs []string
+	r.NoError(json.Unmarshal(rec.Body.Bytes(), &botIDs))
+	r.Equal([]string{testBotID}, botIDs)
+
+	rec = httptest.NewRecorder()
+	api.handleAgentLogs(rec, newAgentLogsRequest("/agents/logs?id=0x2"))
+	r.Equal(http.StatusNotFound, rec.Code)
+}
+
+func newAgentLogsRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	return req
+}
@@ -0,0 +1,32 @@
+package supervisor
+
+import (
+	"github.com/forta-network/forta-node/clients/messaging"
+)
+
+// handleAdminBotsAdd runs the bots which the operator added from the admin API.
+func (sup *SupervisorService) handleAdminBotsAdd(payload messaging.AgentPayload) error {
+	if err := sup.operatorBots.AddBots(payload); err != nil {
+		return err
+	}
+	sup.triggerBotRefresh()
+	return nil
+}
+
+// handleAdminBotsRemove stops the bots which the operator removed from the admin API.
+func (sup *SupervisorService) handleAdminBotsRemove(payload messaging.AgentPayload) error {
+	if err := sup.operatorBots.RemoveBots(payload); err != nil {
+		return err
+	}
+	sup.triggerBotRefresh()
+	return nil
+}
+
+// triggerBotRefresh refreshes the bots without waiting for the next check interval. A refresh
+// which is already triggered covers the latest changes too.
+func (sup *SupervisorService) triggerBotRefresh() {
+	select {
+	case sup.refreshBotsCh <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,126 @@
+package supervisor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultAgentLogCaptureInterval = 10 * time.Second
+
+// agentLogCaptureTail limits the lines which are read from a container in each poll, so that the
+// whole log history of a container is not read when the container is captured for the first time.
+const agentLogCaptureTail = 1000
+
+func (sup *SupervisorService) captureAgentLogs() {
+	interval := time.Duration(sup.config.Config.AgentLogsConfig.Capture.PollIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultAgentLogCaptureInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := sup.doCaptureAgentLogs()
+		sup.lastAgentLogsCaptureError.Set(err)
+		if err != nil {
+			log.WithError(err).Warn("failed to capture agent logs")
+		}
+	}
+}
+
+// doCaptureAgentLogs appends the new logs of each bot container to the log files of the bots.
+func (sup *SupervisorService) doCaptureAgentLogs() error {
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(sup.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load the bot containers: %v", err)
+	}
+
+	captured := make(map[string]time.Time)
+	for _, container := range botContainers {
+		botID := container.Labels[docker.LabelFortaBotID]
+		if len(botID) == 0 {
+			continue
+		}
+		logger := log.WithFields(log.Fields{
+			"agent":     botID,
+			"container": container.ID,
+		})
+
+		since, ok := sup.agentLogsCaptured[container.ID]
+		if !ok {
+			// continue after the logs which were captured before a restart
+			since = sup.lastCapturedAgentLog(botID)
+		}
+		logs, err := sup.client.GetContainerLogsSince(sup.ctx, container.ID, since, agentLogCaptureTail)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get agent container logs")
+			captured[container.ID] = since
+			continue
+		}
+		newLogs, last := newAgentLogLines(logs, since)
+		captured[container.ID] = last
+		if len(newLogs) == 0 {
+			continue
+		}
+		if err := sup.agentLogs.Append(botID, newLogs); err != nil {
+			logger.WithError(err).Warn("failed to write agent logs")
+			captured[container.ID] = since
+		}
+	}
+	// forget the removed containers
+	sup.agentLogsCaptured = captured
+	return nil
+}
+
+func (sup *SupervisorService) lastCapturedAgentLog(botID string) time.Time {
+	lines, err := sup.agentLogs.Tail(botID, 1)
+	if err != nil || len(lines) == 0 {
+		return time.Time{}
+	}
+	ts, _ := parseAgentLogLine(lines[0])
+	return ts
+}
+
+// newAgentLogLines returns the lines which are logged after the given time and the time of the last line.
+// The lines without a timestamp are kept.
+func newAgentLogLines(logs []byte, after time.Time) ([]byte, time.Time) {
+	var newLogs bytes.Buffer
+	last := after
+	for _, line := range strings.Split(string(logs), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		ts, ok := parseAgentLogLine(line)
+		if ok && !ts.After(after) {
+			continue
+		}
+		if ok {
+			last = ts
+		}
+		newLogs.WriteString(line)
+		newLogs.WriteByte('\n')
+	}
+	return newLogs.Bytes(), last
+}
+
+// parseAgentLogLine parses the timestamp which docker adds to the beginning of each line.
+func parseAgentLogLine(line string) (time.Time, bool) {
+	i := strings.IndexByte(line, ' ')
+	if i < 0 {
+		i = len(line)
+	}
+	ts, err := time.Parse(time.RFC3339Nano, line[:i])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
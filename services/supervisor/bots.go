@@ -6,17 +6,26 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// refreshBotContainers refreshes bot containers every 15 seconds.
+const defaultBotRefreshInterval = time.Minute
+
+// refreshBotContainers refreshes bot containers at every bot refresh interval.
 // This allows us to blast the latest assignment list very often
 // and keep bot containers and clients in order.
 func (sup *SupervisorService) refreshBotContainers() {
+	interval := time.Duration(sup.config.Config.Registry.BotRefreshIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultBotRefreshInterval
+	}
 	sup.doRefreshBotContainers()
 	for {
 		select {
 		case <-sup.ctx.Done():
 			return
 
-		case <-time.After(time.Minute):
+		case <-time.After(interval):
+			sup.doRefreshBotContainers()
+
+		case <-sup.refreshBotsCh:
 			sup.doRefreshBotContainers()
 		}
 	}
@@ -29,6 +38,10 @@ func (sup *SupervisorService) doRefreshBotContainers() {
 	if err := sup.botLifecycle.BotManager.CleanupUnusedBots(sup.ctx); err != nil {
 		log.WithError(err).Error("error while cleaning up unused bots")
 	}
+	// doing the limit checks before restarts so the bots stopped here are restarted in this round
+	if err := sup.botLifecycle.BotManager.EnforceBotLimits(sup.ctx); err != nil {
+		log.WithError(err).Error("error while enforcing bot limits")
+	}
 	if err := sup.botLifecycle.BotManager.RestartExitedBots(sup.ctx); err != nil {
 		log.WithError(err).Error("error while restarting exited bots")
 	}
@@ -20,10 +20,12 @@ func TestBotManagement(t *testing.T) {
 	gomock.InOrder(
 		botManager.EXPECT().ManageBots(gomock.Any()).Return(testErr),
 		botManager.EXPECT().CleanupUnusedBots(gomock.Any()).Return(testErr),
+		botManager.EXPECT().EnforceBotLimits(gomock.Any()).Return(testErr),
 		botManager.EXPECT().RestartExitedBots(gomock.Any()).Return(testErr),
 		botManager.EXPECT().ExitInactiveBots(gomock.Any()).Return(testErr),
 		botManager.EXPECT().ManageBots(gomock.Any()).Return(testErr),
 		botManager.EXPECT().CleanupUnusedBots(gomock.Any()).Return(testErr),
+		botManager.EXPECT().EnforceBotLimits(gomock.Any()).Return(testErr),
 		botManager.EXPECT().RestartExitedBots(gomock.Any()).Return(testErr),
 		botManager.EXPECT().ExitInactiveBots(gomock.Any()).Return(testErr),
 	)
@@ -0,0 +1,42 @@
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-node/services/components/chaos"
+	log "github.com/sirupsen/logrus"
+)
+
+// killBotsRandomly kills a random bot container periodically, so that the bot restarts can be
+// validated with the fault injection.
+func (sup *SupervisorService) killBotsRandomly(injector *chaos.Injector) {
+	ticker := time.NewTicker(time.Duration(sup.config.Config.Chaos.BotKillIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := sup.doKillRandomBot(injector); err != nil {
+			log.WithError(err).Warn("chaos: failed to kill bot container")
+		}
+	}
+}
+
+func (sup *SupervisorService) doKillRandomBot(injector *chaos.Injector) error {
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(sup.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load the bot containers: %v", err)
+	}
+	if len(botContainers) == 0 {
+		return nil
+	}
+	container := botContainers[injector.Pick(len(botContainers))]
+	if err := sup.client.StopContainer(sup.ctx, container.ID); err != nil {
+		return err
+	}
+	log.WithField("container", container.Names).Warn("chaos: killed bot container")
+	return nil
+}
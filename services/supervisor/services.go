@@ -23,7 +23,10 @@ import (
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
+	"github.com/forta-network/forta-node/services/components/chaos"
 	"github.com/forta-network/forta-node/services/components/containers"
+	"github.com/forta-network/forta-node/services/components/registry"
+	"github.com/forta-network/forta-node/store"
 	"github.com/ipfs/go-cid"
 	log "github.com/sirupsen/logrus"
 )
@@ -48,6 +51,10 @@ type SupervisorService struct {
 
 	botLifecycleConfig components.BotLifecycleConfig
 	botLifecycle       components.BotLifecycle
+	// the bots which the operator adds and removes from the admin API
+	operatorBots *registry.OperatorRegistry
+	// refreshes the bots before the next check interval
+	refreshBotsCh chan struct{}
 
 	manifestClient manifest.Client
 	releaseClient  release.Client
@@ -74,6 +81,7 @@ type SupervisorService struct {
 	lastCustomTelemetryRequestError health.ErrorTracker
 	lastAgentLogsRequest            health.TimeTracker
 	lastAgentLogsRequestError       health.ErrorTracker
+	lastAgentLogsCaptureError       health.ErrorTracker
 	autoUpdatesDisabled             health.MessageTracker
 
 	healthClient health.HealthClient
@@ -81,6 +89,10 @@ type SupervisorService struct {
 	sendAgentLogs func(agents agentlogs.Agents, authToken string) error
 	prevAgentLogs agentlogs.Agents
 	inspectionCh  chan *protocol.InspectionResults
+
+	// the captured agent logs and the time of the last captured line by the container IDs
+	agentLogs         store.AgentLogStore
+	agentLogsCaptured map[string]time.Time
 }
 
 type SupervisorServiceConfig struct {
@@ -104,6 +116,9 @@ func (sup *SupervisorService) Start() error {
 
 	go sup.healthCheck()
 	go sup.refreshBotContainers()
+	if chaosCfg := sup.config.Config.Chaos; chaosCfg.Enable && chaosCfg.BotKillIntervalSeconds > 0 {
+		go sup.killBotsRandomly(chaos.NewInjector(chaosCfg))
+	}
 
 	return nil
 }
@@ -137,6 +152,7 @@ func (sup *SupervisorService) start() error {
 	if releaseInfo != nil {
 		release.LogReleaseInfo(releaseInfo)
 	}
+	overrideEnv := config.OverrideEnv(os.Environ())
 
 	sup.maxLogSize = sup.config.Config.Log.MaxLogSize
 	sup.maxLogFiles = sup.config.Config.Log.MaxLogFiles
@@ -238,6 +254,10 @@ func (sup *SupervisorService) start() error {
 		sup.msgClient = messaging.NewClient("supervisor", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
 	}
 	sup.botLifecycleConfig.MessageClient = sup.msgClient // we are able to set this dependency only here
+	if sup.config.Config.AdminAPI.Enable && sup.operatorBots == nil {
+		sup.operatorBots = registry.NewOperatorRegistry(sup.botLifecycleConfig.BotRegistry, sup.config.Config.ChainID)
+		sup.botLifecycleConfig.BotRegistry = sup.operatorBots
+	}
 	sup.botLifecycle, err = components.GetBotLifecycleComponents(sup.ctx, sup.botLifecycleConfig)
 	if err != nil {
 		return fmt.Errorf("failed to get bot lifecycle components: %v", err)
@@ -247,6 +267,17 @@ func (sup *SupervisorService) start() error {
 	if !shouldDisableAgentLogs {
 		go sup.syncAgentLogs()
 	}
+	if !sup.config.Config.AgentLogsConfig.Capture.Disable {
+		// in tests, this is already set to a mock store
+		if sup.agentLogs == nil {
+			captureCfg := sup.config.Config.AgentLogsConfig.Capture
+			sup.agentLogs = store.NewAgentLogStore(
+				path.Join(sup.config.Config.FortaDir, config.DefaultAgentLogsDirName),
+				int64(captureCfg.MaxFileSizeMB)*1024*1024, captureCfg.MaxFiles,
+			)
+		}
+		go sup.captureAgentLogs()
+	}
 
 	sup.registerMessageHandlers()
 
@@ -291,6 +322,7 @@ func (sup *SupervisorService) start() error {
 			Name:  config.DockerJSONRPCProxyContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "json-rpc"},
+			Env:   overrideEnv,
 			Volumes: map[string]string{
 				// give access to host docker
 				"/var/run/docker.sock": "/var/run/docker.sock",
@@ -316,6 +348,7 @@ func (sup *SupervisorService) start() error {
 			Name:  config.DockerPublicAPIProxyContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "public-api"},
+			Env:   overrideEnv,
 			Volumes: map[string]string{
 				// give access to host docker
 				"/var/run/docker.sock": "/var/run/docker.sock",
@@ -355,6 +388,7 @@ func (sup *SupervisorService) start() error {
 			Name:  config.DockerInspectorContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "inspector"},
+			Env:   overrideEnv,
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
@@ -388,20 +422,52 @@ func (sup *SupervisorService) start() error {
 		log.Info("inspection to completed")
 	}
 
+	scannerPorts := map[string]string{
+		"": config.DefaultHealthPort, // random host port
+	}
+	// publish the metrics port from the scanner if the exporter is enabled
+	if promCfg := sup.config.Config.PrometheusConfig; promCfg.Enable {
+		scannerPorts[hostPort(promCfg.HostIP, promCfg.Port)] = promCfg.Port
+	}
+	// publish the status api port from the scanner if the status api is enabled
+	if statusCfg := sup.config.Config.StatusAPI; statusCfg.Enable {
+		scannerPorts[hostPort(statusCfg.HostIP, statusCfg.Port)] = statusCfg.Port
+	}
+	// publish the ingest api ports from the scanner if the ingest api is enabled
+	if ingestCfg := sup.config.Config.IngestAPI; ingestCfg.Enable {
+		scannerPorts[ingestCfg.Port] = ingestCfg.Port
+		scannerPorts[ingestCfg.GrpcPort] = ingestCfg.GrpcPort
+	}
+
+	// publish the admin api port from the scanner if the admin api listens on a port
+	if adminCfg := sup.config.Config.AdminAPI; adminCfg.Enable && len(adminCfg.Port) > 0 {
+		scannerPorts[adminCfg.Port] = adminCfg.Port
+	}
+
+	// publish the alert query api port from the scanner if the alert query api is enabled
+	if queryCfg := sup.config.Config.AlertQueryAPI; queryCfg.Enable {
+		scannerPorts[queryCfg.Port] = queryCfg.Port
+	}
+
+	scannerEnv := map[string]string{
+		config.EnvReleaseInfo: releaseInfo.String(),
+		config.EnvDevelopment: os.Getenv(config.EnvDevelopment),
+		config.EnvReplayFrom:  os.Getenv(config.EnvReplayFrom),
+		config.EnvReplayTo:    os.Getenv(config.EnvReplayTo),
+	}
+	for envVar, value := range overrideEnv {
+		scannerEnv[envVar] = value
+	}
 	sup.scannerContainer, err = sup.client.StartContainer(
 		sup.ctx, docker.ContainerConfig{
 			Name:  config.DockerScannerContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "scanner"},
-			Env: map[string]string{
-				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+			Env:   scannerEnv,
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
-			Ports: map[string]string{
-				"": config.DefaultHealthPort, // random host port
-			},
+			Ports: scannerPorts,
 			Files: map[string][]byte{
 				"passphrase": []byte(sup.config.Passphrase),
 			},
@@ -469,6 +535,18 @@ func (sup *SupervisorService) registerMessageHandlers() {
 	if *sup.config.Config.InspectionConfig.InspectAtStartup {
 		sup.msgClient.Subscribe(messaging.SubjectInspectionDone, messaging.InspectionResultsHandler(sup.handleInspectionResults))
 	}
+	if sup.config.Config.AdminAPI.Enable {
+		sup.msgClient.Subscribe(messaging.SubjectAdminBotsAdd, messaging.AgentsHandler(sup.handleAdminBotsAdd))
+		sup.msgClient.Subscribe(messaging.SubjectAdminBotsRemove, messaging.AgentsHandler(sup.handleAdminBotsRemove))
+		sup.msgClient.Subscribe(messaging.SubjectAdminLogLevel, messaging.LogLevelHandler(messaging.SetLogLevel))
+	}
+	// the scanner lets the containers know after reloading the config
+	sup.msgClient.Subscribe(messaging.SubjectAdminConfigReload, messaging.ConfigReloadHandler(sup.handleConfigReload))
+}
+
+func (sup *SupervisorService) handleConfigReload(payload messaging.ConfigReloadPayload) error {
+	services.TriggerReload()
+	return nil
 }
 
 func manageIpfsDir(cfg config.Config) error {
@@ -559,6 +637,26 @@ func (sup *SupervisorService) removeOldContainers() error {
 				ID:   container.ID,
 				Name: containerName,
 			})
+			continue
+		}
+		// the network of the bot is created again with the right isolation
+		isolated := sup.config.Config.AgentNetwork.IsIsolated(container.Labels[docker.LabelFortaBotID])
+		if !containers.HasBotNetwork(&container, isolated) {
+			logger.WithField("isolated", isolated).Info("bot network isolation has changed - need to remove")
+			containersToRemove = append(containersToRemove, &containerDefinition{
+				ID:   container.ID,
+				Name: containerName,
+			})
+			continue
+		}
+		// the sandbox settings are applied only when the container is created
+		sandbox := config.GetBotSandbox(sup.config.Config.AgentSandbox, container.Labels[docker.LabelFortaBotID])
+		if !containers.HasBotSandbox(&container, sandbox) {
+			logger.Info("bot sandbox has changed - need to remove")
+			containersToRemove = append(containersToRemove, &containerDefinition{
+				ID:   container.ID,
+				Name: containerName,
+			})
 		}
 	}
 
@@ -739,6 +837,7 @@ func (sup *SupervisorService) Health() health.Reports {
 		sup.lastCustomTelemetryRequestError.GetReport("event.custom-telemetry-sync.error"),
 		sup.lastAgentLogsRequest.GetReport("event.agent-logs-sync.time"),
 		sup.lastAgentLogsRequestError.GetReport("event.agent-logs-sync.error"),
+		sup.lastAgentLogsCaptureError.GetReport("event.agent-logs-capture.error"),
 		sup.autoUpdatesDisabled.GetReport("auto-updates.disabled"),
 	}
 }
@@ -779,8 +878,18 @@ func NewSupervisorService(ctx context.Context, cfg SupervisorServiceConfig) (*Su
 		healthClient:       health.NewClient(),
 		sendAgentLogs:      agentlogs.NewClient(cfg.Config.AgentLogsConfig.URL).SendLogs,
 		inspectionCh:       make(chan *protocol.InspectionResults),
+		refreshBotsCh:      make(chan struct{}, 1),
 	}
 	sup.autoUpdatesDisabled.Set(strconv.FormatBool(cfg.Config.AutoUpdate.Disable))
 
 	return sup, nil
 }
+
+// hostPort makes the host port of a published container port on the host interface. The port is
+// published on all interfaces if the host IP is empty.
+func hostPort(hostIP, port string) string {
+	if len(hostIP) == 0 {
+		return port
+	}
+	return fmt.Sprintf("%s:%s", hostIP, port)
+}
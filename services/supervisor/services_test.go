@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/clients/agentlogs"
@@ -22,6 +23,7 @@ import (
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/containers"
 	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
+	"github.com/forta-network/forta-node/store"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -33,6 +35,7 @@ const (
 	testNatsNetworkID          = "nats-network-id"
 	testPublicAPINetworkID     = "public-api-network-id"
 	testGenericContainerID     = "test-generic-container-id"
+	testBotID                  = "0x04302ac5a1f5c0c109e7f1949f1905e1ea28a5477ffb4d4d4d4a9b3a3da0bc7e"
 	testInspectorContainerID   = "test-inspector-container-id"
 	testScannerContainerID     = "test-scanner-container-id"
 	testProxyContainerID       = "test-proxy-container-id"
@@ -131,6 +134,8 @@ func (s *Suite) SetupTest() {
 	supervisor.config.Config.AdvancedConfig.IPFSExperiment = true
 	supervisor.config.Config.InspectionConfig.InspectAtStartup = utils.BoolPtr(false)
 	supervisor.config.Config.AgentLogsConfig.SendIntervalSeconds = 1
+	// the bot certificate authority is created in the forta dir
+	supervisor.config.Config.FortaDir = s.T().TempDir()
 	supervisor.botLifecycleConfig.Config = supervisor.config.Config
 	supervisor.botLifecycle.BotClient = s.botClient
 	s.supervisor = supervisor
@@ -157,21 +162,41 @@ func (s *Suite) initialContainerCheck() {
 					docker.LabelFortaSupervisorStrategyVersion: "old",
 				},
 			},
+			{
+				// isolated while the isolation is disabled
+				Names: []string{"/forta-agent-name"},
+				ID:    testGenericContainerID,
+				Labels: map[string]string{
+					docker.LabelFortaSupervisorStrategyVersion: containers.LabelValueStrategyVersion,
+					docker.LabelFortaBotNetwork:                containers.LabelValueBotNetworkIsolated,
+				},
+			},
+			{
+				// sandboxed while the sandbox is disabled
+				Names: []string{"/forta-agent-name"},
+				ID:    testGenericContainerID,
+				Labels: map[string]string{
+					docker.LabelFortaSupervisorStrategyVersion: containers.LabelValueStrategyVersion,
+					docker.LabelFortaBotSandbox:                "0123456789abcdef",
+				},
+			},
 		}, nil,
 	)
 
 	// supervisor-managed containers
-	for i := 0; i < len(knownServiceContainerNames)+1; i++ {
+	for i := 0; i < len(knownServiceContainerNames)+3; i++ {
 		s.dockerClient.EXPECT().RemoveContainer(s.supervisor.ctx, testGenericContainerID).Return(nil)
 		s.dockerClient.EXPECT().WaitContainerPrune(s.supervisor.ctx, testGenericContainerID).Return(nil)
 	}
-	for i := 0; i < len(knownServiceContainerNames)+1; i++ {
+	for i := 0; i < len(knownServiceContainerNames)+3; i++ {
 		s.dockerClient.EXPECT().RemoveNetworkByName(s.supervisor.ctx, gomock.Any()).Return(nil)
 	}
 }
 
 func (s *Suite) TestStartServices() {
 	s.msgClient.EXPECT().Subscribe(messaging.SubjectMetricAgent, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectAgentsStatusSwapped, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectAdminConfigReload, gomock.Any())
 
 	s.releaseClient.EXPECT().GetReleaseManifest(gomock.Any()).Return(&release.ReleaseManifest{}, nil).AnyTimes()
 
@@ -264,6 +289,42 @@ func (s *Suite) TestDoSyncAgentLogs() {
 	s.r.NoError(s.supervisor.doSyncAgentLogs())
 }
 
+func (s *Suite) TestDoCaptureAgentLogs() {
+	s.supervisor.agentLogs = store.NewAgentLogStore(s.T().TempDir(), 0, 0)
+	botContainers := []types.Container{{
+		ID: testGenericContainerID,
+		Labels: map[string]string{
+			docker.LabelFortaBotID: testBotID,
+		},
+	}}
+	logs := "2023-01-01T00:00:01.000000001Z line 1\n2023-01-01T00:00:02.000000001Z line 2\n"
+	s.botClient.EXPECT().LoadBotContainers(gomock.Any()).Return(botContainers, nil)
+	s.dockerClient.EXPECT().GetContainerLogsSince(gomock.Any(), testGenericContainerID, time.Time{}, agentLogCaptureTail).Return([]byte(logs), nil)
+	s.r.NoError(s.supervisor.doCaptureAgentLogs())
+
+	// the last line is returned again because the since time is inclusive
+	lastLine, _ := time.Parse(time.RFC3339Nano, "2023-01-01T00:00:02.000000001Z")
+	logs = "2023-01-01T00:00:02.000000001Z line 2\n2023-01-01T00:00:03.000000001Z line 3\n"
+	s.botClient.EXPECT().LoadBotContainers(gomock.Any()).Return(botContainers, nil)
+	s.dockerClient.EXPECT().GetContainerLogsSince(gomock.Any(), testGenericContainerID, lastLine, agentLogCaptureTail).Return([]byte(logs), nil)
+	s.r.NoError(s.supervisor.doCaptureAgentLogs())
+
+	lines, err := s.supervisor.agentLogs.Tail(testBotID, 0)
+	s.r.NoError(err)
+	s.r.Equal([]string{
+		"2023-01-01T00:00:01.000000001Z line 1",
+		"2023-01-01T00:00:02.000000001Z line 2",
+		"2023-01-01T00:00:03.000000001Z line 3",
+	}, lines)
+}
+
 func (s *Suite) TestDoHealthCheck() {
 	s.r.NoError(s.supervisor.doHealthCheck())
 }
+
+func TestHostPort(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("127.0.0.1:9108", hostPort("127.0.0.1", "9108"))
+	r.Equal("9108", hostPort("", "9108"))
+}
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Agent log file limits
+const (
+	DefaultAgentLogFileSize = 10 * 1024 * 1024
+	DefaultAgentLogFiles    = 3
+)
+
+const agentLogFileExt = ".log"
+
+// ErrAgentLogsNotFound is returned when there are no logs of the given bot.
+var ErrAgentLogsNotFound = errors.New("agent logs not found")
+
+// AgentLogStore keeps the captured stdout and stderr of the bots.
+type AgentLogStore interface {
+	Append(botID string, logs []byte) error
+	// Tail returns the last lines of the bot logs from the oldest to the newest.
+	Tail(botID string, lines int) ([]string, error)
+	// List returns the IDs of the bots which have logs.
+	List() ([]string, error)
+}
+
+// agentLogStore appends the logs of each bot to a separate file in the directory. When the file
+// exceeds the size limit, it is rotated to <bot>.log.1 and the older files are shifted up to
+// the file limit so that the disk usage of a bot stays bounded.
+type agentLogStore struct {
+	dir      string
+	maxBytes int64
+	maxFiles int
+	mu       sync.Mutex
+}
+
+// NewAgentLogStore creates a new agent log store in the given directory. The max files include the
+// file which is being written.
+func NewAgentLogStore(dir string, maxFileBytes int64, maxFiles int) *agentLogStore {
+	if maxFileBytes <= 0 {
+		maxFileBytes = DefaultAgentLogFileSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultAgentLogFiles
+	}
+	return &agentLogStore{dir: dir, maxBytes: maxFileBytes, maxFiles: maxFiles}
+}
+
+// Append appends the logs to the current log file of the bot.
+func (als *agentLogStore) Append(botID string, logs []byte) error {
+	if !validAgentLogID(botID) {
+		return fmt.Errorf("invalid bot id for the logs: %s", botID)
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	if logs[len(logs)-1] != '\n' {
+		logs = append(logs, '\n')
+	}
+
+	als.mu.Lock()
+	defer als.mu.Unlock()
+
+	if err := makePrivateDir(als.dir); err != nil {
+		return fmt.Errorf("failed to create the agent log dir: %v", err)
+	}
+	info, err := os.Stat(als.filePath(botID, 0))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check the agent log file: %v", err)
+	}
+	if err == nil && info.Size() > 0 && info.Size()+int64(len(logs)) > als.maxBytes {
+		if err := als.rotate(botID); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(als.filePath(botID, 0), os.O_CREATE|os.O_APPEND|os.O_WRONLY, privateFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open the agent log file: %v", err)
+	}
+	defer f.Close()
+	// the existing file may have been created with other permissions
+	if err := f.Chmod(privateFilePerm); err != nil {
+		return fmt.Errorf("failed to set the agent log file permissions: %v", err)
+	}
+	if _, err := f.Write(logs); err != nil {
+		return fmt.Errorf("failed to write the agent logs: %v", err)
+	}
+	return nil
+}
+
+// rotate shifts the log files of the bot by one and deletes the oldest one.
+func (als *agentLogStore) rotate(botID string) error {
+	oldest := als.filePath(botID, als.maxFiles-1)
+	if err := os.Remove(oldest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete the oldest agent log file: %v", err)
+	}
+	for i := als.maxFiles - 2; i >= 0; i-- {
+		err := os.Rename(als.filePath(botID, i), als.filePath(botID, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate the agent log file: %v", err)
+		}
+	}
+	return nil
+}
+
+// Tail reads the files of the bot from the newest to the oldest until it has enough lines.
+// All lines are returned if the line count is not positive.
+func (als *agentLogStore) Tail(botID string, lines int) ([]string, error) {
+	if !validAgentLogID(botID) {
+		return nil, ErrAgentLogsNotFound
+	}
+	var (
+		result []string
+		found  bool
+	)
+	for i := 0; i < als.maxFiles; i++ {
+		b, err := os.ReadFile(als.filePath(botID, i))
+		if errors.Is(err, os.ErrNotExist) {
+			// the current file may be missing right after the rotation
+			if i == 0 {
+				continue
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the agent logs: %v", err)
+		}
+		found = true
+		fileLines := strings.Split(string(bytes.TrimRight(b, "\n")), "\n")
+		if len(b) == 0 {
+			fileLines = nil
+		}
+		result = append(fileLines, result...)
+		if lines > 0 && len(result) >= lines {
+			return result[len(result)-lines:], nil
+		}
+	}
+	if !found {
+		return nil, ErrAgentLogsNotFound
+	}
+	return result, nil
+}
+
+// List returns the IDs of the bots which have logs in an alphabetical order.
+func (als *agentLogStore) List() ([]string, error) {
+	entries, err := os.ReadDir(als.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the agent log dir: %v", err)
+	}
+	var botIDs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, agentLogFileExt) {
+			continue
+		}
+		botIDs = append(botIDs, strings.TrimSuffix(name, agentLogFileExt))
+	}
+	sort.Strings(botIDs)
+	return botIDs, nil
+}
+
+// FindAgentLogs finds the bot which has logs by the bot ID or a unique prefix of it.
+func FindAgentLogs(als AgentLogStore, idOrPrefix string) (string, error) {
+	botIDs, err := als.List()
+	if err != nil {
+		return "", err
+	}
+	idOrPrefix = strings.ToLower(idOrPrefix)
+	var found []string
+	for _, botID := range botIDs {
+		if strings.ToLower(botID) == idOrPrefix {
+			return botID, nil
+		}
+		if strings.HasPrefix(strings.ToLower(botID), idOrPrefix) {
+			found = append(found, botID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", ErrAgentLogsNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("%d bots have logs with the id prefix %s", len(found), idOrPrefix)
+	}
+}
+
+func (als *agentLogStore) filePath(botID string, index int) string {
+	name := botID + agentLogFileExt
+	if index > 0 {
+		name = fmt.Sprintf("%s.%d", name, index)
+	}
+	return path.Join(als.dir, name)
+}
+
+func validAgentLogID(botID string) bool {
+	return len(botID) > 0 && !strings.ContainsAny(botID, `/\`) && !strings.HasPrefix(botID, ".")
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentLogStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := path.Join(t.TempDir(), "agent-logs")
+	als := NewAgentLogStore(dir, 0, 0)
+
+	botIDs, err := als.List()
+	r.NoError(err)
+	r.Empty(botIDs)
+
+	_, err = als.Tail("0x1", 10)
+	r.ErrorIs(err, ErrAgentLogsNotFound)
+
+	r.NoError(als.Append("0x1", []byte("line 1\nline 2")))
+	r.NoError(als.Append("0x1", []byte("line 3\n")))
+	r.NoError(als.Append("0x2", []byte("other\n")))
+	info, err := os.Stat(als.filePath("0x1", 0))
+	r.NoError(err)
+	r.Equal(privateFilePerm, info.Mode().Perm())
+	r.Error(als.Append("../0x1", []byte("line\n")))
+
+	lines, err := NewAgentLogStore(dir, 0, 0).Tail("0x1", 2)
+	r.NoError(err)
+	r.Equal([]string{"line 2", "line 3"}, lines)
+
+	lines, err = als.Tail("0x1", 0)
+	r.NoError(err)
+	r.Equal([]string{"line 1", "line 2", "line 3"}, lines)
+
+	botIDs, err = als.List()
+	r.NoError(err)
+	r.Equal([]string{"0x1", "0x2"}, botIDs)
+
+	botID, err := FindAgentLogs(als, "0x2")
+	r.NoError(err)
+	r.Equal("0x2", botID)
+	_, err = FindAgentLogs(als, "0x")
+	r.Error(err)
+	_, err = FindAgentLogs(als, "0x3")
+	r.ErrorIs(err, ErrAgentLogsNotFound)
+}
+
+func TestAgentLogStore_Rotate(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	// each line is 7 bytes so that each file has two lines
+	als := NewAgentLogStore(dir, 14, 3)
+
+	for i := 0; i < 10; i++ {
+		r.NoError(als.Append("0x1", []byte(fmt.Sprintf("line %d\n", i))))
+	}
+
+	entries, err := os.ReadDir(dir)
+	r.NoError(err)
+	r.Len(entries, 3)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		r.NoError(err)
+		r.LessOrEqual(info.Size(), int64(14))
+	}
+
+	// only the lines of the last three files are kept
+	lines, err := als.Tail("0x1", 0)
+	r.NoError(err)
+	r.Equal([]string{"line 4", "line 5", "line 6", "line 7", "line 8", "line 9"}, lines)
+
+	lines, err = als.Tail("0x1", 3)
+	r.NoError(err)
+	r.Equal([]string{"line 7", "line 8", "line 9"}, lines)
+
+	botIDs, err := als.List()
+	r.NoError(err)
+	r.Equal([]string{"0x1"}, botIDs)
+}
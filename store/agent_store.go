@@ -0,0 +1,238 @@
+package store
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Agent store errors
+var (
+	ErrAgentKeyNotFound        = errors.New("key not found")
+	ErrAgentStoreLimitExceeded = errors.New("agent store limit exceeded")
+	ErrInvalidAgentKey         = errors.New("invalid key")
+)
+
+// AgentStoreLimits are the size limits of the store of each bot.
+type AgentStoreLimits struct {
+	MaxKeys       int
+	MaxKeyBytes   int
+	MaxValueBytes int
+	// the total size of the keys and the values
+	MaxBytes int
+}
+
+// AgentStoreUsage is the size of the store of a bot.
+type AgentStoreUsage struct {
+	BotID string `json:"botId"`
+	Keys  int    `json:"keys"`
+	Bytes int64  `json:"bytes"`
+}
+
+// AgentStore keeps a small key-value store for each bot.
+type AgentStore interface {
+	Get(botID, key string) ([]byte, error)
+	Put(botID, key string, value []byte) error
+	Delete(botID, key string) error
+	Keys(botID string) ([]string, error)
+	Usage(botID string) (*AgentStoreUsage, error)
+	Bots() ([]string, error)
+	Wipe(botID string) error
+}
+
+// agentStore keeps the store of each bot in a separate directory and each value in a separate
+// file, so that both the node and the CLI can use the store at the same time. The file names are
+// the hex encoded keys, so that the keys can be listed and the usage can be calculated without
+// reading the values.
+type agentStore struct {
+	dir    string
+	limits AgentStoreLimits
+	mu     sync.Mutex
+}
+
+// NewAgentStore creates a new agent store in the given directory.
+func NewAgentStore(dir string, limits AgentStoreLimits) *agentStore {
+	return &agentStore{dir: dir, limits: limits}
+}
+
+// Get returns the value of the key in the store of the bot.
+func (as *agentStore) Get(botID, key string) ([]byte, error) {
+	filePath, err := as.filePath(botID, key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrAgentKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the value: %v", err)
+	}
+	return b, nil
+}
+
+// Put sets the value of the key in the store of the bot if the store stays within the limits.
+func (as *agentStore) Put(botID, key string, value []byte) error {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	filePath, err := as.filePath(botID, key)
+	if err != nil {
+		return err
+	}
+	if len(key) > as.limits.MaxKeyBytes {
+		return fmt.Errorf("%w: key is longer than %d bytes", ErrAgentStoreLimitExceeded, as.limits.MaxKeyBytes)
+	}
+	if len(value) > as.limits.MaxValueBytes {
+		return fmt.Errorf("%w: value is larger than %d bytes", ErrAgentStoreLimitExceeded, as.limits.MaxValueBytes)
+	}
+
+	usage, err := as.Usage(botID)
+	if err != nil {
+		return err
+	}
+	// the current value is replaced
+	if info, err := os.Stat(filePath); err == nil {
+		usage.Keys--
+		usage.Bytes -= int64(len(key)) + info.Size()
+	}
+	if usage.Keys+1 > as.limits.MaxKeys {
+		return fmt.Errorf("%w: store has %d keys", ErrAgentStoreLimitExceeded, as.limits.MaxKeys)
+	}
+	if usage.Bytes+int64(len(key)+len(value)) > int64(as.limits.MaxBytes) {
+		return fmt.Errorf("%w: store is larger than %d bytes", ErrAgentStoreLimitExceeded, as.limits.MaxBytes)
+	}
+
+	for _, dir := range []string{as.dir, path.Dir(filePath)} {
+		if err := makePrivateDir(dir); err != nil {
+			return fmt.Errorf("failed to create the agent store dir: %v", err)
+		}
+	}
+	if err := writePrivateFile(filePath, value); err != nil {
+		return fmt.Errorf("failed to save the value: %v", err)
+	}
+	return nil
+}
+
+// Delete deletes the key from the store of the bot. Deleting a missing key is not an error.
+func (as *agentStore) Delete(botID, key string) error {
+	filePath, err := as.filePath(botID, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete the value: %v", err)
+	}
+	return nil
+}
+
+// Keys returns the sorted keys in the store of the bot.
+func (as *agentStore) Keys(botID string) ([]string, error) {
+	var keys []string
+	err := as.walk(botID, func(key string, size int64) {
+		keys = append(keys, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Usage returns the size of the store of the bot.
+func (as *agentStore) Usage(botID string) (*AgentStoreUsage, error) {
+	usage := &AgentStoreUsage{BotID: strings.ToLower(botID)}
+	err := as.walk(botID, func(key string, size int64) {
+		usage.Keys++
+		usage.Bytes += int64(len(key)) + size
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// Bots returns the bots which have a store.
+func (as *agentStore) Bots() ([]string, error) {
+	entries, err := os.ReadDir(as.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the agent store dir: %v", err)
+	}
+	var botIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			botIDs = append(botIDs, entry.Name())
+		}
+	}
+	return botIDs, nil
+}
+
+// Wipe deletes the store of the bot.
+func (as *agentStore) Wipe(botID string) error {
+	botDir, err := as.botDir(botID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(botDir); err != nil {
+		return fmt.Errorf("failed to wipe the agent store: %v", err)
+	}
+	return nil
+}
+
+// walk calls the function with each key and the size of its value.
+func (as *agentStore) walk(botID string, fn func(key string, size int64)) error {
+	botDir, err := as.botDir(botID)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(botDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the agent store dir: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		key, err := hex.DecodeString(name)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		// the value may be deleted while walking
+		if err != nil {
+			continue
+		}
+		fn(string(key), info.Size())
+	}
+	return nil
+}
+
+func (as *agentStore) botDir(botID string) (string, error) {
+	if len(botID) == 0 || strings.ContainsAny(botID, `/\`) || strings.HasPrefix(botID, ".") {
+		return "", fmt.Errorf("invalid bot id: %s", botID)
+	}
+	return path.Join(as.dir, strings.ToLower(botID)), nil
+}
+
+func (as *agentStore) filePath(botID, key string) (string, error) {
+	if len(key) == 0 {
+		return "", ErrInvalidAgentKey
+	}
+	botDir, err := as.botDir(botID)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(botDir, hex.EncodeToString([]byte(key))), nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testAgentStoreLimits = AgentStoreLimits{MaxKeys: 3, MaxKeyBytes: 10, MaxValueBytes: 10, MaxBytes: 30}
+
+func TestAgentStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := path.Join(t.TempDir(), "agent-store")
+	as := NewAgentStore(dir, testAgentStoreLimits)
+
+	_, err := as.Get("0xBot1", "a")
+	r.Equal(ErrAgentKeyNotFound, err)
+	bots, err := as.Bots()
+	r.NoError(err)
+	r.Empty(bots)
+
+	r.NoError(as.Put("0xBot1", "b", []byte("1")))
+	r.NoError(as.Put("0xBot1", "a/../c", []byte("22")))
+	r.NoError(as.Put("0xbot2", "b", []byte("3")))
+
+	// only the owner can read the values
+	filePath, err := as.filePath("0xbot1", "b")
+	r.NoError(err)
+	info, err := os.Stat(filePath)
+	r.NoError(err)
+	r.Equal(privateFilePerm, info.Mode().Perm())
+	info, err = os.Stat(path.Dir(filePath))
+	r.NoError(err)
+	r.Equal(privateDirPerm, info.Mode().Perm())
+
+	// another store in the same dir sees the values and the bot ids are case insensitive
+	other := NewAgentStore(dir, testAgentStoreLimits)
+	value, err := other.Get("0xbot1", "b")
+	r.NoError(err)
+	r.Equal("1", string(value))
+	keys, err := other.Keys("0xbot1")
+	r.NoError(err)
+	r.Equal([]string{"a/../c", "b"}, keys)
+	usage, err := other.Usage("0xBot1")
+	r.NoError(err)
+	r.Equal(&AgentStoreUsage{BotID: "0xbot1", Keys: 2, Bytes: 10}, usage)
+	bots, err = other.Bots()
+	r.NoError(err)
+	r.Equal([]string{"0xbot1", "0xbot2"}, bots)
+
+	// the bots do not see each other's keys
+	value, err = as.Get("0xbot2", "b")
+	r.NoError(err)
+	r.Equal("3", string(value))
+
+	r.NoError(as.Delete("0xbot1", "b"))
+	r.NoError(as.Delete("0xbot1", "b"))
+	_, err = as.Get("0xbot1", "b")
+	r.Equal(ErrAgentKeyNotFound, err)
+
+	r.NoError(as.Wipe("0xbot1"))
+	keys, err = as.Keys("0xbot1")
+	r.NoError(err)
+	r.Empty(keys)
+	bots, err = as.Bots()
+	r.NoError(err)
+	r.Equal([]string{"0xbot2"}, bots)
+
+	_, err = as.Get("0xbot1", "")
+	r.Equal(ErrInvalidAgentKey, err)
+	r.Error(as.Wipe("../0xbot2"))
+	r.Error(as.Wipe(".."))
+}
+
+func TestAgentStore_Limits(t *testing.T) {
+	r := require.New(t)
+
+	as := NewAgentStore(t.TempDir(), testAgentStoreLimits)
+
+	r.True(errors.Is(as.Put("0xbot", "01234567890", nil), ErrAgentStoreLimitExceeded))
+	r.True(errors.Is(as.Put("0xbot", "a", []byte("01234567890")), ErrAgentStoreLimitExceeded))
+
+	r.NoError(as.Put("0xbot", "a", []byte("0123456789")))
+	r.NoError(as.Put("0xbot", "b", []byte("0123456789")))
+	// the size limit
+	r.True(errors.Is(as.Put("0xbot", "c", []byte("0123456789")), ErrAgentStoreLimitExceeded))
+	r.NoError(as.Put("0xbot", "c", []byte("1")))
+	// the key limit
+	r.True(errors.Is(as.Put("0xbot", "d", nil), ErrAgentStoreLimitExceeded))
+	// the values can be replaced within the limits
+	r.NoError(as.Put("0xbot", "c", []byte("0123456")))
+	usage, err := as.Usage("0xbot")
+	r.NoError(err)
+	r.Equal(3, usage.Keys)
+	r.Equal(int64(30), usage.Bytes)
+}
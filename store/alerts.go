@@ -0,0 +1,151 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const prefixStoredAlert = "stored-alert/"
+
+// StoredAlert is an alert which is kept in the local store for the queries.
+type StoredAlert struct {
+	ID          string    `json:"id"`
+	ChainID     uint64    `json:"chainId"`
+	BotID       string    `json:"botId"`
+	Severity    string    `json:"severity"`
+	Addresses   []string  `json:"addresses,omitempty"`
+	BlockNumber uint64    `json:"blockNumber"`
+	Timestamp   time.Time `json:"timestamp"`
+	// the signed alert as JSON
+	Alert json.RawMessage `json:"alert"`
+}
+
+// AlertQuery filters the stored alerts. The zero values match all alerts.
+type AlertQuery struct {
+	ChainID     uint64
+	BotIDs      []string
+	Severities  []string
+	MinSeverity string
+	Address     string
+	// the time range is inclusive
+	From time.Time
+	To   time.Time
+	// the block range is inclusive
+	FromBlock uint64
+	ToBlock   uint64
+	Limit     int
+}
+
+// AlertStore keeps the alerts of the node so that they can be queried locally.
+type AlertStore interface {
+	PutAlert(alert *StoredAlert) error
+	// QueryAlerts returns the matching alerts from the newest to the oldest.
+	QueryAlerts(query AlertQuery) ([]*StoredAlert, error)
+	PruneAlerts(before time.Time) error
+}
+
+// PutAlert stores the alert by the alert timestamp.
+func (ls *localStore) PutAlert(alert *StoredAlert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %v", err)
+	}
+	return ls.db.Put(storedAlertKey(alert.Timestamp, alert.ID), b, nil)
+}
+
+// QueryAlerts iterates the alerts in the time range from the newest and returns the matching ones.
+func (ls *localStore) QueryAlerts(query AlertQuery) ([]*StoredAlert, error) {
+	keyRange := util.BytesPrefix([]byte(prefixStoredAlert))
+	if !query.From.IsZero() {
+		keyRange.Start = storedAlertKey(query.From, "")
+	}
+	if !query.To.IsZero() {
+		keyRange.Limit = storedAlertKey(query.To.Add(time.Nanosecond), "")
+	}
+	iter := ls.db.NewIterator(keyRange, nil)
+	defer iter.Release()
+
+	var alerts []*StoredAlert
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		var alert StoredAlert
+		if err := json.Unmarshal(iter.Value(), &alert); err != nil {
+			return nil, fmt.Errorf("failed to decode alert: %v", err)
+		}
+		if !query.Match(&alert) {
+			continue
+		}
+		alerts = append(alerts, &alert)
+		if query.Limit > 0 && len(alerts) >= query.Limit {
+			break
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate alerts: %v", err)
+	}
+	return alerts, nil
+}
+
+// PruneAlerts deletes the alerts which are older than the given time.
+func (ls *localStore) PruneAlerts(before time.Time) error {
+	iter := ls.db.NewIterator(&util.Range{
+		Start: []byte(prefixStoredAlert),
+		Limit: storedAlertKey(before, ""),
+	}, nil)
+	defer iter.Release()
+
+	var batch leveldb.Batch
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate alerts: %v", err)
+	}
+	return ls.db.Write(&batch, nil)
+}
+
+// Match tells if the alert matches all of the query filters except the time range.
+func (query *AlertQuery) Match(alert *StoredAlert) bool {
+	if query.ChainID != 0 && alert.ChainID != query.ChainID {
+		return false
+	}
+	if len(query.BotIDs) > 0 && !containsFold(query.BotIDs, alert.BotID) {
+		return false
+	}
+	if len(query.Severities) > 0 && !containsFold(query.Severities, alert.Severity) {
+		return false
+	}
+	if len(query.MinSeverity) > 0 &&
+		protocol.Finding_Severity_value[strings.ToUpper(alert.Severity)] < protocol.Finding_Severity_value[strings.ToUpper(query.MinSeverity)] {
+		return false
+	}
+	if len(query.Address) > 0 && !containsFold(alert.Addresses, query.Address) {
+		return false
+	}
+	if query.FromBlock != 0 && alert.BlockNumber < query.FromBlock {
+		return false
+	}
+	if query.ToBlock != 0 && alert.BlockNumber > query.ToBlock {
+		return false
+	}
+	return true
+}
+
+// storedAlertKey makes the alert keys sortable by time.
+func storedAlertKey(t time.Time, id string) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", prefixStoredAlert, t.UnixNano(), id))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_Alerts(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	now := time.Now().UTC()
+	alerts := []*StoredAlert{
+		{ID: "alert1", ChainID: 1, BotID: "0xbot1", Severity: "LOW", BlockNumber: 10, Timestamp: now.Add(-time.Hour), Addresses: []string{"0xabc"}},
+		{ID: "alert2", ChainID: 1, BotID: "0xbot2", Severity: "HIGH", BlockNumber: 20, Timestamp: now.Add(-time.Minute)},
+		{ID: "alert3", ChainID: 137, BotID: "0xbot1", Severity: "CRITICAL", BlockNumber: 30, Timestamp: now},
+	}
+	for _, alert := range alerts {
+		r.NoError(localStore.PutAlert(alert))
+	}
+
+	ids := func(query AlertQuery) (ids []string) {
+		result, err := localStore.QueryAlerts(query)
+		r.NoError(err)
+		for _, alert := range result {
+			ids = append(ids, alert.ID)
+		}
+		return
+	}
+
+	r.Equal([]string{"alert3", "alert2", "alert1"}, ids(AlertQuery{}))
+	r.Equal([]string{"alert3", "alert2"}, ids(AlertQuery{Limit: 2}))
+	r.Equal([]string{"alert2", "alert1"}, ids(AlertQuery{ChainID: 1}))
+	r.Equal([]string{"alert3", "alert1"}, ids(AlertQuery{BotIDs: []string{"0xBOT1"}}))
+	r.Equal([]string{"alert1"}, ids(AlertQuery{Severities: []string{"low"}}))
+	r.Equal([]string{"alert3", "alert2"}, ids(AlertQuery{MinSeverity: "HIGH"}))
+	r.Equal([]string{"alert1"}, ids(AlertQuery{Address: "0xABC"}))
+	r.Equal([]string{"alert2"}, ids(AlertQuery{FromBlock: 15, ToBlock: 25}))
+	r.Equal([]string{"alert2", "alert1"}, ids(AlertQuery{To: now.Add(-time.Minute)}))
+	r.Equal([]string{"alert3", "alert2"}, ids(AlertQuery{From: now.Add(-time.Minute)}))
+
+	r.NoError(localStore.PruneAlerts(now.Add(-time.Minute)))
+	r.Equal([]string{"alert3", "alert2"}, ids(AlertQuery{}))
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// StoredBatch is an alert batch which is persisted until it is published.
+type StoredBatch struct {
+	ID    string
+	Batch *protocol.AlertBatch
+}
+
+// BatchStore persists the alert batches which could not be published yet
+// so that they can be published again after restarts.
+type BatchStore interface {
+	Put(batch *protocol.AlertBatch) (string, error)
+	List() ([]*StoredBatch, error)
+	Delete(id string) error
+}
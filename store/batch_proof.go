@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	prefixBatchProof = "batch-proof/"
+	prefixAlertProof = "alert-proof/"
+)
+
+// BatchProof keeps the alert IDs of a published batch with the signed receipt of their Merkle root,
+// so that the inclusion proofs of the alerts can be generated later.
+type BatchProof struct {
+	Batch string `json:"batch"`
+	// the encoded receipt which the signature is for
+	Receipt   string              `json:"receipt"`
+	Signature *protocol.Signature `json:"signature"`
+	AlertIDs  []string            `json:"alertIds"`
+	Timestamp time.Time           `json:"timestamp"`
+}
+
+// BatchProofStore keeps the batch proofs by the alert IDs.
+type BatchProofStore interface {
+	PutBatchProof(proof *BatchProof) error
+	// GetBatchProof returns the proof of the batch which includes the alert.
+	GetBatchProof(alertID string) (*BatchProof, bool, error)
+	PruneBatchProofs(before time.Time) error
+}
+
+func batchProofKey(t time.Time, batch string) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", prefixBatchProof, t.UnixNano(), batch))
+}
+
+// PutBatchProof stores the batch proof and indexes it by its alerts.
+func (ls *localStore) PutBatchProof(proof *BatchProof) error {
+	b, err := json.Marshal(proof)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch proof: %v", err)
+	}
+	key := batchProofKey(proof.Timestamp, proof.Batch)
+	var batch leveldb.Batch
+	batch.Put(key, b)
+	for _, alertID := range proof.AlertIDs {
+		batch.Put([]byte(prefixAlertProof+alertID), key)
+	}
+	return ls.db.Write(&batch, nil)
+}
+
+// GetBatchProof finds the batch proof from the alert ID.
+func (ls *localStore) GetBatchProof(alertID string) (*BatchProof, bool, error) {
+	key, err := ls.db.Get([]byte(prefixAlertProof+alertID), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get alert proof: %v", err)
+	}
+	b, err := ls.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get batch proof: %v", err)
+	}
+	var proof BatchProof
+	if err := json.Unmarshal(b, &proof); err != nil {
+		return nil, false, fmt.Errorf("failed to decode batch proof: %v", err)
+	}
+	return &proof, true, nil
+}
+
+// PruneBatchProofs deletes the batch proofs which are older than the given time with their alert
+// indexes.
+func (ls *localStore) PruneBatchProofs(before time.Time) error {
+	iter := ls.db.NewIterator(&util.Range{
+		Start: []byte(prefixBatchProof),
+		Limit: batchProofKey(before, ""),
+	}, nil)
+	defer iter.Release()
+
+	var batch leveldb.Batch
+	for iter.Next() {
+		var proof BatchProof
+		if err := json.Unmarshal(iter.Value(), &proof); err == nil {
+			for _, alertID := range proof.AlertIDs {
+				batch.Delete([]byte(prefixAlertProof + alertID))
+			}
+		}
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate batch proofs: %v", err)
+	}
+	return ls.db.Write(&batch, nil)
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_BatchProofs(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	now := time.Now().UTC()
+	r.NoError(localStore.PutBatchProof(&BatchProof{Batch: "batch1", AlertIDs: []string{"alert1", "alert2"}, Timestamp: now.Add(-time.Hour)}))
+	r.NoError(localStore.PutBatchProof(&BatchProof{Batch: "batch2", AlertIDs: []string{"alert3"}, Timestamp: now}))
+
+	proof, ok, err := localStore.GetBatchProof("alert2")
+	r.NoError(err)
+	r.True(ok)
+	r.Equal("batch1", proof.Batch)
+	r.Equal([]string{"alert1", "alert2"}, proof.AlertIDs)
+
+	_, ok, err = localStore.GetBatchProof("alert4")
+	r.NoError(err)
+	r.False(ok)
+
+	r.NoError(localStore.PruneBatchProofs(now.Add(-time.Minute)))
+	_, ok, err = localStore.GetBatchProof("alert1")
+	r.NoError(err)
+	r.False(ok)
+	proof, ok, err = localStore.GetBatchProof("alert3")
+	r.NoError(err)
+	r.True(ok)
+	r.Equal("batch2", proof.Batch)
+}
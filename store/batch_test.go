@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_Batches(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+	localStore.maxBatches = 2
+
+	batches, err := localStore.List()
+	r.NoError(err)
+	r.Empty(batches)
+
+	id1, err := localStore.Put(&protocol.AlertBatch{BlockStart: 1})
+	r.NoError(err)
+	_, err = localStore.Put(&protocol.AlertBatch{BlockStart: 2})
+	r.NoError(err)
+	id3, err := localStore.Put(&protocol.AlertBatch{BlockStart: 3})
+	r.NoError(err)
+
+	// the oldest one is deleted
+	batches, err = localStore.List()
+	r.NoError(err)
+	r.Len(batches, 2)
+	r.Equal(uint64(2), batches[0].Batch.BlockStart)
+	r.Equal(uint64(3), batches[1].Batch.BlockStart)
+	r.NotEqual(id1, batches[0].ID)
+
+	r.NoError(localStore.Delete(id3))
+	r.NoError(localStore.Delete(id1)) // already deleted
+	batches, err = localStore.List()
+	r.NoError(err)
+	r.Len(batches, 1)
+	r.Equal(uint64(2), batches[0].Batch.BlockStart)
+}
@@ -2,10 +2,21 @@ package store
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/ipfs"
 	"github.com/forta-network/forta-core-go/manifest"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -13,15 +24,36 @@ const (
 	botManifestExpiry = time.Hour * 6
 )
 
+// ErrUnknownBotDeveloper is returned when the manifest is signed by a developer who is not allowed.
+var ErrUnknownBotDeveloper = errors.New("bot developer is not allowed")
+
 // BotManifestStore loads bot manifests.
 type BotManifestStore interface {
 	GetBotManifest(ctx context.Context, ref string) (*manifest.SignedAgentManifest, error)
+	GetBotFilters(ctx context.Context, ref string) (*config.BotFilters, error)
+	GetBotManifestData(ctx context.Context, ref string) ([]byte, error)
 }
 
 type botManifestStore struct {
 	manifestCache  *cache.Cache
 	manifestClient manifest.Client
-	maxRetries     int
+	// reads the node-side extensions of the manifests - nil if not used
+	ipfsClient ipfs.Client
+	maxRetries int
+}
+
+// botManifestData keeps the manifest data as it is in the manifest file, since the developers sign
+// the data and not the decoded manifest.
+type botManifestData struct {
+	Manifest json.RawMessage `json:"manifest"`
+}
+
+// botManifestFilters is the "filters" extension of the manifest schema. The filters are in the
+// content-addressed manifest file but they are not a part of the signed manifest data.
+type botManifestFilters struct {
+	Manifest *struct {
+		Filters *config.BotFilters `json:"filters"`
+	} `json:"manifest"`
 }
 
 var _ BotManifestStore = &botManifestStore{}
@@ -35,6 +67,12 @@ func NewBotManifestStore(manifestClient manifest.Client) *botManifestStore {
 	}
 }
 
+// WithFilters makes the store read the subscription filters from the manifests in the IPFS.
+func (bms *botManifestStore) WithFilters(ipfsClient ipfs.Client) *botManifestStore {
+	bms.ipfsClient = ipfsClient
+	return bms
+}
+
 func (bms *botManifestStore) GetBotManifest(ctx context.Context, ref string) (*manifest.SignedAgentManifest, error) {
 	cachedManifest, ok := bms.manifestCache.Get(ref)
 	if ok {
@@ -60,3 +98,148 @@ func (bms *botManifestStore) GetBotManifest(ctx context.Context, ref string) (*m
 	bms.manifestCache.Set(ref, loadedManifest, 0)
 	return loadedManifest, err
 }
+
+// GetBotFilters returns the subscription filters which are declared in the manifest. It returns
+// nil if the manifest does not declare any filters.
+func (bms *botManifestStore) GetBotFilters(ctx context.Context, ref string) (*config.BotFilters, error) {
+	if bms.ipfsClient == nil {
+		return nil, nil
+	}
+	cacheKey := "filters:" + ref
+	if cachedFilters, ok := bms.manifestCache.Get(cacheKey); ok {
+		bms.manifestCache.Set(cacheKey, cachedFilters, 0)
+		return cachedFilters.(*config.BotFilters), nil
+	}
+
+	var doc botManifestFilters
+	if err := bms.ipfsClient.UnmarshalJson(ctx, ref, &doc); err != nil {
+		return nil, fmt.Errorf("failed to load the bot manifest filters: %v", err)
+	}
+	var filters *config.BotFilters
+	if doc.Manifest != nil {
+		filters = doc.Manifest.Filters
+	}
+	bms.manifestCache.Set(cacheKey, filters, 0)
+	return filters, nil
+}
+
+// GetBotManifestData returns the signed manifest data exactly as it is in the manifest file.
+func (bms *botManifestStore) GetBotManifestData(ctx context.Context, ref string) ([]byte, error) {
+	if bms.ipfsClient == nil {
+		return nil, errors.New("the manifest data is not available without the ipfs client")
+	}
+	cacheKey := "data:" + ref
+	if cachedData, ok := bms.manifestCache.Get(cacheKey); ok {
+		bms.manifestCache.Set(cacheKey, cachedData, 0)
+		return cachedData.([]byte), nil
+	}
+
+	b, err := bms.ipfsClient.GetBytes(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the bot manifest data: %v", err)
+	}
+	var doc botManifestData
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode the bot manifest data: %v", err)
+	}
+	if len(doc.Manifest) == 0 {
+		return nil, errors.New("manifest is not present")
+	}
+	bms.manifestCache.Set(cacheKey, []byte(doc.Manifest), 0)
+	return doc.Manifest, nil
+}
+
+// validateBotManifest validates the manifest schema, checks if the manifest belongs to the bot
+// and optionally verifies the developer signature over the signed manifest data.
+func validateBotManifest(botID string, signedManifest *manifest.SignedAgentManifest, manifestData []byte, verifySignature bool) error {
+	if err := signedManifest.Validate(); err != nil {
+		return err
+	}
+
+	botManifest := signedManifest.Manifest
+	if botManifest.AgentIDHash != nil && utils.IsValidBotID(botID) && !strings.EqualFold(*botManifest.AgentIDHash, botID) {
+		return fmt.Errorf("manifest belongs to bot '%s'", *botManifest.AgentIDHash)
+	}
+
+	if !verifySignature {
+		return nil
+	}
+	return verifyBotManifestSignature(signedManifest, manifestData)
+}
+
+// verifyBotManifestSignature verifies the signature over the manifest data from the manifest file,
+// since encoding the decoded manifest again does not produce the same data. The manifest data is
+// loaded separately from the decoded manifest, so the fields which the node uses from the decoded
+// manifest must be the same in the signed data.
+func verifyBotManifestSignature(signedManifest *manifest.SignedAgentManifest, manifestData []byte) error {
+	botManifest := signedManifest.Manifest
+	if botManifest.From == nil || len(signedManifest.Signature) == 0 {
+		return security.ErrMissingSignature
+	}
+	if len(manifestData) == 0 {
+		return errors.New("signed manifest data is not available")
+	}
+	var signed manifest.AgentManifest
+	if err := json.Unmarshal(manifestData, &signed); err != nil {
+		return fmt.Errorf("failed to decode the signed manifest data: %v", err)
+	}
+	if signed.From == nil || !strings.EqualFold(*signed.From, *botManifest.From) {
+		return security.ErrInvalidSignature
+	}
+	if !sameManifestValue(signed.ImageReference, botManifest.ImageReference, false) ||
+		!sameManifestValue(signed.AgentIDHash, botManifest.AgentIDHash, true) {
+		return fmt.Errorf("%w: manifest differs from the signed data", security.ErrInvalidSignature)
+	}
+	sigHex, err := normalizeSignature(signedManifest.Signature)
+	if err != nil {
+		return err
+	}
+	return security.VerifySignature(manifestData, common.HexToAddress(*botManifest.From).Hex(), sigHex)
+}
+
+func sameManifestValue(a, b *string, ignoreCase bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if ignoreCase {
+		return strings.EqualFold(*a, *b)
+	}
+	return *a == *b
+}
+
+// normalizeSignature converts the recovery ID of the signatures from the Ethereum tools (27 or 28)
+// to the recovery ID which the signature recovery expects (0 or 1).
+func normalizeSignature(sigHex string) (string, error) {
+	sig, err := hexutil.Decode("0x" + strings.TrimPrefix(sigHex, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %v", err)
+	}
+	if len(sig) == crypto.SignatureLength && sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// checkBotDeveloper verifies that the manifest is signed by one of the allowed developers or, if
+// the owners are allowed, by the owner of the bot. The signature verification makes sure that the
+// image of the decoded manifest is the signed image of the developer.
+func checkBotDeveloper(
+	signedManifest *manifest.SignedAgentManifest, manifestData []byte, owner string, allowlist config.DeveloperAllowlistConfig,
+) error {
+	if !allowlist.Enabled() {
+		return nil
+	}
+	if err := verifyBotManifestSignature(signedManifest, manifestData); err != nil {
+		return err
+	}
+	developer := *signedManifest.Manifest.From
+	if allowlist.AllowBotOwners && len(owner) > 0 && strings.EqualFold(developer, owner) {
+		return nil
+	}
+	for _, allowed := range allowlist.Allowed {
+		if strings.EqualFold(developer, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownBotDeveloper, developer)
+}
@@ -1,12 +1,19 @@
 package store
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"os"
+	"strings"
 	"testing"
 
+	mock_ipfs "github.com/forta-network/forta-core-go/ipfs/mocks"
 	"github.com/forta-network/forta-core-go/manifest"
 	mock_manifest "github.com/forta-network/forta-core-go/manifest/mocks"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/config"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +58,153 @@ func TestBotManifestStore(t *testing.T) {
 	r.NoError(err)
 	r.Equal(testManifest, manifest)
 }
+
+func TestBotManifestStore_Filters(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	ipfsClient := mock_ipfs.NewMockClient(ctrl)
+	manifestStore := NewBotManifestStore(mock_manifest.NewMockClient(ctrl))
+	testManifestRef := "test-manifest-ref"
+
+	// no filters without the ipfs client
+	filters, err := manifestStore.GetBotFilters(context.Background(), testManifestRef)
+	r.NoError(err)
+	r.Nil(filters)
+
+	manifestStore.WithFilters(ipfsClient)
+	ipfsClient.EXPECT().UnmarshalJson(gomock.Any(), testManifestRef, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, ref string, target interface{}) error {
+			return json.Unmarshal([]byte(`{"manifest":{"filters":{"eventTypes":["tx"],"addresses":["0x1"]}},"signature":""}`), target)
+		},
+	)
+	filters, err = manifestStore.GetBotFilters(context.Background(), testManifestRef)
+	r.NoError(err)
+	r.Equal(&config.BotFilters{EventTypes: []string{"tx"}, Addresses: []string{"0x1"}}, filters)
+
+	// cached
+	filters, err = manifestStore.GetBotFilters(context.Background(), testManifestRef)
+	r.NoError(err)
+	r.NotNil(filters)
+}
+
+// testManifestDeveloper signed the test manifest file outside of the tests. The manifest data in the
+// file is not in the field order of the manifest struct and leaves out the empty fields.
+const testManifestDeveloper = "0xb875Ab388e89E95Fc0568731bd88BC64D3D1cB2F"
+
+// loadTestManifest loads the signed test manifest like the manifest store.
+func loadTestManifest(t *testing.T) (*manifest.SignedAgentManifest, []byte) {
+	b, err := os.ReadFile("testdata/bot_manifest.json")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	ipfsClient := mock_ipfs.NewMockClient(ctrl)
+	ipfsClient.EXPECT().GetBytes(gomock.Any(), "test-manifest-ref").Return(b, nil)
+	manifestData, err := NewBotManifestStore(mock_manifest.NewMockClient(ctrl)).WithFilters(ipfsClient).
+		GetBotManifestData(context.Background(), "test-manifest-ref")
+	require.NoError(t, err)
+
+	var signedManifest manifest.SignedAgentManifest
+	require.NoError(t, json.Unmarshal(b, &signedManifest))
+	return &signedManifest, manifestData
+}
+
+func TestBotManifestStore_Data(t *testing.T) {
+	r := require.New(t)
+
+	signedManifest, manifestData := loadTestManifest(t)
+	r.True(bytes.HasPrefix(manifestData, []byte(`{"from":"`+testManifestDeveloper+`","name":"Test Bot"`)))
+	r.True(bytes.HasSuffix(manifestData, []byte(`"chainIds":[1]}`)))
+
+	// encoding the decoded manifest again produces different data
+	reencoded, err := json.Marshal(signedManifest.Manifest)
+	r.NoError(err)
+	r.NotEqual(manifestData, reencoded)
+	r.Error(verifyBotManifestSignature(signedManifest, reencoded))
+
+	// not available without the ipfs client
+	_, err = NewBotManifestStore(mock_manifest.NewMockClient(gomock.NewController(t))).GetBotManifestData(context.Background(), "ref")
+	r.Error(err)
+}
+
+func TestValidateBotManifest(t *testing.T) {
+	r := require.New(t)
+
+	signedManifest, manifestData := loadTestManifest(t)
+	botID := "0x1d646c4045189991fdfd24a66b192a294158b839a6ec121d740474bdacb3ab23"
+
+	r.NoError(validateBotManifest(botID, signedManifest, manifestData, true))
+	r.Error(validateBotManifest("0x1d646c4045189991fdfd24a66b192a294158b839a6ec121d740474bdacb3ab24", signedManifest, manifestData, true))
+
+	// the data must be the signed data
+	tampered := bytes.Replace(manifestData, []byte("0.0.1"), []byte("0.0.2"), 1)
+	r.ErrorIs(validateBotManifest(botID, signedManifest, tampered, true), security.ErrInvalidSignature)
+	r.Error(validateBotManifest(botID, signedManifest, nil, true))
+
+	// the signer must be the developer in the data
+	otherDeveloper := "0x00000000000000000000000000000000000000aa"
+	otherManifest := *signedManifest.Manifest
+	otherManifest.From = &otherDeveloper
+	r.ErrorIs(validateBotManifest(botID, &manifest.SignedAgentManifest{
+		Manifest: &otherManifest, Signature: signedManifest.Signature,
+	}, manifestData, true), security.ErrInvalidSignature)
+
+	// the manifest must be the signed manifest
+	otherImage := "bafybeibvkqkf7i6a5j7o6kh3bmxmrvigsthn6vwnxkma5cpqozwl3iizzq@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	otherManifest = *signedManifest.Manifest
+	otherManifest.ImageReference = &otherImage
+	r.ErrorIs(validateBotManifest(botID, &manifest.SignedAgentManifest{
+		Manifest: &otherManifest, Signature: signedManifest.Signature,
+	}, manifestData, true), security.ErrInvalidSignature)
+	otherManifest = *signedManifest.Manifest
+	otherManifest.AgentIDHash = nil
+	r.ErrorIs(validateBotManifest(botID, &manifest.SignedAgentManifest{
+		Manifest: &otherManifest, Signature: signedManifest.Signature,
+	}, manifestData, true), security.ErrInvalidSignature)
+
+	// signature is checked only if enabled
+	unsignedManifest := &manifest.SignedAgentManifest{Manifest: signedManifest.Manifest}
+	r.NoError(validateBotManifest(botID, unsignedManifest, nil, false))
+	r.ErrorIs(validateBotManifest(botID, unsignedManifest, manifestData, true), security.ErrMissingSignature)
+
+	// schema is validated
+	r.Error(validateBotManifest(botID, &manifest.SignedAgentManifest{}, nil, false))
+}
+
+func TestCheckBotDeveloper(t *testing.T) {
+	r := require.New(t)
+
+	signedManifest, manifestData := loadTestManifest(t)
+	developer := testManifestDeveloper
+	otherDeveloper := "0x00000000000000000000000000000000000000aa"
+
+	// not checked if no developers are allowed
+	r.NoError(checkBotDeveloper(&manifest.SignedAgentManifest{Manifest: signedManifest.Manifest}, nil, "", config.DeveloperAllowlistConfig{}))
+
+	r.NoError(checkBotDeveloper(signedManifest, manifestData, "", config.DeveloperAllowlistConfig{
+		Allowed: []string{otherDeveloper, strings.ToLower(developer)},
+	}))
+	r.ErrorIs(checkBotDeveloper(signedManifest, manifestData, "", config.DeveloperAllowlistConfig{
+		Allowed: []string{otherDeveloper},
+	}), ErrUnknownBotDeveloper)
+
+	// the owner of the bot is allowed only if enabled
+	ownerAllowlist := config.DeveloperAllowlistConfig{AllowBotOwners: true}
+	r.NoError(checkBotDeveloper(signedManifest, manifestData, developer, ownerAllowlist))
+	r.ErrorIs(checkBotDeveloper(signedManifest, manifestData, otherDeveloper, ownerAllowlist), ErrUnknownBotDeveloper)
+	r.ErrorIs(checkBotDeveloper(signedManifest, manifestData, developer, config.DeveloperAllowlistConfig{
+		Allowed: []string{otherDeveloper},
+	}), ErrUnknownBotDeveloper)
+
+	// the unsigned bots are refused
+	unsignedManifest := &manifest.SignedAgentManifest{Manifest: signedManifest.Manifest}
+	r.ErrorIs(checkBotDeveloper(unsignedManifest, manifestData, developer, ownerAllowlist), security.ErrMissingSignature)
+
+	// the image of an allowed developer cannot be replaced in the manifest
+	otherImage := "bafybeibvkqkf7i6a5j7o6kh3bmxmrvigsthn6vwnxkma5cpqozwl3iizzq@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	otherManifest := *signedManifest.Manifest
+	otherManifest.ImageReference = &otherImage
+	r.ErrorIs(checkBotDeveloper(&manifest.SignedAgentManifest{
+		Manifest: &otherManifest, Signature: signedManifest.Signature,
+	}, manifestData, developer, ownerAllowlist), security.ErrInvalidSignature)
+}
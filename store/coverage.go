@@ -0,0 +1,167 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultMaxCoverageReports is the default limit of the coverage reports in the store.
+const DefaultMaxCoverageReports = 168
+
+const coverageReportFileExt = ".json"
+
+// CoverageReport tells which blocks the node saw, processed and dispatched on each chain within
+// the period of the report and which of the dispatched blocks each bot acknowledged.
+type CoverageReport struct {
+	ID     string           `json:"id"`
+	Start  time.Time        `json:"start"`
+	End    time.Time        `json:"end"`
+	Chains []*ChainCoverage `json:"chains"`
+}
+
+// ChainCoverage is the coverage of a chain. The processed blocks are the blocks which were
+// sent to the bots after skipping the duplicate blocks and the blocks of the other shards.
+type ChainCoverage struct {
+	ChainID         uint64           `json:"chainId"`
+	Seen            uint64           `json:"seen"`
+	Processed       uint64           `json:"processed"`
+	ProcessedBlocks []BlockRange     `json:"processedBlocks"`
+	Agents          []*AgentCoverage `json:"agents"`
+}
+
+// AgentCoverage is the coverage of a bot on a chain. The bots acknowledge the blocks by
+// responding to the block requests successfully.
+type AgentCoverage struct {
+	ID                   string       `json:"id"`
+	Dispatched           uint64       `json:"dispatched"`
+	Acknowledged         uint64       `json:"acknowledged"`
+	AcknowledgedBlocks   []BlockRange `json:"acknowledgedBlocks"`
+	UnacknowledgedBlocks []BlockRange `json:"unacknowledgedBlocks"`
+}
+
+// BlockRange is an inclusive range of block numbers.
+type BlockRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// CoverageStore keeps the latest coverage reports.
+type CoverageStore interface {
+	PutCoverageReport(report *CoverageReport) error
+	ListCoverageReports() ([]*CoverageReport, error)
+}
+
+// coverageStore keeps each report in a separate file in the directory, so that the reports
+// can be read while the node is running.
+type coverageStore struct {
+	dir        string
+	maxReports int
+	mu         sync.Mutex
+}
+
+// NewCoverageStore creates a new coverage store in the given directory. The oldest reports are
+// deleted when the store has more reports than the limit.
+func NewCoverageStore(dir string, maxReports int) *coverageStore {
+	if maxReports <= 0 {
+		maxReports = DefaultMaxCoverageReports
+	}
+	return &coverageStore{dir: dir, maxReports: maxReports}
+}
+
+// PutCoverageReport saves the report and deletes the oldest reports above the limit.
+func (cs *coverageStore) PutCoverageReport(report *CoverageReport) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if len(report.ID) == 0 {
+		report.ID = report.Start.UTC().Format("20060102T150405Z")
+	}
+	if err := os.MkdirAll(cs.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the coverage dir: %v", err)
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode the coverage report: %v", err)
+	}
+	// the report is written to a temporary file first so that the readers never see a partial report
+	tmpPath := path.Join(cs.dir, "."+report.ID+".tmp")
+	if err := os.WriteFile(tmpPath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the coverage report: %v", err)
+	}
+	if err := os.Rename(tmpPath, path.Join(cs.dir, report.ID+coverageReportFileExt)); err != nil {
+		return fmt.Errorf("failed to move the coverage report: %v", err)
+	}
+	return cs.prune()
+}
+
+// ListCoverageReports returns the reports from the oldest to the newest.
+func (cs *coverageStore) ListCoverageReports() ([]*CoverageReport, error) {
+	names, err := cs.reportFiles()
+	if err != nil {
+		return nil, err
+	}
+	var reports []*CoverageReport
+	for _, name := range names {
+		b, err := os.ReadFile(path.Join(cs.dir, name))
+		// the report may be deleted while listing
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the coverage report: %v", err)
+		}
+		var report CoverageReport
+		if err := json.Unmarshal(b, &report); err != nil {
+			log.WithError(err).WithField("file", name).Warn("skipping invalid coverage report")
+			continue
+		}
+		reports = append(reports, &report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Start.Before(reports[j].Start)
+	})
+	return reports, nil
+}
+
+// reportFiles returns the report file names ordered by the report IDs, which are the start times.
+func (cs *coverageStore) reportFiles() ([]string, error) {
+	entries, err := os.ReadDir(cs.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the coverage dir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, coverageReportFileExt) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (cs *coverageStore) prune() error {
+	names, err := cs.reportFiles()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(names)-cs.maxReports; i++ {
+		if err := os.Remove(path.Join(cs.dir, names[i])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete the old coverage report: %v", err)
+		}
+	}
+	return nil
+}
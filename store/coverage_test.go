@@ -0,0 +1,37 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageStore(t *testing.T) {
+	r := require.New(t)
+
+	coverageStore := NewCoverageStore(t.TempDir(), 2)
+	reports, err := coverageStore.ListCoverageReports()
+	r.NoError(err)
+	r.Empty(reports)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		r.NoError(coverageStore.PutCoverageReport(&CoverageReport{
+			Start: start.Add(time.Duration(i) * time.Hour),
+			End:   start.Add(time.Duration(i+1) * time.Hour),
+			Chains: []*ChainCoverage{
+				{ChainID: 1, Seen: uint64(i), ProcessedBlocks: []BlockRange{{From: 10, To: 12}}},
+			},
+		}))
+	}
+
+	// the oldest report should be deleted
+	reports, err = coverageStore.ListCoverageReports()
+	r.NoError(err)
+	r.Len(reports, 2)
+	r.Equal("20230101T010000Z", reports[0].ID)
+	r.Equal(uint64(1), reports[0].Chains[0].Seen)
+	r.Equal("20230101T020000Z", reports[1].ID)
+	r.Equal([]BlockRange{{From: 10, To: 12}}, reports[1].Chains[0].ProcessedBlocks)
+}
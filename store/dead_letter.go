@@ -0,0 +1,211 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultMaxDeadLetters is the default limit of the dead letters in the store.
+const DefaultMaxDeadLetters = 10000
+
+const deadLetterFileExt = ".json"
+
+// ErrDeadLetterNotFound is returned when there is no dead letter with the given ID.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is an evaluation request which could not be delivered to a bot.
+type DeadLetter struct {
+	ID        string          `json:"id"`
+	BotID     string          `json:"botId"`
+	BotImage  string          `json:"botImage"`
+	Method    string          `json:"method"`
+	Reason    string          `json:"reason"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Request   json.RawMessage `json:"request"`
+	// Replay is set to request the node to send the request to the bot again.
+	Replay bool `json:"replay"`
+}
+
+// DeadLetterStore keeps the undeliverable requests so that they can be inspected and replayed.
+type DeadLetterStore interface {
+	Put(letter *DeadLetter) error
+	Get(id string) (*DeadLetter, error)
+	List() ([]*DeadLetter, error)
+	MarkReplay(id string) error
+	Delete(id string) error
+}
+
+// deadLetterStore keeps each dead letter in a separate file in the directory so that both the node
+// and the CLI can use the store at the same time.
+type deadLetterStore struct {
+	dir        string
+	maxLetters int
+	// the approximate amount of letters which is counted once and recounted after pruning
+	count   int
+	counted bool
+	mu      sync.Mutex
+}
+
+// NewDeadLetterStore creates a new dead letter store in the given directory. The oldest letters are
+// deleted when the store has more letters than the limit.
+func NewDeadLetterStore(dir string, maxLetters int) *deadLetterStore {
+	if maxLetters <= 0 {
+		maxLetters = DefaultMaxDeadLetters
+	}
+	return &deadLetterStore{dir: dir, maxLetters: maxLetters}
+}
+
+// Put saves a new dead letter.
+func (dls *deadLetterStore) Put(letter *DeadLetter) error {
+	dls.mu.Lock()
+	defer dls.mu.Unlock()
+
+	if len(letter.ID) == 0 {
+		letter.ID = uuid.Must(uuid.NewUUID()).String()
+	}
+	if letter.CreatedAt.IsZero() {
+		letter.CreatedAt = time.Now().UTC()
+	}
+	// the letters contain the requests of the bots
+	if err := makePrivateDir(dls.dir); err != nil {
+		return fmt.Errorf("failed to create the dead letter dir: %v", err)
+	}
+	if !dls.counted {
+		entries, err := os.ReadDir(dls.dir)
+		if err != nil {
+			return fmt.Errorf("failed to read the dead letter dir: %v", err)
+		}
+		dls.count = len(entries)
+		dls.counted = true
+	}
+	if err := dls.write(letter); err != nil {
+		return err
+	}
+	dls.count++
+	if dls.count <= dls.maxLetters {
+		return nil
+	}
+	return dls.prune()
+}
+
+// Get returns the dead letter with the given ID.
+func (dls *deadLetterStore) Get(id string) (*DeadLetter, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, ErrDeadLetterNotFound
+	}
+	b, err := os.ReadFile(dls.filePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the dead letter: %v", err)
+	}
+	var letter DeadLetter
+	if err := json.Unmarshal(b, &letter); err != nil {
+		return nil, fmt.Errorf("failed to decode the dead letter: %v", err)
+	}
+	return &letter, nil
+}
+
+// List returns all dead letters from the oldest to the newest.
+func (dls *deadLetterStore) List() ([]*DeadLetter, error) {
+	entries, err := os.ReadDir(dls.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the dead letter dir: %v", err)
+	}
+	var letters []*DeadLetter
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, deadLetterFileExt) {
+			continue
+		}
+		letter, err := dls.Get(strings.TrimSuffix(name, deadLetterFileExt))
+		// the letter may be deleted while listing
+		if err == ErrDeadLetterNotFound {
+			continue
+		}
+		if err != nil {
+			log.WithError(err).WithField("file", name).Warn("skipping invalid dead letter")
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	sort.SliceStable(letters, func(i, j int) bool {
+		return letters[i].CreatedAt.Before(letters[j].CreatedAt)
+	})
+	return letters, nil
+}
+
+// MarkReplay requests the node to replay the dead letter.
+func (dls *deadLetterStore) MarkReplay(id string) error {
+	dls.mu.Lock()
+	defer dls.mu.Unlock()
+
+	letter, err := dls.Get(id)
+	if err != nil {
+		return err
+	}
+	letter.Replay = true
+	return dls.write(letter)
+}
+
+// Delete deletes the dead letter.
+func (dls *deadLetterStore) Delete(id string) error {
+	if strings.ContainsAny(id, `/\`) {
+		return ErrDeadLetterNotFound
+	}
+	err := os.Remove(dls.filePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete the dead letter: %v", err)
+	}
+	return nil
+}
+
+func (dls *deadLetterStore) filePath(id string) string {
+	return path.Join(dls.dir, id+deadLetterFileExt)
+}
+
+func (dls *deadLetterStore) write(letter *DeadLetter) error {
+	b, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode the dead letter: %v", err)
+	}
+	if err := writePrivateFile(dls.filePath(letter.ID), b); err != nil {
+		return fmt.Errorf("failed to save the dead letter: %v", err)
+	}
+	return nil
+}
+
+// prune deletes the oldest letters until a tenth of the limit is free, so that the letters
+// are not listed with every new letter.
+func (dls *deadLetterStore) prune() error {
+	letters, err := dls.List()
+	if err != nil {
+		return err
+	}
+	keep := dls.maxLetters - dls.maxLetters/10
+	var deleted int
+	for ; deleted < len(letters)-keep; deleted++ {
+		if err := dls.Delete(letters[deleted].ID); err != nil && err != ErrDeadLetterNotFound {
+			return err
+		}
+	}
+	dls.count = len(letters) - deleted
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := path.Join(t.TempDir(), "dead-letters")
+	dls := NewDeadLetterStore(dir, 0)
+
+	letters, err := dls.List()
+	r.NoError(err)
+	r.Empty(letters)
+
+	letter := &DeadLetter{
+		BotID:   "0x1",
+		Method:  "/network.forta.Agent/EvaluateTx",
+		Reason:  "bot circuit is open",
+		Request: json.RawMessage(`{"requestId":"1"}`),
+	}
+	r.NoError(dls.Put(letter))
+	r.NotEmpty(letter.ID)
+
+	// only the owner can read the letters
+	info, err := os.Stat(dir)
+	r.NoError(err)
+	r.Equal(privateDirPerm, info.Mode().Perm())
+	info, err = os.Stat(dls.filePath(letter.ID))
+	r.NoError(err)
+	r.Equal(privateFilePerm, info.Mode().Perm())
+
+	// another store in the same dir sees the letter
+	letters, err = NewDeadLetterStore(dir, 0).List()
+	r.NoError(err)
+	r.Len(letters, 1)
+	r.Equal(letter.ID, letters[0].ID)
+	r.JSONEq(`{"requestId":"1"}`, string(letters[0].Request))
+	r.False(letters[0].Replay)
+
+	r.NoError(dls.MarkReplay(letter.ID))
+	found, err := dls.Get(letter.ID)
+	r.NoError(err)
+	r.True(found.Replay)
+
+	r.NoError(dls.Delete(letter.ID))
+	_, err = dls.Get(letter.ID)
+	r.Equal(ErrDeadLetterNotFound, err)
+	r.Equal(ErrDeadLetterNotFound, dls.Delete(letter.ID))
+	r.Equal(ErrDeadLetterNotFound, dls.MarkReplay("../"+letter.ID))
+}
+
+func TestDeadLetterStore_Prune(t *testing.T) {
+	r := require.New(t)
+
+	dls := NewDeadLetterStore(t.TempDir(), 10)
+
+	start := time.Now().UTC()
+	for i := 0; i < 11; i++ {
+		r.NoError(dls.Put(&DeadLetter{BotID: "0x1", CreatedAt: start.Add(time.Duration(i) * time.Second)}))
+	}
+
+	// the oldest letters are deleted to free a tenth of the limit
+	letters, err := dls.List()
+	r.NoError(err)
+	r.Len(letters, 9)
+	r.Equal(start.Add(2*time.Second), letters[0].CreatedAt)
+	r.Equal(start.Add(10*time.Second), letters[8].CreatedAt)
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const prefixDispatch = "dispatch/"
+
+// DispatchStore keeps the hashes of the recently dispatched blocks per feed.
+type DispatchStore interface {
+	// PutDispatched returns false if the block was already dispatched.
+	PutDispatched(feed, blockHash string, blockNumber uint64, t time.Time) (bool, error)
+	// ForgetDispatched lets the blocks from the given block number be dispatched again.
+	ForgetDispatched(feed string, fromBlock uint64) error
+	PruneDispatched(before time.Time) error
+}
+
+func dispatchKey(feed, blockHash string) []byte {
+	return []byte(prefixDispatch + feed + "/" + blockHash)
+}
+
+// PutDispatched saves the block as dispatched unless it was already dispatched.
+func (ls *localStore) PutDispatched(feed, blockHash string, blockNumber uint64, t time.Time) (bool, error) {
+	ls.dispatchMu.Lock()
+	defer ls.dispatchMu.Unlock()
+
+	key := dispatchKey(feed, blockHash)
+	ok, err := ls.db.Has(key, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dispatched block: %v", err)
+	}
+	if ok {
+		return false, nil
+	}
+	value := append(encodeUint64(uint64(t.UnixNano())), encodeUint64(blockNumber)...)
+	if err := ls.db.Put(key, value, nil); err != nil {
+		return false, fmt.Errorf("failed to put dispatched block: %v", err)
+	}
+	return true, nil
+}
+
+// ForgetDispatched deletes the dispatched blocks of the feed from the given block number.
+func (ls *localStore) ForgetDispatched(feed string, fromBlock uint64) error {
+	return ls.deleteDispatched([]byte(prefixDispatch+feed+"/"), func(_ time.Time, blockNumber uint64) bool {
+		return blockNumber >= fromBlock
+	})
+}
+
+// PruneDispatched deletes the blocks which were dispatched before the given time.
+func (ls *localStore) PruneDispatched(before time.Time) error {
+	return ls.deleteDispatched([]byte(prefixDispatch), func(dispatchedAt time.Time, _ uint64) bool {
+		return dispatchedAt.Before(before)
+	})
+}
+
+func (ls *localStore) deleteDispatched(prefix []byte, shouldDelete func(time.Time, uint64) bool) error {
+	ls.dispatchMu.Lock()
+	defer ls.dispatchMu.Unlock()
+
+	iter := ls.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	var batch leveldb.Batch
+	for iter.Next() {
+		value := iter.Value()
+		if len(value) != 16 {
+			batch.Delete(append([]byte{}, iter.Key()...))
+			continue
+		}
+		dispatchedAt := time.Unix(0, int64(binary.BigEndian.Uint64(value[:8])))
+		if shouldDelete(dispatchedAt, binary.BigEndian.Uint64(value[8:])) {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate dispatched blocks: %v", err)
+	}
+	return ls.db.Write(&batch, nil)
+}
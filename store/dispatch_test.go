@@ -0,0 +1,49 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_Dispatches(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	now := time.Now()
+	first, err := localStore.PutDispatched("block", "0x1", 1, now.Add(-time.Hour))
+	r.NoError(err)
+	r.True(first)
+	first, err = localStore.PutDispatched("block", "0x1", 1, now)
+	r.NoError(err)
+	r.False(first)
+	// the feeds are separate
+	first, err = localStore.PutDispatched("block-137", "0x1", 1, now)
+	r.NoError(err)
+	r.True(first)
+	_, err = localStore.PutDispatched("block", "0x2", 2, now)
+	r.NoError(err)
+	_, err = localStore.PutDispatched("block", "0x3", 3, now)
+	r.NoError(err)
+
+	// only the blocks of the feed from the block number are forgotten
+	r.NoError(localStore.ForgetDispatched("block", 3))
+	first, err = localStore.PutDispatched("block", "0x3", 3, now)
+	r.NoError(err)
+	r.True(first)
+	first, err = localStore.PutDispatched("block", "0x2", 2, now)
+	r.NoError(err)
+	r.False(first)
+
+	r.NoError(localStore.PruneDispatched(now.Add(-time.Minute)))
+	first, err = localStore.PutDispatched("block", "0x1", 1, now)
+	r.NoError(err)
+	r.True(first)
+	first, err = localStore.PutDispatched("block-137", "0x1", 1, now)
+	r.NoError(err)
+	r.False(first)
+}
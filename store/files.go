@@ -0,0 +1,35 @@
+package store
+
+import (
+	"os"
+	"path"
+)
+
+// the stores in the forta dir which keep the bot data are accessible only by the owner
+const (
+	privateDirPerm  os.FileMode = 0700
+	privateFilePerm os.FileMode = 0600
+)
+
+// makePrivateDir creates the dir which only the owner can access. The permissions of an existing
+// dir are also restricted.
+func makePrivateDir(dir string) error {
+	if err := os.MkdirAll(dir, privateDirPerm); err != nil {
+		return err
+	}
+	return os.Chmod(dir, privateDirPerm)
+}
+
+// writePrivateFile writes the file which only the owner can access. The file is written to a
+// temporary file first so that the readers never see a partial file.
+func writePrivateFile(filePath string, b []byte) error {
+	tmpPath := path.Join(path.Dir(filePath), "."+path.Base(filePath)+".tmp")
+	if err := os.WriteFile(tmpPath, b, privateFilePerm); err != nil {
+		return err
+	}
+	// a temporary file may be left from a crash
+	if err := os.Chmod(tmpPath, privateFilePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	log "github.com/sirupsen/logrus"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"google.golang.org/protobuf/proto"
+)
+
+// Local store key prefixes
+const (
+	prefixCheckpoint = "checkpoint/"
+	prefixAlert      = "alert/"
+	prefixBatch      = "batch/"
+)
+
+// DefaultMaxStoredBatches is the default limit of the unpublished batches in the local store.
+const DefaultMaxStoredBatches = 100
+
+// CheckpointStore keeps the last processed block number per feed.
+type CheckpointStore interface {
+	GetCheckpoint(feed string) (uint64, bool, error)
+	PutCheckpoint(feed string, blockNumber uint64) error
+}
+
+// AlertHistoryStore keeps the last time the alerts were seen.
+type AlertHistoryStore interface {
+	GetAlertTime(key string) (time.Time, bool, error)
+	PutAlertTime(key string, t time.Time) error
+	PruneAlertHistory(before time.Time) error
+}
+
+// LocalStore persists the node state on the disk.
+type LocalStore interface {
+	CheckpointStore
+	AlertHistoryStore
+	BatchStore
+	SnapshotStore
+	AlertStore
+	DispatchStore
+	BatchProofStore
+	Close() error
+}
+
+type localStore struct {
+	db         *leveldb.DB
+	maxBatches int
+	batchMu    sync.Mutex
+	dispatchMu sync.Mutex
+}
+
+// NewLocalStore opens the LevelDB database in the given directory.
+func NewLocalStore(dir string) (*localStore, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local store: %v", err)
+	}
+	return &localStore{db: db, maxBatches: DefaultMaxStoredBatches}, nil
+}
+
+// GetCheckpoint returns the last processed block number of the feed.
+func (ls *localStore) GetCheckpoint(feed string) (uint64, bool, error) {
+	b, err := ls.db.Get([]byte(prefixCheckpoint+feed), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get checkpoint: %v", err)
+	}
+	return binary.BigEndian.Uint64(b), true, nil
+}
+
+// PutCheckpoint stores the last processed block number of the feed.
+func (ls *localStore) PutCheckpoint(feed string, blockNumber uint64) error {
+	return ls.db.Put([]byte(prefixCheckpoint+feed), encodeUint64(blockNumber), nil)
+}
+
+// GetAlertTime returns the last time the alert was seen.
+func (ls *localStore) GetAlertTime(key string) (time.Time, bool, error) {
+	b, err := ls.db.Get([]byte(prefixAlert+key), nil)
+	if err == leveldb.ErrNotFound {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get alert time: %v", err)
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b))), true, nil
+}
+
+// PutAlertTime stores the last time the alert was seen.
+func (ls *localStore) PutAlertTime(key string, t time.Time) error {
+	return ls.db.Put([]byte(prefixAlert+key), encodeUint64(uint64(t.UnixNano())), nil)
+}
+
+// PruneAlertHistory deletes the alerts which were seen before the given time.
+func (ls *localStore) PruneAlertHistory(before time.Time) error {
+	iter := ls.db.NewIterator(util.BytesPrefix([]byte(prefixAlert)), nil)
+	defer iter.Release()
+
+	var batch leveldb.Batch
+	for iter.Next() {
+		if int64(binary.BigEndian.Uint64(iter.Value())) < before.UnixNano() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("failed to iterate alert history: %v", err)
+	}
+	return ls.db.Write(&batch, nil)
+}
+
+// Put stores the batch and returns the ID. The oldest batches are deleted when
+// there are more than the max batches.
+func (ls *localStore) Put(batch *protocol.AlertBatch) (string, error) {
+	ls.batchMu.Lock()
+	defer ls.batchMu.Unlock()
+
+	b, err := proto.Marshal(batch)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal batch: %v", err)
+	}
+	// sortable by time
+	id := fmt.Sprintf("%020d", time.Now().UnixNano())
+	if err := ls.db.Put([]byte(prefixBatch+id), b, nil); err != nil {
+		return "", fmt.Errorf("failed to put batch: %v", err)
+	}
+
+	ids, err := ls.listBatchIDs()
+	if err != nil {
+		return id, err
+	}
+	for len(ids) > ls.maxBatches {
+		log.WithField("batch", ids[0]).Warn("too many unpublished batches - deleting the oldest")
+		if err := ls.db.Delete([]byte(prefixBatch+ids[0]), nil); err != nil {
+			return id, fmt.Errorf("failed to delete the oldest batch: %v", err)
+		}
+		ids = ids[1:]
+	}
+	return id, nil
+}
+
+// List returns the stored batches from the oldest to the newest.
+func (ls *localStore) List() ([]*StoredBatch, error) {
+	ls.batchMu.Lock()
+	defer ls.batchMu.Unlock()
+
+	iter := ls.db.NewIterator(util.BytesPrefix([]byte(prefixBatch)), nil)
+	defer iter.Release()
+
+	var batches []*StoredBatch
+	for iter.Next() {
+		id := strings.TrimPrefix(string(iter.Key()), prefixBatch)
+		var batch protocol.AlertBatch
+		if err := proto.Unmarshal(iter.Value(), &batch); err != nil {
+			log.WithError(err).WithField("batch", id).Warn("failed to unmarshal stored batch - deleting")
+			_ = ls.db.Delete([]byte(prefixBatch+id), nil)
+			continue
+		}
+		batches = append(batches, &StoredBatch{ID: id, Batch: &batch})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate batches: %v", err)
+	}
+	return batches, nil
+}
+
+// Delete deletes the stored batch.
+func (ls *localStore) Delete(id string) error {
+	ls.batchMu.Lock()
+	defer ls.batchMu.Unlock()
+
+	return ls.db.Delete([]byte(prefixBatch+id), nil)
+}
+
+func (ls *localStore) listBatchIDs() ([]string, error) {
+	iter := ls.db.NewIterator(util.BytesPrefix([]byte(prefixBatch)), nil)
+	defer iter.Release()
+
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, strings.TrimPrefix(string(iter.Key()), prefixBatch))
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate batches: %v", err)
+	}
+	return ids, nil
+}
+
+// Close closes the database.
+func (ls *localStore) Close() error {
+	return ls.db.Close()
+}
+
+func encodeUint64(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_Checkpoints(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	localStore, err := NewLocalStore(dir)
+	r.NoError(err)
+
+	_, ok, err := localStore.GetCheckpoint("block")
+	r.NoError(err)
+	r.False(ok)
+
+	r.NoError(localStore.PutCheckpoint("block", 123))
+	r.NoError(localStore.Close())
+
+	// survives reopening
+	localStore, err = NewLocalStore(dir)
+	r.NoError(err)
+	defer localStore.Close()
+	blockNumber, ok, err := localStore.GetCheckpoint("block")
+	r.NoError(err)
+	r.True(ok)
+	r.Equal(uint64(123), blockNumber)
+}
+
+func TestLocalStore_AlertHistory(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	now := time.Now()
+	r.NoError(localStore.PutAlertTime("old", now.Add(-time.Hour)))
+	r.NoError(localStore.PutAlertTime("new", now))
+
+	seenAt, ok, err := localStore.GetAlertTime("new")
+	r.NoError(err)
+	r.True(ok)
+	r.Equal(now.UnixNano(), seenAt.UnixNano())
+
+	r.NoError(localStore.PruneAlertHistory(now.Add(-time.Minute)))
+	_, ok, err = localStore.GetAlertTime("old")
+	r.NoError(err)
+	r.False(ok)
+	_, ok, err = localStore.GetAlertTime("new")
+	r.NoError(err)
+	r.True(ok)
+}
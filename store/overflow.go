@@ -0,0 +1,184 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+)
+
+// DefaultOverflowSegmentSize is the size of the overflow queue files after which a new file is started.
+const DefaultOverflowSegmentSize = 16 * 1024 * 1024
+
+const overflowRecordHeaderSize = 4
+
+// ErrOverflowFull is returned when the overflow queue has no space for the new item.
+var ErrOverflowFull = errors.New("overflow queue is full")
+
+// ErrOverflowClosed is returned when the overflow queue is used after it is closed.
+var ErrOverflowClosed = errors.New("overflow queue is closed")
+
+// OverflowQueue is a FIFO queue on the disk which keeps the items which do not fit in the memory.
+type OverflowQueue interface {
+	Push(b []byte) error
+	// Pop returns false if the queue is empty.
+	Pop() ([]byte, bool, error)
+	Len() int
+	// Close deletes the queue from the disk.
+	Close() error
+}
+
+type overflowSegment struct {
+	file *os.File
+	size int64
+}
+
+// overflowQueue appends the items to the segment files in the directory and reads them from the
+// oldest segment. A segment is deleted after all of its items are read.
+type overflowQueue struct {
+	dir         string
+	maxBytes    int64
+	segmentSize int64
+
+	segments   []*overflowSegment
+	nextID     int
+	readOffset int64
+	bytes      int64
+	count      int
+	closed     bool
+	mu         sync.Mutex
+}
+
+// NewOverflowQueue creates a new overflow queue in the given directory. The directory is created
+// with the first item. Push fails with ErrOverflowFull if the queue has more than the max bytes.
+func NewOverflowQueue(dir string, maxBytes int64) *overflowQueue {
+	return &overflowQueue{dir: dir, maxBytes: maxBytes, segmentSize: DefaultOverflowSegmentSize}
+}
+
+// ClearOverflowQueues deletes the overflow queues of the previous runs in the directory.
+func ClearOverflowQueues(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear the overflow queues: %v", err)
+	}
+	return nil
+}
+
+// Push appends the item to the queue.
+func (oq *overflowQueue) Push(b []byte) error {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+
+	if oq.closed {
+		return ErrOverflowClosed
+	}
+	recordSize := int64(overflowRecordHeaderSize + len(b))
+	if oq.maxBytes > 0 && oq.bytes+recordSize > oq.maxBytes {
+		return ErrOverflowFull
+	}
+	segment, err := oq.writeSegment()
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordSize)
+	binary.BigEndian.PutUint32(record, uint32(len(b)))
+	copy(record[overflowRecordHeaderSize:], b)
+	if _, err := segment.file.WriteAt(record, segment.size); err != nil {
+		return fmt.Errorf("failed to write the overflow item: %v", err)
+	}
+	segment.size += recordSize
+	oq.bytes += recordSize
+	oq.count++
+	return nil
+}
+
+// writeSegment returns the newest segment or starts a new one if the newest one is full.
+func (oq *overflowQueue) writeSegment() (*overflowSegment, error) {
+	if len(oq.segments) > 0 {
+		segment := oq.segments[len(oq.segments)-1]
+		if segment.size < oq.segmentSize {
+			return segment, nil
+		}
+	}
+	if err := os.MkdirAll(oq.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the overflow dir: %v", err)
+	}
+	file, err := os.OpenFile(path.Join(oq.dir, fmt.Sprintf("%020d.seg", oq.nextID)), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the overflow segment: %v", err)
+	}
+	oq.nextID++
+	segment := &overflowSegment{file: file}
+	oq.segments = append(oq.segments, segment)
+	return segment, nil
+}
+
+// Pop removes and returns the oldest item in the queue.
+func (oq *overflowQueue) Pop() ([]byte, bool, error) {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+
+	if oq.closed {
+		return nil, false, ErrOverflowClosed
+	}
+	if oq.count == 0 {
+		return nil, false, nil
+	}
+	segment := oq.segments[0]
+	var header [overflowRecordHeaderSize]byte
+	if _, err := segment.file.ReadAt(header[:], oq.readOffset); err != nil {
+		return nil, false, fmt.Errorf("failed to read the overflow item header: %v", err)
+	}
+	b := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := segment.file.ReadAt(b, oq.readOffset+overflowRecordHeaderSize); err != nil && err != io.EOF {
+		return nil, false, fmt.Errorf("failed to read the overflow item: %v", err)
+	}
+	recordSize := int64(overflowRecordHeaderSize + len(b))
+	oq.readOffset += recordSize
+	oq.bytes -= recordSize
+	oq.count--
+
+	// drop the segment after reading it unless more items are written to it
+	if oq.readOffset >= segment.size && (len(oq.segments) > 1 || segment.size >= oq.segmentSize) {
+		oq.removeSegment(segment)
+		oq.segments = oq.segments[1:]
+		oq.readOffset = 0
+	}
+	return b, true, nil
+}
+
+// Len returns the amount of the items in the queue.
+func (oq *overflowQueue) Len() int {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+
+	return oq.count
+}
+
+// Close deletes all segments.
+func (oq *overflowQueue) Close() error {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+
+	if oq.closed {
+		return nil
+	}
+	oq.closed = true
+	for _, segment := range oq.segments {
+		oq.removeSegment(segment)
+	}
+	oq.segments = nil
+	oq.count = 0
+	oq.bytes = 0
+	if err := os.RemoveAll(oq.dir); err != nil {
+		return fmt.Errorf("failed to delete the overflow dir: %v", err)
+	}
+	return nil
+}
+
+func (oq *overflowQueue) removeSegment(segment *overflowSegment) {
+	_ = segment.file.Close()
+	_ = os.Remove(segment.file.Name())
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverflowQueue(t *testing.T) {
+	r := require.New(t)
+
+	dir := path.Join(t.TempDir(), "bot")
+	queue := NewOverflowQueue(dir, 1024)
+	queue.segmentSize = 40
+
+	// the dir should be created with the first item
+	_, err := os.Stat(dir)
+	r.True(os.IsNotExist(err))
+	_, ok, err := queue.Pop()
+	r.NoError(err)
+	r.False(ok)
+
+	for i := 0; i < 10; i++ {
+		r.NoError(queue.Push([]byte(fmt.Sprintf("item-%d", i))))
+	}
+	r.Equal(10, queue.Len())
+	entries, err := os.ReadDir(dir)
+	r.NoError(err)
+	r.Greater(len(entries), 1)
+
+	// the items should be read in order while more items are pushed
+	for i := 0; i < 5; i++ {
+		b, ok, err := queue.Pop()
+		r.NoError(err)
+		r.True(ok)
+		r.Equal(fmt.Sprintf("item-%d", i), string(b))
+	}
+	r.NoError(queue.Push([]byte("item-10")))
+	for i := 5; i <= 10; i++ {
+		b, ok, err := queue.Pop()
+		r.NoError(err)
+		r.True(ok)
+		r.Equal(fmt.Sprintf("item-%d", i), string(b))
+	}
+	r.Equal(0, queue.Len())
+
+	// the read segments should be deleted
+	entries, err = os.ReadDir(dir)
+	r.NoError(err)
+	r.Len(entries, 1)
+
+	r.NoError(queue.Close())
+	_, err = os.Stat(dir)
+	r.True(os.IsNotExist(err))
+	r.ErrorIs(queue.Push([]byte("item")), ErrOverflowClosed)
+}
+
+func TestOverflowQueue_Full(t *testing.T) {
+	r := require.New(t)
+
+	queue := NewOverflowQueue(t.TempDir(), 20)
+	r.NoError(queue.Push([]byte("12345678")))
+	r.ErrorIs(queue.Push([]byte("12345678901")), ErrOverflowFull)
+	r.NoError(queue.Push([]byte("1234")))
+	r.Equal(2, queue.Len())
+
+	// popping should make space
+	_, _, err := queue.Pop()
+	r.NoError(err)
+	r.NoError(queue.Push([]byte("12345678")))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"strconv"
 	"sync"
 	"time"
@@ -13,6 +14,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/forta-network/forta-core-go/ens"
+	"github.com/forta-network/forta-core-go/ipfs"
 	"github.com/forta-network/forta-core-go/manifest"
 	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/utils"
@@ -188,10 +190,29 @@ func loadBot(ctx context.Context, cfg config.Config, bms BotManifestStore, agent
 		return nil, nil, fmt.Errorf("failed to load the bot manifest: %v", err)
 	}
 
-	if signedManifest.Manifest.ImageReference == nil {
+	if signedManifest.Manifest == nil || signedManifest.Manifest.ImageReference == nil {
 		return nil, nil, fmt.Errorf("%w: invalid bot image reference, it is nil", errInvalidBot)
 	}
 
+	// the signatures are verified over the manifest data as it is in the manifest file
+	var manifestData []byte
+	if cfg.Registry.VerifyManifestSignatures || cfg.Registry.Developers.Enabled() {
+		manifestData, err = bms.GetBotManifestData(ctx, ref)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load the signed bot manifest data: %v", err)
+		}
+	}
+
+	if err := validateBotManifest(agentID, signedManifest, manifestData, cfg.Registry.VerifyManifestSignatures); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid bot manifest '%s': %v", errInvalidBot, ref, err)
+	}
+	if err := checkBotDeveloper(signedManifest, manifestData, owner, cfg.Registry.Developers); err != nil {
+		if !cfg.Development {
+			return nil, nil, fmt.Errorf("%w: refused bot manifest '%s': %v", errInvalidBot, ref, err)
+		}
+		log.WithError(err).WithField("bot", agentID).Warn("launching the bot of an unknown developer in the development mode")
+	}
+
 	image, err := utils.ValidateDiscoImageRef(
 		cfg.Registry.ContainerRegistry, *signedManifest.Manifest.ImageReference,
 	)
@@ -199,12 +220,19 @@ func loadBot(ctx context.Context, cfg config.Config, bms BotManifestStore, agent
 		return nil, nil, fmt.Errorf("%w: invalid bot image reference '%s': %v", errInvalidBot, *signedManifest.Manifest.ImageReference, err)
 	}
 
+	// the bot receives all events if the filters are not available
+	filters, err := bms.GetBotFilters(ctx, ref)
+	if err != nil {
+		log.WithError(err).WithField("bot", agentID).Warn("failed to load the bot filters")
+	}
+
 	return &config.AgentConfig{
 		ID:       agentID,
 		Image:    image,
 		Manifest: ref,
 		ChainID:  cfg.ChainID,
 		Owner:    owner,
+		Filters:  filters,
 	}, signedManifest, nil
 }
 
@@ -225,7 +253,11 @@ func NewRegistryStore(ctx context.Context, cfg config.Config) (*registryStore, e
 	if err != nil {
 		return nil, err
 	}
-	bms := NewBotManifestStore(mc)
+	ic, err := ipfs.NewClient(cfg.Registry.IPFS.GatewayURL)
+	if err != nil {
+		return nil, err
+	}
+	bms := NewBotManifestStore(mc).WithFilters(ic)
 
 	rc, err := GetRegistryClient(
 		ctx, cfg, registry.ClientConfig{
@@ -353,6 +385,9 @@ func (rs *privateRegistryStore) GetAgentsIfChanged(scanner string) ([]config.Age
 		}
 
 		agtCfg.Owner = agt.Owner
+		if filters, ok := rs.cfg.LocalModeConfig.BotFilters[agentID]; ok {
+			agtCfg.Filters = filters
+		}
 		agentConfigs = append(agentConfigs, *agtCfg)
 	}
 
@@ -376,6 +411,29 @@ func (rs *privateRegistryStore) GetAgentsIfChanged(scanner string) ([]config.Age
 		}
 	}
 
+	// load the bots which run on the host
+	for _, attachedBot := range rs.cfg.LocalModeConfig.AttachedBots {
+		agentConfigs = append(agentConfigs, config.AgentConfig{
+			ID:      attachedBot.ID,
+			IsLocal: true,
+			ChainID: rs.cfg.ChainID,
+			Filters: attachedBot.Filters,
+			Address: attachedBotAddress(attachedBot.Address),
+		})
+	}
+
+	// load the bots which are HTTP services
+	for _, httpBot := range rs.cfg.LocalModeConfig.HTTPBots {
+		agentConfigs = append(agentConfigs, config.AgentConfig{
+			ID:       httpBot.ID,
+			IsLocal:  true,
+			ChainID:  rs.cfg.ChainID,
+			Filters:  httpBot.Filters,
+			Protocol: config.BotProtocolHTTP,
+			Endpoint: httpBot.Endpoint,
+		})
+	}
+
 	// load the standalone bot configs that are already running
 	if rs.cfg.LocalModeConfig.IsStandalone() {
 		for _, runningBot := range rs.cfg.LocalModeConfig.Standalone.BotContainers {
@@ -390,6 +448,27 @@ func (rs *privateRegistryStore) GetAgentsIfChanged(scanner string) ([]config.Age
 	return agentConfigs, true, nil
 }
 
+// attachedBotAddress converts the localhost addresses to the Docker host addresses, since the
+// bots are dialed from the scanner container.
+func attachedBotAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if host == "localhost" || host == "127.0.0.1" {
+		host = "host.docker.internal"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// SetLocalModeConfig replaces the local mode config which the bot list is loaded from.
+func (rs *privateRegistryStore) SetLocalModeConfig(localModeCfg config.LocalModeConfig) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.cfg.LocalModeConfig = localModeCfg
+}
+
 func (rs *privateRegistryStore) FindAgentGlobally(agentID string) (*config.AgentConfig, error) {
 	return nil, errors.New("feature not available (private/local registry)")
 }
@@ -404,6 +483,7 @@ func (rs *privateRegistryStore) makePrivateModeAgentConfig(
 		IsLocal:     true,
 		ShardConfig: shardConfig,
 		ChainID:     rs.cfg.ChainID,
+		Filters:     rs.cfg.LocalModeConfig.BotFilters[image],
 	}
 }
 
@@ -412,7 +492,11 @@ func NewPrivateRegistryStore(ctx context.Context, cfg config.Config) (*privateRe
 	if err != nil {
 		return nil, err
 	}
-	bms := NewBotManifestStore(mc)
+	ic, err := ipfs.NewClient(cfg.Registry.IPFS.GatewayURL)
+	if err != nil {
+		return nil, err
+	}
+	bms := NewBotManifestStore(mc).WithFilters(ic)
 
 	rc, err := GetRegistryClient(ctx, cfg, registry.ClientConfig{
 		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
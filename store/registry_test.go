@@ -1,11 +1,15 @@
 package store
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 
+	mock_ipfs "github.com/forta-network/forta-core-go/ipfs/mocks"
 	"github.com/forta-network/forta-core-go/manifest"
 	mock_manifest "github.com/forta-network/forta-core-go/manifest/mocks"
 	"github.com/forta-network/forta-core-go/registry"
@@ -305,3 +309,40 @@ func TestGetAgentsIfChanged_NoUpdateNeeded(t *testing.T) {
 	r.False(update)
 	r.Nil(agents)
 }
+
+func TestAttachedBotAddress(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("host.docker.internal:50052", attachedBotAddress("localhost:50052"))
+	r.Equal("host.docker.internal:50052", attachedBotAddress("127.0.0.1:50052"))
+	r.Equal("10.0.0.5:50052", attachedBotAddress("10.0.0.5:50052"))
+}
+
+func TestLoadBot_Developers(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	manifestClient := mock_manifest.NewMockClient(ctrl)
+	ipfsClient := mock_ipfs.NewMockClient(ctrl)
+	bms := NewBotManifestStore(manifestClient).WithFilters(ipfsClient)
+	b, err := os.ReadFile("testdata/bot_manifest.json")
+	r.NoError(err)
+	var signedManifest manifest.SignedAgentManifest
+	r.NoError(json.Unmarshal(b, &signedManifest))
+
+	manifestClient.EXPECT().GetAgentManifest(gomock.Any(), testManifest1).Return(&signedManifest, nil)
+	ipfsClient.EXPECT().GetBytes(gomock.Any(), testManifest1).Return(b, nil)
+	ipfsClient.EXPECT().UnmarshalJson(gomock.Any(), testManifest1, gomock.Any()).Return(nil)
+
+	// the signature is verified over the manifest file when only the allowlist is enabled
+	var cfg config.Config
+	cfg.Registry.Developers.Allowed = []string{testManifestDeveloper}
+	botCfg, _, err := loadBot(context.Background(), cfg, bms, testBot1, testManifest1, "")
+	r.NoError(err)
+	r.Equal(testBot1, botCfg.ID)
+
+	// the cached manifest is refused for another allowlist
+	cfg.Registry.Developers.Allowed = []string{"0x00000000000000000000000000000000000000aa"}
+	_, _, err = loadBot(context.Background(), cfg, bms, testBot1, testManifest1, "")
+	r.ErrorIs(err, errInvalidBot)
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/forta-network/forta-core-go/registry"
+)
+
+// fortDecimals is used for converting the FORT amounts to the base unit.
+const fortDecimals = 18
+
+// ScannerRegistry is the part of the registry client which is used for verifying the scanner.
+type ScannerRegistry interface {
+	GetScanner(scannerID string) (*registry.Scanner, error)
+	GetAllocatedStakePerManaged(blockNumber, poolID *big.Int) (*big.Int, error)
+}
+
+// ScannerState is the registration and the stake state of a scanner.
+type ScannerState struct {
+	Registered bool
+	Enabled    bool
+	PoolID     string
+	// the stake allocated to each scanner of the pool
+	Stake    *big.Int
+	MinStake *big.Int
+}
+
+// Verified tells if the scanner is registered, enabled and staked enough.
+func (state *ScannerState) Verified() bool {
+	if !state.Registered || !state.Enabled {
+		return false
+	}
+	return state.Staked()
+}
+
+// Staked tells if the allocated stake is not below the configured minimum.
+func (state *ScannerState) Staked() bool {
+	if state.MinStake == nil || state.MinStake.Sign() == 0 {
+		return true
+	}
+	return state.Stake != nil && state.Stake.Cmp(state.MinStake) >= 0
+}
+
+// ParseMinStake converts the minimum stake in FORT to the base unit. Empty value means that there
+// is no minimum in addition to the threshold of the registry.
+func ParseMinStake(minStakeFORT string) (*big.Int, error) {
+	if len(minStakeFORT) == 0 {
+		return nil, nil
+	}
+	minStake, ok := new(big.Float).SetString(minStakeFORT)
+	if !ok || minStake.Sign() < 0 {
+		return nil, fmt.Errorf("invalid minimum stake: %s", minStakeFORT)
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(fortDecimals), nil))
+	minStakeInt, _ := minStake.Mul(minStake, unit).Int(nil)
+	return minStakeInt, nil
+}
+
+// CheckScanner gets the registration and the stake state of the scanner from the registry.
+func CheckScanner(reg ScannerRegistry, scannerAddr string, minStake *big.Int) (*ScannerState, error) {
+	scanner, err := reg.GetScanner(scannerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the scanner: %v", err)
+	}
+	state := &ScannerState{MinStake: minStake}
+	// treat reverts the same as non-registered
+	if scanner == nil {
+		return state, nil
+	}
+	state.Registered = true
+	state.Enabled = scanner.Enabled
+	state.PoolID = scanner.PoolID
+
+	// the stake is checked only if there is a minimum
+	if !state.Staked() {
+		poolID, ok := new(big.Int).SetString(scanner.PoolID, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid pool id: %s", scanner.PoolID)
+		}
+		state.Stake, err = reg.GetAllocatedStakePerManaged(nil, poolID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the allocated stake: %v", err)
+		}
+	}
+	return state, nil
+}
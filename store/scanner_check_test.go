@@ -0,0 +1,77 @@
+package store
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/registry"
+	mock_registry "github.com/forta-network/forta-core-go/registry/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testScannerAddr = "0x0000000000000000000000000000000000000001"
+
+func TestParseMinStake(t *testing.T) {
+	r := require.New(t)
+
+	minStake, err := ParseMinStake("")
+	r.NoError(err)
+	r.Nil(minStake)
+
+	minStake, err = ParseMinStake("2.5")
+	r.NoError(err)
+	r.Equal("2500000000000000000", minStake.String())
+
+	_, err = ParseMinStake("-1")
+	r.Error(err)
+	_, err = ParseMinStake("abc")
+	r.Error(err)
+}
+
+func TestCheckScanner(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regClient := mock_registry.NewMockClient(ctrl)
+
+	// not registered
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(nil, nil)
+	state, err := CheckScanner(regClient, testScannerAddr, nil)
+	r.NoError(err)
+	r.False(state.Registered)
+	r.False(state.Verified())
+
+	// registered without a minimum stake
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(&registry.Scanner{Enabled: true, PoolID: "1"}, nil)
+	state, err = CheckScanner(regClient, testScannerAddr, nil)
+	r.NoError(err)
+	r.True(state.Verified())
+
+	// registered with low stake
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(&registry.Scanner{Enabled: true, PoolID: "1"}, nil)
+	regClient.EXPECT().GetAllocatedStakePerManaged(nil, big.NewInt(1)).Return(big.NewInt(99), nil)
+	state, err = CheckScanner(regClient, testScannerAddr, big.NewInt(100))
+	r.NoError(err)
+	r.True(state.Registered)
+	r.False(state.Staked())
+	r.False(state.Verified())
+
+	// registered with enough stake
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(&registry.Scanner{Enabled: true, PoolID: "1"}, nil)
+	regClient.EXPECT().GetAllocatedStakePerManaged(nil, big.NewInt(1)).Return(big.NewInt(100), nil)
+	state, err = CheckScanner(regClient, testScannerAddr, big.NewInt(100))
+	r.NoError(err)
+	r.True(state.Verified())
+
+	// disabled
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(&registry.Scanner{PoolID: "1"}, nil)
+	state, err = CheckScanner(regClient, testScannerAddr, nil)
+	r.NoError(err)
+	r.False(state.Verified())
+
+	regClient.EXPECT().GetScanner(testScannerAddr).Return(nil, errors.New("failed"))
+	_, err = CheckScanner(regClient, testScannerAddr, nil)
+	r.Error(err)
+}
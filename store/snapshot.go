@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const keyPipelineSnapshot = "snapshot/pipeline"
+
+// PipelineSnapshot is the state of the pipeline at the shutdown. The unpublished alerts are not
+// a part of it since the batches are stored before they are published.
+type PipelineSnapshot struct {
+	Time time.Time `json:"time"`
+	// the next block to dispatch per checkpoint - the events from this block until the checkpoint
+	// were dispatched but not finished by the bots
+	Cursors map[string]uint64 `json:"cursors"`
+	// the IDs of the requests which the bots did not finish
+	InFlightRequests []string `json:"inFlightRequests,omitempty"`
+}
+
+// SnapshotStore keeps the pipeline snapshot from the last shutdown.
+type SnapshotStore interface {
+	GetSnapshot() (*PipelineSnapshot, bool, error)
+	PutSnapshot(snapshot *PipelineSnapshot) error
+	DeleteSnapshot() error
+}
+
+// GetSnapshot returns the pipeline snapshot.
+func (ls *localStore) GetSnapshot() (*PipelineSnapshot, bool, error) {
+	b, err := ls.db.Get([]byte(keyPipelineSnapshot), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get snapshot: %v", err)
+	}
+	var snapshot PipelineSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, false, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	return &snapshot, true, nil
+}
+
+// PutSnapshot stores the pipeline snapshot.
+func (ls *localStore) PutSnapshot(snapshot *PipelineSnapshot) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	return ls.db.Put([]byte(keyPipelineSnapshot), b, nil)
+}
+
+// DeleteSnapshot deletes the pipeline snapshot after it is used.
+func (ls *localStore) DeleteSnapshot() error {
+	return ls.db.Delete([]byte(keyPipelineSnapshot), nil)
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_Snapshot(t *testing.T) {
+	r := require.New(t)
+
+	localStore, err := NewLocalStore(t.TempDir())
+	r.NoError(err)
+	defer localStore.Close()
+
+	_, ok, err := localStore.GetSnapshot()
+	r.NoError(err)
+	r.False(ok)
+
+	snapshotTime := time.Now().UTC().Truncate(time.Second)
+	r.NoError(localStore.PutSnapshot(&PipelineSnapshot{
+		Time:             snapshotTime,
+		Cursors:          map[string]uint64{"block": 10},
+		InFlightRequests: []string{"req1"},
+	}))
+
+	snapshot, ok, err := localStore.GetSnapshot()
+	r.NoError(err)
+	r.True(ok)
+	r.True(snapshotTime.Equal(snapshot.Time))
+	r.Equal(uint64(10), snapshot.Cursors["block"])
+	r.Equal([]string{"req1"}, snapshot.InFlightRequests)
+
+	r.NoError(localStore.DeleteSnapshot())
+	_, ok, err = localStore.GetSnapshot()
+	r.NoError(err)
+	r.False(ok)
+}
@@ -0,0 +1,370 @@
+package agentserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufferSize = 1 << 20
+
+// Behavior decides how the server responds to a method.
+type Behavior struct {
+	// the findings which are returned to every request
+	Findings []*protocol.Finding
+	// the server waits before responding
+	Delay time.Duration
+	// the server responds with the gRPC error
+	Err error
+	// the server responds with the ERROR status and the message
+	ErrorResponse string
+	// the first requests fail with Err or ErrorResponse and the rest succeed if above zero
+	FailTimes int
+}
+
+// Server is an in-process agent gRPC server which records the requests and responds
+// as configured. It implements the streaming service as well.
+type Server struct {
+	protocol.UnimplementedAgentServer
+	agentgrpc.UnimplementedAgentStreamServer
+
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+
+	initResponse      *protocol.InitializeResponse
+	behaviors         map[agentgrpc.Method]*Behavior
+	failures          map[agentgrpc.Method]int
+	initRequests      []*protocol.InitializeRequest
+	txRequests        []*protocol.EvaluateTxRequest
+	blockRequests     []*protocol.EvaluateBlockRequest
+	alertRequests     []*protocol.EvaluateAlertRequest
+	disableTxStream   bool
+	disableInitialize bool
+	mu                sync.Mutex
+}
+
+// New creates and starts a new server. The server stops when the test finishes.
+func New(t testing.TB) *Server {
+	server := &Server{
+		listener:   bufconn.Listen(bufferSize),
+		grpcServer: grpc.NewServer(),
+		behaviors:  make(map[agentgrpc.Method]*Behavior),
+		failures:   make(map[agentgrpc.Method]int),
+	}
+	protocol.RegisterAgentServer(server.grpcServer, server)
+	agentgrpc.RegisterAgentStreamServer(server.grpcServer, server)
+	go server.grpcServer.Serve(server.listener)
+	t.Cleanup(server.Stop)
+	return server
+}
+
+// Stop stops the server.
+func (server *Server) Stop() {
+	server.grpcServer.Stop()
+	server.listener.Close()
+}
+
+// SetBehavior sets the behavior of a method.
+func (server *Server) SetBehavior(method agentgrpc.Method, behavior Behavior) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.behaviors[method] = &behavior
+	server.failures[method] = 0
+}
+
+// SetInitializeResponse sets the response to the initialize requests, e.g. with the alert subscriptions.
+func (server *Server) SetInitializeResponse(resp *protocol.InitializeResponse) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.initResponse = resp
+}
+
+// DisableInitialize makes the server look like a bot which does not implement the initialize method.
+func (server *Server) DisableInitialize() {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.disableInitialize = true
+}
+
+// DisableTxStream makes the server look like a bot which does not implement the streaming service.
+func (server *Server) DisableTxStream() {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.disableTxStream = true
+}
+
+// Dial dials the server.
+func (server *Server) Dial(ctx context.Context) (*grpc.ClientConn, error) {
+	return grpc.DialContext(
+		ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return server.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(agentgrpc.DefaultCallOptions()...),
+	)
+}
+
+// Client creates a new agent client which is connected to the server.
+func (server *Server) Client() (agentgrpc.Client, error) {
+	conn, err := server.Dial(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	client := agentgrpc.NewClient()
+	client.WithConn(conn)
+	return client, nil
+}
+
+// Dialer returns a bot dialer which connects all bots to the server.
+func (server *Server) Dialer() agentgrpc.BotDialer {
+	return Dialer(func(config.AgentConfig) *Server {
+		return server
+	})
+}
+
+// Dialer is a bot dialer which connects the bots to the servers returned by the func.
+type Dialer func(config.AgentConfig) *Server
+
+// DialBot implements agentgrpc.BotDialer.
+func (dialer Dialer) DialBot(ac config.AgentConfig) (agentgrpc.Client, error) {
+	server := dialer(ac)
+	if server == nil {
+		return nil, fmt.Errorf("no mock server for bot %s", ac.ID)
+	}
+	return server.Client()
+}
+
+// respond waits and decides the error of the response as configured.
+func (server *Server) respond(ctx context.Context, method agentgrpc.Method) (*Behavior, error) {
+	server.mu.Lock()
+	behavior := server.behaviors[method]
+	if behavior == nil {
+		behavior = &Behavior{}
+	}
+	fail := behavior.FailTimes == 0 || server.failures[method] < behavior.FailTimes
+	if fail && (behavior.Err != nil || len(behavior.ErrorResponse) > 0) {
+		server.failures[method]++
+	}
+	server.mu.Unlock()
+
+	if behavior.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(behavior.Delay):
+		}
+	}
+	if fail && behavior.Err != nil {
+		return nil, behavior.Err
+	}
+	if fail && len(behavior.ErrorResponse) > 0 {
+		return &Behavior{ErrorResponse: behavior.ErrorResponse}, nil
+	}
+	return &Behavior{Findings: behavior.Findings}, nil
+}
+
+func (behavior *Behavior) status() (protocol.ResponseStatus, []*protocol.Error) {
+	if len(behavior.ErrorResponse) > 0 {
+		return protocol.ResponseStatus_ERROR, []*protocol.Error{{Message: behavior.ErrorResponse}}
+	}
+	return protocol.ResponseStatus_SUCCESS, nil
+}
+
+// Initialize implements protocol.AgentServer.
+func (server *Server) Initialize(ctx context.Context, req *protocol.InitializeRequest) (*protocol.InitializeResponse, error) {
+	server.mu.Lock()
+	server.initRequests = append(server.initRequests, req)
+	initResponse := server.initResponse
+	disabled := server.disableInitialize
+	server.mu.Unlock()
+
+	if disabled {
+		return nil, status.Error(codes.Unimplemented, "method Initialize not implemented")
+	}
+	behavior, err := server.respond(ctx, agentgrpc.MethodInitialize)
+	if err != nil {
+		return nil, err
+	}
+	if len(behavior.ErrorResponse) > 0 || initResponse == nil {
+		respStatus, respErrors := behavior.status()
+		return &protocol.InitializeResponse{Status: respStatus, Errors: respErrors}, nil
+	}
+	return initResponse, nil
+}
+
+// EvaluateTx implements protocol.AgentServer.
+func (server *Server) EvaluateTx(ctx context.Context, req *protocol.EvaluateTxRequest) (*protocol.EvaluateTxResponse, error) {
+	server.mu.Lock()
+	server.txRequests = append(server.txRequests, req)
+	server.mu.Unlock()
+
+	behavior, err := server.respond(ctx, agentgrpc.MethodEvaluateTx)
+	if err != nil {
+		return nil, err
+	}
+	respStatus, respErrors := behavior.status()
+	return &protocol.EvaluateTxResponse{
+		Status:    respStatus,
+		Errors:    respErrors,
+		Findings:  behavior.Findings,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// EvaluateTxStream implements agentgrpc.AgentStreamServer. The stream requests share the behavior
+// of the EvaluateTx method.
+func (server *Server) EvaluateTxStream(stream agentgrpc.AgentStream_EvaluateTxStreamServer) error {
+	server.mu.Lock()
+	disabled := server.disableTxStream
+	server.mu.Unlock()
+	if disabled {
+		return server.UnimplementedAgentStreamServer.EvaluateTxStream(stream)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp, err := server.EvaluateTx(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// EvaluateBlock implements protocol.AgentServer.
+func (server *Server) EvaluateBlock(ctx context.Context, req *protocol.EvaluateBlockRequest) (*protocol.EvaluateBlockResponse, error) {
+	server.mu.Lock()
+	server.blockRequests = append(server.blockRequests, req)
+	server.mu.Unlock()
+
+	behavior, err := server.respond(ctx, agentgrpc.MethodEvaluateBlock)
+	if err != nil {
+		return nil, err
+	}
+	respStatus, respErrors := behavior.status()
+	return &protocol.EvaluateBlockResponse{
+		Status:    respStatus,
+		Errors:    respErrors,
+		Findings:  behavior.Findings,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// EvaluateAlert implements protocol.AgentServer.
+func (server *Server) EvaluateAlert(ctx context.Context, req *protocol.EvaluateAlertRequest) (*protocol.EvaluateAlertResponse, error) {
+	server.mu.Lock()
+	server.alertRequests = append(server.alertRequests, req)
+	server.mu.Unlock()
+
+	behavior, err := server.respond(ctx, agentgrpc.MethodEvaluateAlert)
+	if err != nil {
+		return nil, err
+	}
+	respStatus, respErrors := behavior.status()
+	return &protocol.EvaluateAlertResponse{
+		Status:    respStatus,
+		Errors:    respErrors,
+		Findings:  behavior.Findings,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// HealthCheck implements protocol.AgentServer.
+func (server *Server) HealthCheck(ctx context.Context, req *protocol.HealthCheckRequest) (*protocol.HealthCheckResponse, error) {
+	if _, err := server.respond(ctx, agentgrpc.MethodHealthCheck); err != nil {
+		return nil, err
+	}
+	return &protocol.HealthCheckResponse{Status: protocol.HealthCheckResponse_SUCCESS}, nil
+}
+
+// InitializeRequests returns the received initialize requests.
+func (server *Server) InitializeRequests() []*protocol.InitializeRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return append([]*protocol.InitializeRequest(nil), server.initRequests...)
+}
+
+// TxRequests returns the received tx requests, including the ones received through the streams.
+func (server *Server) TxRequests() []*protocol.EvaluateTxRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return append([]*protocol.EvaluateTxRequest(nil), server.txRequests...)
+}
+
+// BlockRequests returns the received block requests.
+func (server *Server) BlockRequests() []*protocol.EvaluateBlockRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return append([]*protocol.EvaluateBlockRequest(nil), server.blockRequests...)
+}
+
+// AlertRequests returns the received alert requests.
+func (server *Server) AlertRequests() []*protocol.EvaluateAlertRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return append([]*protocol.EvaluateAlertRequest(nil), server.alertRequests...)
+}
+
+// RequireTxRequests waits until the server receives at least n tx requests and returns them.
+func (server *Server) RequireTxRequests(t testing.TB, n int, timeout time.Duration) []*protocol.EvaluateTxRequest {
+	require.Eventually(t, func() bool {
+		return len(server.TxRequests()) >= n
+	}, timeout, 10*time.Millisecond, "expected at least %d tx requests", n)
+	return server.TxRequests()
+}
+
+// RequireBlockRequests waits until the server receives at least n block requests and returns them.
+func (server *Server) RequireBlockRequests(t testing.TB, n int, timeout time.Duration) []*protocol.EvaluateBlockRequest {
+	require.Eventually(t, func() bool {
+		return len(server.BlockRequests()) >= n
+	}, timeout, 10*time.Millisecond, "expected at least %d block requests", n)
+	return server.BlockRequests()
+}
+
+// RequireAlertRequests waits until the server receives at least n alert requests and returns them.
+func (server *Server) RequireAlertRequests(t testing.TB, n int, timeout time.Duration) []*protocol.EvaluateAlertRequest {
+	require.Eventually(t, func() bool {
+		return len(server.AlertRequests()) >= n
+	}, timeout, 10*time.Millisecond, "expected at least %d alert requests", n)
+	return server.AlertRequests()
+}
+
+// RequireTxHashes checks that the server received the requests for the transactions in the order.
+func (server *Server) RequireTxHashes(t testing.TB, hashes ...string) {
+	var received []string
+	for _, req := range server.TxRequests() {
+		received = append(received, req.GetEvent().GetTransaction().GetHash())
+	}
+	require.Equal(t, hashes, received)
+}
@@ -0,0 +1,97 @@
+package agentserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func txRequest(hash string) *protocol.EvaluateTxRequest {
+	return &protocol.EvaluateTxRequest{
+		RequestId: hash,
+		Event:     &protocol.TransactionEvent{Transaction: &protocol.TransactionEvent_EthTransaction{Hash: hash}},
+	}
+}
+
+func TestServer(t *testing.T) {
+	r := require.New(t)
+
+	server := New(t)
+	server.SetBehavior(agentgrpc.MethodEvaluateTx, Behavior{
+		Findings:  []*protocol.Finding{{Name: "finding"}},
+		Err:       errors.New("bot failed"),
+		FailTimes: 1,
+	})
+	server.SetBehavior(agentgrpc.MethodEvaluateBlock, Behavior{ErrorResponse: "bad block"})
+
+	client, err := server.Dialer().DialBot(config.AgentConfig{ID: "0xbot"})
+	r.NoError(err)
+	defer client.Close()
+
+	_, err = client.Initialize(context.Background(), &protocol.InitializeRequest{AgentId: "0xbot"})
+	r.NoError(err)
+	r.Equal("0xbot", server.InitializeRequests()[0].AgentId)
+
+	// the first tx request fails and the next one succeeds
+	_, err = client.EvaluateTx(context.Background(), txRequest("0x1"))
+	r.Error(err)
+	txResp, err := client.EvaluateTx(context.Background(), txRequest("0x2"))
+	r.NoError(err)
+	r.Len(txResp.Findings, 1)
+	server.RequireTxHashes(t, "0x1", "0x2")
+
+	blockResp, err := client.EvaluateBlock(context.Background(), &protocol.EvaluateBlockRequest{})
+	r.NoError(err)
+	r.Equal(protocol.ResponseStatus_ERROR, blockResp.Status)
+	r.Equal("bad block", blockResp.Errors[0].Message)
+	r.Len(server.RequireBlockRequests(t, 1, time.Second), 1)
+}
+
+func TestServer_Delay(t *testing.T) {
+	r := require.New(t)
+
+	server := New(t)
+	server.SetBehavior(agentgrpc.MethodEvaluateTx, Behavior{Delay: time.Second})
+	client, err := server.Client()
+	r.NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.EvaluateTx(ctx, txRequest("0x1"))
+	r.Equal(codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestServer_Stream(t *testing.T) {
+	r := require.New(t)
+
+	server := New(t)
+	client, err := server.Client()
+	r.NoError(err)
+	defer client.Close()
+
+	stream, err := client.EvaluateTxStream(context.Background())
+	r.NoError(err)
+	for _, hash := range []string{"0x1", "0x2"} {
+		r.NoError(stream.Send(txRequest(hash)))
+		resp, err := stream.Recv()
+		r.NoError(err)
+		r.Equal(protocol.ResponseStatus_SUCCESS, resp.Status)
+	}
+	server.RequireTxHashes(t, "0x1", "0x2")
+
+	server.DisableTxStream()
+	stream, err = client.EvaluateTxStream(context.Background())
+	r.NoError(err)
+	_ = stream.Send(txRequest("0x3"))
+	_, err = stream.Recv()
+	r.Equal(codes.Unimplemented, status.Code(err))
+}